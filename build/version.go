@@ -163,6 +163,12 @@ var (
 	rawVersion = "v1.0.7"
 	// Version is the current version of rivined.
 	Version ProtocolVersion
+
+	// GitRevision is the git commit of which rivined is built,
+	// and BuildTime the (RFC 3339) time at which it is built,
+	// both set using linker flags, empty if not defined.
+	GitRevision = ""
+	BuildTime   = ""
 )
 
 const (
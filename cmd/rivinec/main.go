@@ -1,8 +1,7 @@
 package main
 
 import (
-	"fmt"
-	"os"
+	"errors"
 
 	"github.com/threefoldtech/rivine/modules"
 	"github.com/threefoldtech/rivine/pkg/cli"
@@ -14,7 +13,12 @@ import (
 func main() {
 	// create command line client
 	bchainInfo := types.DefaultBlockchainInfo()
-	cliClient, err := client.NewCommandLineClient("", bchainInfo.Name, daemon.RivineUserAgent)
+	// connect to the API address of the default network of the daemon by default
+	networkCfg, err := daemon.DefaultNetworkConfig(bchainInfo.NetworkName)
+	if err != nil {
+		panic(err)
+	}
+	cliClient, err := client.NewCommandLineClient("http://"+networkCfg.APIaddr, bchainInfo.Name, daemon.RivineUserAgent)
 	if err != nil {
 		panic(err)
 	}
@@ -27,19 +31,19 @@ func main() {
 			cfg = &newCfg
 		}
 
-		if !(cfg.NetworkName == "standard" || cfg.NetworkName == "devnet" || cfg.NetworkName == "testnet") {
-			return nil, fmt.Errorf("Netork name %q not recognized", cfg.NetworkName)
+		// custom networks are named as defined by their network file
+		if cfg.NetworkName == "" {
+			return nil, errors.New("network name not defined")
 		}
 
 		return cfg, nil
 	}
 	// start cli
 	if err := cliClient.Run(); err != nil {
-		fmt.Fprintln(os.Stderr, "client exited with an error: ", err)
 		// Since no commands return errors (all commands set Command.Run instead of
 		// Command.RunE), Command.Execute() should only return an error on an
 		// invalid command or flag. Therefore Command.Usage() was called (assuming
 		// Command.SilenceUsage is false) and we should exit with exitCodeUsage.
-		os.Exit(cli.ExitCodeUsage)
+		cli.DieWithExitCode(cli.ExitCodeUsage, "client exited with an error:", err)
 	}
 }
@@ -4,7 +4,6 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
 	"runtime"
 	"strings"
 
@@ -17,11 +16,32 @@ import (
 type commands struct {
 	cfg           daemon.Config
 	moduleSetFlag daemon.ModuleSetFlag
+
+	configFile  string
+	printConfig bool
+}
+
+// envPrefix returns the prefix of the environment variables overwriting the configuration.
+func (cmds *commands) envPrefix() string {
+	return strings.ToUpper(cmds.cfg.BlockchainInfo.Name) + "D_"
 }
 
-func (cmds *commands) rootCommand(*cobra.Command, []string) {
-	// create and validate network config
-	networkCfg, err := daemon.DefaultNetworkConfig(cmds.cfg.BlockchainInfo.NetworkName)
+func (cmds *commands) rootCommand(cmd *cobra.Command, _ []string) {
+	// apply the configuration file and environment variables to all flags not given
+	err := daemon.ApplyConfigFile(cmd.Flags(), cmds.configFile, cmds.envPrefix())
+	if err != nil {
+		cli.DieWithExitCode(cli.ExitCodeUsage, "failed to load configuration:", err)
+	}
+	if cmds.printConfig {
+		err = daemon.WriteConfigFile(os.Stdout, cmd.Flags())
+		if err != nil {
+			cli.DieWithError("failed to print configuration", err)
+		}
+		return
+	}
+
+	// create and validate network config, of one of the standard networks or a custom network file
+	networkName, networkCfg, err := daemon.LoadNetworkConfig(cmds.cfg.BlockchainInfo.NetworkName)
 	if err != nil {
 		cli.DieWithError("failed to create network config", err)
 	}
@@ -30,8 +50,9 @@ func (cmds *commands) rootCommand(*cobra.Command, []string) {
 		cli.DieWithError("failed to validate network config", err)
 	}
 
-	// Silently append a subdirectory for storage with the name of the network so we don't create conflicts
-	cmds.cfg.RootPersistentDir = filepath.Join(cmds.cfg.RootPersistentDir, cmds.cfg.BlockchainInfo.NetworkName)
+	// Silently append a subdirectory for storage with the name of the network so we don't create conflicts,
+	// and use the default addresses of the network, unless configured otherwise
+	cmds.cfg.ApplyNetworkConfig(cmd.Flags(), networkName, networkCfg)
 	// Check if we require an api password
 	if cmds.cfg.AuthenticateAPI {
 		// if its not set, ask one now
@@ -14,17 +14,29 @@ import (
 	"github.com/threefoldtech/rivine/modules/consensus"
 	"github.com/threefoldtech/rivine/modules/explorer"
 	"github.com/threefoldtech/rivine/modules/gateway"
+	"github.com/threefoldtech/rivine/modules/remote"
 	"github.com/threefoldtech/rivine/modules/transactionpool"
 	"github.com/threefoldtech/rivine/modules/wallet"
+	"github.com/threefoldtech/rivine/persist"
 	"github.com/threefoldtech/rivine/pkg/api"
 	"github.com/threefoldtech/rivine/pkg/daemon"
+	"github.com/threefoldtech/rivine/types"
 )
 
+// apiShutdownTimeout is the maximum duration the API server waits
+// for the active API calls to finish when the daemon stops.
+const apiShutdownTimeout = 10 * time.Second
+
 func runDaemon(cfg daemon.Config, networkCfg daemon.NetworkConfig, moduleIdentifiers daemon.ModuleIdentifierSet) error {
 	// Print a startup message.
 	fmt.Println("Loading...")
 	loadStart := time.Now()
 
+	err := cfg.ApplyLogConfig()
+	if err != nil {
+		return err
+	}
+
 	var (
 		i             = 1
 		modulesToLoad = moduleIdentifiers.Len()
@@ -34,10 +46,47 @@ func runDaemon(cfg daemon.Config, networkCfg daemon.NetworkConfig, moduleIdentif
 		i++
 	}
 
+	// use the consensus set and transaction pool of a remote node, if configured,
+	// in which case only the wallet is loaded
+	var remoteNode *api.HTTPClient
+	if cfg.RemoteNodeAddr != "" {
+		remoteNode, err = newRemoteNodeClient(cfg, moduleIdentifiers)
+		if err != nil {
+			return err
+		}
+		moduleIdentifiers = daemon.ForceNewIdentifierSet(daemon.WalletModule.Identifier())
+		modulesToLoad = moduleIdentifiers.Len()
+	}
+
+	// migrate the databases of all modules, prior to loading any of them
+	err = migrateDatabases(cfg, moduleIdentifiers)
+	if err != nil {
+		return err
+	}
+	if cfg.DatabaseMigrationDryRun {
+		fmt.Println("Database migration dry run finished, exiting...")
+		return nil
+	}
+	// compact the databases scheduled to be compacted, prior to loading any of them
+	err = compactDatabases(cfg, moduleIdentifiers)
+	if err != nil {
+		return err
+	}
+
 	// create our server already, this way we can fail early if the API addr is already bound
 	fmt.Println("Binding API Address and serving the API...")
-	srv, err := daemon.NewHTTPServer(cfg.APIaddr)
+	var srv *daemon.HTTPServer
+	if tlsCfg := cfg.APITLSConfig(); tlsCfg.Enabled() {
+		srv, err = daemon.NewHTTPSServer(cfg.APIaddr, tlsCfg)
+	} else {
+		srv, err = daemon.NewHTTPServer(cfg.APIaddr)
+	}
+	if err != nil {
+		return err
+	}
+	err = listenAPI(srv, cfg)
 	if err != nil {
+		srv.Close()
 		return err
 	}
 	servErrs := make(chan error)
@@ -45,8 +94,101 @@ func runDaemon(cfg daemon.Config, networkCfg daemon.NetworkConfig, moduleIdentif
 		servErrs <- srv.Serve()
 	}()
 
+	// close the API server and all loaded modules in dependency order when the daemon stops,
+	// also when failing to load a module
+	shutdown := daemon.NewShutdown(cfg.ShutdownTimeout, os.Stdout)
+	defer shutdown.Close()
+	shutdown.Add(daemon.ShutdownStageAPI, "API server", func() error {
+		return srv.Shutdown(apiShutdownTimeout)
+	})
+
+	// serve the health endpoints already, reporting the modules as they are loaded
+	var moduleNames []string
+	for _, module := range []struct {
+		id   daemon.ModuleIdentifier
+		name string
+	}{
+		{daemon.GatewayModule.Identifier(), "gateway"},
+		{daemon.ConsensusSetModule.Identifier(), "consensus"},
+		{daemon.TransactionPoolModule.Identifier(), "transactionpool"},
+		{daemon.WalletModule.Identifier(), "wallet"},
+		{daemon.BlockCreatorModule.Identifier(), "blockcreator"},
+		{daemon.ExplorerModule.Identifier(), "explorer"},
+	} {
+		if moduleIdentifiers.Contains(module.id) {
+			moduleNames = append(moduleNames, module.name)
+		}
+	}
+	for _, plugin := range daemon.ModulePlugins() {
+		if mod := plugin.Module(); moduleIdentifiers.Contains(mod.Identifier()) {
+			moduleNames = append(moduleNames, pluginHealthName(mod))
+		}
+	}
+	health := api.NewHealthTracker(moduleNames...)
+	healthHandler := api.NewHealthHandler(health)
+	srv.Handle("/health", healthHandler)
+	srv.Handle("/health/", healthHandler)
+
+	// notify systemd once the daemon is ready, servicing its watchdog meanwhile,
+	// should the daemon be started by systemd as a notify service
+	notifier, err := daemon.NewSystemdNotifier()
+	if err != nil {
+		return err
+	}
+	if notifier != nil {
+		notifier.Run(func() (bool, string) {
+			if ready, _ := health.Ready(); !ready {
+				return false, "Loading modules..."
+			}
+			synced, consensus := health.Synced(types.BlockHeight(cfg.SystemdReadyMaxBlocksBehind))
+			if consensus == nil {
+				// nothing to sync without a consensus set
+				return true, "Ready"
+			}
+			if !synced {
+				return false, fmt.Sprintf("Syncing, at height %d of %d", consensus.Height, consensus.EstimatedNetworkHeight)
+			}
+			return true, fmt.Sprintf("Synced, at height %d", consensus.Height)
+		})
+		shutdown.Add(daemon.ShutdownStageAPI, "systemd notifier", notifier.Close)
+	}
+
 	// router to register all endpoints to
 	router := httprouter.New()
+	// version 2 of the API, registered to the router once all modules are loaded
+	v2 := api.NewAPIv2(cfg.APIPassword)
+	// the modules of which the persistent data can be backed up while running
+	var backuppers []modules.Backupper
+	// the modules of which the database can be inspected while running, keyed by their persistent directory
+	databaseInspectors := make(map[string]modules.DatabaseInspector)
+	// the modules of which the disk usage is reported
+	var diskUsageModules []api.DiskUsageModule
+
+	// the consensus set and transaction pool of the remote node, if used
+	var (
+		cs    modules.ConsensusSet
+		tpool modules.TransactionPool
+	)
+	if remoteNode != nil {
+		fmt.Printf("Connecting to remote node %s...\r\n", cfg.RemoteNodeAddr)
+		err = verifyRemoteNode(remoteNode, cfg)
+		if err != nil {
+			return err
+		}
+		err = os.MkdirAll(cfg.RootPersistentDir, 0700)
+		if err != nil {
+			return err
+		}
+		remoteLog, err := persist.NewFileLogger(cfg.BlockchainInfo, filepath.Join(cfg.RootPersistentDir, remoteNodeLogFile))
+		if err != nil {
+			return err
+		}
+		cs = remote.NewConsensusSet(remoteNode, remoteLog)
+		tpool = remote.NewTransactionPool(remoteNode, remoteLog)
+		shutdown.Add(daemon.ShutdownStageTransactionPool, "remote transaction pool", tpool.Close)
+		shutdown.Add(daemon.ShutdownStageConsensus, "remote consensus set", cs.Close)
+		shutdown.Add(daemon.ShutdownStageGateway, "remote node log", remoteLog.Close)
+	}
 
 	// Initialize the Rivine modules
 	var g modules.Gateway
@@ -59,51 +201,54 @@ func runDaemon(cfg daemon.Config, networkCfg daemon.NetworkConfig, moduleIdentif
 			return err
 		}
 		api.RegisterGatewayHTTPHandlers(router, g, cfg.APIPassword)
-		defer func() {
-			fmt.Println("Closing gateway...")
-			err := g.Close()
-			if err != nil {
-				fmt.Println("Error during gateway shutdown:", err)
-			}
-		}()
+		api.RegisterGatewayAPIv2Handlers(v2, g)
+		backuppers = append(backuppers, g.(modules.Backupper))
+		diskUsageModules = append(diskUsageModules, api.DiskUsageModule{Name: modules.GatewayDir, Dir: modules.GatewayDir})
+		health.SetModuleLoaded("gateway")
+		shutdown.Add(daemon.ShutdownStageGateway, "gateway", g.Close)
 
 	}
-	var cs modules.ConsensusSet
 	if moduleIdentifiers.Contains(daemon.ConsensusSetModule.Identifier()) {
 		printModuleIsLoading("consensus")
-		cs, err = consensus.New(g, !cfg.NoBootstrap,
+		consensusSet, err := consensus.New(g, !cfg.NoBootstrap,
 			filepath.Join(cfg.RootPersistentDir, modules.ConsensusDir),
 			cfg.BlockchainInfo, networkCfg.Constants)
 		if err != nil {
 			return err
 		}
+		err = consensusSet.SetUnlockHashIndex(cfg.UnlockHashIndex)
+		if err != nil {
+			return err
+		}
+		cs = consensusSet
 		api.RegisterConsensusHTTPHandlers(router, cs)
-		defer func() {
-			fmt.Println("Closing consensus set...")
-			err := cs.Close()
-			if err != nil {
-				fmt.Println("Error during consensus set shutdown:", err)
-			}
-		}()
+		api.RegisterConsensusAPIv2Handlers(v2, cs)
+		backuppers = append(backuppers, consensusSet)
+		databaseInspectors[modules.ConsensusDir] = consensusSet
+		diskUsageModules = append(diskUsageModules, api.DiskUsageModule{Name: modules.ConsensusDir, Dir: modules.ConsensusDir, Database: consensus.DatabaseFilename})
+		health.SetConsensusSet(cs)
+		health.SetModuleLoaded("consensus")
+		shutdown.Add(daemon.ShutdownStageConsensus, "consensus set", cs.Close)
 
 	}
-	var tpool modules.TransactionPool
 	if moduleIdentifiers.Contains(daemon.TransactionPoolModule.Identifier()) {
 		printModuleIsLoading("transaction pool")
+		tpoolConstants := networkCfg.Constants
+		tpoolConstants.TransactionPool = cfg.TransactionPoolConstants(tpoolConstants.TransactionPool)
 		tpool, err = transactionpool.New(cs, g,
 			filepath.Join(cfg.RootPersistentDir, modules.TransactionPoolDir),
-			cfg.BlockchainInfo, networkCfg.Constants)
+			cfg.BlockchainInfo, tpoolConstants)
 		if err != nil {
 			return err
 		}
 		api.RegisterTransactionPoolHTTPHandlers(router, cs, tpool, cfg.APIPassword)
-		defer func() {
-			fmt.Println("Closing transaction pool...")
-			err := tpool.Close()
-			if err != nil {
-				fmt.Println("Error during transaction pool shutdown:", err)
-			}
-		}()
+		api.RegisterTransactionPoolAPIv2Handlers(v2, cs, tpool)
+		databaseInspectors[modules.TransactionPoolDir] = tpool.(modules.DatabaseInspector)
+		// serve the wallets using this node as their remote node
+		api.RegisterRemoteNodeHTTPHandlers(router, cs, tpool, cfg.APIPassword)
+		diskUsageModules = append(diskUsageModules, api.DiskUsageModule{Name: modules.TransactionPoolDir, Dir: modules.TransactionPoolDir, Database: transactionpool.DatabaseFilename})
+		health.SetModuleLoaded("transactionpool")
+		shutdown.Add(daemon.ShutdownStageTransactionPool, "transaction pool", tpool.Close)
 	}
 	var w modules.Wallet
 	if moduleIdentifiers.Contains(daemon.WalletModule.Identifier()) {
@@ -115,13 +260,11 @@ func runDaemon(cfg daemon.Config, networkCfg daemon.NetworkConfig, moduleIdentif
 			return err
 		}
 		api.RegisterWalletHTTPHandlers(router, w, cfg.APIPassword)
-		defer func() {
-			fmt.Println("Closing wallet...")
-			err := w.Close()
-			if err != nil {
-				fmt.Println("Error during wallet shutdown:", err)
-			}
-		}()
+		api.RegisterWalletAPIv2Handlers(v2, w)
+		backuppers = append(backuppers, w.(modules.Backupper))
+		diskUsageModules = append(diskUsageModules, api.DiskUsageModule{Name: modules.WalletDir, Dir: modules.WalletDir})
+		health.SetModuleLoaded("wallet")
+		shutdown.Add(daemon.ShutdownStageConsensusSubscribers, "wallet", w.Close)
 
 	}
 	var b modules.BlockCreator
@@ -133,14 +276,10 @@ func runDaemon(cfg daemon.Config, networkCfg daemon.NetworkConfig, moduleIdentif
 		if err != nil {
 			return err
 		}
-		// block creator has no API endpoints to register
-		defer func() {
-			fmt.Println("Closing block creator...")
-			err := b.Close()
-			if err != nil {
-				fmt.Println("Error during block creator shutdown:", err)
-			}
-		}()
+		api.RegisterBlockCreatorHTTPHandlers(router, b, cfg.APIPassword)
+		diskUsageModules = append(diskUsageModules, api.DiskUsageModule{Name: modules.BlockCreatorDir, Dir: modules.BlockCreatorDir})
+		health.SetModuleLoaded("blockcreator")
+		shutdown.Add(daemon.ShutdownStageBlockCreator, "block creator", b.Close)
 	}
 	var e modules.Explorer
 	if moduleIdentifiers.Contains(daemon.ExplorerModule.Identifier()) {
@@ -152,27 +291,120 @@ func runDaemon(cfg daemon.Config, networkCfg daemon.NetworkConfig, moduleIdentif
 			return err
 		}
 		api.RegisterExplorerHTTPHandlers(router, cs, e, tpool)
-		defer func() {
-			fmt.Println("Closing explorer...")
-			err := e.Close()
-			if err != nil {
-				fmt.Println("Error during explorer shutdown:", err)
-			}
-		}()
+		if cfg.ExplorerGraphQL {
+			api.RegisterExplorerGraphQLHTTPHandlers(router, e)
+		}
+		backuppers = append(backuppers, e.(modules.Backupper))
+		databaseInspectors[modules.ExplorerDir] = e.(modules.DatabaseInspector)
+		diskUsageModules = append(diskUsageModules, api.DiskUsageModule{Name: modules.ExplorerDir, Dir: modules.ExplorerDir, Database: explorer.DatabaseFilename})
+		health.SetModuleLoaded("explorer")
+		shutdown.Add(daemon.ShutdownStageConsensusSubscribers, "explorer", e.Close)
 	}
 
+	// load the modules of the enabled plugins, once all modules they can depend on are loaded
+	plugins, err := daemon.LoadModulePlugins(moduleIdentifiers, daemon.ModuleDependencies{
+		Config:          cfg,
+		NetworkConfig:   networkCfg,
+		Gateway:         g,
+		ConsensusSet:    cs,
+		TransactionPool: tpool,
+		Wallet:          w,
+		BlockCreator:    b,
+		Explorer:        e,
+	})
+	if err != nil {
+		return err
+	}
+	plugins.RegisterHTTPHandlers(router, cfg.APIPassword)
+	for _, pm := range plugins.Modules() {
+		if backupper, ok := pm.(modules.Backupper); ok {
+			backuppers = append(backuppers, backupper)
+		}
+	}
+	for _, plugin := range daemon.ModulePlugins() {
+		if mod := plugin.Module(); moduleIdentifiers.Contains(mod.Identifier()) {
+			health.SetModuleLoaded(pluginHealthName(mod))
+		}
+	}
+	shutdown.Add(daemon.ShutdownStagePlugins, "plugins", plugins.Close)
+
+	// the events of all loaded modules can be streamed over a single connection
+	api.RegisterEventsHTTPHandlers(router, cs, tpool, w, g, cfg.APIPassword)
+
 	fmt.Println("Setting up root HTTP API handler...")
 
+	// API tokens can only be used if the API is password protected
+	var tokens *api.APITokenStore
+	if cfg.APIPassword != "" {
+		tokens, err = api.NewAPITokenStore(filepath.Join(cfg.RootPersistentDir, api.APITokensFile))
+		if err != nil {
+			return err
+		}
+		api.RegisterAPITokenHTTPHandlers(router, tokens, cfg.APIPassword)
+	}
+
 	// register our special daemon HTTP handlers
 	router.GET("/daemon/constants", func(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
 		constants := modules.NewDaemonConstants(cfg.BlockchainInfo, networkCfg.Constants)
 		api.WriteJSON(w, constants)
 	})
-	router.GET("/daemon/version", func(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
-		api.WriteJSON(w, daemon.Version{
-			ChainVersion:    cfg.BlockchainInfo.ChainVersion,
-			ProtocolVersion: cfg.BlockchainInfo.ProtocolVersion,
-		})
+	// report the version of the daemon, as well as the result of the last update check, if enabled
+	version := daemon.NewVersion(cfg, networkCfg, moduleNames)
+	updates, err := cfg.UpdateChecker()
+	if err != nil {
+		return err
+	}
+	if updates != nil {
+		updates.Run()
+		shutdown.Add(daemon.ShutdownStageAPI, "update checker", updates.Close)
+	}
+	versionHandler := func(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+		v := version
+		if updates != nil {
+			status := updates.Status()
+			v.Update = &status
+		}
+		api.WriteJSON(w, v)
+	}
+	router.GET("/daemon/version", versionHandler)
+	// record the API calls modifying the state of the daemon, if configured
+	var auditLog *api.APIAuditLog
+	if cfg.APIAuditLog {
+		auditLog, err = api.NewAPIAuditLog(filepath.Join(cfg.RootPersistentDir, api.APIAuditLogFile))
+		if err != nil {
+			return err
+		}
+		shutdown.Add(daemon.ShutdownStageGateway, "API audit log", auditLog.Close)
+		api.RegisterAuditHTTPHandlers(router, auditLog, cfg.APIPassword)
+		api.RegisterAuditAPIv2Handlers(v2, auditLog)
+	}
+	// remember the responses of the sends made using an idempotency key, if configured
+	var idempotencyCache *api.APIIdempotencyCache
+	if cfg.APIIdempotencyKeyTTL > 0 {
+		idempotencyCache, err = api.NewAPIIdempotencyCache(filepath.Join(cfg.RootPersistentDir, api.APIIdempotencyFile), cfg.APIIdempotencyKeyTTL)
+		if err != nil {
+			return err
+		}
+	}
+	api.RegisterLogHTTPHandlers(router, cfg.APIPassword)
+	api.RegisterBackupHTTPHandlers(router, backuppers, cfg.APIPassword)
+	api.RegisterDatabaseHTTPHandlers(router, databaseInspectors, cfg.APIPassword)
+	api.RegisterDatabaseAPIv2Handlers(v2, databaseInspectors)
+	api.RegisterProfileHTTPHandlers(router, cfg.ProfileDir, cfg.APIPprof, cfg.APIPassword)
+	diskUsage := api.NewDiskUsageTracker(cfg.RootPersistentDir, diskUsageModules...)
+	diskUsage.Run()
+	shutdown.Add(daemon.ShutdownStageAPI, "disk usage tracker", diskUsage.Close)
+	api.RegisterDiskHTTPHandlers(router, diskUsage, cfg.APIPassword)
+	api.RegisterLogAPIv2Handlers(v2)
+	v2.Handle(api.APIv2Route{
+		Method: http.MethodGet, Path: "/daemon/version", Tag: "daemon",
+		Summary:  "get the version of the daemon",
+		Response: daemon.Version{},
+		Handler:  versionHandler,
+	})
+	v2.Register(router, api.OpenAPIInfo{
+		Title:   cfg.BlockchainInfo.Name + " daemon API",
+		Version: cfg.BlockchainInfo.ChainVersion.String(),
 	})
 	router.POST("/daemon/stop", func(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
 		// can't write after we stop the server, so lie a bit.
@@ -191,8 +423,35 @@ func runDaemon(cfg daemon.Config, networkCfg daemon.NetworkConfig, moduleIdentif
 	})
 
 	// handle all our endpoints over a router,
-	// which requires a user agent should one be configured
-	srv.Handle("/", api.RequireUserAgentHandler(router, cfg.RequiredUserAgent))
+	// which requires a user agent should one be configured,
+	// and authenticates the requests using API tokens should the API be password protected,
+	// auditing the requests modifying the state of the daemon should this be configured,
+	// limiting the rate of the requests per IP address and API token,
+	// executing the sends made using an idempotency key only once should this be configured,
+	// allowing cross-origin requests of the configured origins
+	var handler http.Handler = router
+	if idempotencyCache != nil {
+		handler = api.APIIdempotencyHandler(handler, idempotencyCache)
+	}
+	handler = api.RateLimitHandler(handler, api.RateLimitConfig{
+		IPRate:                 cfg.APIRateLimit,
+		IPBurst:                cfg.APIRateBurst,
+		TokenRate:              cfg.APITokenRateLimit,
+		TokenBurst:             cfg.APITokenRateBurst,
+		MaxConcurrentExpensive: cfg.APIMaxConcurrentExpensive,
+	})
+	if auditLog != nil {
+		handler = api.APIAuditHandler(handler, auditLog, cfg.APIPassword)
+	}
+	if tokens != nil {
+		handler = api.RequireAPITokenScopeHandler(handler, tokens)
+	}
+	handler = api.RequireUserAgentHandler(handler, cfg.RequiredUserAgent)
+	srv.Handle("/", api.CORSHandler(handler, api.CORSConfig{
+		AllowedOrigins: cfg.APICORSAllowedOrigins,
+		AllowedMethods: cfg.APICORSAllowedMethods,
+		AllowedHeaders: cfg.APICORSAllowedHeaders,
+	}))
 
 	// stop the server if a kill signal is caught
 	sigChan := make(chan os.Signal, 1)
@@ -207,6 +466,39 @@ func runDaemon(cfg daemon.Config, networkCfg daemon.NetworkConfig, moduleIdentif
 	startupTime := time.Since(loadStart)
 	fmt.Println("Finished loading in", startupTime.Seconds(), "seconds")
 
-	// return the first error which is returned
-	return <-servErrs
+	// return the first error which is returned,
+	// once the API server and all modules are closed
+	err = <-servErrs
+	if shutdownErr := shutdown.Close(); err == nil {
+		err = shutdownErr
+	}
+	return err
+}
+
+// listenAPI makes the API server listen on the additional addresses
+// and the unix domain socket of the configuration, if any.
+func listenAPI(srv *daemon.HTTPServer, cfg daemon.Config) error {
+	for _, addr := range cfg.APIListenAddrs {
+		err := srv.ListenTCP(addr)
+		if err != nil {
+			return fmt.Errorf("failed to listen on API address %s: %v", addr, err)
+		}
+	}
+	path := cfg.APIUnixSocketPath()
+	if path == "" {
+		return nil
+	}
+	perm, err := cfg.APIUnixSocketPerm()
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return err
+	}
+	err = srv.ListenUnix(path, perm)
+	if err != nil {
+		return fmt.Errorf("failed to listen on API unix socket %s: %v", path, err)
+	}
+	return nil
 }
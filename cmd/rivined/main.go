@@ -14,6 +14,10 @@ func main() {
 	var cmds commands
 	// load default config to start with
 	cmds.cfg = daemon.DefaultConfig()
+	// open the plugins, such that their modules are available to the modules flag
+	if err := openPlugins(os.Args[1:], cmds.envPrefix()); err != nil {
+		cli.DieWithExitCode(cli.ExitCodeUsage, "failed to open plugins:", err)
+	}
 	// load default config flag
 	cmds.moduleSetFlag = daemon.DefaultModuleSetFlag()
 
@@ -29,6 +33,13 @@ func main() {
 	cmds.cfg.RegisterAsFlags(root.Flags())
 	// also add our modules as a flag
 	cmds.moduleSetFlag.RegisterFlag(root.Flags(), fmt.Sprintf("%s modules", os.Args[0]))
+	// and the flags to load the configuration from a file
+	root.Flags().StringVarP(&cmds.configFile, "config-file", "c", "",
+		"TOML file to load the configuration from, overwritten by environment variables ("+cmds.envPrefix()+"<FLAG>) and flags")
+	root.Flags().BoolVar(&cmds.printConfig, "print-config", false,
+		"print the configuration, loaded from the config file, environment variables and flags, and exit")
+	root.Flags().StringSlice(pluginFlag, nil,
+		"Go plugins to open, registering the modules of a downstream chain, which can then be enabled using the modules flag")
 
 	// create the other commands
 	root.AddCommand(&cobra.Command{
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/modules/consensus"
+	"github.com/threefoldtech/rivine/modules/explorer"
+	"github.com/threefoldtech/rivine/modules/transactionpool"
+	"github.com/threefoldtech/rivine/persist"
+	"github.com/threefoldtech/rivine/pkg/api"
+	"github.com/threefoldtech/rivine/pkg/daemon"
+)
+
+// migrateDatabases migrates the databases of all modules to be loaded,
+// in the order the modules are loaded, prior to loading any of them.
+func migrateDatabases(cfg daemon.Config, moduleIdentifiers daemon.ModuleIdentifierSet) error {
+	opts := persist.MigrationOptions{
+		DryRun: cfg.DatabaseMigrationDryRun,
+		Backup: !cfg.NoDatabaseBackup,
+	}
+	dbs := []struct {
+		module  daemon.ModuleIdentifier
+		name    string
+		dir     string
+		migrate func(string, persist.MigrationOptions) ([]persist.Migration, error)
+	}{
+		{daemon.ConsensusSetModule.Identifier(), "consensus", modules.ConsensusDir, consensus.MigrateDatabase},
+		{daemon.TransactionPoolModule.Identifier(), "transaction pool", modules.TransactionPoolDir, transactionpool.MigrateDatabase},
+		{daemon.ExplorerModule.Identifier(), "explorer", modules.ExplorerDir, explorer.MigrateDatabase},
+	}
+	for _, db := range dbs {
+		if !moduleIdentifiers.Contains(db.module) {
+			continue
+		}
+		migrations, err := db.migrate(filepath.Join(cfg.RootPersistentDir, db.dir), opts)
+		if err != nil {
+			return fmt.Errorf("failed to migrate %s database: %v", db.name, err)
+		}
+		for _, migration := range migrations {
+			if opts.DryRun {
+				fmt.Printf("%s database can be migrated from v%s to v%s: %s\n", db.name, migration.From, migration.To, migration.Description)
+			} else {
+				fmt.Printf("Migrated %s database from v%s to v%s: %s\n", db.name, migration.From, migration.To, migration.Description)
+			}
+		}
+	}
+	return nil
+}
+
+// compactDatabases compacts the databases of all modules to be loaded,
+// which are scheduled to be compacted using the API, prior to loading any of them.
+func compactDatabases(cfg daemon.Config, moduleIdentifiers daemon.ModuleIdentifierSet) error {
+	schedule, err := api.LoadDatabaseCompactionSchedule(cfg.RootPersistentDir)
+	if err != nil {
+		return fmt.Errorf("failed to load database compaction schedule: %v", err)
+	}
+	if len(schedule.Modules) == 0 {
+		return nil
+	}
+	dbs := []struct {
+		module  daemon.ModuleIdentifier
+		name    string
+		dir     string
+		compact func(string) (persist.DatabaseCompaction, error)
+	}{
+		{daemon.ConsensusSetModule.Identifier(), "consensus", modules.ConsensusDir, consensus.CompactDatabase},
+		{daemon.TransactionPoolModule.Identifier(), "transaction pool", modules.TransactionPoolDir, transactionpool.CompactDatabase},
+		{daemon.ExplorerModule.Identifier(), "explorer", modules.ExplorerDir, explorer.CompactDatabase},
+	}
+	// the databases of modules which are not loaded remain scheduled
+	var remaining api.DatabaseCompactionSchedule
+	for _, name := range schedule.Modules {
+		compacted := false
+		for _, db := range dbs {
+			if db.dir != name || !moduleIdentifiers.Contains(db.module) {
+				continue
+			}
+			fmt.Printf("Compacting %s database...\n", db.name)
+			start := time.Now()
+			compaction, err := db.compact(filepath.Join(cfg.RootPersistentDir, db.dir))
+			if err != nil {
+				return fmt.Errorf("failed to compact %s database: %v", db.name, err)
+			}
+			fmt.Printf("Compacted %s database from %d to %d bytes in %v\n",
+				db.name, compaction.SizeBefore, compaction.SizeAfter, time.Since(start))
+			compacted = true
+		}
+		if !compacted {
+			remaining.Modules = append(remaining.Modules, name)
+		}
+	}
+	return api.SaveDatabaseCompactionSchedule(cfg.RootPersistentDir, remaining)
+}
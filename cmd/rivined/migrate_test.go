@@ -0,0 +1,94 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/threefoldtech/rivine/build"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/modules/explorer"
+	"github.com/threefoldtech/rivine/modules/transactionpool"
+	"github.com/threefoldtech/rivine/persist"
+	"github.com/threefoldtech/rivine/pkg/daemon"
+)
+
+// TestMigrateDatabases migrates the transaction pool and explorer databases
+// from the versions prior to their first migration, as rivined does at startup.
+func TestMigrateDatabases(t *testing.T) {
+	dir := build.TempDir("rivined", t.Name())
+	os.RemoveAll(dir)
+
+	dbs := []struct {
+		md       persist.Metadata
+		filename string
+		migrate  func(string, persist.MigrationOptions) ([]persist.Migration, error)
+	}{
+		{
+			persist.Metadata{Header: "Sia Transaction Pool DB", Version: "0.6.0"},
+			filepath.Join(dir, modules.TransactionPoolDir, transactionpool.DatabaseFilename),
+			transactionpool.MigrateDatabase,
+		},
+		{
+			persist.Metadata{Header: "Sia Explorer", Version: "1.0.8"},
+			filepath.Join(dir, modules.ExplorerDir, explorer.DatabaseFilename),
+			explorer.MigrateDatabase,
+		},
+	}
+	for _, db := range dbs {
+		err := os.MkdirAll(filepath.Dir(db.filename), 0700)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bdb, err := persist.OpenDatabase(db.md, db.filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = bdb.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := daemon.DefaultConfig()
+	cfg.RootPersistentDir = dir
+	moduleIdentifiers := daemon.ForceNewIdentifierSet(
+		daemon.ConsensusSetModule.Identifier(),
+		daemon.TransactionPoolModule.Identifier(),
+		daemon.ExplorerModule.Identifier(),
+	)
+
+	// a dry run leaves the databases at their old version
+	cfg.DatabaseMigrationDryRun = true
+	err := migrateDatabases(cfg, moduleIdentifiers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, db := range dbs {
+		bdb, err := persist.OpenDatabase(db.md, db.filename)
+		if err != nil {
+			t.Fatalf("%s: dry run modified the database: %v", db.md.Header, err)
+		}
+		bdb.Close()
+	}
+
+	// a regular run migrates the databases, backing them up first
+	cfg.DatabaseMigrationDryRun = false
+	err = migrateDatabases(cfg, moduleIdentifiers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, db := range dbs {
+		if _, err := os.Stat(persist.MigrationBackupFilename(db.filename, db.md.Version)); err != nil {
+			t.Errorf("%s: missing backup: %v", db.md.Header, err)
+		}
+		_, err = persist.OpenDatabase(db.md, db.filename)
+		if err != persist.ErrBadVersion {
+			t.Errorf("%s: expected the database to be migrated, not: %v", db.md.Header, err)
+		}
+		migrations, err := db.migrate(filepath.Dir(db.filename), persist.MigrationOptions{})
+		if err != nil || len(migrations) != 0 {
+			t.Errorf("%s: unexpected result for migrated database: %v %v", db.md.Header, migrations, err)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"github.com/threefoldtech/rivine/pkg/daemon"
+)
+
+// pluginFlag is the flag of the Go plugins to open,
+// which register the modules of downstream chains.
+const pluginFlag = "plugin"
+
+// openPlugins opens the Go plugins given using the plugin flag, or its environment variable,
+// prior to parsing the other flags, such that the modules they register can be enabled using the modules flag.
+func openPlugins(args []string, envPrefix string) error {
+	flagSet := pflag.NewFlagSet(pluginFlag, pflag.ContinueOnError)
+	flagSet.ParseErrorsWhitelist.UnknownFlags = true
+	flagSet.SetOutput(ioutil.Discard)
+	filenames := flagSet.StringSlice(pluginFlag, nil, "")
+	// errors, such as the help flag, are reported once all flags are parsed
+	flagSet.Parse(args)
+	if !flagSet.Changed(pluginFlag) {
+		if value, ok := os.LookupEnv(daemon.ConfigEnvVar(envPrefix, pluginFlag)); ok {
+			flagSet.Set(pluginFlag, value)
+		}
+	}
+	return daemon.OpenModulePlugins(*filenames)
+}
+
+// pluginHealthName returns the name of the module of a plugin,
+// as reported by the health endpoints.
+func pluginHealthName(mod *daemon.Module) string {
+	return strings.ToLower(strings.Replace(mod.Name, " ", "", -1))
+}
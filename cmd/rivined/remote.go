@@ -0,0 +1,99 @@
+package main
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/pkg/api"
+	"github.com/threefoldtech/rivine/pkg/daemon"
+)
+
+const (
+	// remoteNodeLogFile is the name of the log file of the remote consensus set and transaction pool,
+	// stored in the root persistent directory.
+	remoteNodeLogFile = "remote.log"
+	// remoteNodeTimeout is the timeout of the API calls made to the remote node,
+	// not applying to the streams of the consensus changes and unconfirmed transactions.
+	remoteNodeTimeout = time.Minute
+	// remoteNodeRetries is the amount of times a failed API call to the remote node is retried.
+	remoteNodeRetries = 3
+)
+
+// newRemoteNodeClient creates the client of the API of the remote node configured,
+// of which the consensus set and transaction pool are used by the wallet,
+// validating that only the wallet is to be loaded, the other modules (if enabled) being those of the remote node.
+func newRemoteNodeClient(cfg daemon.Config, moduleIdentifiers daemon.ModuleIdentifierSet) (*api.HTTPClient, error) {
+	if !moduleIdentifiers.Contains(daemon.WalletModule.Identifier()) {
+		return nil, errors.New("a remote node can only be used by the wallet, which is not enabled")
+	}
+	unsupported := []*daemon.Module{daemon.BlockCreatorModule, daemon.ExplorerModule}
+	for _, plugin := range daemon.ModulePlugins() {
+		unsupported = append(unsupported, plugin.Module())
+	}
+	for _, module := range unsupported {
+		if moduleIdentifiers.Contains(module.Identifier()) {
+			return nil, fmt.Errorf("the %s module cannot be used with a remote node", module.Name)
+		}
+	}
+
+	client := &http.Client{Timeout: remoteNodeTimeout}
+	var config *tls.Config
+	if len(cfg.RemoteNodeTLSPins) > 0 {
+		if !strings.HasPrefix(cfg.RemoteNodeAddr, "https://") {
+			return nil, fmt.Errorf("cannot pin the certificate of remote node %q: an https address is required", cfg.RemoteNodeAddr)
+		}
+		var err error
+		config, err = api.NewPinnedTLSConfig(cfg.RemoteNodeTLSPins)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if cfg.RemoteNodeTLSCertFile != "" || cfg.RemoteNodeTLSKeyFile != "" {
+		if cfg.RemoteNodeTLSCertFile == "" || cfg.RemoteNodeTLSKeyFile == "" {
+			return nil, errors.New("both a TLS certificate and key are required to authenticate to the remote node")
+		}
+		if !strings.HasPrefix(cfg.RemoteNodeAddr, "https://") {
+			return nil, fmt.Errorf("cannot present a client certificate to remote node %q: an https address is required", cfg.RemoteNodeAddr)
+		}
+		cert, err := tls.LoadX509KeyPair(cfg.RemoteNodeTLSCertFile, cfg.RemoteNodeTLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load TLS client certificate: %v", err)
+		}
+		if config == nil {
+			config = &tls.Config{}
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	if config != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = config
+		client.Transport = transport
+	}
+	return &api.HTTPClient{
+		RootURL:   strings.TrimSuffix(cfg.RemoteNodeAddr, "/"),
+		Password:  cfg.RemoteNodePassword,
+		UserAgent: cfg.RequiredUserAgent,
+		Client:    client,
+		Retries:   remoteNodeRetries,
+	}, nil
+}
+
+// verifyRemoteNode verifies that the remote node can be reached,
+// and that it is a node of the same blockchain network as the daemon.
+func verifyRemoteNode(client *api.HTTPClient, cfg daemon.Config) error {
+	var constants modules.DaemonConstants
+	err := client.GetAPI("/daemon/constants", &constants)
+	if err != nil {
+		return fmt.Errorf("failed to connect to remote node %s: %v", cfg.RemoteNodeAddr, err)
+	}
+	if info := constants.ChainInfo; info.Name != cfg.BlockchainInfo.Name || info.NetworkName != cfg.BlockchainInfo.NetworkName {
+		return fmt.Errorf("remote node %s is a node of %s (%s), not of %s (%s)", cfg.RemoteNodeAddr,
+			info.Name, info.NetworkName, cfg.BlockchainInfo.Name, cfg.BlockchainInfo.NetworkName)
+	}
+	return nil
+}
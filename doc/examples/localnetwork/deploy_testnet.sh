@@ -24,7 +24,7 @@ docker rm -f r2
 docker run -d --name r2 --net=$network_name rivine_testnet
 
 # Connect the dockers
-docker exec r1 rivinec gateway connect "r2:23112"
+docker exec r1 rivinec gateway connect "r2:23132"
  
 
 # Create a wallet
@@ -61,7 +61,7 @@ echo "$testpass" | docker exec -i r1 rivinec wallet unlock
 
 # So piping the password to the docker to ensure that the http api listens on none localhost addresses causes some issues.
 docker rm -f r3
-docker run -d -i --name r3 --net=$network_name rivine_testnet --disable-api-security --authenticate-api --no-bootstrap -M cgte --api-addr :23110
+docker run -d -i --name r3 --net=$network_name rivine_testnet --disable-api-security --authenticate-api --no-bootstrap -M cgte --api-addr :23130
 # Do some serious monkey business to get the gateway running
 echo $testpass | docker attach r3
 
@@ -70,10 +70,10 @@ echo $testpass | docker attach r3
 sleep 1
 
 # Connect the gateway to the network
-echo $testpass | docker exec -i r3 rivinec gateway connect "r1.$network_name:23112"
+echo $testpass | docker exec -i r3 rivinec gateway connect "r1.$network_name:23132"
 
 # So now we have a docker with a gateway running which accepts commands from non-localhost addresses.
 # Echo the gateway ip for good measure
 echo "Gateway addr:"
-echo http://$(docker inspect -f "{{ .NetworkSettings.Networks.$network_name.IPAddress }}" r3):23110
+echo http://$(docker inspect -f "{{ .NetworkSettings.Networks.$network_name.IPAddress }}" r3):23130
 echo "Possible address to send coins: $addr"
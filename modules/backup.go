@@ -0,0 +1,11 @@
+package modules
+
+import "github.com/threefoldtech/rivine/persist"
+
+// Backupper is implemented by the modules which can back up their persistent data
+// while the daemon is running, such as the consensus set, wallet, gateway and explorer.
+type Backupper interface {
+	// Backup writes a consistent, point-in-time copy of the persistent data of the module,
+	// named relative to the root persistent directory of the daemon (e.g. consensus/consensus.db).
+	Backup(persist.BackupWriter) error
+}
@@ -1,14 +1,72 @@
 package modules
 
-import "io"
+import (
+	"io"
+
+	"github.com/threefoldtech/rivine/types"
+)
 
 const (
 	// BlockCreatorDir is the name of the directory that is used to store the BlockCreator's
 	// persistent data.
 	BlockCreatorDir = "blockcreator"
+
+	// BlockCreatorMaxCreatedBlocks is the maximum amount of created blocks
+	// remembered by the block creator.
+	BlockCreatorMaxCreatedBlocks = 100
+)
+
+type (
+	// BlockCreatorStatus contains the status of the block creator.
+	BlockCreatorStatus struct {
+		// Active is true if the block creator is trying to create blocks,
+		// which requires a synced consensus set, an unlocked wallet and owned blockstake.
+		// If not active, the reason is given in InactiveReason.
+		Active         bool   `json:"active"`
+		InactiveReason string `json:"inactivereason,omitempty"`
+
+		Synced         bool `json:"synced"`
+		WalletUnlocked bool `json:"walletunlocked"`
+
+		// BlockStake is the blockstake owned by the wallet,
+		// of which EligibleBlockStake is aged enough to create the next block.
+		BlockStake         types.Currency `json:"blockstake"`
+		EligibleBlockStake types.Currency `json:"eligibleblockstake"`
+		// NetworkBlockStake is the active blockstake of the network,
+		// estimated using the difficulty of the next block.
+		NetworkBlockStake types.Currency `json:"networkblockstake"`
+		// ExpectedTimeToBlock is the average time, in seconds, it takes to create a block
+		// using the eligible blockstake, 0 if no block can be created.
+		ExpectedTimeToBlock uint64 `json:"expectedtimetoblock"`
+
+		// LastAttempt is the time at which the block creator last tried to create a block,
+		// 0 if it didn't try yet since the daemon was started.
+		LastAttempt types.Timestamp `json:"lastattempt"`
+		// CreatedBlockCount is the amount of created blocks remembered by the block creator,
+		// of which LastCreatedBlock is the most recent one.
+		CreatedBlockCount int                `json:"createdblockcount"`
+		LastCreatedBlock  *BlockCreatorBlock `json:"lastcreatedblock,omitempty"`
+	}
+
+	// BlockCreatorBlock is a block created by the block creator,
+	// which is part of the current blockchain.
+	BlockCreatorBlock struct {
+		ID        types.BlockID     `json:"id"`
+		Height    types.BlockHeight `json:"height"`
+		Timestamp types.Timestamp   `json:"timestamp"`
+		// Reward is the sum of the miner payouts of the block.
+		Reward types.Currency `json:"reward"`
+	}
 )
 
 // The BlockCreator interface provides access to BlockCreator features.
 type BlockCreator interface {
 	io.Closer
+
+	// Status returns the status of the block creator.
+	Status() BlockCreatorStatus
+
+	// CreatedBlocks returns the most recent blocks created by the block creator,
+	// up to BlockCreatorMaxCreatedBlocks, sorted from newest to oldest.
+	CreatedBlocks() []BlockCreatorBlock
 }
@@ -28,6 +28,9 @@ type BlockCreator struct {
 
 	unsolvedBlock *types.Block
 
+	// lastAttempt is the time at which the block creator last tried to create a block
+	lastAttempt types.Timestamp
+
 	log        *persist.Logger
 	mu         sync.RWMutex
 	persist    persistence
@@ -122,11 +125,6 @@ func New(cs modules.ConsensusSet, tpool modules.TransactionPool, w modules.Walle
 		b.cs.Unsubscribe(b)
 	})
 
-	b.tpool.TransactionPoolSubscribe(b)
-	b.tg.OnStop(func() {
-		b.tpool.Unsubscribe(b)
-	})
-
 	// Save after synchronizing with consensus
 	// In case we exit while the consensus set is syncing, it is possible that one
 	// of the callbacks is still modifying the block creator persistent data, which
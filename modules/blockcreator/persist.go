@@ -27,6 +27,9 @@ type (
 		RecentChange modules.ConsensusChangeID
 		Height       types.BlockHeight
 		ParentID     types.BlockID
+		// CreatedBlocks are the most recent blocks created by the block creator,
+		// sorted from oldest to newest.
+		CreatedBlocks []modules.BlockCreatorBlock `json:",omitempty"`
 	}
 )
 
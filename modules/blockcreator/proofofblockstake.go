@@ -2,10 +2,9 @@ package blockcreator
 
 import (
 	"encoding/json"
-	"math/big"
 	"time"
 
-	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/modules"
 	"github.com/threefoldtech/rivine/types"
 )
 
@@ -34,8 +33,13 @@ func (bc *BlockCreator) SolveBlocks() {
 			bc.csSynced = true
 		}
 
-		// Try to solve a block for blocktimes of the next 10 seconds
+		// Fill the unsolved block with the pooled transactions paying the highest fees,
+		// and try to solve a block for blocktimes of the next 10 seconds
+		bc.updateUnsolvedBlockTransactions()
 		now := time.Now().Unix()
+		bc.mu.Lock()
+		bc.lastAttempt = types.Timestamp(now)
+		bc.mu.Unlock()
 		bc.log.Debugln("[BC] Attempting to solve blocks")
 		b := bc.solveBlock(uint64(now), 10)
 		if b != nil {
@@ -45,6 +49,8 @@ func (bc *BlockCreator) SolveBlocks() {
 			err := bc.submitBlock(*b)
 			if err != nil {
 				bc.log.Println("ERROR: An error occurred while submitting a solved block:", err)
+			} else {
+				bc.addCreatedBlock(*b)
 			}
 		}
 		//sleep a while before recalculating
@@ -67,28 +73,26 @@ func (bc *BlockCreator) solveBlock(startTime uint64, secondsInTheFuture uint64)
 		bc.log.Printf("failed to start solving block stakes: %v", err)
 		return nil
 	}
+	// report the expected time it takes to create a block with the available blockstake
+	var blockStake types.Currency
 	for _, ubso := range unspentBlockStakeOutputs {
-		BlockStakeAge := types.Timestamp(0)
-		// Filter all unspent block stakes for aging. If the index of the unspent
-		// block stake output is not the first transaction with the first index,
-		// then block stake can only be used to solve blocks after its aging is
-		// older than types.BlockStakeAging (more than 1 day)
-		if ubso.Indexes.TransactionIndex != 0 || ubso.Indexes.OutputIndex != 0 {
-			blockatheigh, _ := bc.cs.BlockAtHeight(ubso.Indexes.BlockHeight)
-			BlockStakeAge = blockatheigh.Header().Timestamp + types.Timestamp(bc.chainCts.BlockStakeAging)
-		}
+		blockStake = blockStake.Add(ubso.Value)
+	}
+	bc.log.Debugf("[BC] Expected time to create a block with %v blockstake: %v\n",
+		blockStake, modules.ExpectedTimeToBlock(target.Difficulty(bc.chainCts.RootDepth), blockStake))
+
+	pobsRules := bc.chainCts.POBSRules()
+	for _, ubso := range unspentBlockStakeOutputs {
+		// Filter all unspent block stakes for aging,
+		// as defined by the proof of blockstake rules.
+		minimumBlockTimestamp := bc.minimumBlockTimestamp(pobsRules, ubso)
 		// Try all timestamps for this timerange
 		for blocktime := startTime; blocktime < startTime+secondsInTheFuture; blocktime++ {
-			if BlockStakeAge > types.Timestamp(blocktime) {
+			if minimumBlockTimestamp > types.Timestamp(blocktime) {
 				continue
 			}
-			// Calculate the hash for the given unspent output and timestamp
-			pobshash := crypto.HashAll(stakemodifier.Bytes(), ubso.Indexes.BlockHeight, ubso.Indexes.TransactionIndex, ubso.Indexes.OutputIndex, blocktime)
-			// Check if it meets the difficulty
-			pobshashvalue := big.NewInt(0).SetBytes(pobshash[:])
-			pobshashvalue.Div(pobshashvalue, ubso.Value.Big()) //TODO rivine : this div can be mul on the other side of the compare
-
-			if pobshashvalue.Cmp(target.Int()) == -1 {
+			// Check if the given unspent output and timestamp meet the difficulty
+			if pobsRules.MeetsTarget(stakemodifier, ubso.Indexes, ubso.Value, types.Timestamp(blocktime), target) {
 				err := bc.RespentBlockStake(ubso)
 				if err != nil {
 					bc.log.Printf("failed to respond block stake %q: %v", ubso.BlockStakeOutputID.String(), err)
@@ -142,6 +146,17 @@ func (bc *BlockCreator) solveBlock(startTime uint64, secondsInTheFuture uint64)
 	return
 }
 
+// minimumBlockTimestamp returns the minimum timestamp of a block created using the given
+// unspent blockstake output, such that blockstake is aged as defined by the proof of blockstake rules.
+func (bc *BlockCreator) minimumBlockTimestamp(pobsRules types.ProofOfBlockStakeRules, ubso types.UnspentBlockStakeOutput) types.Timestamp {
+	var outputBlockTimestamp types.Timestamp
+	if ubso.Indexes.TransactionIndex != 0 || ubso.Indexes.OutputIndex != 0 {
+		blockatheigh, _ := bc.cs.BlockAtHeight(ubso.Indexes.BlockHeight)
+		outputBlockTimestamp = blockatheigh.Header().Timestamp
+	}
+	return pobsRules.MinimumBlockTimestamp(ubso.Indexes, outputBlockTimestamp)
+}
+
 // RespentBlockStake will spent the unspent block stake output which is needed
 // for the POBS algorithm. The transaction created will be the first transaction
 // in the block to avoid the BlockStakeAging for later use of this block stake.
@@ -0,0 +1,113 @@
+package blockcreator
+
+import (
+	"time"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// Status implements modules.BlockCreator.Status
+func (bc *BlockCreator) Status() modules.BlockCreatorStatus {
+	status := modules.BlockCreatorStatus{
+		Synced:         bc.cs.Synced(),
+		WalletUnlocked: bc.wallet.Unlocked(),
+	}
+
+	// the difficulty is the amount of active blockstake times the block frequency
+	target, _ := bc.cs.ChildTarget(bc.cs.CurrentBlock().ID())
+	difficulty := target.Difficulty(bc.chainCts.RootDepth)
+	if bc.chainCts.BlockFrequency > 0 {
+		status.NetworkBlockStake = types.NewCurrency(difficulty.Big()).Div64(uint64(bc.chainCts.BlockFrequency))
+	}
+
+	if status.WalletUnlocked {
+		ubsos, err := bc.wallet.GetUnspentBlockStakeOutputs()
+		if err != nil {
+			bc.log.Printf("failed to get the unspent block stake outputs for the status: %v", err)
+		}
+		pobsRules := bc.chainCts.POBSRules()
+		now := types.CurrentTimestamp()
+		for _, ubso := range ubsos {
+			status.BlockStake = status.BlockStake.Add(ubso.Value)
+			if bc.minimumBlockTimestamp(pobsRules, ubso) <= now {
+				status.EligibleBlockStake = status.EligibleBlockStake.Add(ubso.Value)
+			}
+		}
+		if !status.EligibleBlockStake.IsZero() {
+			status.ExpectedTimeToBlock = uint64(modules.ExpectedTimeToBlock(difficulty, status.EligibleBlockStake) / time.Second)
+		}
+	}
+
+	switch {
+	case !status.Synced:
+		status.InactiveReason = "consensus set is not synced"
+	case !status.WalletUnlocked:
+		status.InactiveReason = "wallet is locked"
+	case status.BlockStake.IsZero():
+		status.InactiveReason = "wallet has no blockstake"
+	default:
+		status.Active = true
+	}
+
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+	status.LastAttempt = bc.lastAttempt
+	status.CreatedBlockCount = len(bc.persist.CreatedBlocks)
+	if status.CreatedBlockCount > 0 {
+		block := bc.persist.CreatedBlocks[status.CreatedBlockCount-1]
+		status.LastCreatedBlock = &block
+	}
+	return status
+}
+
+// CreatedBlocks implements modules.BlockCreator.CreatedBlocks
+func (bc *BlockCreator) CreatedBlocks() []modules.BlockCreatorBlock {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+	blocks := make([]modules.BlockCreatorBlock, 0, len(bc.persist.CreatedBlocks))
+	for i := len(bc.persist.CreatedBlocks) - 1; i >= 0; i-- {
+		blocks = append(blocks, bc.persist.CreatedBlocks[i])
+	}
+	return blocks
+}
+
+// addCreatedBlock remembers the given block, accepted by the consensus set,
+// as created by the block creator, forgetting the oldest created block if needed.
+func (bc *BlockCreator) addCreatedBlock(b types.Block) {
+	height, _ := bc.cs.BlockHeightOfBlock(b)
+	block := modules.BlockCreatorBlock{
+		ID:        b.ID(),
+		Height:    height,
+		Timestamp: b.Timestamp,
+	}
+	for _, mp := range b.MinerPayouts {
+		block.Reward = block.Reward.Add(mp.Value)
+	}
+
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	bc.persist.CreatedBlocks = append(bc.persist.CreatedBlocks, block)
+	if n := len(bc.persist.CreatedBlocks); n > modules.BlockCreatorMaxCreatedBlocks {
+		bc.persist.CreatedBlocks = bc.persist.CreatedBlocks[n-modules.BlockCreatorMaxCreatedBlocks:]
+	}
+	err := bc.save()
+	if err != nil {
+		bc.log.Println(err)
+	}
+}
+
+// removeCreatedBlock forgets the given block if it was created by the block creator,
+// as it is no longer part of the blockchain. It should be called while holding the lock.
+func (bc *BlockCreator) removeCreatedBlock(b types.Block) {
+	if len(bc.persist.CreatedBlocks) == 0 {
+		return
+	}
+	id := b.ID()
+	for i, block := range bc.persist.CreatedBlocks {
+		if block.ID == id {
+			bc.persist.CreatedBlocks = append(bc.persist.CreatedBlocks[:i], bc.persist.CreatedBlocks[i+1:]...)
+			return
+		}
+	}
+}
@@ -2,8 +2,6 @@ package blockcreator
 
 import (
 	"github.com/threefoldtech/rivine/modules"
-	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
-	"github.com/threefoldtech/rivine/types"
 )
 
 // ProcessConsensusChange will update the blockcreator's most recent block.
@@ -13,6 +11,8 @@ func (bc *BlockCreator) ProcessConsensusChange(cc modules.ConsensusChange) {
 
 	// Update the block creator's understanding of the block height.
 	for _, block := range cc.RevertedBlocks {
+		// A reverted block is no longer part of the blockchain, even if it was created by us.
+		bc.removeCreatedBlock(block)
 		// Only doing the block check if the height is above zero saves hashing
 		// and saves a nontrivial amount of time during IBD.
 		if bc.persist.Height > 0 || block.ID() != bc.genesisID {
@@ -49,27 +49,13 @@ func (bc *BlockCreator) ProcessConsensusChange(cc modules.ConsensusChange) {
 
 }
 
-// ReceiveUpdatedUnconfirmedTransactions will replace the current unconfirmed
-// set of transactions with the input transactions.
-func (bc *BlockCreator) ReceiveUpdatedUnconfirmedTransactions(unconfirmedTransactions []types.Transaction, _ modules.ConsensusChange) {
+// updateUnsolvedBlockTransactions replaces the transactions of the unsolved block
+// with the pooled transactions paying the highest (package) fee-per-byte,
+// up to the block size limit. It should not be called while holding the block creator lock,
+// as the transaction pool can call into the block creator while holding its own lock.
+func (bc *BlockCreator) updateUnsolvedBlockTransactions() {
+	txns := bc.tpool.BlockTransactions(bc.chainCts.BlockSizeLimit - 5e3) //check this 5k for the first extra
 	bc.mu.Lock()
-	defer bc.mu.Unlock()
-	// Edge case - if there are no transactions, set the block's transactions
-	// to nil and return.
-	if len(unconfirmedTransactions) == 0 {
-		bc.unsolvedBlock.Transactions = nil
-		return
-	}
-
-	// Add transactions to the block until the block size limit is reached.
-	// Transactions are assumed to be in a sensible order.
-	var i int
-	remainingSize := int(bc.chainCts.BlockSizeLimit - 5e3) //check this 5k for the first extra
-	for i = range unconfirmedTransactions {
-		remainingSize -= len(siabin.Marshal(unconfirmedTransactions[i]))
-		if remainingSize < 0 {
-			break
-		}
-	}
-	bc.unsolvedBlock.Transactions = unconfirmedTransactions[:i+1]
+	bc.unsolvedBlock.Transactions = txns
+	bc.mu.Unlock()
 }
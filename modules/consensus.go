@@ -2,7 +2,10 @@ package modules
 
 import (
 	"errors"
+	"fmt"
+	"math"
 	"math/big"
+	"time"
 
 	"github.com/threefoldtech/rivine/crypto"
 	"github.com/threefoldtech/rivine/types"
@@ -19,6 +22,33 @@ const (
 	// DiffRevert indicates that a diff is being reverted from the consensus
 	// set.
 	DiffRevert DiffDirection = false
+
+	// DefaultSubscriberQueueSize is the amount of consensus changes an
+	// asynchronous subscriber can have pending, when no queue size is
+	// given as part of its AsyncSubscribeOptions.
+	DefaultSubscriberQueueSize = 64
+
+	// DefaultBlockStatisticsWindow is the amount of most recent blocks
+	// used to compute the block statistics, when no window is given.
+	DefaultBlockStatisticsWindow = 100
+)
+
+const (
+	// SubscriberOverflowBlock makes the consensus set wait until an
+	// asynchronous subscriber has room in its queue again. No consensus
+	// change is ever lost, at the cost of slowing down block acceptance
+	// once the queue is full: the consensus set waits while still holding
+	// its (demoted) lock, such that a subscriber which keeps its queue full
+	// stalls block acceptance just like a slow synchronous subscriber would.
+	SubscriberOverflowBlock SubscriberOverflowPolicy = iota
+	// SubscriberOverflowDropOldest drops the oldest pending consensus change
+	// of an asynchronous subscriber in order to make room for the new one.
+	// Only use this policy for subscribers that do not require
+	// every consensus change (e.g. notifiers which only care about the tip).
+	SubscriberOverflowDropOldest
+	// SubscriberOverflowUnsubscribe disconnects an asynchronous subscriber
+	// as soon as its queue overflows, dropping all its pending changes.
+	SubscriberOverflowUnsubscribe
 )
 
 var (
@@ -47,6 +77,10 @@ var (
 	// should be handled by the module, and not reported to the user.
 	ErrInvalidConsensusChangeID = errors.New("consensus subscription has invalid id - files are inconsistent")
 
+	// ErrUnlockHashIndexDisabled is returned when looking up unspent outputs
+	// by unlock hash, while the unlock hash index of the consensus set is disabled.
+	ErrUnlockHashIndexDisabled = errors.New("unlock hash index of the consensus set is disabled")
+
 	// ErrNonExtendingBlock indicates that a block is valid but does not result
 	// in a fork that is the heaviest known fork - the consensus set has not
 	// changed as a result of seeing the block.
@@ -71,6 +105,49 @@ type (
 		ProcessConsensusChange(ConsensusChange)
 	}
 
+	// SubscriberOverflowPolicy defines what happens when
+	// the queue of an asynchronous subscriber is full.
+	SubscriberOverflowPolicy uint8
+
+	// AsyncSubscribeOptions configures how consensus changes are dispatched
+	// to an asynchronous consensus set subscriber.
+	AsyncSubscribeOptions struct {
+		// QueueSize is the maximum amount of consensus changes which can be pending
+		// for the subscriber, DefaultSubscriberQueueSize is used if it is 0.
+		QueueSize int
+		// OverflowPolicy defines what happens when a consensus change is
+		// dispatched while the queue is full.
+		OverflowPolicy SubscriberOverflowPolicy
+	}
+
+	// SubscriberStats reports the dispatch metrics of a single consensus set subscriber.
+	SubscriberStats struct {
+		// Name identifies the subscriber, and is the name of its (Go) type.
+		Name string `json:"name"`
+		// Async is true in case the subscriber receives its changes
+		// through a bounded queue, rather than synchronously.
+		Async bool `json:"async"`
+
+		// The following properties are only defined for asynchronous subscribers.
+
+		OverflowPolicy SubscriberOverflowPolicy `json:"overflowpolicy"`
+		// QueueSize is the capacity of the queue.
+		QueueSize int `json:"queuesize"`
+		// QueueLength is the amount of changes currently pending.
+		QueueLength int `json:"queuelength"`
+		// Delivered is the total amount of changes processed by the subscriber.
+		Delivered uint64 `json:"delivered"`
+		// Dropped is the total amount of changes dropped because of overflows.
+		Dropped uint64 `json:"dropped"`
+		// LastLag is the time between dispatching and processing of the
+		// last delivered change, MaxLag is the largest lag observed so far.
+		LastLag time.Duration `json:"lastlag"`
+		MaxLag  time.Duration `json:"maxlag"`
+		// Disconnected is true if the subscriber was disconnected
+		// due to the SubscriberOverflowUnsubscribe policy.
+		Disconnected bool `json:"disconnected"`
+	}
+
 	// A ConsensusChange enumerates a set of changes that occurred to the consensus set.
 	ConsensusChange struct {
 		// ID is a unique id for the consensus change derived from the reverted
@@ -147,6 +224,73 @@ type (
 		ShortID   types.TransactionShortID
 	}
 
+	// UnspentCoinOutput is an unspent coin output, paired with its ID.
+	UnspentCoinOutput struct {
+		ID     types.CoinOutputID `json:"id"`
+		Output types.CoinOutput   `json:"output"`
+	}
+
+	// UnspentBlockStakeOutput is an unspent block stake output, paired with its ID.
+	UnspentBlockStakeOutput struct {
+		ID     types.BlockStakeOutputID `json:"id"`
+		Output types.BlockStakeOutput   `json:"output"`
+	}
+
+	// UnspentOutputs groups all unspent coin and block stake outputs
+	// locked by a condition with the same unlock hash.
+	UnspentOutputs struct {
+		CoinOutputs       []UnspentCoinOutput       `json:"coinoutputs"`
+		BlockStakeOutputs []UnspentBlockStakeOutput `json:"blockstakeoutputs"`
+	}
+
+	// BlockStatistics are rolling statistics, computed over
+	// a window of the most recent blocks of the current path.
+	BlockStatistics struct {
+		// Height is the height of the current block.
+		Height types.BlockHeight `json:"height"`
+		// Window is the amount of block intervals the statistics are computed over.
+		Window types.BlockHeight `json:"window"`
+
+		// TargetBlockTime is the block interval aimed for by the network, in seconds.
+		TargetBlockTime types.BlockHeight `json:"targetblocktime"`
+		// AverageBlockTime is the actual average block interval, in seconds.
+		AverageBlockTime float64 `json:"averageblocktime"`
+
+		// Difficulty is the difficulty of the next block.
+		Difficulty types.Difficulty `json:"difficulty"`
+		// WindowStartDifficulty is the difficulty at the start of the window.
+		WindowStartDifficulty types.Difficulty `json:"windowstartdifficulty"`
+		// DifficultyTrend is the relative change of the difficulty over the window,
+		// e.g. 0.05 means the difficulty increased with 5%.
+		DifficultyTrend float64 `json:"difficultytrend"`
+
+		// EstimatedActiveBlockStake is an estimate of the amount of blockstake
+		// participating in the creation of blocks, derived from the difficulty
+		// and the actual average block interval.
+		EstimatedActiveBlockStake types.Currency `json:"estimatedactiveblockstake"`
+	}
+
+	// SyncProgress reports the progress of the consensus set,
+	// while synchronizing with the network.
+	SyncProgress struct {
+		// Synced is true if the initial blockchain download has finished.
+		Synced bool `json:"synced"`
+		// Height is the height of the current block.
+		Height types.BlockHeight `json:"height"`
+		// EstimatedNetworkHeight is the estimated height of the network,
+		// the highest height of the headers relayed by peers, or extrapolated from
+		// the timestamp of the current block while the consensus set isn't synced yet.
+		EstimatedNetworkHeight types.BlockHeight `json:"estimatednetworkheight"`
+		// BlocksPerSecond is the average amount of blocks
+		// processed per second since the initial blockchain download started.
+		BlocksPerSecond float64 `json:"blockspersecond"`
+		// Progress is the estimated sync progress, a fraction within the [0,1] range.
+		Progress float64 `json:"progress"`
+		// ETA is the estimated remaining time until the consensus set is synced,
+		// in seconds, 0 if synced or if it cannot be estimated yet.
+		ETA uint64 `json:"eta"`
+	}
+
 	// A ConsensusSet accepts blocks and builds an understanding of network
 	// consensus.
 	ConsensusSet interface {
@@ -195,6 +339,19 @@ type (
 		// described by the ConsensusChangeX variables in this package.
 		ConsensusSetSubscribe(ConsensusSetSubscriber, ConsensusChangeID, <-chan struct{}) error
 
+		// ConsensusSetSubscribeAsync behaves like ConsensusSetSubscribe,
+		// except that consensus changes following the initial catch-up are
+		// dispatched through a bounded queue, processed in a goroutine
+		// dedicated to the subscriber. This ensures that a slow subscriber
+		// does not delay block acceptance, within the limits of its queue.
+		// As the consensus set might have moved on by the time a change is processed,
+		// asynchronous subscribers should only use the data carried by the changes
+		// (or their own state), rather than look up the state of the consensus set.
+		ConsensusSetSubscribeAsync(ConsensusSetSubscriber, ConsensusChangeID, <-chan struct{}, AsyncSubscribeOptions) error
+
+		// SubscriberStats returns the dispatch metrics of all subscribers.
+		SubscriberStats() []SubscriberStats
+
 		// CurrentBlock returns the latest block in the heaviest known
 		// blockchain.
 		CurrentBlock() types.Block
@@ -238,9 +395,66 @@ type (
 
 		// GetBlockStakeOutput takes a blockstake output ID and returns the appropriate blockstake output
 		GetBlockStakeOutput(types.BlockStakeOutputID) (types.BlockStakeOutput, error)
+
+		// UnspentOutputsByUnlockHash returns all unspent coin and blockstake outputs
+		// locked by a condition with the given unlock hash, using the unlock hash index.
+		// ErrUnlockHashIndexDisabled is returned in case that index is disabled.
+		UnspentOutputsByUnlockHash(types.UnlockHash) (UnspentOutputs, error)
+
+		// BlockStatistics returns rolling statistics computed over the given
+		// amount of most recent blocks, DefaultBlockStatisticsWindow is used if it is 0.
+		BlockStatistics(window types.BlockHeight) (BlockStatistics, error)
+
+		// SyncProgress returns the progress of the synchronization with the network.
+		SyncProgress() SyncProgress
 	}
 )
 
+// ExpectedTimeToBlock returns the average time it takes to create a block,
+// using the given amount of blockstake, for the given difficulty.
+// As the difficulty is the amount of active blockstake times the block frequency,
+// this is the difficulty divided by the given blockstake, in seconds.
+func ExpectedTimeToBlock(difficulty types.Difficulty, blockStake types.Currency) time.Duration {
+	if blockStake.IsZero() {
+		return time.Duration(math.MaxInt64)
+	}
+	seconds, _ := new(big.Rat).SetFrac(difficulty.Big(), blockStake.Big()).Float64()
+	if seconds >= math.MaxInt64/float64(time.Second) {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// String returns the policy as a human-readable string.
+func (p SubscriberOverflowPolicy) String() string {
+	switch p {
+	case SubscriberOverflowBlock:
+		return "block"
+	case SubscriberOverflowDropOldest:
+		return "dropoldest"
+	case SubscriberOverflowUnsubscribe:
+		return "unsubscribe"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.MarshalText
+func (p SubscriberOverflowPolicy) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.UnmarshalText
+func (p *SubscriberOverflowPolicy) UnmarshalText(b []byte) error {
+	for policy := SubscriberOverflowBlock; policy <= SubscriberOverflowUnsubscribe; policy++ {
+		if policy.String() == string(b) {
+			*p = policy
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown subscriber overflow policy %q", string(b))
+}
+
 // Append takes to ConsensusChange objects and adds all of their diffs together.
 //
 // NOTE: It is possible for diffs to overlap or be inconsistent. This function
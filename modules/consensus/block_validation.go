@@ -2,9 +2,8 @@ package consensus
 
 import (
 	"errors"
-	"math/big"
+	"fmt"
 
-	"github.com/threefoldtech/rivine/crypto"
 	"github.com/threefoldtech/rivine/modules"
 	"github.com/threefoldtech/rivine/types"
 )
@@ -48,20 +47,8 @@ func newBlockValidator(consensusSet *ConsensusSet) stdBlockValidator {
 
 // checkTarget returns true if the block's ID meets the given target.
 func checkTarget(b types.Block, target types.Target, value types.Currency, height types.BlockHeight, cs *ConsensusSet) bool {
-
 	stakemodifier := cs.CalculateStakeModifier(height, b, cs.chainCts.StakeModifierDelay)
-
-	// Calculate the hash for the given unspent output and timestamp
-
-	pobshash := crypto.HashAll(stakemodifier.Bytes(), b.POBSOutput.BlockHeight, b.POBSOutput.TransactionIndex, b.POBSOutput.OutputIndex, b.Timestamp)
-	// Check if it meets the difficulty
-	pobshashvalue := big.NewInt(0).SetBytes(pobshash[:])
-	pobshashvalue.Div(pobshashvalue, value.Big()) //TODO rivine : this div can be mul on the other side of the compare
-
-	if pobshashvalue.Cmp(target.Int()) == -1 {
-		return true
-	}
-	return false
+	return cs.chainCts.POBSRules().MeetsTarget(stakemodifier, b.POBSOutput, value, b.Timestamp, target)
 }
 
 // ValidateBlock validates a block against a minimum timestamp, a block target,
@@ -137,19 +124,17 @@ func (bv stdBlockValidator) ValidateBlock(b types.Block, minTimestamp types.Time
 		return modules.ErrBlockUnsolved
 	}
 
-	// If the index of the unspent block stake output is not the first transaction
-	// with the first index, then block stake can only be used to solve blocks
-	// after its aging is older than types.BlockStakeAging (more than 1 day)
-	if ubsu.TransactionIndex != 0 || ubsu.OutputIndex != 0 {
-		BlockStakeAge := blockatheight.Header().Timestamp + types.Timestamp(bv.cs.chainCts.BlockStakeAging)
-		if BlockStakeAge > types.Timestamp(b.Header().Timestamp) {
-			return errBlockStakeAgeNotMet
-		}
+	// Check that the unspent block stake output is aged enough,
+	// as defined by the proof of blockstake rules.
+	if bv.cs.chainCts.POBSRules().MinimumBlockTimestamp(ubsu, blockatheight.Header().Timestamp) > b.Timestamp {
+		return errBlockStakeAgeNotMet
 	}
 
-	// Check that the block is below the size limit.
-	if uint64(len(bv.marshaler.Marshal(b))) > bv.cs.chainCts.BlockSizeLimit {
-		return errLargeBlock
+	// Check that the block is below the size limit,
+	// and valid according to the rules of the hard fork active at this height.
+	err := bv.validateBlockRules(b, height)
+	if err != nil {
+		return err
 	}
 
 	// Check if the block is in the extreme future. We make a distinction between
@@ -174,6 +159,24 @@ func (bv stdBlockValidator) ValidateBlock(b types.Block, minTimestamp types.Time
 	return nil
 }
 
+// validateBlockRules validates a block against the block rule set
+// of the hard fork that is active at the given block height,
+// using the encoding of that rule set to check the size of the block.
+func (bv stdBlockValidator) validateBlockRules(b types.Block, height types.BlockHeight) error {
+	rules := bv.cs.chainCts.BlockRuleSetAt(height)
+	encodedBlock, err := rules.MarshalBlock(b)
+	if err != nil {
+		return fmt.Errorf("failed to encode block: %v", err)
+	}
+	if uint64(len(encodedBlock)) > bv.cs.chainCts.BlockSizeLimit {
+		return errLargeBlock
+	}
+	return rules.ValidateBlock(b, types.BlockValidationContext{
+		BlockHeight:    height,
+		BlockSizeLimit: bv.cs.chainCts.BlockSizeLimit,
+	})
+}
+
 // checkMinerPayouts checks a block creator payouts to the block's subsidy and
 // returns true if they are equal.
 func (bv stdBlockValidator) checkMinerPayouts(b types.Block) bool {
@@ -279,6 +279,7 @@ func addCoinOutput(tx *bolt.Tx, id types.CoinOutputID, sco types.CoinOutput) {
 	if build.DEBUG && err != nil {
 		panic(err)
 	}
+	addUnlockHashIndexEntry(tx, sco.Condition, unlockHashIndexKey(unlockHashIndexCoinOutputPrefix, id[:]))
 }
 
 // removeCoinOutput removes a coin output from the database. An error is
@@ -289,6 +290,12 @@ func removeCoinOutput(tx *bolt.Tx, id types.CoinOutputID) {
 	if build.DEBUG && scoBucket.Get(id[:]) == nil {
 		panic("nil siacoin output")
 	}
+	if unlockHashIndexEnabled(tx) {
+		sco, err := getCoinOutput(tx, id)
+		if err == nil {
+			removeUnlockHashIndexEntry(tx, sco.Condition, unlockHashIndexKey(unlockHashIndexCoinOutputPrefix, id[:]))
+		}
+	}
 	err := scoBucket.Delete(id[:])
 	if build.DEBUG && err != nil {
 		panic(err)
@@ -327,6 +334,7 @@ func addBlockStakeOutput(tx *bolt.Tx, id types.BlockStakeOutputID, sfo types.Blo
 	if build.DEBUG && err != nil {
 		panic(err)
 	}
+	addUnlockHashIndexEntry(tx, sfo.Condition, unlockHashIndexKey(unlockHashIndexBlockStakeOutputPrefix, id[:]))
 }
 
 // removeBlockStakeOutput removes a blockstake output from the database. An error is
@@ -336,6 +344,12 @@ func removeBlockStakeOutput(tx *bolt.Tx, id types.BlockStakeOutputID) {
 	if build.DEBUG && sfoBucket.Get(id[:]) == nil {
 		panic("nil blockstake output")
 	}
+	if unlockHashIndexEnabled(tx) {
+		sfo, err := getBlockStakeOutput(tx, id)
+		if err == nil {
+			removeUnlockHashIndexEntry(tx, sfo.Condition, unlockHashIndexKey(unlockHashIndexBlockStakeOutputPrefix, id[:]))
+		}
+	}
 	err := sfoBucket.Delete(id[:])
 	if build.DEBUG && err != nil {
 		panic(err)
@@ -8,6 +8,7 @@ package consensus
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/threefoldtech/rivine/modules"
 	"github.com/threefoldtech/rivine/persist"
@@ -81,6 +82,9 @@ type ConsensusSet struct {
 	// whether the consensus set is synced with the network.
 	synced bool
 
+	// syncProgress keeps track of information used to report the sync progress.
+	syncProgress syncProgressTracker
+
 	// Interfaces to abstract the dependencies of the ConsensusSet.
 	marshaler       marshaler
 	blockRuleHelper blockRuleHelper
@@ -204,6 +208,21 @@ func New(gateway modules.Gateway, bootstrap bool, persistDir string, bcInfo type
 	return cs, nil
 }
 
+// NewWithGenesis returns a new ConsensusSet, like New, but using the given
+// genesis definition, instead of the genesis defined by the chain constants.
+// The genesis definition is validated for internal consistency first.
+//
+// Other modules derive the genesis block from the chain constants as well,
+// so use (*types.ChainConstants).SetGenesis directly in case
+// the constants are shared with other modules.
+func NewWithGenesis(gateway modules.Gateway, bootstrap bool, persistDir string, bcInfo types.BlockchainInfo, chainCts types.ChainConstants, genesis types.GenesisDefinition) (*ConsensusSet, error) {
+	err := chainCts.SetGenesis(genesis)
+	if err != nil {
+		return nil, fmt.Errorf("invalid genesis definition: %v", err)
+	}
+	return New(gateway, bootstrap, persistDir, bcInfo, chainCts)
+}
+
 // BlockAtHeight returns the block at a given height.
 func (cs *ConsensusSet) BlockAtHeight(height types.BlockHeight) (block types.Block, exists bool) {
 	_ = cs.db.View(func(tx *bolt.Tx) error {
@@ -427,4 +446,5 @@ func (cs *ConsensusSet) MinimumValidChildTimestamp(id types.BlockID) (timestamp
 
 var (
 	_ modules.ConsensusSet = (*ConsensusSet)(nil)
+	_ modules.Backupper    = (*ConsensusSet)(nil)
 )
@@ -0,0 +1,227 @@
+package consensus
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/threefoldtech/rivine/modules"
+)
+
+var (
+	errInvalidSubscriberQueueSize = errors.New("async subscriber queue size cannot be negative")
+	errUnknownOverflowPolicy      = errors.New("unknown async subscriber overflow policy")
+)
+
+type (
+	// asyncSubscriber wraps a ConsensusSetSubscriber,
+	// such that consensus changes are queued and delivered
+	// in a goroutine dedicated to that subscriber.
+	asyncSubscriber struct {
+		subscriber modules.ConsensusSetSubscriber
+		policy     modules.SubscriberOverflowPolicy
+		size       int
+
+		queue []queuedChange
+		// cond is signalled each time a change is queued or dequeued,
+		// as well as when the subscriber is stopped
+		cond *sync.Cond
+		mu   sync.Mutex
+
+		delivered    uint64
+		dropped      uint64
+		lastLag      time.Duration
+		maxLag       time.Duration
+		stopped      bool
+		disconnected bool
+
+		done chan struct{}
+	}
+
+	// queuedChange is a consensus change pending for an async subscriber,
+	// along with the time it was dispatched, used to compute the lag.
+	queuedChange struct {
+		cc         modules.ConsensusChange
+		dispatched time.Time
+	}
+)
+
+// newAsyncSubscriber creates a new async subscriber,
+// and starts its delivery goroutine.
+func newAsyncSubscriber(subscriber modules.ConsensusSetSubscriber, opts modules.AsyncSubscribeOptions) (*asyncSubscriber, error) {
+	if opts.QueueSize < 0 {
+		return nil, errInvalidSubscriberQueueSize
+	}
+	if opts.QueueSize == 0 {
+		opts.QueueSize = modules.DefaultSubscriberQueueSize
+	}
+	switch opts.OverflowPolicy {
+	case modules.SubscriberOverflowBlock, modules.SubscriberOverflowDropOldest, modules.SubscriberOverflowUnsubscribe:
+	default:
+		return nil, errUnknownOverflowPolicy
+	}
+	as := &asyncSubscriber{
+		subscriber: subscriber,
+		policy:     opts.OverflowPolicy,
+		size:       opts.QueueSize,
+		queue:      make([]queuedChange, 0, opts.QueueSize),
+		done:       make(chan struct{}),
+	}
+	as.cond = sync.NewCond(&as.mu)
+	go as.threadedDeliver()
+	return as, nil
+}
+
+// ProcessConsensusChange implements modules.ConsensusSetSubscriber,
+// queueing the change according to the overflow policy of the subscriber.
+func (as *asyncSubscriber) ProcessConsensusChange(cc modules.ConsensusChange) {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+	if as.stopped {
+		return
+	}
+	if len(as.queue) >= as.size {
+		switch as.policy {
+		case modules.SubscriberOverflowBlock:
+			for len(as.queue) >= as.size && !as.stopped {
+				as.cond.Wait()
+			}
+			if as.stopped {
+				return
+			}
+		case modules.SubscriberOverflowDropOldest:
+			as.queue = as.queue[1:]
+			as.dropped++
+		case modules.SubscriberOverflowUnsubscribe:
+			as.dropped += uint64(len(as.queue)) + 1
+			as.queue = nil
+			as.disconnected = true
+			as.stopped = true
+			as.cond.Broadcast()
+			return
+		}
+	}
+	as.queue = append(as.queue, queuedChange{
+		cc:         cc,
+		dispatched: time.Now(),
+	})
+	as.cond.Broadcast()
+}
+
+// threadedDeliver delivers all queued changes, in order,
+// until the subscriber is stopped.
+func (as *asyncSubscriber) threadedDeliver() {
+	defer close(as.done)
+	for {
+		as.mu.Lock()
+		for len(as.queue) == 0 && !as.stopped {
+			as.cond.Wait()
+		}
+		if as.stopped {
+			as.mu.Unlock()
+			return
+		}
+		qc := as.queue[0]
+		as.queue = as.queue[1:]
+		as.cond.Broadcast()
+		as.mu.Unlock()
+
+		as.subscriber.ProcessConsensusChange(qc.cc)
+
+		lag := time.Since(qc.dispatched)
+		as.mu.Lock()
+		as.delivered++
+		as.lastLag = lag
+		if lag > as.maxLag {
+			as.maxLag = lag
+		}
+		as.mu.Unlock()
+	}
+}
+
+// stop stops the delivery goroutine, dropping all pending changes,
+// blocking until a change that might be processed, has finished.
+func (as *asyncSubscriber) stop() {
+	as.mu.Lock()
+	as.stopped = true
+	as.cond.Broadcast()
+	as.mu.Unlock()
+	<-as.done
+}
+
+// stats returns the current dispatch metrics of the subscriber.
+func (as *asyncSubscriber) stats() modules.SubscriberStats {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+	return modules.SubscriberStats{
+		Name:           subscriberName(as.subscriber),
+		Async:          true,
+		OverflowPolicy: as.policy,
+		QueueSize:      as.size,
+		QueueLength:    len(as.queue),
+		Delivered:      as.delivered,
+		Dropped:        as.dropped,
+		LastLag:        as.lastLag,
+		MaxLag:         as.maxLag,
+		Disconnected:   as.disconnected,
+	}
+}
+
+// subscriberName returns the name of the (Go) type of the subscriber.
+func subscriberName(subscriber modules.ConsensusSetSubscriber) string {
+	return fmt.Sprintf("%T", subscriber)
+}
+
+// ConsensusSetSubscribeAsync adds a subscriber to the list of subscribers,
+// and gives them every consensus change that has occurred since the change with
+// the provided id. The catch-up is delivered synchronously,
+// while all consensus changes that follow are dispatched through
+// a bounded queue, processed in a goroutine dedicated to the subscriber.
+func (cs *ConsensusSet) ConsensusSetSubscribeAsync(subscriber modules.ConsensusSetSubscriber, start modules.ConsensusChangeID, cancel <-chan struct{}, opts modules.AsyncSubscribeOptions) error {
+	as, err := newAsyncSubscriber(subscriber, opts)
+	if err != nil {
+		return err
+	}
+	err = cs.tg.Add()
+	if err != nil {
+		as.stop()
+		return err
+	}
+	defer cs.tg.Done()
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	// Get the input module caught up to the current consensus set,
+	// this is done synchronously, so the catch-up does not count towards the queue.
+	err = cs.initializeSubscribe(subscriber, start, cancel)
+	if err != nil {
+		as.stop()
+		return err
+	}
+	cs.subscribers = append(cs.subscribers, as)
+	cs.tg.OnStop(as.stop)
+	return nil
+}
+
+// SubscriberStats returns the dispatch metrics of all subscribers.
+func (cs *ConsensusSet) SubscriberStats() []modules.SubscriberStats {
+	if cs.tg.Add() != nil {
+		return nil
+	}
+	defer cs.tg.Done()
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	stats := make([]modules.SubscriberStats, 0, len(cs.subscribers))
+	for _, subscriber := range cs.subscribers {
+		if as, ok := subscriber.(*asyncSubscriber); ok {
+			stats = append(stats, as.stats())
+			continue
+		}
+		stats = append(stats, modules.SubscriberStats{
+			Name: subscriberName(subscriber),
+		})
+	}
+	return stats
+}
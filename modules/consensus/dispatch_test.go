@@ -0,0 +1,145 @@
+package consensus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/threefoldtech/rivine/modules"
+)
+
+// blockingSubscriber is a subscriber which blocks on every change,
+// until it is released, remembering the IDs of all changes received.
+type blockingSubscriber struct {
+	release  chan struct{}
+	received chan modules.ConsensusChangeID
+}
+
+func newBlockingSubscriber() *blockingSubscriber {
+	return &blockingSubscriber{
+		release:  make(chan struct{}),
+		received: make(chan modules.ConsensusChangeID, 16),
+	}
+}
+
+func (bs *blockingSubscriber) ProcessConsensusChange(cc modules.ConsensusChange) {
+	<-bs.release
+	bs.received <- cc.ID
+}
+
+func (bs *blockingSubscriber) expect(t *testing.T, ids ...modules.ConsensusChangeID) {
+	for _, id := range ids {
+		select {
+		case received := <-bs.received:
+			if received != id {
+				t.Fatalf("expected change %v, received %v", id, received)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout while waiting for change %v", id)
+		}
+	}
+}
+
+func TestAsyncSubscriberInvalidOptions(t *testing.T) {
+	_, err := newAsyncSubscriber(newBlockingSubscriber(), modules.AsyncSubscribeOptions{QueueSize: -1})
+	if err != errInvalidSubscriberQueueSize {
+		t.Error("unexpected error:", err)
+	}
+	_, err = newAsyncSubscriber(newBlockingSubscriber(), modules.AsyncSubscribeOptions{OverflowPolicy: 42})
+	if err != errUnknownOverflowPolicy {
+		t.Error("unexpected error:", err)
+	}
+}
+
+func TestAsyncSubscriberDropOldest(t *testing.T) {
+	bs := newBlockingSubscriber()
+	as, err := newAsyncSubscriber(bs, modules.AsyncSubscribeOptions{
+		QueueSize:      2,
+		OverflowPolicy: modules.SubscriberOverflowDropOldest,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer as.stop()
+
+	// the first change is picked up by the delivery goroutine,
+	// wait until it is no longer in the queue
+	as.ProcessConsensusChange(modules.ConsensusChange{ID: modules.ConsensusChangeID{1}})
+	for as.stats().QueueLength != 0 {
+		time.Sleep(time.Millisecond)
+	}
+	for i := byte(2); i <= 4; i++ {
+		as.ProcessConsensusChange(modules.ConsensusChange{ID: modules.ConsensusChangeID{i}})
+	}
+	stats := as.stats()
+	if stats.QueueLength != 2 || stats.Dropped != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+
+	close(bs.release)
+	bs.expect(t, modules.ConsensusChangeID{1}, modules.ConsensusChangeID{3}, modules.ConsensusChangeID{4})
+}
+
+func TestAsyncSubscriberUnsubscribe(t *testing.T) {
+	bs := newBlockingSubscriber()
+	as, err := newAsyncSubscriber(bs, modules.AsyncSubscribeOptions{
+		QueueSize:      1,
+		OverflowPolicy: modules.SubscriberOverflowUnsubscribe,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer as.stop()
+
+	as.ProcessConsensusChange(modules.ConsensusChange{ID: modules.ConsensusChangeID{1}})
+	for as.stats().QueueLength != 0 {
+		time.Sleep(time.Millisecond)
+	}
+	as.ProcessConsensusChange(modules.ConsensusChange{ID: modules.ConsensusChangeID{2}})
+	as.ProcessConsensusChange(modules.ConsensusChange{ID: modules.ConsensusChangeID{3}})
+	stats := as.stats()
+	if !stats.Disconnected || stats.Dropped != 2 || stats.QueueLength != 0 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	close(bs.release)
+	bs.expect(t, modules.ConsensusChangeID{1})
+}
+
+func TestAsyncSubscriberBlock(t *testing.T) {
+	bs := newBlockingSubscriber()
+	as, err := newAsyncSubscriber(bs, modules.AsyncSubscribeOptions{
+		QueueSize:      1,
+		OverflowPolicy: modules.SubscriberOverflowBlock,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer as.stop()
+
+	as.ProcessConsensusChange(modules.ConsensusChange{ID: modules.ConsensusChangeID{1}})
+	for as.stats().QueueLength != 0 {
+		time.Sleep(time.Millisecond)
+	}
+	as.ProcessConsensusChange(modules.ConsensusChange{ID: modules.ConsensusChangeID{2}})
+	dispatched := make(chan struct{})
+	go func() {
+		as.ProcessConsensusChange(modules.ConsensusChange{ID: modules.ConsensusChangeID{3}})
+		close(dispatched)
+	}()
+	select {
+	case <-dispatched:
+		t.Fatal("dispatch should block while the queue is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(bs.release)
+	bs.expect(t, modules.ConsensusChangeID{1}, modules.ConsensusChangeID{2}, modules.ConsensusChangeID{3})
+	<-dispatched
+	if stats := as.stats(); stats.Dropped != 0 || stats.Delivered != 3 {
+		// delivered is updated after the change was processed,
+		// so give it a moment
+		time.Sleep(10 * time.Millisecond)
+		if stats = as.stats(); stats.Dropped != 0 || stats.Delivered != 3 {
+			t.Fatalf("unexpected stats: %+v", stats)
+		}
+	}
+}
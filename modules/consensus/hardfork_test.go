@@ -0,0 +1,268 @@
+package consensus
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/threefoldtech/rivine/build"
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+	"github.com/threefoldtech/rivine/types"
+)
+
+var (
+	errNoArbitraryData   = errors.New("arbitrary data is no longer allowed")
+	errTooManyTxns       = errors.New("block has too many transactions")
+	errNoMinerPayouts    = errors.New("miner payouts are no longer allowed")
+	testPayoutUnlockHash = types.UnlockHash{Type: types.UnlockTypePubKey, Hash: crypto.Hash{1}}
+)
+
+// noArbitraryDataRuleSet is a test rule set, which disallows arbitrary data,
+// optionally limits the amount of transactions a block can have,
+// and optionally encodes blocks with some padding, such that they are bigger.
+type noArbitraryDataRuleSet struct {
+	types.DefaultBlockRuleSet
+	maxTransactions int
+	padding         int
+}
+
+func (rs noArbitraryDataRuleSet) MarshalBlock(b types.Block) ([]byte, error) {
+	return append(siabin.Marshal(b), make([]byte, rs.padding)...), nil
+}
+
+func (rs noArbitraryDataRuleSet) ValidateBlock(b types.Block, ctx types.BlockValidationContext) error {
+	if rs.maxTransactions > 0 && len(b.Transactions) > rs.maxTransactions {
+		return errTooManyTxns
+	}
+	for _, txn := range b.Transactions {
+		if len(txn.ArbitraryData) > 0 {
+			return errNoArbitraryData
+		}
+	}
+	return nil
+}
+
+// TestValidateBlockRulesAcrossForks replays the same blocks
+// at heights prior to, at and after the hard fork boundaries.
+func TestValidateBlockRulesAcrossForks(t *testing.T) {
+	cts := types.TestnetChainConstants()
+	cts.HardForks = []types.HardFork{
+		{Name: "noarbitrarydata", Height: 100, Rules: noArbitraryDataRuleSet{}},
+		{Name: "singletransaction", Height: 200, Rules: noArbitraryDataRuleSet{maxTransactions: 1}},
+		{Name: "padding", Height: 300, Rules: noArbitraryDataRuleSet{padding: int(cts.BlockSizeLimit)}},
+	}
+	if err := cts.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	bv := stdBlockValidator{marshaler: stdMarshaler{}, cs: &ConsensusSet{chainCts: cts}}
+
+	plainBlock := types.Block{Transactions: []types.Transaction{{Version: types.TransactionVersionOne}}}
+	dataBlock := types.Block{Transactions: []types.Transaction{{
+		Version:       types.TransactionVersionOne,
+		ArbitraryData: []byte("data"),
+	}}}
+	doubleBlock := types.Block{Transactions: []types.Transaction{
+		{Version: types.TransactionVersionOne},
+		{Version: types.TransactionVersionOne},
+	}}
+	largeBlock := types.Block{Transactions: []types.Transaction{{
+		Version:       types.TransactionVersionOne,
+		ArbitraryData: make([]byte, cts.BlockSizeLimit),
+	}}}
+
+	testCases := []struct {
+		block  types.Block
+		height types.BlockHeight
+		err    error
+	}{
+		{plainBlock, 99, nil},
+		{dataBlock, 99, nil},
+		{largeBlock, 99, errLargeBlock},
+		{plainBlock, 100, nil},
+		{dataBlock, 100, errNoArbitraryData},
+		{dataBlock, 199, errNoArbitraryData},
+		{plainBlock, 199, nil},
+		{doubleBlock, 199, nil},
+		{plainBlock, 200, nil},
+		{doubleBlock, 200, errTooManyTxns},
+		{doubleBlock, 201, errTooManyTxns},
+		{largeBlock, 201, errLargeBlock},
+		// blocks are checked against the size limit using the encoding of the fork
+		{plainBlock, 299, nil},
+		{plainBlock, 300, errLargeBlock},
+		{plainBlock, 301, errLargeBlock},
+	}
+	for idx, tc := range testCases {
+		err := bv.validateBlockRules(tc.block, tc.height)
+		if err != tc.err {
+			t.Errorf("test case #%d (height %d): unexpected error: %v (expected %v)", idx, tc.height, err, tc.err)
+		}
+	}
+}
+
+// noMinerPayoutsRuleSet is a test rule set, which disallows miner payouts.
+type noMinerPayoutsRuleSet struct {
+	types.DefaultBlockRuleSet
+}
+
+func (noMinerPayoutsRuleSet) ValidateBlock(b types.Block, ctx types.BlockValidationContext) error {
+	if len(b.MinerPayouts) > 0 {
+		return errNoMinerPayouts
+	}
+	return nil
+}
+
+// hardForkBlockValidator only validates the timestamp and the fork-specific rules of a block,
+// skipping the proof of blockstake, as no block creator is available to solve blocks in this test.
+type hardForkBlockValidator struct {
+	stdBlockValidator
+}
+
+func (bv hardForkBlockValidator) ValidateBlock(b types.Block, minTimestamp types.Timestamp, target types.Target, height types.BlockHeight) error {
+	if minTimestamp > b.Timestamp {
+		return errEarlyTimestamp
+	}
+	return bv.validateBlockRules(b, height)
+}
+
+// hardForkGateway is a gateway without any peers.
+type hardForkGateway struct {
+	modules.Gateway
+}
+
+func (hardForkGateway) Peers() []modules.Peer                         { return nil }
+func (hardForkGateway) Broadcast(string, interface{}, []modules.Peer) {}
+func (hardForkGateway) RegisterRPC(string, modules.RPCFunc)           {}
+func (hardForkGateway) UnregisterRPC(string)                          {}
+func (hardForkGateway) RegisterConnectCall(string, modules.RPCFunc)   {}
+func (hardForkGateway) UnregisterConnectCall(string)                  {}
+
+// appliedBlocksSubscriber remembers the IDs of all applied blocks.
+type appliedBlocksSubscriber struct {
+	ids []types.BlockID
+}
+
+func (s *appliedBlocksSubscriber) ProcessConsensusChange(cc modules.ConsensusChange) {
+	for _, block := range cc.RevertedBlocks {
+		if len(s.ids) > 0 && s.ids[len(s.ids)-1] == block.ID() {
+			s.ids = s.ids[:len(s.ids)-1]
+		}
+	}
+	for _, block := range cc.AppliedBlocks {
+		s.ids = append(s.ids, block.ID())
+	}
+}
+
+// newHardForkConsensusSet creates a consensus set for the given chain constants,
+// persisted in the given directory, validating blocks using the hardForkBlockValidator.
+func newHardForkConsensusSet(t *testing.T, cts types.ChainConstants, dir string) *ConsensusSet {
+	cs, err := New(hardForkGateway{}, false, dir, types.DefaultBlockchainInfo(), cts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs.blockValidator = hardForkBlockValidator{newBlockValidator(cs)}
+	return cs
+}
+
+// TestAcceptBlocksAcrossForks applies blocks on both sides of a hard fork height,
+// and ensures the same blocks are loaded and replayed after a restart.
+func TestAcceptBlocksAcrossForks(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	cts := types.DevnetChainConstants()
+	cts.HardForks = []types.HardFork{
+		{Name: "nominerpayouts", Height: 3, Rules: noMinerPayoutsRuleSet{}},
+	}
+	if err := cts.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	testdir := build.TempDir(modules.ConsensusDir, t.Name())
+	os.RemoveAll(testdir)
+	dir := filepath.Join(testdir, "original")
+	cs := newHardForkConsensusSet(t, cts, dir)
+
+	// childBlock creates a child of the current block,
+	// paying the block creator if requested
+	childBlock := func(cs *ConsensusSet, pay bool) types.Block {
+		parent := cs.CurrentBlock()
+		b := types.Block{
+			ParentID:  parent.ID(),
+			Timestamp: parent.Timestamp + 10,
+		}
+		if pay {
+			b.MinerPayouts = []types.MinerPayout{{Value: cts.BlockCreatorFee, UnlockHash: testPayoutUnlockHash}}
+		}
+		return b
+	}
+
+	// miner payouts are allowed prior to the fork only
+	for height, pay := range []bool{true, true} {
+		if err := cs.AcceptBlock(childBlock(cs, pay)); err != nil {
+			t.Fatalf("block at height %d: %v", height+1, err)
+		}
+	}
+	if err := cs.AcceptBlock(childBlock(cs, true)); err != errNoMinerPayouts {
+		t.Fatal("expected block paying the block creator to be rejected at the fork height, got:", err)
+	}
+	for height := types.BlockHeight(3); height <= 4; height++ {
+		if err := cs.AcceptBlock(childBlock(cs, false)); err != nil {
+			t.Fatalf("block at height %d: %v", height, err)
+		}
+	}
+	if height := cs.Height(); height != 4 {
+		t.Fatal("unexpected height:", height)
+	}
+	var ids []types.BlockID
+	for height := types.BlockHeight(0); height <= 4; height++ {
+		b, ok := cs.BlockAtHeight(height)
+		if !ok {
+			t.Fatal("missing block at height", height)
+		}
+		ids = append(ids, b.ID())
+	}
+	if err := cs.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the blocks on both sides of the fork are loaded after a restart,
+	// and replayed to subscribers
+	cs = newHardForkConsensusSet(t, cts, dir)
+	defer cs.Close()
+	if height := cs.Height(); height != 4 {
+		t.Fatal("unexpected height after restart:", height)
+	}
+	var subscriber appliedBlocksSubscriber
+	if err := cs.ConsensusSetSubscribe(&subscriber, modules.ConsensusChangeBeginning, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(subscriber.ids) != len(ids) {
+		t.Fatalf("expected %d blocks to be replayed, got %d", len(ids), len(subscriber.ids))
+	}
+	for height, id := range ids {
+		if subscriber.ids[height] != id {
+			t.Errorf("block at height %d: replayed %v, expected %v", height, subscriber.ids[height], id)
+		}
+	}
+
+	// the fork rules still apply after a restart
+	if err := cs.AcceptBlock(childBlock(cs, true)); err != errNoMinerPayouts {
+		t.Fatal("expected block paying the block creator to be rejected after the fork height, got:", err)
+	}
+
+	// the loaded blocks are accepted by a fresh consensus set as well
+	replay := newHardForkConsensusSet(t, cts, filepath.Join(testdir, "replay"))
+	defer replay.Close()
+	for height := types.BlockHeight(1); height <= 4; height++ {
+		b, _ := cs.BlockAtHeight(height)
+		if err := replay.AcceptBlock(b); err != nil {
+			t.Fatalf("replaying block at height %d: %v", height, err)
+		}
+	}
+	if id := replay.CurrentBlock().ID(); id != ids[4] {
+		t.Fatalf("unexpected current block after replay: %v (expected %v)", id, ids[4])
+	}
+}
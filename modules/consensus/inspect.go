@@ -0,0 +1,54 @@
+package consensus
+
+import (
+	"bytes"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+	"github.com/threefoldtech/rivine/types"
+
+	"github.com/rivine/bbolt"
+)
+
+// InspectDatabase implements modules.DatabaseInspector.InspectDatabase,
+// calling the given function within a read transaction.
+func (cs *ConsensusSet) InspectDatabase(fn func(*bolt.Tx) error) error {
+	err := cs.tg.Add()
+	if err != nil {
+		return err
+	}
+	defer cs.tg.Done()
+	return cs.db.View(fn)
+}
+
+// DecodeDatabaseValue implements modules.DatabaseInspector.DecodeDatabaseValue,
+// decoding the values of the consensus buckets.
+func (cs *ConsensusSet) DecodeDatabaseValue(bucket [][]byte, key, value []byte) (interface{}, bool) {
+	if len(bucket) != 1 {
+		return nil, false
+	}
+	var v interface{}
+	switch name := bucket[0]; {
+	case bytes.Equal(name, BlockHeight):
+		v = new(types.BlockHeight)
+	case bytes.Equal(name, BlockMap):
+		v = new(processedBlock)
+	case bytes.Equal(name, BlockPath):
+		v = new(types.BlockID)
+	case bytes.Equal(name, CoinOutputs), bytes.HasPrefix(name, prefixDCO):
+		v = new(types.CoinOutput)
+	case bytes.Equal(name, BlockStakeOutputs):
+		v = new(types.BlockStakeOutput)
+	case bytes.Equal(name, TransactionIDMap):
+		v = new(types.TransactionShortID)
+	default:
+		return nil, false
+	}
+	if err := siabin.Unmarshal(value, v); err != nil {
+		return nil, false
+	}
+	return v, true
+}
+
+// enforce that the database of the ConsensusSet can be inspected while running
+var _ modules.DatabaseInspector = (*ConsensusSet)(nil)
@@ -0,0 +1,28 @@
+package consensus
+
+import (
+	"path/filepath"
+
+	"github.com/threefoldtech/rivine/persist"
+)
+
+// dbMigrations are the migrations of the consensus database,
+// a migration from the previous version has to be added
+// each time the version of dbMetadata is bumped.
+// The optional unlock hash index requires no migration,
+// as it is created on demand, see SetUnlockHashIndex.
+var dbMigrations []persist.Migration
+
+// MigrateDatabase migrates the consensus database, stored in the given persist directory,
+// to the version used by this consensus module, returning the applied migrations.
+// It has to be called prior to creating the consensus set.
+func MigrateDatabase(persistDir string, opts persist.MigrationOptions) ([]persist.Migration, error) {
+	return persist.MigrateDatabase(dbMetadata, filepath.Join(persistDir, DatabaseFilename), dbMigrations, opts)
+}
+
+// CompactDatabase compacts the consensus database, stored in the given persist directory,
+// reclaiming the space of the pages freed over time.
+// It has to be called prior to creating the consensus set.
+func CompactDatabase(persistDir string) (persist.DatabaseCompaction, error) {
+	return persist.CompactDatabase(filepath.Join(persistDir, DatabaseFilename))
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 
 	"github.com/threefoldtech/rivine/build"
@@ -93,3 +94,14 @@ func (cs *ConsensusSet) initPersist() error {
 	})
 	return nil
 }
+
+// Backup implements modules.Backupper.Backup,
+// copying the database within a read transaction.
+func (cs *ConsensusSet) Backup(bw persist.BackupWriter) error {
+	err := cs.tg.Add()
+	if err != nil {
+		return err
+	}
+	defer cs.tg.Done()
+	return cs.db.Backup(bw, path.Join(modules.ConsensusDir, DatabaseFilename))
+}
@@ -0,0 +1,97 @@
+package consensus
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+
+	"github.com/rivine/bbolt"
+)
+
+var (
+	errNoBlockStatistics = errors.New("no block statistics are available, as only the genesis block is known")
+)
+
+// BlockStatistics returns rolling statistics computed over the given
+// amount of most recent blocks of the current path,
+// modules.DefaultBlockStatisticsWindow is used if it is 0.
+// The window is capped to the current height.
+func (cs *ConsensusSet) BlockStatistics(window types.BlockHeight) (stats modules.BlockStatistics, err error) {
+	err = cs.tg.Add()
+	if err != nil {
+		return
+	}
+	defer cs.tg.Done()
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	if window == 0 {
+		window = modules.DefaultBlockStatisticsWindow
+	}
+	err = cs.db.View(func(tx *bolt.Tx) error {
+		height := blockHeight(tx)
+		if height == 0 {
+			return errNoBlockStatistics
+		}
+		if window > height {
+			window = height
+		}
+		current, err := getBlockAtHeight(tx, height)
+		if err != nil {
+			return err
+		}
+		start, err := getBlockAtHeight(tx, height-window)
+		if err != nil {
+			return err
+		}
+		stats = computeBlockStatistics(start, current, cs.chainCts)
+		return nil
+	})
+	return
+}
+
+// getBlockAtHeight returns the processed block at the given height of the current path.
+func getBlockAtHeight(tx *bolt.Tx, height types.BlockHeight) (*processedBlock, error) {
+	id, err := getPath(tx, height)
+	if err != nil {
+		return nil, err
+	}
+	return getBlockMap(tx, id)
+}
+
+// computeBlockStatistics computes the block statistics,
+// over the blocks between (and including) the given start and current block.
+func computeBlockStatistics(start, current *processedBlock, chainCts types.ChainConstants) modules.BlockStatistics {
+	window := current.Height - start.Height
+	stats := modules.BlockStatistics{
+		Height:                current.Height,
+		Window:                window,
+		TargetBlockTime:       chainCts.BlockFrequency,
+		Difficulty:            current.ChildTarget.Difficulty(chainCts.RootDepth),
+		WindowStartDifficulty: start.ChildTarget.Difficulty(chainCts.RootDepth),
+	}
+	if window == 0 {
+		return stats
+	}
+
+	// timestamps are not guaranteed to be increasing,
+	// a negative average is possible, albeit unlikely, for small windows
+	timespan := int64(current.Block.Timestamp) - int64(start.Block.Timestamp)
+	stats.AverageBlockTime = float64(timespan) / float64(window)
+
+	startDifficulty := stats.WindowStartDifficulty.Big()
+	if startDifficulty.Sign() != 0 {
+		trend := new(big.Rat).SetFrac(new(big.Int).Sub(stats.Difficulty.Big(), startDifficulty), startDifficulty)
+		stats.DifficultyTrend, _ = trend.Float64()
+	}
+
+	// the difficulty is the amount of active blockstake times the block interval
+	if timespan > 0 {
+		stake := new(big.Int).Mul(stats.Difficulty.Big(), big.NewInt(int64(window)))
+		stake.Div(stake, big.NewInt(timespan))
+		stats.EstimatedActiveBlockStake = types.NewCurrency(stake)
+	}
+	return stats
+}
@@ -0,0 +1,55 @@
+package consensus
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+func TestComputeBlockStatistics(t *testing.T) {
+	cts := types.TestnetChainConstants()
+	startDifficulty := types.NewDifficulty(big.NewInt(1000000))
+	currentDifficulty := types.NewDifficulty(big.NewInt(1100000))
+	start := &processedBlock{
+		Block:       types.Block{Timestamp: 1500000000},
+		Height:      50,
+		ChildTarget: types.NewTarget(startDifficulty, cts.RootDepth),
+	}
+	current := &processedBlock{
+		Block:       types.Block{Timestamp: 1500000000 + 100*110},
+		Height:      150,
+		ChildTarget: types.NewTarget(currentDifficulty, cts.RootDepth),
+	}
+
+	stats := computeBlockStatistics(start, current, cts)
+	if stats.Height != 150 || stats.Window != 100 || stats.TargetBlockTime != cts.BlockFrequency {
+		t.Fatalf("unexpected statistics: %+v", stats)
+	}
+	if stats.AverageBlockTime != 110 {
+		t.Error("unexpected average block time:", stats.AverageBlockTime)
+	}
+	if stats.Difficulty.Cmp(currentDifficulty) != 0 || stats.WindowStartDifficulty.Cmp(startDifficulty) != 0 {
+		t.Errorf("unexpected difficulties: %v -> %v", stats.WindowStartDifficulty, stats.Difficulty)
+	}
+	if stats.DifficultyTrend < 0.099 || stats.DifficultyTrend > 0.101 {
+		t.Error("unexpected difficulty trend:", stats.DifficultyTrend)
+	}
+	if !stats.EstimatedActiveBlockStake.Equals64(10000) {
+		t.Error("unexpected active blockstake estimate:", stats.EstimatedActiveBlockStake)
+	}
+
+	// the expected time to create a block with all active blockstake
+	// should equal the average block time
+	if d := modules.ExpectedTimeToBlock(stats.Difficulty, stats.EstimatedActiveBlockStake); d != 110*time.Second {
+		t.Error("unexpected time to block:", d)
+	}
+
+	// a window of zero blocks has no averages
+	stats = computeBlockStatistics(current, current, cts)
+	if stats.Window != 0 || stats.AverageBlockTime != 0 || !stats.EstimatedActiveBlockStake.IsZero() {
+		t.Fatalf("unexpected statistics: %+v", stats)
+	}
+}
@@ -183,15 +183,32 @@ func (cs *ConsensusSet) Unsubscribe(subscriber modules.ConsensusSetSubscriber) {
 		return
 	}
 	defer cs.tg.Done()
+	as := cs.managedRemoveSubscriber(subscriber)
+	if as != nil {
+		// stop the async subscriber outside of the lock,
+		// as it might be waiting for a consensus lock while processing a change
+		as.stop()
+	}
+}
+
+// managedRemoveSubscriber removes the subscriber from the list of subscribers,
+// returning its async wrapper in case it is an asynchronous subscriber.
+func (cs *ConsensusSet) managedRemoveSubscriber(subscriber modules.ConsensusSetSubscriber) *asyncSubscriber {
 	cs.mu.Lock()
 	defer cs.mu.Unlock()
 
 	// Search for the subscriber in the list of subscribers and remove it if
-	// found.
+	// found. Asynchronous subscribers are wrapped, and are matched by their
+	// wrapped subscriber.
 	for i := range cs.subscribers {
 		if cs.subscribers[i] == subscriber {
 			cs.subscribers = append(cs.subscribers[0:i], cs.subscribers[i+1:]...)
-			break
+			return nil
+		}
+		if as, ok := cs.subscribers[i].(*asyncSubscriber); ok && as.subscriber == subscriber {
+			cs.subscribers = append(cs.subscribers[0:i], cs.subscribers[i+1:]...)
+			return as
 		}
 	}
+	return nil
 }
@@ -368,7 +368,16 @@ func (cs *ConsensusSet) threadedRPCRelayHeader(conn modules.PeerConn) error {
 	cs.mu.RLock()
 	err = cs.db.View(func(tx *bolt.Tx) error {
 		// Do some relatively inexpensive checks to validate the header
-		return cs.validateHeader(boltTxWrapper{tx}, h)
+		err := cs.validateHeader(boltTxWrapper{tx}, h)
+		if err != nil {
+			return err
+		}
+		// register the height of the header, used to estimate the network height
+		parent, err := getBlockMap(tx, h.ParentID)
+		if err == nil {
+			cs.syncProgress.registerPeerHeight(parent.Height + 1)
+		}
+		return nil
 	})
 	cs.mu.RUnlock()
 	if err == errOrphan {
@@ -504,6 +513,7 @@ func (cs *ConsensusSet) threadedInitialBlockchainDownload() error {
 	}
 	height := getHeight()
 	lastReceiveTime := time.Now()
+	cs.syncProgress.startIBD(height)
 
 	for {
 		numOutboundSynced = 0
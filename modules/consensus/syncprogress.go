@@ -0,0 +1,122 @@
+package consensus
+
+import (
+	"sync"
+	"time"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+
+	"github.com/rivine/bbolt"
+)
+
+// syncProgressTracker keeps track of the information required to report
+// the sync progress, which is collected outside of the consensus set lock.
+type syncProgressTracker struct {
+	mu sync.Mutex
+	// ibdStartTime and ibdStartHeight are the time and height
+	// at which the initial blockchain download started.
+	ibdStartTime   time.Time
+	ibdStartHeight types.BlockHeight
+	// peerHeight is the highest height of a header relayed by a peer.
+	peerHeight types.BlockHeight
+}
+
+// startIBD marks the start of the initial blockchain download.
+func (spt *syncProgressTracker) startIBD(height types.BlockHeight) {
+	spt.mu.Lock()
+	spt.ibdStartTime = time.Now()
+	spt.ibdStartHeight = height
+	spt.mu.Unlock()
+}
+
+// registerPeerHeight registers the height of a header relayed by a peer.
+func (spt *syncProgressTracker) registerPeerHeight(height types.BlockHeight) {
+	spt.mu.Lock()
+	if height > spt.peerHeight {
+		spt.peerHeight = height
+	}
+	spt.mu.Unlock()
+}
+
+// SyncProgress returns the progress of the synchronization with the network.
+func (cs *ConsensusSet) SyncProgress() modules.SyncProgress {
+	err := cs.tg.Add()
+	if err != nil {
+		return modules.SyncProgress{}
+	}
+	defer cs.tg.Done()
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	var (
+		height    types.BlockHeight
+		timestamp types.Timestamp
+	)
+	_ = cs.db.View(func(tx *bolt.Tx) error {
+		height = blockHeight(tx)
+		timestamp, err = blockTimeStamp(tx, height)
+		return err
+	})
+
+	cs.syncProgress.mu.Lock()
+	defer cs.syncProgress.mu.Unlock()
+	return computeSyncProgress(syncProgressState{
+		synced:           cs.synced,
+		height:           height,
+		currentTimestamp: timestamp,
+		peerHeight:       cs.syncProgress.peerHeight,
+		ibdStartTime:     cs.syncProgress.ibdStartTime,
+		ibdStartHeight:   cs.syncProgress.ibdStartHeight,
+	}, time.Now(), cs.chainCts.BlockFrequency)
+}
+
+// syncProgressState is all state used to compute the sync progress.
+type syncProgressState struct {
+	synced           bool
+	height           types.BlockHeight
+	currentTimestamp types.Timestamp
+	peerHeight       types.BlockHeight
+	ibdStartTime     time.Time
+	ibdStartHeight   types.BlockHeight
+}
+
+// computeSyncProgress computes the sync progress at the given time.
+func computeSyncProgress(state syncProgressState, now time.Time, blockFrequency types.BlockHeight) modules.SyncProgress {
+	sp := modules.SyncProgress{
+		Synced:                 state.synced,
+		Height:                 state.height,
+		EstimatedNetworkHeight: state.height,
+	}
+	if state.peerHeight > sp.EstimatedNetworkHeight {
+		sp.EstimatedNetworkHeight = state.peerHeight
+	}
+	// while not synced, estimate the network height by extrapolating
+	// the timestamp of the current block, as that is the only information available
+	// during the initial blockchain download
+	if !state.synced && blockFrequency > 0 {
+		elapsed := now.Unix() - int64(state.currentTimestamp)
+		if elapsed > 0 {
+			extrapolatedHeight := state.height + types.BlockHeight(elapsed)/blockFrequency
+			if extrapolatedHeight > sp.EstimatedNetworkHeight {
+				sp.EstimatedNetworkHeight = extrapolatedHeight
+			}
+		}
+	}
+
+	if !state.ibdStartTime.IsZero() && state.height > state.ibdStartHeight {
+		if elapsed := now.Sub(state.ibdStartTime).Seconds(); elapsed > 0 {
+			sp.BlocksPerSecond = float64(state.height-state.ibdStartHeight) / elapsed
+		}
+	}
+
+	if state.synced || sp.EstimatedNetworkHeight == 0 {
+		sp.Progress = 1
+		return sp
+	}
+	sp.Progress = float64(state.height) / float64(sp.EstimatedNetworkHeight)
+	if sp.BlocksPerSecond > 0 {
+		sp.ETA = uint64(float64(sp.EstimatedNetworkHeight-state.height) / sp.BlocksPerSecond)
+	}
+	return sp
+}
@@ -0,0 +1,48 @@
+package consensus
+
+import (
+	"testing"
+	"time"
+)
+
+func TestComputeSyncProgress(t *testing.T) {
+	now := time.Unix(1500010000, 0)
+
+	// syncing: 1000 blocks processed in 10 seconds,
+	// with the current block 10000 seconds (100 blocks) old
+	sp := computeSyncProgress(syncProgressState{
+		height:           1100,
+		currentTimestamp: 1500000000,
+		ibdStartTime:     now.Add(-10 * time.Second),
+		ibdStartHeight:   100,
+	}, now, 100)
+	if sp.Synced || sp.Height != 1100 || sp.EstimatedNetworkHeight != 1200 {
+		t.Fatalf("unexpected sync progress: %+v", sp)
+	}
+	if sp.BlocksPerSecond != 100 || sp.ETA != 1 {
+		t.Errorf("unexpected sync speed: %+v", sp)
+	}
+	if sp.Progress < 0.916 || sp.Progress > 0.917 {
+		t.Error("unexpected progress:", sp.Progress)
+	}
+
+	// a relayed peer header has precedence over the extrapolated height
+	sp = computeSyncProgress(syncProgressState{
+		height:           1100,
+		currentTimestamp: 1500000000,
+		peerHeight:       2200,
+	}, now, 100)
+	if sp.EstimatedNetworkHeight != 2200 || sp.Progress != 0.5 || sp.ETA != 0 {
+		t.Fatalf("unexpected sync progress: %+v", sp)
+	}
+
+	// once synced, the height is no longer extrapolated
+	sp = computeSyncProgress(syncProgressState{
+		synced:           true,
+		height:           1100,
+		currentTimestamp: 1500000000,
+	}, now, 100)
+	if sp.EstimatedNetworkHeight != 1100 || sp.Progress != 1 || sp.ETA != 0 {
+		t.Fatalf("unexpected sync progress: %+v", sp)
+	}
+}
@@ -0,0 +1,184 @@
+package consensus
+
+import (
+	"github.com/threefoldtech/rivine/build"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+	"github.com/threefoldtech/rivine/types"
+
+	"github.com/rivine/bbolt"
+)
+
+// unlockhashindex.go contains the optional secondary index of the consensus set,
+// mapping unlock hashes to the unspent outputs locked by a condition with that unlock hash.
+// The index is enabled only if its bucket exists, and is maintained
+// by the functions which add and remove unspent outputs to the database.
+
+var (
+	// UnlockHashIndex is a database bucket containing a bucket for each unlock hash
+	// which has unspent outputs. Each nested bucket contains the prefixed IDs of those outputs.
+	// This bucket only exists if the unlock hash index is enabled.
+	UnlockHashIndex = []byte("UnlockHashIndex")
+)
+
+const (
+	// prefixes used for the keys within a nested unlock hash index bucket,
+	// such that coin and blockstake outputs can be distinguished
+	unlockHashIndexCoinOutputPrefix       = byte('c')
+	unlockHashIndexBlockStakeOutputPrefix = byte('b')
+)
+
+// unlockHashIndexKey returns the key for an output within a nested unlock hash index bucket.
+func unlockHashIndexKey(prefix byte, id []byte) []byte {
+	return append([]byte{prefix}, id...)
+}
+
+// addUnlockHashIndexEntry adds an output to the unlock hash index,
+// if the index is enabled.
+func addUnlockHashIndexEntry(tx *bolt.Tx, condition types.UnlockConditionProxy, key []byte) {
+	index := tx.Bucket(UnlockHashIndex)
+	if index == nil {
+		return // index is disabled
+	}
+	uh := condition.UnlockHash()
+	bucket, err := index.CreateBucketIfNotExists(siabin.Marshal(uh))
+	if build.DEBUG && err != nil {
+		panic(err)
+	}
+	err = bucket.Put(key, []byte{})
+	if build.DEBUG && err != nil {
+		panic(err)
+	}
+}
+
+// removeUnlockHashIndexEntry removes an output from the unlock hash index,
+// if the index is enabled, deleting the unlock hash bucket if it no longer contains any outputs.
+func removeUnlockHashIndexEntry(tx *bolt.Tx, condition types.UnlockConditionProxy, key []byte) {
+	index := tx.Bucket(UnlockHashIndex)
+	if index == nil {
+		return // index is disabled
+	}
+	uhKey := siabin.Marshal(condition.UnlockHash())
+	bucket := index.Bucket(uhKey)
+	if build.DEBUG && (bucket == nil || bucket.Get(key) == nil) {
+		panic("nil unlock hash index entry")
+	}
+	if bucket == nil {
+		return
+	}
+	err := bucket.Delete(key)
+	if build.DEBUG && err != nil {
+		panic(err)
+	}
+	if k, _ := bucket.Cursor().First(); k == nil {
+		err = index.DeleteBucket(uhKey)
+		if build.DEBUG && err != nil {
+			panic(err)
+		}
+	}
+}
+
+// unlockHashIndexEnabled returns true if the unlock hash index is enabled.
+func unlockHashIndexEnabled(tx *bolt.Tx) bool {
+	return tx.Bucket(UnlockHashIndex) != nil
+}
+
+// createUnlockHashIndex creates the unlock hash index,
+// indexing all unspent coin and blockstake outputs currently in the database.
+func createUnlockHashIndex(tx *bolt.Tx) error {
+	_, err := tx.CreateBucket(UnlockHashIndex)
+	if err != nil {
+		return err
+	}
+	err = tx.Bucket(CoinOutputs).ForEach(func(k, v []byte) error {
+		var co types.CoinOutput
+		err := siabin.Unmarshal(v, &co)
+		if err != nil {
+			return err
+		}
+		addUnlockHashIndexEntry(tx, co.Condition, unlockHashIndexKey(unlockHashIndexCoinOutputPrefix, k))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return tx.Bucket(BlockStakeOutputs).ForEach(func(k, v []byte) error {
+		var bso types.BlockStakeOutput
+		err := siabin.Unmarshal(v, &bso)
+		if err != nil {
+			return err
+		}
+		addUnlockHashIndexEntry(tx, bso.Condition, unlockHashIndexKey(unlockHashIndexBlockStakeOutputPrefix, k))
+		return nil
+	})
+}
+
+// SetUnlockHashIndex enables or disables the unlock hash index.
+// Enabling the index builds it from the current set of unspent outputs,
+// which can take a while for big chains, while disabling it deletes the index.
+// The state of the index is persistent.
+func (cs *ConsensusSet) SetUnlockHashIndex(enabled bool) error {
+	err := cs.tg.Add()
+	if err != nil {
+		return err
+	}
+	defer cs.tg.Done()
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	return cs.db.Update(func(tx *bolt.Tx) error {
+		if unlockHashIndexEnabled(tx) == enabled {
+			return nil
+		}
+		if !enabled {
+			return tx.DeleteBucket(UnlockHashIndex)
+		}
+		return createUnlockHashIndex(tx)
+	})
+}
+
+// UnspentOutputsByUnlockHash returns all unspent coin and blockstake outputs
+// locked by a condition with the given unlock hash, using the unlock hash index.
+// modules.ErrUnlockHashIndexDisabled is returned in case that index is disabled.
+func (cs *ConsensusSet) UnspentOutputsByUnlockHash(uh types.UnlockHash) (outputs modules.UnspentOutputs, err error) {
+	err = cs.tg.Add()
+	if err != nil {
+		return
+	}
+	defer cs.tg.Done()
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	err = cs.db.View(func(tx *bolt.Tx) error {
+		index := tx.Bucket(UnlockHashIndex)
+		if index == nil {
+			return modules.ErrUnlockHashIndexDisabled
+		}
+		bucket := index.Bucket(siabin.Marshal(uh))
+		if bucket == nil {
+			return nil // no unspent outputs for this unlock hash
+		}
+		return bucket.ForEach(func(k, _ []byte) error {
+			switch k[0] {
+			case unlockHashIndexCoinOutputPrefix:
+				var id types.CoinOutputID
+				copy(id[:], k[1:])
+				co, err := getCoinOutput(tx, id)
+				if err != nil {
+					return err
+				}
+				outputs.CoinOutputs = append(outputs.CoinOutputs, modules.UnspentCoinOutput{ID: id, Output: co})
+			case unlockHashIndexBlockStakeOutputPrefix:
+				var id types.BlockStakeOutputID
+				copy(id[:], k[1:])
+				bso, err := getBlockStakeOutput(tx, id)
+				if err != nil {
+					return err
+				}
+				outputs.BlockStakeOutputs = append(outputs.BlockStakeOutputs, modules.UnspentBlockStakeOutput{ID: id, Output: bso})
+			}
+			return nil
+		})
+	})
+	return
+}
@@ -0,0 +1,112 @@
+package consensus
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/threefoldtech/rivine/build"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/modules/gateway"
+	"github.com/threefoldtech/rivine/types"
+
+	"github.com/rivine/bbolt"
+)
+
+// newTestConsensusSet creates a consensus set, using the testnet chain constants,
+// which contains only the genesis block.
+func newTestConsensusSet(t *testing.T) (*ConsensusSet, types.ChainConstants, func()) {
+	testdir := build.TempDir(modules.ConsensusDir, t.Name())
+	bcInfo := types.DefaultBlockchainInfo()
+	chainCts := types.TestnetChainConstants()
+	g, err := gateway.New("localhost:0", false, filepath.Join(testdir, modules.GatewayDir), bcInfo, chainCts, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs, err := New(g, false, filepath.Join(testdir, modules.ConsensusDir), bcInfo, chainCts)
+	if err != nil {
+		g.Close()
+		t.Fatal(err)
+	}
+	return cs, chainCts, func() {
+		cs.Close()
+		g.Close()
+	}
+}
+
+func TestUnlockHashIndex(t *testing.T) {
+	cs, chainCts, closeFn := newTestConsensusSet(t)
+	defer closeFn()
+
+	uh := chainCts.GenesisCoinDistribution[0].Condition.UnlockHash()
+	_, err := cs.UnspentOutputsByUnlockHash(uh)
+	if err != modules.ErrUnlockHashIndexDisabled {
+		t.Fatal("expected index to be disabled by default, error:", err)
+	}
+
+	err = cs.SetUnlockHashIndex(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputs, err := cs.UnspentOutputsByUnlockHash(uh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	genesisTxn := chainCts.GenesisBlock().Transactions[0]
+	if len(outputs.CoinOutputs) != 1 || outputs.CoinOutputs[0].ID != genesisTxn.CoinOutputID(0) {
+		t.Fatalf("unexpected coin outputs: %v", outputs.CoinOutputs)
+	}
+	if !outputs.CoinOutputs[0].Output.Value.Equals(chainCts.GenesisCoinDistribution[0].Value) {
+		t.Fatalf("unexpected coin output value: %v", outputs.CoinOutputs[0].Output.Value)
+	}
+	if len(outputs.BlockStakeOutputs) != 1 || outputs.BlockStakeOutputs[0].ID != genesisTxn.BlockStakeOutputID(0) {
+		t.Fatalf("unexpected blockstake outputs: %v", outputs.BlockStakeOutputs)
+	}
+
+	// unknown unlock hashes have no outputs
+	outputs, err = cs.UnspentOutputsByUnlockHash(types.UnlockHash{Type: types.UnlockTypePubKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(outputs.CoinOutputs) != 0 || len(outputs.BlockStakeOutputs) != 0 {
+		t.Fatalf("unexpected outputs: %v", outputs)
+	}
+
+	// maintain the index, as outputs are spent and created
+	coid := genesisTxn.CoinOutputID(0)
+	newCO := types.CoinOutput{
+		Value:     types.NewCurrency64(42),
+		Condition: types.NewCondition(types.NewUnlockHashCondition(types.UnlockHash{Type: types.UnlockTypePubKey})),
+	}
+	err = cs.db.Update(func(tx *bolt.Tx) error {
+		removeCoinOutput(tx, coid)
+		addCoinOutput(tx, types.CoinOutputID{1}, newCO)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputs, err = cs.UnspentOutputsByUnlockHash(uh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(outputs.CoinOutputs) != 0 || len(outputs.BlockStakeOutputs) != 1 {
+		t.Fatalf("unexpected outputs: %v", outputs)
+	}
+	outputs, err = cs.UnspentOutputsByUnlockHash(newCO.Condition.UnlockHash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(outputs.CoinOutputs) != 1 || outputs.CoinOutputs[0].ID != (types.CoinOutputID{1}) {
+		t.Fatalf("unexpected outputs: %v", outputs)
+	}
+
+	// disabling the index deletes it
+	err = cs.SetUnlockHashIndex(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = cs.UnspentOutputsByUnlockHash(uh)
+	if err != modules.ErrUnlockHashIndexDisabled {
+		t.Fatal("expected index to be disabled, error:", err)
+	}
+}
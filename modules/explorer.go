@@ -1,6 +1,7 @@
 package modules
 
 import (
+	"errors"
 	"math/big"
 
 	"github.com/threefoldtech/rivine/types"
@@ -12,6 +13,48 @@ const (
 	ExplorerDir = "explorer"
 )
 
+// The directions of an AddressTransaction, relative to its address.
+const (
+	// AddressTransactionIncoming is the direction of a transaction
+	// creating outputs for the address, without spending any of its outputs.
+	AddressTransactionIncoming AddressTransactionDirection = "incoming"
+	// AddressTransactionOutgoing is the direction of a transaction
+	// spending outputs of the address, without creating any outputs for it.
+	AddressTransactionOutgoing AddressTransactionDirection = "outgoing"
+	// AddressTransactionBoth is the direction of a transaction
+	// both spending and creating outputs of the address, e.g. returning change.
+	AddressTransactionBoth AddressTransactionDirection = "both"
+)
+
+// The periods over which Explorer.Aggregates aggregates the blocks.
+const (
+	// AggregatePeriodDay aggregates the blocks per (UTC) day, based on their timestamp.
+	AggregatePeriodDay AggregatePeriod = "day"
+	// AggregatePeriodBlocks aggregates the blocks per AggregatePeriodBlocksSize blocks, based on their height.
+	AggregatePeriodBlocks AggregatePeriod = "blocks"
+
+	// AggregatePeriodBlocksSize is the amount of blocks aggregated per AggregatePeriodBlocks period.
+	AggregatePeriodBlocksSize = 1000
+)
+
+var (
+	// ErrInvalidAddressTransactionsCursor is returned for a cursor
+	// which was not returned by Explorer.AddressTransactions.
+	ErrInvalidAddressTransactionsCursor = errors.New("invalid address transactions cursor")
+	// ErrInvalidBlocksCursor is returned for a cursor
+	// which was not returned by Explorer.Blocks.
+	ErrInvalidBlocksCursor = errors.New("invalid blocks cursor")
+	// ErrInvalidTransactionsCursor is returned for a cursor
+	// which was not returned by Explorer.Transactions.
+	ErrInvalidTransactionsCursor = errors.New("invalid transactions cursor")
+	// ErrInvalidAggregatesCursor is returned for a cursor
+	// which was not returned by Explorer.Aggregates.
+	ErrInvalidAggregatesCursor = errors.New("invalid aggregates cursor")
+	// ErrUnknownAggregatePeriod is returned for an AggregatePeriod
+	// other than AggregatePeriodDay and AggregatePeriodBlocks.
+	ErrUnknownAggregatePeriod = errors.New("unknown aggregate period")
+)
+
 type (
 	// BlockFacts returns a bunch of statistics about the consensus set as they
 	// were at a specific block.
@@ -92,6 +135,212 @@ type (
 		DefaultTransactionVersion types.TransactionVersion `json:"deftransactionversion"`
 	}
 
+	// AddressTransactionDirection defines whether a transaction created outputs for an address,
+	// spent outputs of that address, or both.
+	AddressTransactionDirection string
+
+	// AddressTransaction references a transaction which created or spent outputs of an address,
+	// as indexed by the explorer.
+	AddressTransaction struct {
+		// TransactionID is the ID of the transaction,
+		// or the ID of the block for the miner payouts of a block.
+		TransactionID types.TransactionID `json:"transactionid"`
+		BlockID       types.BlockID       `json:"blockid"`
+		Height        types.BlockHeight   `json:"height"`
+		// Index is the index of the transaction within its block,
+		// 0 for the miner payouts of a block, see MinerPayout.
+		Index       int  `json:"index"`
+		MinerPayout bool `json:"minerpayout,omitempty"`
+
+		Direction AddressTransactionDirection `json:"direction"`
+		// The total value of the outputs created for and spent by the address.
+		CoinsReceived       types.Currency `json:"coinsreceived"`
+		CoinsSent           types.Currency `json:"coinssent"`
+		BlockStakesReceived types.Currency `json:"blockstakesreceived"`
+		BlockStakesSent     types.Currency `json:"blockstakessent"`
+	}
+
+	// AddressTransactionsQuery defines the transactions of an address
+	// returned by Explorer.AddressTransactions.
+	AddressTransactionsQuery struct {
+		// MinHeight and MaxHeight are the (inclusive) range of heights of the transactions,
+		// there is no maximum if MaxHeight is 0.
+		MinHeight types.BlockHeight
+		MaxHeight types.BlockHeight
+		// Direction only returns the transactions creating outputs for the address if incoming,
+		// spending its outputs if outgoing, or doing both if both, all transactions if empty.
+		// Transactions doing both are returned for incoming and outgoing as well.
+		Direction AddressTransactionDirection
+		// Cursor is the cursor returned with the previous page, empty for the first page.
+		Cursor string
+		// Reverse returns the transactions from newest to oldest, rather than from oldest to newest.
+		Reverse bool
+		// Limit is the maximum amount of transactions returned, unlimited if 0.
+		Limit int
+	}
+
+	// BlockSummary summarizes a block, as listed by Explorer.Blocks.
+	BlockSummary struct {
+		BlockID          types.BlockID     `json:"blockid"`
+		ParentID         types.BlockID     `json:"parentid"`
+		Height           types.BlockHeight `json:"height"`
+		Timestamp        types.Timestamp   `json:"timestamp"`
+		TransactionCount int               `json:"transactioncount"`
+		// MinerPayouts is the total value of the miner payouts of the block.
+		MinerPayouts types.Currency `json:"minerpayouts"`
+	}
+
+	// TransactionSummary summarizes a transaction, as listed by Explorer.Transactions.
+	TransactionSummary struct {
+		TransactionID types.TransactionID `json:"transactionid"`
+		BlockID       types.BlockID       `json:"blockid"`
+		Height        types.BlockHeight   `json:"height"`
+		// Index is the index of the transaction within its block.
+		Index     int                      `json:"index"`
+		Timestamp types.Timestamp          `json:"timestamp"`
+		Version   types.TransactionVersion `json:"version"`
+
+		CoinInputCount        int `json:"coininputcount"`
+		CoinOutputCount       int `json:"coinoutputcount"`
+		BlockStakeInputCount  int `json:"blockstakeinputcount"`
+		BlockStakeOutputCount int `json:"blockstakeoutputcount"`
+		// CoinOutputValue is the total value of the coin outputs of the transaction,
+		// BlockStakeOutputValue the total value of its block stake outputs.
+		CoinOutputValue       types.Currency `json:"coinoutputvalue"`
+		BlockStakeOutputValue types.Currency `json:"blockstakeoutputvalue"`
+		MinerFees             types.Currency `json:"minerfees"`
+	}
+
+	// BlocksQuery defines the blocks returned by Explorer.Blocks.
+	BlocksQuery struct {
+		// MinHeight and MaxHeight are the (inclusive) range of heights of the blocks,
+		// there is no maximum if MaxHeight is 0.
+		MinHeight types.BlockHeight
+		MaxHeight types.BlockHeight
+		// MinTimestamp and MaxTimestamp are the (inclusive) range of timestamps of the blocks,
+		// there is no maximum if MaxTimestamp is 0.
+		MinTimestamp types.Timestamp
+		MaxTimestamp types.Timestamp
+		// Cursor is the cursor returned with the previous page, empty for the first page.
+		Cursor string
+		// Reverse returns the blocks from newest to oldest, rather than from oldest to newest.
+		Reverse bool
+		// Limit is the maximum amount of blocks returned, unlimited if 0.
+		Limit int
+	}
+
+	// TransactionsQuery defines the transactions returned by Explorer.Transactions.
+	TransactionsQuery struct {
+		// MinHeight, MaxHeight, MinTimestamp and MaxTimestamp define the range of blocks
+		// the transactions are part of, just like the fields of BlocksQuery.
+		MinHeight    types.BlockHeight
+		MaxHeight    types.BlockHeight
+		MinTimestamp types.Timestamp
+		MaxTimestamp types.Timestamp
+		// Versions only returns the transactions of the given versions, all transactions if empty.
+		Versions []types.TransactionVersion
+		// MinValue only returns the transactions with coin outputs of at least the given total value.
+		MinValue types.Currency
+		// Cursor is the cursor returned with the previous page, empty for the first page.
+		Cursor string
+		// Reverse returns the transactions from newest to oldest, rather than from oldest to newest.
+		Reverse bool
+		// Limit is the maximum amount of transactions returned, unlimited if 0.
+		Limit int
+	}
+
+	// AggregatePeriod is the period over which Explorer.Aggregates aggregates the blocks.
+	AggregatePeriod string
+
+	// ChainAggregate aggregates the blocks of a period, as returned by Explorer.Aggregates.
+	ChainAggregate struct {
+		// Start is the start of the period: the timestamp of the start of the day
+		// for AggregatePeriodDay, or the height of its first block for AggregatePeriodBlocks.
+		Start uint64 `json:"start"`
+
+		Blocks       uint64 `json:"blocks"`
+		Transactions uint64 `json:"transactions"`
+		// MinerFees is the total value of the miner fees of the transactions,
+		// CoinOutputValue the total value of their coin outputs.
+		MinerFees       types.Currency `json:"minerfees"`
+		CoinOutputValue types.Currency `json:"coinoutputvalue"`
+		// AverageBlockTime is the average amount of seconds between the blocks and their parent,
+		// the genesis block not being counted, 0 if there are no such blocks.
+		AverageBlockTime float64 `json:"averageblocktime"`
+		// AverageBlockSize is the average size of the (encoded) blocks in bytes.
+		AverageBlockSize float64 `json:"averageblocksize"`
+	}
+
+	// AggregatesQuery defines the aggregates returned by Explorer.Aggregates.
+	AggregatesQuery struct {
+		// Period is the period over which the blocks are aggregated.
+		Period AggregatePeriod
+		// Start and End are the (inclusive) range of the aggregates, in the unit of the period,
+		// the aggregates of the periods containing them being included.
+		// There is no end if End is 0.
+		Start uint64
+		End   uint64
+		// Cursor is the cursor returned with the previous page, empty for the first page.
+		Cursor string
+		// Reverse returns the aggregates from newest to oldest, rather than from oldest to newest.
+		Reverse bool
+		// Limit is the maximum amount of aggregates returned, unlimited if 0.
+		Limit int
+	}
+
+	// ExplorerReorg is a reorg of the blockchain, as processed by the explorer,
+	// which records the most recent reorgs.
+	ExplorerReorg struct {
+		// ID identifies the reorg, the ID of each reorg being one higher than the ID of the previous one.
+		ID uint64 `json:"id"`
+		// ForkHeight is the height of the last block both chains have in common.
+		ForkHeight types.BlockHeight `json:"forkheight"`
+		// RevertedBlocks are the IDs of the reverted blocks, in the order they were reverted,
+		// AppliedBlocks the IDs of the blocks applied in their place, in the order they were applied.
+		RevertedBlocks []types.BlockID `json:"revertedblocks"`
+		AppliedBlocks  []types.BlockID `json:"appliedblocks"`
+		// Timestamp is the time at which the explorer processed the reorg.
+		Timestamp types.Timestamp `json:"timestamp"`
+	}
+
+	// AddressBalance is the balance of an address, as indexed by the explorer,
+	// including the value of its outputs which are (still) locked.
+	AddressBalance struct {
+		UnlockHash  types.UnlockHash `json:"unlockhash"`
+		Coins       types.Currency   `json:"coins"`
+		BlockStakes types.Currency   `json:"blockstakes"`
+	}
+
+	// BalanceRange groups the addresses of which the coin balance is within a range,
+	// as part of the balance distribution returned by Explorer.BalanceDistribution.
+	BalanceRange struct {
+		// MinBalance and MaxBalance are the (inclusive) minimum
+		// and (exclusive) maximum coin balance of the range.
+		MinBalance types.Currency `json:"minbalance"`
+		MaxBalance types.Currency `json:"maxbalance"`
+		// Addresses is the amount of addresses with a balance within the range,
+		// Coins the sum of their balances.
+		Addresses uint64         `json:"addresses"`
+		Coins     types.Currency `json:"coins"`
+	}
+
+	// SupplyStats contains the supply of coins and block stakes,
+	// as it is at the height of the last block processed by the explorer.
+	SupplyStats struct {
+		Height types.BlockHeight `json:"height"`
+		// Coins is the total value of all unspent coin outputs,
+		// the sum of the ActiveCoins and LockedCoins.
+		Coins types.Currency `json:"coins"`
+		// ActiveCoins is the value of the unspent coin outputs which can be spent.
+		ActiveCoins types.Currency `json:"activecoins"`
+		// LockedCoins is the value of the unspent coin outputs which cannot be spent yet,
+		// being either time-locked or miner payouts which did not mature yet.
+		LockedCoins types.Currency `json:"lockedcoins"`
+		BlockStakes types.Currency `json:"blockstakes"`
+		// Addresses is the amount of addresses with a coin balance.
+		Addresses uint64 `json:"addresses"`
+	}
+
 	// Explorer tracks the blockchain and provides tools for gathering
 	// statistics and finding objects or patterns within the blockchain.
 	Explorer interface {
@@ -107,6 +356,20 @@ type (
 		// in the explorer's database.
 		LatestBlockFacts() BlockFacts
 
+		// Blocks returns a page of the blocks within the given ranges, ordered by height,
+		// as well as the cursor of the next page, empty if there is no next page.
+		Blocks(BlocksQuery) ([]BlockSummary, string, error)
+
+		// Transactions returns a page of the transactions matching the given query,
+		// ordered by height and index, as well as the cursor of the next page,
+		// empty if there is no next page. The miner payouts are not listed.
+		Transactions(TransactionsQuery) ([]TransactionSummary, string, error)
+
+		// Aggregates returns a page of the aggregates of the blocks per period, ordered by period,
+		// as well as the cursor of the next page, empty if there is no next page.
+		// Periods without blocks are not returned.
+		Aggregates(AggregatesQuery) ([]ChainAggregate, string, error)
+
 		// Transaction returns the block that contains the input transaction
 		// id. The transaction itself is either the block (indicating the miner
 		// payouts are somehow involved), or it is a transaction inside of the
@@ -121,6 +384,30 @@ type (
 		// MultiSigAddresses returns all multisig addresses this wallet address is involved in.
 		MultiSigAddresses(types.UnlockHash) []types.UnlockHash
 
+		// AddressTransactions returns a page of the transactions which created or spent
+		// outputs of the provided unlock hash, ordered by height and index,
+		// as well as the cursor of the next page, empty if there is no next page.
+		AddressTransactions(types.UnlockHash, AddressTransactionsQuery) ([]AddressTransaction, string, error)
+
+		// RichList returns the given amount of addresses with the highest coin balance,
+		// ordered from the highest to the lowest balance.
+		RichList(int) ([]AddressBalance, error)
+
+		// AddressBalance returns the balance of the given address,
+		// a zero balance if it has no unspent outputs.
+		AddressBalance(types.UnlockHash) (AddressBalance, error)
+
+		// BalanceDistribution returns the amount of addresses and their total balance,
+		// per range of coin balances, ordered from the lowest to the highest range.
+		BalanceDistribution() ([]BalanceRange, error)
+
+		// Supply returns the current supply of coins and block stakes.
+		Supply() (SupplyStats, error)
+
+		// Reorgs returns the given amount of most recent reorgs,
+		// ordered from the newest to the oldest reorg.
+		Reorgs(int) ([]ExplorerReorg, error)
+
 		// CoinOutput will return the coin output associated with the
 		// input id.
 		CoinOutput(types.CoinOutputID) (types.CoinOutput, bool)
@@ -0,0 +1,237 @@
+package explorer
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+	"github.com/threefoldtech/rivine/types"
+
+	"github.com/rivine/bbolt"
+)
+
+// The address history is stored in bucketAddressHistory, containing a nested bucket per unlock hash,
+// in which the transactions creating or spending outputs of that unlock hash are keyed by their
+// position in the blockchain, such that they are ordered by height and index.
+
+// addressHistoryKeySize is the size of the key of an address transaction:
+// its block height (8 bytes) followed by its position within the block (4 bytes),
+// both big-endian, the position being 0 for the miner payouts and the index + 1 for the transactions.
+const addressHistoryKeySize = 12
+
+// addressHistoryKey returns the key of the address transaction at the given height and position.
+func addressHistoryKey(height types.BlockHeight, position uint32) []byte {
+	key := make([]byte, addressHistoryKeySize)
+	binary.BigEndian.PutUint64(key[:8], uint64(height))
+	binary.BigEndian.PutUint32(key[8:], position)
+	return key
+}
+
+// addressHistoryKeyHeight returns the block height of the given key.
+func addressHistoryKeyHeight(key []byte) types.BlockHeight {
+	return types.BlockHeight(binary.BigEndian.Uint64(key[:8]))
+}
+
+// addressHistoryEntry is an address transaction, as it is indexed for its unlock hash.
+type addressHistoryEntry struct {
+	unlockHash types.UnlockHash
+	key        []byte
+	txn        modules.AddressTransaction
+	created    bool
+	spent      bool
+}
+
+// addressHistoryOfBlock returns the address transactions of all unlock hashes
+// of which the given block creates or spends outputs. All outputs of the block,
+// as well as the outputs it spends, have to be stored in the database.
+func addressHistoryOfBlock(tx *bolt.Tx, block types.Block, height types.BlockHeight) []*addressHistoryEntry {
+	var entries []*addressHistoryEntry
+	blockID := block.ID()
+	// collect the entries of a single transaction, keyed by unlock hash, in order of appearance
+	var (
+		position uint32
+		current  map[types.UnlockHash]*addressHistoryEntry
+	)
+	entry := func(uh types.UnlockHash, txid types.TransactionID, index int) *addressHistoryEntry {
+		if e, ok := current[uh]; ok {
+			return e
+		}
+		e := &addressHistoryEntry{
+			unlockHash: uh,
+			key:        addressHistoryKey(height, position),
+			txn: modules.AddressTransaction{
+				TransactionID:       txid,
+				BlockID:             blockID,
+				Height:              height,
+				Index:               index,
+				MinerPayout:         position == 0,
+				CoinsReceived:       types.ZeroCurrency,
+				CoinsSent:           types.ZeroCurrency,
+				BlockStakesReceived: types.ZeroCurrency,
+				BlockStakesSent:     types.ZeroCurrency,
+			},
+		}
+		current[uh] = e
+		entries = append(entries, e)
+		return e
+	}
+
+	// the miner payouts are a transaction, identified by the block ID
+	current = make(map[types.UnlockHash]*addressHistoryEntry)
+	for _, payout := range block.MinerPayouts {
+		e := entry(payout.UnlockHash, types.TransactionID(blockID), 0)
+		e.txn.CoinsReceived = e.txn.CoinsReceived.Add(payout.Value)
+		e.created = true
+	}
+	for i, txn := range block.Transactions {
+		position = uint32(i + 1)
+		current = make(map[types.UnlockHash]*addressHistoryEntry)
+		txid := txn.ID()
+		for _, ci := range txn.CoinInputs {
+			var co types.CoinOutput
+			if err := dbGetAndDecode(bucketCoinOutputs, ci.ParentID, &co)(tx); err != nil {
+				continue
+			}
+			e := entry(co.Condition.UnlockHash(), txid, i)
+			e.txn.CoinsSent = e.txn.CoinsSent.Add(co.Value)
+			e.spent = true
+		}
+		for _, bsi := range txn.BlockStakeInputs {
+			var bso types.BlockStakeOutput
+			if err := dbGetAndDecode(bucketBlockStakeOutputs, bsi.ParentID, &bso)(tx); err != nil {
+				continue
+			}
+			e := entry(bso.Condition.UnlockHash(), txid, i)
+			e.txn.BlockStakesSent = e.txn.BlockStakesSent.Add(bso.Value)
+			e.spent = true
+		}
+		for _, co := range txn.CoinOutputs {
+			e := entry(co.Condition.UnlockHash(), txid, i)
+			e.txn.CoinsReceived = e.txn.CoinsReceived.Add(co.Value)
+			e.created = true
+		}
+		for _, bso := range txn.BlockStakeOutputs {
+			e := entry(bso.Condition.UnlockHash(), txid, i)
+			e.txn.BlockStakesReceived = e.txn.BlockStakesReceived.Add(bso.Value)
+			e.created = true
+		}
+	}
+
+	for _, e := range entries {
+		switch {
+		case e.created && e.spent:
+			e.txn.Direction = modules.AddressTransactionBoth
+		case e.spent:
+			e.txn.Direction = modules.AddressTransactionOutgoing
+		default:
+			e.txn.Direction = modules.AddressTransactionIncoming
+		}
+	}
+	return entries
+}
+
+// dbAddAddressHistory indexes the transactions of the given block for the unlock hashes
+// of which they create or spend outputs. It has to be called once all outputs of the block are stored.
+func dbAddAddressHistory(tx *bolt.Tx, block types.Block, height types.BlockHeight) {
+	for _, e := range addressHistoryOfBlock(tx, block, height) {
+		b, err := tx.Bucket(bucketAddressHistory).CreateBucketIfNotExists(siabin.Marshal(e.unlockHash))
+		assertNil(err)
+		assertNil(b.Put(e.key, siabin.Marshal(e.txn)))
+	}
+}
+
+// dbRemoveAddressHistory removes the transactions of the given block from the address history.
+// It has to be called prior to removing any output of the block.
+func dbRemoveAddressHistory(tx *bolt.Tx, block types.Block, height types.BlockHeight) {
+	ahb := tx.Bucket(bucketAddressHistory)
+	for _, e := range addressHistoryOfBlock(tx, block, height) {
+		muh := siabin.Marshal(e.unlockHash)
+		b := ahb.Bucket(muh)
+		if b == nil {
+			continue // nothing indexed for this unlock hash
+		}
+		assertNil(b.Delete(e.key))
+		if bucketIsEmpty(b) {
+			assertNil(ahb.DeleteBucket(muh))
+		}
+	}
+}
+
+// AddressTransactions returns a page of the transactions which created or spent outputs
+// of the given unlock hash, ordered by height and index.
+func (e *Explorer) AddressTransactions(uh types.UnlockHash, query modules.AddressTransactionsQuery) (txns []modules.AddressTransaction, next string, err error) {
+	switch query.Direction {
+	case "", modules.AddressTransactionIncoming, modules.AddressTransactionOutgoing, modules.AddressTransactionBoth:
+	default:
+		return nil, "", fmt.Errorf("invalid address transaction direction %q", query.Direction)
+	}
+	var after []byte
+	if query.Cursor != "" {
+		after, err = hex.DecodeString(query.Cursor)
+		if err != nil || len(after) != addressHistoryKeySize {
+			return nil, "", modules.ErrInvalidAddressTransactionsCursor
+		}
+	}
+	err = e.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketAddressHistory).Bucket(siabin.Marshal(uh))
+		if b == nil {
+			return nil
+		}
+		start := addressHistoryKey(query.MinHeight, 0)
+		var end []byte
+		if query.MaxHeight != 0 {
+			end = addressHistoryKey(query.MaxHeight+1, 0)
+		}
+		c := b.Cursor()
+		for k, v := seekPage(c, after, query.Reverse, start, end); k != nil; k, v = nextInOrder(c, query.Reverse) {
+			height := addressHistoryKeyHeight(k)
+			if query.Reverse && height < query.MinHeight || !query.Reverse && query.MaxHeight != 0 && height > query.MaxHeight {
+				break
+			}
+			if height < query.MinHeight || query.MaxHeight != 0 && height > query.MaxHeight {
+				continue // a cursor beyond the range
+			}
+			var txn modules.AddressTransaction
+			err := siabin.Unmarshal(v, &txn)
+			if err != nil {
+				return err
+			}
+			if !addressTransactionMatches(txn, query.Direction) {
+				continue
+			}
+			if query.Limit > 0 && len(txns) == query.Limit {
+				next = hex.EncodeToString(addressHistoryKey(txns[len(txns)-1].Height, addressTransactionPosition(txns[len(txns)-1])))
+				return nil
+			}
+			txns = append(txns, txn)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return txns, next, nil
+}
+
+// addressTransactionPosition returns the position of the given address transaction within its block.
+func addressTransactionPosition(txn modules.AddressTransaction) uint32 {
+	if txn.MinerPayout {
+		return 0
+	}
+	return uint32(txn.Index + 1)
+}
+
+// addressTransactionMatches returns true if the given address transaction has the given direction,
+// a transaction of both directions matching either direction.
+func addressTransactionMatches(txn modules.AddressTransaction, direction modules.AddressTransactionDirection) bool {
+	switch direction {
+	case "", txn.Direction:
+		return true
+	case modules.AddressTransactionIncoming, modules.AddressTransactionOutgoing:
+		return txn.Direction == modules.AddressTransactionBoth
+	default:
+		return false
+	}
+}
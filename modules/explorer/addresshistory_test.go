@@ -0,0 +1,143 @@
+package explorer
+
+import (
+	"os"
+	"testing"
+
+	"github.com/threefoldtech/rivine/build"
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+
+	"github.com/rivine/bbolt"
+)
+
+// TestAddressHistory indexes the address history of a few blocks,
+// including a transaction spending an output created in the same block,
+// pages through it, and checks that reverting the blocks removes it.
+func TestAddressHistory(t *testing.T) {
+	dir := build.TempDir(modules.ExplorerDir, t.Name())
+	os.RemoveAll(dir)
+	e := &Explorer{persistDir: dir}
+	err := e.initPersist()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.db.Close()
+
+	alice := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: crypto.Hash{1}}
+	bob := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: crypto.Hash{2}}
+	output := func(uh types.UnlockHash, value uint64) types.CoinOutput {
+		return types.CoinOutput{
+			Value:     types.NewCurrency64(value),
+			Condition: types.NewCondition(types.NewUnlockHashCondition(uh)),
+		}
+	}
+	// block 1 pays alice, block 2 has alice pay bob returning change,
+	// after which bob pays alice within the same transaction set
+	txn1 := types.Transaction{CoinOutputs: []types.CoinOutput{output(alice, 5)}}
+	block1 := types.Block{
+		Timestamp:    1,
+		MinerPayouts: []types.MinerPayout{{Value: types.NewCurrency64(10), UnlockHash: alice}},
+		Transactions: []types.Transaction{txn1},
+	}
+	txn2 := types.Transaction{
+		CoinInputs:  []types.CoinInput{{ParentID: txn1.CoinOutputID(0)}},
+		CoinOutputs: []types.CoinOutput{output(bob, 3), output(alice, 2)},
+	}
+	txn3 := types.Transaction{
+		CoinInputs:  []types.CoinInput{{ParentID: txn2.CoinOutputID(0)}},
+		CoinOutputs: []types.CoinOutput{output(alice, 3)},
+	}
+	block2 := types.Block{Timestamp: 2, Transactions: []types.Transaction{txn2, txn3}}
+
+	apply := func(block types.Block, height types.BlockHeight) {
+		err := e.db.Update(func(tx *bolt.Tx) error {
+			for _, txn := range block.Transactions {
+				for i, co := range txn.CoinOutputs {
+					dbAddCoinOutput(tx, txn.CoinOutputID(uint64(i)), co)
+				}
+			}
+			dbAddAddressHistory(tx, block, height)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	apply(block1, 1)
+	apply(block2, 2)
+
+	// list pages through all transactions of the address
+	list := func(uh types.UnlockHash, query modules.AddressTransactionsQuery) []modules.AddressTransaction {
+		var all []modules.AddressTransaction
+		for {
+			txns, next, err := e.AddressTransactions(uh, query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			all = append(all, txns...)
+			if next == "" {
+				return all
+			}
+			query.Cursor = next
+		}
+	}
+	txns := list(alice, modules.AddressTransactionsQuery{Limit: 1})
+	expected := []struct {
+		id        types.TransactionID
+		direction modules.AddressTransactionDirection
+		received  uint64
+		sent      uint64
+	}{
+		{types.TransactionID(block1.ID()), modules.AddressTransactionIncoming, 10, 0},
+		{txn1.ID(), modules.AddressTransactionIncoming, 5, 0},
+		{txn2.ID(), modules.AddressTransactionBoth, 2, 5},
+		{txn3.ID(), modules.AddressTransactionIncoming, 3, 0},
+	}
+	if len(txns) != len(expected) {
+		t.Fatalf("expected %d transactions, got %d: %+v", len(expected), len(txns), txns)
+	}
+	for i, txn := range txns {
+		if txn.TransactionID != expected[i].id || txn.Direction != expected[i].direction ||
+			!txn.CoinsReceived.Equals64(expected[i].received) || !txn.CoinsSent.Equals64(expected[i].sent) {
+			t.Errorf("unexpected transaction #%d: %+v", i, txn)
+		}
+	}
+	if !txns[0].MinerPayout || txns[0].Height != 1 || txns[2].Height != 2 || txns[3].Index != 1 || txns[3].BlockID != block2.ID() {
+		t.Errorf("unexpected transaction references: %+v", txns)
+	}
+
+	// the transactions can be filtered and listed from newest to oldest
+	if txns := list(alice, modules.AddressTransactionsQuery{Reverse: true, Limit: 3}); len(txns) != 4 || txns[0].TransactionID != txn3.ID() {
+		t.Errorf("unexpected reversed transactions: %+v", txns)
+	}
+	if txns := list(alice, modules.AddressTransactionsQuery{MinHeight: 2, Direction: modules.AddressTransactionOutgoing}); len(txns) != 1 || txns[0].TransactionID != txn2.ID() {
+		t.Errorf("unexpected outgoing transactions: %+v", txns)
+	}
+	if txns := list(alice, modules.AddressTransactionsQuery{MaxHeight: 1, Reverse: true}); len(txns) != 2 || txns[0].TransactionID != txn1.ID() {
+		t.Errorf("unexpected transactions up to height 1: %+v", txns)
+	}
+	if txns := list(bob, modules.AddressTransactionsQuery{}); len(txns) != 2 ||
+		txns[0].Direction != modules.AddressTransactionIncoming || txns[1].Direction != modules.AddressTransactionOutgoing {
+		t.Errorf("unexpected transactions of bob: %+v", txns)
+	}
+	if _, _, err := e.AddressTransactions(alice, modules.AddressTransactionsQuery{Cursor: "foo"}); err != modules.ErrInvalidAddressTransactionsCursor {
+		t.Errorf("expected an invalid cursor to be refused: %v", err)
+	}
+
+	// reverting the blocks removes their transactions
+	err = e.db.Update(func(tx *bolt.Tx) error {
+		dbRemoveAddressHistory(tx, block2, 2)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if txns := list(alice, modules.AddressTransactionsQuery{}); len(txns) != 2 {
+		t.Errorf("expected the reverted transactions to be removed: %+v", txns)
+	}
+	if txns := list(bob, modules.AddressTransactionsQuery{}); len(txns) != 0 {
+		t.Errorf("expected the reverted transactions to be removed: %+v", txns)
+	}
+}
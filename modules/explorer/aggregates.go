@@ -0,0 +1,191 @@
+package explorer
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"math"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+	"github.com/threefoldtech/rivine/types"
+
+	"github.com/rivine/bbolt"
+)
+
+// The blocks are aggregated per day in bucketDailyAggregates, keyed by the day (the timestamp of
+// the block divided by secondsPerDay), and per modules.AggregatePeriodBlocksSize blocks in
+// bucketBlocksAggregates, keyed by the height of the block divided by that size, both 8 bytes and big-endian.
+// Applying a block adds it to both aggregates, while reverting it subtracts it again,
+// such that the aggregates never need to be recomputed from the blocks.
+
+// secondsPerDay is the amount of seconds of an AggregatePeriodDay period.
+const secondsPerDay = 24 * 60 * 60
+
+// aggregate is the stored aggregate of the blocks of a period.
+type aggregate struct {
+	Blocks          uint64
+	Transactions    uint64
+	MinerFees       types.Currency
+	CoinOutputValue types.Currency
+	// BlockTime is the sum of the seconds between the blocks and their parent,
+	// BlockTimeBlocks the amount of blocks with a parent.
+	BlockTime       int64
+	BlockTimeBlocks uint64
+	// BlockSize is the sum of the sizes of the encoded blocks.
+	BlockSize uint64
+}
+
+// add returns the sum of both aggregates.
+func (a aggregate) add(b aggregate) aggregate {
+	return aggregate{
+		Blocks:          a.Blocks + b.Blocks,
+		Transactions:    a.Transactions + b.Transactions,
+		MinerFees:       a.MinerFees.Add(b.MinerFees),
+		CoinOutputValue: a.CoinOutputValue.Add(b.CoinOutputValue),
+		BlockTime:       a.BlockTime + b.BlockTime,
+		BlockTimeBlocks: a.BlockTimeBlocks + b.BlockTimeBlocks,
+		BlockSize:       a.BlockSize + b.BlockSize,
+	}
+}
+
+// sub returns the given aggregate subtracted from the aggregate.
+func (a aggregate) sub(b aggregate) aggregate {
+	return aggregate{
+		Blocks:          a.Blocks - b.Blocks,
+		Transactions:    a.Transactions - b.Transactions,
+		MinerFees:       a.MinerFees.Sub(b.MinerFees),
+		CoinOutputValue: a.CoinOutputValue.Sub(b.CoinOutputValue),
+		BlockTime:       a.BlockTime - b.BlockTime,
+		BlockTimeBlocks: a.BlockTimeBlocks - b.BlockTimeBlocks,
+		BlockSize:       a.BlockSize - b.BlockSize,
+	}
+}
+
+// aggregateKey returns the key of the aggregate of the period with the given index.
+func aggregateKey(index uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, index)
+	return key
+}
+
+// blockAggregate returns the aggregate of the given block, applied at the given height,
+// using the summary of its parent to compute its block time.
+func blockAggregate(tx *bolt.Tx, block types.Block, height types.BlockHeight) aggregate {
+	a := aggregate{
+		Blocks:          1,
+		Transactions:    uint64(len(block.Transactions)),
+		MinerFees:       types.ZeroCurrency,
+		CoinOutputValue: types.ZeroCurrency,
+		BlockSize:       uint64(len(siabin.Marshal(block))),
+	}
+	for _, txn := range block.Transactions {
+		for _, fee := range txn.MinerFees {
+			a.MinerFees = a.MinerFees.Add(fee)
+		}
+		for _, co := range txn.CoinOutputs {
+			a.CoinOutputValue = a.CoinOutputValue.Add(co.Value)
+		}
+	}
+	if height > 0 {
+		if v := tx.Bucket(bucketBlockSummaries).Get(blockSummaryKey(height - 1)); v != nil {
+			var parent modules.BlockSummary
+			assertNil(siabin.Unmarshal(v, &parent))
+			a.BlockTime = int64(block.Timestamp) - int64(parent.Timestamp)
+			a.BlockTimeBlocks = 1
+		}
+	}
+	return a
+}
+
+// dbUpdateAggregate adds (or subtracts) the given aggregate to the stored aggregate
+// of the given period, removing it once no blocks are left.
+func dbUpdateAggregate(b *bolt.Bucket, index uint64, delta aggregate, subtract bool) {
+	key := aggregateKey(index)
+	a := aggregate{MinerFees: types.ZeroCurrency, CoinOutputValue: types.ZeroCurrency}
+	if v := b.Get(key); v != nil {
+		assertNil(siabin.Unmarshal(v, &a))
+	}
+	if subtract {
+		a = a.sub(delta)
+	} else {
+		a = a.add(delta)
+	}
+	if a.Blocks == 0 {
+		assertNil(b.Delete(key))
+		return
+	}
+	assertNil(b.Put(key, siabin.Marshal(a)))
+}
+
+// dbAddAggregates adds the given block, applied at the given height, to the aggregates of its periods.
+// The summary of its parent has to be stored, see dbAddSummaries.
+func dbAddAggregates(tx *bolt.Tx, block types.Block, height types.BlockHeight) {
+	a := blockAggregate(tx, block, height)
+	dbUpdateAggregate(tx.Bucket(bucketDailyAggregates), uint64(block.Timestamp)/secondsPerDay, a, false)
+	dbUpdateAggregate(tx.Bucket(bucketBlocksAggregates), uint64(height)/modules.AggregatePeriodBlocksSize, a, false)
+}
+
+// dbRemoveAggregates subtracts the given block, reverted at the given height, from the aggregates of its periods.
+// The summary of its parent has to be stored, see dbRemoveSummaries.
+func dbRemoveAggregates(tx *bolt.Tx, block types.Block, height types.BlockHeight) {
+	a := blockAggregate(tx, block, height)
+	dbUpdateAggregate(tx.Bucket(bucketDailyAggregates), uint64(block.Timestamp)/secondsPerDay, a, true)
+	dbUpdateAggregate(tx.Bucket(bucketBlocksAggregates), uint64(height)/modules.AggregatePeriodBlocksSize, a, true)
+}
+
+// Aggregates returns a page of the aggregates of the blocks per period, ordered by period.
+func (e *Explorer) Aggregates(query modules.AggregatesQuery) (aggregates []modules.ChainAggregate, next string, err error) {
+	var (
+		bucket []byte
+		size   uint64
+	)
+	switch query.Period {
+	case modules.AggregatePeriodDay:
+		bucket, size = bucketDailyAggregates, secondsPerDay
+	case modules.AggregatePeriodBlocks:
+		bucket, size = bucketBlocksAggregates, modules.AggregatePeriodBlocksSize
+	default:
+		return nil, "", modules.ErrUnknownAggregatePeriod
+	}
+	var after []byte
+	if query.Cursor != "" {
+		after, err = hex.DecodeString(query.Cursor)
+		if err != nil || len(after) != 8 {
+			return nil, "", modules.ErrInvalidAggregatesCursor
+		}
+	}
+	var max uint64 = math.MaxUint64
+	if query.End != 0 {
+		max = query.End / size
+	}
+	err = e.db.View(func(tx *bolt.Tx) error {
+		return dbListRange(tx.Bucket(bucket), after, query.Reverse, query.Start/size, max, func(k, v []byte) (bool, error) {
+			if query.Limit > 0 && len(aggregates) == query.Limit {
+				next = hex.EncodeToString(aggregateKey(aggregates[len(aggregates)-1].Start / size))
+				return false, nil
+			}
+			var a aggregate
+			err := siabin.Unmarshal(v, &a)
+			if err != nil {
+				return false, err
+			}
+			ca := modules.ChainAggregate{
+				Start:            binary.BigEndian.Uint64(k) * size,
+				Blocks:           a.Blocks,
+				Transactions:     a.Transactions,
+				MinerFees:        a.MinerFees,
+				CoinOutputValue:  a.CoinOutputValue,
+				AverageBlockSize: float64(a.BlockSize) / float64(a.Blocks),
+			}
+			if a.BlockTimeBlocks > 0 {
+				ca.AverageBlockTime = float64(a.BlockTime) / float64(a.BlockTimeBlocks)
+			}
+			aggregates = append(aggregates, ca)
+			return true, nil
+		})
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return aggregates, next, nil
+}
@@ -0,0 +1,110 @@
+package explorer
+
+import (
+	"os"
+	"testing"
+
+	"github.com/threefoldtech/rivine/build"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+
+	"github.com/rivine/bbolt"
+)
+
+// TestAggregates aggregates blocks spanning two days and two ranges of heights,
+// lists the aggregates of both periods, and checks that reverting the blocks reverts them.
+func TestAggregates(t *testing.T) {
+	dir := build.TempDir(modules.ExplorerDir, t.Name())
+	os.RemoveAll(dir)
+	e := &Explorer{persistDir: dir}
+	err := e.initPersist()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.db.Close()
+
+	// the blocks are 10 minutes apart, starting 20 minutes before the end of the first day,
+	// the block at height 999 having a transaction paying a fee
+	const blockTime = 600
+	heights := []types.BlockHeight{998, 999, 1000, 1001}
+	var blocks []types.Block
+	for i := range heights {
+		block := types.Block{Timestamp: types.Timestamp(secondsPerDay - 2*blockTime + i*blockTime)}
+		if i == 1 {
+			block.Transactions = []types.Transaction{{
+				CoinOutputs: []types.CoinOutput{{Value: types.NewCurrency64(30)}, {Value: types.NewCurrency64(12)}},
+				MinerFees:   []types.Currency{types.NewCurrency64(2)},
+			}}
+		}
+		blocks = append(blocks, block)
+	}
+	update := func(fn func(tx *bolt.Tx)) {
+		err := e.db.Update(func(tx *bolt.Tx) error {
+			fn(tx)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	update(func(tx *bolt.Tx) {
+		for i, block := range blocks {
+			dbAddSummaries(tx, block, heights[i])
+			dbAddAggregates(tx, block, heights[i])
+		}
+	})
+
+	list := func(query modules.AggregatesQuery) []modules.ChainAggregate {
+		var all []modules.ChainAggregate
+		for {
+			aggregates, next, err := e.Aggregates(query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			all = append(all, aggregates...)
+			if next == "" {
+				return all
+			}
+			query.Cursor = next
+		}
+	}
+
+	// the parent of the first block is unknown, such that only three blocks have a block time
+	days := list(modules.AggregatesQuery{Period: modules.AggregatePeriodDay, Limit: 1})
+	if len(days) != 2 || days[0].Start != 0 || days[1].Start != secondsPerDay ||
+		days[0].Blocks != 2 || days[0].Transactions != 1 || !days[0].MinerFees.Equals64(2) || !days[0].CoinOutputValue.Equals64(42) ||
+		days[0].AverageBlockTime != blockTime || days[1].Blocks != 2 || days[1].AverageBlockTime != blockTime ||
+		days[0].AverageBlockSize <= days[1].AverageBlockSize {
+		t.Errorf("unexpected daily aggregates: %+v", days)
+	}
+	ranges := list(modules.AggregatesQuery{Period: modules.AggregatePeriodBlocks, Start: 1500, Reverse: true})
+	if len(ranges) != 1 || ranges[0].Start != 1000 || ranges[0].Blocks != 2 || ranges[0].Transactions != 0 {
+		t.Errorf("unexpected aggregates per range of heights: %+v", ranges)
+	}
+	if ranges := list(modules.AggregatesQuery{Period: modules.AggregatePeriodBlocks, End: 999}); len(ranges) != 1 || ranges[0].Start != 0 {
+		t.Errorf("unexpected aggregates per range of heights up to height 999: %+v", ranges)
+	}
+	if _, _, err := e.Aggregates(modules.AggregatesQuery{Period: "week"}); err != modules.ErrUnknownAggregatePeriod {
+		t.Errorf("expected an unknown period to be refused: %v", err)
+	}
+	if _, _, err := e.Aggregates(modules.AggregatesQuery{Period: modules.AggregatePeriodDay, Cursor: "00"}); err != modules.ErrInvalidAggregatesCursor {
+		t.Errorf("expected an invalid cursor to be refused: %v", err)
+	}
+
+	// reverting the blocks removes the aggregates
+	update(func(tx *bolt.Tx) {
+		for i := len(blocks) - 1; i >= 0; i-- {
+			dbRemoveAggregates(tx, blocks[i], heights[i])
+			dbRemoveSummaries(tx, blocks[i], heights[i])
+		}
+	})
+	err = e.db.View(func(tx *bolt.Tx) error {
+		if !bucketIsEmpty(tx.Bucket(bucketDailyAggregates)) || !bucketIsEmpty(tx.Bucket(bucketBlocksAggregates)) {
+			t.Error("expected the aggregates to be removed")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,409 @@
+package explorer
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+	"github.com/threefoldtech/rivine/types"
+
+	"github.com/rivine/bbolt"
+)
+
+// The balances are updated incrementally as blocks are applied and reverted,
+// using the address transactions of each block, see addressHistoryOfBlock:
+//
+//   - bucketAddressBalances maps each unlock hash to its addressBalance;
+//   - bucketRichList contains a key per unlock hash with a coin balance, see richListKey,
+//     such that the addresses are ordered by coin balance;
+//   - bucketBalanceDistribution maps the index of each range of coin balances, see balanceRangeIndex,
+//     to the balanceRange of the addresses with a balance within that range;
+//   - bucketLockedCoins maps lock times (8 bytes, big-endian) to the value of the coin outputs
+//     which are locked until then, being either time-locked or miner payouts which have to mature.
+
+type (
+	// addressBalance is the balance of an unlock hash, as stored in bucketAddressBalances.
+	addressBalance struct {
+		Coins       types.Currency
+		BlockStakes types.Currency
+	}
+
+	// balanceRange is a range of coin balances, as stored in bucketBalanceDistribution.
+	balanceRange struct {
+		Addresses uint64
+		Coins     types.Currency
+	}
+
+	// lockedCoins is the value of the coin outputs with the same lock time,
+	// created and spent by a single block.
+	lockedCoins struct {
+		created types.Currency
+		spent   types.Currency
+	}
+)
+
+// richListKey returns the key of the given unlock hash and its coin balance in bucketRichList:
+// the size of the (big-endian) balance (1 byte), the balance and the unlock hash,
+// such that the keys are ordered by balance.
+func richListKey(coins types.Currency, uh types.UnlockHash) []byte {
+	b := coins.Big().Bytes()
+	return append(append([]byte{byte(len(b))}, b...), siabin.Marshal(uh)...)
+}
+
+// parseRichListKey returns the coin balance and unlock hash of the given key of bucketRichList.
+func parseRichListKey(key []byte) (types.Currency, types.UnlockHash, error) {
+	var uh types.UnlockHash
+	if len(key) == 0 || len(key) < 1+int(key[0]) {
+		return types.Currency{}, uh, errors.New("invalid rich list key")
+	}
+	coins := types.NewCurrency(new(big.Int).SetBytes(key[1 : 1+key[0]]))
+	err := siabin.Unmarshal(key[1+key[0]:], &uh)
+	return coins, uh, err
+}
+
+// balanceRangeIndex returns the index of the range of coin balances the given balance is part of:
+// 0 for balances below one coin, n for balances of at least 10^(n-1) coins and below 10^n coins.
+func (e *Explorer) balanceRangeIndex(coins types.Currency) byte {
+	whole := coins.Div(e.chainCts.CurrencyUnits.OneCoin)
+	if whole.IsZero() {
+		return 0
+	}
+	return byte(len(whole.Big().String()))
+}
+
+// balanceRangeBounds returns the (inclusive) minimum and (exclusive) maximum
+// coin balance of the range with the given index, see balanceRangeIndex.
+func (e *Explorer) balanceRangeBounds(index byte) (min, max types.Currency) {
+	oneCoin := e.chainCts.CurrencyUnits.OneCoin
+	max = oneCoin.Mul(types.NewCurrency(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(index)), nil)))
+	if index == 0 {
+		return types.ZeroCurrency, max
+	}
+	return max.Div64(10), max
+}
+
+// lockedCoinsKey returns the key of the given lock time in bucketLockedCoins.
+func lockedCoinsKey(lockTime uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, lockTime)
+	return key
+}
+
+// coinOutputLockTime returns the lock time of the given coin output, if it is time-locked.
+func coinOutputLockTime(co types.CoinOutput) (uint64, bool) {
+	tl, ok := co.Condition.Condition.(*types.TimeLockCondition)
+	if !ok {
+		return 0, false
+	}
+	return tl.LockTime, true
+}
+
+// lockedCoinsOfBlock returns the value of the locked coin outputs the given block creates and spends,
+// per lock time. The miner payouts are locked until they mature. All outputs of the block,
+// as well as the outputs it spends, have to be stored in the database.
+func (e *Explorer) lockedCoinsOfBlock(tx *bolt.Tx, block types.Block, height types.BlockHeight) map[uint64]*lockedCoins {
+	locked := make(map[uint64]*lockedCoins)
+	lock := func(lockTime uint64) *lockedCoins {
+		lc, ok := locked[lockTime]
+		if !ok {
+			lc = &lockedCoins{created: types.ZeroCurrency, spent: types.ZeroCurrency}
+			locked[lockTime] = lc
+		}
+		return lc
+	}
+	// miner payouts can only be spent once matured,
+	// and thus do not have to be unlocked when spent
+	for _, payout := range block.MinerPayouts {
+		lc := lock(uint64(height + e.chainCts.MaturityDelay))
+		lc.created = lc.created.Add(payout.Value)
+	}
+	for _, txn := range block.Transactions {
+		for _, ci := range txn.CoinInputs {
+			var co types.CoinOutput
+			if err := dbGetAndDecode(bucketCoinOutputs, ci.ParentID, &co)(tx); err != nil {
+				continue
+			}
+			if lockTime, ok := coinOutputLockTime(co); ok {
+				lc := lock(lockTime)
+				lc.spent = lc.spent.Add(co.Value)
+			}
+		}
+		for _, co := range txn.CoinOutputs {
+			if lockTime, ok := coinOutputLockTime(co); ok {
+				lc := lock(lockTime)
+				lc.created = lc.created.Add(co.Value)
+			}
+		}
+	}
+	return locked
+}
+
+// dbAddBalances updates the balances, their distribution and the locked coins for the given block,
+// applied at the given height. It has to be called once all outputs of the block are stored.
+func (e *Explorer) dbAddBalances(tx *bolt.Tx, block types.Block, height types.BlockHeight) {
+	e.dbUpdateBalances(tx, block, height, false)
+}
+
+// dbRemoveBalances reverts the balances, their distribution and the locked coins for the given block.
+// It has to be called prior to removing any output of the block.
+func (e *Explorer) dbRemoveBalances(tx *bolt.Tx, block types.Block, height types.BlockHeight) {
+	e.dbUpdateBalances(tx, block, height, true)
+}
+
+func (e *Explorer) dbUpdateBalances(tx *bolt.Tx, block types.Block, height types.BlockHeight, revert bool) {
+	entries := addressHistoryOfBlock(tx, block, height)
+	if revert {
+		// revert the transactions in reverse order, such that no balance drops below zero
+		for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+			entries[i], entries[j] = entries[j], entries[i]
+		}
+	}
+	blockStakesCreated, blockStakesSpent := types.ZeroCurrency, types.ZeroCurrency
+	for _, entry := range entries {
+		coinsIn, coinsOut := entry.txn.CoinsReceived, entry.txn.CoinsSent
+		blockStakesIn, blockStakesOut := entry.txn.BlockStakesReceived, entry.txn.BlockStakesSent
+		if revert {
+			coinsIn, coinsOut = coinsOut, coinsIn
+			blockStakesIn, blockStakesOut = blockStakesOut, blockStakesIn
+		}
+		balance := dbGetAddressBalance(tx, entry.unlockHash)
+		e.dbSetAddressBalance(tx, entry.unlockHash, balance, addressBalance{
+			Coins:       balance.Coins.Add(coinsIn).Sub(coinsOut),
+			BlockStakes: balance.BlockStakes.Add(blockStakesIn).Sub(blockStakesOut),
+		})
+		blockStakesCreated = blockStakesCreated.Add(blockStakesIn)
+		blockStakesSpent = blockStakesSpent.Add(blockStakesOut)
+	}
+
+	var blockStakes types.Currency
+	assertNil(dbGetInternal(internalBlockStakeSupply, &blockStakes)(tx))
+	assertNil(dbSetInternal(internalBlockStakeSupply, blockStakes.Add(blockStakesCreated).Sub(blockStakesSpent))(tx))
+
+	b := tx.Bucket(bucketLockedCoins)
+	for lockTime, lc := range e.lockedCoinsOfBlock(tx, block, height) {
+		created, spent := lc.created, lc.spent
+		if revert {
+			created, spent = spent, created
+		}
+		key := lockedCoinsKey(lockTime)
+		value := types.ZeroCurrency
+		if v := b.Get(key); v != nil {
+			assertNil(siabin.Unmarshal(v, &value))
+		}
+		value = value.Add(created).Sub(spent)
+		if value.IsZero() {
+			assertNil(b.Delete(key))
+		} else {
+			assertNil(b.Put(key, siabin.Marshal(value)))
+		}
+	}
+}
+
+// dbGetAddressBalance returns the balance of the given unlock hash, zero if it has none.
+func dbGetAddressBalance(tx *bolt.Tx, uh types.UnlockHash) addressBalance {
+	balance := addressBalance{Coins: types.ZeroCurrency, BlockStakes: types.ZeroCurrency}
+	err := dbGetAndDecode(bucketAddressBalances, uh, &balance)(tx)
+	if err != nil && err != errNotExist {
+		panic(err)
+	}
+	return balance
+}
+
+// dbSetAddressBalance updates the balance of the given unlock hash,
+// as well as its position in the rich list and the balance distribution.
+func (e *Explorer) dbSetAddressBalance(tx *bolt.Tx, uh types.UnlockHash, old, balance addressBalance) {
+	if balance.Coins.IsZero() && balance.BlockStakes.IsZero() {
+		mustDelete(tx.Bucket(bucketAddressBalances), uh)
+	} else {
+		mustPut(tx.Bucket(bucketAddressBalances), uh, balance)
+	}
+	if old.Coins.Equals(balance.Coins) {
+		return
+	}
+	if !old.Coins.IsZero() {
+		assertNil(tx.Bucket(bucketRichList).Delete(richListKey(old.Coins, uh)))
+		e.dbUpdateBalanceRange(tx, old.Coins, false)
+	}
+	if !balance.Coins.IsZero() {
+		assertNil(tx.Bucket(bucketRichList).Put(richListKey(balance.Coins, uh), nil))
+		e.dbUpdateBalanceRange(tx, balance.Coins, true)
+	}
+}
+
+// dbUpdateBalanceRange adds an address with the given coin balance to its range
+// of the balance distribution, or removes it from it.
+func (e *Explorer) dbUpdateBalanceRange(tx *bolt.Tx, coins types.Currency, add bool) {
+	b := tx.Bucket(bucketBalanceDistribution)
+	key := []byte{e.balanceRangeIndex(coins)}
+	r := balanceRange{Coins: types.ZeroCurrency}
+	if v := b.Get(key); v != nil {
+		assertNil(siabin.Unmarshal(v, &r))
+	}
+	if add {
+		r.Addresses++
+		r.Coins = r.Coins.Add(coins)
+	} else {
+		r.Addresses--
+		r.Coins = r.Coins.Sub(coins)
+	}
+	if r.Addresses == 0 {
+		assertNil(b.Delete(key))
+	} else {
+		assertNil(b.Put(key, siabin.Marshal(r)))
+	}
+}
+
+// dbLockedCoins returns the value of the unspent coin outputs which are still locked
+// at the given block height and time.
+func dbLockedCoins(tx *bolt.Tx, height types.BlockHeight, timestamp types.Timestamp) (types.Currency, error) {
+	locked := types.ZeroCurrency
+	c := tx.Bucket(bucketLockedCoins).Cursor()
+	add := func(v []byte) error {
+		var value types.Currency
+		err := siabin.Unmarshal(v, &value)
+		if err != nil {
+			return err
+		}
+		locked = locked.Add(value)
+		return nil
+	}
+	// outputs locked until a block height which is not reached yet
+	for k, v := c.Seek(lockedCoinsKey(uint64(height) + 1)); k != nil && binary.BigEndian.Uint64(k) < types.LockTimeMinTimestampValue; k, v = c.Next() {
+		if err := add(v); err != nil {
+			return types.Currency{}, err
+		}
+	}
+	// outputs locked until a time which is not reached yet
+	from := uint64(timestamp) + 1
+	if from < types.LockTimeMinTimestampValue {
+		from = types.LockTimeMinTimestampValue
+	}
+	for k, v := c.Seek(lockedCoinsKey(from)); k != nil; k, v = c.Next() {
+		if err := add(v); err != nil {
+			return types.Currency{}, err
+		}
+	}
+	return locked, nil
+}
+
+// RichList returns the given amount of addresses with the highest coin balance,
+// ordered from the highest to the lowest balance.
+func (e *Explorer) RichList(n int) (balances []modules.AddressBalance, err error) {
+	err = e.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(bucketRichList).Cursor()
+		for k, _ := c.Last(); k != nil && len(balances) < n; k, _ = c.Prev() {
+			coins, uh, err := parseRichListKey(k)
+			if err != nil {
+				return err
+			}
+			var balance addressBalance
+			err = dbGetAndDecode(bucketAddressBalances, uh, &balance)(tx)
+			if err != nil {
+				return err
+			}
+			balances = append(balances, modules.AddressBalance{
+				UnlockHash:  uh,
+				Coins:       coins,
+				BlockStakes: balance.BlockStakes,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return balances, nil
+}
+
+// AddressBalance returns the balance of the given address, a zero balance if it has no unspent outputs.
+func (e *Explorer) AddressBalance(uh types.UnlockHash) (modules.AddressBalance, error) {
+	balance := addressBalance{Coins: types.ZeroCurrency, BlockStakes: types.ZeroCurrency}
+	err := e.db.View(dbGetAndDecode(bucketAddressBalances, uh, &balance))
+	if err != nil && err != errNotExist {
+		return modules.AddressBalance{}, err
+	}
+	return modules.AddressBalance{
+		UnlockHash:  uh,
+		Coins:       balance.Coins,
+		BlockStakes: balance.BlockStakes,
+	}, nil
+}
+
+// BalanceDistribution returns the amount of addresses and their total balance,
+// per range of coin balances, ordered from the lowest to the highest range.
+// All ranges up to the highest range with addresses are returned, including the empty ones.
+func (e *Explorer) BalanceDistribution() (ranges []modules.BalanceRange, err error) {
+	err = e.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketBalanceDistribution).ForEach(func(k, v []byte) error {
+			var r balanceRange
+			err := siabin.Unmarshal(v, &r)
+			if err != nil {
+				return err
+			}
+			// add the empty ranges preceding this range
+			for index := byte(len(ranges)); index <= k[0]; index++ {
+				min, max := e.balanceRangeBounds(index)
+				ranges = append(ranges, modules.BalanceRange{
+					MinBalance: min,
+					MaxBalance: max,
+					Coins:      types.ZeroCurrency,
+				})
+			}
+			ranges[k[0]].Addresses = r.Addresses
+			ranges[k[0]].Coins = r.Coins
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ranges, nil
+}
+
+// Supply returns the current supply of coins and block stakes.
+func (e *Explorer) Supply() (stats modules.SupplyStats, err error) {
+	err = e.db.View(func(tx *bolt.Tx) error {
+		err := dbGetInternal(internalBlockHeight, &stats.Height)(tx)
+		if err != nil {
+			return err
+		}
+		err = dbGetInternal(internalBlockStakeSupply, &stats.BlockStakes)(tx)
+		if err != nil {
+			return err
+		}
+		stats.Coins = types.ZeroCurrency
+		err = tx.Bucket(bucketBalanceDistribution).ForEach(func(_, v []byte) error {
+			var r balanceRange
+			err := siabin.Unmarshal(v, &r)
+			if err != nil {
+				return err
+			}
+			stats.Coins = stats.Coins.Add(r.Coins)
+			stats.Addresses += r.Addresses
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		// the timestamp of the block is taken from the explorer itself, rather than from
+		// the consensus set, which might already have moved on to a different block at this height
+		v := tx.Bucket(bucketBlockSummaries).Get(blockSummaryKey(stats.Height))
+		if v == nil {
+			return errors.New("explorer is missing the summary of the block at its height")
+		}
+		var block modules.BlockSummary
+		err = siabin.Unmarshal(v, &block)
+		if err != nil {
+			return err
+		}
+		stats.LockedCoins, err = dbLockedCoins(tx, stats.Height, block.Timestamp)
+		return err
+	})
+	if err != nil {
+		return modules.SupplyStats{}, err
+	}
+	stats.ActiveCoins = stats.Coins.Sub(stats.LockedCoins)
+	return stats, nil
+}
@@ -0,0 +1,183 @@
+package explorer
+
+import (
+	"os"
+	"testing"
+
+	"github.com/threefoldtech/rivine/build"
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+
+	"github.com/rivine/bbolt"
+)
+
+// TestBalances tracks the balances of a few blocks, including a time-locked output
+// and a miner payout which has to mature, and checks that reverting the blocks reverts them.
+func TestBalances(t *testing.T) {
+	dir := build.TempDir(modules.ExplorerDir, t.Name())
+	os.RemoveAll(dir)
+	e := &Explorer{persistDir: dir, chainCts: types.StandardnetChainConstants()}
+	err := e.initPersist()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.db.Close()
+
+	oneCoin := e.chainCts.CurrencyUnits.OneCoin
+	coins := func(n uint64) types.Currency { return oneCoin.Mul64(n) }
+	alice := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: crypto.Hash{1}}
+	bob := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: crypto.Hash{2}}
+	carol := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: crypto.Hash{3}}
+	// block 1 pays alice and carol, the latter being time-locked until height 10,
+	// block 2 has alice pay bob returning change, and mines block stakes for bob
+	txn1 := types.Transaction{
+		CoinOutputs: []types.CoinOutput{
+			{Value: coins(500), Condition: types.NewCondition(types.NewUnlockHashCondition(alice))},
+			{Value: coins(20), Condition: types.NewCondition(types.NewTimeLockCondition(10, types.NewUnlockHashCondition(carol)))},
+		},
+		BlockStakeOutputs: []types.BlockStakeOutput{
+			{Value: types.NewCurrency64(7), Condition: types.NewCondition(types.NewUnlockHashCondition(bob))},
+		},
+	}
+	block1 := types.Block{
+		Timestamp:    1,
+		MinerPayouts: []types.MinerPayout{{Value: coins(10), UnlockHash: alice}},
+		Transactions: []types.Transaction{txn1},
+	}
+	txn2 := types.Transaction{
+		CoinInputs: []types.CoinInput{{ParentID: txn1.CoinOutputID(0)}},
+		CoinOutputs: []types.CoinOutput{
+			{Value: coins(3), Condition: types.NewCondition(types.NewUnlockHashCondition(bob))},
+			{Value: coins(497), Condition: types.NewCondition(types.NewUnlockHashCondition(alice))},
+		},
+	}
+	block2 := types.Block{Timestamp: 2, Transactions: []types.Transaction{txn2}}
+
+	update := func(fn func(tx *bolt.Tx)) {
+		err := e.db.Update(func(tx *bolt.Tx) error {
+			fn(tx)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	update(func(tx *bolt.Tx) {
+		for _, block := range []types.Block{block1, block2} {
+			for _, txn := range block.Transactions {
+				for i, co := range txn.CoinOutputs {
+					dbAddCoinOutput(tx, txn.CoinOutputID(uint64(i)), co)
+				}
+				for i, bso := range txn.BlockStakeOutputs {
+					dbAddBlockStakeOutput(tx, txn.BlockStakeOutputID(uint64(i)), bso)
+				}
+			}
+		}
+		e.dbAddBalances(tx, block1, 1)
+		e.dbAddBalances(tx, block2, 2)
+		dbAddSummaries(tx, block1, 1)
+		dbAddSummaries(tx, block2, 2)
+		assertNil(dbSetInternal(internalBlockHeight, types.BlockHeight(2))(tx))
+	})
+
+	balances, err := e.RichList(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(balances) != 2 ||
+		balances[0].UnlockHash != alice || !balances[0].Coins.Equals(coins(507)) ||
+		balances[1].UnlockHash != carol || !balances[1].Coins.Equals(coins(20)) {
+		t.Errorf("unexpected rich list: %+v", balances)
+	}
+	balances, err = e.RichList(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(balances) != 3 || balances[2].UnlockHash != bob || !balances[2].Coins.Equals(coins(3)) || !balances[2].BlockStakes.Equals64(7) {
+		t.Errorf("unexpected rich list: %+v", balances)
+	}
+
+	if balance, err := e.AddressBalance(bob); err != nil || !balance.Coins.Equals(coins(3)) || !balance.BlockStakes.Equals64(7) {
+		t.Errorf("unexpected balance of bob: %+v (%v)", balance, err)
+	}
+
+	ranges, err := e.BalanceDistribution()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// bob has between 1 and 10 coins, carol between 10 and 100 and alice between 100 and 1000
+	if len(ranges) != 4 || ranges[0].Addresses != 0 ||
+		ranges[1].Addresses != 1 || !ranges[1].Coins.Equals(coins(3)) || !ranges[1].MinBalance.Equals(coins(1)) ||
+		ranges[2].Addresses != 1 || !ranges[2].MaxBalance.Equals(coins(100)) ||
+		ranges[3].Addresses != 1 || !ranges[3].Coins.Equals(coins(507)) {
+		t.Errorf("unexpected balance distribution: %+v", ranges)
+	}
+
+	lockedCoins := func(height types.BlockHeight) types.Currency {
+		var locked types.Currency
+		err := e.db.View(func(tx *bolt.Tx) (err error) {
+			locked, err = dbLockedCoins(tx, height, 2)
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return locked
+	}
+	// the time-locked output unlocks at height 10, the miner payout once matured
+	maturity := 1 + e.chainCts.MaturityDelay
+	if locked := lockedCoins(2); !locked.Equals(coins(30)) {
+		t.Errorf("expected 30 coins to be locked at height 2, got %v", locked)
+	}
+	if locked := lockedCoins(10); !locked.Equals(coins(10)) {
+		t.Errorf("expected 10 coins to be locked at height 10, got %v", locked)
+	}
+	if locked := lockedCoins(maturity); !locked.IsZero() {
+		t.Errorf("expected no coins to be locked at height %d, got %v", maturity, locked)
+	}
+
+	// the supply is computed by the explorer on its own, without a consensus set
+	supply, err := e.Supply()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if supply.Height != 2 || !supply.Coins.Equals(coins(530)) || !supply.LockedCoins.Equals(coins(30)) ||
+		!supply.ActiveCoins.Equals(coins(500)) || !supply.BlockStakes.Equals64(7) {
+		t.Errorf("unexpected supply: %+v", supply)
+	}
+
+	// reverting the blocks reverts all balances
+	update(func(tx *bolt.Tx) {
+		e.dbRemoveBalances(tx, block2, 2)
+		e.dbRemoveBalances(tx, block1, 1)
+		dbRemoveSummaries(tx, block2, 2)
+		dbRemoveSummaries(tx, block1, 1)
+	})
+	if balances, err := e.RichList(10); err != nil || len(balances) != 0 {
+		t.Errorf("expected the rich list to be empty: %+v (%v)", balances, err)
+	}
+	if balance, err := e.AddressBalance(alice); err != nil || !balance.Coins.IsZero() || !balance.BlockStakes.IsZero() {
+		t.Errorf("expected the balance of alice to be zero: %+v (%v)", balance, err)
+	}
+	if ranges, err := e.BalanceDistribution(); err != nil || len(ranges) != 0 {
+		t.Errorf("expected the balance distribution to be empty: %+v (%v)", ranges, err)
+	}
+	if locked := lockedCoins(0); !locked.IsZero() {
+		t.Errorf("expected no coins to be locked, got %v", locked)
+	}
+	err = e.db.View(func(tx *bolt.Tx) error {
+		if !bucketIsEmpty(tx.Bucket(bucketAddressBalances)) {
+			t.Error("expected the address balances to be removed")
+		}
+		var blockStakes types.Currency
+		err := dbGetInternal(internalBlockStakeSupply, &blockStakes)(tx)
+		if err == nil && !blockStakes.IsZero() {
+			t.Errorf("expected the block stake supply to be reverted, got %v", blockStakes)
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
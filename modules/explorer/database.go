@@ -1,7 +1,10 @@
 package explorer
 
 import (
+	"bytes"
+	"encoding/binary"
 	"errors"
+	"math"
 
 	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
 	"github.com/threefoldtech/rivine/types"
@@ -27,12 +30,31 @@ var (
 	// used to map (single-signature) wallet addresses to all the
 	// multisig addresses they are part of
 	bucketWalletAddressToMultiSigAddressMapping = []byte("WalletAddressToMultiSigAddressMapping")
+	// used to map addresses to the transactions creating or spending their outputs,
+	// see addresshistory.go
+	bucketAddressHistory = []byte("AddressHistory")
+	// used to track the balance of all addresses, rank them by coin balance,
+	// group them per range of coin balances and track the locked coins,
+	// see balances.go
+	bucketAddressBalances     = []byte("AddressBalances")
+	bucketRichList            = []byte("RichList")
+	bucketBalanceDistribution = []byte("BalanceDistribution")
+	bucketLockedCoins         = []byte("LockedCoins")
+	// used to list the blocks and transactions by height, see summaries.go
+	bucketBlockSummaries       = []byte("BlockSummaries")
+	bucketTransactionSummaries = []byte("TransactionSummaries")
+	// used to aggregate the blocks per day and per range of heights, see aggregates.go
+	bucketDailyAggregates  = []byte("DailyAggregates")
+	bucketBlocksAggregates = []byte("BlocksAggregates")
+	// used to record the most recent reorgs, see reorgs.go
+	bucketReorgs = []byte("Reorgs")
 
 	errNotExist = errors.New("entry does not exist")
 
 	// keys for bucketInternal
-	internalBlockHeight  = []byte("BlockHeight")
-	internalRecentChange = []byte("RecentChange")
+	internalBlockHeight      = []byte("BlockHeight")
+	internalRecentChange     = []byte("RecentChange")
+	internalBlockStakeSupply = []byte("BlockStakeSupply")
 )
 
 // These functions all return a 'func(*bolt.Tx) error', which, allows them to
@@ -116,3 +138,74 @@ func dbGetInternal(key []byte, val interface{}) func(*bolt.Tx) error {
 		return siabin.Unmarshal(tx.Bucket(bucketInternal).Get(key), val)
 	}
 }
+
+// seekPage positions the cursor at the first key of a page, in the given order:
+// the key following (or preceding, if reversed) the given key, if any,
+// or the first (or last) key within the range starting at start and ending before end otherwise,
+// end being nil if the range has no end.
+func seekPage(c *bolt.Cursor, after []byte, reverse bool, start, end []byte) ([]byte, []byte) {
+	var k, v []byte
+	switch {
+	case after != nil && reverse:
+		k, v = c.Seek(after)
+		if k == nil {
+			k, v = c.Last()
+		}
+		if k != nil && bytes.Compare(k, after) >= 0 {
+			k, v = c.Prev()
+		}
+	case after != nil:
+		k, v = c.Seek(after)
+		if bytes.Equal(k, after) {
+			k, v = c.Next()
+		}
+	case reverse && end != nil:
+		k, v = c.Seek(end)
+		if k == nil {
+			k, v = c.Last()
+		} else {
+			k, v = c.Prev()
+		}
+	case reverse:
+		k, v = c.Last()
+	default:
+		k, v = c.Seek(start)
+	}
+	return k, v
+}
+
+// nextInOrder moves the cursor to the next key, in the given order.
+func nextInOrder(c *bolt.Cursor, reverse bool) ([]byte, []byte) {
+	if reverse {
+		return c.Prev()
+	}
+	return c.Next()
+}
+
+// dbListRange calls the given function for the entries of the given bucket within the (inclusive) range
+// of indices, the index of an entry being the first 8 bytes (big-endian) of its key, in the given order,
+// starting after the given key, until the function returns false. There is no maximum if max is math.MaxUint64.
+func dbListRange(b *bolt.Bucket, after []byte, reverse bool, min, max uint64, fn func(k, v []byte) (bool, error)) error {
+	start := make([]byte, 8)
+	binary.BigEndian.PutUint64(start, min)
+	var end []byte
+	if max != math.MaxUint64 {
+		end = make([]byte, 8)
+		binary.BigEndian.PutUint64(end, max+1)
+	}
+	c := b.Cursor()
+	for k, v := seekPage(c, after, reverse, start, end); k != nil; k, v = nextInOrder(c, reverse) {
+		index := binary.BigEndian.Uint64(k[:8])
+		if reverse && index < min || !reverse && index > max {
+			break
+		}
+		if index < min || index > max {
+			continue // a cursor beyond the range
+		}
+		more, err := fn(k, v)
+		if err != nil || !more {
+			return err
+		}
+	}
+	return nil
+}
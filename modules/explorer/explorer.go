@@ -79,6 +79,9 @@ func New(cs modules.ConsensusSet, persistDir string, bcInfo types.BlockchainInfo
 		return nil, err
 	}
 
+	// the explorer receives its changes synchronously, as it looks up the child targets
+	// and blocks of the changes it processes in the consensus set, which would otherwise
+	// already reflect a later state of the blockchain
 	err = cs.ConsensusSetSubscribe(e, recentChange, nil)
 	if err != nil {
 		// TODO: restart from 0
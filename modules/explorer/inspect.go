@@ -0,0 +1,68 @@
+package explorer
+
+import (
+	"bytes"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+	"github.com/threefoldtech/rivine/types"
+
+	"github.com/rivine/bbolt"
+)
+
+// InspectDatabase implements modules.DatabaseInspector.InspectDatabase,
+// calling the given function within a read transaction.
+func (e *Explorer) InspectDatabase(fn func(*bolt.Tx) error) error {
+	return e.db.View(fn)
+}
+
+// DecodeDatabaseValue implements modules.DatabaseInspector.DecodeDatabaseValue,
+// decoding the values of the explorer buckets, except the sets of transaction IDs.
+func (e *Explorer) DecodeDatabaseValue(bucket [][]byte, key, value []byte) (interface{}, bool) {
+	var v interface{}
+	switch name := bucket[0]; {
+	case len(bucket) == 2 && bytes.Equal(name, bucketAddressHistory):
+		v = new(modules.AddressTransaction)
+	case len(bucket) != 1:
+		return nil, false
+	case bytes.Equal(name, bucketBlockFacts):
+		v = new(blockFacts)
+	case bytes.Equal(name, bucketBlockIDs), bytes.Equal(name, bucketTransactionIDs):
+		v = new(types.BlockHeight)
+	case bytes.Equal(name, bucketBlockTargets):
+		v = new(types.Target)
+	case bytes.Equal(name, bucketCoinOutputs):
+		v = new(types.CoinOutput)
+	case bytes.Equal(name, bucketBlockStakeOutputs):
+		v = new(types.BlockStakeOutput)
+	case bytes.Equal(name, bucketAddressBalances):
+		v = new(addressBalance)
+	case bytes.Equal(name, bucketBalanceDistribution):
+		v = new(balanceRange)
+	case bytes.Equal(name, bucketLockedCoins):
+		v = new(types.Currency)
+	case bytes.Equal(name, bucketBlockSummaries):
+		v = new(modules.BlockSummary)
+	case bytes.Equal(name, bucketTransactionSummaries):
+		v = new(modules.TransactionSummary)
+	case bytes.Equal(name, bucketDailyAggregates), bytes.Equal(name, bucketBlocksAggregates):
+		v = new(aggregate)
+	case bytes.Equal(name, bucketReorgs):
+		v = new(modules.ExplorerReorg)
+	case bytes.Equal(name, bucketInternal) && bytes.Equal(key, internalBlockHeight):
+		v = new(types.BlockHeight)
+	case bytes.Equal(name, bucketInternal) && bytes.Equal(key, internalRecentChange):
+		v = new(modules.ConsensusChangeID)
+	case bytes.Equal(name, bucketInternal) && bytes.Equal(key, internalBlockStakeSupply):
+		v = new(types.Currency)
+	default:
+		return nil, false
+	}
+	if err := siabin.Unmarshal(value, v); err != nil {
+		return nil, false
+	}
+	return v, true
+}
+
+// enforce that the database of the Explorer can be inspected while running
+var _ modules.DatabaseInspector = (*Explorer)(nil)
@@ -0,0 +1,79 @@
+package explorer
+
+import (
+	"bytes"
+	"path/filepath"
+
+	"github.com/threefoldtech/rivine/persist"
+
+	"github.com/rivine/bbolt"
+)
+
+// dbMigrations are the migrations of the explorer database,
+// a migration from the previous version has to be added
+// each time the version of explorerMetadata is bumped.
+//
+// The explorer indexes the blocks it receives from the consensus set,
+// and does not store the blocks themselves. Migrations adding a new index
+// therefore reset the database, such that the explorer rescans the blockchain
+// and indexes all blocks from the genesis block on.
+var dbMigrations = []persist.Migration{
+	{
+		From: "1.0.8", To: "1.1.0",
+		Description: "index the address history, rescanning the blockchain",
+		Migrate:     resetDatabase,
+	},
+	{
+		From: "1.1.0", To: "1.2.0",
+		Description: "index the address balances, rescanning the blockchain",
+		Migrate:     resetDatabase,
+	},
+	{
+		From: "1.2.0", To: "1.3.0",
+		Description: "summarize the blocks and transactions, rescanning the blockchain",
+		Migrate:     resetDatabase,
+	},
+	{
+		From: "1.3.0", To: "1.4.0",
+		Description: "aggregate the blocks per day and per range of heights, rescanning the blockchain",
+		Migrate:     resetDatabase,
+	},
+}
+
+// resetDatabase deletes all data indexed by the explorer, except for the recorded reorgs,
+// which cannot be recovered from the blockchain. The buckets and internal defaults
+// are created again by initPersist, which subscribes the explorer from the beginning.
+func resetDatabase(tx *bolt.Tx) error {
+	var names [][]byte
+	err := tx.ForEach(func(name []byte, _ *bolt.Bucket) error {
+		if bytes.Equal(name, []byte("Metadata")) || bytes.Equal(name, bucketReorgs) {
+			return nil
+		}
+		names = append(names, append([]byte(nil), name...))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		err = tx.DeleteBucket(name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MigrateDatabase migrates the explorer database, stored in the given persist directory,
+// to the version used by this explorer module, returning the applied migrations.
+// It has to be called prior to creating the explorer.
+func MigrateDatabase(persistDir string, opts persist.MigrationOptions) ([]persist.Migration, error) {
+	return persist.MigrateDatabase(explorerMetadata, filepath.Join(persistDir, DatabaseFilename), dbMigrations, opts)
+}
+
+// CompactDatabase compacts the explorer database, stored in the given persist directory,
+// reclaiming the space of the pages freed over time.
+// It has to be called prior to creating the explorer.
+func CompactDatabase(persistDir string) (persist.DatabaseCompaction, error) {
+	return persist.CompactDatabase(filepath.Join(persistDir, DatabaseFilename))
+}
@@ -0,0 +1,101 @@
+package explorer
+
+import (
+	"os"
+	"testing"
+
+	"github.com/threefoldtech/rivine/build"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/persist"
+	"github.com/threefoldtech/rivine/types"
+
+	"github.com/rivine/bbolt"
+)
+
+// TestMigrateDatabaseReindex migrates a database created prior to the address history,
+// balances, summaries and aggregates, ensuring that the explorer rescans the blockchain
+// once migrated, while keeping the recorded reorgs.
+func TestMigrateDatabaseReindex(t *testing.T) {
+	dir := build.TempDir(modules.ExplorerDir, t.Name())
+	os.RemoveAll(dir)
+	e := &Explorer{persistDir: dir, chainCts: types.StandardnetChainConstants()}
+	err := e.initPersist()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// store some indexed data and downgrade the database to the previous version
+	reorgKey, reorgValue := []byte{0, 0, 0, 0, 0, 0, 0, 1}, []byte{1, 2, 3}
+	err = e.db.Update(func(tx *bolt.Tx) error {
+		err := dbSetInternal(internalRecentChange, modules.ConsensusChangeID{1})(tx)
+		if err != nil {
+			return err
+		}
+		err = dbSetInternal(internalBlockHeight, types.BlockHeight(42))(tx)
+		if err != nil {
+			return err
+		}
+		err = tx.Bucket(bucketBlockIDs).Put([]byte{4, 5, 6}, []byte{7})
+		if err != nil {
+			return err
+		}
+		return tx.Bucket(bucketReorgs).Put(reorgKey, reorgValue)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.db.Version = "1.0.8"
+	err = e.db.SaveMetadata()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = e.db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	migrations, err := MigrateDatabase(dir, persist.MigrationOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) != 4 || migrations[len(migrations)-1].To != explorerMetadata.Version {
+		t.Fatal("unexpected migrations:", migrations)
+	}
+
+	e = &Explorer{persistDir: dir, chainCts: types.StandardnetChainConstants()}
+	err = e.initPersist()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.db.Close()
+	err = e.db.View(func(tx *bolt.Tx) error {
+		var (
+			recentChange modules.ConsensusChangeID
+			height       types.BlockHeight
+		)
+		err := dbGetInternal(internalRecentChange, &recentChange)(tx)
+		if err != nil {
+			return err
+		}
+		if recentChange != (modules.ConsensusChangeID{}) {
+			t.Error("recent change was not reset:", recentChange)
+		}
+		err = dbGetInternal(internalBlockHeight, &height)(tx)
+		if err != nil {
+			return err
+		}
+		if height != 0 {
+			t.Error("block height was not reset:", height)
+		}
+		if !bucketIsEmpty(tx.Bucket(bucketBlockIDs)) {
+			t.Error("block IDs were not reset")
+		}
+		if value := tx.Bucket(bucketReorgs).Get(reorgKey); string(value) != string(reorgValue) {
+			t.Error("recorded reorg was not kept:", value)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -2,6 +2,7 @@ package explorer
 
 import (
 	"os"
+	"path"
 	"path/filepath"
 
 	"github.com/threefoldtech/rivine/modules"
@@ -12,9 +13,13 @@ import (
 	"github.com/rivine/bbolt"
 )
 
+// DatabaseFilename contains the filename of the database that will be used
+// when managing the explorer.
+const DatabaseFilename = modules.ExplorerDir + ".db"
+
 var explorerMetadata = persist.Metadata{
 	Header:  "Sia Explorer",
-	Version: "1.0.8",
+	Version: "1.4.0",
 }
 
 // initPersist initializes the persistent structures of the explorer module.
@@ -26,7 +31,7 @@ func (e *Explorer) initPersist() error {
 	}
 
 	// Open the database
-	dbFilPath := filepath.Join(e.persistDir, "explorer.db")
+	dbFilPath := filepath.Join(e.persistDir, DatabaseFilename)
 	db, err := persist.OpenDatabase(explorerMetadata, dbFilPath)
 	if err != nil {
 		if err != persist.ErrBadVersion {
@@ -36,6 +41,20 @@ func (e *Explorer) initPersist() error {
 		if err != nil {
 			return err
 		}
+		// legacy databases are converted to the version prior to the first migration,
+		// and are migrated from there on to the current version
+		err = db.Close()
+		if err != nil {
+			return err
+		}
+		_, err = persist.MigrateDatabase(explorerMetadata, dbFilPath, dbMigrations, persist.MigrationOptions{})
+		if err != nil {
+			return err
+		}
+		db, err = persist.OpenDatabase(explorerMetadata, dbFilPath)
+		if err != nil {
+			return err
+		}
 	}
 	e.db = db
 
@@ -54,6 +73,16 @@ func (e *Explorer) initPersist() error {
 			bucketTransactionIDs,
 			bucketUnlockHashes,
 			bucketWalletAddressToMultiSigAddressMapping,
+			bucketAddressHistory,
+			bucketAddressBalances,
+			bucketRichList,
+			bucketBalanceDistribution,
+			bucketLockedCoins,
+			bucketBlockSummaries,
+			bucketTransactionSummaries,
+			bucketDailyAggregates,
+			bucketBlocksAggregates,
+			bucketReorgs,
 		}
 		for _, b := range buckets {
 			_, err := tx.CreateBucketIfNotExists(b)
@@ -68,6 +97,7 @@ func (e *Explorer) initPersist() error {
 		}{
 			{internalBlockHeight, siabin.Marshal(types.BlockHeight(0))},
 			{internalRecentChange, siabin.Marshal(modules.ConsensusChangeID{})},
+			{internalBlockStakeSupply, siabin.Marshal(types.ZeroCurrency)},
 		}
 		b := tx.Bucket(bucketInternal)
 		for _, d := range internalDefaults {
@@ -88,3 +118,12 @@ func (e *Explorer) initPersist() error {
 
 	return nil
 }
+
+// Backup implements modules.Backupper.Backup,
+// copying the database within a read transaction.
+func (e *Explorer) Backup(bw persist.BackupWriter) error {
+	return e.db.Backup(bw, path.Join(modules.ExplorerDir, DatabaseFilename))
+}
+
+// enforce that Explorer can be backed up while running
+var _ modules.Backupper = (*Explorer)(nil)
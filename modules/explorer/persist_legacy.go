@@ -11,6 +11,13 @@ import (
 	"github.com/rivine/bbolt"
 )
 
+// legacyConvertedMetadata is the metadata of legacy databases once converted,
+// being the version from which the migrations defined in dbMigrations start.
+var legacyConvertedMetadata = persist.Metadata{
+	Header:  "Sia Explorer",
+	Version: "1.0.8",
+}
+
 func (e *Explorer) convertLegacyDatabase(filePath string) (db *persist.BoltDatabase, err error) {
 	var legacyExplorerMetadata = persist.Metadata{
 		Header:  "Sia Explorer",
@@ -102,7 +109,7 @@ func (e *Explorer) convertLegacyDatabase(filePath string) (db *persist.BoltDatab
 	if err == nil || err == bolt.ErrBucketExists {
 		// set the new metadata, and save it,
 		// such that next time we have the new version stored
-		db.Header, db.Version = explorerMetadata.Header, explorerMetadata.Version
+		db.Header, db.Version = legacyConvertedMetadata.Header, legacyConvertedMetadata.Version
 		err = db.SaveMetadata()
 	}
 	if err != nil {
@@ -115,7 +122,7 @@ func (e *Explorer) convertLegacyDatabase(filePath string) (db *persist.BoltDatab
 }
 
 // convert052Database converts a 0.5.2 explorer database,
-// to a database of the current version as defined by explorerMetadata.
+// to a database of the version defined by legacyConvertedMetadata.
 // It keeps the database open and returns it for further usage.
 func convert052Database(filePath string) (db *persist.BoltDatabase, err error) {
 	var legacyExplorerMetadata = persist.Metadata{
@@ -141,7 +148,7 @@ func convert052Database(filePath string) (db *persist.BoltDatabase, err error) {
 	if err == nil {
 		// set the new metadata, and save it,
 		// such that next time we have the new version stored
-		db.Header, db.Version = explorerMetadata.Header, explorerMetadata.Version
+		db.Header, db.Version = legacyConvertedMetadata.Header, legacyConvertedMetadata.Version
 		err = db.SaveMetadata()
 	}
 	if err != nil {
@@ -0,0 +1,66 @@
+package explorer
+
+import (
+	"encoding/binary"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+	"github.com/threefoldtech/rivine/types"
+
+	"github.com/rivine/bbolt"
+)
+
+// maxRecordedReorgs is the amount of most recent reorgs recorded in bucketReorgs,
+// which maps the ID of each reorg (8 bytes, big-endian) to the reorg.
+const maxRecordedReorgs = 100
+
+// reorgKey returns the key of the reorg with the given ID.
+func reorgKey(id uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, id)
+	return key
+}
+
+// dbAddReorg records the reorg of the given consensus change, of which the reverted blocks
+// are reverted up to the given fork height, forgetting the oldest recorded reorg if needed.
+func dbAddReorg(tx *bolt.Tx, forkHeight types.BlockHeight, cc modules.ConsensusChange) {
+	b := tx.Bucket(bucketReorgs)
+	id, err := b.NextSequence()
+	assertNil(err)
+	reorg := modules.ExplorerReorg{
+		ID:         id,
+		ForkHeight: forkHeight,
+		Timestamp:  types.CurrentTimestamp(),
+	}
+	for _, block := range cc.RevertedBlocks {
+		reorg.RevertedBlocks = append(reorg.RevertedBlocks, block.ID())
+	}
+	for _, block := range cc.AppliedBlocks {
+		reorg.AppliedBlocks = append(reorg.AppliedBlocks, block.ID())
+	}
+	assertNil(b.Put(reorgKey(id), siabin.Marshal(reorg)))
+	if id > maxRecordedReorgs {
+		assertNil(b.Delete(reorgKey(id - maxRecordedReorgs)))
+	}
+}
+
+// Reorgs returns the given amount of most recent reorgs,
+// ordered from the newest to the oldest reorg.
+func (e *Explorer) Reorgs(n int) (reorgs []modules.ExplorerReorg, err error) {
+	err = e.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(bucketReorgs).Cursor()
+		for k, v := c.Last(); k != nil && len(reorgs) < n; k, v = c.Prev() {
+			var reorg modules.ExplorerReorg
+			err := siabin.Unmarshal(v, &reorg)
+			if err != nil {
+				return err
+			}
+			reorgs = append(reorgs, reorg)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reorgs, nil
+}
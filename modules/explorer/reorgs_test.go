@@ -0,0 +1,167 @@
+package explorer
+
+import (
+	"encoding/hex"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/threefoldtech/rivine/build"
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+
+	"github.com/rivine/bbolt"
+)
+
+// reorgConsensusSet is the part of the consensus set used by the explorer to process consensus changes.
+type reorgConsensusSet struct {
+	modules.ConsensusSet
+	blocks []types.Block
+	target types.Target
+}
+
+func (cs *reorgConsensusSet) BlockAtHeight(height types.BlockHeight) (types.Block, bool) {
+	if int(height) >= len(cs.blocks) {
+		return types.Block{}, false
+	}
+	return cs.blocks[height], true
+}
+
+func (cs *reorgConsensusSet) ChildTarget(types.BlockID) (types.Target, bool) {
+	return cs.target, true
+}
+
+// newReorgExplorer creates an explorer following the given consensus set, without subscribing to it.
+func newReorgExplorer(t *testing.T, name string, cs *reorgConsensusSet) *Explorer {
+	dir := build.TempDir(modules.ExplorerDir, name)
+	os.RemoveAll(dir)
+	chainCts := types.StandardnetChainConstants()
+	genesisBlock := chainCts.GenesisBlock()
+	e := &Explorer{
+		cs:             cs,
+		persistDir:     dir,
+		chainCts:       chainCts,
+		rootTarget:     chainCts.RootTarget(),
+		genesisBlock:   genesisBlock,
+		genesisBlockID: genesisBlock.ID(),
+	}
+	cs.target = e.rootTarget
+	err := e.initPersist()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+// explorerIndexes returns all entries of all buckets of the explorer, other than the internal ones and the reorgs.
+func explorerIndexes(t *testing.T, e *Explorer) map[string]string {
+	entries := make(map[string]string)
+	var walk func(path string, b *bolt.Bucket) error
+	walk = func(path string, b *bolt.Bucket) error {
+		return b.ForEach(func(k, v []byte) error {
+			if v == nil {
+				return walk(path+"/"+hex.EncodeToString(k), b.Bucket(k))
+			}
+			entries[path+"/"+hex.EncodeToString(k)] = hex.EncodeToString(v)
+			return nil
+		})
+	}
+	err := e.db.View(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+			if string(name) == string(bucketInternal) || string(name) == string(bucketReorgs) {
+				return nil
+			}
+			return walk(string(name), b)
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entries
+}
+
+// TestReorg reverts a block with a transaction spending an output created in the same block,
+// applies another block in its place, and checks that the indexes of the explorer are the same
+// as the indexes of an explorer which never saw the reverted block, and that the reorg is recorded.
+func TestReorg(t *testing.T) {
+	chainCts := types.StandardnetChainConstants()
+	genesis := chainCts.GenesisBlock()
+	genesisTxn := genesis.Transactions[0]
+	alice := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: crypto.Hash{1}}
+	bob := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: crypto.Hash{2}}
+	carol := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: crypto.Hash{3}}
+	output := func(uh types.UnlockHash, value types.Currency) types.CoinOutput {
+		return types.CoinOutput{Value: value, Condition: types.NewCondition(types.NewUnlockHashCondition(uh))}
+	}
+
+	block1 := types.Block{
+		ParentID:     genesis.ID(),
+		Timestamp:    genesis.Timestamp + 1,
+		MinerPayouts: []types.MinerPayout{{Value: types.NewCurrency64(10), UnlockHash: alice}},
+	}
+	// block 2 has the genesis address pay alice, returning the change to itself,
+	// after which alice pays bob within the same block
+	genesisOutput := genesisTxn.CoinOutputs[0]
+	txn1 := types.Transaction{
+		CoinInputs: []types.CoinInput{{ParentID: genesisTxn.CoinOutputID(0)}},
+		CoinOutputs: []types.CoinOutput{
+			output(alice, types.NewCurrency64(5)),
+			{Value: genesisOutput.Value.Sub(types.NewCurrency64(5)), Condition: genesisOutput.Condition},
+		},
+	}
+	txn2 := types.Transaction{
+		CoinInputs:  []types.CoinInput{{ParentID: txn1.CoinOutputID(0)}},
+		CoinOutputs: []types.CoinOutput{output(bob, types.NewCurrency64(5))},
+	}
+	block2 := types.Block{
+		ParentID:     block1.ID(),
+		Timestamp:    genesis.Timestamp + 2,
+		Transactions: []types.Transaction{txn1, txn2},
+	}
+	otherBlock2 := types.Block{
+		ParentID:     block1.ID(),
+		Timestamp:    genesis.Timestamp + 3,
+		MinerPayouts: []types.MinerPayout{{Value: types.NewCurrency64(10), UnlockHash: carol}},
+	}
+
+	cs := &reorgConsensusSet{blocks: []types.Block{genesis, block1, block2}}
+	e := newReorgExplorer(t, t.Name(), cs)
+	defer e.db.Close()
+	e.ProcessConsensusChange(modules.ConsensusChange{AppliedBlocks: cs.blocks})
+	cs.blocks = []types.Block{genesis, block1, otherBlock2}
+	e.ProcessConsensusChange(modules.ConsensusChange{
+		RevertedBlocks: []types.Block{block2},
+		AppliedBlocks:  []types.Block{otherBlock2},
+	})
+
+	expectedCS := &reorgConsensusSet{blocks: cs.blocks}
+	expected := newReorgExplorer(t, t.Name()+"-expected", expectedCS)
+	defer expected.db.Close()
+	expected.ProcessConsensusChange(modules.ConsensusChange{AppliedBlocks: expectedCS.blocks})
+
+	indexes, expectedIndexes := explorerIndexes(t, e), explorerIndexes(t, expected)
+	for key, value := range expectedIndexes {
+		if indexes[key] != value {
+			t.Errorf("unexpected value of %s after the reorg: %q, expected %q", key, indexes[key], value)
+		}
+	}
+	for key := range indexes {
+		if _, ok := expectedIndexes[key]; !ok {
+			t.Errorf("unexpected entry %s after the reorg", key)
+		}
+	}
+
+	reorgs, err := e.Reorgs(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reorgs) != 1 || reorgs[0].ID != 1 || reorgs[0].ForkHeight != 1 ||
+		!reflect.DeepEqual(reorgs[0].RevertedBlocks, []types.BlockID{block2.ID()}) ||
+		!reflect.DeepEqual(reorgs[0].AppliedBlocks, []types.BlockID{otherBlock2.ID()}) {
+		t.Errorf("unexpected reorgs: %+v", reorgs)
+	}
+	if reorgs, err := expected.Reorgs(10); err != nil || len(reorgs) != 0 {
+		t.Errorf("expected no reorgs to be recorded without reverted blocks: %+v (%v)", reorgs, err)
+	}
+}
@@ -0,0 +1,200 @@
+package explorer
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"math"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+	"github.com/threefoldtech/rivine/types"
+
+	"github.com/rivine/bbolt"
+)
+
+// The blocks and transactions are summarized in bucketBlockSummaries, keyed by the block height,
+// and bucketTransactionSummaries, keyed by the block height followed by the index of the transaction,
+// such that they can be listed by height without loading the blocks from the consensus set.
+
+const (
+	// blockSummaryKeySize is the size of the key of a block summary: its height (8 bytes, big-endian).
+	blockSummaryKeySize = 8
+	// transactionSummaryKeySize is the size of the key of a transaction summary: the height of its block
+	// (8 bytes) followed by its index within the block (4 bytes), both big-endian.
+	transactionSummaryKeySize = 12
+)
+
+// blockSummaryKey returns the key of the block summary at the given height.
+func blockSummaryKey(height types.BlockHeight) []byte {
+	key := make([]byte, blockSummaryKeySize)
+	binary.BigEndian.PutUint64(key, uint64(height))
+	return key
+}
+
+// transactionSummaryKey returns the key of the transaction summary at the given height and index.
+func transactionSummaryKey(height types.BlockHeight, index int) []byte {
+	key := make([]byte, transactionSummaryKeySize)
+	binary.BigEndian.PutUint64(key[:8], uint64(height))
+	binary.BigEndian.PutUint32(key[8:], uint32(index))
+	return key
+}
+
+// dbAddSummaries summarizes the given block, applied at the given height, and its transactions.
+func dbAddSummaries(tx *bolt.Tx, block types.Block, height types.BlockHeight) {
+	blockID := block.ID()
+	summary := modules.BlockSummary{
+		BlockID:          blockID,
+		ParentID:         block.ParentID,
+		Height:           height,
+		Timestamp:        block.Timestamp,
+		TransactionCount: len(block.Transactions),
+		MinerPayouts:     types.ZeroCurrency,
+	}
+	for _, payout := range block.MinerPayouts {
+		summary.MinerPayouts = summary.MinerPayouts.Add(payout.Value)
+	}
+	assertNil(tx.Bucket(bucketBlockSummaries).Put(blockSummaryKey(height), siabin.Marshal(summary)))
+
+	b := tx.Bucket(bucketTransactionSummaries)
+	for i, txn := range block.Transactions {
+		summary := modules.TransactionSummary{
+			TransactionID:         txn.ID(),
+			BlockID:               blockID,
+			Height:                height,
+			Index:                 i,
+			Timestamp:             block.Timestamp,
+			Version:               txn.Version,
+			CoinInputCount:        len(txn.CoinInputs),
+			CoinOutputCount:       len(txn.CoinOutputs),
+			BlockStakeInputCount:  len(txn.BlockStakeInputs),
+			BlockStakeOutputCount: len(txn.BlockStakeOutputs),
+			CoinOutputValue:       types.ZeroCurrency,
+			BlockStakeOutputValue: types.ZeroCurrency,
+			MinerFees:             types.ZeroCurrency,
+		}
+		for _, co := range txn.CoinOutputs {
+			summary.CoinOutputValue = summary.CoinOutputValue.Add(co.Value)
+		}
+		for _, bso := range txn.BlockStakeOutputs {
+			summary.BlockStakeOutputValue = summary.BlockStakeOutputValue.Add(bso.Value)
+		}
+		for _, fee := range txn.MinerFees {
+			summary.MinerFees = summary.MinerFees.Add(fee)
+		}
+		assertNil(b.Put(transactionSummaryKey(height, i), siabin.Marshal(summary)))
+	}
+}
+
+// dbRemoveSummaries removes the summaries of the given block, reverted at the given height, and its transactions.
+func dbRemoveSummaries(tx *bolt.Tx, block types.Block, height types.BlockHeight) {
+	assertNil(tx.Bucket(bucketBlockSummaries).Delete(blockSummaryKey(height)))
+	b := tx.Bucket(bucketTransactionSummaries)
+	for i := range block.Transactions {
+		assertNil(b.Delete(transactionSummaryKey(height, i)))
+	}
+}
+
+// maxHeightIndex returns the maximum index of a range of heights,
+// there being no maximum if the given maximum height is 0.
+func maxHeightIndex(maxHeight types.BlockHeight) uint64 {
+	if maxHeight == 0 {
+		return math.MaxUint64
+	}
+	return uint64(maxHeight)
+}
+
+// timestampInRange returns true if the given timestamp is within the given (inclusive) range,
+// there being no maximum if max is 0.
+func timestampInRange(timestamp, min, max types.Timestamp) bool {
+	return timestamp >= min && (max == 0 || timestamp <= max)
+}
+
+// Blocks returns a page of the blocks within the given ranges, ordered by height.
+func (e *Explorer) Blocks(query modules.BlocksQuery) (blocks []modules.BlockSummary, next string, err error) {
+	var after []byte
+	if query.Cursor != "" {
+		after, err = hex.DecodeString(query.Cursor)
+		if err != nil || len(after) != blockSummaryKeySize {
+			return nil, "", modules.ErrInvalidBlocksCursor
+		}
+	}
+	err = e.db.View(func(tx *bolt.Tx) error {
+		var last []byte
+		return dbListRange(tx.Bucket(bucketBlockSummaries), after, query.Reverse, uint64(query.MinHeight), maxHeightIndex(query.MaxHeight), func(k, v []byte) (bool, error) {
+			var block modules.BlockSummary
+			err := siabin.Unmarshal(v, &block)
+			if err != nil {
+				return false, err
+			}
+			if !timestampInRange(block.Timestamp, query.MinTimestamp, query.MaxTimestamp) {
+				return true, nil
+			}
+			if query.Limit > 0 && len(blocks) == query.Limit {
+				next = hex.EncodeToString(last)
+				return false, nil
+			}
+			blocks = append(blocks, block)
+			last = append(last[:0], k...)
+			return true, nil
+		})
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return blocks, next, nil
+}
+
+// Transactions returns a page of the transactions matching the given query, ordered by height and index.
+func (e *Explorer) Transactions(query modules.TransactionsQuery) (txns []modules.TransactionSummary, next string, err error) {
+	var after []byte
+	if query.Cursor != "" {
+		after, err = hex.DecodeString(query.Cursor)
+		if err != nil || len(after) != transactionSummaryKeySize {
+			return nil, "", modules.ErrInvalidTransactionsCursor
+		}
+	}
+	err = e.db.View(func(tx *bolt.Tx) error {
+		var last []byte
+		return dbListRange(tx.Bucket(bucketTransactionSummaries), after, query.Reverse, uint64(query.MinHeight), maxHeightIndex(query.MaxHeight), func(k, v []byte) (bool, error) {
+			var txn modules.TransactionSummary
+			err := siabin.Unmarshal(v, &txn)
+			if err != nil {
+				return false, err
+			}
+			if !transactionSummaryMatches(txn, query) {
+				return true, nil
+			}
+			if query.Limit > 0 && len(txns) == query.Limit {
+				next = hex.EncodeToString(last)
+				return false, nil
+			}
+			txns = append(txns, txn)
+			last = append(last[:0], k...)
+			return true, nil
+		})
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return txns, next, nil
+}
+
+// transactionSummaryMatches returns true if the given transaction matches the filters
+// of the given query, other than its range of heights.
+func transactionSummaryMatches(txn modules.TransactionSummary, query modules.TransactionsQuery) bool {
+	if !timestampInRange(txn.Timestamp, query.MinTimestamp, query.MaxTimestamp) {
+		return false
+	}
+	if txn.CoinOutputValue.Cmp(query.MinValue) < 0 {
+		return false
+	}
+	if len(query.Versions) == 0 {
+		return true
+	}
+	for _, version := range query.Versions {
+		if txn.Version == version {
+			return true
+		}
+	}
+	return false
+}
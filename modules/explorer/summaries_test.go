@@ -0,0 +1,117 @@
+package explorer
+
+import (
+	"os"
+	"testing"
+
+	"github.com/threefoldtech/rivine/build"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+
+	"github.com/rivine/bbolt"
+)
+
+// TestSummaries summarizes a few blocks, lists them and their transactions
+// using the different filters, and checks that reverting a block removes its summaries.
+func TestSummaries(t *testing.T) {
+	dir := build.TempDir(modules.ExplorerDir, t.Name())
+	os.RemoveAll(dir)
+	e := &Explorer{persistDir: dir}
+	err := e.initPersist()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.db.Close()
+
+	// block n has n transactions, the version and value of the transactions alternating
+	var blocks []types.Block
+	for n := 0; n < 4; n++ {
+		block := types.Block{Timestamp: types.Timestamp(100 * (n + 1))}
+		for i := 0; i < n; i++ {
+			block.Transactions = append(block.Transactions, types.Transaction{
+				Version:       types.TransactionVersion(i % 2),
+				CoinOutputs:   []types.CoinOutput{{Value: types.NewCurrency64(uint64(10 * (i%2 + 1)))}},
+				ArbitraryData: []byte{byte(n), byte(i)},
+			})
+		}
+		blocks = append(blocks, block)
+	}
+	err = e.db.Update(func(tx *bolt.Tx) error {
+		for height, block := range blocks {
+			dbAddSummaries(tx, block, types.BlockHeight(height))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listBlocks := func(query modules.BlocksQuery) []modules.BlockSummary {
+		var all []modules.BlockSummary
+		for {
+			blocks, next, err := e.Blocks(query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			all = append(all, blocks...)
+			if next == "" {
+				return all
+			}
+			query.Cursor = next
+		}
+	}
+	listTransactions := func(query modules.TransactionsQuery) []modules.TransactionSummary {
+		var all []modules.TransactionSummary
+		for {
+			txns, next, err := e.Transactions(query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			all = append(all, txns...)
+			if next == "" {
+				return all
+			}
+			query.Cursor = next
+		}
+	}
+
+	if all := listBlocks(modules.BlocksQuery{Limit: 1}); len(all) != 4 || all[3].BlockID != blocks[3].ID() || all[3].TransactionCount != 3 {
+		t.Errorf("unexpected blocks: %+v", all)
+	}
+	if all := listBlocks(modules.BlocksQuery{MinHeight: 1, MaxHeight: 2, Reverse: true}); len(all) != 2 || all[0].Height != 2 || all[1].Height != 1 {
+		t.Errorf("unexpected blocks within a height range: %+v", all)
+	}
+	if all := listBlocks(modules.BlocksQuery{MinTimestamp: 150, MaxTimestamp: 300, Limit: 1}); len(all) != 2 || all[0].Timestamp != 200 || all[1].Timestamp != 300 {
+		t.Errorf("unexpected blocks within a time range: %+v", all)
+	}
+
+	if all := listTransactions(modules.TransactionsQuery{Limit: 2}); len(all) != 6 ||
+		all[0].TransactionID != blocks[1].Transactions[0].ID() || all[5].TransactionID != blocks[3].Transactions[2].ID() || all[5].Index != 2 {
+		t.Errorf("unexpected transactions: %+v", all)
+	}
+	if all := listTransactions(modules.TransactionsQuery{Versions: []types.TransactionVersion{1}, Reverse: true, Limit: 1}); len(all) != 2 ||
+		all[0].TransactionID != blocks[3].Transactions[1].ID() || all[1].TransactionID != blocks[2].Transactions[1].ID() {
+		t.Errorf("unexpected transactions of version 1: %+v", all)
+	}
+	if all := listTransactions(modules.TransactionsQuery{MinValue: types.NewCurrency64(20), MinTimestamp: 400}); len(all) != 1 || !all[0].CoinOutputValue.Equals64(20) {
+		t.Errorf("unexpected transactions with a minimum value: %+v", all)
+	}
+	if _, _, err := e.Transactions(modules.TransactionsQuery{Cursor: "00"}); err != modules.ErrInvalidTransactionsCursor {
+		t.Errorf("expected an invalid cursor to be refused: %v", err)
+	}
+
+	// reverting a block removes its summaries
+	err = e.db.Update(func(tx *bolt.Tx) error {
+		dbRemoveSummaries(tx, blocks[3], 3)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if all := listBlocks(modules.BlocksQuery{}); len(all) != 3 {
+		t.Errorf("expected the reverted block to be removed: %+v", all)
+	}
+	if all := listTransactions(modules.TransactionsQuery{MinHeight: 3}); len(all) != 0 {
+		t.Errorf("expected the reverted transactions to be removed: %+v", all)
+	}
+}
@@ -38,6 +38,11 @@ func (e *Explorer) ProcessConsensusChange(cc modules.ConsensusChange) {
 			bid := block.ID()
 			tbid := types.TransactionID(bid)
 
+			// remove the address history and balances while the outputs it spends and creates are known
+			dbRemoveAddressHistory(tx, block, blockheight)
+			e.dbRemoveBalances(tx, block, blockheight)
+			dbRemoveAggregates(tx, block, blockheight)
+			dbRemoveSummaries(tx, block, blockheight)
 			blockheight--
 			dbRemoveBlockID(tx, bid)
 			dbRemoveTransactionID(tx, tbid) // Miner payouts are a transaction
@@ -56,8 +61,10 @@ func (e *Explorer) ProcessConsensusChange(cc modules.ConsensusChange) {
 				dbRemoveCoinOutput(tx, scoid)
 			}
 
-			// Remove transactions
-			for _, txn := range block.Transactions {
+			// Remove transactions, in reverse order, such that the outputs spent
+			// by a transaction are only removed once that transaction is removed
+			for i := len(block.Transactions) - 1; i >= 0; i-- {
+				txn := block.Transactions[i]
 				txid := txn.ID()
 				dbRemoveTransactionID(tx, txid)
 
@@ -93,6 +100,11 @@ func (e *Explorer) ProcessConsensusChange(cc modules.ConsensusChange) {
 			dbRemoveBlockFacts(tx, bid)
 		}
 
+		// record the reorg, if any, such that API consumers know the history changed
+		if len(cc.RevertedBlocks) > 0 {
+			dbAddReorg(tx, blockheight, cc)
+		}
+
 		// Update cumulative stats for applied blocks.
 		for _, block := range cc.AppliedBlocks {
 			bid := block.ID()
@@ -101,6 +113,10 @@ func (e *Explorer) ProcessConsensusChange(cc modules.ConsensusChange) {
 			// special handling for genesis block
 			if bid == e.genesisBlockID {
 				e.dbAddGenesisBlock(tx)
+				dbAddAddressHistory(tx, block, 0)
+				e.dbAddBalances(tx, block, 0)
+				dbAddSummaries(tx, block, 0)
+				dbAddAggregates(tx, block, 0)
 				continue
 			}
 
@@ -161,6 +177,12 @@ func (e *Explorer) ProcessConsensusChange(cc modules.ConsensusChange) {
 				}
 			}
 
+			// index the address history, balances, summaries and aggregates, now that all outputs of the block are known
+			dbAddAddressHistory(tx, block, blockheight)
+			e.dbAddBalances(tx, block, blockheight)
+			dbAddSummaries(tx, block, blockheight)
+			dbAddAggregates(tx, block, blockheight)
+
 			// calculate and add new block facts, if possible
 			if tx.Bucket(bucketBlockFacts).Get(siabin.Marshal(block.ParentID)) != nil {
 				facts := e.dbCalculateBlockFacts(tx, block)
@@ -550,6 +572,9 @@ func dbRemoveUnlockHash(tx *bolt.Tx, uh types.UnlockHash, txid types.Transaction
 	uhb := tx.Bucket(bucketUnlockHashes)
 	muh := siabin.Marshal(uh)
 	b := uhb.Bucket(muh)
+	if b == nil {
+		return // removed already, as the transaction references the unlock hash more than once
+	}
 	mustDelete(b, txid)
 	if bucketIsEmpty(b) {
 		uhb.DeleteBucket(muh)
@@ -584,8 +609,14 @@ func dbRemoveWalletAddressToMultiSigAddressMapping(tx *bolt.Tx, walletAddress, m
 	mb := tx.Bucket(bucketWalletAddressToMultiSigAddressMapping)
 	wa := siabin.Marshal(walletAddress)
 	wb := mb.Bucket(wa)
+	if wb == nil {
+		return // removed already, as the transaction references the address more than once
+	}
 	msa := siabin.Marshal(multiSigAddress)
 	msb := wb.Bucket(msa)
+	if msb == nil {
+		return // removed already, as the transaction references the address more than once
+	}
 	mustDelete(msb, txid)
 	if bucketIsEmpty(msb) {
 		wb.DeleteBucket(msa)
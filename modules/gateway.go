@@ -1,6 +1,7 @@
 package modules
 
 import (
+	"fmt"
 	"net"
 
 	"github.com/threefoldtech/rivine/build"
@@ -87,7 +88,65 @@ type (
 		// Online returns true if the gateway is connected to remote hosts
 		Online() bool
 
+		// PeerEventSubscribe adds a listener to the gateway,
+		// which receives all peer events from now on.
+		PeerEventSubscribe(PeerEventListener)
+
+		// PeerEventUnsubscribe removes a listener from the gateway.
+		PeerEventUnsubscribe(PeerEventListener)
+
 		// Close safely stops the Gateway's listener process.
 		Close() error
 	}
+
+	// PeerEventType defines the type of a PeerEvent.
+	PeerEventType uint8
+
+	// PeerEvent describes a peer connecting to or disconnecting from the gateway.
+	PeerEvent struct {
+		Type PeerEventType `json:"type"`
+		Peer Peer          `json:"peer"`
+	}
+
+	// A PeerEventListener receives the peer events of the gateway.
+	PeerEventListener interface {
+		// ReceivePeerEvent notifies listeners of a peer event.
+		// It is called while the gateway is locked, and should thus not block.
+		ReceivePeerEvent(PeerEvent)
+	}
 )
+
+const (
+	// PeerEventConnected is emitted when a peer connects to the gateway.
+	PeerEventConnected PeerEventType = iota + 1
+	// PeerEventDisconnected is emitted when a peer disconnects from the gateway.
+	PeerEventDisconnected
+)
+
+// String returns the type as a human-readable string.
+func (t PeerEventType) String() string {
+	switch t {
+	case PeerEventConnected:
+		return "connected"
+	case PeerEventDisconnected:
+		return "disconnected"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.MarshalText
+func (t PeerEventType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.UnmarshalText
+func (t *PeerEventType) UnmarshalText(b []byte) error {
+	for et := PeerEventConnected; et <= PeerEventDisconnected; et++ {
+		if et.String() == string(b) {
+			*t = et
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown peer event type %q", string(b))
+}
@@ -0,0 +1,35 @@
+package gateway
+
+import (
+	"github.com/threefoldtech/rivine/modules"
+)
+
+// sendPeerEvent sends the given event to all event listeners,
+// it should be called while holding the lock.
+func (g *Gateway) sendPeerEvent(event modules.PeerEvent) {
+	for _, listener := range g.eventListeners {
+		listener.ReceivePeerEvent(event)
+	}
+}
+
+// PeerEventSubscribe adds a listener to the gateway.
+// Listeners receive the events of all peers connecting and disconnecting from now on.
+func (g *Gateway) PeerEventSubscribe(listener modules.PeerEventListener) {
+	g.mu.Lock()
+	g.eventListeners = append(g.eventListeners, listener)
+	g.mu.Unlock()
+}
+
+// PeerEventUnsubscribe removes a listener from the gateway.
+// If the listener is not in g.eventListeners, PeerEventUnsubscribe does nothing.
+func (g *Gateway) PeerEventUnsubscribe(listener modules.PeerEventListener) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for i := range g.eventListeners {
+		if g.eventListeners[i] == listener {
+			g.eventListeners = append(g.eventListeners[0:i], g.eventListeners[i+1:]...)
+			break
+		}
+	}
+}
@@ -145,6 +145,9 @@ type Gateway struct {
 	peers  map[modules.NetAddress]*peer
 	peerTG siasync.ThreadGroup
 
+	// eventListeners receive the events of peers connecting and disconnecting.
+	eventListeners []modules.PeerEventListener
+
 	// Utilities.
 	log        *persist.Logger
 	mu         sync.RWMutex
@@ -343,5 +346,8 @@ func New(addr string, bootstrap bool, persistDir string, bcInfo types.Blockchain
 	return g, nil
 }
 
-// enforce that Gateway satisfies the modules.Gateway interface
-var _ modules.Gateway = (*Gateway)(nil)
+// enforce that Gateway satisfies the modules.Gateway and modules.Backupper interfaces
+var (
+	_ modules.Gateway   = (*Gateway)(nil)
+	_ modules.Backupper = (*Gateway)(nil)
+)
@@ -57,7 +57,7 @@ func (g *Gateway) managedIPFromPeers() (string, error) {
 				addr := net.ParseIP(address)
 				if addr == nil {
 					returnChan <- ""
-					g.log.Debug("DEBUG: failed to parse ip address")
+					g.log.Debug("failed to parse ip address")
 					return errors.New("failed to parse ip address")
 				}
 				returnChan <- addr.String()
@@ -75,9 +75,20 @@ func (p *peer) accept() (modules.PeerConn, error) {
 // to handle its requests and increments the remotePeers accordingly
 func (g *Gateway) addPeer(p *peer) {
 	g.peers[p.NetAddress] = p
+	g.sendPeerEvent(modules.PeerEvent{Type: modules.PeerEventConnected, Peer: p.Peer})
 	go g.threadedListenPeer(p)
 }
 
+// removePeer removes a peer from the Gateway's peer list, if it is still in it.
+func (g *Gateway) removePeer(addr modules.NetAddress) {
+	p, ok := g.peers[addr]
+	if !ok {
+		return
+	}
+	delete(g.peers, addr)
+	g.sendPeerEvent(modules.PeerEvent{Type: modules.PeerEventDisconnected, Peer: p.Peer})
+}
+
 // randomOutboundPeer returns a random outbound peer.
 func (g *Gateway) randomOutboundPeer() (modules.NetAddress, error) {
 	// Get the list of outbound peers.
@@ -241,7 +252,7 @@ func (g *Gateway) acceptPeer(p *peer) {
 	kick := addrs[fastrand.Intn(len(addrs))]
 
 	g.peers[kick].sess.Close()
-	delete(g.peers, kick)
+	g.removePeer(kick)
 	g.log.Printf("INFO: disconnected from %v to make room for %v\n", kick, p.NetAddress)
 	g.addPeer(p)
 }
@@ -725,7 +736,7 @@ func (g *Gateway) Disconnect(addr modules.NetAddress) error {
 	g.mu.Lock()
 	// Peer is removed from the peer list as well as the node list, to prevent
 	// the node from being re-connected while looking for a replacement peer.
-	delete(g.peers, addr)
+	g.removePeer(addr)
 	delete(g.nodes, addr)
 	g.mu.Unlock()
 
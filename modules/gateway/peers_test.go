@@ -529,6 +529,76 @@ func TestDisconnect(t *testing.T) {
 	}
 }
 
+// peerEventRecorder is a PeerEventListener recording all received events.
+type peerEventRecorder struct {
+	events []modules.PeerEvent
+}
+
+func (r *peerEventRecorder) ReceivePeerEvent(event modules.PeerEvent) {
+	r.events = append(r.events, event)
+}
+
+// TestPeerEvents checks that listeners receive the events of peers
+// connecting and disconnecting, until they unsubscribe.
+func TestPeerEvents(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	g := newTestingGateway(t)
+	defer g.Close()
+
+	recorder := new(peerEventRecorder)
+	g.PeerEventSubscribe(recorder)
+
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal("couldn't start listener:", err)
+	}
+	defer l.Close()
+	go l.Accept()
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal("dial failed:", err)
+	}
+	g.mu.Lock()
+	g.addPeer(&peer{
+		Peer: modules.Peer{
+			NetAddress: "foo.com:123",
+			Inbound:    true,
+		},
+		sess: newSmuxClient(conn),
+	})
+	g.mu.Unlock()
+	if err := g.Disconnect("foo.com:123"); err != nil {
+		t.Fatal("disconnect failed:", err)
+	}
+
+	g.mu.RLock()
+	events := append([]modules.PeerEvent{}, recorder.events...)
+	g.mu.RUnlock()
+	if len(events) != 2 ||
+		events[0].Type != modules.PeerEventConnected || events[0].Peer.NetAddress != "foo.com:123" || !events[0].Peer.Inbound ||
+		events[1].Type != modules.PeerEventDisconnected || events[1].Peer.NetAddress != "foo.com:123" {
+		t.Fatalf("unexpected peer events: %v", events)
+	}
+
+	// an unsubscribed listener receives no more events
+	g.PeerEventUnsubscribe(recorder)
+	g.mu.Lock()
+	g.addPeer(&peer{
+		Peer: modules.Peer{NetAddress: "bar.com:123"},
+		sess: newSmuxClient(conn),
+	})
+	g.mu.Unlock()
+	g.mu.RLock()
+	n := len(recorder.events)
+	g.mu.RUnlock()
+	if n != 2 {
+		t.Fatalf("unsubscribed listener received %d events", n-2)
+	}
+}
+
 // TestPeerManager checks that the peer manager is properly spacing out peer
 // connection requests.
 func TestPeerManager(t *testing.T) {
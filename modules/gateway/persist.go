@@ -1,6 +1,7 @@
 package gateway
 
 import (
+	"path"
 	"path/filepath"
 	"time"
 
@@ -95,3 +96,16 @@ func (g *Gateway) loadv033persist() error {
 	}
 	return nil
 }
+
+// Backup implements modules.Backupper.Backup,
+// writing the nodes known by the gateway.
+func (g *Gateway) Backup(bw persist.BackupWriter) error {
+	err := g.threads.Add()
+	if err != nil {
+		return err
+	}
+	defer g.threads.Done()
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return persist.BackupJSON(bw, path.Join(modules.GatewayDir, nodesFile), persistMetadata, g.persistData())
+}
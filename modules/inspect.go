@@ -0,0 +1,13 @@
+package modules
+
+import "github.com/rivine/bbolt"
+
+// DatabaseInspector is implemented by the modules of which the database can be inspected
+// while the daemon is running, such as the consensus set, transaction pool and explorer.
+type DatabaseInspector interface {
+	// InspectDatabase calls the given function within a read-only transaction of the database of the module.
+	InspectDatabase(func(*bolt.Tx) error) error
+	// DecodeDatabaseValue decodes the value of a key of the (nested) bucket of the given path,
+	// returning false if it is not known how to decode it.
+	DecodeDatabaseValue(bucket [][]byte, key, value []byte) (interface{}, bool)
+}
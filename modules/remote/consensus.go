@@ -0,0 +1,384 @@
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/persist"
+	"github.com/threefoldtech/rivine/pkg/api"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// ConsensusSet is the consensus set of a remote node, accessed over its API.
+// Subscribers receive the consensus changes streamed by the node, reconnecting
+// whenever the stream is lost, continuing from the last change received.
+//
+// The calls used by the wallet, which are the subscription calls, the lookups of blocks
+// and unspent outputs, and the state of the consensus set, are served by the node.
+// The calls used to create blocks are not supported, returning ErrNotSupported or zero values.
+// Calls which cannot return an error return zero values if the node cannot be reached, logging the error.
+type ConsensusSet struct {
+	client *api.HTTPClient
+	log    *persist.Logger
+
+	mu            sync.Mutex
+	subscriptions map[modules.ConsensusSetSubscriber]*consensusSubscription
+	// heights of the blocks applied by the consensus changes being processed by the subscribers,
+	// such that BlockHeightOfBlock can be served without calling the node
+	heights map[types.BlockID]blockHeight
+	closed  bool
+}
+
+type blockHeight struct {
+	height types.BlockHeight
+	refs   int
+}
+
+type consensusSubscription struct {
+	subscriber modules.ConsensusSetSubscriber
+	// last is the ID of the last consensus change processed by the subscriber
+	last modules.ConsensusChangeID
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewConsensusSet creates the consensus set of the remote node the given client connects to.
+func NewConsensusSet(client *api.HTTPClient, log *persist.Logger) *ConsensusSet {
+	return &ConsensusSet{
+		client:        client,
+		log:           log,
+		subscriptions: make(map[modules.ConsensusSetSubscriber]*consensusSubscription),
+		heights:       make(map[types.BlockID]blockHeight),
+	}
+}
+
+// ConsensusSetSubscribe implements modules.ConsensusSet.ConsensusSetSubscribe,
+// returning once the subscriber received all consensus changes since the given one.
+func (cs *ConsensusSet) ConsensusSetSubscribe(subscriber modules.ConsensusSetSubscriber, start modules.ConsensusChangeID, cancel <-chan struct{}) error {
+	cs.mu.Lock()
+	_, subscribed := cs.subscriptions[subscriber]
+	closed := cs.closed
+	cs.mu.Unlock()
+	if closed {
+		return errClosed
+	}
+	if subscribed {
+		return errors.New("already subscribed to the remote consensus set")
+	}
+
+	sub := &consensusSubscription{
+		subscriber: subscriber,
+		last:       start,
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	stream, err := cs.openChanges(start)
+	if err != nil {
+		return err
+	}
+	// catch up, which is aborted by closing the stream when cancelled
+	caughtUp := make(chan struct{})
+	go func() {
+		select {
+		case <-cancel:
+			stream.Close()
+		case <-caughtUp:
+		}
+	}()
+	err = cs.processChanges(sub, stream, true)
+	close(caughtUp)
+	if err != nil {
+		stream.Close()
+		select {
+		case <-cancel:
+			return errors.New("aborting remote consensus set subscription")
+		default:
+		}
+		return err
+	}
+
+	cs.mu.Lock()
+	if cs.closed {
+		cs.mu.Unlock()
+		stream.Close()
+		return errClosed
+	}
+	cs.subscriptions[subscriber] = sub
+	cs.mu.Unlock()
+	go func() {
+		defer close(sub.done)
+		keepStreaming(cs.log, "consensus change", stream, sub.stop, func() (*api.WebSocketStream, error) {
+			return cs.openChanges(sub.last)
+		}, func(stream *api.WebSocketStream) error {
+			return cs.processChanges(sub, stream, false)
+		})
+	}()
+	return nil
+}
+
+// ConsensusSetSubscribeAsync implements modules.ConsensusSet.ConsensusSetSubscribeAsync,
+// the consensus changes of a remote consensus set are always processed
+// in a goroutine dedicated to the subscriber, hence the options are ignored.
+func (cs *ConsensusSet) ConsensusSetSubscribeAsync(subscriber modules.ConsensusSetSubscriber, start modules.ConsensusChangeID, cancel <-chan struct{}, _ modules.AsyncSubscribeOptions) error {
+	return cs.ConsensusSetSubscribe(subscriber, start, cancel)
+}
+
+// Unsubscribe implements modules.ConsensusSet.Unsubscribe,
+// returning once the stream of the subscriber is closed.
+func (cs *ConsensusSet) Unsubscribe(subscriber modules.ConsensusSetSubscriber) {
+	cs.mu.Lock()
+	sub, ok := cs.subscriptions[subscriber]
+	delete(cs.subscriptions, subscriber)
+	cs.mu.Unlock()
+	if ok {
+		close(sub.stop)
+		<-sub.done
+	}
+}
+
+// Close implements modules.ConsensusSet.Close,
+// unsubscribing all subscribers.
+func (cs *ConsensusSet) Close() error {
+	cs.mu.Lock()
+	cs.closed = true
+	subscribers := make([]modules.ConsensusSetSubscriber, 0, len(cs.subscriptions))
+	for subscriber := range cs.subscriptions {
+		subscribers = append(subscribers, subscriber)
+	}
+	cs.mu.Unlock()
+	for _, subscriber := range subscribers {
+		cs.Unsubscribe(subscriber)
+	}
+	return nil
+}
+
+// openChanges opens the stream of the consensus changes since the given one.
+func (cs *ConsensusSet) openChanges(start modules.ConsensusChangeID) (*api.WebSocketStream, error) {
+	call := "/consensus/changes"
+	if start != modules.ConsensusChangeBeginning {
+		call += "?id=" + crypto.Hash(start).String()
+	}
+	return cs.client.Subscribe(call)
+}
+
+// processChanges passes the consensus changes received over the given stream to the subscriber,
+// until the stream is lost, or, if untilCaughtUp is true, until all changes since the requested one are received.
+func (cs *ConsensusSet) processChanges(sub *consensusSubscription, stream *api.WebSocketStream, untilCaughtUp bool) error {
+	for {
+		var msg api.ConsensusChangeMessage
+		if err := stream.ReadJSON(&msg); err != nil {
+			return err
+		}
+		switch {
+		case msg.Error != "":
+			if msg.Error == modules.ErrInvalidConsensusChangeID.Error() {
+				return modules.ErrInvalidConsensusChangeID
+			}
+			return errors.New(msg.Error)
+		case msg.CaughtUp:
+			if untilCaughtUp {
+				return nil
+			}
+		case msg.Change != nil:
+			cs.processChange(sub, *msg.Change, msg.Height)
+		}
+	}
+}
+
+// processChange passes a single consensus change to the subscriber,
+// of which the last applied block is at the given height.
+func (cs *ConsensusSet) processChange(sub *consensusSubscription, cc modules.ConsensusChange, height types.BlockHeight) {
+	ids := make([]types.BlockID, 0, len(cc.AppliedBlocks))
+	cs.mu.Lock()
+	for i, block := range cc.AppliedBlocks {
+		id := block.ID()
+		ids = append(ids, id)
+		bh := cs.heights[id]
+		bh.height = height - types.BlockHeight(len(cc.AppliedBlocks)-1-i)
+		bh.refs++
+		cs.heights[id] = bh
+	}
+	cs.mu.Unlock()
+
+	sub.subscriber.ProcessConsensusChange(cc)
+	sub.last = cc.ID
+
+	cs.mu.Lock()
+	for _, id := range ids {
+		bh := cs.heights[id]
+		if bh.refs--; bh.refs == 0 {
+			delete(cs.heights, id)
+		} else {
+			cs.heights[id] = bh
+		}
+	}
+	cs.mu.Unlock()
+}
+
+// consensus returns the state of the consensus set of the node.
+func (cs *ConsensusSet) consensus() (api.ConsensusGET, error) {
+	var resp api.ConsensusGET
+	err := cs.client.GetAPI("/consensus", &resp)
+	if err != nil {
+		cs.log.Warnf("failed to get the state of the remote consensus set: %v", err)
+	}
+	return resp, err
+}
+
+// Height implements modules.ConsensusSet.Height
+func (cs *ConsensusSet) Height() types.BlockHeight {
+	resp, _ := cs.consensus()
+	return resp.Height
+}
+
+// Synced implements modules.ConsensusSet.Synced
+func (cs *ConsensusSet) Synced() bool {
+	resp, _ := cs.consensus()
+	return resp.Synced
+}
+
+// SyncProgress implements modules.ConsensusSet.SyncProgress
+func (cs *ConsensusSet) SyncProgress() modules.SyncProgress {
+	resp, _ := cs.consensus()
+	return modules.SyncProgress{
+		Synced:                 resp.Synced,
+		Height:                 resp.Height,
+		EstimatedNetworkHeight: resp.EstimatedNetworkHeight,
+		BlocksPerSecond:        resp.BlocksPerSecond,
+		Progress:               resp.SyncProgress,
+		ETA:                    resp.SyncETA,
+	}
+}
+
+// CurrentBlock implements modules.ConsensusSet.CurrentBlock
+func (cs *ConsensusSet) CurrentBlock() types.Block {
+	block, _ := cs.BlockAtHeight(cs.Height())
+	return block
+}
+
+// BlockAtHeight implements modules.ConsensusSet.BlockAtHeight
+func (cs *ConsensusSet) BlockAtHeight(height types.BlockHeight) (types.Block, bool) {
+	var resp api.ConsensusGetBlock
+	err := cs.client.GetAPI(fmt.Sprintf("/consensus/blocks/%d", height), &resp)
+	if err != nil {
+		if err != api.ErrStatusNotFound {
+			cs.log.Warnf("failed to get the remote block at height %d: %v", height, err)
+		}
+		return types.Block{}, false
+	}
+	return resp.Block, true
+}
+
+// BlockHeightOfBlock implements modules.ConsensusSet.BlockHeightOfBlock,
+// only for the blocks applied by the consensus changes being processed by the subscribers.
+func (cs *ConsensusSet) BlockHeightOfBlock(block types.Block) (types.BlockHeight, bool) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	bh, ok := cs.heights[block.ID()]
+	return bh.height, ok
+}
+
+// GetCoinOutput implements modules.ConsensusSet.GetCoinOutput
+func (cs *ConsensusSet) GetCoinOutput(id types.CoinOutputID) (types.CoinOutput, error) {
+	var resp api.ConsensusGetUnspentCoinOutput
+	err := cs.client.GetAPI("/consensus/unspent/coinoutputs/"+id.String(), &resp)
+	if err == api.ErrStatusNotFound {
+		return types.CoinOutput{}, fmt.Errorf("unspent coin output %s not found", id.String())
+	}
+	return resp.Output, err
+}
+
+// GetBlockStakeOutput implements modules.ConsensusSet.GetBlockStakeOutput
+func (cs *ConsensusSet) GetBlockStakeOutput(id types.BlockStakeOutputID) (types.BlockStakeOutput, error) {
+	var resp api.ConsensusGetUnspentBlockstakeOutput
+	err := cs.client.GetAPI("/consensus/unspent/blockstakeoutputs/"+id.String(), &resp)
+	if err == api.ErrStatusNotFound {
+		return types.BlockStakeOutput{}, fmt.Errorf("unspent blockstake output %s not found", id.String())
+	}
+	return resp.Output, err
+}
+
+// UnspentOutputsByUnlockHash implements modules.ConsensusSet.UnspentOutputsByUnlockHash
+func (cs *ConsensusSet) UnspentOutputsByUnlockHash(uh types.UnlockHash) (modules.UnspentOutputs, error) {
+	var resp api.ConsensusGetUnspentOutputs
+	err := cs.client.GetAPI("/consensus/unspent/unlockhashes/"+uh.String(), &resp)
+	return resp.UnspentOutputs, err
+}
+
+// BlockStatistics implements modules.ConsensusSet.BlockStatistics
+func (cs *ConsensusSet) BlockStatistics(window types.BlockHeight) (modules.BlockStatistics, error) {
+	var resp api.ConsensusGetStatistics
+	err := cs.client.GetAPI(fmt.Sprintf("/consensus/statistics?window=%d", window), &resp)
+	return resp.BlockStatistics, err
+}
+
+// AcceptBlock implements modules.ConsensusSet.AcceptBlock,
+// blocks cannot be submitted to a remote consensus set.
+func (cs *ConsensusSet) AcceptBlock(types.Block) error {
+	return ErrNotSupported
+}
+
+// TryTransactionSet implements modules.ConsensusSet.TryTransactionSet,
+// not supported by a remote consensus set.
+func (cs *ConsensusSet) TryTransactionSet([]types.Transaction) (modules.ConsensusChange, error) {
+	return modules.ConsensusChange{}, ErrNotSupported
+}
+
+// TransactionAtShortID implements modules.ConsensusSet.TransactionAtShortID,
+// not supported by a remote consensus set.
+func (cs *ConsensusSet) TransactionAtShortID(types.TransactionShortID) (types.Transaction, bool) {
+	return types.Transaction{}, false
+}
+
+// TransactionAtID implements modules.ConsensusSet.TransactionAtID,
+// not supported by a remote consensus set.
+func (cs *ConsensusSet) TransactionAtID(types.TransactionID) (types.Transaction, types.TransactionShortID, bool) {
+	return types.Transaction{}, 0, false
+}
+
+// FindParentBlock implements modules.ConsensusSet.FindParentBlock,
+// not supported by a remote consensus set.
+func (cs *ConsensusSet) FindParentBlock(types.Block, types.BlockHeight) (types.Block, bool) {
+	return types.Block{}, false
+}
+
+// ChildTarget implements modules.ConsensusSet.ChildTarget,
+// not supported by a remote consensus set.
+func (cs *ConsensusSet) ChildTarget(types.BlockID) (types.Target, bool) {
+	return types.Target{}, false
+}
+
+// InCurrentPath implements modules.ConsensusSet.InCurrentPath,
+// not supported by a remote consensus set.
+func (cs *ConsensusSet) InCurrentPath(types.BlockID) bool {
+	return false
+}
+
+// MinimumValidChildTimestamp implements modules.ConsensusSet.MinimumValidChildTimestamp,
+// not supported by a remote consensus set.
+func (cs *ConsensusSet) MinimumValidChildTimestamp(types.BlockID) (types.Timestamp, bool) {
+	return 0, false
+}
+
+// CalculateStakeModifier implements modules.ConsensusSet.CalculateStakeModifier,
+// not supported by a remote consensus set, returning a zero stake modifier.
+func (cs *ConsensusSet) CalculateStakeModifier(types.BlockHeight, types.Block, types.BlockHeight) *big.Int {
+	return new(big.Int)
+}
+
+// SubscriberStats implements modules.ConsensusSet.SubscriberStats,
+// the subscribers of a remote consensus set are not dispatched to by the consensus set itself.
+func (cs *ConsensusSet) SubscriberStats() []modules.SubscriberStats {
+	return nil
+}
+
+// Flush implements modules.ConsensusSet.Flush
+func (cs *ConsensusSet) Flush() error {
+	return nil
+}
+
+var _ modules.ConsensusSet = (*ConsensusSet)(nil)
@@ -0,0 +1,89 @@
+// Package remote provides the consensus set and transaction pool of a remote node,
+// accessed over its API, such that a wallet can run in a separate process from the node
+// it uses, and a (public-facing) node can serve consensus and relay transactions while holding no secrets.
+//
+// Only the calls used by the wallet are served by the remote node,
+// see the documentation of the ConsensusSet and TransactionPool types.
+package remote
+
+import (
+	"errors"
+	"time"
+
+	"github.com/threefoldtech/rivine/persist"
+	"github.com/threefoldtech/rivine/pkg/api"
+)
+
+var (
+	// ErrNotSupported is returned by the calls which cannot be made to a remote module.
+	ErrNotSupported = errors.New("not supported by a remote module")
+
+	errClosed = errors.New("remote module is closed")
+)
+
+const (
+	// minReconnectDelay is the delay before reconnecting a lost stream,
+	// doubled for every failed attempt, up to maxReconnectDelay.
+	minReconnectDelay = time.Second
+	maxReconnectDelay = time.Minute
+)
+
+// keepStreaming handles the given stream, or a newly opened one if nil, using the given function,
+// reopening the stream whenever it is lost, until stop is closed.
+// Failing attempts are retried with an exponential backoff, as are streams which are lost shortly after opening them.
+func keepStreaming(log *persist.Logger, name string, stream *api.WebSocketStream, stop <-chan struct{},
+	open func() (*api.WebSocketStream, error), handle func(*api.WebSocketStream) error) {
+	delay := minReconnectDelay
+	wait := func() bool {
+		select {
+		case <-stop:
+			return false
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > maxReconnectDelay {
+			delay = maxReconnectDelay
+		}
+		return true
+	}
+	for {
+		if stream == nil {
+			var err error
+			stream, err = open()
+			if err != nil {
+				log.Warnf("failed to open the %s stream, retrying in %v: %v", name, delay, err)
+				if !wait() {
+					return
+				}
+				continue
+			}
+		}
+
+		// close the stream once stopped, unblocking the handler
+		opened := time.Now()
+		handled := make(chan struct{})
+		go func(stream *api.WebSocketStream) {
+			select {
+			case <-stop:
+				stream.Close()
+			case <-handled:
+			}
+		}(stream)
+		err := handle(stream)
+		close(handled)
+		stream.Close()
+		stream = nil
+
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		if time.Since(opened) > maxReconnectDelay {
+			delay = minReconnectDelay
+		}
+		log.Warnf("lost the %s stream, reconnecting in %v: %v", name, delay, err)
+		if !wait() {
+			return
+		}
+	}
+}
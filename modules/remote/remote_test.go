@@ -0,0 +1,155 @@
+package remote
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/persist"
+	"github.com/threefoldtech/rivine/pkg/api"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// testNodeConsensusSet is the consensus set of the node,
+// pushing its changes to a subscriber, of which the blocks have their height as timestamp.
+type testNodeConsensusSet struct {
+	modules.ConsensusSet
+	changes []modules.ConsensusChange
+
+	mu         sync.Mutex
+	subscriber modules.ConsensusSetSubscriber
+}
+
+func (cs *testNodeConsensusSet) ConsensusSetSubscribe(subscriber modules.ConsensusSetSubscriber, start modules.ConsensusChangeID, _ <-chan struct{}) error {
+	changes := cs.changes
+	if start != modules.ConsensusChangeBeginning {
+		changes = nil
+		for i, cc := range cs.changes {
+			if cc.ID == start {
+				changes = cs.changes[i+1:]
+			}
+		}
+		if changes == nil {
+			return modules.ErrInvalidConsensusChangeID
+		}
+	}
+	for _, cc := range changes {
+		subscriber.ProcessConsensusChange(cc)
+	}
+	cs.mu.Lock()
+	cs.subscriber = subscriber
+	cs.mu.Unlock()
+	return nil
+}
+
+func (cs *testNodeConsensusSet) Unsubscribe(modules.ConsensusSetSubscriber) {
+	cs.mu.Lock()
+	cs.subscriber = nil
+	cs.mu.Unlock()
+}
+
+func (cs *testNodeConsensusSet) BlockHeightOfBlock(block types.Block) (types.BlockHeight, bool) {
+	return types.BlockHeight(block.Timestamp), true
+}
+
+type testNodeTransactionPool struct {
+	modules.TransactionPool
+}
+
+// testSubscriber records the heights of the blocks it applies,
+// as reported by the remote consensus set while processing the changes.
+type testSubscriber struct {
+	cs      *ConsensusSet
+	heights chan types.BlockHeight
+}
+
+func (s *testSubscriber) ProcessConsensusChange(cc modules.ConsensusChange) {
+	for _, block := range cc.AppliedBlocks {
+		height, ok := s.cs.BlockHeightOfBlock(block)
+		if !ok {
+			panic("unknown height of applied block")
+		}
+		s.heights <- height
+	}
+}
+
+func testConsensusChange(id byte, heights ...types.BlockHeight) modules.ConsensusChange {
+	cc := modules.ConsensusChange{ID: modules.ConsensusChangeID{id}}
+	for _, height := range heights {
+		cc.AppliedBlocks = append(cc.AppliedBlocks, types.Block{Timestamp: types.Timestamp(height)})
+	}
+	return cc
+}
+
+func TestConsensusSetSubscribe(t *testing.T) {
+	node := &testNodeConsensusSet{
+		changes: []modules.ConsensusChange{testConsensusChange(1, 0), testConsensusChange(2, 1, 2)},
+	}
+	router := httprouter.New()
+	api.RegisterRemoteNodeHTTPHandlers(router, node, testNodeTransactionPool{}, "foo")
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	cs := NewConsensusSet(&api.HTTPClient{RootURL: server.URL, Password: "foo"},
+		persist.NewLogger(types.DefaultBlockchainInfo(), ioutil.Discard))
+	defer cs.Close()
+	subscriber := &testSubscriber{cs: cs, heights: make(chan types.BlockHeight, 4)}
+
+	// the subscriber has caught up once subscribed
+	err := cs.ConsensusSetSubscribe(subscriber, modules.ConsensusChangeBeginning, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subscriber.heights) != 3 {
+		t.Fatalf("expected 3 applied blocks, got %d", len(subscriber.heights))
+	}
+	for expected := types.BlockHeight(0); expected < 3; expected++ {
+		if height := <-subscriber.heights; height != expected {
+			t.Fatalf("expected block at height %d, got %d", expected, height)
+		}
+	}
+	if err = cs.ConsensusSetSubscribe(subscriber, modules.ConsensusChangeBeginning, nil); err == nil {
+		t.Fatal("expected a second subscription to be refused")
+	}
+
+	// new changes are processed in the background
+	node.mu.Lock()
+	node.subscriber.ProcessConsensusChange(testConsensusChange(3, 3))
+	node.mu.Unlock()
+	select {
+	case height := <-subscriber.heights:
+		if height != 3 {
+			t.Fatalf("expected block at height 3, got %d", height)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("new consensus change was not processed")
+	}
+
+	cs.Unsubscribe(subscriber)
+	if err = cs.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = cs.ConsensusSetSubscribe(subscriber, modules.ConsensusChangeBeginning, nil); err != errClosed {
+		t.Fatalf("expected the closed error, got: %v", err)
+	}
+}
+
+func TestConsensusSetSubscribeUnknownChange(t *testing.T) {
+	node := &testNodeConsensusSet{changes: []modules.ConsensusChange{testConsensusChange(1, 0)}}
+	router := httprouter.New()
+	api.RegisterRemoteNodeHTTPHandlers(router, node, testNodeTransactionPool{}, "")
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	cs := NewConsensusSet(&api.HTTPClient{RootURL: server.URL},
+		persist.NewLogger(types.DefaultBlockchainInfo(), ioutil.Discard))
+	defer cs.Close()
+	err := cs.ConsensusSetSubscribe(&testSubscriber{cs: cs}, modules.ConsensusChangeID{2}, nil)
+	if err != modules.ErrInvalidConsensusChangeID {
+		t.Fatalf("expected the invalid consensus change error, got: %v", err)
+	}
+}
@@ -0,0 +1,218 @@
+package remote
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/persist"
+	"github.com/threefoldtech/rivine/pkg/api"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// TransactionPool is the transaction pool of a remote node, accessed over its API.
+// Subscribers receive the unconfirmed transactions streamed by the node,
+// reconnecting whenever the stream is lost. The consensus change passed along
+// with the unconfirmed transactions is always empty, as it is not streamed by the node.
+//
+// The calls used by the wallet, which are the subscription calls and the submission
+// of transaction sets, as well as the lookups of the unconfirmed transactions, are served by the node.
+// The calls used to create blocks and to manage the transaction pool are not supported, returning zero values,
+// as are the transaction pool events. Calls which cannot return an error return zero values
+// if the node cannot be reached, logging the error.
+type TransactionPool struct {
+	client *api.HTTPClient
+	log    *persist.Logger
+
+	mu            sync.Mutex
+	subscriptions map[modules.TransactionPoolSubscriber]*transactionPoolSubscription
+	closed        bool
+}
+
+type transactionPoolSubscription struct {
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewTransactionPool creates the transaction pool of the remote node the given client connects to.
+func NewTransactionPool(client *api.HTTPClient, log *persist.Logger) *TransactionPool {
+	return &TransactionPool{
+		client:        client,
+		log:           log,
+		subscriptions: make(map[modules.TransactionPoolSubscriber]*transactionPoolSubscription),
+	}
+}
+
+// TransactionPoolSubscribe implements modules.TransactionPool.TransactionPoolSubscribe,
+// the subscriber receives the unconfirmed transactions in the background, once connected to the node.
+func (tp *TransactionPool) TransactionPoolSubscribe(subscriber modules.TransactionPoolSubscriber) {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+	if _, ok := tp.subscriptions[subscriber]; ok || tp.closed {
+		return
+	}
+	sub := &transactionPoolSubscription{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	tp.subscriptions[subscriber] = sub
+	go func() {
+		defer close(sub.done)
+		keepStreaming(tp.log, "transaction pool update", nil, sub.stop, func() (*api.WebSocketStream, error) {
+			return tp.client.Subscribe("/transactionpool/updates")
+		}, func(stream *api.WebSocketStream) error {
+			for {
+				var update api.TransactionPoolUpdate
+				if err := stream.ReadJSON(&update); err != nil {
+					return err
+				}
+				subscriber.ReceiveUpdatedUnconfirmedTransactions(update.Transactions, modules.ConsensusChange{})
+			}
+		})
+	}()
+}
+
+// Unsubscribe implements modules.TransactionPool.Unsubscribe,
+// returning once the stream of the subscriber is closed.
+func (tp *TransactionPool) Unsubscribe(subscriber modules.TransactionPoolSubscriber) {
+	tp.mu.Lock()
+	sub, ok := tp.subscriptions[subscriber]
+	delete(tp.subscriptions, subscriber)
+	tp.mu.Unlock()
+	if ok {
+		close(sub.stop)
+		<-sub.done
+	}
+}
+
+// Close implements modules.TransactionPool.Close,
+// unsubscribing all subscribers.
+func (tp *TransactionPool) Close() error {
+	tp.mu.Lock()
+	tp.closed = true
+	subscribers := make([]modules.TransactionPoolSubscriber, 0, len(tp.subscriptions))
+	for subscriber := range tp.subscriptions {
+		subscribers = append(subscribers, subscriber)
+	}
+	tp.mu.Unlock()
+	for _, subscriber := range subscribers {
+		tp.Unsubscribe(subscriber)
+	}
+	return nil
+}
+
+// AcceptTransactionSet implements modules.TransactionPool.AcceptTransactionSet,
+// the node accepts the transaction set as a local one, such that it rebroadcasts it until it is confirmed.
+func (tp *TransactionPool) AcceptTransactionSet(txns []types.Transaction) error {
+	return tp.postTransactionSet(txns, false)
+}
+
+// AcceptLocalTransactionSet implements modules.TransactionPool.AcceptLocalTransactionSet
+func (tp *TransactionPool) AcceptLocalTransactionSet(txns []types.Transaction) error {
+	return tp.postTransactionSet(txns, false)
+}
+
+// AcceptPriorityTransactionSet implements modules.TransactionPool.AcceptPriorityTransactionSet
+func (tp *TransactionPool) AcceptPriorityTransactionSet(txns []types.Transaction) error {
+	return tp.postTransactionSet(txns, true)
+}
+
+func (tp *TransactionPool) postTransactionSet(txns []types.Transaction, priority bool) error {
+	b, err := json.Marshal(api.TransactionPoolSetPOST{Transactions: txns})
+	if err != nil {
+		return err
+	}
+	call := "/transactionpool/transactionset"
+	if priority {
+		call += "?priority=true"
+	}
+	var resp api.TransactionPoolSetPOSTResp
+	return tp.client.PostResp(call, string(b), &resp)
+}
+
+// TransactionList implements modules.TransactionPool.TransactionList
+func (tp *TransactionPool) TransactionList() []types.Transaction {
+	var resp api.TransactionPoolGET
+	if err := tp.client.GetAPI("/transactionpool/transactions", &resp); err != nil {
+		tp.log.Warnf("failed to get the remote unconfirmed transactions: %v", err)
+	}
+	return resp.Transactions
+}
+
+// Transaction implements modules.TransactionPool.Transaction
+func (tp *TransactionPool) Transaction(id types.TransactionID) (types.Transaction, error) {
+	ptxn, err := tp.PooledTransaction(id)
+	return ptxn.Transaction, err
+}
+
+// PooledTransactions implements modules.TransactionPool.PooledTransactions
+func (tp *TransactionPool) PooledTransactions() []modules.PooledTransaction {
+	var resp api.TransactionPoolGetEntries
+	if err := tp.client.GetAPI("/transactionpool/entries", &resp); err != nil {
+		tp.log.Warnf("failed to get the remote unconfirmed transactions: %v", err)
+	}
+	return resp.Transactions
+}
+
+// PooledTransaction implements modules.TransactionPool.PooledTransaction
+func (tp *TransactionPool) PooledTransaction(id types.TransactionID) (modules.PooledTransaction, error) {
+	var resp api.TransactionPoolGetTransaction
+	err := tp.client.GetAPI("/transactionpool/transactions/"+id.String(), &resp)
+	if err == api.ErrStatusNotFound {
+		return modules.PooledTransaction{}, modules.ErrTransactionNotFound
+	}
+	return resp.PooledTransaction, err
+}
+
+// Statistics implements modules.TransactionPool.Statistics
+func (tp *TransactionPool) Statistics() modules.TransactionPoolStatistics {
+	var resp api.TransactionPoolGetStatistics
+	if err := tp.client.GetAPI("/transactionpool/statistics", &resp); err != nil {
+		tp.log.Warnf("failed to get the remote transaction pool statistics: %v", err)
+	}
+	return resp.TransactionPoolStatistics
+}
+
+// FeeHistogram implements modules.TransactionPool.FeeHistogram
+func (tp *TransactionPool) FeeHistogram() []modules.FeeHistogramEntry {
+	var resp api.TransactionPoolGetFeeHistogram
+	if err := tp.client.GetAPI("/transactionpool/feehistogram", &resp); err != nil {
+		tp.log.Warnf("failed to get the remote fee histogram: %v", err)
+	}
+	return resp.Histogram
+}
+
+// FeeEstimate implements modules.TransactionPool.FeeEstimate
+func (tp *TransactionPool) FeeEstimate() modules.FeeEstimate {
+	var resp api.TransactionPoolGetFeeEstimate
+	if err := tp.client.GetAPI("/transactionpool/feeestimate", &resp); err != nil {
+		tp.log.Warnf("failed to get the remote fee estimate: %v", err)
+	}
+	return resp.FeeEstimate
+}
+
+// FeeEstimation implements modules.TransactionPool.FeeEstimation,
+// using the low and high fee estimate of the node.
+func (tp *TransactionPool) FeeEstimation() (types.Currency, types.Currency) {
+	estimate := tp.FeeEstimate()
+	return estimate.Low, estimate.High
+}
+
+// BlockTransactions implements modules.TransactionPool.BlockTransactions,
+// blocks cannot be created using a remote transaction pool.
+func (tp *TransactionPool) BlockTransactions(uint64) []types.Transaction {
+	return nil
+}
+
+// PurgeTransactionPool implements modules.TransactionPool.PurgeTransactionPool,
+// a remote transaction pool cannot be purged.
+func (tp *TransactionPool) PurgeTransactionPool() {}
+
+// TransactionPoolEventSubscribe implements modules.TransactionPool.TransactionPoolEventSubscribe,
+// the events of a remote transaction pool are not streamed.
+func (tp *TransactionPool) TransactionPoolEventSubscribe(modules.TransactionPoolEventListener) {}
+
+// TransactionPoolEventUnsubscribe implements modules.TransactionPool.TransactionPoolEventUnsubscribe
+func (tp *TransactionPool) TransactionPoolEventUnsubscribe(modules.TransactionPoolEventListener) {}
+
+var _ modules.TransactionPool = (*TransactionPool)(nil)
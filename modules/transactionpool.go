@@ -1,7 +1,9 @@
 package modules
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 
 	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
 	"github.com/threefoldtech/rivine/types"
@@ -32,6 +34,15 @@ var (
 	// in the transaction pool for a specific ID.
 	ErrTransactionNotFound = errors.New("transaction not found")
 
+	// ErrReplacementFeeTooLow is returned in case a transaction set conflicts with
+	// pooled transactions, but does not pay sufficiently higher fees to replace them.
+	ErrReplacementFeeTooLow = errors.New("transaction set does not pay enough fees to replace the conflicting pooled transactions")
+
+	// ErrOrphanTransactionSet is returned in case a transaction set spends outputs
+	// which are not known yet. Such a transaction set is held by the transaction pool
+	// as an orphan, and accepted automatically once its parent(s) become known.
+	ErrOrphanTransactionSet = errors.New("transaction set spends unknown outputs, it is held as an orphan until its parents are known")
+
 	// TransactionPoolDir is the name of the directory that is used to store
 	// the transaction pool's persistent data.
 	TransactionPoolDir = "transactionpool"
@@ -45,6 +56,9 @@ const (
 	// TransactionPoolMaxRebroadcasts is the maximum amount of times a transaction
 	// will get broadcast again.
 	TransactionPoolMaxRebroadcasts = 4
+	// TransactionPoolOrphanLifetime is the amount of blocks an orphan transaction set
+	// is held by the transaction pool, waiting for its parents, before it is dropped.
+	TransactionPoolOrphanLifetime = 3
 )
 
 // A TransactionPoolSubscriber receives updates about the confirmed and
@@ -57,12 +71,213 @@ type TransactionPoolSubscriber interface {
 	ReceiveUpdatedUnconfirmedTransactions([]types.Transaction, ConsensusChange)
 }
 
+// TransactionPoolEventType defines the type of a TransactionPoolEvent.
+type TransactionPoolEventType uint8
+
+const (
+	// TransactionPoolEventReplaced is emitted when pooled transactions
+	// are replaced by a conflicting transaction set, paying higher fees.
+	TransactionPoolEventReplaced TransactionPoolEventType = iota + 1
+	// TransactionPoolEventEvicted is emitted when pooled transactions are evicted
+	// from a full transaction pool, to make room for transactions paying a higher fee-per-byte.
+	TransactionPoolEventEvicted
+	// TransactionPoolEventExpired is emitted when pooled transactions are dropped,
+	// as they remained unconfirmed for longer than the maximum transaction age.
+	TransactionPoolEventExpired
+	// TransactionPoolEventConflict is emitted when a transaction arrives, in the pool or in a block,
+	// which spends the same outputs as pooled transactions, indicating an attempted double spend.
+	TransactionPoolEventConflict
+	// TransactionPoolEventAdded is emitted when transactions are added to the pool.
+	TransactionPoolEventAdded
+	// TransactionPoolEventRemoved is emitted when transactions are removed from the pool,
+	// without being confirmed (e.g. because they were replaced, evicted, expired or became invalid).
+	TransactionPoolEventRemoved
+	// TransactionPoolEventConfirmed is emitted when pooled transactions are
+	// removed from the pool, as they got confirmed in a block.
+	TransactionPoolEventConfirmed
+)
+
+// String returns the type as a human-readable string.
+func (t TransactionPoolEventType) String() string {
+	switch t {
+	case TransactionPoolEventReplaced:
+		return "replaced"
+	case TransactionPoolEventEvicted:
+		return "evicted"
+	case TransactionPoolEventExpired:
+		return "expired"
+	case TransactionPoolEventConflict:
+		return "conflict"
+	case TransactionPoolEventAdded:
+		return "added"
+	case TransactionPoolEventRemoved:
+		return "removed"
+	case TransactionPoolEventConfirmed:
+		return "confirmed"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.MarshalText
+func (t TransactionPoolEventType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.UnmarshalText
+func (t *TransactionPoolEventType) UnmarshalText(b []byte) error {
+	for et := TransactionPoolEventReplaced; et <= TransactionPoolEventConfirmed; et++ {
+		if et.String() == string(b) {
+			*t = et
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown transaction pool event type %q", string(b))
+}
+
+// TransactionPoolEvent describes a change to the transaction pool,
+// which is relevant to a specific set of transactions.
+type TransactionPoolEvent struct {
+	Type TransactionPoolEventType `json:"type"`
+	// Transactions are the IDs of the transactions the event is about,
+	// e.g. the replaced, evicted, expired or conflicting pooled transactions.
+	Transactions []types.TransactionID `json:"transactions"`
+	// RelatedTransactions are the IDs of the transactions related to the event,
+	// e.g. the replacing transactions, or the transactions conflicting with pooled transactions.
+	RelatedTransactions []types.TransactionID `json:"relatedtransactions,omitempty"`
+	// Outputs are the IDs of the outputs related to the event,
+	// e.g. the outputs contested by conflicting transactions.
+	Outputs []types.OutputID `json:"outputs,omitempty"`
+}
+
+// A TransactionPoolEventListener receives the events of the transaction pool,
+// such as transactions being added to, removed from or confirmed out of the pool,
+// without receiving the full set of unconfirmed transactions on every change.
+type TransactionPoolEventListener interface {
+	// ReceiveTransactionPoolEvent notifies listeners of an event,
+	// prior to the update of the unconfirmed transactions it resulted in.
+	// It is called while the transaction pool is locked, and should thus not block.
+	ReceiveTransactionPoolEvent(TransactionPoolEvent)
+}
+
+// A TransactionPoolEventSubscriber is a TransactionPoolSubscriber which
+// also receives the events of the transaction pool, such as replaced transactions.
+// A TransactionPoolSubscriber can optionally implement this interface.
+type TransactionPoolEventSubscriber interface {
+	TransactionPoolSubscriber
+	TransactionPoolEventListener
+}
+
+// PooledTransaction describes an unconfirmed transaction in the transaction pool.
+type PooledTransaction struct {
+	Transaction types.Transaction   `json:"transaction"`
+	ID          types.TransactionID `json:"id"`
+	// Fee is the total miner fee paid by the transaction.
+	Fee types.Currency `json:"fee"`
+	// Size is the size, in bytes, of the binary-encoded transaction.
+	Size uint64 `json:"size"`
+	// Height is the block height at which the transaction was added to the pool,
+	// such that its age can be computed.
+	Height types.BlockHeight `json:"height"`
+	// Parents are the pooled transactions which create outputs spent by this transaction.
+	Parents []types.TransactionID `json:"parents,omitempty"`
+	// Children are the pooled transactions which spend outputs created by this transaction.
+	Children []types.TransactionID `json:"children,omitempty"`
+}
+
+// TransactionPoolStatistics contains aggregate information about the transaction pool.
+type TransactionPoolStatistics struct {
+	TransactionCount    uint64 `json:"transactioncount"`
+	TransactionSetCount uint64 `json:"transactionsetcount"`
+	// Size is the combined size, in bytes, of all pooled transaction sets.
+	Size uint64 `json:"size"`
+	// SizeLimit is the size, in bytes, above which the pool is considered full.
+	SizeLimit uint64 `json:"sizelimit"`
+	// TransactionCountLimit is the maximum amount of pooled transactions, 0 if there is no limit.
+	TransactionCountLimit uint64 `json:"transactioncountlimit"`
+	// SourceTransactionLimit is the maximum amount of pooled transactions relayed
+	// by a single peer, 0 if there is no limit.
+	SourceTransactionLimit uint64 `json:"sourcetransactionlimit"`
+	// SizeUtilization and CountUtilization are the fractions of
+	// the size and transaction count limit in use.
+	SizeUtilization  float64 `json:"sizeutilization"`
+	CountUtilization float64 `json:"countutilization"`
+	// Sources contains the amount of pooled transactions per source,
+	// being the address of the relaying peer, or "local" for the transactions submitted through this node.
+	Sources map[string]uint64 `json:"sources,omitempty"`
+
+	TotalFees   types.Currency `json:"totalfees"`
+	OrphanCount uint64         `json:"orphancount"`
+	// OrphanSize is the combined size, in bytes, of all orphan transaction sets.
+	OrphanSize uint64 `json:"orphansize"`
+}
+
+// FeeHistogramEntry is a bin of a fee histogram, containing the combined size of
+// the pooled transaction sets paying at least the given fee-per-byte, yet less than
+// the fee-per-byte of the previous bin.
+//
+// It is JSON-encoded as a [feeperbyte, size] pair, the same shape as used by
+// the mempool.get_fee_histogram method of the Electrum protocol.
+type FeeHistogramEntry struct {
+	FeePerByte types.Currency
+	Size       uint64
+}
+
+// MarshalJSON implements json.Marshaler.MarshalJSON
+func (entry FeeHistogramEntry) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]interface{}{entry.FeePerByte, entry.Size})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.UnmarshalJSON
+func (entry *FeeHistogramEntry) UnmarshalJSON(b []byte) error {
+	pair := []interface{}{&entry.FeePerByte, &entry.Size}
+	err := json.Unmarshal(b, &pair)
+	if err != nil {
+		return err
+	}
+	if len(pair) != 2 {
+		return errors.New("fee histogram entry has to be a [feeperbyte, size] pair")
+	}
+	return nil
+}
+
+// FeeEstimate contains the estimated fee-per-byte a transaction set has to pay
+// in order to be included in a block within a given amount of blocks,
+// based on the transaction sets currently in the transaction pool.
+// Estimates are never lower than the minimum fee-per-byte accepted by the transaction pool.
+type FeeEstimate struct {
+	// Low targets getting included within FeeEstimateBlocksLow blocks.
+	Low types.Currency `json:"low"`
+	// Normal targets getting included within FeeEstimateBlocksNormal blocks.
+	Normal types.Currency `json:"normal"`
+	// High targets getting included in the next block.
+	High types.Currency `json:"high"`
+}
+
+// The amount of blocks targeted by the different priorities of a FeeEstimate.
+const (
+	FeeEstimateBlocksLow    = 10
+	FeeEstimateBlocksNormal = 3
+	FeeEstimateBlocksHigh   = 1
+)
+
 // A TransactionPool manages unconfirmed transactions.
 type TransactionPool interface {
 	// AcceptTransactionSet accepts a set of potentially interdependent
 	// transactions.
 	AcceptTransactionSet([]types.Transaction) error
 
+	// AcceptLocalTransactionSet accepts a set of potentially interdependent
+	// transactions, submitted through this node (e.g. its wallet or API).
+	// Local transactions are rebroadcast every block, until they are confirmed or dropped from the pool.
+	AcceptLocalTransactionSet([]types.Transaction) error
+
+	// AcceptPriorityTransactionSet accepts a set of potentially interdependent
+	// local transactions with priority, exempting them from the fee and size policies
+	// of the transaction pool (but not from consensus validity), such that operators can
+	// get their own transactions pooled when the pool is full. Priority transactions are never evicted.
+	AcceptPriorityTransactionSet([]types.Transaction) error
+
 	// Close is necessary for clean shutdown (e.g. during testing).
 	Close() error
 
@@ -86,10 +301,37 @@ type TransactionPool interface {
 	// put into a block.
 	TransactionList() []types.Transaction
 
+	// BlockTransactions returns the pooled transactions to put into a new block,
+	// sorted by decreasing (package) fee-per-byte, such that their combined size
+	// does not exceed the given size limit, in bytes. Transaction sets are
+	// either included as a whole, or not at all.
+	BlockTransactions(sizeLimit uint64) []types.Transaction
+
 	// Transaction returns the transaction with the given ID from the transaction pool.
 	// If no transaction for that ID is found ErrNotFound is returned.
 	Transaction(id types.TransactionID) (types.Transaction, error)
 
+	// PooledTransactions returns all transactions in the transaction pool,
+	// together with their fee, size, age and dependency information.
+	PooledTransactions() []PooledTransaction
+
+	// PooledTransaction returns the transaction with the given ID from the transaction pool,
+	// together with its fee, size, age and dependency information.
+	// If no transaction for that ID is found ErrTransactionNotFound is returned.
+	PooledTransaction(id types.TransactionID) (PooledTransaction, error)
+
+	// Statistics returns aggregate information about the transaction pool.
+	Statistics() TransactionPoolStatistics
+
+	// FeeHistogram returns a histogram of the fee-per-byte paid by the pooled transaction sets,
+	// sorted by decreasing fee-per-byte.
+	FeeHistogram() []FeeHistogramEntry
+
+	// FeeEstimate returns the fee-per-byte a transaction set has to pay
+	// in order to be included within a low, normal and high priority amount of blocks,
+	// based on the fee-per-byte paid by the pooled transaction sets.
+	FeeEstimate() FeeEstimate
+
 	// TransactionPoolSubscribe adds a subscriber to the transaction pool.
 	// Subscribers will receive all consensus set changes as well as
 	// transaction pool changes, and should not subscribe to both.
@@ -98,6 +340,13 @@ type TransactionPool interface {
 	// Unsubscribe removes a subscriber from the transaction pool.
 	// This is necessary for clean shutdown of the miner.
 	Unsubscribe(TransactionPoolSubscriber)
+
+	// TransactionPoolEventSubscribe adds a listener to the transaction pool,
+	// which receives all events of the transaction pool from now on.
+	TransactionPoolEventSubscribe(TransactionPoolEventListener)
+
+	// TransactionPoolEventUnsubscribe removes a listener from the transaction pool.
+	TransactionPoolEventUnsubscribe(TransactionPoolEventListener)
 }
 
 // ConsensusConflict implements the error interface, and indicates that a
@@ -23,6 +23,7 @@ var (
 	errFullTransactionPool = errors.New("transaction pool cannot accept more transactions")
 	errLowMinerFees        = errors.New("transaction set needs more miner fees to be accepted")
 	errEmptySet            = errors.New("transaction set is empty")
+	errSourceLimit         = errors.New("transaction pool cannot accept more transactions from this source")
 )
 
 // relatedObjectIDs determines all of the object ids related to a transaction.
@@ -51,23 +52,27 @@ func relatedObjectIDs(ts []types.Transaction) []ObjectID {
 }
 
 // validateTransactionSetComposition checks if the transaction set
-// is valid given the state of the pool.
-func (tp *TransactionPool) validateTransactionSetComposition(ts []types.Transaction) error {
+// is valid given the state of the pool, returning the pooled transaction sets
+// which have to be evicted to make room for it, in case the pool is full.
+// These are not evicted yet, as the transaction set might still turn out to be invalid.
+func (tp *TransactionPool) validateTransactionSetComposition(ts []types.Transaction) ([]TransactionSetID, error) {
 	// Check that the transaction set is not already known.
 	setID := TransactionSetID(crypto.HashObject(ts))
 	_, exists := tp.transactionSets[setID]
 	if exists {
-		return modules.ErrDuplicateTransactionSet
-	}
-
-	if tp.transactionListSize > tp.chainCts.TransactionPool.PoolSizeLimit {
-		return errFullTransactionPool
+		return nil, modules.ErrDuplicateTransactionSet
 	}
 
 	// TODO: There is no DoS prevention mechanism in place to prevent repeated
 	// expensive verifications of invalid transactions that are created on the
 	// fly.
 
+	// Priority transaction sets are exempt from the fee and size policies of the pool,
+	// and thus only have to be valid according to the consensus.
+	if tp.priority.containsAll(ts) {
+		return nil, tp.validateTransactionSet(ts, false)
+	}
+
 	// Validates that the transaction set fits within the
 	// chain (network) defined byte size limit, when binary encoded.
 	// It also validates that the the transaction itself does not exceed a transaction pool defined
@@ -77,9 +82,23 @@ func (tp *TransactionPool) validateTransactionSetComposition(ts []types.Transact
 	// that the validation code knows the transaction is still unconfirmed, and thus not yet part of a created block.
 	err := tp.ValidateTransactionSet(ts)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+
+	// Check that the transaction set pays the minimum fee-per-byte.
+	size := len(siabin.Marshal(ts))
+	fee := transactionSetFee(ts)
+	if fee.Cmp(tp.chainCts.TransactionPool.MinimumFeePerByte.Mul64(uint64(size))) < 0 {
+		return nil, errLowMinerFees
+	}
+
+	// Select the transaction sets paying a lower fee-per-byte
+	// to make room for the transaction set, in case the pool is full.
+	if tp.transactionListSize > tp.chainCts.TransactionPool.PoolSizeLimit ||
+		!tp.withinCountLimit(tp.transactionCount()+len(ts)) {
+		return tp.selectEvictions(ts, fee, size)
+	}
+	return nil, nil
 }
 
 // handleConflicts detects whether the conflicts in the transaction pool are
@@ -145,7 +164,7 @@ func (tp *TransactionPool) handleConflicts(ts []types.Transaction, conflicts []T
 
 	// Validates the composition of the transaction set, including fees and
 	// IsStandard rules (this is a new set, the rules must be rechecked).
-	err := tp.validateTransactionSetComposition(superset)
+	evictions, err := tp.validateTransactionSetComposition(superset)
 	if err != nil {
 		return err
 	}
@@ -156,6 +175,9 @@ func (tp *TransactionPool) handleConflicts(ts []types.Transaction, conflicts []T
 		return modules.NewConsensusConflict(err.Error())
 	}
 
+	// Make room for the transaction set, now that it is known to be valid.
+	tp.evictTransactionSets(evictions, superset)
+
 	// Remove the conflicts from the transaction pool. The diffs do not need to
 	// be removed, they will be overwritten later in the function.
 	for _, conflict := range conflictMap {
@@ -174,6 +196,8 @@ func (tp *TransactionPool) handleConflicts(ts []types.Transaction, conflicts []T
 	for _, diff := range cc.BlockStakeOutputDiffs {
 		tp.knownObjects[ObjectID(diff.ID)] = setID
 	}
+	// the transactions of the conflicts keep their age
+	tp.ages.add(superset, tp.consensusSet.Height())
 	tp.transactionSetDiffs[setID] = cc
 	tp.transactionListSize += len(siabin.Marshal(superset))
 	return nil
@@ -205,12 +229,30 @@ func (tp *TransactionPool) acceptTransactionSet(ts []types.Transaction) error {
 		return modules.ErrDuplicateTransactionSet
 	}
 
-	// Validate the composition of the transaction set
-	err = tp.validateTransactionSetComposition(ts)
+	// Validate the composition of the transaction set. Transaction sets which
+	// do not pay the minimum fee are held, such that a child can pay for them.
+	evictions, err := tp.validateTransactionSetComposition(ts)
+	if err == errLowMinerFees {
+		tp.underpaid.add(ts, tp.consensusSet.Height(), tp.chainCts.TransactionPool.OrphanPoolSizeLimit)
+	}
 	if err != nil {
 		return err
 	}
 
+	// Notify subscribers of pooled transaction sets which spend the same outputs,
+	// and replace them if allowed by the replace-by-fee policy. Without it,
+	// such a double-spend gets rejected as a consensus conflict.
+	if conflicts := doubleSpends(tp.transactionSets, ts); len(conflicts) > 0 {
+		tp.pendingEvents = append(tp.pendingEvents, conflictEvents(tp.transactionSets, conflicts)...)
+		if tp.chainCts.TransactionPool.ReplaceByFee {
+			replaced := make([]TransactionSetID, 0, len(conflicts))
+			for id := range conflicts {
+				replaced = append(replaced, id)
+			}
+			return tp.replaceTransactionSets(ts, replaced)
+		}
+	}
+
 	// Check for conflicts with other transactions, which would indicate a
 	// double-spend. Legal children of a transaction set will also trigger the
 	// conflict-detector.
@@ -225,11 +267,26 @@ func (tp *TransactionPool) acceptTransactionSet(ts []types.Transaction) error {
 	if len(conflicts) > 0 {
 		return tp.handleConflicts(ts, conflicts)
 	}
+	// Hold the transaction set as an orphan, in case it spends outputs
+	// which are not known (yet), rather than rejecting it, unless it spends
+	// outputs of underpaid transaction sets, in which case it is accepted as
+	// a single package with those, should it pay enough for the whole package.
+	if tp.hasMissingParents(ts) {
+		if pkg, parents := tp.underpaidPackage(ts); len(parents) > 0 {
+			return tp.acceptUnderpaidPackage(pkg, parents)
+		}
+		tp.orphans.add(ts, tp.consensusSet.Height(), tp.chainCts.TransactionPool.OrphanPoolSizeLimit)
+		return modules.ErrOrphanTransactionSet
+	}
 	cc, err := tp.consensusSet.TryTransactionSet(ts)
 	if err != nil {
 		return modules.NewConsensusConflict(err.Error())
 	}
 
+	// Make room for the transaction set, now that it is known to be valid,
+	// such that invalid transaction sets cannot flush the pool.
+	tp.evictTransactionSets(evictions, ts)
+
 	// Add the transaction set to the pool.
 	setID := TransactionSetID(crypto.HashObject(ts))
 	tp.transactionSets[setID] = ts
@@ -238,6 +295,7 @@ func (tp *TransactionPool) acceptTransactionSet(ts []types.Transaction) error {
 	}
 	// remember when the transaction was added
 	tp.broadcastCache.add(setID, tp.consensusSet.Height())
+	tp.ages.add(ts, tp.consensusSet.Height())
 	tp.transactionSetDiffs[setID] = cc
 	tp.transactionListSize += len(siabin.Marshal(ts))
 	return nil
@@ -247,17 +305,59 @@ func (tp *TransactionPool) acceptTransactionSet(ts []types.Transaction) error {
 // transactions. If the transaction is accepted, it will be relayed to
 // connected peers.
 func (tp *TransactionPool) AcceptTransactionSet(ts []types.Transaction) error {
+	return tp.acceptTransactionSetFromSource(ts, "", false)
+}
+
+// AcceptLocalTransactionSet implements TransactionPool.AcceptLocalTransactionSet
+func (tp *TransactionPool) AcceptLocalTransactionSet(ts []types.Transaction) error {
+	return tp.acceptTransactionSetFromSource(ts, localSource, false)
+}
+
+// AcceptPriorityTransactionSet implements TransactionPool.AcceptPriorityTransactionSet
+func (tp *TransactionPool) AcceptPriorityTransactionSet(ts []types.Transaction) error {
+	return tp.acceptTransactionSetFromSource(ts, localSource, true)
+}
+
+// acceptTransactionSetFromSource accepts a transaction set, received from the given source,
+// and relays it to the connected peers if it is accepted. The source is either the address of
+// the peer which relayed the transaction set, localSource, or the empty string if unknown.
+// Only local transaction sets can be accepted with priority, exempting them from the fee and size policies.
+func (tp *TransactionPool) acceptTransactionSetFromSource(ts []types.Transaction, source string, priority bool) error {
 	tp.mu.Lock()
 	defer tp.mu.Unlock()
 
+	// Ensure a single peer cannot fill up the transaction pool.
+	if source != "" && source != localSource && !tp.sources.withinLimit(source, len(ts), tp.chainCts.TransactionPool.PoolSourceTransactionLimit) {
+		return errSourceLimit
+	}
+
+	var marked []types.TransactionID
+	if priority && source == localSource {
+		marked = tp.priority.mark(ts)
+	}
 	err := tp.acceptTransactionSet(ts)
 	if err != nil {
+		for _, id := range marked {
+			delete(tp.priority, id)
+		}
+		// events (e.g. conflicts) can occur for rejected transaction sets as well
+		tp.sendEvents(tp.takePendingEvents())
 		return err
 	}
+	if source != "" {
+		tp.sources.add(ts, source)
+	}
+	if source == localSource {
+		tp.local.add(ts)
+	}
 
-	// Notify subscribers and broadcast the transaction set.
+	// Notify subscribers and broadcast the transaction set,
+	// as well as any orphans which could be accepted thanks to it.
 	go tp.gateway.Broadcast("RelayTransactionSet", ts, tp.gateway.Peers())
-	tp.updateSubscribersTransactions()
+	for _, set := range tp.promoteOrphans() {
+		go tp.gateway.Broadcast("RelayTransactionSet", set, tp.gateway.Peers())
+	}
+	tp.updateSubscribersTransactions(tp.takePendingEvents())
 	return nil
 }
 
@@ -270,7 +370,7 @@ func (tp *TransactionPool) relayTransactionSet(conn modules.PeerConn) error {
 	if err != nil {
 		return err
 	}
-	return tp.AcceptTransactionSet(ts)
+	return tp.acceptTransactionSetFromSource(ts, string(conn.RPCAddr()), false)
 }
 
 func (tp *TransactionPool) transactionMinFee() types.Currency {
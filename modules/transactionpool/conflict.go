@@ -0,0 +1,79 @@
+package transactionpool
+
+import (
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+type (
+	// setConflict describes how a transaction set conflicts with other transactions.
+	setConflict struct {
+		// outputs are the contested outputs
+		outputs []types.OutputID
+		// spenders are the other transactions spending the contested outputs
+		spenders []types.TransactionID
+	}
+)
+
+// doubleSpends returns, for every given transaction set which spends outputs
+// also spent by a different transaction of the given transactions, how it conflicts.
+// Transactions which are part of both a transaction set and the given transactions
+// (e.g. duplicates or confirmed transactions) are not considered conflicting.
+func doubleSpends(sets map[TransactionSetID][]types.Transaction, txns []types.Transaction) map[TransactionSetID]*setConflict {
+	spenders := make(map[ObjectID]types.TransactionID)
+	for _, txn := range txns {
+		id := txn.ID()
+		for oid := range spentObjectIDs([]types.Transaction{txn}) {
+			spenders[oid] = id
+		}
+	}
+	conflicts := make(map[TransactionSetID]*setConflict)
+	for setID, set := range sets {
+		for _, txn := range set {
+			id := txn.ID()
+			for oid := range spentObjectIDs([]types.Transaction{txn}) {
+				spender, ok := spenders[oid]
+				if !ok || spender == id {
+					continue
+				}
+				conflict, ok := conflicts[setID]
+				if !ok {
+					conflict = new(setConflict)
+					conflicts[setID] = conflict
+				}
+				conflict.outputs = append(conflict.outputs, types.OutputID(oid))
+				conflict.addSpender(spender)
+			}
+		}
+	}
+	return conflicts
+}
+
+// addSpender adds a conflicting transaction, if it wasn't added yet.
+func (conflict *setConflict) addSpender(id types.TransactionID) {
+	for _, spender := range conflict.spenders {
+		if spender == id {
+			return
+		}
+	}
+	conflict.spenders = append(conflict.spenders, id)
+}
+
+// conflictEvents creates a conflict event for every conflicting transaction set,
+// naming the transactions of that set, the transactions conflicting with it,
+// and the contested outputs.
+func conflictEvents(sets map[TransactionSetID][]types.Transaction, conflicts map[TransactionSetID]*setConflict) []modules.TransactionPoolEvent {
+	events := make([]modules.TransactionPoolEvent, 0, len(conflicts))
+	for setID, conflict := range conflicts {
+		event := modules.TransactionPoolEvent{
+			Type:                modules.TransactionPoolEventConflict,
+			RelatedTransactions: conflict.spenders,
+			Outputs:             conflict.outputs,
+		}
+		for _, txn := range sets[setID] {
+			event.Transactions = append(event.Transactions, txn.ID())
+		}
+		events = append(events, event)
+	}
+	return events
+}
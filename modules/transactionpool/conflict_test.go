@@ -0,0 +1,64 @@
+package transactionpool
+
+import (
+	"testing"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// TestDoubleSpends ensures only transaction sets which spend the
+// same outputs as a different transaction are detected as conflicting.
+func TestDoubleSpends(t *testing.T) {
+	pooled := types.Transaction{
+		Version:          types.TransactionVersionOne,
+		CoinInputs:       []types.CoinInput{{ParentID: types.CoinOutputID{1}}},
+		BlockStakeInputs: []types.BlockStakeInput{{ParentID: types.BlockStakeOutputID{4}}},
+	}
+	sets := map[TransactionSetID][]types.Transaction{
+		{1}: {pooled},
+		{2}: {{CoinInputs: []types.CoinInput{{ParentID: types.CoinOutputID{2}}}}},
+	}
+
+	// duplicates do not conflict
+	conflicts := doubleSpends(sets, []types.Transaction{pooled})
+	if len(conflicts) != 0 {
+		t.Fatal("duplicate transactions should not conflict:", conflicts)
+	}
+
+	// unrelated transactions do not conflict
+	conflicts = doubleSpends(sets, []types.Transaction{{
+		CoinInputs: []types.CoinInput{{ParentID: types.CoinOutputID{3}}},
+	}})
+	if len(conflicts) != 0 {
+		t.Fatal("unrelated transactions should not conflict:", conflicts)
+	}
+
+	// double spends conflict
+	doubleSpend := types.Transaction{
+		Version:          types.TransactionVersionOne,
+		CoinInputs:       []types.CoinInput{{ParentID: types.CoinOutputID{1}}},
+		BlockStakeInputs: []types.BlockStakeInput{{ParentID: types.BlockStakeOutputID{4}}},
+		MinerFees:        []types.Currency{types.NewCurrency64(1)},
+	}
+	conflicts = doubleSpends(sets, []types.Transaction{doubleSpend})
+	conflict, ok := conflicts[TransactionSetID{1}]
+	if len(conflicts) != 1 || !ok {
+		t.Fatal("unexpected conflicts:", conflicts)
+	}
+	if len(conflict.outputs) != 2 || len(conflict.spenders) != 1 || conflict.spenders[0] != doubleSpend.ID() {
+		t.Fatal("unexpected conflict:", conflict)
+	}
+
+	events := conflictEvents(sets, conflicts)
+	if len(events) != 1 {
+		t.Fatal("expected a single conflict event, got:", events)
+	}
+	event := events[0]
+	if event.Type != modules.TransactionPoolEventConflict ||
+		len(event.Transactions) != 1 || event.Transactions[0] != pooled.ID() ||
+		len(event.RelatedTransactions) != 1 || event.RelatedTransactions[0] != doubleSpend.ID() ||
+		len(event.Outputs) != 2 {
+		t.Fatal("unexpected conflict event:", event)
+	}
+}
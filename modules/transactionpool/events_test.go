@@ -0,0 +1,53 @@
+package transactionpool
+
+import (
+	"testing"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// TestMembershipEvents ensures the transactions which got added to, removed from
+// or confirmed out of the pool are reported as such, exactly once.
+func TestMembershipEvents(t *testing.T) {
+	txn := func(id byte) types.Transaction {
+		return types.Transaction{
+			Version:    types.TransactionVersionOne,
+			CoinInputs: []types.CoinInput{{ParentID: types.CoinOutputID{id}}},
+		}
+	}
+	a, b, c := txn(1), txn(2), txn(3)
+	tp := new(TransactionPool)
+
+	events := tp.membershipEvents([]types.Transaction{a, b, c})
+	if len(events) != 1 || events[0].Type != modules.TransactionPoolEventAdded || len(events[0].Transactions) != 3 {
+		t.Fatal("unexpected events:", events)
+	}
+	if events := tp.membershipEvents([]types.Transaction{a, b, c}); len(events) != 0 {
+		t.Fatal("an unchanged pool should not result in events:", events)
+	}
+
+	// a got confirmed, b dropped and d added
+	d := txn(4)
+	tp.confirmedTransactions = map[types.TransactionID]struct{}{a.ID(): {}}
+	events = tp.membershipEvents([]types.Transaction{c, d})
+	if len(events) != 3 {
+		t.Fatal("unexpected events:", events)
+	}
+	expected := []struct {
+		Type modules.TransactionPoolEventType
+		ID   types.TransactionID
+	}{
+		{modules.TransactionPoolEventConfirmed, a.ID()},
+		{modules.TransactionPoolEventRemoved, b.ID()},
+		{modules.TransactionPoolEventAdded, d.ID()},
+	}
+	for i, e := range expected {
+		if events[i].Type != e.Type || len(events[i].Transactions) != 1 || events[i].Transactions[0] != e.ID {
+			t.Errorf("unexpected %s event: %v", e.Type, events[i])
+		}
+	}
+	if tp.confirmedTransactions != nil {
+		t.Error("confirmed transactions should be cleared after an update")
+	}
+}
@@ -0,0 +1,118 @@
+package transactionpool
+
+import (
+	"sort"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+	"github.com/threefoldtech/rivine/types"
+)
+
+type (
+	// pooledSet identifies a pooled transaction set,
+	// together with its total fee and size.
+	pooledSet struct {
+		id    TransactionSetID
+		fee   types.Currency
+		size  int
+		count int
+	}
+)
+
+// lowerFeeDensity returns true if a fee of feeA for sizeA bytes
+// is a lower fee-per-byte than a fee of feeB for sizeB bytes.
+func lowerFeeDensity(feeA types.Currency, sizeA int, feeB types.Currency, sizeB int) bool {
+	return feeA.Mul64(uint64(sizeB)).Cmp(feeB.Mul64(uint64(sizeA))) < 0
+}
+
+// selectEvictions selects the pooled transaction sets paying the lowest fee-per-byte, which have to be evicted
+// for the given transaction set (with the given fee and size) to fit within the pool size and count limits.
+// Only transaction sets paying a lower fee-per-byte than the given transaction set are selected,
+// priority transaction sets are never selected. Nothing is evicted yet, such that the selected
+// transaction sets can be evicted, using evictTransactionSets, once the given transaction set is known to be valid.
+// errFullTransactionPool is returned in case not enough room can be made.
+func (tp *TransactionPool) selectEvictions(ts []types.Transaction, fee types.Currency, size int) ([]TransactionSetID, error) {
+	// transactions of the given set might already be pooled (e.g. as the parents of
+	// a set that is merged with its pooled conflicts), these should never be evicted
+	txids := make(map[types.TransactionID]struct{}, len(ts))
+	for _, txn := range ts {
+		txids[txn.ID()] = struct{}{}
+	}
+
+	var candidates []pooledSet
+	for id, set := range tp.transactionSets {
+		if _, ok := txids[set[0].ID()]; ok {
+			continue
+		}
+		if tp.priority.containsAny(set) {
+			continue
+		}
+		candidate := pooledSet{
+			id:    id,
+			fee:   transactionSetFee(set),
+			size:  len(siabin.Marshal(set)),
+			count: len(set),
+		}
+		if lowerFeeDensity(candidate.fee, candidate.size, fee, size) {
+			candidates = append(candidates, candidate)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return lowerFeeDensity(candidates[i].fee, candidates[i].size, candidates[j].fee, candidates[j].size)
+	})
+
+	listSize, count := tp.transactionListSize, tp.transactionCount()
+	full := func() bool {
+		return listSize+size > tp.chainCts.TransactionPool.PoolSizeLimit ||
+			!tp.withinCountLimit(count+len(ts))
+	}
+	var evict []TransactionSetID
+	for _, candidate := range candidates {
+		if !full() {
+			break
+		}
+		evict = append(evict, candidate.id)
+		listSize -= candidate.size
+		count -= candidate.count
+	}
+	if full() {
+		return nil, errFullTransactionPool
+	}
+	return evict, nil
+}
+
+// evictTransactionSets evicts the given pooled transaction sets, as selected by selectEvictions,
+// in order to make room for the given transaction set, notifying subscribers of the evicted transactions.
+func (tp *TransactionPool) evictTransactionSets(ids []TransactionSetID, ts []types.Transaction) {
+	if len(ids) == 0 {
+		return
+	}
+	event := modules.TransactionPoolEvent{
+		Type: modules.TransactionPoolEventEvicted,
+	}
+	for _, id := range ids {
+		for _, txn := range tp.transactionSets[id] {
+			event.Transactions = append(event.Transactions, txn.ID())
+		}
+		tp.removeTransactionSet(id)
+	}
+	for _, txn := range ts {
+		event.RelatedTransactions = append(event.RelatedTransactions, txn.ID())
+	}
+	tp.pendingEvents = append(tp.pendingEvents, event)
+}
+
+// transactionCount returns the amount of pooled transactions.
+func (tp *TransactionPool) transactionCount() (count int) {
+	for _, set := range tp.transactionSets {
+		count += len(set)
+	}
+	return
+}
+
+// withinCountLimit returns true if the given amount of transactions
+// does not exceed the pool transaction count limit, if any.
+func (tp *TransactionPool) withinCountLimit(count int) bool {
+	limit := tp.chainCts.TransactionPool.PoolTransactionCountLimit
+	return limit <= 0 || count <= limit
+}
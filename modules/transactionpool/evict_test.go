@@ -0,0 +1,263 @@
+package transactionpool
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/threefoldtech/rivine/build"
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// stubConsensusSet is a consensus set of which the unspent coin outputs and height are given,
+// only implementing the methods used to accept transaction sets.
+type stubConsensusSet struct {
+	modules.ConsensusSet
+	outputs map[types.CoinOutputID]types.CoinOutput
+	height  types.BlockHeight
+}
+
+func (cs *stubConsensusSet) ConsensusSetSubscribe(modules.ConsensusSetSubscriber, modules.ConsensusChangeID, <-chan struct{}) error {
+	return nil
+}
+
+func (cs *stubConsensusSet) Height() types.BlockHeight { return cs.height }
+
+func (cs *stubConsensusSet) BlockAtHeight(types.BlockHeight) (types.Block, bool) {
+	return types.Block{}, true
+}
+
+func (cs *stubConsensusSet) GetCoinOutput(id types.CoinOutputID) (types.CoinOutput, error) {
+	co, ok := cs.outputs[id]
+	if !ok {
+		return types.CoinOutput{}, errors.New("unknown coin output")
+	}
+	return co, nil
+}
+
+func (cs *stubConsensusSet) GetBlockStakeOutput(types.BlockStakeOutputID) (types.BlockStakeOutput, error) {
+	return types.BlockStakeOutput{}, errors.New("unknown block stake output")
+}
+
+// TryTransactionSet only checks that the transactions spend unspent coin outputs,
+// or coin outputs created earlier in the set, and that the value of their inputs and outputs match.
+func (cs *stubConsensusSet) TryTransactionSet(ts []types.Transaction) (modules.ConsensusChange, error) {
+	created := make(map[types.CoinOutputID]types.CoinOutput)
+	for _, txn := range ts {
+		var inputs types.Currency
+		for _, ci := range txn.CoinInputs {
+			co, ok := cs.outputs[ci.ParentID]
+			if !ok {
+				co, ok = created[ci.ParentID]
+			}
+			if !ok {
+				return modules.ConsensusChange{}, types.MissingCoinOutputError{ID: ci.ParentID}
+			}
+			inputs = inputs.Add(co.Value)
+		}
+		if !inputs.Equals(txn.CoinOutputSum()) {
+			return modules.ConsensusChange{}, types.ErrCoinInputOutputMismatch
+		}
+		for i, co := range txn.CoinOutputs {
+			created[txn.CoinOutputID(uint64(i))] = co
+		}
+	}
+	return modules.ConsensusChange{}, nil
+}
+
+// newStubTransactionPool creates a transaction pool on top of the given stub consensus set.
+func newStubTransactionPool(t *testing.T, cs *stubConsensusSet) *TransactionPool {
+	tp := &TransactionPool{
+		consensusSet:        cs,
+		knownObjects:        make(map[ObjectID]TransactionSetID),
+		transactionSets:     make(map[TransactionSetID][]types.Transaction),
+		transactionSetDiffs: make(map[TransactionSetID]modules.ConsensusChange),
+		broadcastCache:      newTransactionCache(),
+		ages:                make(transactionAges),
+		orphans:             newOrphanPool(),
+		underpaid:           newOrphanPool(),
+		local:               make(localTransactions),
+		priority:            make(localTransactions),
+		sources:             make(transactionSources),
+		persistDir:          build.TempDir(modules.TransactionPoolDir, t.Name()),
+		chainCts:            types.DevnetChainConstants(),
+	}
+	tp.chainCts.TransactionPool = types.DefaultTransactionPoolConstants()
+	os.RemoveAll(tp.persistDir)
+	if err := tp.initPersist(); err != nil {
+		t.Fatal(err)
+	}
+	return tp
+}
+
+// newStubTransaction creates a transaction spending the given coin output,
+// paying the given fee, with a fulfillment that is standard, but not valid.
+func newStubTransaction(parentID types.CoinOutputID, fee types.Currency) types.Transaction {
+	return types.Transaction{
+		Version: types.TransactionVersionOne,
+		CoinInputs: []types.CoinInput{{
+			ParentID: parentID,
+			Fulfillment: types.NewFulfillment(&types.SingleSignatureFulfillment{
+				PublicKey: types.PublicKey{Algorithm: types.SignatureAlgoEd25519, Key: make([]byte, crypto.PublicKeySize)},
+				Signature: make([]byte, crypto.SignatureSize),
+			}),
+		}},
+		MinerFees: []types.Currency{fee},
+	}
+}
+
+// TestEvictTransactionSets ensures only the pooled transaction sets paying
+// the lowest fee-per-byte are evicted, and only when it makes enough room.
+func TestEvictTransactionSets(t *testing.T) {
+	newSet := func(id byte, fee uint64) []types.Transaction {
+		return []types.Transaction{{
+			CoinInputs: []types.CoinInput{{ParentID: types.CoinOutputID{id}}},
+			MinerFees:  []types.Currency{types.NewCurrency64(fee)},
+		}}
+	}
+	low, mid := newSet(1, 10), newSet(2, 20)
+	setSize := len(siabin.Marshal(low))
+
+	tp := &TransactionPool{
+		knownObjects: make(map[ObjectID]TransactionSetID),
+		transactionSets: map[TransactionSetID][]types.Transaction{
+			{1}: low,
+			{2}: mid,
+		},
+		transactionSetDiffs: make(map[TransactionSetID]modules.ConsensusChange),
+		broadcastCache:      newTransactionCache(),
+		transactionListSize: 2 * setSize,
+	}
+	tp.chainCts.TransactionPool.PoolSizeLimit = 2 * setSize
+
+	// a set paying less than all pooled sets cannot make room
+	cheap := newSet(3, 5)
+	_, err := tp.selectEvictions(cheap, types.NewCurrency64(5), setSize)
+	if err != errFullTransactionPool {
+		t.Fatal("expected full transaction pool error, got:", err)
+	}
+	if len(tp.transactionSets) != 2 {
+		t.Fatal("no transaction sets should have been evicted")
+	}
+
+	// a set paying more than one pooled set evicts only that set
+	rich := newSet(4, 15)
+	ids, err := tp.selectEvictions(rich, types.NewCurrency64(15), setSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tp.transactionSets) != 2 || len(tp.pendingEvents) != 0 {
+		t.Fatal("selected transaction sets should only be evicted once the set is accepted")
+	}
+	tp.evictTransactionSets(ids, rich)
+	if _, ok := tp.transactionSets[TransactionSetID{1}]; ok {
+		t.Fatal("lowest paying transaction set should have been evicted")
+	}
+	if _, ok := tp.transactionSets[TransactionSetID{2}]; !ok {
+		t.Fatal("highest paying transaction set should not have been evicted")
+	}
+	if tp.transactionListSize != setSize {
+		t.Fatal("unexpected transaction list size:", tp.transactionListSize)
+	}
+	if len(tp.pendingEvents) != 1 || len(tp.pendingEvents[0].Transactions) != 1 {
+		t.Fatal("expected a single eviction event, got:", tp.pendingEvents)
+	}
+}
+
+// TestEvictTransactionSetsCountLimit ensures transaction sets are
+// evicted as well in order to respect the transaction count limit.
+func TestEvictTransactionSetsCountLimit(t *testing.T) {
+	newSet := func(id byte, fee uint64) []types.Transaction {
+		return []types.Transaction{{
+			CoinInputs: []types.CoinInput{{ParentID: types.CoinOutputID{id}}},
+			MinerFees:  []types.Currency{types.NewCurrency64(fee)},
+		}}
+	}
+	low := newSet(1, 10)
+	setSize := len(siabin.Marshal(low))
+
+	tp := &TransactionPool{
+		knownObjects: make(map[ObjectID]TransactionSetID),
+		transactionSets: map[TransactionSetID][]types.Transaction{
+			{1}: low,
+		},
+		transactionSetDiffs: make(map[TransactionSetID]modules.ConsensusChange),
+		broadcastCache:      newTransactionCache(),
+		transactionListSize: setSize,
+	}
+	tp.chainCts.TransactionPool.PoolSizeLimit = 10 * setSize
+	tp.chainCts.TransactionPool.PoolTransactionCountLimit = 1
+
+	_, err := tp.selectEvictions(newSet(2, 5), types.NewCurrency64(5), setSize)
+	if err != errFullTransactionPool {
+		t.Fatal("expected full transaction pool error, got:", err)
+	}
+	ids, err := tp.selectEvictions(newSet(3, 20), types.NewCurrency64(20), setSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tp.evictTransactionSets(ids, newSet(3, 20))
+	if len(tp.transactionSets) != 0 {
+		t.Fatal("lowest paying transaction set should have been evicted")
+	}
+}
+
+// TestEvictOnlyForValidTransactionSets ensures that a full pool only evicts transaction sets
+// to make room for transaction sets which are accepted, such that orphan or invalid
+// transaction sets paying a high fee cannot be used to flush the pool.
+func TestEvictOnlyForValidTransactionSets(t *testing.T) {
+	coin := types.DevnetChainConstants().CurrencyUnits.OneCoin
+	cs := &stubConsensusSet{outputs: map[types.CoinOutputID]types.CoinOutput{
+		{1}: {Value: coin},
+		{2}: {Value: coin.Mul64(10)},
+		{3}: {Value: coin},
+	}}
+	tp := newStubTransactionPool(t, cs)
+	defer tp.db.Close()
+	tp.chainCts.TransactionPool.PoolTransactionCountLimit = 1
+
+	cheap := []types.Transaction{newStubTransaction(types.CoinOutputID{1}, coin)}
+	if err := tp.acceptTransactionSet(cheap); err != nil {
+		t.Fatal(err)
+	}
+	cheapID := TransactionSetID(crypto.HashObject(cheap))
+	pooled := func() bool {
+		_, ok := tp.transactionSets[cheapID]
+		return ok && len(tp.transactionSets) == 1 && len(tp.pendingEvents) == 0
+	}
+
+	// an orphan paying a higher fee is held, without evicting anything
+	orphan := []types.Transaction{newStubTransaction(types.CoinOutputID{9}, coin.Mul64(10))}
+	if err := tp.acceptTransactionSet(orphan); err != modules.ErrOrphanTransactionSet {
+		t.Fatal("expected orphan transaction set error, got:", err)
+	}
+	if !pooled() {
+		t.Fatal("no transaction set should have been evicted for an orphan")
+	}
+
+	// an invalid set paying a higher fee is rejected, without evicting anything
+	invalid := []types.Transaction{newStubTransaction(types.CoinOutputID{3}, coin.Mul64(10))}
+	if err := tp.acceptTransactionSet(invalid); err == nil {
+		t.Fatal("expected invalid transaction set to be rejected")
+	} else if _, ok := err.(modules.ConsensusConflict); !ok {
+		t.Fatal("expected consensus conflict, got:", err)
+	}
+	if !pooled() {
+		t.Fatal("no transaction set should have been evicted for an invalid transaction set")
+	}
+
+	// a valid set paying a higher fee evicts the cheap set
+	rich := []types.Transaction{newStubTransaction(types.CoinOutputID{2}, coin.Mul64(10))}
+	if err := tp.acceptTransactionSet(rich); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := tp.transactionSets[cheapID]; ok || len(tp.transactionSets) != 1 {
+		t.Fatal("cheap transaction set should have been evicted")
+	}
+	if len(tp.pendingEvents) != 1 || tp.pendingEvents[0].Type != modules.TransactionPoolEventEvicted {
+		t.Fatal("expected a single eviction event, got:", tp.pendingEvents)
+	}
+}
@@ -0,0 +1,91 @@
+package transactionpool
+
+import (
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// transactionAges tracks the height at which each pooled transaction was added.
+// Ages are tracked by transaction ID rather than by transaction set,
+// as pooled transaction sets get merged with their children, and are
+// re-accepted (possibly partially) after each consensus change.
+type transactionAges map[types.TransactionID]types.BlockHeight
+
+// add the transactions of the given transaction set,
+// which were not tracked yet, as added at the given height.
+func (ta transactionAges) add(ts []types.Transaction, height types.BlockHeight) {
+	for _, txn := range ts {
+		id := txn.ID()
+		if _, ok := ta[id]; !ok {
+			ta[id] = height
+		}
+	}
+}
+
+// remove the transactions of the given transaction set.
+func (ta transactionAges) remove(ts []types.Transaction) {
+	for _, txn := range ts {
+		delete(ta, txn.ID())
+	}
+}
+
+// oldest returns the height at which the oldest transaction
+// of the given transaction set was added, if any is tracked.
+func (ta transactionAges) oldest(ts []types.Transaction) (types.BlockHeight, bool) {
+	var (
+		oldest types.BlockHeight
+		found  bool
+	)
+	for _, txn := range ts {
+		height, ok := ta[txn.ID()]
+		if ok && (!found || height < oldest) {
+			oldest, found = height, true
+		}
+	}
+	return oldest, found
+}
+
+// prune forgets all transactions which are not part of the given (pooled) transaction sets,
+// as they got confirmed or dropped from the pool.
+func (ta transactionAges) prune(pooled map[TransactionSetID][]types.Transaction) {
+	if len(ta) == 0 {
+		return
+	}
+	ids := make(map[types.TransactionID]struct{})
+	for _, set := range pooled {
+		for _, txn := range set {
+			ids[txn.ID()] = struct{}{}
+		}
+	}
+	for id := range ta {
+		if _, ok := ids[id]; !ok {
+			delete(ta, id)
+		}
+	}
+}
+
+// expireTransactionSets drops all transaction sets of which the oldest transaction
+// was added at least MaxTransactionAge blocks prior to the given height,
+// notifying subscribers of the expired transactions.
+func (tp *TransactionPool) expireTransactionSets(height types.BlockHeight) {
+	maxAge := tp.chainCts.TransactionPool.MaxTransactionAge
+	if maxAge == 0 {
+		return // transactions never expire
+	}
+	event := modules.TransactionPoolEvent{
+		Type: modules.TransactionPoolEventExpired,
+	}
+	for id, set := range tp.transactionSets {
+		added, ok := tp.ages.oldest(set)
+		if !ok || added+maxAge > height {
+			continue
+		}
+		for _, txn := range set {
+			event.Transactions = append(event.Transactions, txn.ID())
+		}
+		tp.removeTransactionSet(id)
+	}
+	if len(event.Transactions) > 0 {
+		tp.pendingEvents = append(tp.pendingEvents, event)
+	}
+}
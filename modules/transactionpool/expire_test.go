@@ -0,0 +1,90 @@
+package transactionpool
+
+import (
+	"testing"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// TestExpireTransactionSets ensures only transaction sets which
+// exceeded the maximum transaction age are dropped.
+func TestExpireTransactionSets(t *testing.T) {
+	tp := &TransactionPool{
+		knownObjects: make(map[ObjectID]TransactionSetID),
+		transactionSets: map[TransactionSetID][]types.Transaction{
+			{1}: {{ArbitraryData: []byte{1}}},
+			{2}: {{ArbitraryData: []byte{2}}},
+		},
+		transactionSetDiffs: make(map[TransactionSetID]modules.ConsensusChange),
+		broadcastCache:      newTransactionCache(),
+		ages:                make(transactionAges),
+	}
+	tp.ages.add(tp.transactionSets[TransactionSetID{1}], 10)
+	tp.ages.add(tp.transactionSets[TransactionSetID{2}], 20)
+	tp.chainCts.TransactionPool.MaxTransactionAge = 10
+
+	tp.expireTransactionSets(19)
+	if len(tp.transactionSets) != 2 || len(tp.pendingEvents) != 0 {
+		t.Fatal("no transaction sets should have expired yet")
+	}
+	tp.expireTransactionSets(20)
+	if _, ok := tp.transactionSets[TransactionSetID{1}]; ok || len(tp.transactionSets) != 1 {
+		t.Fatal("oldest transaction set should have expired")
+	}
+	if len(tp.pendingEvents) != 1 || tp.pendingEvents[0].Type != modules.TransactionPoolEventExpired {
+		t.Fatal("expected a single expiry event, got:", tp.pendingEvents)
+	}
+
+	// transaction sets never expire if no maximum age is defined
+	tp.chainCts.TransactionPool.MaxTransactionAge = 0
+	tp.expireTransactionSets(100)
+	if len(tp.transactionSets) != 1 {
+		t.Fatal("transaction sets should not expire without a maximum age")
+	}
+}
+
+// TestExpireMergedTransactionSet ensures a transaction set, merged with a child
+// into a new transaction set, expires once its oldest transaction exceeded the maximum age.
+func TestExpireMergedTransactionSet(t *testing.T) {
+	coin := types.DevnetChainConstants().CurrencyUnits.OneCoin
+	cs := &stubConsensusSet{outputs: map[types.CoinOutputID]types.CoinOutput{
+		{1}: {Value: coin.Mul64(2)},
+	}}
+	tp := newStubTransactionPool(t, cs)
+	defer tp.db.Close()
+	tp.chainCts.TransactionPool.MaxTransactionAge = 10
+
+	parent := newStubTransaction(types.CoinOutputID{1}, coin)
+	parent.CoinOutputs = []types.CoinOutput{{Value: coin}}
+	if err := tp.acceptTransactionSet([]types.Transaction{parent}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the child is merged with its parent into a new transaction set
+	cs.height = 5
+	child := newStubTransaction(parent.CoinOutputID(0), coin)
+	if err := tp.acceptTransactionSet([]types.Transaction{child}); err != nil {
+		t.Fatal(err)
+	}
+	merged := TransactionSetID(crypto.HashObject([]types.Transaction{parent, child}))
+	if _, ok := tp.transactionSets[merged]; !ok || len(tp.transactionSets) != 1 {
+		t.Fatal("expected the parent and child to be merged into a single transaction set")
+	}
+
+	tp.expireTransactionSets(9)
+	if len(tp.transactionSets) != 1 || len(tp.pendingEvents) != 0 {
+		t.Fatal("the merged transaction set should not have expired yet")
+	}
+	tp.expireTransactionSets(10)
+	if len(tp.transactionSets) != 0 {
+		t.Fatal("the merged transaction set should have expired together with its parent")
+	}
+	if len(tp.pendingEvents) != 1 || len(tp.pendingEvents[0].Transactions) != 2 {
+		t.Fatal("expected a single expiry event for both transactions, got:", tp.pendingEvents)
+	}
+	if len(tp.ages) != 0 {
+		t.Fatal("the ages of the expired transactions should be forgotten, got:", tp.ages)
+	}
+}
@@ -0,0 +1,55 @@
+package transactionpool
+
+import (
+	"sort"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// feeEstimate estimates the fee-per-byte required to be included within the given amount of blocks,
+// by filling that many blocks of the given size with the given transaction sets, sorted by decreasing fee-per-byte,
+// in the same way as they are selected for a new block. If the transaction sets do not fit,
+// the estimate outbids the first transaction set that does not fit. The estimate is never lower than the given minimum.
+func feeEstimate(sets []pooledSet, blocks, blockSize uint64, minimum types.Currency) types.Currency {
+	capacity := blocks * blockSize
+	var size uint64
+	for _, set := range sets {
+		size += uint64(set.size)
+		if size <= capacity {
+			continue
+		}
+		estimate := set.fee.Div64(uint64(set.size)).Add(types.NewCurrency64(1))
+		if estimate.Cmp(minimum) < 0 {
+			return minimum
+		}
+		return estimate
+	}
+	return minimum
+}
+
+// FeeEstimate implements TransactionPool.FeeEstimate
+func (tp *TransactionPool) FeeEstimate() modules.FeeEstimate {
+	tp.mu.RLock()
+	sets := make([]pooledSet, 0, len(tp.transactionSets))
+	for id, set := range tp.transactionSets {
+		sets = append(sets, pooledSet{
+			id:    id,
+			fee:   transactionSetFee(set),
+			size:  len(siabin.Marshal(set)),
+			count: len(set),
+		})
+	}
+	tp.mu.RUnlock()
+
+	sort.Slice(sets, func(i, j int) bool {
+		return lowerFeeDensity(sets[j].fee, sets[j].size, sets[i].fee, sets[i].size)
+	})
+	blockSize, minimum := tp.chainCts.BlockSizeLimit, tp.chainCts.TransactionPool.MinimumFeePerByte
+	return modules.FeeEstimate{
+		Low:    feeEstimate(sets, modules.FeeEstimateBlocksLow, blockSize, minimum),
+		Normal: feeEstimate(sets, modules.FeeEstimateBlocksNormal, blockSize, minimum),
+		High:   feeEstimate(sets, modules.FeeEstimateBlocksHigh, blockSize, minimum),
+	}
+}
@@ -0,0 +1,43 @@
+package transactionpool
+
+import (
+	"testing"
+
+	"github.com/threefoldtech/rivine/types"
+)
+
+// TestFeeEstimate probes the fee estimation for the different block targets.
+func TestFeeEstimate(t *testing.T) {
+	minimum := types.NewCurrency64(1)
+	if estimate := feeEstimate(nil, 1, 10e3, minimum); !estimate.Equals(minimum) {
+		t.Fatal("expected the minimum for an empty pool, got:", estimate)
+	}
+
+	// sorted by decreasing fee-per-byte, as done by TransactionPool.FeeEstimate
+	sets := []pooledSet{
+		{fee: types.NewCurrency64(120e3), size: 6e3}, // 20/byte
+		{fee: types.NewCurrency64(40e3), size: 4e3},  // 10/byte
+		{fee: types.NewCurrency64(25e3), size: 5e3},  // 5/byte
+		{fee: types.NewCurrency64(1e3), size: 1e3},   // 1/byte
+	}
+	testCases := []struct {
+		Blocks   uint64
+		Minimum  uint64
+		Expected uint64
+	}{
+		// the 20/byte and 10/byte sets fill the first block exactly
+		{1, 1, 6},
+		// all sets fit within two blocks
+		{2, 1, 1},
+		{3, 3, 3},
+		// the estimate is never lower than the minimum
+		{1, 8, 8},
+	}
+	for _, testCase := range testCases {
+		estimate := feeEstimate(sets, testCase.Blocks, 10e3, types.NewCurrency64(testCase.Minimum))
+		if !estimate.Equals64(testCase.Expected) {
+			t.Errorf("%d block(s) with minimum %d: expected %d, got %v",
+				testCase.Blocks, testCase.Minimum, testCase.Expected, estimate)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package transactionpool
+
+import (
+	"sort"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+)
+
+const (
+	// feeHistogramBinSize is the minimum size, in bytes, of the first fee histogram bin,
+	// the minimum size of every next bin grows with feeHistogramBinGrowth percent.
+	feeHistogramBinSize   = 10e3
+	feeHistogramBinGrowth = 10
+)
+
+// feeHistogram computes a histogram of the fee-per-byte paid by the given transaction sets,
+// sorted by decreasing fee-per-byte. Transaction sets are accumulated in a bin until its
+// minimum size is reached, using the same algorithm as Electrum(X) servers,
+// which keeps the histogram compact, while being the most precise for the highest fees.
+func feeHistogram(sets []pooledSet) []modules.FeeHistogramEntry {
+	sort.Slice(sets, func(i, j int) bool {
+		return lowerFeeDensity(sets[j].fee, sets[j].size, sets[i].fee, sets[i].size)
+	})
+	var (
+		histogram []modules.FeeHistogramEntry
+		binSize   uint64
+		minSize   uint64 = feeHistogramBinSize
+	)
+	for i, set := range sets {
+		binSize += uint64(set.size)
+		if binSize < minSize && i < len(sets)-1 {
+			continue
+		}
+		histogram = append(histogram, modules.FeeHistogramEntry{
+			FeePerByte: set.fee.Div64(uint64(set.size)),
+			Size:       binSize,
+		})
+		binSize = 0
+		minSize += minSize * feeHistogramBinGrowth / 100
+	}
+	return histogram
+}
+
+// FeeHistogram implements TransactionPool.FeeHistogram
+func (tp *TransactionPool) FeeHistogram() []modules.FeeHistogramEntry {
+	tp.mu.RLock()
+	defer tp.mu.RUnlock()
+	sets := make([]pooledSet, 0, len(tp.transactionSets))
+	for id, set := range tp.transactionSets {
+		sets = append(sets, pooledSet{
+			id:    id,
+			fee:   transactionSetFee(set),
+			size:  len(siabin.Marshal(set)),
+			count: len(set),
+		})
+	}
+	return feeHistogram(sets)
+}
@@ -0,0 +1,58 @@
+package transactionpool
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// TestFeeHistogram probes the binning of the fee histogram.
+func TestFeeHistogram(t *testing.T) {
+	histogram := feeHistogram(nil)
+	if len(histogram) != 0 {
+		t.Fatal("expected an empty histogram, got:", histogram)
+	}
+
+	sets := []pooledSet{
+		{fee: types.NewCurrency64(1e3), size: 1e3},    // 1/byte
+		{fee: types.NewCurrency64(30e3), size: 6e3},   // 5/byte
+		{fee: types.NewCurrency64(40e3), size: 4e3},   // 10/byte
+		{fee: types.NewCurrency64(24e3), size: 12e3},  // 2/byte
+		{fee: types.NewCurrency64(300e3), size: 15e3}, // 20/byte
+	}
+	histogram = feeHistogram(sets)
+	expected := []modules.FeeHistogramEntry{
+		{FeePerByte: types.NewCurrency64(20), Size: 15e3},
+		// second bin requires at least 11e3 bytes, and holds the 10/byte, 5/byte and 2/byte sets
+		{FeePerByte: types.NewCurrency64(2), Size: 22e3},
+		// the last bin does not have to reach its minimum size
+		{FeePerByte: types.NewCurrency64(1), Size: 1e3},
+	}
+	if len(histogram) != len(expected) {
+		t.Fatalf("expected %d bins, got: %v", len(expected), histogram)
+	}
+	for i := range expected {
+		if !histogram[i].FeePerByte.Equals(expected[i].FeePerByte) || histogram[i].Size != expected[i].Size {
+			t.Errorf("bin #%d: expected %v, got %v", i, expected[i], histogram[i])
+		}
+	}
+
+	// bins are JSON-encoded as pairs
+	b, err := json.Marshal(histogram[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `[["20",15000]]` {
+		t.Fatal("unexpected JSON-encoded histogram:", string(b))
+	}
+	var decoded []modules.FeeHistogramEntry
+	err = json.Unmarshal(b, &decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded) != 1 || !decoded[0].FeePerByte.Equals64(20) || decoded[0].Size != 15e3 {
+		t.Fatal("unexpected JSON-decoded histogram:", decoded)
+	}
+}
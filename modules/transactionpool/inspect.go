@@ -0,0 +1,30 @@
+package transactionpool
+
+import (
+	"bytes"
+
+	"github.com/threefoldtech/rivine/modules"
+
+	"github.com/rivine/bbolt"
+)
+
+// InspectDatabase implements modules.DatabaseInspector.InspectDatabase,
+// calling the given function within a read transaction.
+func (tp *TransactionPool) InspectDatabase(fn func(*bolt.Tx) error) error {
+	return tp.db.View(fn)
+}
+
+// DecodeDatabaseValue implements modules.DatabaseInspector.DecodeDatabaseValue,
+// decoding the most recent consensus change, the confirmed transactions and spent outputs have no value.
+func (tp *TransactionPool) DecodeDatabaseValue(bucket [][]byte, key, value []byte) (interface{}, bool) {
+	if len(bucket) != 1 || !bytes.Equal(bucket[0], bucketRecentConsensusChange) ||
+		!bytes.Equal(key, fieldRecentConsensusChange) || len(value) != len(modules.ConsensusChangeID{}) {
+		return nil, false
+	}
+	var cc modules.ConsensusChangeID
+	copy(cc[:], value)
+	return cc, true
+}
+
+// enforce that the database of the TransactionPool can be inspected while running
+var _ modules.DatabaseInspector = (*TransactionPool)(nil)
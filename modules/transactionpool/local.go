@@ -0,0 +1,92 @@
+package transactionpool
+
+import (
+	"github.com/threefoldtech/rivine/types"
+)
+
+// localTransactions tracks the IDs of the transactions submitted through this node.
+// Transactions are tracked by ID rather than by transaction set,
+// as the transaction set a pooled transaction is part of can change over time.
+type localTransactions map[types.TransactionID]struct{}
+
+// add all transactions of the given transaction set.
+func (lt localTransactions) add(ts []types.Transaction) {
+	for _, txn := range ts {
+		lt[txn.ID()] = struct{}{}
+	}
+}
+
+// prune forgets all local transactions which are not part of the given (pooled) transaction sets,
+// as they got confirmed or dropped from the pool.
+func (lt localTransactions) prune(pooled map[TransactionSetID][]types.Transaction) {
+	if len(lt) == 0 {
+		return
+	}
+	ids := make(map[types.TransactionID]struct{})
+	for _, set := range pooled {
+		for _, txn := range set {
+			ids[txn.ID()] = struct{}{}
+		}
+	}
+	for id := range lt {
+		if _, ok := ids[id]; !ok {
+			delete(lt, id)
+		}
+	}
+}
+
+// sets returns the IDs of the given (pooled) transaction sets
+// which contain at least one local transaction.
+func (lt localTransactions) sets(pooled map[TransactionSetID][]types.Transaction) []TransactionSetID {
+	if len(lt) == 0 {
+		return nil
+	}
+	var ids []TransactionSetID
+	for id, set := range pooled {
+		for _, txn := range set {
+			if _, ok := lt[txn.ID()]; ok {
+				ids = append(ids, id)
+				break
+			}
+		}
+	}
+	return ids
+}
+
+// mark adds all transactions of the given transaction set,
+// returning the IDs of the transactions which were not tracked yet.
+func (lt localTransactions) mark(ts []types.Transaction) []types.TransactionID {
+	var ids []types.TransactionID
+	for _, txn := range ts {
+		id := txn.ID()
+		if _, ok := lt[id]; ok {
+			continue
+		}
+		lt[id] = struct{}{}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// containsAny returns true if at least one transaction of the given transaction set is tracked.
+func (lt localTransactions) containsAny(ts []types.Transaction) bool {
+	for _, txn := range ts {
+		if _, ok := lt[txn.ID()]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// containsAll returns true if all transactions of the given (non-empty) transaction set are tracked.
+func (lt localTransactions) containsAll(ts []types.Transaction) bool {
+	if len(lt) == 0 || len(ts) == 0 {
+		return false
+	}
+	for _, txn := range ts {
+		if _, ok := lt[txn.ID()]; !ok {
+			return false
+		}
+	}
+	return true
+}
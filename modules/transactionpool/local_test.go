@@ -0,0 +1,97 @@
+package transactionpool
+
+import (
+	"testing"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// TestLocalTransactions ensures local transactions are tracked
+// for as long as they are part of the pooled transaction sets.
+func TestLocalTransactions(t *testing.T) {
+	newTxn := func(fee uint64) types.Transaction {
+		return types.Transaction{
+			Version:   types.TransactionVersionOne,
+			MinerFees: []types.Currency{types.NewCurrency64(fee)},
+		}
+	}
+	local, remote := newTxn(1), newTxn(2)
+	pooled := map[TransactionSetID][]types.Transaction{
+		{1}: {local},
+		{2}: {remote},
+	}
+
+	lt := make(localTransactions)
+	if ids := lt.sets(pooled); len(ids) != 0 {
+		t.Fatal("no local transaction sets expected, got:", ids)
+	}
+	lt.add([]types.Transaction{local})
+	if ids := lt.sets(pooled); len(ids) != 1 || ids[0] != (TransactionSetID{1}) {
+		t.Fatal("unexpected local transaction sets:", ids)
+	}
+
+	// local transactions remain tracked when their set changes
+	pooled = map[TransactionSetID][]types.Transaction{
+		{3}: {remote, local},
+	}
+	lt.prune(pooled)
+	if ids := lt.sets(pooled); len(ids) != 1 || ids[0] != (TransactionSetID{3}) {
+		t.Fatal("unexpected local transaction sets:", ids)
+	}
+
+	// local transactions are forgotten once they are no longer pooled
+	pooled = map[TransactionSetID][]types.Transaction{
+		{2}: {remote},
+	}
+	lt.prune(pooled)
+	if len(lt) != 0 {
+		t.Fatal("local transaction should have been forgotten")
+	}
+}
+
+// TestPriorityTransactions ensures only transaction sets consisting out of priority
+// transactions have priority, and that those are never evicted from a full pool.
+func TestPriorityTransactions(t *testing.T) {
+	newSet := func(id byte, fee uint64) []types.Transaction {
+		return []types.Transaction{{
+			Version:    types.TransactionVersionOne,
+			CoinInputs: []types.CoinInput{{ParentID: types.CoinOutputID{id}}},
+			MinerFees:  []types.Currency{types.NewCurrency64(fee)},
+		}}
+	}
+	cheap, rich := newSet(1, 5), newSet(2, 50)
+	setSize := len(siabin.Marshal(cheap))
+
+	tp := &TransactionPool{
+		knownObjects: make(map[ObjectID]TransactionSetID),
+		transactionSets: map[TransactionSetID][]types.Transaction{
+			{1}: cheap,
+		},
+		transactionSetDiffs: make(map[TransactionSetID]modules.ConsensusChange),
+		broadcastCache:      newTransactionCache(),
+		transactionListSize: setSize,
+		priority:            make(localTransactions),
+	}
+	tp.chainCts.TransactionPool.PoolSizeLimit = setSize
+
+	if ids := tp.priority.mark(cheap); len(ids) != 1 || ids[0] != cheap[0].ID() {
+		t.Fatal("unexpected marked transactions:", ids)
+	}
+	if ids := tp.priority.mark(cheap); len(ids) != 0 {
+		t.Fatal("transactions should only be marked once, got:", ids)
+	}
+	if !tp.priority.containsAll(cheap) || tp.priority.containsAll(append(cheap, rich...)) {
+		t.Fatal("only sets consisting out of priority transactions should have priority")
+	}
+
+	// the priority set cannot be evicted, even by a set paying a higher fee
+	_, err := tp.selectEvictions(rich, types.NewCurrency64(50), setSize)
+	if err != errFullTransactionPool {
+		t.Fatal("expected full transaction pool error, got:", err)
+	}
+	if _, ok := tp.transactionSets[TransactionSetID{1}]; !ok {
+		t.Fatal("priority transaction set should not have been evicted")
+	}
+}
@@ -0,0 +1,57 @@
+package transactionpool
+
+import (
+	"path/filepath"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/persist"
+
+	"github.com/rivine/bbolt"
+)
+
+// dbMigrations are the migrations of the transaction pool database,
+// a migration from the previous version has to be added
+// each time the version of dbMetadata is bumped.
+var dbMigrations = []persist.Migration{
+	{
+		From: "0.6.0", To: "0.7.0",
+		Description: "track the outputs spent by confirmed transactions, rescanning the blockchain",
+		Migrate:     migrateSpentOutputs,
+	},
+}
+
+// migrateSpentOutputs adds the spent outputs bucket,
+// and resets the confirmed transactions and recent consensus change,
+// such that the transaction pool rescans the blockchain in order to track
+// the outputs spent by all confirmed transactions.
+func migrateSpentOutputs(tx *bolt.Tx) error {
+	for _, bucket := range [][]byte{bucketConfirmedTransactions, bucketSpentOutputs} {
+		err := tx.DeleteBucket(bucket)
+		if err != nil && err != bolt.ErrBucketNotFound {
+			return err
+		}
+		_, err = tx.CreateBucket(bucket)
+		if err != nil {
+			return err
+		}
+	}
+	bucket, err := tx.CreateBucketIfNotExists(bucketRecentConsensusChange)
+	if err != nil {
+		return err
+	}
+	return bucket.Put(fieldRecentConsensusChange, modules.ConsensusChangeBeginning[:])
+}
+
+// MigrateDatabase migrates the transaction pool database, stored in the given persist directory,
+// to the version used by this transaction pool module, returning the applied migrations.
+// It has to be called prior to creating the transaction pool.
+func MigrateDatabase(persistDir string, opts persist.MigrationOptions) ([]persist.Migration, error) {
+	return persist.MigrateDatabase(dbMetadata, filepath.Join(persistDir, DatabaseFilename), dbMigrations, opts)
+}
+
+// CompactDatabase compacts the transaction pool database, stored in the given persist directory,
+// reclaiming the space of the pages freed over time.
+// It has to be called prior to creating the transaction pool.
+func CompactDatabase(persistDir string) (persist.DatabaseCompaction, error) {
+	return persist.CompactDatabase(filepath.Join(persistDir, DatabaseFilename))
+}
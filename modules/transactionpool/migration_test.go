@@ -0,0 +1,99 @@
+package transactionpool
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/threefoldtech/rivine/build"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/persist"
+
+	"github.com/rivine/bbolt"
+)
+
+// TestMigrateDatabaseSpentOutputs migrates a database created prior to the tracking of spent outputs,
+// ensuring that the transaction pool rescans the blockchain once migrated.
+func TestMigrateDatabaseSpentOutputs(t *testing.T) {
+	persistDir := build.TempDir(modules.TransactionPoolDir, t.Name())
+	os.RemoveAll(persistDir)
+	if err := os.MkdirAll(persistDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(persistDir, DatabaseFilename)
+
+	// create a database as it was persisted by the previous version
+	db, err := persist.OpenDatabase(persist.Metadata{Header: dbMetadata.Header, Version: "0.6.0"}, filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucket(bucketRecentConsensusChange)
+		if err != nil {
+			return err
+		}
+		err = bucket.Put(fieldRecentConsensusChange, []byte{1, 2, 3, 4})
+		if err != nil {
+			return err
+		}
+		bucket, err = tx.CreateBucket(bucketConfirmedTransactions)
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte{5, 6, 7, 8}, []byte{})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the old version is refused until the database is migrated
+	db, err = persist.OpenDatabase(dbMetadata, filename)
+	if err != persist.ErrBadVersion {
+		t.Fatal("expected bad version error, not:", err)
+	}
+
+	migrations, err := MigrateDatabase(persistDir, persist.MigrationOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) != 1 || migrations[0].To != dbMetadata.Version {
+		t.Fatal("unexpected migrations:", migrations)
+	}
+
+	db, err = persist.OpenDatabase(dbMetadata, filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.View(func(tx *bolt.Tx) error {
+		if tx.Bucket(bucketSpentOutputs) == nil {
+			t.Error("spent outputs bucket was not created")
+		}
+		if key, _ := tx.Bucket(bucketConfirmedTransactions).Cursor().First(); key != nil {
+			t.Error("confirmed transactions were not reset")
+		}
+		cc, err := (&TransactionPool{}).getRecentConsensusChange(tx)
+		if err != nil {
+			return err
+		}
+		if cc != modules.ConsensusChangeBeginning {
+			t.Error("recent consensus change was not reset:", cc)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// an up to date database requires no further migration
+	migrations, err = MigrateDatabase(persistDir, persist.MigrationOptions{})
+	if err != nil || len(migrations) != 0 {
+		t.Fatal("unexpected result for up to date database:", migrations, err)
+	}
+}
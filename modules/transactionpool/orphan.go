@@ -0,0 +1,165 @@
+package transactionpool
+
+import (
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+	"github.com/threefoldtech/rivine/types"
+
+	"github.com/rivine/bbolt"
+)
+
+type (
+	// orphanPool holds transaction sets which spend outputs that are not known yet,
+	// such that they can be accepted once their parents arrive in the pool or chain,
+	// rather than being rejected (only to be relayed again by other peers).
+	orphanPool struct {
+		sets map[TransactionSetID]*orphanSet
+		// order tracks the orphans in the order they were added,
+		// such that the oldest orphans can be dropped first
+		order []TransactionSetID
+		size  int
+	}
+
+	orphanSet struct {
+		transactions []types.Transaction
+		size         int
+		// height is the block height at which the orphan was added
+		height types.BlockHeight
+	}
+)
+
+func newOrphanPool() orphanPool {
+	return orphanPool{
+		sets: make(map[TransactionSetID]*orphanSet),
+	}
+}
+
+// add an orphan transaction set to the pool, dropping the oldest orphans
+// in case the pool would otherwise exceed the given size limit.
+// Nothing is done in case the orphan is already known or exceeds the size limit by itself.
+func (op *orphanPool) add(ts []types.Transaction, height types.BlockHeight, limit int) {
+	id := TransactionSetID(crypto.HashObject(ts))
+	if _, exists := op.sets[id]; exists {
+		return
+	}
+	size := len(siabin.Marshal(ts))
+	if size > limit {
+		return
+	}
+	for op.size+size > limit {
+		op.delete(op.order[0])
+	}
+	op.sets[id] = &orphanSet{
+		transactions: ts,
+		size:         size,
+		height:       height,
+	}
+	op.order = append(op.order, id)
+	op.size += size
+}
+
+// delete an orphan transaction set from the pool, if it exists.
+func (op *orphanPool) delete(id TransactionSetID) {
+	set, exists := op.sets[id]
+	if !exists {
+		return
+	}
+	op.size -= set.size
+	delete(op.sets, id)
+	for i := range op.order {
+		if op.order[i] == id {
+			op.order = append(op.order[:i], op.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// expire drops all orphans which were added more than
+// TransactionPoolOrphanLifetime blocks prior to the given height.
+func (op *orphanPool) expire(height types.BlockHeight) {
+	for _, id := range append([]TransactionSetID(nil), op.order...) {
+		if op.sets[id].height+modules.TransactionPoolOrphanLifetime < height {
+			op.delete(id)
+		}
+	}
+}
+
+// hasMissingParents returns true if the transaction set spends at least one output
+// which is created neither by the transaction set itself, nor by the pooled transactions,
+// nor is known as an unspent output by the consensus set. Outputs spent by confirmed
+// transactions are not missing, such that double spends of those are rejected
+// as consensus conflicts, rather than held as orphans.
+func (tp *TransactionPool) hasMissingParents(ts []types.Transaction) bool {
+	created := make(map[ObjectID]struct{})
+	for _, t := range ts {
+		for i := range t.CoinOutputs {
+			created[ObjectID(t.CoinOutputID(uint64(i)))] = struct{}{}
+		}
+		for i := range t.BlockStakeOutputs {
+			created[ObjectID(t.BlockStakeOutputID(uint64(i)))] = struct{}{}
+		}
+	}
+	known := func(oid ObjectID) bool {
+		if _, ok := created[oid]; ok {
+			return true
+		}
+		_, ok := tp.knownObjects[oid]
+		return ok
+	}
+
+	var missing bool
+	err := tp.db.View(func(tx *bolt.Tx) error {
+		for _, t := range ts {
+			for _, ci := range t.CoinInputs {
+				oid := ObjectID(ci.ParentID)
+				if known(oid) {
+					continue
+				}
+				if _, err := tp.consensusSet.GetCoinOutput(ci.ParentID); err != nil && !tp.outputSpent(tx, oid) {
+					missing = true
+					return nil
+				}
+			}
+			for _, bsi := range t.BlockStakeInputs {
+				oid := ObjectID(bsi.ParentID)
+				if known(oid) {
+					continue
+				}
+				if _, err := tp.consensusSet.GetBlockStakeOutput(bsi.ParentID); err != nil && !tp.outputSpent(tx, oid) {
+					missing = true
+					return nil
+				}
+			}
+		}
+		return nil
+	})
+	// the transaction set is validated by the consensus set in case
+	// the spent outputs cannot be looked up, rejecting it if it is missing parents
+	return err == nil && missing
+}
+
+// promoteOrphans accepts all orphan transaction sets for which all parents are known by now,
+// returning the transaction sets that were accepted. Orphans which are no longer missing parents
+// but which cannot be accepted, are dropped. Orphans which are still missing parents are kept.
+func (tp *TransactionPool) promoteOrphans() [][]types.Transaction {
+	var promoted [][]types.Transaction
+	// accepting an orphan might make other orphans acceptable,
+	// hence keep going until no more orphans can be promoted
+	for progress := true; progress; {
+		progress = false
+		for _, id := range append([]TransactionSetID(nil), tp.orphans.order...) {
+			set := tp.orphans.sets[id]
+			if tp.hasMissingParents(set.transactions) {
+				continue
+			}
+			tp.orphans.delete(id)
+			if err := tp.acceptTransactionSet(set.transactions); err != nil {
+				continue
+			}
+			promoted = append(promoted, set.transactions)
+			progress = true
+		}
+	}
+	return promoted
+}
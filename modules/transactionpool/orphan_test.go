@@ -0,0 +1,94 @@
+package transactionpool
+
+import (
+	"testing"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// TestOrphanPool probes the size limit and expiry of the orphan pool.
+func TestOrphanPool(t *testing.T) {
+	newSet := func(id byte) []types.Transaction {
+		return []types.Transaction{{
+			CoinInputs: []types.CoinInput{{ParentID: types.CoinOutputID{id}}},
+		}}
+	}
+	setSize := len(siabin.Marshal(newSet(1)))
+	limit := 2 * setSize
+
+	op := newOrphanPool()
+	op.add(newSet(1), 1, limit)
+	op.add(newSet(1), 1, limit) // duplicate
+	op.add(newSet(2), 2, limit)
+	if len(op.sets) != 2 || op.size != 2*setSize {
+		t.Fatalf("unexpected orphan pool: %d sets of %d bytes", len(op.sets), op.size)
+	}
+
+	// adding a third orphan drops the oldest one
+	op.add(newSet(3), 3, limit)
+	if len(op.sets) != 2 || op.size != 2*setSize {
+		t.Fatalf("unexpected orphan pool: %d sets of %d bytes", len(op.sets), op.size)
+	}
+	if _, ok := op.sets[TransactionSetID(crypto.HashObject(newSet(1)))]; ok {
+		t.Fatal("oldest orphan should have been dropped")
+	}
+
+	// orphans too big for the pool are ignored
+	op.add(newSet(4), 3, setSize-1)
+	if len(op.sets) != 2 {
+		t.Fatal("orphan exceeding the size limit should have been ignored")
+	}
+
+	// orphans expire after their lifetime
+	op.expire(2 + modules.TransactionPoolOrphanLifetime + 1)
+	if len(op.sets) != 1 || len(op.order) != 1 || op.size != setSize {
+		t.Fatalf("unexpected orphan pool: %d sets of %d bytes", len(op.sets), op.size)
+	}
+	if _, ok := op.sets[TransactionSetID(crypto.HashObject(newSet(3)))]; !ok {
+		t.Fatal("youngest orphan should not have expired")
+	}
+}
+
+// TestDoubleSpendIsNotOrphaned ensures that transaction sets spending outputs
+// which got spent by confirmed transactions are rejected, rather than held as orphans,
+// while transaction sets spending unknown outputs are held as orphans.
+func TestDoubleSpendIsNotOrphaned(t *testing.T) {
+	coin := types.DevnetChainConstants().CurrencyUnits.OneCoin
+	cs := &stubConsensusSet{outputs: map[types.CoinOutputID]types.CoinOutput{
+		{1}: {Value: coin},
+	}}
+	tp := newStubTransactionPool(t, cs)
+	defer tp.db.Close()
+
+	pooled := []types.Transaction{newStubTransaction(types.CoinOutputID{1}, coin)}
+	if err := tp.acceptTransactionSet(pooled); err != nil {
+		t.Fatal(err)
+	}
+
+	// a block spends the output spent by the pooled transaction set as well
+	confirmed := newStubTransaction(types.CoinOutputID{1}, coin)
+	confirmed.ArbitraryData = []byte("confirmed")
+	delete(cs.outputs, types.CoinOutputID{1})
+	tp.ProcessConsensusChange(modules.ConsensusChange{
+		AppliedBlocks: []types.Block{{Transactions: []types.Transaction{confirmed}}},
+	})
+	if len(tp.transactionSets) != 0 || len(tp.orphans.sets) != 0 {
+		t.Fatal("double spent transaction set should have been dropped, rather than held as an orphan")
+	}
+
+	err := tp.acceptTransactionSet(pooled)
+	if _, ok := err.(modules.ConsensusConflict); !ok {
+		t.Fatal("expected consensus conflict, got:", err)
+	}
+	if len(tp.orphans.sets) != 0 {
+		t.Fatal("double spend should not have been held as an orphan")
+	}
+
+	orphan := []types.Transaction{newStubTransaction(types.CoinOutputID{2}, coin)}
+	if err := tp.acceptTransactionSet(orphan); err != modules.ErrOrphanTransactionSet {
+		t.Fatal("expected orphan transaction set error, got:", err)
+	}
+}
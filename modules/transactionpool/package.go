@@ -0,0 +1,149 @@
+package transactionpool
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// package.go implements child-pays-for-parent (CPFP): fees are evaluated over packages,
+// a transaction set together with the transaction sets it depends on.
+//
+// Dependent transaction sets are merged into a single pooled transaction set,
+// such that every pooled transaction set is a package on its own. The fee-per-byte
+// of pooled transaction sets is hence used to order the transactions offered
+// to block creators, and to evict transaction sets from a full pool.
+// Transaction sets which do not pay the minimum fee-per-byte are held for a while,
+// such that a child transaction set can pay for them.
+
+// underpaidPackage returns the package formed by the given transaction set and all
+// underpaid transaction sets it (indirectly) depends on, ordered such that parents come first,
+// as well as the IDs of the underpaid transaction sets used.
+func (tp *TransactionPool) underpaidPackage(ts []types.Transaction) ([]types.Transaction, []TransactionSetID) {
+	// map all outputs created by underpaid transaction sets to the set creating them
+	creators := make(map[ObjectID]TransactionSetID)
+	for id, set := range tp.underpaid.sets {
+		for _, txn := range set.transactions {
+			for i := range txn.CoinOutputs {
+				creators[ObjectID(txn.CoinOutputID(uint64(i)))] = id
+			}
+			for i := range txn.BlockStakeOutputs {
+				creators[ObjectID(txn.BlockStakeOutputID(uint64(i)))] = id
+			}
+		}
+	}
+
+	// collect all underpaid ancestors, parents prior to their children
+	var (
+		parents []TransactionSetID
+		visited = make(map[TransactionSetID]struct{})
+	)
+	var visit func(set []types.Transaction)
+	visit = func(set []types.Transaction) {
+		for oid := range spentObjectIDs(set) {
+			id, ok := creators[oid]
+			if !ok {
+				continue
+			}
+			if _, ok := visited[id]; ok {
+				continue
+			}
+			visited[id] = struct{}{}
+			visit(tp.underpaid.sets[id].transactions)
+			parents = append(parents, id)
+		}
+	}
+	visit(ts)
+
+	var pkg []types.Transaction
+	for _, id := range parents {
+		pkg = append(pkg, tp.underpaid.sets[id].transactions...)
+	}
+	return append(pkg, ts...), parents
+}
+
+// acceptUnderpaidPackage accepts a package, consisting out of the given underpaid
+// transaction sets and a child paying for them, as a single transaction set.
+// The underpaid transaction sets are no longer held once they are accepted.
+func (tp *TransactionPool) acceptUnderpaidPackage(pkg []types.Transaction, parents []TransactionSetID) error {
+	held := make([]*orphanSet, 0, len(parents))
+	for _, id := range parents {
+		held = append(held, tp.underpaid.sets[id])
+		tp.underpaid.delete(id)
+	}
+	err := tp.acceptTransactionSet(pkg)
+	if err != nil && err != errLowMinerFees {
+		// restore the held sets, these might still be paid for by another child
+		// (a package which is still underpaid is held as a whole instead)
+		for _, set := range held {
+			tp.underpaid.add(set.transactions, set.height, tp.chainCts.TransactionPool.OrphanPoolSizeLimit)
+		}
+	}
+	return err
+}
+
+// orderedTransactionSets returns the IDs of all pooled transaction sets,
+// sorted by decreasing (package) fee-per-byte.
+func (tp *TransactionPool) orderedTransactionSets() []TransactionSetID {
+	sets := make([]pooledSet, 0, len(tp.transactionSets))
+	for id, set := range tp.transactionSets {
+		sets = append(sets, pooledSet{
+			id:    id,
+			fee:   transactionSetFee(set),
+			size:  len(siabin.Marshal(set)),
+			count: len(set),
+		})
+	}
+	sort.Slice(sets, func(i, j int) bool {
+		if lowerFeeDensity(sets[j].fee, sets[j].size, sets[i].fee, sets[i].size) {
+			return true
+		}
+		if lowerFeeDensity(sets[i].fee, sets[i].size, sets[j].fee, sets[j].size) {
+			return false
+		}
+		// break ties by ID, such that the order does not change between calls
+		return bytes.Compare(sets[i].id[:], sets[j].id[:]) < 0
+	})
+	ids := make([]TransactionSetID, 0, len(sets))
+	for _, set := range sets {
+		ids = append(ids, set.id)
+	}
+	return ids
+}
+
+// orderedTransactions returns all pooled transactions, in an order that can acceptably
+// be put into a block, sorted by decreasing (package) fee-per-byte of their transaction set.
+func (tp *TransactionPool) orderedTransactions() []types.Transaction {
+	var txns []types.Transaction
+	for _, id := range tp.orderedTransactionSets() {
+		txns = append(txns, tp.transactionSets[id]...)
+	}
+	return txns
+}
+
+// BlockTransactions implements TransactionPool.BlockTransactions
+func (tp *TransactionPool) BlockTransactions(sizeLimit uint64) []types.Transaction {
+	tp.mu.RLock()
+	defer tp.mu.RUnlock()
+
+	var (
+		txns []types.Transaction
+		size uint64
+	)
+	for _, id := range tp.orderedTransactionSets() {
+		set := tp.transactionSets[id]
+		var setSize uint64
+		for _, txn := range set {
+			setSize += uint64(len(siabin.Marshal(txn)))
+		}
+		if size+setSize > sizeLimit {
+			// a smaller transaction set, paying a lower fee-per-byte, might still fit
+			continue
+		}
+		size += setSize
+		txns = append(txns, set...)
+	}
+	return txns
+}
@@ -0,0 +1,117 @@
+package transactionpool
+
+import (
+	"testing"
+
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// TestUnderpaidPackage ensures a transaction set is packaged
+// together with all underpaid transaction sets it depends on.
+func TestUnderpaidPackage(t *testing.T) {
+	grandparent := types.Transaction{
+		Version:     types.TransactionVersionOne,
+		CoinInputs:  []types.CoinInput{{ParentID: types.CoinOutputID{1}}},
+		CoinOutputs: []types.CoinOutput{{Value: types.NewCurrency64(3)}},
+	}
+	parent := types.Transaction{
+		Version:     types.TransactionVersionOne,
+		CoinInputs:  []types.CoinInput{{ParentID: grandparent.CoinOutputID(0)}},
+		CoinOutputs: []types.CoinOutput{{Value: types.NewCurrency64(2)}},
+	}
+	unrelated := types.Transaction{
+		Version:     types.TransactionVersionOne,
+		CoinInputs:  []types.CoinInput{{ParentID: types.CoinOutputID{2}}},
+		CoinOutputs: []types.CoinOutput{{Value: types.NewCurrency64(1)}},
+	}
+	child := types.Transaction{
+		Version:    types.TransactionVersionOne,
+		CoinInputs: []types.CoinInput{{ParentID: parent.CoinOutputID(0)}},
+		MinerFees:  []types.Currency{types.NewCurrency64(100)},
+	}
+
+	tp := &TransactionPool{underpaid: newOrphanPool()}
+	tp.underpaid.add([]types.Transaction{parent}, 0, 1e6)
+	tp.underpaid.add([]types.Transaction{grandparent}, 0, 1e6)
+	tp.underpaid.add([]types.Transaction{unrelated}, 0, 1e6)
+
+	pkg, parents := tp.underpaidPackage([]types.Transaction{child})
+	if len(parents) != 2 {
+		t.Fatal("expected 2 underpaid parents, got:", parents)
+	}
+	if len(pkg) != 3 || pkg[0].ID() != grandparent.ID() || pkg[1].ID() != parent.ID() || pkg[2].ID() != child.ID() {
+		t.Fatal("unexpected package:", pkg)
+	}
+
+	pkg, parents = tp.underpaidPackage([]types.Transaction{grandparent})
+	if len(parents) != 0 || len(pkg) != 1 {
+		t.Fatal("unexpected package for transaction without underpaid parents:", pkg)
+	}
+}
+
+// TestOrderedTransactionSets ensures pooled transaction sets
+// are ordered by decreasing fee-per-byte.
+func TestOrderedTransactionSets(t *testing.T) {
+	newSet := func(fee uint64) []types.Transaction {
+		return []types.Transaction{{
+			Version:   types.TransactionVersionOne,
+			MinerFees: []types.Currency{types.NewCurrency64(fee)},
+		}}
+	}
+	tp := &TransactionPool{
+		transactionSets: map[TransactionSetID][]types.Transaction{
+			{1}: newSet(10),
+			{2}: newSet(30),
+			{3}: newSet(20),
+		},
+	}
+	ids := tp.orderedTransactionSets()
+	if len(ids) != 3 || ids[0] != (TransactionSetID{2}) || ids[1] != (TransactionSetID{3}) || ids[2] != (TransactionSetID{1}) {
+		t.Fatal("unexpected order:", ids)
+	}
+}
+
+// TestBlockTransactions ensures the transactions for a new block are selected
+// by decreasing fee-per-byte, as whole transaction sets, within the size limit.
+func TestBlockTransactions(t *testing.T) {
+	newTxn := func(fee uint64, data int) types.Transaction {
+		return types.Transaction{
+			Version:       types.TransactionVersionOne,
+			MinerFees:     []types.Currency{types.NewCurrency64(fee)},
+			ArbitraryData: make([]byte, data),
+		}
+	}
+	rich := []types.Transaction{newTxn(1000, 0)}
+	large := []types.Transaction{newTxn(500, 100), newTxn(500, 100)}
+	cheap := []types.Transaction{newTxn(10, 0)}
+	tp := &TransactionPool{
+		transactionSets: map[TransactionSetID][]types.Transaction{
+			{1}: cheap,
+			{2}: large,
+			{3}: rich,
+		},
+	}
+	size := func(txns []types.Transaction) (n uint64) {
+		for _, txn := range txns {
+			n += uint64(len(siabin.Marshal(txn)))
+		}
+		return
+	}
+
+	// everything fits
+	txns := tp.BlockTransactions(size(rich) + size(large) + size(cheap))
+	if len(txns) != 4 || txns[0].ID() != rich[0].ID() || txns[3].ID() != cheap[0].ID() {
+		t.Fatal("unexpected block transactions:", txns)
+	}
+
+	// the large set does not fit as a whole, the cheap set does
+	txns = tp.BlockTransactions(size(rich) + size(large) - 1)
+	if len(txns) != 2 || txns[0].ID() != rich[0].ID() || txns[1].ID() != cheap[0].ID() {
+		t.Fatal("unexpected block transactions:", txns)
+	}
+
+	if txns = tp.BlockTransactions(0); len(txns) != 0 {
+		t.Fatal("no transactions expected, got:", txns)
+	}
+}
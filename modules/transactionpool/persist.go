@@ -21,6 +21,11 @@ var (
 	// been confirmed on the blockchain.
 	bucketConfirmedTransactions = []byte("ConfirmedTransactions")
 
+	// bucketSpentOutputs holds the ids of every output that has been spent
+	// by a confirmed transaction, such that double spends of confirmed outputs
+	// can be told apart from transactions spending outputs which are not known yet.
+	bucketSpentOutputs = []byte("SpentOutputs")
+
 	// errNilConsensusChange is returned if there is no consensus change in the
 	// database.
 	errNilConsensusChange = errors.New("no consensus change found")
@@ -32,16 +37,17 @@ var (
 
 // resetDB deletes all consensus related persistence from the transaction pool.
 func (tp *TransactionPool) resetDB(tx *bolt.Tx) error {
-	err := tx.DeleteBucket(bucketConfirmedTransactions)
-	if err != nil {
-		return err
-	}
-	err = tp.putRecentConsensusChange(tx, modules.ConsensusChangeBeginning)
-	if err != nil {
-		return err
+	for _, bucket := range [][]byte{bucketConfirmedTransactions, bucketSpentOutputs} {
+		err := tx.DeleteBucket(bucket)
+		if err != nil {
+			return err
+		}
+		_, err = tx.CreateBucket(bucket)
+		if err != nil {
+			return err
+		}
 	}
-	_, err = tx.CreateBucket(bucketConfirmedTransactions)
-	return err
+	return tp.putRecentConsensusChange(tx, modules.ConsensusChangeBeginning)
 }
 
 // initPersist creates buckets in the database
@@ -53,7 +59,7 @@ func (tp *TransactionPool) initPersist() error {
 	}
 
 	// Open the database file.
-	tp.db, err = persist.OpenDatabase(dbMetadata, filepath.Join(tp.persistDir, dbFilename))
+	tp.db, err = persist.OpenDatabase(dbMetadata, filepath.Join(tp.persistDir, DatabaseFilename))
 	if err != nil {
 		return err
 	}
@@ -65,6 +71,7 @@ func (tp *TransactionPool) initPersist() error {
 		buckets := [][]byte{
 			bucketRecentConsensusChange,
 			bucketConfirmedTransactions,
+			bucketSpentOutputs,
 		}
 		for _, bucket := range buckets {
 			_, err := tx.CreateBucketIfNotExists(bucket)
@@ -138,3 +145,30 @@ func (tp *TransactionPool) addTransaction(tx *bolt.Tx, id types.TransactionID) e
 func (tp *TransactionPool) deleteTransaction(tx *bolt.Tx, id types.TransactionID) error {
 	return tx.Bucket(bucketConfirmedTransactions).Delete(id[:])
 }
+
+// outputSpent returns true if the output has been spent by a confirmed transaction.
+func (tp *TransactionPool) outputSpent(tx *bolt.Tx, id ObjectID) bool {
+	return tx.Bucket(bucketSpentOutputs).Get(id[:]) != nil
+}
+
+// addSpentOutputs adds the outputs spent by a transaction to the list of spent outputs.
+func (tp *TransactionPool) addSpentOutputs(tx *bolt.Tx, txn types.Transaction) error {
+	for oid := range spentObjectIDs([]types.Transaction{txn}) {
+		err := tx.Bucket(bucketSpentOutputs).Put(oid[:], []byte{})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteSpentOutputs deletes the outputs spent by a transaction from the list of spent outputs.
+func (tp *TransactionPool) deleteSpentOutputs(tx *bolt.Tx, txn types.Transaction) error {
+	for oid := range spentObjectIDs([]types.Transaction{txn}) {
+		err := tx.Bucket(bucketSpentOutputs).Delete(oid[:])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
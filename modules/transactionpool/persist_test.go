@@ -56,7 +56,7 @@ func TestRescan(t *testing.T) {
 	// if err != nil {
 	// 	t.Fatal(err)
 	// }
-	// db, err := persist.OpenDatabase(dbMetadata, filepath.Join(persistDir, dbFilename))
+	// db, err := persist.OpenDatabase(dbMetadata, filepath.Join(persistDir, DatabaseFilename))
 	// if err != nil {
 	// 	t.Fatal(err)
 	// }
@@ -0,0 +1,102 @@
+package transactionpool
+
+import (
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// pooledTransactions returns all pooled transactions, with their fee, size,
+// age and dependency information, optionally filtered by a given ID.
+func (tp *TransactionPool) pooledTransactions(filter func(types.TransactionID) bool) []modules.PooledTransaction {
+	// map all pooled outputs to the transaction creating them
+	creators := make(map[ObjectID]types.TransactionID)
+	for _, set := range tp.transactionSets {
+		for _, txn := range set {
+			id := txn.ID()
+			for i := range txn.CoinOutputs {
+				creators[ObjectID(txn.CoinOutputID(uint64(i)))] = id
+			}
+			for i := range txn.BlockStakeOutputs {
+				creators[ObjectID(txn.BlockStakeOutputID(uint64(i)))] = id
+			}
+		}
+	}
+
+	var ptxns []modules.PooledTransaction
+	children := make(map[types.TransactionID][]types.TransactionID)
+	for _, set := range tp.transactionSets {
+		for _, txn := range set {
+			id := txn.ID()
+			ptxn := modules.PooledTransaction{
+				Transaction: txn,
+				ID:          id,
+				Fee:         transactionSetFee([]types.Transaction{txn}),
+				Size:        uint64(len(siabin.Marshal(txn))),
+				Height:      tp.ages[id],
+			}
+			for oid := range spentObjectIDs([]types.Transaction{txn}) {
+				if parent, ok := creators[oid]; ok {
+					ptxn.Parents = append(ptxn.Parents, parent)
+					children[parent] = append(children[parent], id)
+				}
+			}
+			if filter == nil || filter(id) {
+				ptxns = append(ptxns, ptxn)
+			}
+		}
+	}
+	for i := range ptxns {
+		ptxns[i].Children = children[ptxns[i].ID]
+	}
+	return ptxns
+}
+
+// PooledTransactions implements TransactionPool.PooledTransactions
+func (tp *TransactionPool) PooledTransactions() []modules.PooledTransaction {
+	tp.mu.RLock()
+	defer tp.mu.RUnlock()
+	return tp.pooledTransactions(nil)
+}
+
+// PooledTransaction implements TransactionPool.PooledTransaction
+func (tp *TransactionPool) PooledTransaction(id types.TransactionID) (modules.PooledTransaction, error) {
+	tp.mu.RLock()
+	defer tp.mu.RUnlock()
+	ptxns := tp.pooledTransactions(func(txid types.TransactionID) bool {
+		return txid == id
+	})
+	if len(ptxns) == 0 {
+		return modules.PooledTransaction{}, modules.ErrTransactionNotFound
+	}
+	return ptxns[0], nil
+}
+
+// Statistics implements TransactionPool.Statistics
+func (tp *TransactionPool) Statistics() modules.TransactionPoolStatistics {
+	tp.mu.RLock()
+	defer tp.mu.RUnlock()
+	stats := modules.TransactionPoolStatistics{
+		TransactionSetCount:    uint64(len(tp.transactionSets)),
+		Size:                   uint64(tp.transactionListSize),
+		SizeLimit:              uint64(tp.chainCts.TransactionPool.PoolSizeLimit),
+		TransactionCountLimit:  uint64(tp.chainCts.TransactionPool.PoolTransactionCountLimit),
+		SourceTransactionLimit: uint64(tp.chainCts.TransactionPool.PoolSourceTransactionLimit),
+		OrphanCount:            uint64(len(tp.orphans.sets)),
+		OrphanSize:             uint64(tp.orphans.size),
+	}
+	for _, set := range tp.transactionSets {
+		stats.TransactionCount += uint64(len(set))
+		stats.TotalFees = stats.TotalFees.Add(transactionSetFee(set))
+	}
+	if stats.SizeLimit > 0 {
+		stats.SizeUtilization = float64(stats.Size) / float64(stats.SizeLimit)
+	}
+	if stats.TransactionCountLimit > 0 {
+		stats.CountUtilization = float64(stats.TransactionCount) / float64(stats.TransactionCountLimit)
+	}
+	if len(tp.sources) > 0 {
+		stats.Sources = tp.sources.counts()
+	}
+	return stats
+}
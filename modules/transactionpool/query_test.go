@@ -0,0 +1,60 @@
+package transactionpool
+
+import (
+	"testing"
+
+	"github.com/threefoldtech/rivine/types"
+)
+
+// TestPooledTransactionDependencies ensures the parents and children
+// of pooled transactions are reported correctly.
+func TestPooledTransactionDependencies(t *testing.T) {
+	parent := types.Transaction{
+		Version:     types.TransactionVersionOne,
+		CoinInputs:  []types.CoinInput{{ParentID: types.CoinOutputID{1}}},
+		CoinOutputs: []types.CoinOutput{{Value: types.NewCurrency64(1)}},
+		MinerFees:   []types.Currency{types.NewCurrency64(2)},
+	}
+	child := types.Transaction{
+		Version:    types.TransactionVersionOne,
+		CoinInputs: []types.CoinInput{{ParentID: parent.CoinOutputID(0)}},
+		MinerFees:  []types.Currency{types.NewCurrency64(3)},
+	}
+	tp := &TransactionPool{
+		transactionSets: map[TransactionSetID][]types.Transaction{
+			{1}: {parent, child},
+		},
+		ages: make(transactionAges),
+	}
+	tp.ages.add(tp.transactionSets[TransactionSetID{1}], 42)
+
+	ptxns := tp.pooledTransactions(nil)
+	if len(ptxns) != 2 {
+		t.Fatal("expected 2 pooled transactions, got:", len(ptxns))
+	}
+	for _, ptxn := range ptxns {
+		if ptxn.Height != 42 {
+			t.Error("unexpected height:", ptxn.Height)
+		}
+		switch ptxn.ID {
+		case parent.ID():
+			if len(ptxn.Parents) != 0 || len(ptxn.Children) != 1 || ptxn.Children[0] != child.ID() {
+				t.Error("unexpected dependencies for parent:", ptxn.Parents, ptxn.Children)
+			}
+			if !ptxn.Fee.Equals64(2) {
+				t.Error("unexpected fee for parent:", ptxn.Fee)
+			}
+		case child.ID():
+			if len(ptxn.Parents) != 1 || ptxn.Parents[0] != parent.ID() || len(ptxn.Children) != 0 {
+				t.Error("unexpected dependencies for child:", ptxn.Parents, ptxn.Children)
+			}
+		default:
+			t.Error("unexpected transaction:", ptxn.ID)
+		}
+	}
+
+	ptxns = tp.pooledTransactions(func(id types.TransactionID) bool { return id == child.ID() })
+	if len(ptxns) != 1 || len(ptxns[0].Parents) != 1 {
+		t.Fatal("unexpected filtered pooled transactions:", ptxns)
+	}
+}
@@ -0,0 +1,131 @@
+package transactionpool
+
+import (
+	"fmt"
+
+	"github.com/threefoldtech/rivine/build"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// spentObjectIDs returns the IDs of all outputs spent by a transaction set.
+func spentObjectIDs(ts []types.Transaction) map[ObjectID]struct{} {
+	oids := make(map[ObjectID]struct{})
+	for _, t := range ts {
+		for _, ci := range t.CoinInputs {
+			oids[ObjectID(ci.ParentID)] = struct{}{}
+		}
+		for _, bsi := range t.BlockStakeInputs {
+			oids[ObjectID(bsi.ParentID)] = struct{}{}
+		}
+	}
+	return oids
+}
+
+// transactionSetFee returns the total miner fee paid by a transaction set.
+func transactionSetFee(ts []types.Transaction) types.Currency {
+	var fee types.Currency
+	for _, t := range ts {
+		for _, mf := range t.MinerFees {
+			fee = fee.Add(mf)
+		}
+	}
+	return fee
+}
+
+// replacementPaysEnough returns true if a transaction set, with the given fee and size,
+// pays enough to replace transaction set(s) with the given combined fee and size.
+// Both the total fee and the fee-per-byte have to increase by at least the given percentage.
+func replacementPaysEnough(fee types.Currency, size int, replacedFee types.Currency, replacedSize int, minIncrease uint64) bool {
+	// total fee: fee >= replacedFee * (100+minIncrease) / 100
+	if fee.Mul64(100).Cmp(replacedFee.Mul64(100+minIncrease)) < 0 {
+		return false
+	}
+	// fee-per-byte: fee/size >= replacedFee/replacedSize * (100+minIncrease) / 100
+	return fee.Mul64(uint64(replacedSize)).Mul64(100).Cmp(
+		replacedFee.Mul64(uint64(size)).Mul64(100+minIncrease)) >= 0
+}
+
+// removeTransactionSet removes a transaction set from the pool,
+// as well as all objects that are tracked for it.
+func (tp *TransactionPool) removeTransactionSet(id TransactionSetID) {
+	set, exists := tp.transactionSets[id]
+	if !exists {
+		return
+	}
+	for oid, setID := range tp.knownObjects {
+		if setID == id {
+			delete(tp.knownObjects, oid)
+		}
+	}
+	tp.broadcastCache.delete(id)
+	tp.ages.remove(set)
+	tp.transactionListSize -= len(siabin.Marshal(set))
+	delete(tp.transactionSets, id)
+	delete(tp.transactionSetDiffs, id)
+}
+
+// replaceTransactionSets replaces the given pooled transaction sets with a transaction set
+// that conflicts with them, should it pay sufficiently higher fees, as defined by the
+// replace-by-fee policy of the transaction pool. The replaced transaction sets
+// are restored in case the replacing transaction set cannot be accepted,
+// in which case any error restoring them is returned as well.
+func (tp *TransactionPool) replaceTransactionSets(ts []types.Transaction, replaced []TransactionSetID) error {
+	var (
+		replacedSets [][]types.Transaction
+		replacedAges = make(transactionAges)
+		replacedFee  types.Currency
+		replacedSize int
+	)
+	for _, id := range replaced {
+		set := tp.transactionSets[id]
+		replacedSets = append(replacedSets, set)
+		for _, txn := range set {
+			replacedAges[txn.ID()] = tp.ages[txn.ID()]
+		}
+		replacedFee = replacedFee.Add(transactionSetFee(set))
+		replacedSize += len(siabin.Marshal(set))
+	}
+	if !replacementPaysEnough(transactionSetFee(ts), len(siabin.Marshal(ts)),
+		replacedFee, replacedSize, tp.chainCts.TransactionPool.ReplaceByFeeMinimumIncrease) {
+		return modules.ErrReplacementFeeTooLow
+	}
+
+	for _, id := range replaced {
+		tp.removeTransactionSet(id)
+	}
+	err := tp.acceptTransactionSet(ts)
+	if err != nil {
+		// restore the replaced transaction sets,
+		// these were valid prior to the replacement attempt,
+		// and keep the age they had
+		for id, height := range replacedAges {
+			tp.ages[id] = height
+		}
+		errs := []error{err}
+		for _, set := range replacedSets {
+			if restoreErr := tp.acceptTransactionSet(set); restoreErr != nil {
+				errs = append(errs, fmt.Errorf("failed to restore replaced transaction set: %v", restoreErr))
+			}
+		}
+		if len(errs) > 1 {
+			return build.ComposeErrors(errs...)
+		}
+		return err
+	}
+
+	event := modules.TransactionPoolEvent{
+		Type: modules.TransactionPoolEventReplaced,
+	}
+	for _, set := range replacedSets {
+		for _, txn := range set {
+			event.Transactions = append(event.Transactions, txn.ID())
+		}
+	}
+	for _, txn := range ts {
+		event.RelatedTransactions = append(event.RelatedTransactions, txn.ID())
+	}
+	tp.pendingEvents = append(tp.pendingEvents, event)
+	return nil
+}
@@ -0,0 +1,93 @@
+package transactionpool
+
+import (
+	"testing"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// TestReplacementPaysEnough probes the fee rules of the replace-by-fee policy.
+func TestReplacementPaysEnough(t *testing.T) {
+	testCases := []struct {
+		fee, replacedFee   uint64
+		size, replacedSize int
+		minIncrease        uint64
+		result             bool
+	}{
+		{110, 100, 100, 100, 10, true},
+		{109, 100, 100, 100, 10, false},
+		{100, 100, 100, 100, 0, true},
+		// higher total fee, but lower fee-per-byte
+		{200, 100, 300, 100, 10, false},
+		// higher total fee and fee-per-byte
+		{200, 100, 150, 100, 10, true},
+		{1, 0, 100, 100, 10, true},
+	}
+	for idx, tc := range testCases {
+		result := replacementPaysEnough(
+			types.NewCurrency64(tc.fee), tc.size,
+			types.NewCurrency64(tc.replacedFee), tc.replacedSize, tc.minIncrease)
+		if result != tc.result {
+			t.Errorf("test case #%d: expected %v, got %v", idx, tc.result, result)
+		}
+	}
+}
+
+// TestReplaceTransactionSets ensures that a child submitted together with its pooled parent
+// is not mistaken for a double spend, and that replaced transaction sets are restored
+// in case the replacing transaction set is invalid.
+func TestReplaceTransactionSets(t *testing.T) {
+	coin := types.DevnetChainConstants().CurrencyUnits.OneCoin
+	cs := &stubConsensusSet{outputs: map[types.CoinOutputID]types.CoinOutput{
+		{1}: {Value: coin.Mul64(2)},
+	}}
+	tp := newStubTransactionPool(t, cs)
+	defer tp.db.Close()
+
+	parent := newStubTransaction(types.CoinOutputID{1}, coin)
+	parent.CoinOutputs = []types.CoinOutput{{Value: coin}}
+	if err := tp.acceptTransactionSet([]types.Transaction{parent}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the identical parent is not a double spend, the child is simply added
+	child := newStubTransaction(parent.CoinOutputID(0), coin)
+	if err := tp.acceptTransactionSet([]types.Transaction{parent, child}); err != nil {
+		t.Fatal("expected the child to be accepted together with its parent, got:", err)
+	}
+	merged := TransactionSetID(crypto.HashObject([]types.Transaction{parent, child}))
+	if _, ok := tp.transactionSets[merged]; !ok || len(tp.transactionSets) != 1 {
+		t.Fatal("expected the parent and child to be merged into a single transaction set")
+	}
+	if len(tp.pendingEvents) != 0 {
+		t.Fatal("no events expected, got:", tp.pendingEvents)
+	}
+
+	// a double spend not paying enough does not replace the pooled parent
+	doubleSpend := newStubTransaction(types.CoinOutputID{1}, coin.Mul64(2))
+	if err := tp.acceptTransactionSet([]types.Transaction{doubleSpend}); err != modules.ErrReplacementFeeTooLow {
+		t.Fatal("expected replacement fee too low error, got:", err)
+	}
+
+	// an invalid double spend paying enough is rejected, restoring the pooled sets
+	cs.height = 3
+	invalid := newStubTransaction(types.CoinOutputID{1}, coin.Mul64(10))
+	if err := tp.acceptTransactionSet([]types.Transaction{invalid}); err == nil {
+		t.Fatal("expected invalid transaction set to be rejected")
+	} else if _, ok := err.(modules.ConsensusConflict); !ok {
+		t.Fatal("expected consensus conflict, got:", err)
+	}
+	if _, ok := tp.transactionSets[merged]; !ok || len(tp.transactionSets) != 1 {
+		t.Fatal("expected the replaced transaction set to be restored")
+	}
+	if height, ok := tp.ages.oldest([]types.Transaction{parent, child}); !ok || height != 0 {
+		t.Fatal("expected the restored transactions to keep their age, got:", height, ok)
+	}
+	for _, event := range tp.pendingEvents {
+		if event.Type == modules.TransactionPoolEventReplaced {
+			t.Fatal("no transaction set should have been replaced:", event)
+		}
+	}
+}
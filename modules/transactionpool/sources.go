@@ -0,0 +1,66 @@
+package transactionpool
+
+import (
+	"github.com/threefoldtech/rivine/types"
+)
+
+// localSource is the source of the transactions submitted through this node.
+const localSource = "local"
+
+// transactionSources tracks the source (e.g. the relaying peer)
+// of pooled transactions, by transaction ID.
+type transactionSources map[types.TransactionID]string
+
+// add the given source for all transactions of the given transaction set,
+// for which no source is tracked yet.
+func (sources transactionSources) add(ts []types.Transaction, source string) {
+	for _, txn := range ts {
+		id := txn.ID()
+		if _, ok := sources[id]; !ok {
+			sources[id] = source
+		}
+	}
+}
+
+// prune forgets the source of all transactions which are not part of the given
+// (pooled) transaction sets, as they got confirmed or dropped from the pool.
+func (sources transactionSources) prune(pooled map[TransactionSetID][]types.Transaction) {
+	if len(sources) == 0 {
+		return
+	}
+	ids := make(map[types.TransactionID]struct{})
+	for _, set := range pooled {
+		for _, txn := range set {
+			ids[txn.ID()] = struct{}{}
+		}
+	}
+	for id := range sources {
+		if _, ok := ids[id]; !ok {
+			delete(sources, id)
+		}
+	}
+}
+
+// counts returns the amount of tracked transactions per source.
+func (sources transactionSources) counts() map[string]uint64 {
+	counts := make(map[string]uint64)
+	for _, source := range sources {
+		counts[source]++
+	}
+	return counts
+}
+
+// withinLimit returns true if the given source can add the given amount
+// of transactions, without exceeding the given limit. A limit of 0 means no limit.
+func (sources transactionSources) withinLimit(source string, n, limit int) bool {
+	if limit <= 0 {
+		return true
+	}
+	count := 0
+	for _, s := range sources {
+		if s == source {
+			count++
+		}
+	}
+	return count+n <= limit
+}
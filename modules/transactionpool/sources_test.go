@@ -0,0 +1,42 @@
+package transactionpool
+
+import (
+	"testing"
+
+	"github.com/threefoldtech/rivine/types"
+)
+
+// TestTransactionSources probes the tracking and limiting
+// of pooled transactions per source.
+func TestTransactionSources(t *testing.T) {
+	newTxn := func(fee uint64) types.Transaction {
+		return types.Transaction{
+			Version:   types.TransactionVersionOne,
+			MinerFees: []types.Currency{types.NewCurrency64(fee)},
+		}
+	}
+	a, b, c := newTxn(1), newTxn(2), newTxn(3)
+
+	sources := make(transactionSources)
+	sources.add([]types.Transaction{a, b}, "peer")
+	sources.add([]types.Transaction{b, c}, localSource) // b keeps its original source
+	counts := sources.counts()
+	if counts["peer"] != 2 || counts[localSource] != 1 {
+		t.Fatal("unexpected source counts:", counts)
+	}
+
+	if !sources.withinLimit("peer", 1, 0) {
+		t.Error("a limit of 0 should mean no limit")
+	}
+	if !sources.withinLimit("peer", 1, 3) || sources.withinLimit("peer", 2, 3) {
+		t.Error("unexpected limit result for known source")
+	}
+	if !sources.withinLimit("other", 3, 3) {
+		t.Error("unexpected limit result for unknown source")
+	}
+
+	sources.prune(map[TransactionSetID][]types.Transaction{{1}: {c}})
+	if len(sources) != 1 || sources[c.ID()] != localSource {
+		t.Fatal("unexpected sources after pruning:", sources)
+	}
+}
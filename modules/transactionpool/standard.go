@@ -13,6 +13,13 @@ import (
 // It also ensures that the transaction as well as the transaction set,
 // are within an acceptable byte size range, when binary encoded.
 func (tp *TransactionPool) ValidateTransactionSet(ts []types.Transaction) error {
+	return tp.validateTransactionSet(ts, true)
+}
+
+// validateTransactionSet validates a transaction set, as documented by ValidateTransactionSet.
+// The transaction (set) size limits of the transaction pool are only applied if sizePolicy is true,
+// such that priority transaction sets are only required to be valid according to the consensus.
+func (tp *TransactionPool) validateTransactionSet(ts []types.Transaction, sizePolicy bool) error {
 	totalSize := 0
 	blockHeight := tp.consensusSet.Height()
 	block, ok := tp.consensusSet.BlockAtHeight(blockHeight)
@@ -28,7 +35,7 @@ func (tp *TransactionPool) ValidateTransactionSet(ts []types.Transaction) error
 	var err error
 	for _, t := range ts {
 		size := len(siabin.Marshal(t))
-		if size > tp.chainCts.TransactionPool.TransactionSizeLimit {
+		if sizePolicy && size > tp.chainCts.TransactionPool.TransactionSizeLimit {
 			return modules.ErrLargeTransaction
 		}
 		totalSize += size
@@ -41,7 +48,7 @@ func (tp *TransactionPool) ValidateTransactionSet(ts []types.Transaction) error
 			return err
 		}
 	}
-	if totalSize > tp.chainCts.TransactionPool.TransactionSetSizeLimit {
+	if sizePolicy && totalSize > tp.chainCts.TransactionPool.TransactionSetSizeLimit {
 		return modules.ErrLargeTransactionSet
 	}
 	return nil
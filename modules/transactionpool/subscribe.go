@@ -5,14 +5,94 @@ import (
 	"github.com/threefoldtech/rivine/types"
 )
 
-// updateSubscribersTransactions sends a new transaction pool update to all
-// subscribers.
-func (tp *TransactionPool) updateSubscribersTransactions() {
-	var txns []types.Transaction
-	var cc modules.ConsensusChange
-	for _, tSet := range tp.transactionSets {
-		txns = append(txns, tSet...)
+// takePendingEvents returns all pending events,
+// clearing them from the transaction pool.
+func (tp *TransactionPool) takePendingEvents() []modules.TransactionPoolEvent {
+	events := tp.pendingEvents
+	tp.pendingEvents = nil
+	return events
+}
+
+// sendEvents sends the given events to all event listeners, as well as all subscribers
+// which implement the TransactionPoolEventSubscriber interface.
+func (tp *TransactionPool) sendEvents(events []modules.TransactionPoolEvent) {
+	for _, subscriber := range tp.subscribers {
+		eventSubscriber, ok := subscriber.(modules.TransactionPoolEventSubscriber)
+		if !ok {
+			continue
+		}
+		for _, event := range events {
+			eventSubscriber.ReceiveTransactionPoolEvent(event)
+		}
+	}
+	for _, listener := range tp.eventListeners {
+		for _, event := range events {
+			listener.ReceiveTransactionPoolEvent(event)
+		}
+	}
+}
+
+// membershipEvents returns the events describing the transactions which got added to,
+// removed from or confirmed out of the pool since the last update, given the current pooled transactions.
+func (tp *TransactionPool) membershipEvents(txns []types.Transaction) []modules.TransactionPoolEvent {
+	current := make(map[types.TransactionID]struct{}, len(txns))
+	ids := make([]types.TransactionID, 0, len(txns))
+	for _, txn := range txns {
+		id := txn.ID()
+		current[id] = struct{}{}
+		ids = append(ids, id)
+	}
+	previous := make(map[types.TransactionID]struct{}, len(tp.notifiedTransactions))
+	var removed, confirmed []types.TransactionID
+	for _, id := range tp.notifiedTransactions {
+		previous[id] = struct{}{}
+		if _, ok := current[id]; ok {
+			continue
+		}
+		if _, ok := tp.confirmedTransactions[id]; ok {
+			confirmed = append(confirmed, id)
+		} else {
+			removed = append(removed, id)
+		}
+	}
+	var added []types.TransactionID
+	for _, id := range ids {
+		if _, ok := previous[id]; !ok {
+			added = append(added, id)
+		}
+	}
+	tp.notifiedTransactions = ids
+	tp.confirmedTransactions = nil
+
+	var events []modules.TransactionPoolEvent
+	if len(confirmed) > 0 {
+		events = append(events, modules.TransactionPoolEvent{
+			Type:         modules.TransactionPoolEventConfirmed,
+			Transactions: confirmed,
+		})
 	}
+	if len(removed) > 0 {
+		events = append(events, modules.TransactionPoolEvent{
+			Type:         modules.TransactionPoolEventRemoved,
+			Transactions: removed,
+		})
+	}
+	if len(added) > 0 {
+		events = append(events, modules.TransactionPoolEvent{
+			Type:         modules.TransactionPoolEventAdded,
+			Transactions: added,
+		})
+	}
+	return events
+}
+
+// updateSubscribersTransactions sends the given events, followed by a new
+// transaction pool update, to all subscribers.
+func (tp *TransactionPool) updateSubscribersTransactions(events []modules.TransactionPoolEvent) {
+	txns := tp.orderedTransactions()
+	tp.sendEvents(append(events, tp.membershipEvents(txns)...))
+
+	var cc modules.ConsensusChange
 	for _, tSetDiff := range tp.transactionSetDiffs {
 		cc = cc.Append(tSetDiff)
 	}
@@ -32,10 +112,7 @@ func (tp *TransactionPool) TransactionPoolSubscribe(subscriber modules.Transacti
 	tp.subscribers = append(tp.subscribers, subscriber)
 
 	// Send the new subscriber the transaction pool set.
-	var txns []types.Transaction
-	for _, tSet := range tp.transactionSets {
-		txns = append(txns, tSet...)
-	}
+	txns := tp.orderedTransactions()
 	var cc modules.ConsensusChange
 	for _, tSetDiff := range tp.transactionSetDiffs {
 		cc = cc.Append(tSetDiff)
@@ -59,3 +136,26 @@ func (tp *TransactionPool) Unsubscribe(subscriber modules.TransactionPoolSubscri
 		}
 	}
 }
+
+// TransactionPoolEventSubscribe adds a listener to the transaction pool.
+// Listeners receive all events of the transaction pool from now on,
+// but not the full transaction set on every change.
+func (tp *TransactionPool) TransactionPoolEventSubscribe(listener modules.TransactionPoolEventListener) {
+	tp.mu.Lock()
+	tp.eventListeners = append(tp.eventListeners, listener)
+	tp.mu.Unlock()
+}
+
+// TransactionPoolEventUnsubscribe removes a listener from the transaction pool.
+// If the listener is not in tp.eventListeners, TransactionPoolEventUnsubscribe does nothing.
+func (tp *TransactionPool) TransactionPoolEventUnsubscribe(listener modules.TransactionPoolEventListener) {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+
+	for i := range tp.eventListeners {
+		if tp.eventListeners[i] == listener {
+			tp.eventListeners = append(tp.eventListeners[0:i], tp.eventListeners[i+1:]...)
+			break
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package transactionpool
+
+import (
+	"time"
+
+	"github.com/threefoldtech/rivine/build"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// sync.go synchronizes the transaction pool with newly connected peers,
+// such that nodes which were briefly offline converge on the same pooled transactions,
+// rather than waiting for those to be relayed again.
+//
+// Both sides exchange compact lists of their pooled transaction IDs, after which
+// both sides request and send the transaction sets missing on the other side.
+// For every step, the calling side of the RPC sends first.
+
+const (
+	// poolSyncIDLimit is the maximum amount of transaction IDs exchanged
+	// when synchronizing the transaction pool with a peer.
+	poolSyncIDLimit = 10e3
+	// poolSyncSizeLimit is the maximum size, in bytes, of the transaction sets
+	// sent when synchronizing the transaction pool with a peer. Remaining
+	// transactions are received through regular relay.
+	poolSyncSizeLimit = 5e6
+)
+
+var (
+	// poolSyncTimeout is the timeout for the SyncTransactionPool RPC.
+	poolSyncTimeout = func() time.Duration {
+		switch build.Release {
+		case "dev":
+			return 40 * time.Second
+		case "standard":
+			return 2 * time.Minute
+		case "testing":
+			return 5 * time.Second
+		default:
+			panic("unrecognized build.Release")
+		}
+	}()
+)
+
+// shortTransactionID is the compact form of a transaction ID,
+// used to exchange the pooled transaction IDs with peers.
+type shortTransactionID [8]byte
+
+// shortID returns the compact form of the given transaction ID.
+func shortID(id types.TransactionID) (sid shortTransactionID) {
+	copy(sid[:], id[:])
+	return
+}
+
+// pooledShortIDs returns the compact IDs of all pooled transactions,
+// limited to the transactions paying the highest fees.
+func (tp *TransactionPool) pooledShortIDs() []shortTransactionID {
+	tp.mu.RLock()
+	defer tp.mu.RUnlock()
+
+	var ids []shortTransactionID
+	for _, txn := range tp.orderedTransactions() {
+		if len(ids) == poolSyncIDLimit {
+			break
+		}
+		ids = append(ids, shortID(txn.ID()))
+	}
+	return ids
+}
+
+// missingShortIDs returns the compact IDs of the transactions
+// offered by a peer, which are not pooled.
+func (tp *TransactionPool) missingShortIDs(offered []shortTransactionID) []shortTransactionID {
+	tp.mu.RLock()
+	defer tp.mu.RUnlock()
+
+	pooled := make(map[shortTransactionID]struct{})
+	for _, set := range tp.transactionSets {
+		for _, txn := range set {
+			pooled[shortID(txn.ID())] = struct{}{}
+		}
+	}
+	var missing []shortTransactionID
+	for _, id := range offered {
+		if _, ok := pooled[id]; !ok {
+			missing = append(missing, id)
+			// prevent duplicates, should a peer offer the same ID more than once
+			pooled[id] = struct{}{}
+		}
+	}
+	return missing
+}
+
+// requestedTransactionSets returns the pooled transaction sets containing at least one of the requested
+// transactions, in an order that can acceptably be put into a block, up to poolSyncSizeLimit bytes.
+func (tp *TransactionPool) requestedTransactionSets(requested []shortTransactionID) [][]types.Transaction {
+	if len(requested) == 0 {
+		return nil
+	}
+	tp.mu.RLock()
+	defer tp.mu.RUnlock()
+
+	ids := make(map[shortTransactionID]struct{}, len(requested))
+	for _, id := range requested {
+		ids[id] = struct{}{}
+	}
+	var (
+		sets [][]types.Transaction
+		size int
+	)
+	for _, setID := range tp.orderedTransactionSets() {
+		set := tp.transactionSets[setID]
+		for _, txn := range set {
+			if _, ok := ids[shortID(txn.ID())]; !ok {
+				continue
+			}
+			setSize := len(siabin.Marshal(set))
+			if size+setSize <= poolSyncSizeLimit {
+				sets = append(sets, set)
+				size += setSize
+			}
+			break
+		}
+	}
+	return sets
+}
+
+// exchangeObjects sends the given object to the peer, and receives an object from the peer,
+// in that order if caller is true, and in reverse order otherwise.
+func exchangeObjects(conn modules.PeerConn, caller bool, send, receive interface{}, maxLen uint64) error {
+	if caller {
+		if err := siabin.WriteObject(conn, send); err != nil {
+			return err
+		}
+		return siabin.ReadObject(conn, receive, maxLen)
+	}
+	if err := siabin.ReadObject(conn, receive, maxLen); err != nil {
+		return err
+	}
+	return siabin.WriteObject(conn, send)
+}
+
+// syncTransactionPool synchronizes the transaction pool with the given peer,
+// accepting the transaction sets which are pooled by the peer but not by us.
+func (tp *TransactionPool) syncTransactionPool(conn modules.PeerConn, caller bool) error {
+	// Ignore errors returned by SetDeadline if the conn is a pipe in testing.
+	_ = conn.SetDeadline(time.Now().Add(poolSyncTimeout))
+
+	var offered []shortTransactionID
+	err := exchangeObjects(conn, caller, tp.pooledShortIDs(), &offered, 8+poolSyncIDLimit*8)
+	if err != nil {
+		return err
+	}
+	var requested []shortTransactionID
+	err = exchangeObjects(conn, caller, tp.missingShortIDs(offered), &requested, 8+poolSyncIDLimit*8)
+	if err != nil {
+		return err
+	}
+	var received [][]types.Transaction
+	err = exchangeObjects(conn, caller, tp.requestedTransactionSets(requested), &received, 8+poolSyncSizeLimit)
+	if err != nil {
+		return err
+	}
+
+	// Accept the received transaction sets as if they were relayed by the peer,
+	// invalid or already known transaction sets are simply not accepted.
+	for _, set := range received {
+		_ = tp.acceptTransactionSetFromSource(set, string(conn.RPCAddr()), false)
+	}
+	return nil
+}
+
+// threadedSyncTransactionPool is the calling end of the SyncTransactionPool RPC,
+// called upon connecting to a new peer.
+func (tp *TransactionPool) threadedSyncTransactionPool(conn modules.PeerConn) error {
+	return tp.syncTransactionPool(conn, true)
+}
+
+// rpcSyncTransactionPool is the receiving end of the SyncTransactionPool RPC.
+func (tp *TransactionPool) rpcSyncTransactionPool(conn modules.PeerConn) error {
+	return tp.syncTransactionPool(conn, false)
+}
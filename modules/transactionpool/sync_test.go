@@ -0,0 +1,80 @@
+package transactionpool
+
+import (
+	"net"
+	"testing"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// pipeConn is a net.Conn with a fixed RPC address, used to test RPCs.
+type pipeConn struct {
+	net.Conn
+}
+
+func (pipeConn) RPCAddr() modules.NetAddress { return "pipe:1" }
+
+// TestSyncTransactionPoolExchange ensures two transaction pools exchange
+// exactly the transaction sets missing on the other side.
+func TestSyncTransactionPoolExchange(t *testing.T) {
+	newSet := func(id byte) []types.Transaction {
+		return []types.Transaction{{
+			Version:       types.TransactionVersionOne,
+			ArbitraryData: []byte{id},
+			MinerFees:     []types.Currency{types.NewCurrency64(uint64(id))},
+		}}
+	}
+	shared, onlyA, onlyB := newSet(1), newSet(2), newSet(3)
+	a := &TransactionPool{transactionSets: map[TransactionSetID][]types.Transaction{
+		{1}: shared,
+		{2}: onlyA,
+	}}
+	b := &TransactionPool{transactionSets: map[TransactionSetID][]types.Transaction{
+		{1}: shared,
+		{3}: onlyB,
+	}}
+
+	// run all steps of the exchange, except for accepting the received sets
+	exchange := func(tp *TransactionPool, conn modules.PeerConn, caller bool) ([][]types.Transaction, error) {
+		var offered, requested []shortTransactionID
+		if err := exchangeObjects(conn, caller, tp.pooledShortIDs(), &offered, 8+poolSyncIDLimit*8); err != nil {
+			return nil, err
+		}
+		if err := exchangeObjects(conn, caller, tp.missingShortIDs(offered), &requested, 8+poolSyncIDLimit*8); err != nil {
+			return nil, err
+		}
+		var received [][]types.Transaction
+		err := exchangeObjects(conn, caller, tp.requestedTransactionSets(requested), &received, 8+poolSyncSizeLimit)
+		return received, err
+	}
+	connA, connB := net.Pipe()
+	defer connA.Close()
+	defer connB.Close()
+	type result struct {
+		sets [][]types.Transaction
+		err  error
+	}
+	results := make(chan result, 1)
+	go func() {
+		// close the connection when done, as the gateway does, such that the caller cannot block
+		defer connB.Close()
+		sets, err := exchange(b, pipeConn{connB}, false)
+		results <- result{sets, err}
+	}()
+	receivedA, err := exchange(a, pipeConn{connA}, true)
+	resultB := <-results
+	if resultB.err != nil {
+		t.Fatal(resultB.err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(receivedA) != 1 || receivedA[0][0].ID() != onlyB[0].ID() {
+		t.Fatal("unexpected transaction sets received by the caller:", receivedA)
+	}
+	if len(resultB.sets) != 1 || resultB.sets[0][0].ID() != onlyA[0].ID() {
+		t.Fatal("unexpected transaction sets received by the peer:", resultB.sets)
+	}
+}
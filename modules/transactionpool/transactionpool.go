@@ -12,13 +12,15 @@ import (
 )
 
 const (
-	dbFilename = "transactionpool.db"
+	// DatabaseFilename contains the filename of the database that will be used
+	// when managing the transaction pool.
+	DatabaseFilename = "transactionpool.db"
 )
 
 var (
 	dbMetadata = persist.Metadata{
 		Header:  "Sia Transaction Pool DB",
-		Version: "0.6.0",
+		Version: "0.7.0",
 	}
 
 	errNilCS      = errors.New("transaction pool cannot initialize with a nil consensus set")
@@ -67,9 +69,37 @@ type (
 		// transaction pool, all prior consensus changes are sent to the new
 		// subscriber.
 		subscribers []modules.TransactionPoolSubscriber
+		// eventListeners receive the events of the transaction pool only.
+		eventListeners []modules.TransactionPoolEventListener
+		// notifiedTransactions are the IDs of the pooled transactions as of the last update,
+		// and confirmedTransactions the IDs of the transactions confirmed since the last update,
+		// used to emit the added, removed and confirmed events.
+		notifiedTransactions  []types.TransactionID
+		confirmedTransactions map[types.TransactionID]struct{}
+		// pendingEvents are the events that still have to be sent to
+		// the subscribers, together with the next update.
+		pendingEvents []modules.TransactionPoolEvent
 
 		// broadcastCache keeps track of all transaction sets currently in the pool.
 		broadcastCache transactionCache
+		// ages tracks the height at which each pooled transaction was added,
+		// such that transactions which remain unconfirmed for too long can be expired.
+		ages transactionAges
+
+		// orphans are the transaction sets which spend outputs that are not known yet.
+		orphans orphanPool
+		// underpaid are the transaction sets which do not pay the minimum fee,
+		// held such that a child transaction set can pay for them.
+		underpaid orphanPool
+		// local tracks the transactions submitted through this node,
+		// which are rebroadcast until they are confirmed or dropped.
+		local localTransactions
+		// priority tracks the local transactions submitted with priority,
+		// which are exempt from the fee and size policies of the pool, and never evicted.
+		priority localTransactions
+		// sources tracks the source (e.g. the relaying peer) of pooled transactions,
+		// such that the amount of transactions per source can be limited.
+		sources transactionSources
 
 		// Utilities.
 		db         *persist.BoltDatabase
@@ -101,6 +131,12 @@ func New(cs modules.ConsensusSet, g modules.Gateway, persistDir string, bcInfo t
 		transactionSetDiffs: make(map[TransactionSetID]modules.ConsensusChange),
 
 		broadcastCache: newTransactionCache(),
+		ages:           make(transactionAges),
+		orphans:        newOrphanPool(),
+		underpaid:      newOrphanPool(),
+		local:          make(localTransactions),
+		priority:       make(localTransactions),
+		sources:        make(transactionSources),
 
 		persistDir: persistDir,
 
@@ -116,12 +152,16 @@ func New(cs modules.ConsensusSet, g modules.Gateway, persistDir string, bcInfo t
 
 	// Register RPCs
 	g.RegisterRPC("RelayTransactionSet", tp.relayTransactionSet)
+	g.RegisterRPC("SyncTransactionPool", tp.rpcSyncTransactionPool)
+	g.RegisterConnectCall("SyncTransactionPool", tp.threadedSyncTransactionPool)
 
 	return tp, nil
 }
 
 func (tp *TransactionPool) Close() error {
 	tp.gateway.UnregisterRPC("RelayTransactionSet")
+	tp.gateway.UnregisterRPC("SyncTransactionPool")
+	tp.gateway.UnregisterConnectCall("SyncTransactionPool")
 	tp.consensusSet.Unsubscribe(tp)
 	return tp.db.Close()
 }
@@ -137,16 +177,12 @@ func (tp *TransactionPool) FeeEstimation() (min, max types.Currency) {
 
 // TransactionList returns a list of all transactions in the transaction pool.
 // The transactions are provided in an order that can acceptably be put into a
-// block.
+// block, sorted by decreasing (package) fee-per-byte.
 func (tp *TransactionPool) TransactionList() []types.Transaction {
 	tp.mu.RLock()
 	defer tp.mu.RUnlock()
 
-	var txns []types.Transaction
-	for _, tSet := range tp.transactionSets {
-		txns = append(txns, tSet...)
-	}
-	return txns
+	return tp.orderedTransactions()
 }
 
 // Transaction implements TransactionPool.Transaction
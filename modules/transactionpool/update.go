@@ -29,6 +29,10 @@ func (tp *TransactionPool) ProcessConsensusChange(cc modules.ConsensusChange) {
 				if err != nil {
 					return err
 				}
+				err = tp.deleteSpentOutputs(tx, txn)
+				if err != nil {
+					return err
+				}
 			}
 		}
 		for _, block := range cc.AppliedBlocks {
@@ -37,6 +41,10 @@ func (tp *TransactionPool) ProcessConsensusChange(cc modules.ConsensusChange) {
 				if err != nil {
 					return err
 				}
+				err = tp.addSpentOutputs(tx, txn)
+				if err != nil {
+					return err
+				}
 			}
 		}
 		return tp.putRecentConsensusChange(tx, cc.ID)
@@ -64,6 +72,12 @@ func (tp *TransactionPool) ProcessConsensusChange(cc modules.ConsensusChange) {
 			txids[txn.ID()] = struct{}{}
 		}
 	}
+	if tp.confirmedTransactions == nil {
+		tp.confirmedTransactions = make(map[types.TransactionID]struct{}, len(txids))
+	}
+	for id := range txids {
+		tp.confirmedTransactions[id] = struct{}{}
+	}
 
 	// TODO: Right now, transactions that were reverted to not get saved and
 	// retried, because some transactions such as storage proofs might be
@@ -80,6 +94,15 @@ func (tp *TransactionPool) ProcessConsensusChange(cc modules.ConsensusChange) {
 	// When they stop being valid, you've found a guy to throw away. It's n^2
 	// in the number of transactions in the block.
 
+	// Notify subscribers of pooled transaction sets which got double spent by the applied blocks.
+	var blockTxns []types.Transaction
+	for _, block := range cc.AppliedBlocks {
+		blockTxns = append(blockTxns, block.Transactions...)
+	}
+	if conflicts := doubleSpends(tp.transactionSets, blockTxns); len(conflicts) > 0 {
+		tp.pendingEvents = append(tp.pendingEvents, conflictEvents(tp.transactionSets, conflicts)...)
+	}
+
 	// Save all of the current unconfirmed transaction sets into a list.
 	var unconfirmedSets [][]types.Transaction
 	for _, tSet := range tp.transactionSets {
@@ -128,17 +151,43 @@ func (tp *TransactionPool) ProcessConsensusChange(cc modules.ConsensusChange) {
 		}
 	}
 
+	// Drop the transaction sets which remained unconfirmed for too long.
+	currentheight := tp.consensusSet.Height()
+	tp.expireTransactionSets(currentheight)
+
+	// Drop orphans which have waited too long for their parents,
+	// and accept the ones for which the parents got confirmed.
+	tp.orphans.expire(currentheight)
+	tp.underpaid.expire(currentheight)
+	promoted := tp.promoteOrphans()
+
 	// If we are synced, try to broadcast again
 	if cc.Synced {
-		currentheight := tp.consensusSet.Height()
 		for _, id := range tp.broadcastCache.getTransactionsToBroadcast(currentheight) {
 			go tp.gateway.Broadcast("RelayTransactionSet", tp.transactionSets[id], tp.gateway.Peers())
 		}
+		for _, set := range promoted {
+			go tp.gateway.Broadcast("RelayTransactionSet", set, tp.gateway.Peers())
+		}
+	}
+
+	// Forget the local transactions which are no longer pooled,
+	// and rebroadcast the remaining ones to the current peers,
+	// such that they are not stranded in case they were missed before.
+	tp.local.prune(tp.transactionSets)
+	tp.priority.prune(tp.transactionSets)
+	tp.sources.prune(tp.transactionSets)
+	tp.ages.prune(tp.transactionSets)
+	if cc.Synced {
+		for _, id := range tp.local.sets(tp.transactionSets) {
+			go tp.gateway.Broadcast("RelayTransactionSet", tp.transactionSets[id], tp.gateway.Peers())
+		}
 	}
 
 	// Inform subscribers that an update has executed.
+	events := tp.takePendingEvents()
 	tp.mu.Demote()
-	tp.updateSubscribersTransactions()
+	tp.updateSubscribersTransactions(events)
 	tp.mu.DemotedUnlock()
 }
 
@@ -146,5 +195,11 @@ func (tp *TransactionPool) ProcessConsensusChange(cc modules.ConsensusChange) {
 func (tp *TransactionPool) PurgeTransactionPool() {
 	tp.mu.Lock()
 	tp.purge()
+	tp.orphans = newOrphanPool()
+	tp.underpaid = newOrphanPool()
+	tp.local = make(localTransactions)
+	tp.priority = make(localTransactions)
+	tp.sources = make(transactionSources)
+	tp.ages = make(transactionAges)
 	tp.mu.Unlock()
 }
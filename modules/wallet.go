@@ -3,6 +3,7 @@ package modules
 import (
 	"encoding/hex"
 	"errors"
+	"fmt"
 
 	"github.com/threefoldtech/rivine/crypto"
 	"github.com/threefoldtech/rivine/types"
@@ -127,6 +128,67 @@ type (
 		MinSigs uint64             `json:"minsigs"`
 	}
 
+	// UnsignedTransaction is a transaction exported to be signed by a cold wallet,
+	// paired with the outputs spent by its inputs, such that it can be signed
+	// without access to the consensus set.
+	UnsignedTransaction struct {
+		Transaction types.Transaction `json:"transaction"`
+		// CoinInputOutputs are the coin outputs spent by the coin inputs, in the same order.
+		CoinInputOutputs []types.CoinOutput `json:"coininputoutputs"`
+		// BlockStakeInputOutputs are the blockstake outputs spent by the blockstake inputs, in the same order.
+		BlockStakeInputOutputs []types.BlockStakeOutput `json:"blockstakeinputoutputs"`
+	}
+
+	// WalletEventType defines the type of a WalletEvent.
+	WalletEventType uint8
+
+	// WalletEvent describes a change to the wallet, such as transactions relevant
+	// to the wallet being confirmed, or the wallet being locked.
+	WalletEvent struct {
+		Type WalletEventType `json:"type"`
+		// Transactions are the IDs of the transactions relevant to the wallet the event is about,
+		// using the block ID as transaction ID for miner payouts, as is done for processed transactions.
+		Transactions []types.TransactionID `json:"transactions,omitempty"`
+		// Height is the height of the block in which the transactions got confirmed or reverted.
+		Height types.BlockHeight `json:"height,omitempty"`
+	}
+
+	// A WalletEventListener receives the events of the wallet.
+	WalletEventListener interface {
+		// ReceiveWalletEvent notifies listeners of an event.
+		// It is called while the wallet is locked, and should thus not block.
+		ReceiveWalletEvent(WalletEvent)
+	}
+
+	// WatchedAddress is an address, not owned by the wallet,
+	// of which the outputs and transactions are tracked by the wallet.
+	WatchedAddress struct {
+		Address                    types.UnlockHash `json:"address"`
+		ConfirmedCoinBalance       types.Currency   `json:"confirmedcoinbalance"`
+		ConfirmedBlockStakeBalance types.Currency   `json:"confirmedblockstakebalance"`
+	}
+
+	// CoinControl defines which outputs of the wallet can be used to fund a transaction,
+	// and where the remainder of the spent outputs is refunded to.
+	// The zero value lets the wallet select any spendable output and refund to a new wallet address.
+	CoinControl struct {
+		// CoinInputs, if defined, are the only coin outputs used to fund the transaction,
+		// all of them are spent, even if fewer would suffice.
+		CoinInputs []types.CoinOutputID `json:"coininputs,omitempty"`
+		// BlockStakeInputs, if defined, are the only blockstake outputs used to fund the transaction,
+		// all of them are spent, even if fewer would suffice.
+		BlockStakeInputs []types.BlockStakeOutputID `json:"blockstakeinputs,omitempty"`
+		// ExcludeTimeLocked excludes outputs with a time lock condition,
+		// even if that time lock has already been reached.
+		ExcludeTimeLocked bool `json:"excludetimelocked,omitempty"`
+		// ChangeAddress, if defined, receives the refund outputs,
+		// instead of a new address of the wallet.
+		ChangeAddress *types.UnlockHash `json:"changeaddress,omitempty"`
+		// MinerFee, if defined, is the miner fee paid by the transaction,
+		// instead of the minimum transaction fee. It cannot be lower than that minimum.
+		MinerFee *types.Currency `json:"minerfee,omitempty"`
+	}
+
 	// TransactionBuilder is used to construct custom transactions. A transaction
 	// builder is initialized via 'RegisterTransaction' and then can be modified by
 	// adding funds or other fields. The transaction is completed by calling
@@ -278,6 +340,10 @@ type (
 		// which is linked to the given unlock hash (assumed to be the address a user).
 		GetKey(address types.UnlockHash) (types.PublicKey, types.ByteSlice, error)
 
+		// SignMessage signs the given message using the key pair of the given (single signature) address,
+		// such that anyone can verify the message was signed by the owner of that address.
+		SignMessage(address types.UnlockHash, message []byte) (types.MessageSignature, error)
+
 		// PrimarySeed returns the current primary seed of the wallet,
 		// unencrypted, with an int indicating how many addresses have been
 		// consumed.
@@ -404,6 +470,10 @@ type (
 		// The transaction is automatically given to the transaction pool, and is also returned to the caller.
 		SendOutputs(coinOutputs []types.CoinOutput, blockstakeOutputs []types.BlockStakeOutput, data []byte) (types.Transaction, error)
 
+		// SendOutputsWithCoinControl is the same as SendOutputs,
+		// except that the outputs spent and the change address are defined by the given coin control.
+		SendOutputsWithCoinControl(coinOutputs []types.CoinOutput, blockstakeOutputs []types.BlockStakeOutput, data []byte, cc CoinControl) (types.Transaction, error)
+
 		// BlockStakeStats returns the blockstake statistical information of
 		// this wallet of the last 1000 blocks. If the blockcount is less than
 		// 1000 blocks, BlockCount will be the number available.
@@ -425,9 +495,84 @@ type (
 		// GreedySign attempts to sign every input which can be signed by the keys loaded
 		// in this wallet.
 		GreedySign(types.Transaction) (types.Transaction, error)
+
+		// AddWatchedAddresses adds addresses, which are not owned by the wallet,
+		// to be tracked by the wallet, such that their balance and transactions can be monitored.
+		// The outputs of watched addresses can not be spent by the wallet.
+		// The wallet rescans the blockchain if it has already been scanned.
+		AddWatchedAddresses([]types.UnlockHash) error
+
+		// RemoveWatchedAddresses stops tracking the given watched addresses.
+		// The wallet rescans the blockchain if it has already been scanned.
+		RemoveWatchedAddresses([]types.UnlockHash) error
+
+		// WatchedAddresses returns all addresses watched by the wallet,
+		// together with their confirmed balances.
+		WatchedAddresses() ([]WatchedAddress, error)
+
+		// ColdSign attempts to sign every input which can be signed by the keys loaded
+		// in this wallet, using the spent outputs of the unsigned transaction rather than
+		// the consensus set, such that an offline wallet can sign it.
+		ColdSign(UnsignedTransaction) (types.Transaction, error)
+
+		// WalletEventSubscribe adds a listener to the wallet,
+		// which receives all events of the wallet from now on.
+		WalletEventSubscribe(WalletEventListener)
+
+		// WalletEventUnsubscribe removes a listener from the wallet.
+		WalletEventUnsubscribe(WalletEventListener)
 	}
 )
 
+const (
+	// WalletEventConfirmed is emitted when transactions relevant to the wallet are confirmed in a block.
+	WalletEventConfirmed WalletEventType = iota + 1
+	// WalletEventReverted is emitted when confirmed transactions relevant to the wallet
+	// are reverted, as their block is no longer part of the blockchain.
+	WalletEventReverted
+	// WalletEventUnconfirmed is emitted when unconfirmed transactions relevant to the wallet
+	// are added to the transaction pool.
+	WalletEventUnconfirmed
+	// WalletEventLocked is emitted when the wallet is locked.
+	WalletEventLocked
+	// WalletEventUnlocked is emitted when the wallet is unlocked.
+	WalletEventUnlocked
+)
+
+// String returns the type as a human-readable string.
+func (t WalletEventType) String() string {
+	switch t {
+	case WalletEventConfirmed:
+		return "confirmed"
+	case WalletEventReverted:
+		return "reverted"
+	case WalletEventUnconfirmed:
+		return "unconfirmed"
+	case WalletEventLocked:
+		return "locked"
+	case WalletEventUnlocked:
+		return "unlocked"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.MarshalText
+func (t WalletEventType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.UnmarshalText
+func (t *WalletEventType) UnmarshalText(b []byte) error {
+	for et := WalletEventConfirmed; et <= WalletEventUnlocked; et++ {
+		if et.String() == string(b) {
+			*t = et
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown wallet event type %q", string(b))
+}
+
 // CalculateWalletTransactionID is a helper function for determining the id of
 // a wallet transaction.
 func CalculateWalletTransactionID(tid types.TransactionID, oid types.OutputID) WalletTransactionID {
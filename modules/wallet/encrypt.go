@@ -150,6 +150,7 @@ func (w *Wallet) managedUnlock(masterKey crypto.TwofishKey) error {
 
 	w.mu.Lock()
 	w.unlocked = true
+	w.sendWalletEvent(modules.WalletEvent{Type: modules.WalletEventUnlocked})
 	w.mu.Unlock()
 	return nil
 }
@@ -221,6 +222,7 @@ func (w *Wallet) Lock() error {
 	// calling 'Unlock' again.
 	w.wipeSecrets()
 	w.unlocked = false
+	w.sendWalletEvent(modules.WalletEvent{Type: modules.WalletEventLocked})
 	return nil
 }
 
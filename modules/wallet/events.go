@@ -0,0 +1,50 @@
+package wallet
+
+import (
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// sendWalletEvent sends the given event to all event listeners,
+// it should be called while holding the lock.
+func (w *Wallet) sendWalletEvent(event modules.WalletEvent) {
+	for _, listener := range w.eventListeners {
+		listener.ReceiveWalletEvent(event)
+	}
+}
+
+// sendHistoryEvent sends an event for the given confirmed or reverted transactions,
+// at the current height, unless there are none or the wallet is (re)scanning the blockchain.
+// It should be called while holding the lock.
+func (w *Wallet) sendHistoryEvent(eventType modules.WalletEventType, txids []types.TransactionID) {
+	if len(txids) == 0 || !w.subscribed || w.rescanning {
+		return
+	}
+	w.sendWalletEvent(modules.WalletEvent{
+		Type:         eventType,
+		Transactions: txids,
+		Height:       w.consensusSetHeight,
+	})
+}
+
+// WalletEventSubscribe adds a listener to the wallet.
+// Listeners receive all events of the wallet from now on.
+func (w *Wallet) WalletEventSubscribe(listener modules.WalletEventListener) {
+	w.mu.Lock()
+	w.eventListeners = append(w.eventListeners, listener)
+	w.mu.Unlock()
+}
+
+// WalletEventUnsubscribe removes a listener from the wallet.
+// If the listener is not in w.eventListeners, WalletEventUnsubscribe does nothing.
+func (w *Wallet) WalletEventUnsubscribe(listener modules.WalletEventListener) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for i := range w.eventListeners {
+		if w.eventListeners[i] == listener {
+			w.eventListeners = append(w.eventListeners[0:i], w.eventListeners[i+1:]...)
+			break
+		}
+	}
+}
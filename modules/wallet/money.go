@@ -261,6 +261,12 @@ func (w *Wallet) SendBlockStakes(amount types.Currency, cond types.UnlockConditi
 // SendOutputs is a tool for sending coins and block stakes from the wallet, to one or multiple addreses.
 // The transaction is automatically given to the transaction pool, and is also returned to the caller.
 func (w *Wallet) SendOutputs(coinOutputs []types.CoinOutput, blockstakeOutputs []types.BlockStakeOutput, data []byte) (types.Transaction, error) {
+	return w.SendOutputsWithCoinControl(coinOutputs, blockstakeOutputs, data, modules.CoinControl{})
+}
+
+// SendOutputsWithCoinControl is the same as SendOutputs,
+// except that the outputs spent and the change address are defined by the given coin control.
+func (w *Wallet) SendOutputsWithCoinControl(coinOutputs []types.CoinOutput, blockstakeOutputs []types.BlockStakeOutput, data []byte, cc modules.CoinControl) (types.Transaction, error) {
 	if len(coinOutputs) == 0 && len(blockstakeOutputs) == 0 {
 		// at least one coin output OR one block stake output has to be send
 		return types.Transaction{}, ErrNilOutputs
@@ -272,13 +278,19 @@ func (w *Wallet) SendOutputs(coinOutputs []types.CoinOutput, blockstakeOutputs [
 	defer w.tg.Done()
 
 	tpoolFee := w.chainCts.MinimumTransactionFee.Mul64(1) // TODO better fee algo
+	if cc.MinerFee != nil {
+		if cc.MinerFee.Cmp(tpoolFee) < 0 {
+			return types.Transaction{}, types.ErrTooSmallMinerFee
+		}
+		tpoolFee = *cc.MinerFee
+	}
 	totalAmount := types.NewCurrency64(0).Add(tpoolFee)
-	txnBuilder := w.StartTransaction()
+	txnBuilder := w.StartTransaction().(*transactionBuilder)
 	for _, co := range coinOutputs {
 		txnBuilder.AddCoinOutput(co)
 		totalAmount = totalAmount.Add(co.Value)
 	}
-	err := txnBuilder.FundCoins(totalAmount)
+	err := txnBuilder.fundCoins(totalAmount, cc)
 	if err != nil {
 		return types.Transaction{}, err
 	}
@@ -289,7 +301,7 @@ func (w *Wallet) SendOutputs(coinOutputs []types.CoinOutput, blockstakeOutputs [
 		totalAmount = totalAmount.Add(bso.Value)
 	}
 	if !totalAmount.Equals64(0) {
-		err = txnBuilder.FundBlockStakes(totalAmount)
+		err = txnBuilder.fundBlockStakes(totalAmount, cc)
 		if err != nil {
 			return types.Transaction{}, err
 		}
@@ -304,7 +316,7 @@ func (w *Wallet) SendOutputs(coinOutputs []types.CoinOutput, blockstakeOutputs [
 	if len(txnSet) == 0 {
 		panic("unexpected txnSet length: " + strconv.Itoa(len(txnSet)))
 	}
-	err = w.tpool.AcceptTransactionSet(txnSet)
+	err = w.tpool.AcceptLocalTransactionSet(txnSet)
 	if err != nil {
 		return types.Transaction{}, err
 	}
@@ -3,11 +3,13 @@ package wallet
 import (
 	"crypto/rand"
 	"os"
+	"path"
 	"path/filepath"
 
 	"github.com/threefoldtech/rivine/crypto"
 	"github.com/threefoldtech/rivine/modules"
 	"github.com/threefoldtech/rivine/persist"
+	"github.com/threefoldtech/rivine/types"
 )
 
 const (
@@ -61,6 +63,10 @@ type WalletPersist struct {
 	// UnseededKeys are list of spendable keys that were not generated by a
 	// random seed.
 	UnseededKeys []SpendableKeyFile
+
+	// WatchedAddresses are addresses not owned by the wallet,
+	// of which the outputs and transactions are tracked nonetheless.
+	WatchedAddresses []types.UnlockHash `json:",omitempty"`
 }
 
 // loadSettings reads the wallet's settings from the wallet's settings file,
@@ -104,6 +110,9 @@ func (w *Wallet) initSettings() error {
 	if err != nil {
 		return err
 	}
+	for _, uh := range w.persist.WatchedAddresses {
+		w.watchedAddresses[uh] = struct{}{}
+	}
 	// unlock by default if the file is unencrypted,
 	// load the primary and aux seeds already as well and subscribe the wallet
 	if w.persist.PrimarySeedFile.UID != (UniqueID{}) && len(w.persist.EncryptionVerification) == 0 {
@@ -169,6 +178,19 @@ func (w *Wallet) CreateBackup(backupFilepath string) error {
 	return w.createBackup(backupFilepath)
 }
 
+// Backup implements modules.Backupper.Backup,
+// writing the settings of the wallet, of which the seeds and keys are encrypted.
+// Unlike CreateBackup it does not require the wallet to be unlocked.
+func (w *Wallet) Backup(bw persist.BackupWriter) error {
+	if err := w.tg.Add(); err != nil {
+		return err
+	}
+	defer w.tg.Done()
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return persist.BackupJSON(bw, path.Join(modules.WalletDir, settingsFile), settingsMetadata, w.persist)
+}
+
 /*
 // LoadBackup loads a backup file from the provided filepath. The backup file
 // primary seed is loaded as an auxiliary seed.
@@ -192,3 +214,6 @@ func (w *Wallet) LoadBackup(masterKey, backupMasterKey crypto.TwofishKey, backup
 	TODO: more
 }
 */
+
+// enforce that Wallet can be backed up while running
+var _ modules.Backupper = (*Wallet)(nil)
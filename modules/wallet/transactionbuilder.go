@@ -17,6 +17,10 @@ var (
 	// already added at least one successful signature to the transaction,
 	// meaning that future calls to Sign will result in an invalid transaction.
 	errBuilderAlreadySigned = errors.New("sign has already been called on this transaction builder, multiple calls can cause issues")
+
+	// errUnspendableCoinControlInput indicates that not all outputs selected
+	// by the coin control can be spent by the wallet.
+	errUnspendableCoinControlInput = errors.New("not all selected outputs are unspent and spendable by the wallet")
 )
 
 // transactionBuilder allows transactions to be manually constructed, including
@@ -40,10 +44,60 @@ type inputSignContext struct {
 	UnlockHash types.UnlockHash
 }
 
+// coinControlFilter filters the outputs of the wallet
+// that can be used to fund a transaction, as defined by a coin control.
+type coinControlFilter struct {
+	selected          map[types.OutputID]struct{}
+	excludeTimeLocked bool
+}
+
+func newCoinControlFilter(selected []types.OutputID, excludeTimeLocked bool) coinControlFilter {
+	filter := coinControlFilter{excludeTimeLocked: excludeTimeLocked}
+	if len(selected) > 0 {
+		filter.selected = make(map[types.OutputID]struct{}, len(selected))
+		for _, id := range selected {
+			filter.selected[id] = struct{}{}
+		}
+	}
+	return filter
+}
+
+// allows returns true if the output, identified by the given ID and locked by the given condition,
+// can be used to fund a transaction.
+func (filter coinControlFilter) allows(id types.OutputID, condition types.UnlockConditionProxy) bool {
+	if filter.selected != nil {
+		if _, ok := filter.selected[id]; !ok {
+			return false
+		}
+	}
+	return !filter.excludeTimeLocked || condition.ConditionType() != types.ConditionTypeTimeLock
+}
+
+// spendsAll returns true if all selected outputs have to be spent,
+// rather than only as many outputs as required to fund the transaction.
+func (filter coinControlFilter) spendsAll() bool {
+	return filter.selected != nil
+}
+
+// refundUnlockHash returns the unlock hash to refund the remainder of a funded transaction to,
+// which is the change address of the coin control if defined, or a new address of the wallet otherwise.
+func (tb *transactionBuilder) refundUnlockHash(cc modules.CoinControl) (types.UnlockHash, error) {
+	if cc.ChangeAddress != nil {
+		return *cc.ChangeAddress, nil
+	}
+	return tb.wallet.nextPrimarySeedAddress()
+}
+
 // FundCoins will add a siacoin input of exactly 'amount' to the
 // transaction. The coin input will not be signed until 'Sign' is called
 // on the transaction builder.
 func (tb *transactionBuilder) FundCoins(amount types.Currency) error {
+	return tb.fundCoins(amount, modules.CoinControl{})
+}
+
+// fundCoins is the same as FundCoins,
+// except that the spent outputs and refund address are defined by the given coin control.
+func (tb *transactionBuilder) fundCoins(amount types.Currency, cc modules.CoinControl) error {
 	tb.wallet.mu.Lock()
 	defer tb.wallet.mu.Unlock()
 
@@ -54,10 +108,16 @@ func (tb *transactionBuilder) FundCoins(amount types.Currency) error {
 	// prepare fulfillable context
 	ctx := tb.wallet.getFulfillableContextForLatestBlock()
 
+	selected := make([]types.OutputID, 0, len(cc.CoinInputs))
+	for _, scoid := range cc.CoinInputs {
+		selected = append(selected, types.OutputID(scoid))
+	}
+	filter := newCoinControlFilter(selected, cc.ExcludeTimeLocked)
+
 	// Collect a value-sorted set of fulfillable coin outputs.
 	var so sortedOutputs
 	for scoid, sco := range tb.wallet.coinOutputs {
-		if !sco.Condition.Fulfillable(ctx) {
+		if !sco.Condition.Fulfillable(ctx) || !filter.allows(types.OutputID(scoid), sco.Condition) {
 			continue
 		}
 		so.ids = append(so.ids, scoid)
@@ -72,10 +132,11 @@ func (tb *transactionBuilder) FundCoins(amount types.Currency) error {
 			if err != nil {
 				return err
 			}
-			if !exists || !sco.Condition.Fulfillable(ctx) {
+			scoid := upt.Transaction.CoinOutputID(uint64(i))
+			if !exists || !sco.Condition.Fulfillable(ctx) || !filter.allows(types.OutputID(scoid), sco.Condition) {
 				continue
 			}
-			so.ids = append(so.ids, upt.Transaction.CoinOutputID(uint64(i)))
+			so.ids = append(so.ids, scoid)
 			so.outputs = append(so.outputs, sco)
 		}
 	}
@@ -139,10 +200,13 @@ func (tb *transactionBuilder) FundCoins(amount types.Currency) error {
 		// Add the output to the total fund
 		fund = fund.Add(sco.Value)
 		potentialFund = potentialFund.Add(sco.Value)
-		if fund.Cmp(amount) >= 0 {
+		if !filter.spendsAll() && fund.Cmp(amount) >= 0 {
 			break
 		}
 	}
+	if filter.spendsAll() && len(spentScoids) != len(filter.selected) {
+		return errUnspendableCoinControlInput
+	}
 	if potentialFund.Cmp(amount) >= 0 && fund.Cmp(amount) < 0 {
 		return modules.ErrIncompleteTransactions
 	}
@@ -152,7 +216,7 @@ func (tb *transactionBuilder) FundCoins(amount types.Currency) error {
 
 	// Create a refund output if needed.
 	if !amount.Equals(fund) {
-		refundUnlockHash, err := tb.wallet.nextPrimarySeedAddress()
+		refundUnlockHash, err := tb.refundUnlockHash(cc)
 		if err != nil {
 			return err
 		}
@@ -174,6 +238,12 @@ func (tb *transactionBuilder) FundCoins(amount types.Currency) error {
 // transaction. The blockstake input will not be signed until 'Sign' is called
 // on the transaction builder.
 func (tb *transactionBuilder) FundBlockStakes(amount types.Currency) error {
+	return tb.fundBlockStakes(amount, modules.CoinControl{})
+}
+
+// fundBlockStakes is the same as FundBlockStakes,
+// except that the spent outputs and refund address are defined by the given coin control.
+func (tb *transactionBuilder) fundBlockStakes(amount types.Currency, cc modules.CoinControl) error {
 	tb.wallet.mu.Lock()
 	defer tb.wallet.mu.Unlock()
 
@@ -184,13 +254,19 @@ func (tb *transactionBuilder) FundBlockStakes(amount types.Currency) error {
 	// prepare fulfillable context
 	ctx := tb.wallet.getFulfillableContextForLatestBlock()
 
+	selected := make([]types.OutputID, 0, len(cc.BlockStakeInputs))
+	for _, sfoid := range cc.BlockStakeInputs {
+		selected = append(selected, types.OutputID(sfoid))
+	}
+	filter := newCoinControlFilter(selected, cc.ExcludeTimeLocked)
+
 	// Create a transaction that will add the correct amount of siafunds to the
 	// transaction.
 	var fund types.Currency
 	var potentialFund types.Currency
 	var spentSfoids []types.BlockStakeOutputID
 	for sfoid, sfo := range tb.wallet.blockstakeOutputs {
-		if !sfo.Condition.Fulfillable(ctx) {
+		if !sfo.Condition.Fulfillable(ctx) || !filter.allows(types.OutputID(sfoid), sfo.Condition) {
 			continue
 		}
 		// Check that this output has not recently been spent by the wallet.
@@ -239,10 +315,13 @@ func (tb *transactionBuilder) FundBlockStakes(amount types.Currency) error {
 		// Add the output to the total fund
 		fund = fund.Add(sfo.Value)
 		potentialFund = potentialFund.Add(sfo.Value)
-		if fund.Cmp(amount) >= 0 {
+		if !filter.spendsAll() && fund.Cmp(amount) >= 0 {
 			break
 		}
 	}
+	if filter.spendsAll() && len(spentSfoids) != len(filter.selected) {
+		return errUnspendableCoinControlInput
+	}
 	if potentialFund.Cmp(amount) >= 0 && fund.Cmp(amount) < 0 {
 		return modules.ErrIncompleteTransactions
 	}
@@ -252,7 +331,7 @@ func (tb *transactionBuilder) FundBlockStakes(amount types.Currency) error {
 
 	// Create a refund output if needed.
 	if !amount.Equals(fund) {
-		refundUnlockHash, err := tb.wallet.nextPrimarySeedAddress()
+		refundUnlockHash, err := tb.refundUnlockHash(cc)
 		if err != nil {
 			return err
 		}
@@ -448,6 +527,14 @@ func (tb *transactionBuilder) Sign() ([]types.Transaction, error) {
 
 // SignAllPossible tries to sign any input for which keys are loaded in the wallet
 func (tb *transactionBuilder) SignAllPossible() error {
+	return tb.signAllPossible(tb.wallet.cs.GetCoinOutput, tb.wallet.cs.GetBlockStakeOutput)
+}
+
+// signAllPossible tries to sign any input for which keys are loaded in the wallet,
+// using the given functions to look up the outputs spent by the inputs.
+func (tb *transactionBuilder) signAllPossible(
+	getCoinOutput func(types.CoinOutputID) (types.CoinOutput, error),
+	getBlockStakeOutput func(types.BlockStakeOutputID) (types.BlockStakeOutput, error)) error {
 	if tb.signed {
 		return errBuilderAlreadySigned
 	}
@@ -462,7 +549,7 @@ func (tb *transactionBuilder) SignAllPossible() error {
 	// sign all coin inputs
 	for i := range tb.transaction.CoinInputs {
 		ci := &tb.transaction.CoinInputs[i]
-		uco, err := tb.wallet.cs.GetCoinOutput(ci.ParentID)
+		uco, err := getCoinOutput(ci.ParentID)
 		if err != nil {
 			return err
 		}
@@ -476,7 +563,7 @@ func (tb *transactionBuilder) SignAllPossible() error {
 	// sign all blockstake inputs
 	for i := range tb.transaction.BlockStakeInputs {
 		bsi := &tb.transaction.BlockStakeInputs[i]
-		ubso, err := tb.wallet.cs.GetBlockStakeOutput(bsi.ParentID)
+		ubso, err := getBlockStakeOutput(bsi.ParentID)
 		if err != nil {
 			return err
 		}
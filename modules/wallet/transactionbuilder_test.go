@@ -448,3 +448,38 @@ func TestConcurrentBuilders(t *testing.T) {
 // 		t.Fatal("did not get the expected ending balance", expected, endingSCConfirmed, startingSCConfirmed)
 // 	}
 // }
+
+func TestCoinControlFilter(t *testing.T) {
+	uhCondition := types.NewCondition(types.NewUnlockHashCondition(types.UnlockHash{Type: types.UnlockTypePubKey}))
+	lockedCondition := types.NewCondition(types.NewTimeLockCondition(1, uhCondition.Condition))
+
+	// the zero coin control allows any output
+	filter := newCoinControlFilter(nil, false)
+	if !filter.allows(types.OutputID{1}, uhCondition) || !filter.allows(types.OutputID{2}, lockedCondition) {
+		t.Fatal("expected the default filter to allow any output")
+	}
+	if filter.spendsAll() {
+		t.Fatal("expected the default filter to only spend the required outputs")
+	}
+
+	// only selected outputs are allowed, and all of them are to be spent
+	filter = newCoinControlFilter([]types.OutputID{{1}, {2}}, false)
+	if !filter.allows(types.OutputID{1}, uhCondition) || !filter.allows(types.OutputID{2}, lockedCondition) {
+		t.Fatal("expected the selected outputs to be allowed")
+	}
+	if filter.allows(types.OutputID{3}, uhCondition) {
+		t.Fatal("expected an unselected output not to be allowed")
+	}
+	if !filter.spendsAll() {
+		t.Fatal("expected the filter to spend all selected outputs")
+	}
+
+	// time locked outputs can be excluded
+	filter = newCoinControlFilter([]types.OutputID{{1}, {2}}, true)
+	if !filter.allows(types.OutputID{1}, uhCondition) {
+		t.Fatal("expected an output without time lock to be allowed")
+	}
+	if filter.allows(types.OutputID{2}, lockedCondition) {
+		t.Fatal("expected a time locked output to be excluded")
+	}
+}
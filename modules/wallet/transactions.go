@@ -264,3 +264,35 @@ func (w *Wallet) GreedySign(txn types.Transaction) (types.Transaction, error) {
 	signedTxn, _ := txnBuilder.View()
 	return signedTxn, err
 }
+
+// ColdSign attempts to sign every input in the unsigned transaction that can be signed
+// using the keys loaded in this wallet, looking up the spent outputs in the unsigned transaction
+// rather than the consensus set, such that the wallet does not need to be synced.
+func (w *Wallet) ColdSign(utxn modules.UnsignedTransaction) (types.Transaction, error) {
+	txn := utxn.Transaction
+	if len(utxn.CoinInputOutputs) != len(txn.CoinInputs) {
+		return txn, errors.New("Mismatched coin input - spent coin output count")
+	}
+	if len(utxn.BlockStakeInputOutputs) != len(txn.BlockStakeInputs) {
+		return txn, errors.New("Mismatched blockstake input - spent blockstake output count")
+	}
+	coinOutputs := make(map[types.CoinOutputID]types.CoinOutput, len(txn.CoinInputs))
+	for i, ci := range txn.CoinInputs {
+		coinOutputs[ci.ParentID] = utxn.CoinInputOutputs[i]
+	}
+	blockStakeOutputs := make(map[types.BlockStakeOutputID]types.BlockStakeOutput, len(txn.BlockStakeInputs))
+	for i, bsi := range txn.BlockStakeInputs {
+		blockStakeOutputs[bsi.ParentID] = utxn.BlockStakeInputOutputs[i]
+	}
+
+	txnBuilder := w.RegisterTransaction(txn, nil)
+	err := txnBuilder.(*transactionBuilder).signAllPossible(
+		func(id types.CoinOutputID) (types.CoinOutput, error) {
+			return coinOutputs[id], nil
+		},
+		func(id types.BlockStakeOutputID) (types.BlockStakeOutput, error) {
+			return blockStakeOutputs[id], nil
+		})
+	signedTxn, _ := txnBuilder.View()
+	return signedTxn, err
+}
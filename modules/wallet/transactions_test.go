@@ -1,5 +1,12 @@
 package wallet
 
+import (
+	"testing"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
 // TestIntegrationTransactions checks that the transaction history is being
 // correctly recorded and extended.
 // func TestIntegrationTransactions(t *testing.T) {
@@ -125,3 +132,52 @@ package wallet
 // 		t.Error("addresses unconfirmed transactions should be empty")
 // 	}
 // }
+
+// TestColdSign checks that inputs can be signed using the spent outputs
+// given with the unsigned transaction, rather than those known by the consensus set.
+func TestColdSign(t *testing.T) {
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	uh, err := wt.wallet.NextAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	condition := types.NewCondition(types.NewUnlockHashCondition(uh))
+	utxn := modules.UnsignedTransaction{
+		Transaction: types.Transaction{
+			Version:    types.TransactionVersionOne,
+			CoinInputs: []types.CoinInput{{ParentID: types.CoinOutputID{1}}},
+			CoinOutputs: []types.CoinOutput{{
+				Value:     types.NewCurrency64(90),
+				Condition: condition,
+			}},
+			MinerFees: []types.Currency{types.NewCurrency64(10)},
+		},
+		CoinInputOutputs: []types.CoinOutput{{
+			Value:     types.NewCurrency64(100),
+			Condition: condition,
+		}},
+	}
+
+	txn, err := wt.wallet.ColdSign(utxn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = condition.Fulfill(txn.CoinInputs[0].Fulfillment, types.FulfillContext{
+		ExtraObjects: []interface{}{uint64(0)},
+		Transaction:  txn,
+	})
+	if err != nil {
+		t.Fatal("coin input was not correctly signed:", err)
+	}
+
+	// the spent outputs have to match the inputs
+	utxn.CoinInputOutputs = nil
+	if _, err = wt.wallet.ColdSign(utxn); err == nil {
+		t.Fatal("expected an error for missing spent coin outputs")
+	}
+}
@@ -58,6 +58,16 @@ func (w *Wallet) updateConfirmedSet(cc modules.ConsensusChange) {
 			continue
 		}
 
+		// track the outputs of watched addresses separately, as they cannot be spent
+		if _, exists := w.watchedAddresses[diff.CoinOutput.Condition.UnlockHash()]; exists {
+			if diff.Direction == modules.DiffApply {
+				w.watchedCoinOutputs[diff.ID] = diff.CoinOutput
+			} else {
+				delete(w.watchedCoinOutputs, diff.ID)
+			}
+			continue
+		}
+
 		// try to get the unlock hash slice of a multisig
 		unlockhashes, _ := getMultisigConditionProperties(diff.CoinOutput.Condition.Condition)
 		if len(unlockhashes) == 0 {
@@ -101,6 +111,16 @@ func (w *Wallet) updateConfirmedSet(cc modules.ConsensusChange) {
 			continue
 		}
 
+		// track the outputs of watched addresses separately, as they cannot be spent
+		if _, exists := w.watchedAddresses[diff.BlockStakeOutput.Condition.UnlockHash()]; exists {
+			if diff.Direction == modules.DiffApply {
+				w.watchedBlockStakeOutputs[diff.ID] = diff.BlockStakeOutput
+			} else {
+				delete(w.watchedBlockStakeOutputs, diff.ID)
+			}
+			continue
+		}
+
 		// try to get the unlock hash slice of a multisig
 		unlockhashes, _ := getMultisigConditionProperties(diff.BlockStakeOutput.Condition.Condition)
 		if len(unlockhashes) == 0 {
@@ -157,6 +177,7 @@ func getMultisigConditionProperties(condition types.MarshalableUnlockCondition)
 // blocks in the consensus change.
 func (w *Wallet) revertHistory(cc modules.ConsensusChange) {
 	for _, block := range cc.RevertedBlocks {
+		var reverted []types.TransactionID
 		// Remove any transactions that have been reverted.
 		for i := len(block.Transactions) - 1; i >= 0; i-- {
 			// If the transaction is relevant to the wallet, it will be the
@@ -168,18 +189,21 @@ func (w *Wallet) revertHistory(cc modules.ConsensusChange) {
 			if len(w.processedTransactions) > 0 && txid == w.processedTransactions[len(w.processedTransactions)-1].TransactionID {
 				w.processedTransactions = w.processedTransactions[:len(w.processedTransactions)-1]
 				delete(w.processedTransactionMap, txid)
+				reverted = append(reverted, txid)
 			}
 		}
 
 		// Remove the miner payout transaction if applicable.
 		for _, mp := range block.MinerPayouts {
 			_, exists := w.keys[mp.UnlockHash]
-			if exists {
+			if exists || w.isWatchedAddress(mp.UnlockHash) {
 				w.processedTransactions = w.processedTransactions[:len(w.processedTransactions)-1]
 				delete(w.processedTransactionMap, types.TransactionID(block.ID()))
+				reverted = append(reverted, types.TransactionID(block.ID()))
 				break
 			}
 		}
+		w.sendHistoryEvent(modules.WalletEventReverted, reverted)
 		w.consensusSetHeight--
 	}
 }
@@ -189,6 +213,7 @@ func (w *Wallet) revertHistory(cc modules.ConsensusChange) {
 func (w *Wallet) applyHistory(cc modules.ConsensusChange) {
 	for _, block := range cc.AppliedBlocks {
 		w.consensusSetHeight++
+		applied := len(w.processedTransactions)
 		// Apply the miner payout transaction if applicable.
 		minerPT := modules.ProcessedTransaction{
 			Transaction:           types.Transaction{},
@@ -199,7 +224,7 @@ func (w *Wallet) applyHistory(cc modules.ConsensusChange) {
 		relevant := false
 		for i, mp := range block.MinerPayouts {
 			_, exists := w.keys[mp.UnlockHash]
-			if exists {
+			if exists || w.isWatchedAddress(mp.UnlockHash) {
 				relevant = true
 			}
 			minerPT.Outputs = append(minerPT.Outputs, modules.ProcessedOutput{
@@ -244,6 +269,9 @@ func (w *Wallet) applyHistory(cc modules.ConsensusChange) {
 					relevant = true
 					// set "exists" to false since the output is not owned by the wallet.
 					exists = false
+				} else if w.isWatchedAddress(output.UnlockHash) {
+					// watched outputs are relevant, but not owned by the wallet
+					relevant = true
 				}
 				pt.Inputs = append(pt.Inputs, modules.ProcessedInput{
 					FundType:       types.SpecifierCoinInput,
@@ -262,6 +290,9 @@ func (w *Wallet) applyHistory(cc modules.ConsensusChange) {
 					relevant = true
 					// set "exists" to false since the output is not owned by the wallet.
 					exists = false
+				} else if w.isWatchedAddress(sco.Condition.UnlockHash()) {
+					// watched outputs are relevant, but not owned by the wallet
+					relevant = true
 				}
 				uh := sco.Condition.UnlockHash()
 				pt.Outputs = append(pt.Outputs, modules.ProcessedOutput{
@@ -288,6 +319,9 @@ func (w *Wallet) applyHistory(cc modules.ConsensusChange) {
 					relevant = true
 					// set "exists" to false since the output is not owned by the wallet.
 					exists = false
+				} else if w.isWatchedAddress(output.UnlockHash) {
+					// watched outputs are relevant, but not owned by the wallet
+					relevant = true
 				}
 				pt.Inputs = append(pt.Inputs, modules.ProcessedInput{
 					FundType:       types.SpecifierBlockStakeInput,
@@ -306,6 +340,9 @@ func (w *Wallet) applyHistory(cc modules.ConsensusChange) {
 					relevant = true
 					// set "exists" to false since the output is not owned by the wallet.
 					exists = false
+				} else if w.isWatchedAddress(sfo.Condition.UnlockHash()) {
+					// watched outputs are relevant, but not owned by the wallet
+					relevant = true
 				}
 				uh := sfo.Condition.UnlockHash()
 				pt.Outputs = append(pt.Outputs, modules.ProcessedOutput{
@@ -339,6 +376,12 @@ func (w *Wallet) applyHistory(cc modules.ConsensusChange) {
 				w.processedTransactionMap[pt.TransactionID] = &w.processedTransactions[len(w.processedTransactions)-1]
 			}
 		}
+
+		var confirmed []types.TransactionID
+		for _, pt := range w.processedTransactions[applied:] {
+			confirmed = append(confirmed, pt.TransactionID)
+		}
+		w.sendHistoryEvent(modules.WalletEventConfirmed, confirmed)
 	}
 }
 
@@ -359,6 +402,42 @@ func (w *Wallet) ProcessConsensusChange(cc modules.ConsensusChange) {
 	w.applyHistory(cc)
 }
 
+// ReceiveTransactionPoolEvent tracks the unconfirmed transactions relevant to the wallet,
+// which got replaced, evicted, expired or double spent. The outputs spent by evicted and expired transactions
+// are released, such that the wallet can spend them again (e.g. to rebuild a failed withdrawal).
+func (w *Wallet) ReceiveTransactionPoolEvent(event modules.TransactionPoolEvent) {
+	if err := w.tg.Add(); err != nil {
+		return
+	}
+	defer w.tg.Done()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	affected := make(map[types.TransactionID]struct{}, len(event.Transactions))
+	for _, txid := range event.Transactions {
+		affected[txid] = struct{}{}
+	}
+	for _, pt := range w.unconfirmedProcessedTransactions {
+		if _, ok := affected[pt.TransactionID]; !ok {
+			continue
+		}
+		switch event.Type {
+		case modules.TransactionPoolEventReplaced:
+			w.log.Printf("unconfirmed transaction %v was replaced by transaction(s) %v", pt.TransactionID, event.RelatedTransactions)
+		case modules.TransactionPoolEventConflict:
+			w.log.Printf("unconfirmed transaction %v conflicts with transaction(s) %v, contesting output(s) %v", pt.TransactionID, event.RelatedTransactions, event.Outputs)
+		case modules.TransactionPoolEventEvicted, modules.TransactionPoolEventExpired:
+			w.log.Printf("unconfirmed transaction %v was %s from the transaction pool, releasing its inputs", pt.TransactionID, event.Type)
+			for _, ci := range pt.Transaction.CoinInputs {
+				delete(w.spentOutputs, types.OutputID(ci.ParentID))
+			}
+			for _, bsi := range pt.Transaction.BlockStakeInputs {
+				delete(w.spentOutputs, types.OutputID(bsi.ParentID))
+			}
+		}
+	}
+}
+
 // ReceiveUpdatedUnconfirmedTransactions updates the wallet's unconfirmed
 // transaction set.
 func (w *Wallet) ReceiveUpdatedUnconfirmedTransactions(txns []types.Transaction, _ modules.ConsensusChange) {
@@ -371,6 +450,10 @@ func (w *Wallet) ReceiveUpdatedUnconfirmedTransactions(txns []types.Transaction,
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
+	previous := make(map[types.TransactionID]struct{}, len(w.unconfirmedProcessedTransactions))
+	for _, pt := range w.unconfirmedProcessedTransactions {
+		previous[pt.TransactionID] = struct{}{}
+	}
 	w.unconfirmedProcessedTransactions = nil
 	for _, txn := range txns {
 		// To save on code complexity, relevancy is determined while building
@@ -394,6 +477,9 @@ func (w *Wallet) ReceiveUpdatedUnconfirmedTransactions(txns []types.Transaction,
 				relevant = true
 				// set "exists" to false since the output is not owned by the wallet.
 				exists = false
+			} else if w.isWatchedAddress(output.UnlockHash) {
+				// watched outputs are relevant, but not owned by the wallet
+				relevant = true
 			}
 			pt.Inputs = append(pt.Inputs, modules.ProcessedInput{
 				FundType:       types.SpecifierCoinInput,
@@ -413,6 +499,9 @@ func (w *Wallet) ReceiveUpdatedUnconfirmedTransactions(txns []types.Transaction,
 				relevant = true
 				// set "exists" to false since the output is not owned by the wallet.
 				exists = false
+			} else if w.isWatchedAddress(uh) {
+				// watched outputs are relevant, but not owned by the wallet
+				relevant = true
 			}
 			pt.Outputs = append(pt.Outputs, modules.ProcessedOutput{
 				FundType:       types.SpecifierCoinOutput,
@@ -430,4 +519,14 @@ func (w *Wallet) ReceiveUpdatedUnconfirmedTransactions(txns []types.Transaction,
 			w.unconfirmedProcessedTransactions = append(w.unconfirmedProcessedTransactions, pt)
 		}
 	}
+
+	var added []types.TransactionID
+	for _, pt := range w.unconfirmedProcessedTransactions {
+		if _, ok := previous[pt.TransactionID]; !ok {
+			added = append(added, pt.TransactionID)
+		}
+	}
+	if len(added) > 0 {
+		w.sendWalletEvent(modules.WalletEvent{Type: modules.WalletEventUnconfirmed, Transactions: added})
+	}
 }
@@ -91,6 +91,12 @@ type Wallet struct {
 	multiSigCoinOutputs       map[types.CoinOutputID]types.CoinOutput
 	multiSigBlockStakeOutputs map[types.BlockStakeOutputID]types.BlockStakeOutput
 
+	// watchedAddresses holds all addresses watched by the wallet,
+	// not owned by the wallet, with their outputs tracked in the watched output maps
+	watchedAddresses         map[types.UnlockHash]struct{}
+	watchedCoinOutputs       map[types.CoinOutputID]types.CoinOutput
+	watchedBlockStakeOutputs map[types.BlockStakeOutputID]types.BlockStakeOutput
+
 	// The following fields are kept to track transaction history.
 	// processedTransactions are stored in chronological order, and have a map for
 	// constant time random access. The set of full transactions is kept as
@@ -111,6 +117,11 @@ type Wallet struct {
 	// unnecessary. There's a better way to do it.
 	historicOutputs map[types.OutputID]historicOutput
 
+	// eventListeners receive the events of the wallet,
+	// no history events are sent while rescanning the blockchain.
+	eventListeners []modules.WalletEventListener
+	rescanning     bool
+
 	persistDir string
 	log        *persist.Logger
 	mu         sync.RWMutex
@@ -152,6 +163,9 @@ func New(cs modules.ConsensusSet, tpool modules.TransactionPool, persistDir stri
 		unspentblockstakeoutputs:  make(map[types.BlockStakeOutputID]types.UnspentBlockStakeOutput),
 		multiSigCoinOutputs:       make(map[types.CoinOutputID]types.CoinOutput),
 		multiSigBlockStakeOutputs: make(map[types.BlockStakeOutputID]types.BlockStakeOutput),
+		watchedAddresses:          make(map[types.UnlockHash]struct{}),
+		watchedCoinOutputs:        make(map[types.CoinOutputID]types.CoinOutput),
+		watchedBlockStakeOutputs:  make(map[types.BlockStakeOutputID]types.BlockStakeOutput),
 
 		processedTransactionMap: make(map[types.TransactionID]*modules.ProcessedTransaction),
 
@@ -239,6 +253,21 @@ func (w *Wallet) getKey(address types.UnlockHash) (types.PublicKey, types.ByteSl
 	}
 	return types.Ed25519PublicKey(sp.PublicKey), types.ByteSlice(sp.SecretKey[:]), nil
 }
+
+// SignMessage implements modules.Wallet.SignMessage
+func (w *Wallet) SignMessage(address types.UnlockHash, message []byte) (types.MessageSignature, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if !w.unlocked {
+		return types.MessageSignature{}, modules.ErrLockedWallet
+	}
+	sp, found := w.keys[address]
+	if !found {
+		return types.MessageSignature{}, errUnknownAddress
+	}
+	return types.SignMessage(message, sp.SecretKey), nil
+}
+
 func (w *Wallet) keyExists(address types.UnlockHash) (bool, error) {
 	if !w.unlocked {
 		return false, modules.ErrLockedWallet
@@ -297,6 +297,47 @@ func TestAllAddresses(t *testing.T) {
 	}
 }
 
+// TestSignMessage checks that the wallet signs messages
+// using the key of the given address only.
+func TestSignMessage(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	addr, err := wt.wallet.NextAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := []byte("hello rivine")
+	sig, err := wt.wallet.SignMessage(addr, message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = sig.Verify(addr, message); err != nil {
+		t.Error(err)
+	}
+
+	_, err = wt.wallet.SignMessage(types.NewUnlockHash(types.UnlockTypePubKey, crypto.Hash{1}), message)
+	if err != errUnknownAddress {
+		t.Error("expected errUnknownAddress, got:", err)
+	}
+
+	err = wt.wallet.Lock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wt.wallet.SignMessage(addr, message)
+	if err != modules.ErrLockedWallet {
+		t.Error("expected ErrLockedWallet, got:", err)
+	}
+}
+
 // TestCloseWallet tries to close the wallet.
 func TestCloseWallet(t *testing.T) {
 	if testing.Short() {
@@ -605,6 +646,14 @@ func (css *consensusSetStub) ConsensusSetSubscribe(subscriber modules.ConsensusS
 	return nil
 }
 
+func (css *consensusSetStub) ConsensusSetSubscribeAsync(subscriber modules.ConsensusSetSubscriber, changeID modules.ConsensusChangeID, cancel <-chan struct{}, _ modules.AsyncSubscribeOptions) error {
+	return css.ConsensusSetSubscribe(subscriber, changeID, cancel)
+}
+
+func (css *consensusSetStub) SubscriberStats() []modules.SubscriberStats {
+	return nil
+}
+
 func (css *consensusSetStub) Unsubscribe(subscriber modules.ConsensusSetSubscriber) {
 	delete(css.subscribers, subscriber)
 }
@@ -634,3 +683,78 @@ func (css *consensusSetStub) GetBlockStakeOutput(id types.BlockStakeOutputID) (b
 	}
 	return types.BlockStakeOutput{}, errors.New("BlockStake output not found in database")
 }
+
+func (css *consensusSetStub) UnspentOutputsByUnlockHash(types.UnlockHash) (modules.UnspentOutputs, error) {
+	return modules.UnspentOutputs{}, modules.ErrUnlockHashIndexDisabled
+}
+
+func (css *consensusSetStub) BlockStatistics(types.BlockHeight) (modules.BlockStatistics, error) {
+	return modules.BlockStatistics{}, errors.New("no block statistics available")
+}
+
+func (css *consensusSetStub) SyncProgress() modules.SyncProgress {
+	return modules.SyncProgress{Synced: true, Height: css.Height(), EstimatedNetworkHeight: css.Height(), Progress: 1}
+}
+
+type walletEventRecorder struct {
+	events []modules.WalletEvent
+}
+
+func (r *walletEventRecorder) ReceiveWalletEvent(event modules.WalletEvent) {
+	r.events = append(r.events, event)
+}
+
+// TestWalletEvents checks that the wallet sends events for
+// confirmed transactions and for locking and unlocking the wallet.
+func TestWalletEvents(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	cs := newConsensusSetStub()
+	wt, err := createWalletTesterWithStubCS(t.Name(), cs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	recorder := new(walletEventRecorder)
+	wt.wallet.WalletEventSubscribe(recorder)
+
+	addr, err := wt.wallet.NextAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cs.addTransactionAsBlock(addr, types.NewCurrency64(1000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = wt.wallet.Lock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = wt.wallet.Unlock(wt.walletMasterKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(recorder.events) != 3 {
+		t.Fatalf("unexpected events: %v", recorder.events)
+	}
+	confirmed := recorder.events[0]
+	txid := cs.blocks[len(cs.blocks)-1].Transactions[0].ID()
+	if confirmed.Type != modules.WalletEventConfirmed || len(confirmed.Transactions) != 1 || confirmed.Transactions[0] != txid {
+		t.Errorf("unexpected confirmed event: %v", confirmed)
+	}
+	if recorder.events[1].Type != modules.WalletEventLocked || recorder.events[2].Type != modules.WalletEventUnlocked {
+		t.Errorf("unexpected lock events: %v", recorder.events[1:])
+	}
+
+	wt.wallet.WalletEventUnsubscribe(recorder)
+	err = wt.wallet.Lock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recorder.events) != 3 {
+		t.Error("received an event after unsubscribing")
+	}
+}
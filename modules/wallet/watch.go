@@ -0,0 +1,151 @@
+package wallet
+
+import (
+	"errors"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+var (
+	// errWatchOwnedAddress is returned when trying to watch an address owned by the wallet.
+	errWatchOwnedAddress = errors.New("cannot watch an address owned by the wallet")
+	// errWatchNilAddress is returned when trying to watch the nil address.
+	errWatchNilAddress = errors.New("cannot watch the nil address")
+)
+
+// AddWatchedAddresses adds addresses, which are not owned by the wallet,
+// to be tracked by the wallet, such that their balance and transactions can be monitored.
+// The wallet rescans the blockchain if it has already been scanned.
+func (w *Wallet) AddWatchedAddresses(addresses []types.UnlockHash) error {
+	return w.updateWatchedAddresses(func() (bool, error) {
+		for _, uh := range addresses {
+			if uh.Type == types.UnlockTypeNil {
+				return false, errWatchNilAddress
+			}
+			if _, exists := w.keys[uh]; exists {
+				return false, errWatchOwnedAddress
+			}
+		}
+		updated := false
+		for _, uh := range addresses {
+			if _, exists := w.watchedAddresses[uh]; exists {
+				continue
+			}
+			w.watchedAddresses[uh] = struct{}{}
+			w.persist.WatchedAddresses = append(w.persist.WatchedAddresses, uh)
+			updated = true
+		}
+		return updated, nil
+	})
+}
+
+// RemoveWatchedAddresses stops tracking the given watched addresses.
+// The wallet rescans the blockchain if it has already been scanned.
+func (w *Wallet) RemoveWatchedAddresses(addresses []types.UnlockHash) error {
+	return w.updateWatchedAddresses(func() (bool, error) {
+		updated := false
+		for _, uh := range addresses {
+			if _, exists := w.watchedAddresses[uh]; !exists {
+				continue
+			}
+			delete(w.watchedAddresses, uh)
+			updated = true
+		}
+		if !updated {
+			return false, nil
+		}
+		watched := make([]types.UnlockHash, 0, len(w.watchedAddresses))
+		for _, uh := range w.persist.WatchedAddresses {
+			if _, exists := w.watchedAddresses[uh]; exists {
+				watched = append(watched, uh)
+			}
+		}
+		w.persist.WatchedAddresses = watched
+		return true, nil
+	})
+}
+
+// WatchedAddresses returns all addresses watched by the wallet,
+// together with their confirmed balances.
+func (w *Wallet) WatchedAddresses() ([]modules.WatchedAddress, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer w.tg.Done()
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	indices := make(map[types.UnlockHash]int, len(w.persist.WatchedAddresses))
+	addresses := make([]modules.WatchedAddress, 0, len(w.persist.WatchedAddresses))
+	for _, uh := range w.persist.WatchedAddresses {
+		indices[uh] = len(addresses)
+		addresses = append(addresses, modules.WatchedAddress{Address: uh})
+	}
+	for _, co := range w.watchedCoinOutputs {
+		if index, ok := indices[co.Condition.UnlockHash()]; ok {
+			addresses[index].ConfirmedCoinBalance = addresses[index].ConfirmedCoinBalance.Add(co.Value)
+		}
+	}
+	for _, bso := range w.watchedBlockStakeOutputs {
+		if index, ok := indices[bso.Condition.UnlockHash()]; ok {
+			addresses[index].ConfirmedBlockStakeBalance = addresses[index].ConfirmedBlockStakeBalance.Add(bso.Value)
+		}
+	}
+	return addresses, nil
+}
+
+// isWatchedAddress returns true if the given address is watched by the wallet.
+func (w *Wallet) isWatchedAddress(uh types.UnlockHash) bool {
+	_, exists := w.watchedAddresses[uh]
+	return exists
+}
+
+// updateWatchedAddresses updates the watched addresses using the given function,
+// saving the settings and rescanning the blockchain in case the watched addresses were updated.
+func (w *Wallet) updateWatchedAddresses(update func() (bool, error)) error {
+	if err := w.tg.Add(); err != nil {
+		return err
+	}
+	defer w.tg.Done()
+
+	w.mu.Lock()
+	updated, err := update()
+	if err == nil && updated {
+		err = w.saveSettingsSync()
+	}
+	subscribed := w.subscribed
+	w.mu.Unlock()
+	if err != nil || !updated || !subscribed {
+		return err
+	}
+	return w.rescan()
+}
+
+// rescan resets all state the wallet tracks from the consensus set,
+// and scans the consensus set again from the beginning,
+// such that changes to the tracked addresses apply to the entire blockchain.
+func (w *Wallet) rescan() error {
+	w.cs.Unsubscribe(w)
+
+	w.mu.Lock()
+	w.consensusSetHeight = 0
+	w.coinOutputs = make(map[types.CoinOutputID]types.CoinOutput)
+	w.blockstakeOutputs = make(map[types.BlockStakeOutputID]types.BlockStakeOutput)
+	w.unspentblockstakeoutputs = make(map[types.BlockStakeOutputID]types.UnspentBlockStakeOutput)
+	w.multiSigCoinOutputs = make(map[types.CoinOutputID]types.CoinOutput)
+	w.multiSigBlockStakeOutputs = make(map[types.BlockStakeOutputID]types.BlockStakeOutput)
+	w.watchedCoinOutputs = make(map[types.CoinOutputID]types.CoinOutput)
+	w.watchedBlockStakeOutputs = make(map[types.BlockStakeOutputID]types.BlockStakeOutput)
+	w.processedTransactions = nil
+	w.processedTransactionMap = make(map[types.TransactionID]*modules.ProcessedTransaction)
+	w.historicOutputs = make(map[types.OutputID]historicOutput)
+	w.rescanning = true
+	w.mu.Unlock()
+
+	err := w.cs.ConsensusSetSubscribe(w, modules.ConsensusChangeBeginning, w.tg.StopChan())
+	w.mu.Lock()
+	w.rescanning = false
+	w.mu.Unlock()
+	return err
+}
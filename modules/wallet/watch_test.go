@@ -0,0 +1,98 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/threefoldtech/rivine/types"
+)
+
+func TestWatchedAddresses(t *testing.T) {
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// watch the address receiving the genesis block stakes and coins
+	chainCts := types.TestnetChainConstants()
+	uh := chainCts.GenesisBlockStakeAllocation[0].Condition.UnlockHash()
+	err = wt.wallet.AddWatchedAddresses([]types.UnlockHash{uh})
+	if err != nil {
+		t.Fatal(err)
+	}
+	addresses, err := wt.wallet.WatchedAddresses()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(addresses) != 1 || addresses[0].Address != uh {
+		t.Fatal("unexpected watched addresses:", addresses)
+	}
+	var coins, blockStakes types.Currency
+	for _, co := range chainCts.GenesisCoinDistribution {
+		if co.Condition.UnlockHash() == uh {
+			coins = coins.Add(co.Value)
+		}
+	}
+	for _, bso := range chainCts.GenesisBlockStakeAllocation {
+		if bso.Condition.UnlockHash() == uh {
+			blockStakes = blockStakes.Add(bso.Value)
+		}
+	}
+	if !addresses[0].ConfirmedCoinBalance.Equals(coins) || !addresses[0].ConfirmedBlockStakeBalance.Equals(blockStakes) {
+		t.Fatalf("unexpected balance of watched address: %v coins and %v block stakes",
+			addresses[0].ConfirmedCoinBalance, addresses[0].ConfirmedBlockStakeBalance)
+	}
+
+	// the genesis transaction is part of the history of the watched address, but not of the wallet
+	pts, err := wt.wallet.AddressTransactions(uh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pts) == 0 {
+		t.Fatal("expected the history of the watched address to be tracked")
+	}
+	for _, output := range pts[0].Outputs {
+		if output.WalletAddress {
+			t.Fatal("watched output is marked as owned by the wallet")
+		}
+	}
+	coinBalance, blockStakeBalance, err := wt.wallet.ConfirmedBalance()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !coinBalance.IsZero() || !blockStakeBalance.IsZero() {
+		t.Fatal("watched outputs are part of the wallet balance")
+	}
+
+	// owned and nil addresses cannot be watched
+	owned, err := wt.wallet.NextAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = wt.wallet.AddWatchedAddresses([]types.UnlockHash{owned}); err != errWatchOwnedAddress {
+		t.Fatal("expected owned address to be refused, not:", err)
+	}
+	if err = wt.wallet.AddWatchedAddresses([]types.UnlockHash{types.NilUnlockHash}); err != errWatchNilAddress {
+		t.Fatal("expected nil address to be refused, not:", err)
+	}
+
+	// removing the address removes its history as well
+	err = wt.wallet.RemoveWatchedAddresses([]types.UnlockHash{uh})
+	if err != nil {
+		t.Fatal(err)
+	}
+	addresses, err = wt.wallet.WatchedAddresses()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(addresses) != 0 {
+		t.Fatal("unexpected watched addresses:", addresses)
+	}
+	pts, err = wt.wallet.AddressTransactions(uh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pts) != 0 {
+		t.Fatal("expected the history of the removed address to be gone")
+	}
+}
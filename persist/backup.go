@@ -0,0 +1,137 @@
+package persist
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/threefoldtech/rivine/build"
+
+	"github.com/rivine/bbolt"
+)
+
+// BackupWriter writes the files of a backup, taken while the daemon is running,
+// see NewDirBackupWriter and NewTarBackupWriter.
+type BackupWriter interface {
+	// WriteFile writes a file of the given size, of which the content is written
+	// by the given function. The name is slash-separated and relative to the root of the backup.
+	WriteFile(name string, size int64, write func(io.Writer) error) error
+}
+
+// Backup writes a consistent, point-in-time copy of the database,
+// using a read transaction, such that the database can be written to meanwhile.
+func (db *BoltDatabase) Backup(bw BackupWriter, name string) error {
+	return db.View(func(tx *bolt.Tx) error {
+		return bw.WriteFile(name, tx.Size(), func(w io.Writer) error {
+			_, err := tx.WriteTo(w)
+			return err
+		})
+	})
+}
+
+// BackupJSON writes the given object, in the format of the files saved by SaveJSON.
+func BackupJSON(bw BackupWriter, name string, meta Metadata, object interface{}) error {
+	data, err := marshalJSON(meta, object)
+	if err != nil {
+		return err
+	}
+	return bw.WriteFile(name, int64(len(data)), func(w io.Writer) error {
+		_, err := io.Copy(w, bytes.NewReader(data))
+		return err
+	})
+}
+
+// backupFilePath validates the given slash-separated name of a backup file,
+// returning it as a relative path.
+func backupFilePath(name string) (string, error) {
+	clean := path.Clean(name)
+	if clean != name || path.IsAbs(name) || clean == "." || strings.HasPrefix(clean, "../") || clean == ".." {
+		return "", fmt.Errorf("invalid backup file name %q", name)
+	}
+	return filepath.FromSlash(clean), nil
+}
+
+// dirBackupWriter writes the files of a backup to a directory.
+type dirBackupWriter struct {
+	dir string
+}
+
+// NewDirBackupWriter creates a BackupWriter writing the files of a backup to the given directory,
+// which is created if it doesn't exist yet. Existing files are overwritten.
+func NewDirBackupWriter(dir string) BackupWriter {
+	return dirBackupWriter{dir: dir}
+}
+
+// WriteFile implements BackupWriter.WriteFile,
+// writing the file to a temporary file first, such that a file is only ever complete.
+func (bw dirBackupWriter) WriteFile(name string, size int64, write func(io.Writer) error) error {
+	rel, err := backupFilePath(name)
+	if err != nil {
+		return err
+	}
+	filename := filepath.Join(bw.dir, rel)
+	err = os.MkdirAll(filepath.Dir(filename), 0700)
+	if err != nil {
+		return err
+	}
+	err = func() (err error) {
+		file, err := os.OpenFile(filename+tempSuffix, os.O_RDWR|os.O_TRUNC|os.O_CREATE, 0600)
+		if err != nil {
+			return build.ExtendErr("unable to open temp file", err)
+		}
+		defer func() {
+			err = build.ComposeErrors(err, file.Close())
+		}()
+		err = write(file)
+		if err != nil {
+			return build.ExtendErr("unable to write temp file", err)
+		}
+		return file.Sync()
+	}()
+	if err != nil {
+		os.Remove(filename + tempSuffix)
+		return err
+	}
+	return os.Rename(filename+tempSuffix, filename)
+}
+
+// TarBackupWriter writes the files of a backup as a tar stream.
+type TarBackupWriter struct {
+	tw *tar.Writer
+}
+
+// NewTarBackupWriter creates a BackupWriter writing the files of a backup
+// as a tar stream to the given writer. Close has to be called to complete the stream.
+func NewTarBackupWriter(w io.Writer) *TarBackupWriter {
+	return &TarBackupWriter{tw: tar.NewWriter(w)}
+}
+
+// WriteFile implements BackupWriter.WriteFile
+func (bw *TarBackupWriter) WriteFile(name string, size int64, write func(io.Writer) error) error {
+	_, err := backupFilePath(name)
+	if err != nil {
+		return err
+	}
+	err = bw.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     size,
+		Mode:     0600,
+		ModTime:  time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	return write(bw.tw)
+}
+
+// Close completes the tar stream, without closing the underlying writer.
+func (bw *TarBackupWriter) Close() error {
+	return bw.tw.Close()
+}
@@ -0,0 +1,132 @@
+package persist
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/threefoldtech/rivine/build"
+
+	"github.com/rivine/bbolt"
+)
+
+// TestBackup backs up a database and a JSON object, while the database is open,
+// to both a directory and a tar stream, and checks that the backups can be loaded.
+func TestBackup(t *testing.T) {
+	dir := build.TempDir(persistDir, t.Name())
+	os.RemoveAll(dir)
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	meta := Metadata{"Test Backup", "1.0.0"}
+	db, err := OpenDatabase(meta, filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	err = db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucket([]byte("bucket"))
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte("key"), []byte("value"))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	type testStruct struct {
+		Value string
+	}
+
+	// back up to a directory
+	backupDir := filepath.Join(dir, "backup")
+	bw := NewDirBackupWriter(backupDir)
+	if err = db.Backup(bw, "module/test.db"); err != nil {
+		t.Fatal(err)
+	}
+	if err = BackupJSON(bw, "module/settings.json", meta, testStruct{"foo"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// back up as a tar stream, equal to the backup in the directory
+	var buf bytes.Buffer
+	tbw := NewTarBackupWriter(&buf)
+	if err = db.Backup(tbw, "module/test.db"); err != nil {
+		t.Fatal(err)
+	}
+	if err = BackupJSON(tbw, "module/settings.json", meta, testStruct{"foo"}); err != nil {
+		t.Fatal(err)
+	}
+	if err = tbw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(&buf)
+	for _, name := range []string{"module/test.db", "module/settings.json"} {
+		hdr, err := tr.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Name != name {
+			t.Fatalf("expected file %q, got %q", name, hdr.Name)
+		}
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, err := ioutil.ReadFile(filepath.Join(backupDir, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, expected) {
+			t.Errorf("%s: tar content differs from the directory backup", name)
+		}
+	}
+	if _, err = tr.Next(); err != io.EOF {
+		t.Fatal("expected the end of the archive, got", err)
+	}
+
+	// the backups can be loaded
+	var obj testStruct
+	if err = LoadJSON(meta, &obj, filepath.Join(backupDir, "module", "settings.json")); err != nil {
+		t.Fatal(err)
+	}
+	if obj.Value != "foo" {
+		t.Errorf("unexpected object %+v", obj)
+	}
+	backupDB, err := OpenDatabase(meta, filepath.Join(backupDir, "module", "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = backupDB.View(func(tx *bolt.Tx) error {
+		if value := tx.Bucket([]byte("bucket")).Get([]byte("key")); string(value) != "value" {
+			t.Errorf("unexpected value %q", value)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	backupDB.Close()
+	if _, err = os.Stat(filepath.Join(backupDir, "module", "test.db"+tempSuffix)); !os.IsNotExist(err) {
+		t.Error("temp file was not removed:", err)
+	}
+}
+
+// TestBackupInvalidNames checks that files can't be written outside of the backup.
+func TestBackupInvalidNames(t *testing.T) {
+	dir := build.TempDir(persistDir, t.Name())
+	for _, name := range []string{"", ".", "..", "../foo", "/foo", "foo/../../bar", "foo//bar"} {
+		for _, bw := range []BackupWriter{NewDirBackupWriter(dir), NewTarBackupWriter(ioutil.Discard)} {
+			err := BackupJSON(bw, name, Metadata{"Test Backup", "1.0.0"}, struct{}{})
+			if err == nil {
+				t.Errorf("%T: expected name %q to be invalid", bw, name)
+			}
+		}
+	}
+}
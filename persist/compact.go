@@ -0,0 +1,183 @@
+package persist
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rivine/bbolt"
+)
+
+// compactTxMaxSize is the maximum amount of bytes copied
+// within a single transaction while compacting a database,
+// such that a large database can be compacted using a limited amount of memory.
+const compactTxMaxSize = 64 << 20
+
+// DatabaseCompaction reports the result of compacting a database.
+type DatabaseCompaction struct {
+	// SizeBefore is the size of the database file prior to compacting it.
+	SizeBefore int64
+	// SizeAfter is the size of the database file after compacting it.
+	SizeAfter int64
+}
+
+// CompactDatabase compacts the bolt database with the given filename,
+// which cannot be opened meanwhile, by copying all of its buckets into a new database,
+// filling its pages completely, such that the space of the pages freed over time is reclaimed.
+// The compacted database replaces the original one only once it is complete.
+// Nothing is done in case the database does not exist.
+func CompactDatabase(filename string) (DatabaseCompaction, error) {
+	stat, err := os.Stat(filename)
+	if os.IsNotExist(err) {
+		return DatabaseCompaction{}, nil // nothing to compact
+	}
+	if err != nil {
+		return DatabaseCompaction{}, err
+	}
+	result := DatabaseCompaction{SizeBefore: stat.Size()}
+
+	src, err := bolt.Open(filename, 0600, &bolt.Options{Timeout: 3 * time.Second, ReadOnly: true})
+	if err != nil {
+		return DatabaseCompaction{}, err
+	}
+	defer src.Close()
+
+	tmpFilename := filename + tempSuffix
+	os.Remove(tmpFilename) // a left-over of an interrupted compaction
+	dst, err := bolt.Open(tmpFilename, stat.Mode(), &bolt.Options{Timeout: 3 * time.Second})
+	if err != nil {
+		return DatabaseCompaction{}, err
+	}
+	err = compactDatabase(dst, src)
+	if err == nil {
+		err = dst.Close()
+	} else {
+		dst.Close()
+	}
+	if err != nil {
+		os.Remove(tmpFilename)
+		return DatabaseCompaction{}, err
+	}
+	err = os.Rename(tmpFilename, filename)
+	if err != nil {
+		return DatabaseCompaction{}, err
+	}
+	if stat, err = os.Stat(filename); err != nil {
+		return DatabaseCompaction{}, err
+	}
+	result.SizeAfter = stat.Size()
+	return result, nil
+}
+
+// compactDatabase copies all buckets of the source database into the destination database,
+// committing every compactTxMaxSize bytes.
+func compactDatabase(dst, src *bolt.DB) error {
+	tx, err := dst.Begin(true)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if tx != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var size int64
+	err = src.View(func(srcTx *bolt.Tx) error {
+		return walkDatabase(srcTx, func(path [][]byte, k, v []byte, seq uint64) error {
+			// commit once the transaction has grown too large
+			if sz := int64(len(k) + len(v)); size+sz > compactTxMaxSize && size > 0 {
+				if err := tx.Commit(); err != nil {
+					tx = nil
+					return err
+				}
+				tx, err = dst.Begin(true)
+				if err != nil {
+					tx = nil
+					return err
+				}
+				size = 0
+			}
+			size += int64(len(k) + len(v))
+
+			if len(path) == 0 {
+				// root bucket
+				b, err := tx.CreateBucket(k)
+				if err != nil {
+					return err
+				}
+				return b.SetSequence(seq)
+			}
+			b := tx.Bucket(path[0])
+			for _, name := range path[1:] {
+				b = b.Bucket(name)
+			}
+			// the pages are only appended to, fill them completely
+			b.FillPercent = 1.0
+			if v == nil {
+				// nested bucket
+				nb, err := b.CreateBucket(k)
+				if err != nil {
+					return err
+				}
+				return nb.SetSequence(seq)
+			}
+			return b.Put(k, v)
+		})
+	})
+	if err != nil {
+		return err
+	}
+	err = tx.Commit()
+	tx = nil
+	return err
+}
+
+// walkDatabase calls the given function for every bucket and key/value pair of the database,
+// with the path of the bucket containing it, and the sequence of the bucket.
+// The value is nil for a bucket.
+func walkDatabase(tx *bolt.Tx, fn func(path [][]byte, k, v []byte, seq uint64) error) error {
+	return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+		return walkBucket(b, nil, name, nil, b.Sequence(), fn)
+	})
+}
+
+func walkBucket(b *bolt.Bucket, path [][]byte, k, v []byte, seq uint64, fn func(path [][]byte, k, v []byte, seq uint64) error) error {
+	err := fn(path, k, v, seq)
+	if err != nil || v != nil {
+		return err
+	}
+	// walk the content of the bucket
+	path = append(path[:len(path):len(path)], k)
+	return b.ForEach(func(k, v []byte) error {
+		if v == nil {
+			nb := b.Bucket(k)
+			return walkBucket(nb, path, k, nil, nb.Sequence(), fn)
+		}
+		return walkBucket(b, path, k, v, b.Sequence(), fn)
+	})
+}
+
+// DatabaseMigrationBackups returns the filenames of the backups
+// made of the database with the given filename prior to migrating it,
+// named as defined by MigrationBackupFilename.
+func DatabaseMigrationBackups(filename string) ([]string, error) {
+	dir, base := filepath.Dir(filename), filepath.Base(filename)
+	infos, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var backups []string
+	for _, info := range infos {
+		name := info.Name()
+		if !info.IsDir() && strings.HasPrefix(name, base+".v") && strings.HasSuffix(name, ".bak") {
+			backups = append(backups, filepath.Join(dir, name))
+		}
+	}
+	return backups, nil
+}
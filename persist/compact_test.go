@@ -0,0 +1,132 @@
+package persist
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/threefoldtech/rivine/build"
+
+	"github.com/rivine/bbolt"
+)
+
+// TestCompactDatabase compacts a database of which most data was deleted,
+// and checks that its remaining content is preserved.
+func TestCompactDatabase(t *testing.T) {
+	dir := build.TempDir(persistDir, t.Name())
+	os.RemoveAll(dir)
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta := Metadata{"Test Compact", "1.0.0"}
+	filename := filepath.Join(dir, "test.db")
+	db, err := OpenDatabase(meta, filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucket([]byte("bucket"))
+		if err != nil {
+			return err
+		}
+		if err = b.SetSequence(42); err != nil {
+			return err
+		}
+		nested, err := b.CreateBucket([]byte("nested"))
+		if err != nil {
+			return err
+		}
+		if err = nested.Put([]byte("key"), []byte("value")); err != nil {
+			return err
+		}
+		large, err := tx.CreateBucket([]byte("large"))
+		if err != nil {
+			return err
+		}
+		for i := 0; i < 1000; i++ {
+			if err = large.Put([]byte(fmt.Sprint(i)), bytes.Repeat([]byte{byte(i)}, 1024)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		return tx.DeleteBucket([]byte("large"))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	compaction, err := CompactDatabase(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if compaction.SizeAfter >= compaction.SizeBefore {
+		t.Errorf("expected the database to shrink, got %+v", compaction)
+	}
+	if _, err = os.Stat(filename + tempSuffix); !os.IsNotExist(err) {
+		t.Error("temp file was not removed:", err)
+	}
+
+	db, err = OpenDatabase(meta, filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	err = db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("bucket"))
+		if b == nil || b.Sequence() != 42 {
+			t.Fatal("bucket or its sequence was not preserved")
+		}
+		if value := b.Bucket([]byte("nested")).Get([]byte("key")); string(value) != "value" {
+			t.Errorf("unexpected value %q", value)
+		}
+		if tx.Bucket([]byte("large")) != nil {
+			t.Error("deleted bucket was restored")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// nothing is done for a database which does not exist
+	if compaction, err = CompactDatabase(filepath.Join(dir, "unknown.db")); err != nil || compaction != (DatabaseCompaction{}) {
+		t.Errorf("unexpected compaction of an unknown database: %+v, %v", compaction, err)
+	}
+}
+
+func TestDatabaseMigrationBackups(t *testing.T) {
+	dir := build.TempDir(persistDir, t.Name())
+	os.RemoveAll(dir)
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(dir, "test.db")
+	for _, name := range []string{
+		"test.db", MigrationBackupFilename("test.db", "1.0.0"), MigrationBackupFilename("test.db", "1.1.0"),
+		MigrationBackupFilename("other.db", "1.0.0"),
+	} {
+		if err = ioutil.WriteFile(filepath.Join(dir, name), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	backups, err := DatabaseMigrationBackups(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 2 || backups[0] != MigrationBackupFilename(filename, "1.0.0") || backups[1] != MigrationBackupFilename(filename, "1.1.0") {
+		t.Errorf("unexpected backups %v", backups)
+	}
+}
@@ -0,0 +1,266 @@
+package persist
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/rivine/bbolt"
+)
+
+// DatabaseBucket describes a (top-level) bucket of a bolt database, as listed by InspectDatabaseBuckets.
+type DatabaseBucket struct {
+	// Name is the name of the bucket, see DatabaseName.
+	Name string `json:"name"`
+	// Keys is the amount of keys of the bucket, including the keys of its nested buckets.
+	Keys int `json:"keys"`
+	// Buckets is the amount of nested buckets of the bucket, including the buckets nested in those.
+	Buckets int `json:"buckets"`
+}
+
+// DatabaseEntry is a key of a bucket of a bolt database, as listed by InspectDatabaseEntries.
+type DatabaseEntry struct {
+	// Key is the hex-encoded key, KeyText the key as text if it is printable.
+	Key     string `json:"key"`
+	KeyText string `json:"keytext,omitempty"`
+	// Bucket is true if the key is a nested bucket, which has no value.
+	Bucket bool `json:"bucket,omitempty"`
+	// Value is the hex-encoded value, truncated to the maximum value size of the query,
+	// ValueSize the size of the full value in bytes.
+	Value     string `json:"value,omitempty"`
+	ValueSize int    `json:"valuesize"`
+	Truncated bool   `json:"truncated,omitempty"`
+	// Decoded is the decoded value, on a best-effort basis, omitted if it could not be decoded.
+	Decoded interface{} `json:"decoded,omitempty"`
+}
+
+// DatabaseEntriesQuery defines the entries listed by InspectDatabaseEntries.
+type DatabaseEntriesQuery struct {
+	// Bucket is the path of the (nested) bucket of which the entries are listed.
+	Bucket [][]byte
+	// Prefix limits the entries to the keys starting with it.
+	Prefix []byte
+	// After lists the entries following this key, or preceding it if Reverse is true,
+	// all entries are listed from the start (or end) if it is nil.
+	After   []byte
+	Reverse bool
+	// Limit is the maximum amount of entries listed, unlimited if 0.
+	Limit int
+	// MaxValueSize is the maximum size of the values returned,
+	// larger values are truncated, unlimited if 0.
+	MaxValueSize int
+}
+
+// DatabaseValueDecodeFunc decodes the value of a key of the (nested) bucket of the given path,
+// returning false if it does not know how to decode it.
+type DatabaseValueDecodeFunc func(bucket [][]byte, key, value []byte) (interface{}, bool)
+
+// ErrDatabaseBucketNotFound is returned when inspecting a bucket which does not exist.
+var ErrDatabaseBucketNotFound = errors.New("database bucket not found")
+
+// DatabaseName returns a bucket name or key as text if it is printable,
+// and hex-encoded prefixed by 0x otherwise, see ParseDatabaseName.
+func DatabaseName(b []byte) string {
+	if isPrintable(b) && !strings.HasPrefix(string(b), "0x") && !strings.ContainsRune(string(b), '/') {
+		return string(b)
+	}
+	return "0x" + hex.EncodeToString(b)
+}
+
+// ParseDatabaseName parses a name as returned by DatabaseName.
+func ParseDatabaseName(s string) ([]byte, error) {
+	if strings.HasPrefix(s, "0x") {
+		b, err := hex.DecodeString(s[2:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid hex-encoded name %q: %v", s, err)
+		}
+		return b, nil
+	}
+	if s == "" {
+		return nil, errors.New("empty name")
+	}
+	return []byte(s), nil
+}
+
+// ParseDatabaseBucketPath parses the slash-separated path of a (nested) bucket,
+// of which each name is formatted as returned by DatabaseName.
+func ParseDatabaseBucketPath(s string) ([][]byte, error) {
+	var path [][]byte
+	for _, name := range strings.Split(s, "/") {
+		b, err := ParseDatabaseName(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bucket path %q: %v", s, err)
+		}
+		path = append(path, b)
+	}
+	return path, nil
+}
+
+// isPrintable returns true if the given bytes are non-empty printable UTF-8 text.
+func isPrintable(b []byte) bool {
+	if len(b) == 0 || !utf8.Valid(b) {
+		return false
+	}
+	for _, r := range string(b) {
+		if !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// InspectDatabaseBuckets lists the top-level buckets of the database, within the given (read-only) transaction.
+// The nested buckets are listed as entries of their parent bucket, see InspectDatabaseEntries.
+func InspectDatabaseBuckets(tx *bolt.Tx) []DatabaseBucket {
+	var buckets []DatabaseBucket
+	tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+		stats := b.Stats()
+		buckets = append(buckets, DatabaseBucket{
+			Name: DatabaseName(name),
+			Keys: stats.KeyN,
+			// the bucket itself is counted as well
+			Buckets: stats.BucketN - 1,
+		})
+		return nil
+	})
+	return buckets
+}
+
+// InspectDatabaseEntries lists the entries of a bucket of the database, as defined by the given query,
+// within the given (read-only) transaction. The values are decoded using the given function, if any,
+// falling back to a generic decoding of JSON, text and 8-byte integers. It returns the key of the last
+// listed entry, to be used as After of the query listing the next entries, or nil if all entries are listed.
+func InspectDatabaseEntries(tx *bolt.Tx, query DatabaseEntriesQuery, decode DatabaseValueDecodeFunc) ([]DatabaseEntry, []byte, error) {
+	if len(query.Bucket) == 0 {
+		return nil, nil, errors.New("no bucket given")
+	}
+	b := tx.Bucket(query.Bucket[0])
+	for _, name := range query.Bucket[1:] {
+		if b == nil {
+			break
+		}
+		b = b.Bucket(name)
+	}
+	if b == nil {
+		return nil, nil, ErrDatabaseBucketNotFound
+	}
+
+	c := b.Cursor()
+	var k, v []byte
+	switch {
+	case query.After != nil:
+		k, v = c.Seek(query.After)
+		if query.Reverse {
+			if k == nil {
+				k, v = c.Last()
+			}
+			if k != nil && bytes.Compare(k, query.After) >= 0 {
+				k, v = c.Prev()
+			}
+		} else if bytes.Equal(k, query.After) {
+			k, v = c.Next()
+		}
+	case query.Reverse && len(query.Prefix) > 0:
+		// seek the last key with the prefix
+		k, v = c.Seek(prefixUpperBound(query.Prefix))
+		if k == nil {
+			k, v = c.Last()
+		} else {
+			k, v = c.Prev()
+		}
+		for k != nil && bytes.Compare(k, query.Prefix) >= 0 && !bytes.HasPrefix(k, query.Prefix) {
+			k, v = c.Prev()
+		}
+	case query.Reverse:
+		k, v = c.Last()
+	default:
+		k, v = c.Seek(query.Prefix)
+	}
+	next := func() ([]byte, []byte) {
+		if query.Reverse {
+			return c.Prev()
+		}
+		return c.Next()
+	}
+
+	var entries []DatabaseEntry
+	for ; k != nil; k, v = next() {
+		if !bytes.HasPrefix(k, query.Prefix) {
+			if query.Reverse && bytes.Compare(k, query.Prefix) > 0 {
+				continue // a key following the prefix, when starting after a given key
+			}
+			break
+		}
+		if query.Limit > 0 && len(entries) >= query.Limit {
+			return entries, entries[len(entries)-1].rawKey(), nil
+		}
+		entries = append(entries, newDatabaseEntry(query, k, v, decode))
+	}
+	return entries, nil, nil
+}
+
+// rawKey returns the decoded key of the entry.
+func (entry DatabaseEntry) rawKey() []byte {
+	b, _ := hex.DecodeString(entry.Key)
+	return b
+}
+
+// newDatabaseEntry creates the entry of the given key and value.
+func newDatabaseEntry(query DatabaseEntriesQuery, k, v []byte, decode DatabaseValueDecodeFunc) DatabaseEntry {
+	entry := DatabaseEntry{Key: hex.EncodeToString(k)}
+	if isPrintable(k) {
+		entry.KeyText = string(k)
+	}
+	if v == nil {
+		entry.Bucket = true
+		return entry
+	}
+	entry.ValueSize = len(v)
+	value := v
+	if query.MaxValueSize > 0 && len(value) > query.MaxValueSize {
+		value, entry.Truncated = value[:query.MaxValueSize], true
+	}
+	entry.Value = hex.EncodeToString(value)
+	if decode != nil {
+		if decoded, ok := decode(query.Bucket, k, v); ok {
+			entry.Decoded = decoded
+			return entry
+		}
+	}
+	entry.Decoded = decodeDatabaseValue(v)
+	return entry
+}
+
+// decodeDatabaseValue decodes a value without knowing its type, on a best-effort basis,
+// as JSON, text or a (little-endian) 8-byte integer.
+func decodeDatabaseValue(v []byte) interface{} {
+	switch {
+	case json.Valid(v) && (v[0] == '{' || v[0] == '['):
+		return json.RawMessage(v)
+	case isPrintable(v):
+		return string(v)
+	case len(v) == 8:
+		return binary.LittleEndian.Uint64(v)
+	default:
+		return nil
+	}
+}
+
+// prefixUpperBound returns the smallest key following all keys with the given prefix,
+// or nil if there is no such key.
+func prefixUpperBound(prefix []byte) []byte {
+	upper := append([]byte{}, prefix...)
+	for i := len(upper) - 1; i >= 0; i-- {
+		if upper[i] < 0xff {
+			upper[i]++
+			return upper[:i+1]
+		}
+	}
+	return nil
+}
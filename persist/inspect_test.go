@@ -0,0 +1,168 @@
+package persist
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/threefoldtech/rivine/build"
+
+	"github.com/rivine/bbolt"
+)
+
+// TestInspectDatabase lists the buckets and pages through the entries of a database,
+// in both orders, limited to a prefix, checking that the values are decoded.
+func TestInspectDatabase(t *testing.T) {
+	dir := build.TempDir(persistDir, t.Name())
+	os.RemoveAll(dir)
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := OpenDatabase(Metadata{"Test Inspect", "1.0.0"}, filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	height := make([]byte, 8)
+	binary.LittleEndian.PutUint64(height, 42)
+	err = db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucket([]byte("bucket"))
+		if err != nil {
+			return err
+		}
+		for _, k := range []string{"a1", "a2", "a3", "b1", "c1"} {
+			if err = b.Put([]byte(k), []byte("value "+k)); err != nil {
+				return err
+			}
+		}
+		if err = b.Put([]byte{0, 1}, height); err != nil {
+			return err
+		}
+		nested, err := b.CreateBucket([]byte{0xff})
+		if err != nil {
+			return err
+		}
+		return nested.Put([]byte("json"), []byte(`{"foo":"bar"}`))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buckets []DatabaseBucket
+	db.View(func(tx *bolt.Tx) error {
+		buckets = InspectDatabaseBuckets(tx)
+		return nil
+	})
+	found := false
+	for _, b := range buckets {
+		if b.Name == "bucket" {
+			found = true
+			if b.Keys != 8 || b.Buckets != 1 {
+				t.Errorf("unexpected bucket stats: %+v", b)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("bucket not listed: %+v", buckets)
+	}
+
+	// list applies the query, paging through all entries, and returns their keys
+	list := func(query DatabaseEntriesQuery, decode DatabaseValueDecodeFunc) (keys []string, entries []DatabaseEntry) {
+		for {
+			var (
+				page []DatabaseEntry
+				next []byte
+			)
+			err := db.View(func(tx *bolt.Tx) (err error) {
+				page, next, err = InspectDatabaseEntries(tx, query, decode)
+				return err
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, entry := range page {
+				keys = append(keys, DatabaseName(entry.rawKey()))
+			}
+			entries = append(entries, page...)
+			if next == nil {
+				return keys, entries
+			}
+			query.After = next
+		}
+	}
+	bucket, err := ParseDatabaseBucketPath("bucket")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		query    DatabaseEntriesQuery
+		expected []string
+	}{
+		{DatabaseEntriesQuery{Bucket: bucket, Limit: 2}, []string{"0x0001", "a1", "a2", "a3", "b1", "c1", "0xff"}},
+		{DatabaseEntriesQuery{Bucket: bucket, Limit: 2, Reverse: true}, []string{"0xff", "c1", "b1", "a3", "a2", "a1", "0x0001"}},
+		{DatabaseEntriesQuery{Bucket: bucket, Limit: 2, Prefix: []byte("a")}, []string{"a1", "a2", "a3"}},
+		{DatabaseEntriesQuery{Bucket: bucket, Limit: 2, Prefix: []byte("a"), Reverse: true}, []string{"a3", "a2", "a1"}},
+		{DatabaseEntriesQuery{Bucket: bucket, Prefix: []byte("d")}, nil},
+	} {
+		keys, _ := list(tc.query, nil)
+		if len(keys) != len(tc.expected) {
+			t.Errorf("%+v: expected %v, got %v", tc.query, tc.expected, keys)
+			continue
+		}
+		for i := range keys {
+			if keys[i] != tc.expected[i] {
+				t.Errorf("%+v: expected %v, got %v", tc.query, tc.expected, keys)
+				break
+			}
+		}
+	}
+
+	// values are decoded generically, unless the decode function knows them
+	_, entries := list(DatabaseEntriesQuery{Bucket: bucket, MaxValueSize: 4}, func(bucket [][]byte, key, value []byte) (interface{}, bool) {
+		if bytes.Equal(key, []byte("c1")) {
+			return "decoded", true
+		}
+		return nil, false
+	})
+	if entries[0].Decoded != uint64(42) || entries[1].Decoded != "value a1" || entries[5].Decoded != "decoded" {
+		t.Errorf("unexpected decoded values: %+v", entries)
+	}
+	if !entries[1].Truncated || entries[1].Value != "76616c75" || entries[1].ValueSize != 8 {
+		t.Errorf("expected the value to be truncated: %+v", entries[1])
+	}
+	if !entries[6].Bucket || entries[6].Value != "" {
+		t.Errorf("expected a nested bucket: %+v", entries[6])
+	}
+	nested, err := ParseDatabaseBucketPath("bucket/0xff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, entries = list(DatabaseEntriesQuery{Bucket: nested}, nil); len(entries) != 1 || entries[0].KeyText != "json" {
+		t.Fatalf("unexpected entries of the nested bucket: %+v", entries)
+	}
+
+	err = db.View(func(tx *bolt.Tx) error {
+		_, _, err := InspectDatabaseEntries(tx, DatabaseEntriesQuery{Bucket: [][]byte{[]byte("foo")}}, nil)
+		return err
+	})
+	if err != ErrDatabaseBucketNotFound {
+		t.Fatalf("expected the bucket not to be found: %v", err)
+	}
+}
+
+// TestDatabaseName checks that the names of buckets and keys can be parsed back.
+func TestDatabaseName(t *testing.T) {
+	for _, name := range [][]byte{[]byte("BlockMap"), {0, 1, 2}, []byte("0xfoo"), []byte("a/b"), []byte("dco_\x00\x00")} {
+		str := DatabaseName(name)
+		parsed, err := ParseDatabaseName(str)
+		if err != nil || !bytes.Equal(parsed, name) {
+			t.Errorf("%q: failed to parse %q: %v", name, str, err)
+		}
+	}
+	if _, err := ParseDatabaseBucketPath("foo//bar"); err == nil {
+		t.Error("expected an empty name to be refused")
+	}
+}
@@ -128,6 +128,33 @@ func LoadJSON(meta Metadata, object interface{}, filename string) error {
 	return nil
 }
 
+// marshalJSON encodes the metadata, checksum and object,
+// in the format of the files saved by SaveJSON.
+func marshalJSON(meta Metadata, object interface{}) ([]byte, error) {
+	// Write the metadata to the buffer.
+	buf := new(bytes.Buffer)
+	enc := json.NewEncoder(buf)
+	if err := enc.Encode(meta.Header); err != nil {
+		return nil, build.ExtendErr("unable to encode metadata header", err)
+	}
+	if err := enc.Encode(meta.Version); err != nil {
+		return nil, build.ExtendErr("unable to encode metadata version", err)
+	}
+
+	// Marshal the object into json and write the checksum + result to the
+	// buffer.
+	objBytes, err := json.MarshalIndent(object, "", "\t")
+	if err != nil {
+		return nil, build.ExtendErr("unable to marshal the provided object", err)
+	}
+	checksum := crypto.HashBytes(objBytes)
+	if err := enc.Encode(checksum); err != nil {
+		return nil, build.ExtendErr("unable to encode checksum", err)
+	}
+	buf.Write(objBytes)
+	return buf.Bytes(), nil
+}
+
 // SaveJSON will save a json object to disk in a durable, atomic way. The
 // resulting file will have a checksum of the data as the third line. If
 // manually editing files, the checksum line can be replaced with the 8
@@ -162,30 +189,12 @@ func SaveJSON(meta Metadata, object interface{}, filename string) error {
 		activeFilesMu.Unlock()
 	}()
 
-	// Write the metadata to the buffer.
-	buf := new(bytes.Buffer)
-	enc := json.NewEncoder(buf)
-	if err := enc.Encode(meta.Header); err != nil {
-		return build.ExtendErr("unable to encode metadata header", err)
-	}
-	if err := enc.Encode(meta.Version); err != nil {
-		return build.ExtendErr("unable to encode metadata version", err)
-	}
-
-	// Marshal the object into json and write the checksum + result to the
-	// buffer.
-	objBytes, err := json.MarshalIndent(object, "", "\t")
+	data, err := marshalJSON(meta, object)
 	if err != nil {
-		return build.ExtendErr("unable to marshal the provided object", err)
-	}
-	checksum := crypto.HashBytes(objBytes)
-	if err := enc.Encode(checksum); err != nil {
-		return build.ExtendErr("unable to encode checksum", err)
+		return err
 	}
-	buf.Write(objBytes)
 
 	// Write out the data to the temp file, with a sync.
-	data := buf.Bytes()
 	err = func() (err error) {
 		file, err := os.OpenFile(filename+tempSuffix, os.O_RDWR|os.O_TRUNC|os.O_CREATE, 0600)
 		if err != nil {
@@ -1,86 +1,354 @@
 package persist
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/threefoldtech/rivine/build"
 	"github.com/threefoldtech/rivine/types"
 )
 
-// Logger is a wrapper for the standard library logger that enforces logging
-// with the Sia-standard settings. It also supports a Close method, which
-// attempts to close the underlying io.Writer.
+// LogLevel defines the severity of a logged message,
+// messages below the level of a logger are not logged.
+type LogLevel uint8
+
+// The levels of logged messages, in increasing severity.
+const (
+	LogLevelDebug LogLevel = iota + 1
+	LogLevelInfo
+	LogLevelWarn
+	LogLevelError
+)
+
+// String implements fmt.Stringer.String
+func (level LogLevel) String() string {
+	switch level {
+	case LogLevelDebug:
+		return "debug"
+	case LogLevelInfo:
+		return "info"
+	case LogLevelWarn:
+		return "warn"
+	case LogLevelError:
+		return "error"
+	default:
+		return fmt.Sprintf("LogLevel(%d)", uint8(level))
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.MarshalText
+func (level LogLevel) MarshalText() ([]byte, error) {
+	return []byte(level.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.UnmarshalText
+func (level *LogLevel) UnmarshalText(b []byte) error {
+	switch str := strings.ToLower(string(b)); str {
+	case "debug":
+		*level = LogLevelDebug
+	case "info":
+		*level = LogLevelInfo
+	case "warn", "warning":
+		*level = LogLevelWarn
+	case "error":
+		*level = LogLevelError
+	default:
+		return fmt.Errorf("unknown log level %q: expected debug, info, warn or error", str)
+	}
+	return nil
+}
+
+// LogFormat defines how the messages of a logger are written.
+type LogFormat string
+
+const (
+	// LogFormatText writes each message as a line of text,
+	// prefixed with the (UTC) time and source of the message.
+	LogFormatText LogFormat = "text"
+	// LogFormatJSON writes each message as a JSON object on a single line,
+	// with the time, level, module, source and message as fields.
+	LogFormatJSON LogFormat = "json"
+)
+
+// logSettings are the settings shared by all loggers,
+// such that the log levels can be changed at runtime.
+var logSettings = struct {
+	mu           sync.RWMutex
+	format       LogFormat
+	defaultLevel LogLevel
+	levels       map[string]LogLevel
+	modules      map[string]int // the amount of open loggers per module
+	rotation     LogRotation
+}{
+	format:       LogFormatText,
+	defaultLevel: defaultLogLevel(),
+	levels:       make(map[string]LogLevel),
+	modules:      make(map[string]int),
+}
+
+// defaultLogLevel returns the log level of loggers for which no level is defined,
+// debug messages are only logged by default when build.DEBUG is true.
+func defaultLogLevel() LogLevel {
+	if build.DEBUG {
+		return LogLevelDebug
+	}
+	return LogLevelInfo
+}
+
+// SetLogFormat sets the format of all loggers created afterwards.
+func SetLogFormat(format LogFormat) error {
+	switch format {
+	case LogFormatText, LogFormatJSON:
+	default:
+		return fmt.Errorf("unknown log format %q: expected %s or %s", format, LogFormatText, LogFormatJSON)
+	}
+	logSettings.mu.Lock()
+	logSettings.format = format
+	logSettings.mu.Unlock()
+	return nil
+}
+
+// CurrentLogFormat returns the format of loggers created now.
+func CurrentLogFormat() LogFormat {
+	logSettings.mu.RLock()
+	defer logSettings.mu.RUnlock()
+	return logSettings.format
+}
+
+// SetLogLevel sets the level of the loggers of the given module,
+// or the level of all modules without a level of their own if the module is empty.
+// The level applies to existing loggers immediately.
+func SetLogLevel(module string, level LogLevel) error {
+	if level < LogLevelDebug || level > LogLevelError {
+		return fmt.Errorf("invalid log level %v", level)
+	}
+	logSettings.mu.Lock()
+	defer logSettings.mu.Unlock()
+	if module == "" {
+		logSettings.defaultLevel = level
+	} else {
+		logSettings.levels[module] = level
+	}
+	return nil
+}
+
+// DefaultLogLevel returns the level of all modules without a level of their own.
+func DefaultLogLevel() LogLevel {
+	logSettings.mu.RLock()
+	defer logSettings.mu.RUnlock()
+	return logSettings.defaultLevel
+}
+
+// LogLevels returns the level of every module with an open logger,
+// as well as of every module for which a level is set.
+func LogLevels() map[string]LogLevel {
+	logSettings.mu.RLock()
+	defer logSettings.mu.RUnlock()
+	levels := make(map[string]LogLevel, len(logSettings.modules))
+	for module := range logSettings.modules {
+		levels[module] = logSettings.defaultLevel
+	}
+	for module, level := range logSettings.levels {
+		levels[module] = level
+	}
+	return levels
+}
+
+// logLevelOf returns the current level of the given module.
+func logLevelOf(module string) LogLevel {
+	logSettings.mu.RLock()
+	defer logSettings.mu.RUnlock()
+	if level, ok := logSettings.levels[module]; ok {
+		return level
+	}
+	return logSettings.defaultLevel
+}
+
+// Logger is a leveled logger of a single module, writing either text or JSON,
+// see SetLogFormat, that enforces logging with the Sia-standard settings.
+// It also supports a Close method, which attempts to close the underlying io.Writer.
 type Logger struct {
-	*log.Logger
-	w io.Writer
+	module string
+	format LogFormat
+	text   *log.Logger // used for the text format
+
+	mu     sync.Mutex // protects the writer and prefix
+	w      io.Writer
+	prefix string
+}
+
+// jsonLogEntry is a message logged in the JSON format.
+type jsonLogEntry struct {
+	Time    string   `json:"time"`
+	Level   LogLevel `json:"level"`
+	Module  string   `json:"module,omitempty"`
+	Source  string   `json:"source,omitempty"`
+	Message string   `json:"msg"`
+}
+
+// SetPrefix sets the prefix of all messages logged afterwards.
+func (l *Logger) SetPrefix(prefix string) {
+	l.mu.Lock()
+	l.prefix = prefix
+	l.mu.Unlock()
+	l.text.SetPrefix(prefix)
+}
+
+// Enabled returns true if messages of the given level are currently logged.
+func (l *Logger) Enabled(level LogLevel) bool {
+	return level >= logLevelOf(l.module)
+}
+
+// output logs the given message at the given level, if that level is enabled,
+// calldepth is the amount of stack frames to skip to find the source of the message.
+func (l *Logger) output(calldepth int, level LogLevel, msg string) {
+	if !l.Enabled(level) {
+		return
+	}
+	if l.format != LogFormatJSON {
+		if level == LogLevelDebug {
+			msg = "[DEBUG] " + msg
+		}
+		l.text.Output(calldepth+1, msg)
+		return
+	}
+	entry := jsonLogEntry{
+		Time:    time.Now().UTC().Format(time.RFC3339Nano),
+		Level:   level,
+		Module:  l.module,
+		Message: strings.TrimSuffix(msg, "\n"),
+	}
+	if _, file, line, ok := runtime.Caller(calldepth); ok {
+		entry.Source = filepath.Base(file) + ":" + strconv.Itoa(line)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entry.Message = l.prefix + entry.Message
+	b, err := json.Marshal(entry)
+	if err == nil {
+		l.w.Write(append(b, '\n'))
+	}
 }
 
 // Close logs a shutdown message and closes the Logger's underlying io.Writer,
 // if it is also an io.Closer.
 func (l *Logger) Close() error {
-	l.Output(2, "SHUTDOWN: Logging has terminated.")
+	l.output(2, LogLevelInfo, "SHUTDOWN: Logging has terminated.")
+	unregisterLogger(l.module)
 	if c, ok := l.w.(io.Closer); ok {
 		return c.Close()
 	}
 	return nil
 }
 
-// Critical logs a message with a CRITICAL prefix that guides the user to the
+// Print logs a message at the info level, arguments are handled in the manner of fmt.Print.
+func (l *Logger) Print(v ...interface{}) {
+	l.output(2, LogLevelInfo, fmt.Sprint(v...))
+}
+
+// Printf logs a message at the info level, arguments are handled in the manner of fmt.Printf.
+func (l *Logger) Printf(format string, v ...interface{}) {
+	l.output(2, LogLevelInfo, fmt.Sprintf(format, v...))
+}
+
+// Println logs a message at the info level, arguments are handled in the manner of fmt.Println.
+func (l *Logger) Println(v ...interface{}) {
+	l.output(2, LogLevelInfo, fmt.Sprintln(v...))
+}
+
+// Warnf logs a message at the warn level, arguments are handled in the manner of fmt.Printf.
+func (l *Logger) Warnf(format string, v ...interface{}) {
+	l.output(2, LogLevelWarn, fmt.Sprintf(format, v...))
+}
+
+// Warnln logs a message at the warn level, arguments are handled in the manner of fmt.Println.
+func (l *Logger) Warnln(v ...interface{}) {
+	l.output(2, LogLevelWarn, fmt.Sprintln(v...))
+}
+
+// Critical logs a message with a CRITICAL prefix at the error level, that guides the user to the
 // Sia github tracker. If debug mode is enabled, it will also write the message
 // to os.Stderr and panic. Critical should only be called if there has been a
 // developer error, otherwise Severe should be called.
 func (l *Logger) Critical(v ...interface{}) {
-	l.Output(2, "CRITICAL: "+fmt.Sprintln(v...))
+	l.output(2, LogLevelError, "CRITICAL: "+fmt.Sprintln(v...))
 	build.Critical(v...)
 }
 
-// Debug is equivalent to Logger.Print when build.DEBUG is true. Otherwise it
-// is a no-op.
+// Debug logs a message at the debug level, arguments are handled in the manner of fmt.Print.
+// Debug messages are only logged by default when build.DEBUG is true.
 func (l *Logger) Debug(v ...interface{}) {
-	if build.DEBUG {
-		l.Output(2, fmt.Sprint(v...))
-	}
+	l.output(2, LogLevelDebug, fmt.Sprint(v...))
 }
 
-// Debugf is equivalent to Logger.Printf when build.DEBUG is true. Otherwise it
-// is a no-op.
+// Debugf logs a message at the debug level, arguments are handled in the manner of fmt.Printf.
+// Debug messages are only logged by default when build.DEBUG is true.
 func (l *Logger) Debugf(format string, v ...interface{}) {
-	if build.DEBUG {
-		l.Output(2, fmt.Sprintf(format, v...))
-	}
+	l.output(2, LogLevelDebug, fmt.Sprintf(format, v...))
 }
 
-// Debugln is equivalent to Logger.Println when build.DEBUG is true. Otherwise
-// it is a no-op.
+// Debugln logs a message at the debug level, arguments are handled in the manner of fmt.Println.
+// Debug messages are only logged by default when build.DEBUG is true.
 func (l *Logger) Debugln(v ...interface{}) {
-	if build.DEBUG {
-		l.Output(2, "[DEBUG] "+fmt.Sprintln(v...))
-	}
+	l.output(2, LogLevelDebug, fmt.Sprintln(v...))
 }
 
-// Severe logs a message with a SEVERE prefix. If debug mode is enabled, it
+// Severe logs a message with a SEVERE prefix at the error level. If debug mode is enabled, it
 // will also write the message to os.Stderr and panic. Severe should be called
 // if there is a severe problem with the user's machine or setup that should be
 // addressed ASAP but does not necessarily require that the machine crash or
 // exit.
 func (l *Logger) Severe(v ...interface{}) {
-	l.Output(2, "SEVERE: "+fmt.Sprintln(v...))
+	l.output(2, LogLevelError, "SEVERE: "+fmt.Sprintln(v...))
 	build.Severe(v...)
 }
 
 // NewLogger returns a logger that can be closed. Calls should not be made to
 // the logger after 'Close' has been called.
 func NewLogger(info types.BlockchainInfo, w io.Writer) *Logger {
-	l := log.New(w, "", log.Ldate|log.Ltime|log.Lmicroseconds|log.Lshortfile|log.LUTC)
+	return newLogger(info, "", w)
+}
+
+// newLogger returns a logger of the given module, using the current log format.
+func newLogger(info types.BlockchainInfo, module string, w io.Writer) *Logger {
+	logSettings.mu.Lock()
+	format := logSettings.format
+	if module != "" {
+		logSettings.modules[module]++
+	}
+	logSettings.mu.Unlock()
+	l := &Logger{
+		module: module,
+		format: format,
+		text:   log.New(w, "", log.Ldate|log.Ltime|log.Lmicroseconds|log.Lshortfile|log.LUTC),
+		w:      w,
+	}
 	// Call depth is 3 because NewLogger is usually called by NewFileLogger
-	l.Output(3, fmt.Sprintf(
+	l.output(3, LogLevelInfo, fmt.Sprintf(
 		"STARTUP: Logging has started. %s Version %s",
 		info.Name, info.ChainVersion.String()))
-	return &Logger{l, w}
+	return l
+}
+
+// unregisterLogger forgets a closed logger of the given module.
+func unregisterLogger(module string) {
+	if module == "" {
+		return
+	}
+	logSettings.mu.Lock()
+	defer logSettings.mu.Unlock()
+	if logSettings.modules[module]--; logSettings.modules[module] <= 0 {
+		delete(logSettings.modules, module)
+	}
 }
 
 // closeableFile wraps an os.File to perform sanity checks on its Write and
@@ -121,12 +389,24 @@ func (cf *closeableFile) Write(b []byte) (int, error) {
 }
 
 // NewFileLogger returns a logger that logs to logFilename. The file is opened
-// in append mode, and created if it does not exist.
+// in append mode, and created if it does not exist. The module of the logger,
+// of which the level can be set using SetLogLevel, is named after the file (e.g. consensus for consensus.log).
+// The file is rotated according to the current log rotation, see SetLogRotation.
 func NewFileLogger(info types.BlockchainInfo, logFilename string) (*Logger, error) {
-	logFile, err := os.OpenFile(logFilename, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0660)
-	if err != nil {
-		return nil, err
+	var w io.Writer
+	if rotation := CurrentLogRotation(); rotation.Enabled() {
+		rf, err := openRotatingFile(logFilename, rotation)
+		if err != nil {
+			return nil, err
+		}
+		w = rf
+	} else {
+		logFile, err := os.OpenFile(logFilename, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0660)
+		if err != nil {
+			return nil, err
+		}
+		w = &closeableFile{File: logFile}
 	}
-	cf := &closeableFile{File: logFile}
-	return NewLogger(info, cf), nil
+	module := strings.TrimSuffix(filepath.Base(logFilename), filepath.Ext(logFilename))
+	return newLogger(info, module, w), nil
 }
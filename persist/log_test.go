@@ -1,6 +1,8 @@
 package persist
 
 import (
+	"bytes"
+	"encoding/json"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -93,3 +95,85 @@ func TestLoggerCritical(t *testing.T) {
 	}()
 	fl.Critical("a critical message")
 }
+
+// TestLoggerLevels checks that messages below the level of the module of a logger
+// are not logged, and that levels can be changed while logging.
+func TestLoggerLevels(t *testing.T) {
+	defer SetLogLevel("", DefaultLogLevel())
+	if err := SetLogLevel("", LogLevelInfo); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	l := newLogger(types.DefaultBlockchainInfo(), "test-levels", &buf)
+	defer l.Close()
+	defer delete(logSettings.levels, "test-levels")
+
+	l.Debugln("hidden")
+	l.Println("shown")
+	if str := buf.String(); strings.Contains(str, "hidden") || !strings.Contains(str, "shown") {
+		t.Fatalf("unexpected log output: %q", str)
+	}
+	if level, ok := LogLevels()["test-levels"]; !ok || level != LogLevelInfo {
+		t.Fatalf("unexpected level of an open logger: %v (%v)", level, ok)
+	}
+
+	// the level of a module applies immediately
+	if err := SetLogLevel("test-levels", LogLevelDebug); err != nil {
+		t.Fatal(err)
+	}
+	l.Debugf("now %s", "shown")
+	if str := buf.String(); !strings.Contains(str, "[DEBUG] now shown") {
+		t.Fatalf("expected the debug message to be logged: %q", str)
+	}
+	if err := SetLogLevel("test-levels", LogLevel(0)); err == nil {
+		t.Fatal("expected an error for an invalid level")
+	}
+
+	var level LogLevel
+	if err := level.UnmarshalText([]byte("WARN")); err != nil || level != LogLevelWarn {
+		t.Fatalf("unexpected level %v: %v", level, err)
+	}
+	if err := level.UnmarshalText([]byte("verbose")); err == nil {
+		t.Fatal("expected an error for an unknown level")
+	}
+}
+
+// TestLoggerJSON checks that the JSON format logs a JSON object per message.
+func TestLoggerJSON(t *testing.T) {
+	defer SetLogFormat(CurrentLogFormat())
+	if err := SetLogFormat(LogFormatJSON); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	l := newLogger(types.DefaultBlockchainInfo(), "test-json", &buf)
+	buf.Reset()
+	l.Printf("hello %s", "world")
+	l.Warnln("careful")
+	l.Close()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d: %q", len(lines), buf.String())
+	}
+	var entry jsonLogEntry
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.Level != LogLevelInfo || entry.Module != "test-json" || entry.Message != "hello world" ||
+		!strings.HasPrefix(entry.Source, "log_test.go:") || entry.Time == "" {
+		t.Fatalf("unexpected entry: %+v", entry)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.Level != LogLevelWarn || entry.Message != "careful" {
+		t.Fatalf("unexpected entry: %+v", entry)
+	}
+	if _, ok := LogLevels()["test-json"]; ok {
+		t.Fatal("expected the closed logger to be forgotten")
+	}
+
+	if err := SetLogFormat("xml"); err == nil {
+		t.Fatal("expected an error for an unknown format")
+	}
+}
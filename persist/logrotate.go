@@ -0,0 +1,341 @@
+package persist
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/threefoldtech/rivine/build"
+)
+
+// LogRotation defines when the log files of the modules are rotated,
+// and which of the rotated log files are retained.
+//
+// A log file is rotated by renaming it after the time it was rotated (e.g. consensus-2006-01-02T15-04-05.000.log),
+// after which logging continues in a new file. Rotated log files are compressed and removed in the background.
+type LogRotation struct {
+	// MaxSize is the size in bytes a log file can grow to before it is rotated, unlimited if 0.
+	MaxSize int64
+	// Interval is the duration after which a log file is rotated, unlimited if 0.
+	// A log file which exists when a module is loaded, is considered to be created at the time it was last modified.
+	Interval time.Duration
+	// Compress defines whether or not rotated log files are compressed using gzip.
+	Compress bool
+	// MaxBackups is the amount of rotated log files retained per module, all if 0.
+	MaxBackups int
+	// MaxAge is the duration rotated log files are retained, forever if 0.
+	MaxAge time.Duration
+}
+
+// Enabled returns true if log files are rotated.
+func (rotation LogRotation) Enabled() bool {
+	return rotation.MaxSize > 0 || rotation.Interval > 0
+}
+
+// Validate validates that none of the limits are negative.
+func (rotation LogRotation) Validate() error {
+	if rotation.MaxSize < 0 || rotation.Interval < 0 || rotation.MaxBackups < 0 || rotation.MaxAge < 0 {
+		return fmt.Errorf("invalid log rotation %+v: limits cannot be negative", rotation)
+	}
+	return nil
+}
+
+// SetLogRotation sets the rotation of the log files of all file loggers created afterwards.
+func SetLogRotation(rotation LogRotation) error {
+	if err := rotation.Validate(); err != nil {
+		return err
+	}
+	logSettings.mu.Lock()
+	logSettings.rotation = rotation
+	logSettings.mu.Unlock()
+	return nil
+}
+
+// CurrentLogRotation returns the rotation of the log files of file loggers created now.
+func CurrentLogRotation() LogRotation {
+	logSettings.mu.RLock()
+	defer logSettings.mu.RUnlock()
+	return logSettings.rotation
+}
+
+const (
+	// rotatedLogTimeFormat is the format of the time in the name of a rotated log file,
+	// which sorts chronologically and can be used in a filename on all platforms.
+	rotatedLogTimeFormat = "2006-01-02T15-04-05.000"
+	// compressedLogExt is the extension appended to the name of a compressed log file.
+	compressedLogExt = ".gz"
+)
+
+// rotatingFile is a log file, which is rotated according to its LogRotation.
+// The rotated files are compressed and removed in a background goroutine,
+// which is awaited when the file is closed.
+type rotatingFile struct {
+	filename string
+	rotation LogRotation
+
+	mu      sync.Mutex
+	file    *os.File
+	size    int64
+	created time.Time
+	closed  bool
+
+	// maintained is signaled, without blocking, once a file is rotated
+	maintained chan struct{}
+	done       chan struct{}
+}
+
+// openRotatingFile opens the log file with the given name in append mode,
+// creating it if it does not exist, rotating it if it is due.
+func openRotatingFile(filename string, rotation LogRotation) (*rotatingFile, error) {
+	rf := &rotatingFile{
+		filename:   filename,
+		rotation:   rotation,
+		maintained: make(chan struct{}, 1),
+		done:       make(chan struct{}),
+	}
+	err := rf.open()
+	if err != nil {
+		return nil, err
+	}
+	if rf.due(0) {
+		err = rf.rotate()
+		if err != nil {
+			rf.file.Close()
+			return nil, err
+		}
+	}
+	// compress and remove the files rotated by a previous run as well
+	select {
+	case rf.maintained <- struct{}{}:
+	default:
+	}
+	go rf.maintain()
+	return rf, nil
+}
+
+// open opens the log file, of which the size and creation time are taken from the existing file, if any.
+func (rf *rotatingFile) open() error {
+	file, err := os.OpenFile(rf.filename, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0660)
+	if err != nil {
+		return err
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	rf.file, rf.size, rf.created = file, stat.Size(), time.Now()
+	if rf.size > 0 {
+		rf.created = stat.ModTime()
+	}
+	return nil
+}
+
+// due returns true if the log file has to be rotated prior to writing n bytes to it.
+func (rf *rotatingFile) due(n int) bool {
+	if rf.size == 0 {
+		return false
+	}
+	if rf.rotation.MaxSize > 0 && rf.size+int64(n) > rf.rotation.MaxSize {
+		return true
+	}
+	return rf.rotation.Interval > 0 && time.Since(rf.created) >= rf.rotation.Interval
+}
+
+// rotate renames the log file after the current time, and opens a new log file.
+func (rf *rotatingFile) rotate() error {
+	if err := rf.file.Sync(); err != nil {
+		return err
+	}
+	if err := rf.file.Close(); err != nil {
+		return err
+	}
+	err := os.Rename(rf.filename, rf.rotatedFilename(time.Now()))
+	if err != nil {
+		// continue logging to the current file, as losing the log would be worse
+		file, openErr := os.OpenFile(rf.filename, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0660)
+		if openErr == nil {
+			rf.file = file
+		}
+		return err
+	}
+	err = rf.open()
+	if err != nil {
+		return err
+	}
+	select {
+	case rf.maintained <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// rotatedFilename returns the name of the log file rotated at the given time,
+// which is made unique by moving the time forward, should a file already be rotated at that time.
+func (rf *rotatingFile) rotatedFilename(t time.Time) string {
+	ext := filepath.Ext(rf.filename)
+	for {
+		filename := strings.TrimSuffix(rf.filename, ext) + "-" + t.UTC().Format(rotatedLogTimeFormat) + ext
+		if _, err := os.Stat(filename); os.IsNotExist(err) {
+			if _, err = os.Stat(filename + compressedLogExt); os.IsNotExist(err) {
+				return filename
+			}
+		}
+		t = t.Add(time.Millisecond)
+	}
+}
+
+// Write writes to the log file, rotating it first if it is due.
+func (rf *rotatingFile) Write(b []byte) (int, error) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	// Sanity check - close should not have been called yet.
+	if rf.closed {
+		build.Critical("cannot write to the file after it has been closed")
+	}
+	if rf.due(len(b)) {
+		if err := rf.rotate(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to rotate log file %s: %v\n", rf.filename, err)
+		}
+	}
+	n, err := rf.file.Write(b)
+	rf.size += int64(n)
+	return n, err
+}
+
+// Close closes the log file, once the rotated files are compressed and removed.
+func (rf *rotatingFile) Close() error {
+	rf.mu.Lock()
+	// Sanity check - close should not have been called yet.
+	if rf.closed {
+		build.Critical("cannot close the file; already closed")
+	}
+	rf.closed = true
+	close(rf.maintained)
+	rf.mu.Unlock()
+	<-rf.done
+
+	if err := rf.file.Sync(); err != nil {
+		rf.file.Close()
+		return err
+	}
+	return rf.file.Close()
+}
+
+// maintain compresses and removes the rotated log files, whenever a file is rotated, until closed.
+func (rf *rotatingFile) maintain() {
+	defer close(rf.done)
+	for range rf.maintained {
+		if err := rf.maintainRotated(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to maintain rotated log files of %s: %v\n", rf.filename, err)
+		}
+	}
+}
+
+// rotatedLogFile is a rotated log file, possibly compressed.
+type rotatedLogFile struct {
+	filename   string
+	rotated    time.Time
+	compressed bool
+}
+
+// rotatedFiles returns the rotated log files, ordered from the most recently rotated.
+func (rf *rotatingFile) rotatedFiles() ([]rotatedLogFile, error) {
+	dir := filepath.Dir(rf.filename)
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	ext := filepath.Ext(rf.filename)
+	prefix := strings.TrimSuffix(filepath.Base(rf.filename), ext) + "-"
+	var files []rotatedLogFile
+	for _, info := range infos {
+		name := info.Name()
+		if info.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		file := rotatedLogFile{filename: filepath.Join(dir, name)}
+		if strings.HasSuffix(name, compressedLogExt) {
+			file.compressed = true
+			name = strings.TrimSuffix(name, compressedLogExt)
+		}
+		if !strings.HasSuffix(name, ext) {
+			continue
+		}
+		file.rotated, err = time.Parse(rotatedLogTimeFormat, strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext))
+		if err != nil {
+			continue // not a rotated log file
+		}
+		files = append(files, file)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].rotated.After(files[j].rotated)
+	})
+	return files, nil
+}
+
+// maintainRotated removes the rotated log files which are not to be retained,
+// and compresses the others if required.
+func (rf *rotatingFile) maintainRotated() error {
+	files, err := rf.rotatedFiles()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for i, file := range files {
+		if (rf.rotation.MaxBackups > 0 && i >= rf.rotation.MaxBackups) ||
+			(rf.rotation.MaxAge > 0 && time.Since(file.rotated) > rf.rotation.MaxAge) {
+			if err = os.Remove(file.filename); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if rf.rotation.Compress && !file.compressed {
+			if err = compressLogFile(file.filename); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return build.ComposeErrors(errs...)
+}
+
+// compressLogFile compresses the given log file using gzip, replacing it.
+func compressLogFile(filename string) error {
+	src, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	// write to a temporary file first, such that an interrupted compression leaves no partial file behind
+	tmp := filename + compressedLogExt + ".tmp"
+	dst, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0660)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	_, err = io.Copy(zw, src)
+	if err == nil {
+		err = zw.Close()
+	}
+	if err == nil {
+		err = dst.Sync()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, filename+compressedLogExt)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to compress %s: %v", filename, err)
+	}
+	return os.Remove(filename)
+}
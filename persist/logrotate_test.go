@@ -0,0 +1,190 @@
+package persist
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/threefoldtech/rivine/build"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// newRotatingFileTestDir creates an empty directory for the log files of a test.
+func newRotatingFileTestDir(t *testing.T) string {
+	testdir := build.TempDir(persistDir, t.Name())
+	err := os.RemoveAll(testdir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.MkdirAll(testdir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testdir
+}
+
+// rotatedLogFiles returns the names of the rotated log files in the given directory.
+func rotatedLogFiles(t *testing.T, dir string) []string {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, info := range infos {
+		if info.Name() != "test.log" {
+			names = append(names, info.Name())
+		}
+	}
+	return names
+}
+
+// TestRotatingFileMaxSize checks that a log file is rotated once it exceeds its maximum size,
+// and that only the configured amount of rotated files is retained, compressed.
+func TestRotatingFileMaxSize(t *testing.T) {
+	testdir := newRotatingFileTestDir(t)
+	filename := filepath.Join(testdir, "test.log")
+	rf, err := openRotatingFile(filename, LogRotation{MaxSize: 10, Compress: true, MaxBackups: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err = rf.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// closing awaits the compression and removal of the rotated files
+	if err = rf.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "fourth\n" {
+		t.Fatalf("unexpected log file content: %q", b)
+	}
+	names := rotatedLogFiles(t, testdir)
+	if len(names) != 2 {
+		t.Fatal("expected 2 rotated log files, got:", names)
+	}
+	// the oldest rotated file is removed, the most recent ones are retained
+	for i, expected := range []string{"second\n", "third\n"} {
+		if !strings.HasPrefix(names[i], "test-") || !strings.HasSuffix(names[i], ".log.gz") {
+			t.Fatal("unexpected rotated log file name:", names[i])
+		}
+		file, err := os.Open(filepath.Join(testdir, names[i]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		zr, err := gzip.NewReader(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err = ioutil.ReadAll(zr)
+		file.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != expected {
+			t.Fatalf("unexpected content of rotated log file %s: %q", names[i], b)
+		}
+	}
+}
+
+// TestRotatingFileInterval checks that a log file is rotated once it is older than the interval,
+// also when it is reopened, and that rotated files older than the maximum age are removed.
+func TestRotatingFileInterval(t *testing.T) {
+	testdir := newRotatingFileTestDir(t)
+	filename := filepath.Join(testdir, "test.log")
+	rotation := LogRotation{Interval: time.Hour, MaxAge: 24 * time.Hour}
+
+	// an existing log file last written to more than an interval ago is rotated when opened
+	err := ioutil.WriteFile(filename, []byte("old\n"), 0660)
+	if err != nil {
+		t.Fatal(err)
+	}
+	past := time.Now().Add(-2 * time.Hour)
+	if err = os.Chtimes(filename, past, past); err != nil {
+		t.Fatal(err)
+	}
+	// as are rotated log files older than the maximum age removed
+	expired := filepath.Join(testdir, "test-"+time.Now().Add(-48*time.Hour).UTC().Format(rotatedLogTimeFormat)+".log")
+	if err = ioutil.WriteFile(expired, []byte("expired\n"), 0660); err != nil {
+		t.Fatal(err)
+	}
+	rf, err := openRotatingFile(filename, rotation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = rf.Write([]byte("new\n")); err != nil {
+		t.Fatal(err)
+	}
+	names := rotatedLogFiles(t, testdir)
+	if len(names) == 0 || names[len(names)-1] == filepath.Base(expired) {
+		t.Fatal("expected the old log file to be rotated, got:", names)
+	}
+
+	// a log file is rotated once older than the interval
+	rf.mu.Lock()
+	rf.created = rf.created.Add(-rotation.Interval)
+	rf.mu.Unlock()
+	if _, err = rf.Write([]byte("newer\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err = rf.Close(); err != nil {
+		t.Fatal(err)
+	}
+	names = rotatedLogFiles(t, testdir)
+	if len(names) != 2 {
+		t.Fatal("expected 2 rotated log files, got:", names)
+	}
+	for _, name := range names {
+		if name == filepath.Base(expired) {
+			t.Fatal("expected the expired log file to be removed")
+		}
+	}
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "newer\n" {
+		t.Fatalf("unexpected log file content: %q", b)
+	}
+}
+
+// TestFileLoggerRotation checks that file loggers rotate their log file according to the log rotation.
+func TestFileLoggerRotation(t *testing.T) {
+	defer SetLogRotation(CurrentLogRotation())
+	if err := SetLogRotation(LogRotation{MaxSize: -1}); err == nil {
+		t.Fatal("expected a negative size to be refused")
+	}
+	if err := SetLogRotation(LogRotation{MaxSize: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	testdir := newRotatingFileTestDir(t)
+	filename := filepath.Join(testdir, "test.log")
+	fl, err := NewFileLogger(types.DefaultBlockchainInfo(), filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fl.Println("TEST: this should get written to a new logfile")
+	if err = fl.Close(); err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), "SHUTDOWN") || strings.Contains(string(b), "STARTUP") {
+		t.Fatalf("expected only the last message in the log file, got: %q", b)
+	}
+	if names := rotatedLogFiles(t, testdir); len(names) != 2 {
+		t.Fatal("expected 2 rotated log files, got:", names)
+	}
+}
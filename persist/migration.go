@@ -0,0 +1,143 @@
+package persist
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/rivine/bbolt"
+)
+
+// A Migration upgrades a bolt database from one metadata version to the next one.
+type Migration struct {
+	// From is the metadata version the migration applies to.
+	From string
+	// To is the metadata version of the database after the migration.
+	To string
+	// Description describes the migration in a human readable way.
+	Description string
+	// Migrate migrates the database, within the given transaction.
+	Migrate func(tx *bolt.Tx) error
+}
+
+// MigrationOptions define how MigrateDatabase migrates a database.
+type MigrationOptions struct {
+	// DryRun applies all migrations, only to roll them back afterwards,
+	// such that one can validate that a database can be migrated,
+	// without actually modifying it.
+	DryRun bool
+	// Backup copies the database, prior to migrating it,
+	// to a file next to it, suffixed with the version it is migrated from.
+	Backup bool
+}
+
+var (
+	// errMigrationDryRun is used to roll back the migrations of a dry run.
+	errMigrationDryRun = errors.New("migration dry run")
+)
+
+// MigrationBackupFilename returns the filename used to back up a database,
+// prior to migrating it from the given version.
+func MigrationBackupFilename(filename, version string) string {
+	return filename + ".v" + version + ".bak"
+}
+
+// MigrateDatabase migrates the database with the given filename to the version of the given metadata,
+// by applying the given migrations in order, as a single atomic operation.
+// The migrations that were (or would be, in case of a dry run) applied are returned.
+//
+// Nothing is done in case the database does not exist yet, is already up to date,
+// or in case no migration path exists from its version, leaving it up to the owner of
+// the database to handle it (e.g. by converting it as a legacy database).
+// ErrBadHeader is returned in case the header of the database does not match.
+func MigrateDatabase(md Metadata, filename string, migrations []Migration, opts MigrationOptions) ([]Migration, error) {
+	if _, err := os.Stat(filename); os.IsNotExist(err) {
+		return nil, nil // nothing to migrate
+	}
+
+	db, err := bolt.Open(filename, 0600, &bolt.Options{Timeout: 3 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	// collect the version of the database
+	var header, version string
+	err = db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte("Metadata"))
+		if bucket == nil {
+			return nil
+		}
+		header = string(bucket.Get([]byte("Header")))
+		version = string(bucket.Get([]byte("Version")))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if header == "" {
+		return nil, nil // database has no metadata yet
+	}
+	if header != md.Header {
+		return nil, ErrBadHeader
+	}
+
+	path := migrationPath(version, md.Version, migrations)
+	if len(path) == 0 {
+		return nil, nil
+	}
+
+	if opts.Backup && !opts.DryRun {
+		err = db.View(func(tx *bolt.Tx) error {
+			return tx.CopyFile(MigrationBackupFilename(filename, version), 0600)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to back up database prior to migration: %v", err)
+		}
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, migration := range path {
+			err := migration.Migrate(tx)
+			if err != nil {
+				return fmt.Errorf("failed to migrate database from v%s to v%s: %v", migration.From, migration.To, err)
+			}
+		}
+		bucket := tx.Bucket([]byte("Metadata"))
+		err := bucket.Put([]byte("Version"), []byte(md.Version))
+		if err != nil {
+			return err
+		}
+		if opts.DryRun {
+			return errMigrationDryRun
+		}
+		return nil
+	})
+	if err != nil && err != errMigrationDryRun {
+		return nil, err
+	}
+	return path, nil
+}
+
+// migrationPath returns the ordered migrations required to migrate
+// from one version to another, or nil if no such path exists.
+func migrationPath(from, to string, migrations []Migration) []Migration {
+	var path []Migration
+	version := from
+	for version != to {
+		found := false
+		for _, migration := range migrations {
+			if migration.From == version {
+				path = append(path, migration)
+				version = migration.To
+				found = true
+				break
+			}
+		}
+		if !found || len(path) > len(migrations) {
+			return nil // no path, or a cycle
+		}
+	}
+	return path
+}
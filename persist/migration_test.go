@@ -0,0 +1,121 @@
+package persist
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/threefoldtech/rivine/build"
+
+	"github.com/rivine/bbolt"
+)
+
+// testMigrations migrate a database from v1 to v3,
+// adding a bucket in the first migration and a key in the second one.
+var testMigrations = []Migration{
+	{
+		From: "2", To: "3",
+		Description: "add key",
+		Migrate: func(tx *bolt.Tx) error {
+			return tx.Bucket([]byte("Bucket")).Put([]byte("Key"), []byte("Value"))
+		},
+	},
+	{
+		From: "1", To: "2",
+		Description: "add bucket",
+		Migrate: func(tx *bolt.Tx) error {
+			_, err := tx.CreateBucket([]byte("Bucket"))
+			return err
+		},
+	},
+}
+
+// createTestDatabase creates a database with the given metadata.
+func createTestDatabase(t *testing.T, md Metadata, filename string) {
+	db, err := OpenDatabase(md, filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMigrateDatabase(t *testing.T) {
+	testDir := build.TempDir(persistDir, t.Name())
+	err := os.MkdirAll(testDir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(testDir, "migration.db")
+	v1 := Metadata{Header: "Migration Test", Version: "1"}
+	v3 := Metadata{Header: "Migration Test", Version: "3"}
+
+	// a non-existing database requires no migration
+	migrations, err := MigrateDatabase(v3, filename, testMigrations, MigrationOptions{})
+	if err != nil || len(migrations) != 0 {
+		t.Fatal("unexpected result for non-existing database:", migrations, err)
+	}
+
+	createTestDatabase(t, v1, filename)
+
+	// a dry run does not modify the database, nor make a backup
+	migrations, err = MigrateDatabase(v3, filename, testMigrations, MigrationOptions{DryRun: true, Backup: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) != 2 || migrations[0].To != "2" || migrations[1].To != "3" {
+		t.Fatal("unexpected migrations:", migrations)
+	}
+	if _, err = os.Stat(MigrationBackupFilename(filename, "1")); !os.IsNotExist(err) {
+		t.Fatal("dry run created a backup:", err)
+	}
+	createTestDatabase(t, v1, filename) // would fail if the version was modified
+
+	// an unknown header is refused
+	_, err = MigrateDatabase(Metadata{Header: "Other", Version: "3"}, filename, testMigrations, MigrationOptions{})
+	if err != ErrBadHeader {
+		t.Fatal("expected bad header error, not:", err)
+	}
+
+	// migrate the database, with a backup
+	migrations, err = MigrateDatabase(v3, filename, testMigrations, MigrationOptions{Backup: true})
+	if err != nil || len(migrations) != 2 {
+		t.Fatal("unexpected migration result:", migrations, err)
+	}
+	db, err := OpenDatabase(v3, filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.View(func(tx *bolt.Tx) error {
+		if string(tx.Bucket([]byte("Bucket")).Get([]byte("Key"))) != "Value" {
+			t.Error("migrations were not applied")
+		}
+		return nil
+	})
+	db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	createTestDatabase(t, v1, MigrationBackupFilename(filename, "1"))
+
+	// migrating an up-to-date database does nothing
+	migrations, err = MigrateDatabase(v3, filename, testMigrations, MigrationOptions{})
+	if err != nil || len(migrations) != 0 {
+		t.Fatal("unexpected result for up-to-date database:", migrations, err)
+	}
+}
+
+func TestMigrationPath(t *testing.T) {
+	if path := migrationPath("1", "3", testMigrations); len(path) != 2 {
+		t.Error("unexpected path:", path)
+	}
+	if path := migrationPath("0", "3", testMigrations); path != nil {
+		t.Error("unexpected path:", path)
+	}
+	cyclic := []Migration{{From: "1", To: "2"}, {From: "2", To: "1"}}
+	if path := migrationPath("1", "3", cyclic); path != nil {
+		t.Error("unexpected path:", path)
+	}
+}
@@ -0,0 +1,374 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/threefoldtech/rivine/types"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// APIAuditLogFile is the name of the file, stored in the root persistent directory of the daemon,
+// to which the API calls modifying the state of the daemon are appended.
+const APIAuditLogFile = "apiaudit.log"
+
+const (
+	// apiAuditBodyLimit is the amount of bytes of a request body used to summarize the request,
+	// larger bodies are summarized using their size only.
+	apiAuditBodyLimit = 4 << 10
+	// apiAuditSummaryLimit is the maximum length of the summary of a request.
+	apiAuditSummaryLimit = 512
+	// apiAuditRedacted replaces the values of the sensitive parameters of a request.
+	apiAuditRedacted = "<redacted>"
+)
+
+// APIAuditIdentity defines how the caller of an audited API call authenticated.
+type APIAuditIdentity string
+
+const (
+	// APIAuditIdentityNone is the identity of calls which did not authenticate.
+	APIAuditIdentityNone APIAuditIdentity = "none"
+	// APIAuditIdentityPassword is the identity of calls authenticated using the API password.
+	APIAuditIdentityPassword APIAuditIdentity = "password"
+	// APIAuditIdentityToken is the identity of calls authenticated using an API token.
+	APIAuditIdentityToken APIAuditIdentity = "token"
+	// APIAuditIdentityUnixSocket is the identity of calls received over a unix domain socket,
+	// authenticated by its file permissions.
+	APIAuditIdentityUnixSocket APIAuditIdentity = "unixsocket"
+)
+
+// APIAuditEntry is a single API call recorded in the audit log.
+type APIAuditEntry struct {
+	Timestamp types.Timestamp  `json:"timestamp"`
+	Identity  APIAuditIdentity `json:"identity"`
+	// TokenID and TokenName identify the API token authenticating the call, if any.
+	TokenID    string `json:"tokenid,omitempty"`
+	TokenName  string `json:"tokenname,omitempty"`
+	RemoteAddr string `json:"remoteaddr"`
+	Method     string `json:"method"`
+	Path       string `json:"path"`
+	// Summary contains the parameters of the call, with the values of secrets redacted.
+	Summary string `json:"summary,omitempty"`
+	// Status is the HTTP status code the call was answered with.
+	Status int `json:"status"`
+}
+
+// APIAuditLog is an append-only log of the API calls modifying the state of the daemon,
+// every entry is stored as a single line of JSON.
+type APIAuditLog struct {
+	filename string
+	file     *os.File
+	mu       sync.Mutex
+}
+
+// NewAPIAuditLog opens the audit log stored in the given file,
+// creating it if it doesn't exist yet.
+func NewAPIAuditLog(filename string) (*APIAuditLog, error) {
+	err := os.MkdirAll(filepath.Dir(filename), 0700)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open API audit log: %v", err)
+	}
+	return &APIAuditLog{filename: filename, file: file}, nil
+}
+
+// Record appends the given entry to the audit log, syncing it to disk.
+func (al *APIAuditLog) Record(entry APIAuditEntry) error {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	al.mu.Lock()
+	defer al.mu.Unlock()
+	if _, err = al.file.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	return al.file.Sync()
+}
+
+// Entries returns the most recent entries of the audit log, recorded at or after the given timestamp,
+// in the order they were recorded. All entries are returned if the limit is 0.
+func (al *APIAuditLog) Entries(since types.Timestamp, limit int) ([]APIAuditEntry, error) {
+	al.mu.Lock()
+	defer al.mu.Unlock()
+	file, err := os.Open(al.filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	entries := []APIAuditEntry{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry APIAuditEntry
+		if err = json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("invalid API audit log entry: %v", err)
+		}
+		if entry.Timestamp < since {
+			continue
+		}
+		entries = append(entries, entry)
+		if limit > 0 && len(entries) > limit {
+			entries = entries[1:]
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// Close closes the audit log.
+func (al *APIAuditLog) Close() error {
+	al.mu.Lock()
+	defer al.mu.Unlock()
+	return al.file.Close()
+}
+
+// IsAuditedAPICall returns true if the API call of the given method can modify the state of the daemon,
+// such as sending coins, banning peers or changing its configuration, and is therefore audited.
+func IsAuditedAPICall(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	default:
+		return true
+	}
+}
+
+// APIAuditHandler is middleware that records the API calls which can modify the state of the daemon,
+// see IsAuditedAPICall, to the given audit log, once answered. The identity of a call is defined
+// by the API token authenticating it, see RequireAPITokenScopeHandler, by the given API password,
+// or by the unix domain socket it is received over, see UnixSocketHandler.
+// Calls are recorded regardless of their outcome, including the ones failing to authenticate.
+func APIAuditHandler(h http.Handler, log *APIAuditLog, password string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !IsAuditedAPICall(req.Method) {
+			h.ServeHTTP(w, req)
+			return
+		}
+		entry := APIAuditEntry{
+			Timestamp:  types.CurrentTimestamp(),
+			Identity:   APIAuditIdentityNone,
+			RemoteAddr: req.RemoteAddr,
+			Method:     req.Method,
+			Path:       req.URL.Path,
+			Summary:    summarizeAPICall(req),
+		}
+		if token, ok := APITokenFromRequest(req); ok {
+			entry.Identity = APIAuditIdentityToken
+			entry.TokenID, entry.TokenName = token.ID, token.Name
+		} else if _, pass, ok := req.BasicAuth(); ok && password != "" && pass == password {
+			entry.Identity = APIAuditIdentityPassword
+		} else if IsUnixSocketRequest(req) {
+			entry.Identity = APIAuditIdentityUnixSocket
+		}
+		sw := &apiAuditStatusWriter{ResponseWriter: w}
+		h.ServeHTTP(sw, req)
+		entry.Status = sw.status
+		if entry.Status == 0 {
+			entry.Status = http.StatusOK
+		}
+		if err := log.Record(entry); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to record %s %s in the API audit log: %v\n", entry.Method, entry.Path, err)
+		}
+	})
+}
+
+// apiAuditStatusWriter is a response writer which remembers the status code of the response.
+type apiAuditStatusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader implements http.ResponseWriter.WriteHeader
+func (sw *apiAuditStatusWriter) WriteHeader(status int) {
+	if sw.status == 0 {
+		sw.status = status
+	}
+	sw.ResponseWriter.WriteHeader(status)
+}
+
+// Write implements http.ResponseWriter.Write
+func (sw *apiAuditStatusWriter) Write(b []byte) (int, error) {
+	if sw.status == 0 {
+		sw.status = http.StatusOK
+	}
+	return sw.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher.Flush,
+// such that calls flushing their response (e.g. /daemon/stop) can be audited.
+func (sw *apiAuditStatusWriter) Flush() {
+	if f, ok := sw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// summarizeAPICall summarizes the parameters of the given API call, given as query string,
+// form-encoded or JSON body, redacting the values of all sensitive parameters.
+// The body is peeked at only, such that it can still be read by the handler of the call.
+func summarizeAPICall(req *http.Request) string {
+	values := url.Values{}
+	for key, vs := range req.URL.Query() {
+		values[key] = vs
+	}
+	var summary string
+	if req.Body != nil {
+		body, err := ioutil.ReadAll(io.LimitReader(req.Body, apiAuditBodyLimit+1))
+		req.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), req.Body), req.Body}
+		switch {
+		case err != nil || len(body) == 0:
+		case len(body) > apiAuditBodyLimit:
+			summary = fmt.Sprintf("<body larger than %d bytes>", apiAuditBodyLimit)
+		case strings.HasPrefix(req.Header.Get("Content-Type"), "application/x-www-form-urlencoded"):
+			form, err := url.ParseQuery(string(body))
+			if err != nil {
+				summary = fmt.Sprintf("<%d bytes>", len(body))
+				break
+			}
+			for key, vs := range form {
+				values[key] = append(values[key], vs...)
+			}
+		default:
+			var obj interface{}
+			if err := json.Unmarshal(body, &obj); err != nil {
+				summary = fmt.Sprintf("<%d bytes>", len(body))
+				break
+			}
+			var buf bytes.Buffer
+			enc := json.NewEncoder(&buf)
+			enc.SetEscapeHTML(false)
+			enc.Encode(redactAPIAuditValue(obj))
+			summary = strings.TrimSpace(buf.String())
+		}
+	}
+	if len(values) > 0 {
+		keys := make([]string, 0, len(values))
+		for key := range values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		params := make([]string, 0, len(keys))
+		for _, key := range keys {
+			value := strings.Join(values[key], ",")
+			if isSensitiveAPIParameter(key) {
+				value = apiAuditRedacted
+			}
+			params = append(params, key+"="+value)
+		}
+		summary = strings.TrimSpace(strings.Join(params, " ") + " " + summary)
+	}
+	if len(summary) > apiAuditSummaryLimit {
+		summary = summary[:apiAuditSummaryLimit] + "..."
+	}
+	return summary
+}
+
+// redactAPIAuditValue redacts the values of all sensitive keys of the given decoded JSON value.
+func redactAPIAuditValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, elem := range v {
+			if isSensitiveAPIParameter(key) {
+				v[key] = apiAuditRedacted
+			} else {
+				v[key] = redactAPIAuditValue(elem)
+			}
+		}
+	case []interface{}:
+		for i, elem := range v {
+			v[i] = redactAPIAuditValue(elem)
+		}
+	}
+	return value
+}
+
+// isSensitiveAPIParameter returns true if the parameter with the given name can contain a secret,
+// such as a passphrase, seed or private key.
+func isSensitiveAPIParameter(name string) bool {
+	name = strings.ToLower(name)
+	for _, sensitive := range []string{"pass", "secret", "seed", "mnemonic", "key"} {
+		if strings.Contains(name, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// DaemonAuditGET contains the entries returned by a GET call to /daemon/audit.
+type DaemonAuditGET struct {
+	Entries []APIAuditEntry `json:"entries"`
+}
+
+// RegisterAuditHTTPHandlers registers the handler for the API call to retrieve the audit log.
+func RegisterAuditHTTPHandlers(router Router, log *APIAuditLog, requiredPassword string) {
+	if log == nil {
+		panic("no API audit log given")
+	}
+	if router == nil {
+		panic("no httprouter Router given")
+	}
+	router.GET("/daemon/audit", RequirePasswordHandler(NewDaemonAuditGetHandler(log), requiredPassword))
+}
+
+// RegisterAuditAPIv2Handlers registers the call of version 2 of the API to retrieve the audit log.
+func RegisterAuditAPIv2Handlers(v2 *APIv2, log *APIAuditLog) {
+	v2.Handle(APIv2Route{
+		Method: http.MethodGet, Path: "/daemon/audit", Tag: "daemon",
+		Summary: "get the most recent API calls modifying the state of the daemon",
+		Query: map[string]string{
+			"since": "the (inclusive) unix timestamp of the first entry",
+			"limit": "the maximum amount of (most recent) entries returned, all entries if 0 or omitted",
+		},
+		Response:      DaemonAuditGET{},
+		Authenticated: true,
+		Handler:       NewDaemonAuditGetHandler(log),
+	})
+}
+
+// NewDaemonAuditGetHandler creates a handler to handle the API call to retrieve the audit log.
+func NewDaemonAuditGetHandler(log *APIAuditLog) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		var (
+			since uint64
+			limit int
+			err   error
+		)
+		if str := req.FormValue("since"); str != "" {
+			since, err = strconv.ParseUint(str, 10, 64)
+			if err != nil {
+				WriteError(w, Error{"invalid since timestamp: " + err.Error()}, http.StatusBadRequest)
+				return
+			}
+		}
+		if str := req.FormValue("limit"); str != "" {
+			limit, err = strconv.Atoi(str)
+			if err != nil || limit < 0 {
+				WriteError(w, Error{fmt.Sprintf("invalid limit %q", str)}, http.StatusBadRequest)
+				return
+			}
+		}
+		entries, err := log.Entries(types.Timestamp(since), limit)
+		if err != nil {
+			WriteError(w, Error{"error after call to /daemon/audit: " + err.Error()}, http.StatusInternalServerError)
+			return
+		}
+		WriteJSON(w, DaemonAuditGET{Entries: entries})
+	}
+}
@@ -0,0 +1,119 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+func TestAPIAuditHandler(t *testing.T) {
+	dir, err := ioutil.TempDir("", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	log, err := NewAPIAuditLog(filepath.Join(dir, APIAuditLogFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer log.Close()
+
+	// the handler can still read the body which is summarized
+	var bodies []string
+	h := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		b, _ := ioutil.ReadAll(req.Body)
+		bodies = append(bodies, string(b))
+		if req.URL.Path == "/gateway/ban" {
+			WriteError(w, Error{"unknown peer"}, http.StatusBadRequest)
+			return
+		}
+		WriteSuccess(w)
+	})
+	handler := APIAuditHandler(h, log, "password")
+
+	calls := []struct {
+		req   *http.Request
+		token *APIToken
+	}{
+		{req: httptest.NewRequest(http.MethodGet, "/wallet", nil)},
+		{
+			req:   httptest.NewRequest(http.MethodPost, "/wallet/coins", strings.NewReader(`{"coinoutputs":[{"value":"1"}],"passphrase":"foo"}`)),
+			token: &APIToken{ID: "01", Name: "payments"},
+		},
+		{req: httptest.NewRequest(http.MethodPost, "/wallet/unlock", strings.NewReader("passphrase=foo"))},
+		{req: httptest.NewRequest(http.MethodPost, "/gateway/ban?addr=1.2.3.4:23112", nil)},
+	}
+	calls[2].req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	calls[3].req.SetBasicAuth("", "password")
+	for _, call := range calls {
+		req := call.req
+		if call.token != nil {
+			req = req.WithContext(context.WithValue(req.Context(), apiTokenContextKey{}, *call.token))
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	if bodies[1] != `{"coinoutputs":[{"value":"1"}],"passphrase":"foo"}` || bodies[2] != "passphrase=foo" {
+		t.Fatalf("unexpected bodies read by the handler: %v", bodies)
+	}
+
+	entries, err := log.Entries(0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []APIAuditEntry{
+		{
+			Identity: APIAuditIdentityToken, TokenID: "01", TokenName: "payments",
+			Method: http.MethodPost, Path: "/wallet/coins",
+			Summary: `{"coinoutputs":[{"value":"1"}],"passphrase":"<redacted>"}`,
+			Status:  http.StatusNoContent,
+		},
+		{
+			Identity: APIAuditIdentityNone,
+			Method:   http.MethodPost, Path: "/wallet/unlock",
+			Summary: "passphrase=<redacted>",
+			Status:  http.StatusNoContent,
+		},
+		{
+			Identity: APIAuditIdentityPassword,
+			Method:   http.MethodPost, Path: "/gateway/ban",
+			Summary: "addr=1.2.3.4:23112",
+			Status:  http.StatusBadRequest,
+		},
+	}
+	if len(entries) != len(expected) {
+		t.Fatalf("expected %d entries, got %d: %v", len(expected), len(entries), entries)
+	}
+	for i, entry := range entries {
+		if entry.Timestamp == 0 || entry.RemoteAddr == "" {
+			t.Errorf("entry %d: missing timestamp or remote address: %v", i, entry)
+		}
+		entry.Timestamp, entry.RemoteAddr = 0, ""
+		if entry != expected[i] {
+			t.Errorf("entry %d: expected %v, got %v", i, expected[i], entry)
+		}
+	}
+
+	// only the most recent entries are returned if limited
+	rec := httptest.NewRecorder()
+	NewDaemonAuditGetHandler(log)(rec, httptest.NewRequest(http.MethodGet, "/daemon/audit?limit=1", nil), httprouter.Params{})
+	var resp DaemonAuditGET
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Entries) != 1 || resp.Entries[0].Path != "/gateway/ban" {
+		t.Fatalf("expected the most recent entry only, got %v", resp.Entries)
+	}
+	rec = httptest.NewRecorder()
+	NewDaemonAuditGetHandler(log)(rec, httptest.NewRequest(http.MethodGet, "/daemon/audit?since=foo", nil), httprouter.Params{})
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected an invalid timestamp to be refused, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/persist"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// DaemonBackupPOST contains the body of a POST call to /daemon/backup.
+type DaemonBackupPOST struct {
+	// Destination is the absolute path of the directory to write the backup to,
+	// created if it doesn't exist yet.
+	Destination string `json:"destination"`
+}
+
+// RegisterBackupHTTPHandlers registers the handlers for the API calls
+// to back up the persistent data of the given modules, while the daemon is running.
+func RegisterBackupHTTPHandlers(router Router, backuppers []modules.Backupper, requiredPassword string) {
+	if router == nil {
+		panic("no httprouter Router given")
+	}
+	router.GET("/daemon/backup", RequirePasswordHandler(NewDaemonBackupGetHandler(backuppers), requiredPassword))
+	router.POST("/daemon/backup", RequirePasswordHandler(NewDaemonBackupPostHandler(backuppers), requiredPassword))
+}
+
+// backupModules backs up all given modules using the given writer.
+func backupModules(bw persist.BackupWriter, backuppers []modules.Backupper) error {
+	for _, backupper := range backuppers {
+		if err := backupper.Backup(bw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewDaemonBackupGetHandler creates a handler to handle the API call
+// streaming a backup of the given modules as a tar archive.
+func NewDaemonBackupGetHandler(backuppers []modules.Backupper) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		w.Header().Set("Content-Type", "application/x-tar")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="backup-%d.tar"`, time.Now().Unix()))
+		bw := persist.NewTarBackupWriter(w)
+		err := backupModules(bw, backuppers)
+		if err == nil {
+			err = bw.Close()
+		}
+		if err != nil {
+			// the status is already written, abort the response,
+			// such that the client does not mistake it for a complete archive
+			panic(http.ErrAbortHandler)
+		}
+	}
+}
+
+// NewDaemonBackupPostHandler creates a handler to handle the API call
+// writing a backup of the given modules to a directory.
+func NewDaemonBackupPostHandler(backuppers []modules.Backupper) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		var body DaemonBackupPOST
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			WriteError(w, Error{"error decoding the supplied backup destination: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		if !filepath.IsAbs(body.Destination) {
+			WriteError(w, Error{"destination must be an absolute path"}, http.StatusBadRequest)
+			return
+		}
+		err := backupModules(persist.NewDirBackupWriter(body.Destination), backuppers)
+		if err != nil {
+			WriteError(w, Error{"failed to back up the daemon: " + err.Error()}, http.StatusInternalServerError)
+			return
+		}
+		WriteSuccess(w)
+	}
+}
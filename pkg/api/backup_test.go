@@ -0,0 +1,90 @@
+package api
+
+import (
+	"archive/tar"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/persist"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// testBackupper backs up a single file with the given content, or fails.
+type testBackupper struct {
+	name, content string
+	err           error
+}
+
+func (b testBackupper) Backup(bw persist.BackupWriter) error {
+	if b.err != nil {
+		return b.err
+	}
+	return bw.WriteFile(b.name, int64(len(b.content)), func(w io.Writer) error {
+		_, err := io.WriteString(w, b.content)
+		return err
+	})
+}
+
+func TestDaemonBackup(t *testing.T) {
+	backuppers := []modules.Backupper{
+		testBackupper{name: "foo/foo.db", content: "foo"},
+		testBackupper{name: "bar/bar.json", content: "bar"},
+	}
+
+	// stream the backup as a tar archive
+	rec := httptest.NewRecorder()
+	NewDaemonBackupGetHandler(backuppers)(rec, httptest.NewRequest(http.MethodGet, "/daemon/backup", nil), httprouter.Params{})
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/x-tar" {
+		t.Fatalf("unexpected response %d: %v", rec.Code, rec.Header())
+	}
+	tr := tar.NewReader(rec.Body)
+	for _, b := range backuppers {
+		hdr, err := tr.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := ioutil.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := b.(testBackupper); hdr.Name != expected.name || string(content) != expected.content {
+			t.Errorf("unexpected file %q: %q", hdr.Name, content)
+		}
+	}
+
+	// write the backup to a directory
+	dir, err := ioutil.TempDir("", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	post := func(backuppers []modules.Backupper, body string) int {
+		rec := httptest.NewRecorder()
+		NewDaemonBackupPostHandler(backuppers)(rec, httptest.NewRequest(http.MethodPost, "/daemon/backup", strings.NewReader(body)), httprouter.Params{})
+		return rec.Code
+	}
+	if code := post(backuppers, `{"destination":"`+filepath.ToSlash(dir)+`"}`); code != http.StatusNoContent {
+		t.Fatalf("expected status 204, got %d", code)
+	}
+	if content, err := ioutil.ReadFile(filepath.Join(dir, "bar", "bar.json")); err != nil || string(content) != "bar" {
+		t.Errorf("unexpected backup file %q: %v", content, err)
+	}
+	for _, body := range []string{"", `{"destination":"relative/path"}`} {
+		if code := post(backuppers, body); code != http.StatusBadRequest {
+			t.Errorf("%q: expected status 400, got %d", body, code)
+		}
+	}
+	failing := []modules.Backupper{testBackupper{err: errors.New("failed")}}
+	if code := post(failing, `{"destination":"`+filepath.ToSlash(dir)+`"}`); code != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", code)
+	}
+}
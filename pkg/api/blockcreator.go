@@ -0,0 +1,48 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/threefoldtech/rivine/modules"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+type (
+	// BlockCreatorGET contains the fields returned by a GET call to "/blockcreator".
+	BlockCreatorGET struct {
+		modules.BlockCreatorStatus
+	}
+
+	// BlockCreatorBlocksGET contains the fields returned by a GET call to "/blockcreator/blocks".
+	BlockCreatorBlocksGET struct {
+		Blocks []modules.BlockCreatorBlock `json:"blocks"`
+	}
+)
+
+// RegisterBlockCreatorHTTPHandlers registers the default Rivine handlers for all default Rivine BlockCreator HTTP endpoints.
+func RegisterBlockCreatorHTTPHandlers(router Router, blockCreator modules.BlockCreator, requiredPassword string) {
+	if blockCreator == nil {
+		panic("no block creator module given")
+	}
+	if router == nil {
+		panic("no httprouter Router given")
+	}
+	router.GET("/blockcreator", RequirePasswordHandler(NewBlockCreatorRootHandler(blockCreator), requiredPassword))
+	router.GET("/blockcreator/blocks", RequirePasswordHandler(NewBlockCreatorBlocksHandler(blockCreator), requiredPassword))
+}
+
+// NewBlockCreatorRootHandler creates a handler to handle the API call to get the block creator status.
+func NewBlockCreatorRootHandler(blockCreator modules.BlockCreator) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		WriteJSON(w, BlockCreatorGET{BlockCreatorStatus: blockCreator.Status()})
+	}
+}
+
+// NewBlockCreatorBlocksHandler creates a handler to handle the API call
+// to get the most recent blocks created by the block creator.
+func NewBlockCreatorBlocksHandler(blockCreator modules.BlockCreator) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		WriteJSON(w, BlockCreatorBlocksGET{Blocks: blockCreator.CreatedBlocks()})
+	}
+}
@@ -4,7 +4,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/bgentry/speakeasy"
 )
@@ -12,6 +16,8 @@ import (
 var (
 	// ErrStatusNotFound is returned when status wasn't found.
 	ErrStatusNotFound = errors.New("expecting a response, but API returned status code 204 No Content")
+	// ErrDaemonUnreachable is returned when no response was received from the daemon.
+	ErrDaemonUnreachable = errors.New("no response from daemon")
 )
 
 // HTTPError is return for HTTP Errors by the HTTPClient
@@ -49,12 +55,26 @@ func DecodeError(resp *http.Response) error {
 	return apiErr
 }
 
+// DefaultRetryDelay is the delay before the first retry of an API call,
+// used if no RetryDelay is defined for the HTTPClient.
+const DefaultRetryDelay = 500 * time.Millisecond
+
 // HTTPClient is used to communicate with the Rivine-based daemon,
 // using the exposed (local) REST API over HTTP.
 type HTTPClient struct {
 	RootURL   string
 	Password  string
 	UserAgent string
+
+	// Client is the HTTP client used to make the API calls,
+	// defining the timeout and TLS config, http.DefaultClient is used if nil.
+	Client *http.Client
+	// Retries is the amount of times a GET call is retried if no response is received,
+	// or the daemon is temporarily unavailable (e.g. a 503 returned by a reverse proxy).
+	// POST calls are only retried if no connection could be made, such that they are never executed twice.
+	Retries int
+	// RetryDelay is the delay before the first retry, doubled for every next retry.
+	RetryDelay time.Duration
 }
 
 // PostResp makes a POST API call and decodes the response. An error is
@@ -123,21 +143,38 @@ func (c *HTTPClient) Get(call string) error {
 // not return 2xx, the error will be read and returned. When no error is returned,
 // the response's body isn't closed, otherwise it is.
 func (c *HTTPClient) apiGet(call string) (*http.Response, error) {
-	resp, err := HTTPGet(c.RootURL+call, c.UserAgent)
+	return c.apiCall(http.MethodGet, call, "")
+}
+
+// ApiPost wraps a POST request with a status code check, such that if the POST
+// does not return 2xx, the error will be read and returned. When no error is returned,
+// the response's body isn't closed, otherwise it is.
+func (c *HTTPClient) apiPost(call, data string) (*http.Response, error) {
+	return c.apiCall(http.MethodPost, call, data)
+}
+
+// apiCall makes an API call, authenticating it with the API password when required,
+// and checks the status code, such that if the call does not return 2xx, the error will be read and returned.
+// When no error is returned, the response's body isn't closed, otherwise it is.
+func (c *HTTPClient) apiCall(method, call, data string) (*http.Response, error) {
+	resp, err := c.do(method, call, data, "")
 	if err != nil {
-		return nil, errors.New("no response from daemon")
+		return nil, err
 	}
 	// check error code
 	if resp.StatusCode == http.StatusUnauthorized {
 		resp.Body.Close()
-		// try again using an authenticated HTTP Post call
+		// try again using an authenticated HTTP call
 		password, err := c.apiPassword()
 		if err != nil {
 			return nil, err
 		}
-		resp, err = HTTPGETAuthenticated(c.RootURL+call, c.UserAgent, password)
+		resp, err = c.do(method, call, data, password)
 		if err != nil {
-			return nil, errors.New("no response from daemon - authentication failed")
+			if err == ErrDaemonUnreachable {
+				return nil, errors.New("no response from daemon - authentication failed")
+			}
+			return nil, err
 		}
 	}
 	if Non2xx(resp.StatusCode) {
@@ -158,28 +195,119 @@ func (c *HTTPClient) apiGet(call string) (*http.Response, error) {
 	return resp, nil
 }
 
-// ApiPost wraps a POST request with a status code check, such that if the POST
-// does not return 2xx, the error will be read and returned. When no error is returned,
-// the response's body isn't closed, otherwise it is.
-func (c *HTTPClient) apiPost(call, data string) (*http.Response, error) {
-	resp, err := HTTPPost(c.RootURL+call, data, c.UserAgent)
-	if err != nil {
-		return nil, errors.New("no response from daemon")
+// do makes a single API call, authenticated using the given password if it is not empty,
+// retrying it as defined by the Retries of the client. A non-2xx response does not return an error.
+// ErrDaemonUnreachable is returned if no response is received, unless the certificate of the daemon is not pinned.
+func (c *HTTPClient) do(method, call, data, password string) (*http.Response, error) {
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
 	}
-	// check error code
-	if resp.StatusCode == http.StatusUnauthorized {
-		resp.Body.Close()
-		// try again using an authenticated HTTP Post call
-		password, err := c.apiPassword()
+	delay := c.RetryDelay
+	if delay == 0 {
+		delay = DefaultRetryDelay
+	}
+	for attempt := 0; ; attempt++ {
+		req, err := newRequest(method, c.RootURL+call, data, c.UserAgent, password)
 		if err != nil {
 			return nil, err
 		}
-		resp, err = HTTPPostAuthenticated(c.RootURL+call, data, c.UserAgent, password)
+		resp, err := client.Do(req)
+		if attempt < c.Retries && retryable(method, resp, err) {
+			wait := delay
+			if resp != nil {
+				resp.Body.Close()
+				if retryAfter := retryAfterDelay(resp); retryAfter > wait {
+					wait = retryAfter
+				}
+			}
+			time.Sleep(wait)
+			delay *= 2
+			continue
+		}
 		if err != nil {
-			return nil, errors.New("no response from daemon - authentication failed")
+			var pinErr *UnpinnedCertificateError
+			if errors.As(err, &pinErr) {
+				return nil, pinErr
+			}
+			return nil, ErrDaemonUnreachable
 		}
+		return resp, nil
 	}
-	if Non2xx(resp.StatusCode) {
+}
+
+// retryable returns true if an API call using the given method,
+// resulting in the given response or error, can be retried.
+func retryable(method string, resp *http.Response, err error) bool {
+	if err != nil {
+		var pinErr *UnpinnedCertificateError
+		if errors.As(err, &pinErr) {
+			return false
+		}
+		// a POST call might have been executed already, unless no connection could be made
+		var opErr *net.OpError
+		return method == http.MethodGet || (errors.As(err, &opErr) && opErr.Op == "dial")
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		// the call was refused by the rate limits of the daemon, prior to being executed
+		return true
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return method == http.MethodGet
+	default:
+		return false
+	}
+}
+
+// retryAfterDelay returns the delay defined by the Retry-After header
+// (in seconds) of the given response, 0 if not defined.
+func retryAfterDelay(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// Subscribe opens a websocket connection for the given API call (e.g. /consensus/events),
+// over which the daemon pushes JSON-encoded messages. An error is returned if the upgrade is refused.
+// The stream is not subject to the timeout of the HTTP client, and should be closed by the caller.
+func (c *HTTPClient) Subscribe(call string) (*WebSocketStream, error) {
+	client := http.Client{}
+	if c.Client != nil {
+		client = *c.Client
+	}
+	client.Timeout = 0
+	password := c.Password
+	for {
+		req, err := newRequest(http.MethodGet, c.RootURL+call, "", c.UserAgent, password)
+		if err != nil {
+			return nil, err
+		}
+		stream, resp, err := newWebSocketStream(&client, req)
+		if err != nil {
+			var pinErr *UnpinnedCertificateError
+			if errors.As(err, &pinErr) {
+				return nil, pinErr
+			}
+			var urlErr *url.Error
+			if errors.As(err, &urlErr) {
+				return nil, ErrDaemonUnreachable
+			}
+			return nil, err
+		}
+		if stream != nil {
+			return stream, nil
+		}
+		if resp.StatusCode == http.StatusUnauthorized && password == "" {
+			resp.Body.Close()
+			// try again using an authenticated HTTP call
+			password, err = c.apiPassword()
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
 		if resp.StatusCode == http.StatusNotFound {
 			resp.Body.Close()
 			return nil, &HTTPError{
@@ -187,14 +315,13 @@ func (c *HTTPClient) apiPost(call, data string) (*http.Response, error) {
 				statusCode:    resp.StatusCode,
 			}
 		}
-		err := DecodeError(resp)
+		err = DecodeError(resp)
 		resp.Body.Close()
 		return nil, &HTTPError{
 			internalError: err,
 			statusCode:    resp.StatusCode,
 		}
 	}
-	return resp, nil
 }
 
 func (c *HTTPClient) apiPassword() (string, error) {
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestHTTPClientRetries ensures GET calls are retried when the daemon is temporarily unavailable,
+// while POST calls which reached the daemon are not.
+func TestHTTPClientRetries(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		calls++
+		if calls%3 != 0 {
+			WriteError(w, Error{"try again later"}, http.StatusServiceUnavailable)
+			return
+		}
+		WriteJSON(w, TransactionPoolPOST{})
+	}))
+	defer server.Close()
+
+	client := &HTTPClient{RootURL: server.URL, Retries: 2, RetryDelay: time.Millisecond}
+	var resp TransactionPoolPOST
+	err := client.GetAPI("/foo", &resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 calls, got %d", calls)
+	}
+
+	calls = 0
+	err = client.PostResp("/foo", "", &resp)
+	if err == nil {
+		t.Fatal("expected the POST call to fail")
+	}
+	if calls != 1 {
+		t.Fatalf("expected the POST call not to be retried, got %d calls", calls)
+	}
+
+	// without retries the first failure is returned
+	calls = 0
+	client.Retries = 0
+	err = client.GetAPI("/foo", &resp)
+	if err == nil || calls != 1 {
+		t.Fatalf("expected a single failed call, got %d calls: %v", calls, err)
+	}
+}
+
+// TestHTTPClientRetriesTooManyRequests ensures calls refused by the rate limits of the daemon
+// are retried, including POST calls, as they were not executed.
+func TestHTTPClientRetriesTooManyRequests(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "0")
+			WriteError(w, Error{"too many API calls"}, http.StatusTooManyRequests)
+			return
+		}
+		WriteJSON(w, TransactionPoolPOST{})
+	}))
+	defer server.Close()
+
+	client := &HTTPClient{RootURL: server.URL, Retries: 1, RetryDelay: time.Millisecond}
+	var resp TransactionPoolPOST
+	if err := client.PostResp("/foo", "", &resp); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected 2 calls, got %d", calls)
+	}
+}
+
+// TestHTTPClientTLSPins ensures only the pinned (self-signed) certificate of the daemon is accepted.
+func TestHTTPClientTLSPins(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		WriteJSON(w, TransactionPoolPOST{})
+	}))
+	defer server.Close()
+	pin := PublicKeyPin(server.Certificate())
+
+	for _, pins := range [][]string{{pin}, {"sha256//47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=", pin[len("sha256//"):]}} {
+		config, err := NewPinnedTLSConfig(pins)
+		if err != nil {
+			t.Fatal(err)
+		}
+		client := &HTTPClient{
+			RootURL: server.URL,
+			Client:  &http.Client{Transport: &http.Transport{TLSClientConfig: config}},
+		}
+		var resp TransactionPoolPOST
+		if err = client.GetAPI("/", &resp); err != nil {
+			t.Errorf("pins %v: %v", pins, err)
+		}
+	}
+
+	config, err := NewPinnedTLSConfig([]string{"sha256//47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &HTTPClient{
+		RootURL: server.URL,
+		Client:  &http.Client{Transport: &http.Transport{TLSClientConfig: config}},
+		Retries: 1,
+	}
+	var resp TransactionPoolPOST
+	err = client.GetAPI("/", &resp)
+	if pinErr, ok := err.(*UnpinnedCertificateError); !ok || pinErr.Pin != pin {
+		t.Fatalf("expected an unpinned certificate error for %s, got: %v", pin, err)
+	}
+
+	for _, pins := range [][]string{nil, {"foo"}, {"sha256//AAAA"}} {
+		if _, err = NewPinnedTLSConfig(pins); err == nil {
+			t.Errorf("expected an error for invalid pins %v", pins)
+		}
+	}
+}
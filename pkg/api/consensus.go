@@ -4,7 +4,10 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"sync"
 
+	"github.com/threefoldtech/rivine/crypto"
 	"github.com/threefoldtech/rivine/modules"
 	"github.com/threefoldtech/rivine/types"
 
@@ -62,6 +65,11 @@ type (
 		Height       types.BlockHeight `json:"height"`
 		CurrentBlock types.BlockID     `json:"currentblock"`
 		Target       types.Target      `json:"target"`
+
+		EstimatedNetworkHeight types.BlockHeight `json:"estimatednetworkheight"`
+		BlocksPerSecond        float64           `json:"blockspersecond"`
+		SyncProgress           float64           `json:"syncprogress"`
+		SyncETA                uint64            `json:"synceta"`
 	}
 
 	// ConsensusGetTransaction is the object returned by a GET request to
@@ -71,6 +79,12 @@ type (
 		TxShortID types.TransactionShortID `json:"shortid,omitempty"`
 	}
 
+	// ConsensusGetBlock is the object returned by a GET request to
+	// /consensus/blocks/:height
+	ConsensusGetBlock struct {
+		Block types.Block `json:"block"`
+	}
+
 	// ConsensusGetUnspentCoinOutput is the object returned by a GET request to
 	// /consensus/unspent/coinoutput/:id
 	ConsensusGetUnspentCoinOutput struct {
@@ -82,6 +96,44 @@ type (
 	ConsensusGetUnspentBlockstakeOutput struct {
 		Output types.BlockStakeOutput `json:"output"`
 	}
+
+	// ConsensusGetUnspentOutputs is the object returned by a GET request to
+	// /consensus/unspent?unlockhash=:unlockhash or /consensus/unspent/unlockhashes/:unlockhash
+	ConsensusGetUnspentOutputs struct {
+		modules.UnspentOutputs
+	}
+
+	// ConsensusGetStatistics is the object returned by a GET request to
+	// /consensus/statistics
+	ConsensusGetStatistics struct {
+		modules.BlockStatistics
+	}
+
+	// ConsensusWaitGET is the object returned by a GET request to /consensus/wait,
+	// once its condition is met or it timed out.
+	ConsensusWaitGET struct {
+		// Reached is true if the condition is met, false if the call timed out.
+		Reached      bool              `json:"reached"`
+		Height       types.BlockHeight `json:"height"`
+		CurrentBlock types.BlockID     `json:"currentblock"`
+	}
+
+	// ConsensusGetSubscribers is the object returned by a GET request to
+	// /consensus/subscribers
+	ConsensusGetSubscribers struct {
+		Subscribers []modules.SubscriberStats `json:"subscribers"`
+	}
+
+	// ConsensusEvent is the message pushed for every consensus change
+	// over the websocket connection opened by a GET request to /consensus/events
+	ConsensusEvent struct {
+		// Height is the height of the consensus set at the time the event is sent.
+		Height types.BlockHeight `json:"height"`
+		// RevertedBlocks are the IDs of the reverted blocks, in the order they were reverted.
+		RevertedBlocks []types.BlockID `json:"revertedblocks,omitempty"`
+		// AppliedBlocks are the IDs of the applied blocks, in the order they were applied.
+		AppliedBlocks []types.BlockID `json:"appliedblocks"`
+	}
 )
 
 // RegisterConsensusHTTPHandlers registers the default Rivine handlers for all default Rivine Consensus HTTP endpoints.
@@ -95,8 +147,89 @@ func RegisterConsensusHTTPHandlers(router Router, cs modules.ConsensusSet) {
 
 	router.GET("/consensus", NewConsensusRootHandler(cs))
 	router.GET("/consensus/transactions/:id", NewConsensusGetTransactionHandler(cs))
+	router.GET("/consensus/blocks/:height", NewConsensusGetBlockHandler(cs))
 	router.GET("/consensus/unspent/coinoutputs/:id", NewConsensusGetUnspentCoinOutputHandler(cs))
 	router.GET("/consensus/unspent/blockstakeoutputs/:id", NewConsensusGetUnspentBlockstakeOutputHandler(cs))
+	router.GET("/consensus/unspent", NewConsensusGetUnspentOutputsHandler(cs))
+	router.GET("/consensus/unspent/unlockhashes/:unlockhash", NewConsensusGetUnspentOutputsByUnlockHashHandler(cs))
+	router.GET("/consensus/statistics", NewConsensusGetStatisticsHandler(cs))
+	router.GET("/consensus/subscribers", NewConsensusGetSubscribersHandler(cs))
+	router.GET("/consensus/events", NewConsensusGetEventsHandler(cs))
+	router.GET("/consensus/wait", NewConsensusGetWaitHandler(cs))
+}
+
+// RegisterConsensusAPIv2Handlers registers the Consensus calls of version 2 of the API.
+func RegisterConsensusAPIv2Handlers(v2 *APIv2, cs modules.ConsensusSet) {
+	if cs == nil {
+		panic("no consensus module given")
+	}
+	v2.Handle(APIv2Route{
+		Method: http.MethodGet, Path: "/consensus", Tag: "consensus",
+		Summary:  "get the state of the consensus set",
+		Response: ConsensusGET{},
+		Handler:  NewConsensusRootHandler(cs),
+	})
+	v2.Handle(APIv2Route{
+		Method: http.MethodGet, Path: "/consensus/transactions/:id", Tag: "consensus",
+		Summary:  "get a confirmed transaction by its (short) ID",
+		Response: ConsensusGetTransaction{},
+		Handler:  NewConsensusGetTransactionHandler(cs),
+	})
+	v2.Handle(APIv2Route{
+		Method: http.MethodGet, Path: "/consensus/blocks/:height", Tag: "consensus",
+		Summary:  "get the block at a height of the current chain",
+		Response: ConsensusGetBlock{},
+		Handler:  NewConsensusGetBlockHandler(cs),
+	})
+	v2.Handle(APIv2Route{
+		Method: http.MethodGet, Path: "/consensus/unspent/coinoutputs/:id", Tag: "consensus",
+		Summary:  "get an unspent coin output",
+		Response: ConsensusGetUnspentCoinOutput{},
+		Handler:  NewConsensusGetUnspentCoinOutputHandler(cs),
+	})
+	v2.Handle(APIv2Route{
+		Method: http.MethodGet, Path: "/consensus/unspent/blockstakeoutputs/:id", Tag: "consensus",
+		Summary:  "get an unspent blockstake output",
+		Response: ConsensusGetUnspentBlockstakeOutput{},
+		Handler:  NewConsensusGetUnspentBlockstakeOutputHandler(cs),
+	})
+	v2.Handle(APIv2Route{
+		Method: http.MethodGet, Path: "/consensus/unspent", Tag: "consensus",
+		Summary:  "get all unspent outputs locked by the given unlock hash",
+		Query:    map[string]string{"unlockhash": "the unlock hash (address), required"},
+		Response: ConsensusGetUnspentOutputs{},
+		Handler:  NewConsensusGetUnspentOutputsHandler(cs),
+	})
+	v2.Handle(APIv2Route{
+		Method: http.MethodGet, Path: "/consensus/unspent/unlockhashes/:unlockhash", Tag: "consensus",
+		Summary:  "get all unspent outputs locked by an unlock hash",
+		Response: ConsensusGetUnspentOutputs{},
+		Handler:  NewConsensusGetUnspentOutputsByUnlockHashHandler(cs),
+	})
+	v2.Handle(APIv2Route{
+		Method: http.MethodGet, Path: "/consensus/statistics", Tag: "consensus",
+		Summary:  "get rolling block statistics",
+		Query:    map[string]string{"window": "the amount of blocks the statistics are computed over"},
+		Response: ConsensusGetStatistics{},
+		Handler:  NewConsensusGetStatisticsHandler(cs),
+	})
+	v2.Handle(APIv2Route{
+		Method: http.MethodGet, Path: "/consensus/subscribers", Tag: "consensus",
+		Summary:  "get the dispatch metrics of the consensus set subscribers",
+		Response: ConsensusGetSubscribers{},
+		Handler:  NewConsensusGetSubscribersHandler(cs),
+	})
+	v2.Handle(APIv2Route{
+		Method: http.MethodGet, Path: "/consensus/wait", Tag: "consensus",
+		Summary: "wait until the chain reaches a height or its tip changes",
+		Query: map[string]string{
+			"height":       "wait until the height of the chain is at least this height",
+			"currentblock": "wait until the current block differs from this block ID",
+			"timeout":      "the maximum duration to wait, e.g. 90s, 30s by default and 5m at most",
+		},
+		Response: ConsensusWaitGET{},
+		Handler:  NewConsensusGetWaitHandler(cs),
+	})
 }
 
 // NewConsensusRootHandler creates a handler to handle the API calls to /consensus.
@@ -104,11 +237,17 @@ func NewConsensusRootHandler(cs modules.ConsensusSet) httprouter.Handle {
 	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 		cbid := cs.CurrentBlock().ID()
 		currentTarget, _ := cs.ChildTarget(cbid)
+		sp := cs.SyncProgress()
 		WriteJSON(w, ConsensusGET{
-			Synced:       cs.Synced(),
-			Height:       cs.Height(),
+			Synced:       sp.Synced,
+			Height:       sp.Height,
 			CurrentBlock: cbid,
 			Target:       currentTarget,
+
+			EstimatedNetworkHeight: sp.EstimatedNetworkHeight,
+			BlocksPerSecond:        sp.BlocksPerSecond,
+			SyncProgress:           sp.Progress,
+			SyncETA:                sp.ETA,
 		})
 	}
 }
@@ -146,6 +285,23 @@ func NewConsensusGetTransactionHandler(cs modules.ConsensusSet) httprouter.Handl
 	}
 }
 
+// NewConsensusGetBlockHandler creates a handler to handle the API calls to /consensus/blocks/:height.
+func NewConsensusGetBlockHandler(cs modules.ConsensusSet) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		height, err := strconv.ParseUint(ps.ByName("height"), 10, 64)
+		if err != nil {
+			WriteError(w, Error{"invalid block height: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		block, exists := cs.BlockAtHeight(types.BlockHeight(height))
+		if !exists {
+			WriteError(w, Error{fmt.Sprintf("no block at height %d", height)}, http.StatusNoContent)
+			return
+		}
+		WriteJSON(w, ConsensusGetBlock{Block: block})
+	}
+}
+
 // NewConsensusGetUnspentCoinOutputHandler creates a handler to handle lookups of unspent coin outputs.
 func NewConsensusGetUnspentCoinOutputHandler(cs modules.ConsensusSet) httprouter.Handle {
 	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
@@ -201,3 +357,213 @@ func NewConsensusGetUnspentBlockstakeOutputHandler(cs modules.ConsensusSet) http
 		WriteJSON(w, ConsensusGetUnspentBlockstakeOutput{Output: output})
 	}
 }
+
+// NewConsensusGetUnspentOutputsByUnlockHashHandler creates a handler to handle lookups
+// of all unspent coin and blockstake outputs locked by a given unlock hash,
+// which requires the unlock hash index of the consensus set to be enabled.
+func NewConsensusGetUnspentOutputsByUnlockHashHandler(cs modules.ConsensusSet) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		writeUnspentOutputsByUnlockHash(w, cs, ps.ByName("unlockhash"))
+	}
+}
+
+// NewConsensusGetUnspentOutputsHandler creates a handler to handle lookups
+// of all unspent coin and blockstake outputs locked by the unlock hash given
+// as the required unlockhash query parameter, such that external wallets can fund
+// transactions for addresses not owned by the wallet of the daemon.
+// It requires the unlock hash index of the consensus set to be enabled.
+func NewConsensusGetUnspentOutputsHandler(cs modules.ConsensusSet) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		str := req.URL.Query().Get("unlockhash")
+		if str == "" {
+			WriteError(w, Error{"no unlock hash given"}, http.StatusBadRequest)
+			return
+		}
+		writeUnspentOutputsByUnlockHash(w, cs, str)
+	}
+}
+
+// writeUnspentOutputsByUnlockHash writes all unspent coin and blockstake outputs
+// locked by the given (string-encoded) unlock hash.
+func writeUnspentOutputsByUnlockHash(w http.ResponseWriter, cs modules.ConsensusSet, str string) {
+	var uh types.UnlockHash
+	err := uh.LoadString(str)
+	if err != nil {
+		WriteError(w, Error{"invalid unlock hash: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	outputs, err := cs.UnspentOutputsByUnlockHash(uh)
+	if err != nil {
+		if err == modules.ErrUnlockHashIndexDisabled {
+			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+			return
+		}
+		WriteError(w, Error{err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	WriteJSON(w, ConsensusGetUnspentOutputs{UnspentOutputs: outputs})
+}
+
+// NewConsensusGetStatisticsHandler creates a handler to handle the API calls to /consensus/statistics,
+// returning rolling block statistics computed over an optional window of blocks.
+func NewConsensusGetStatisticsHandler(cs modules.ConsensusSet) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		var window types.BlockHeight
+		if str := req.FormValue("window"); str != "" {
+			n, err := strconv.ParseUint(str, 10, 64)
+			if err != nil {
+				WriteError(w, Error{"invalid window: " + err.Error()}, http.StatusBadRequest)
+				return
+			}
+			window = types.BlockHeight(n)
+		}
+		stats, err := cs.BlockStatistics(window)
+		if err != nil {
+			WriteError(w, Error{err.Error()}, http.StatusInternalServerError)
+			return
+		}
+		WriteJSON(w, ConsensusGetStatistics{BlockStatistics: stats})
+	}
+}
+
+// NewConsensusGetSubscribersHandler creates a handler to handle the API calls to /consensus/subscribers,
+// returning the dispatch metrics of all subscribers of the consensus set.
+func NewConsensusGetSubscribersHandler(cs modules.ConsensusSet) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		subscribers := cs.SubscriberStats()
+		if subscribers == nil {
+			subscribers = []modules.SubscriberStats{}
+		}
+		WriteJSON(w, ConsensusGetSubscribers{Subscribers: subscribers})
+	}
+}
+
+// NewConsensusGetWaitHandler creates a handler to handle the long-polling API calls to /consensus/wait,
+// which block until the height of the chain reaches the height query parameter,
+// and/or the current block differs from the currentblock query parameter,
+// such that clients can follow the tip of the chain without polling it continuously.
+// The call returns the current state once the condition is met, or once the timeout expires.
+func NewConsensusGetWaitHandler(cs modules.ConsensusSet) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		var (
+			height    types.BlockHeight
+			hasHeight bool
+			blockID   types.BlockID
+			hasBlock  bool
+		)
+		if str := req.FormValue("height"); str != "" {
+			n, err := strconv.ParseUint(str, 10, 64)
+			if err != nil {
+				WriteError(w, Error{"invalid height: " + err.Error()}, http.StatusBadRequest)
+				return
+			}
+			height, hasHeight = types.BlockHeight(n), true
+		}
+		if str := req.FormValue("currentblock"); str != "" {
+			err := (*crypto.Hash)(&blockID).LoadString(str)
+			if err != nil {
+				WriteError(w, Error{"invalid current block: " + err.Error()}, http.StatusBadRequest)
+				return
+			}
+			hasBlock = true
+		}
+		if !hasHeight && !hasBlock {
+			WriteError(w, Error{"a height and/or current block to wait for is required"}, http.StatusBadRequest)
+			return
+		}
+		timeout, err := parseWaitTimeout(req)
+		if err != nil {
+			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+			return
+		}
+
+		var resp ConsensusWaitGET
+		resp.Reached, err = waitUntil(req, cs, nil, timeout, func() bool {
+			block := cs.CurrentBlock()
+			resp.Height, resp.CurrentBlock = cs.Height(), block.ID()
+			return (!hasHeight || resp.Height >= height) && (!hasBlock || resp.CurrentBlock != blockID)
+		})
+		if err != nil {
+			if req.Context().Err() == nil {
+				WriteError(w, Error{err.Error()}, http.StatusInternalServerError)
+			}
+			return
+		}
+		WriteJSON(w, resp)
+	}
+}
+
+// consensusEventBufferSize is the amount of consensus events
+// buffered for a single event stream, before the client is considered too slow.
+const consensusEventBufferSize = 64
+
+// consensusEventStream is a ConsensusSetSubscriber
+// buffering the events to be sent to a single client.
+type consensusEventStream struct {
+	events   chan ConsensusEvent
+	overflow chan struct{}
+	once     sync.Once
+}
+
+// ProcessConsensusChange implements modules.ConsensusSetSubscriber.ProcessConsensusChange
+func (stream *consensusEventStream) ProcessConsensusChange(cc modules.ConsensusChange) {
+	var event ConsensusEvent
+	for _, block := range cc.RevertedBlocks {
+		event.RevertedBlocks = append(event.RevertedBlocks, block.ID())
+	}
+	for _, block := range cc.AppliedBlocks {
+		event.AppliedBlocks = append(event.AppliedBlocks, block.ID())
+	}
+	select {
+	case stream.events <- event:
+	default:
+		// never block the consensus set, drop the client instead
+		stream.once.Do(func() { close(stream.overflow) })
+	}
+}
+
+// NewConsensusGetEventsHandler creates a handler
+// to handle the API call to stream the consensus changes over a websocket connection.
+// The connection is closed when the client falls behind by more than consensusEventBufferSize events.
+func NewConsensusGetEventsHandler(cs modules.ConsensusSet) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		ws, err := upgradeWebSocket(w, req)
+		if err != nil {
+			WriteError(w, Error{"failed to open websocket connection: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		defer ws.Close()
+
+		stream := &consensusEventStream{
+			events:   make(chan ConsensusEvent, consensusEventBufferSize),
+			overflow: make(chan struct{}),
+		}
+		closed := make(chan struct{})
+		err = cs.ConsensusSetSubscribe(stream, modules.ConsensusChangeRecent, closed)
+		if err != nil {
+			ws.writeFrame(websocketOpClose, nil)
+			return
+		}
+		defer cs.Unsubscribe(stream)
+
+		go func() {
+			ws.readLoop()
+			close(closed)
+		}()
+		for {
+			select {
+			case event := <-stream.events:
+				// the height is only known outside of the consensus set lock
+				event.Height = cs.Height()
+				if err := ws.WriteJSON(event); err != nil {
+					return
+				}
+			case <-stream.overflow:
+				ws.writeFrame(websocketOpClose, nil)
+				return
+			case <-closed:
+				return
+			}
+		}
+	}
+}
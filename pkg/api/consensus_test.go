@@ -0,0 +1,117 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// testUnlockHashIndex is a consensus set of which the unlock hash index contains the given outputs.
+type testUnlockHashIndex struct {
+	modules.ConsensusSet
+	outputs map[types.UnlockHash]modules.UnspentOutputs
+}
+
+func (cs testUnlockHashIndex) UnspentOutputsByUnlockHash(uh types.UnlockHash) (modules.UnspentOutputs, error) {
+	if cs.outputs == nil {
+		return modules.UnspentOutputs{}, modules.ErrUnlockHashIndexDisabled
+	}
+	return cs.outputs[uh], nil
+}
+
+func TestConsensusGetUnspentOutputs(t *testing.T) {
+	uh := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: [32]byte{1}}
+	outputs := modules.UnspentOutputs{
+		CoinOutputs: []modules.UnspentCoinOutput{{
+			ID:     types.CoinOutputID{2},
+			Output: types.CoinOutput{Value: types.NewCurrency64(42), Condition: types.NewCondition(types.NewUnlockHashCondition(uh))},
+		}},
+	}
+	get := func(cs modules.ConsensusSet, query string) (int, ConsensusGetUnspentOutputs) {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/consensus/unspent"+query, nil)
+		NewConsensusGetUnspentOutputsHandler(cs)(rec, req, httprouter.Params{})
+		var resp ConsensusGetUnspentOutputs
+		if rec.Code == http.StatusOK {
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return rec.Code, resp
+	}
+
+	cs := testUnlockHashIndex{outputs: map[types.UnlockHash]modules.UnspentOutputs{uh: outputs}}
+	code, resp := get(cs, "?unlockhash="+uh.String())
+	if code != http.StatusOK || len(resp.CoinOutputs) != 1 || resp.CoinOutputs[0].ID != outputs.CoinOutputs[0].ID ||
+		!resp.CoinOutputs[0].Output.Value.Equals64(42) {
+		t.Fatalf("unexpected response %d: %+v", code, resp)
+	}
+	for _, query := range []string{"", "?unlockhash=invalid"} {
+		if code, _ = get(cs, query); code != http.StatusBadRequest {
+			t.Errorf("%q: expected status 400, got %d", query, code)
+		}
+	}
+	// the unlock hash index is required
+	if code, _ = get(testUnlockHashIndex{}, "?unlockhash="+uh.String()); code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for a disabled index, got %d", code)
+	}
+}
+
+// testSubscriberStats is a consensus set reporting the given subscriber stats.
+type testSubscriberStats struct {
+	modules.ConsensusSet
+	stats []modules.SubscriberStats
+}
+
+func (cs testSubscriberStats) SubscriberStats() []modules.SubscriberStats {
+	return cs.stats
+}
+
+func TestConsensusGetSubscribers(t *testing.T) {
+	get := func(cs modules.ConsensusSet) (string, ConsensusGetSubscribers) {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/consensus/subscribers", nil)
+		NewConsensusGetSubscribersHandler(cs)(rec, req, httprouter.Params{})
+		if rec.Code != http.StatusOK {
+			t.Fatalf("unexpected status %d: %s", rec.Code, rec.Body.String())
+		}
+		var resp ConsensusGetSubscribers
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		return rec.Body.String(), resp
+	}
+
+	// no subscribers are reported as an empty list
+	body, resp := get(testSubscriberStats{})
+	if len(resp.Subscribers) != 0 || !strings.Contains(body, `"subscribers":[]`) {
+		t.Fatal("unexpected response:", body)
+	}
+
+	stats := []modules.SubscriberStats{
+		{Name: "*explorer.Explorer"},
+		{
+			Name: "*api.waitNotifier", Async: true,
+			OverflowPolicy: modules.SubscriberOverflowDropOldest,
+			QueueSize:      1, QueueLength: 1, Delivered: 3, Dropped: 2,
+			LastLag: time.Millisecond, MaxLag: time.Second,
+		},
+	}
+	body, resp = get(testSubscriberStats{stats: stats})
+	if !reflect.DeepEqual(resp.Subscribers, stats) {
+		t.Fatalf("unexpected subscribers: %+v", resp.Subscribers)
+	}
+	// the overflow policy is reported by name
+	if !strings.Contains(body, `"overflowpolicy":"dropoldest"`) {
+		t.Fatal("unexpected response:", body)
+	}
+}
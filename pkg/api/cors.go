@@ -0,0 +1,95 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// CORSConfig defines the cross-origin requests accepted by the API,
+// such that browser-based applications can call it directly.
+type CORSConfig struct {
+	// AllowedOrigins are the origins (e.g. https://wallet.example.com) allowed to call the API,
+	// "*" allows any origin. No cross-origin requests are allowed if empty.
+	// Only origins which are listed explicitly are exempt from the RequireUserAgentHandler,
+	// such that a wildcard never allows an arbitrary page to forge requests.
+	AllowedOrigins []string
+	// AllowedMethods are the HTTP methods cross-origin requests can use.
+	AllowedMethods []string
+	// AllowedHeaders are the (non-simple) headers cross-origin requests can set.
+	AllowedHeaders []string
+}
+
+// corsPreflightMaxAge is the time, in seconds, browsers can cache the result of a preflight request.
+const corsPreflightMaxAge = 600
+
+// corsOriginContextKey is the key of the explicitly allowed origin of a cross-origin request, stored in its context.
+type corsOriginContextKey struct{}
+
+// isAllowedCORSOrigin returns true if the given request was made by a browser
+// on behalf of an origin explicitly allowed by the CORSHandler.
+func isAllowedCORSOrigin(req *http.Request) bool {
+	_, ok := req.Context().Value(corsOriginContextKey{}).(string)
+	return ok
+}
+
+// CORSHandler is middleware that allows cross-origin requests from the configured origins,
+// answering preflight requests itself. Requests without an allowed origin are passed on
+// unchanged, such that browsers refuse to expose the response to the calling page.
+// As browsers cannot set the user agent, requests of an explicitly allowed origin are exempt
+// from the RequireUserAgentHandler. Origins only allowed by the "*" wildcard are not.
+func CORSHandler(h http.Handler, cfg CORSConfig) http.Handler {
+	if len(cfg.AllowedOrigins) == 0 {
+		return h
+	}
+	allowedMethods := strings.Join(cfg.AllowedMethods, ", ")
+	allowedHeaders := strings.Join(cfg.AllowedHeaders, ", ")
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		origin := req.Header.Get("Origin")
+		listed := containsFold(cfg.AllowedOrigins, origin)
+		if origin == "" || !(listed || containsFold(cfg.AllowedOrigins, "*")) {
+			h.ServeHTTP(w, req)
+			return
+		}
+		w.Header().Add("Vary", "Origin")
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+
+		requestMethod := req.Header.Get("Access-Control-Request-Method")
+		if req.Method != http.MethodOptions || requestMethod == "" {
+			if listed {
+				req = req.WithContext(context.WithValue(req.Context(), corsOriginContextKey{}, origin))
+			}
+			h.ServeHTTP(w, req)
+			return
+		}
+
+		// answer the preflight request
+		if !containsFold(cfg.AllowedMethods, requestMethod) {
+			WriteError(w, Error{"cross-origin requests using method " + requestMethod + " are not allowed"}, http.StatusForbidden)
+			return
+		}
+		for _, header := range strings.Split(req.Header.Get("Access-Control-Request-Headers"), ",") {
+			header = strings.TrimSpace(header)
+			if header != "" && !containsFold(cfg.AllowedHeaders, header) {
+				WriteError(w, Error{"cross-origin requests setting header " + header + " are not allowed"}, http.StatusForbidden)
+				return
+			}
+		}
+		w.Header().Set("Access-Control-Allow-Methods", allowedMethods)
+		w.Header().Set("Access-Control-Allow-Headers", allowedHeaders)
+		w.Header().Set("Access-Control-Max-Age", strconv.Itoa(corsPreflightMaxAge))
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// containsFold returns true if the given values contain the given value,
+// compared case-insensitively.
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCORSHandler(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) { WriteSuccess(w) })
+	handler := CORSHandler(RequireUserAgentHandler(ok, "Rivine-Agent"), CORSConfig{
+		AllowedOrigins: []string{"https://wallet.example.com"},
+		AllowedMethods: []string{http.MethodGet, http.MethodPost},
+		AllowedHeaders: []string{"Authorization", "Content-Type"},
+	})
+
+	testCases := []struct {
+		Method, Origin, RequestMethod, RequestHeaders string
+		StatusCode                                    int
+		AllowOrigin                                   string
+	}{
+		// requests without an (allowed) origin require the user agent
+		{http.MethodGet, "", "", "", http.StatusBadRequest, ""},
+		{http.MethodGet, "https://evil.example.com", "", "", http.StatusBadRequest, ""},
+		// requests of an allowed origin don't
+		{http.MethodGet, "https://wallet.example.com", "", "", http.StatusNoContent, "https://wallet.example.com"},
+		// preflight requests
+		{http.MethodOptions, "https://wallet.example.com", http.MethodPost, "authorization, content-type", http.StatusNoContent, "https://wallet.example.com"},
+		{http.MethodOptions, "https://wallet.example.com", http.MethodDelete, "", http.StatusForbidden, "https://wallet.example.com"},
+		{http.MethodOptions, "https://wallet.example.com", http.MethodGet, "X-Custom", http.StatusForbidden, "https://wallet.example.com"},
+	}
+	for _, tc := range testCases {
+		req := httptest.NewRequest(tc.Method, "/wallet", nil)
+		if tc.Origin != "" {
+			req.Header.Set("Origin", tc.Origin)
+		}
+		if tc.RequestMethod != "" {
+			req.Header.Set("Access-Control-Request-Method", tc.RequestMethod)
+			req.Header.Set("Access-Control-Request-Headers", tc.RequestHeaders)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tc.StatusCode || rec.Header().Get("Access-Control-Allow-Origin") != tc.AllowOrigin {
+			t.Errorf("%s (origin %q, preflight %s %q): unexpected response %d (allowed origin %q)",
+				tc.Method, tc.Origin, tc.RequestMethod, tc.RequestHeaders, rec.Code, rec.Header().Get("Access-Control-Allow-Origin"))
+		}
+		if tc.RequestMethod != "" && tc.StatusCode == http.StatusNoContent && rec.Header().Get("Access-Control-Allow-Methods") != "GET, POST" {
+			t.Errorf("unexpected allowed methods: %q", rec.Header().Get("Access-Control-Allow-Methods"))
+		}
+	}
+}
+
+// TestCORSHandlerWildcard ensures that origins only allowed by the "*" wildcard
+// are not exempt from the user agent check, unlike origins which are listed explicitly.
+func TestCORSHandlerWildcard(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) { WriteSuccess(w) })
+	handler := CORSHandler(RequireUserAgentHandler(ok, "Rivine-Agent"), CORSConfig{
+		AllowedOrigins: []string{"https://wallet.example.com", "*"},
+		AllowedMethods: []string{http.MethodGet, http.MethodPost},
+	})
+
+	testCases := []struct {
+		Method, Origin, UserAgent string
+		StatusCode                int
+	}{
+		{http.MethodGet, "https://wallet.example.com", "", http.StatusNoContent},
+		{http.MethodPost, "https://wallet.example.com", "", http.StatusNoContent},
+		{http.MethodGet, "https://evil.example.com", "", http.StatusBadRequest},
+		{http.MethodPost, "https://evil.example.com", "", http.StatusBadRequest},
+		// official clients are accepted regardless of their origin
+		{http.MethodPost, "https://evil.example.com", "Rivine-Agent", http.StatusNoContent},
+	}
+	for _, tc := range testCases {
+		req := httptest.NewRequest(tc.Method, "/wallet", nil)
+		req.Header.Set("Origin", tc.Origin)
+		req.Header.Set("User-Agent", tc.UserAgent)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tc.StatusCode {
+			t.Errorf("%s (origin %q, user agent %q): unexpected status %d", tc.Method, tc.Origin, tc.UserAgent, rec.Code)
+		}
+		// the wildcard still answers cross-origin requests of any origin
+		if allowOrigin := rec.Header().Get("Access-Control-Allow-Origin"); allowOrigin != tc.Origin {
+			t.Errorf("%s (origin %q): unexpected allowed origin %q", tc.Method, tc.Origin, allowOrigin)
+		}
+	}
+}
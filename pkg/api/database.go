@@ -0,0 +1,219 @@
+package api
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/persist"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/rivine/bbolt"
+)
+
+const (
+	// defaultDatabaseEntriesLimit is the amount of entries listed by default by /debug/database/:module/entries.
+	defaultDatabaseEntriesLimit = 100
+	// maxDatabaseEntriesLimit is the maximum amount of entries listed by /debug/database/:module/entries.
+	maxDatabaseEntriesLimit = 1000
+	// defaultDatabaseMaxValueSize is the size to which values are truncated by default
+	// by /debug/database/:module/entries.
+	defaultDatabaseMaxValueSize = 1024
+)
+
+type (
+	// DebugDatabaseGET contains the names of the modules of which the database can be inspected.
+	DebugDatabaseGET struct {
+		Modules []string `json:"modules"`
+	}
+
+	// DebugDatabaseModuleGET contains the top-level buckets of the database of a module.
+	DebugDatabaseModuleGET struct {
+		Module  string                   `json:"module"`
+		Buckets []persist.DatabaseBucket `json:"buckets"`
+	}
+
+	// DebugDatabaseEntriesGET contains a page of the entries of a bucket of the database of a module.
+	DebugDatabaseEntriesGET struct {
+		Module  string                  `json:"module"`
+		Bucket  string                  `json:"bucket"`
+		Entries []persist.DatabaseEntry `json:"entries"`
+		// NextCursor identifies the next page of entries, omitted if there is no next page.
+		NextCursor string `json:"nextcursor,omitempty"`
+	}
+)
+
+// RegisterDatabaseHTTPHandlers registers the handlers for the read-only API calls
+// to inspect the databases of the given modules, keyed by the name of their persistent directory,
+// while the daemon is running.
+func RegisterDatabaseHTTPHandlers(router Router, inspectors map[string]modules.DatabaseInspector, requiredPassword string) {
+	if router == nil {
+		panic("no httprouter Router given")
+	}
+	router.GET("/debug/database", RequirePasswordHandler(NewDebugDatabaseGetHandler(inspectors), requiredPassword))
+	router.GET("/debug/database/:module", RequirePasswordHandler(NewDebugDatabaseModuleGetHandler(inspectors), requiredPassword))
+	router.GET("/debug/database/:module/entries", RequirePasswordHandler(NewDebugDatabaseEntriesGetHandler(inspectors), requiredPassword))
+}
+
+// RegisterDatabaseAPIv2Handlers registers the calls of version 2 of the API to inspect the databases of the given modules.
+func RegisterDatabaseAPIv2Handlers(v2 *APIv2, inspectors map[string]modules.DatabaseInspector) {
+	v2.Handle(APIv2Route{
+		Method: http.MethodGet, Path: "/debug/database", Tag: "debug",
+		Summary:       "get the modules of which the database can be inspected",
+		Response:      DebugDatabaseGET{},
+		Authenticated: true,
+		Handler:       NewDebugDatabaseGetHandler(inspectors),
+	})
+	v2.Handle(APIv2Route{
+		Method: http.MethodGet, Path: "/debug/database/:module", Tag: "debug",
+		Summary:       "get the top-level buckets of the database of a module",
+		Response:      DebugDatabaseModuleGET{},
+		Authenticated: true,
+		Handler:       NewDebugDatabaseModuleGetHandler(inspectors),
+	})
+	v2.Handle(APIv2Route{
+		Method: http.MethodGet, Path: "/debug/database/:module/entries", Tag: "debug",
+		Summary: "get the keys and values of a bucket of the database of a module",
+		Query: map[string]string{
+			"bucket":       "the slash-separated path of the (nested) bucket, each name as text or hex-encoded prefixed by 0x",
+			"prefix":       "only list the keys with this prefix, as text or hex-encoded prefixed by 0x",
+			"limit":        fmt.Sprintf("the maximum amount of entries returned, %d by default, at most %d", defaultDatabaseEntriesLimit, maxDatabaseEntriesLimit),
+			"cursor":       "the cursor returned as nextcursor by the previous page",
+			"order":        "asc (default) or desc, the order of the keys",
+			"maxvaluesize": fmt.Sprintf("the size in bytes to which values are truncated, %d by default, unlimited if 0", defaultDatabaseMaxValueSize),
+		},
+		Response:      DebugDatabaseEntriesGET{},
+		Authenticated: true,
+		Handler:       NewDebugDatabaseEntriesGetHandler(inspectors),
+	})
+}
+
+// NewDebugDatabaseGetHandler creates a handler to handle the API call
+// listing the modules of which the database can be inspected.
+func NewDebugDatabaseGetHandler(inspectors map[string]modules.DatabaseInspector) httprouter.Handle {
+	return func(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+		names := make([]string, 0, len(inspectors))
+		for name := range inspectors {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		WriteJSON(w, DebugDatabaseGET{Modules: names})
+	}
+}
+
+// databaseInspector returns the inspector of the module of the given call, writing an error if it is unknown.
+func databaseInspector(w http.ResponseWriter, inspectors map[string]modules.DatabaseInspector, ps httprouter.Params) (modules.DatabaseInspector, bool) {
+	inspector, ok := inspectors[ps.ByName("module")]
+	if !ok {
+		WriteError(w, Error{fmt.Sprintf("unknown module %q: its database cannot be inspected", ps.ByName("module"))}, http.StatusNotFound)
+	}
+	return inspector, ok
+}
+
+// NewDebugDatabaseModuleGetHandler creates a handler to handle the API call
+// listing the top-level buckets of the database of a module.
+func NewDebugDatabaseModuleGetHandler(inspectors map[string]modules.DatabaseInspector) httprouter.Handle {
+	return func(w http.ResponseWriter, _ *http.Request, ps httprouter.Params) {
+		inspector, ok := databaseInspector(w, inspectors, ps)
+		if !ok {
+			return
+		}
+		resp := DebugDatabaseModuleGET{Module: ps.ByName("module")}
+		err := inspector.InspectDatabase(func(tx *bolt.Tx) error {
+			resp.Buckets = persist.InspectDatabaseBuckets(tx)
+			return nil
+		})
+		if err != nil {
+			WriteError(w, Error{"failed to inspect the database: " + err.Error()}, http.StatusInternalServerError)
+			return
+		}
+		WriteJSON(w, resp)
+	}
+}
+
+// NewDebugDatabaseEntriesGetHandler creates a handler to handle the API call
+// listing the keys and values of a bucket of the database of a module.
+func NewDebugDatabaseEntriesGetHandler(inspectors map[string]modules.DatabaseInspector) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		inspector, ok := databaseInspector(w, inspectors, ps)
+		if !ok {
+			return
+		}
+		query, err := parseDatabaseEntriesQuery(req)
+		if err != nil {
+			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+			return
+		}
+		resp := DebugDatabaseEntriesGET{
+			Module: ps.ByName("module"),
+			Bucket: req.FormValue("bucket"),
+		}
+		var next []byte
+		err = inspector.InspectDatabase(func(tx *bolt.Tx) (err error) {
+			resp.Entries, next, err = persist.InspectDatabaseEntries(tx, query, inspector.DecodeDatabaseValue)
+			return err
+		})
+		if err == persist.ErrDatabaseBucketNotFound {
+			WriteError(w, Error{fmt.Sprintf("bucket %q not found", resp.Bucket)}, http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			WriteError(w, Error{"failed to inspect the database: " + err.Error()}, http.StatusInternalServerError)
+			return
+		}
+		if next != nil {
+			resp.NextCursor = hex.EncodeToString(next)
+		}
+		WriteJSON(w, resp)
+	}
+}
+
+// parseDatabaseEntriesQuery parses the query of a call to /debug/database/:module/entries.
+func parseDatabaseEntriesQuery(req *http.Request) (persist.DatabaseEntriesQuery, error) {
+	str := req.FormValue("bucket")
+	if str == "" {
+		return persist.DatabaseEntriesQuery{}, fmt.Errorf("no bucket given")
+	}
+	bucket, err := persist.ParseDatabaseBucketPath(str)
+	if err != nil {
+		return persist.DatabaseEntriesQuery{}, err
+	}
+	query := persist.DatabaseEntriesQuery{
+		Bucket:       bucket,
+		Limit:        defaultDatabaseEntriesLimit,
+		MaxValueSize: defaultDatabaseMaxValueSize,
+	}
+	if str := req.FormValue("prefix"); str != "" {
+		query.Prefix, err = persist.ParseDatabaseName(str)
+		if err != nil {
+			return persist.DatabaseEntriesQuery{}, fmt.Errorf("invalid prefix: %v", err)
+		}
+	}
+	opts, err := parseListOptions(req)
+	if err != nil {
+		return persist.DatabaseEntriesQuery{}, err
+	}
+	if opts.Limit > maxDatabaseEntriesLimit {
+		return persist.DatabaseEntriesQuery{}, fmt.Errorf("invalid limit %d: cannot exceed %d", opts.Limit, maxDatabaseEntriesLimit)
+	}
+	if opts.Limit > 0 {
+		query.Limit = opts.Limit
+	}
+	if opts.Cursor != "" {
+		query.After, err = hex.DecodeString(opts.Cursor)
+		if err != nil {
+			return persist.DatabaseEntriesQuery{}, fmt.Errorf("invalid cursor %q: %v", opts.Cursor, err)
+		}
+	}
+	query.Reverse = opts.Order == ListOrderDescending
+	if str := req.FormValue("maxvaluesize"); str != "" {
+		query.MaxValueSize, err = strconv.Atoi(str)
+		if err != nil || query.MaxValueSize < 0 {
+			return persist.DatabaseEntriesQuery{}, fmt.Errorf("invalid maxvaluesize %q: expected a positive integer", str)
+		}
+	}
+	return query, nil
+}
@@ -0,0 +1,391 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/threefoldtech/rivine/persist"
+)
+
+const (
+	// DatabaseCompactionFile is the name of the file, within the root persistent directory,
+	// listing the modules of which the database is to be compacted when the daemon is started next.
+	DatabaseCompactionFile = "compaction.json"
+
+	// diskUsageSampleInterval is the interval at which the disk usage of the modules is sampled.
+	diskUsageSampleInterval = 10 * time.Minute
+	// diskUsageGrowthPeriod is the maximum period over which the growth rate of the modules is measured.
+	diskUsageGrowthPeriod = 24 * time.Hour
+)
+
+var databaseCompactionMetadata = persist.Metadata{
+	Header:  "Database Compaction Schedule",
+	Version: "1.0.0",
+}
+
+type (
+	// DaemonDiskGET contains the disk usage returned by a GET call to /daemon/disk.
+	DaemonDiskGET struct {
+		// Size is the total size of the files of all modules, in bytes.
+		Size    int64             `json:"size"`
+		Modules []ModuleDiskUsage `json:"modules"`
+	}
+
+	// ModuleDiskUsage reports the disk usage of a single module.
+	ModuleDiskUsage struct {
+		Name string `json:"name"`
+		// Size is the total size of the files of the module, in bytes.
+		Size int64 `json:"size"`
+		// DatabaseSize is the size of the database of the module, 0 if it has none.
+		DatabaseSize int64 `json:"databasesize"`
+		// BackupSize is the size of the backups made of the database prior to migrating it.
+		BackupSize int64 `json:"backupsize"`
+		// GrowthRate is the growth of the size of the module in bytes per day,
+		// measured over the last GrowthPeriod seconds, at most a day.
+		GrowthRate   int64 `json:"growthrate"`
+		GrowthPeriod int64 `json:"growthperiod"`
+		// CompactionScheduled reports whether the database of the module
+		// is compacted when the daemon is started next.
+		CompactionScheduled bool `json:"compactionscheduled"`
+	}
+
+	// DaemonDiskCompactPOST contains the body of a POST call to /daemon/disk/compact,
+	// selecting the modules of which the database is compacted, and the backups are pruned.
+	// Only the modules exceeding all given thresholds are selected.
+	DaemonDiskCompactPOST struct {
+		// Modules are the names of the modules to select from,
+		// all modules with a database if none are given.
+		Modules []string `json:"modules,omitempty"`
+		// MinDatabaseSize is the minimum size of the database of a module, in bytes.
+		MinDatabaseSize int64 `json:"mindatabasesize,omitempty"`
+		// MinGrowthRate is the minimum growth rate of a module, in bytes per day.
+		MinGrowthRate int64 `json:"mingrowthrate,omitempty"`
+		// PruneBackups removes the backups made of the selected databases prior to migrating them.
+		PruneBackups bool `json:"prunebackups,omitempty"`
+	}
+
+	// DaemonDiskCompactPOSTResp contains the response of a POST call to /daemon/disk/compact.
+	DaemonDiskCompactPOSTResp struct {
+		// Scheduled are the names of the modules of which the database
+		// is compacted when the daemon is started next.
+		Scheduled []string `json:"scheduled"`
+		// PrunedSize is the total size of the removed backups, in bytes.
+		PrunedSize int64 `json:"prunedsize"`
+	}
+
+	// DatabaseCompactionSchedule lists the modules of which the database
+	// is to be compacted when the daemon is started next.
+	DatabaseCompactionSchedule struct {
+		Modules []string `json:"modules"`
+	}
+)
+
+// LoadDatabaseCompactionSchedule loads the database compaction schedule stored
+// in the given root persistent directory, empty if none is scheduled.
+func LoadDatabaseCompactionSchedule(rootDir string) (DatabaseCompactionSchedule, error) {
+	var schedule DatabaseCompactionSchedule
+	err := persist.LoadJSON(databaseCompactionMetadata, &schedule, filepath.Join(rootDir, DatabaseCompactionFile))
+	if os.IsNotExist(err) {
+		return DatabaseCompactionSchedule{}, nil
+	}
+	return schedule, err
+}
+
+// SaveDatabaseCompactionSchedule stores the given database compaction schedule
+// in the given root persistent directory, removing it if no modules are scheduled.
+func SaveDatabaseCompactionSchedule(rootDir string, schedule DatabaseCompactionSchedule) error {
+	filename := filepath.Join(rootDir, DatabaseCompactionFile)
+	if len(schedule.Modules) == 0 {
+		err := os.Remove(filename)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	return persist.SaveJSON(databaseCompactionMetadata, schedule, filename)
+}
+
+// Contains returns true if the database of the module with the given name is scheduled to be compacted.
+func (schedule DatabaseCompactionSchedule) Contains(name string) bool {
+	for _, module := range schedule.Modules {
+		if module == name {
+			return true
+		}
+	}
+	return false
+}
+
+// DiskUsageModule defines a module of which the disk usage is tracked.
+type DiskUsageModule struct {
+	Name string
+	// Dir is the persistent directory of the module, relative to the root persistent directory.
+	Dir string
+	// Database is the filename of the database of the module within its directory,
+	// empty if it has no database which can be compacted.
+	Database string
+}
+
+type diskUsageSample struct {
+	time  time.Time
+	sizes map[string]int64
+}
+
+// DiskUsageTracker tracks the disk usage of the modules of the daemon,
+// sampling it periodically, such that their growth rate can be reported.
+type DiskUsageTracker struct {
+	rootDir string
+	modules []DiskUsageModule
+
+	mu      sync.Mutex
+	samples []diskUsageSample
+	// scheduleMu protects the database compaction schedule file,
+	// which cannot be loaded and saved concurrently
+	scheduleMu sync.Mutex
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewDiskUsageTracker creates a new disk usage tracker for the given modules,
+// stored in the given root persistent directory.
+func NewDiskUsageTracker(rootDir string, modules ...DiskUsageModule) *DiskUsageTracker {
+	return &DiskUsageTracker{
+		rootDir: rootDir,
+		modules: modules,
+		stop:    make(chan struct{}),
+	}
+}
+
+// Run samples the disk usage of the modules periodically, until the tracker is closed.
+func (dt *DiskUsageTracker) Run() {
+	go func() {
+		ticker := time.NewTicker(diskUsageSampleInterval)
+		defer ticker.Stop()
+		for {
+			if _, err := dt.Sample(); err != nil {
+				fmt.Println("Failed to sample the disk usage of the modules:", err)
+			}
+			select {
+			case <-dt.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Close stops sampling the disk usage of the modules.
+func (dt *DiskUsageTracker) Close() error {
+	dt.stopOnce.Do(func() {
+		close(dt.stop)
+	})
+	return nil
+}
+
+// Sample the disk usage of the modules, returning their current disk usage,
+// with their growth rate measured since the oldest sample of the growth period.
+func (dt *DiskUsageTracker) Sample() ([]ModuleDiskUsage, error) {
+	return dt.measure(time.Now(), true)
+}
+
+// measure the current disk usage of the modules, and their growth rate,
+// recording the measurement as a sample if requested.
+func (dt *DiskUsageTracker) measure(now time.Time, record bool) ([]ModuleDiskUsage, error) {
+	usages := make([]ModuleDiskUsage, 0, len(dt.modules))
+	sample := diskUsageSample{time: now, sizes: make(map[string]int64, len(dt.modules))}
+	for _, module := range dt.modules {
+		usage, err := dt.moduleUsage(module)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the disk usage of %s: %v", module.Name, err)
+		}
+		usages = append(usages, usage)
+		sample.sizes[module.Name] = usage.Size
+	}
+
+	dt.mu.Lock()
+	defer dt.mu.Unlock()
+	// drop the samples which are no longer part of the growth period
+	for len(dt.samples) > 0 && now.Sub(dt.samples[0].time) > diskUsageGrowthPeriod {
+		dt.samples = dt.samples[1:]
+	}
+	if len(dt.samples) > 0 {
+		oldest := dt.samples[0]
+		if period := now.Sub(oldest.time); period > 0 {
+			for i := range usages {
+				growth := usages[i].Size - oldest.sizes[usages[i].Name]
+				usages[i].GrowthRate = int64(float64(growth) * float64(24*time.Hour) / float64(period))
+				usages[i].GrowthPeriod = int64(period / time.Second)
+			}
+		}
+	}
+	if record {
+		dt.samples = append(dt.samples, sample)
+	}
+	return usages, nil
+}
+
+// moduleUsage returns the current disk usage of the given module, without its growth rate.
+func (dt *DiskUsageTracker) moduleUsage(module DiskUsageModule) (ModuleDiskUsage, error) {
+	usage := ModuleDiskUsage{Name: module.Name}
+	dir := filepath.Join(dt.rootDir, module.Dir)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil // the module did not persist anything yet, or the file was removed meanwhile
+			}
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		usage.Size += info.Size()
+		if module.Database == "" || filepath.Dir(path) != dir {
+			return nil
+		}
+		name := info.Name()
+		if name == module.Database {
+			usage.DatabaseSize = info.Size()
+		} else if strings.HasPrefix(name, module.Database+".v") && strings.HasSuffix(name, ".bak") {
+			// named as defined by persist.MigrationBackupFilename
+			usage.BackupSize += info.Size()
+		}
+		return nil
+	})
+	return usage, err
+}
+
+// RegisterDiskHTTPHandlers registers the handlers for the API calls to report the disk usage
+// of the modules tracked by the given tracker, and to compact their databases.
+func RegisterDiskHTTPHandlers(router Router, tracker *DiskUsageTracker, requiredPassword string) {
+	if router == nil {
+		panic("no httprouter Router given")
+	}
+	router.GET("/daemon/disk", RequirePasswordHandler(NewDaemonDiskGetHandler(tracker), requiredPassword))
+	router.POST("/daemon/disk/compact", RequirePasswordHandler(NewDaemonDiskCompactPostHandler(tracker), requiredPassword))
+}
+
+// NewDaemonDiskGetHandler creates a handler to handle the API call
+// to get the disk usage of the modules tracked by the given tracker.
+func NewDaemonDiskGetHandler(tracker *DiskUsageTracker) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		usages, err := tracker.measure(time.Now(), false)
+		if err != nil {
+			WriteError(w, Error{err.Error()}, http.StatusInternalServerError)
+			return
+		}
+		tracker.scheduleMu.Lock()
+		schedule, err := LoadDatabaseCompactionSchedule(tracker.rootDir)
+		tracker.scheduleMu.Unlock()
+		if err != nil {
+			WriteError(w, Error{"failed to load the database compaction schedule: " + err.Error()}, http.StatusInternalServerError)
+			return
+		}
+		resp := DaemonDiskGET{Modules: usages}
+		for i := range resp.Modules {
+			resp.Size += resp.Modules[i].Size
+			resp.Modules[i].CompactionScheduled = schedule.Contains(resp.Modules[i].Name)
+		}
+		WriteJSON(w, resp)
+	}
+}
+
+// NewDaemonDiskCompactPostHandler creates a handler to handle the API call
+// scheduling the compaction of the databases of the modules tracked by the given tracker,
+// exceeding the given thresholds, and pruning their backups.
+func NewDaemonDiskCompactPostHandler(tracker *DiskUsageTracker) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		var body DaemonDiskCompactPOST
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			WriteError(w, Error{"error decoding the supplied compaction request: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		selected := make(map[string]bool, len(body.Modules))
+		for _, name := range body.Modules {
+			module, ok := tracker.module(name)
+			if !ok || module.Database == "" {
+				WriteError(w, Error{fmt.Sprintf("module %q has no database which can be compacted", name)}, http.StatusBadRequest)
+				return
+			}
+			selected[name] = true
+		}
+
+		usages, err := tracker.measure(time.Now(), false)
+		if err != nil {
+			WriteError(w, Error{err.Error()}, http.StatusInternalServerError)
+			return
+		}
+		tracker.scheduleMu.Lock()
+		defer tracker.scheduleMu.Unlock()
+		schedule, err := LoadDatabaseCompactionSchedule(tracker.rootDir)
+		if err != nil {
+			WriteError(w, Error{"failed to load the database compaction schedule: " + err.Error()}, http.StatusInternalServerError)
+			return
+		}
+		resp := DaemonDiskCompactPOSTResp{Scheduled: []string{}}
+		for _, usage := range usages {
+			module, _ := tracker.module(usage.Name)
+			if module.Database == "" || (len(selected) > 0 && !selected[usage.Name]) ||
+				usage.DatabaseSize < body.MinDatabaseSize || usage.GrowthRate < body.MinGrowthRate {
+				continue
+			}
+			if body.PruneBackups {
+				pruned, err := pruneDatabaseBackups(filepath.Join(tracker.rootDir, module.Dir, module.Database))
+				resp.PrunedSize += pruned
+				if err != nil {
+					WriteError(w, Error{fmt.Sprintf("failed to prune the database backups of %s: %v", usage.Name, err)}, http.StatusInternalServerError)
+					return
+				}
+			}
+			if !schedule.Contains(usage.Name) {
+				schedule.Modules = append(schedule.Modules, usage.Name)
+			}
+			resp.Scheduled = append(resp.Scheduled, usage.Name)
+		}
+		if len(resp.Scheduled) > 0 {
+			err = SaveDatabaseCompactionSchedule(tracker.rootDir, schedule)
+			if err != nil {
+				WriteError(w, Error{"failed to save the database compaction schedule: " + err.Error()}, http.StatusInternalServerError)
+				return
+			}
+		}
+		WriteJSON(w, resp)
+	}
+}
+
+// module returns the tracked module with the given name.
+func (dt *DiskUsageTracker) module(name string) (DiskUsageModule, bool) {
+	for _, module := range dt.modules {
+		if module.Name == name {
+			return module, true
+		}
+	}
+	return DiskUsageModule{}, false
+}
+
+// pruneDatabaseBackups removes the backups made of the database with the given filename
+// prior to migrating it, returning their total size.
+func pruneDatabaseBackups(filename string) (int64, error) {
+	backups, err := persist.DatabaseMigrationBackups(filename)
+	if err != nil {
+		return 0, err
+	}
+	var pruned int64
+	for _, backup := range backups {
+		info, err := os.Stat(backup)
+		if err != nil {
+			return pruned, err
+		}
+		if err = os.Remove(backup); err != nil {
+			return pruned, err
+		}
+		pruned += info.Size()
+	}
+	return pruned, nil
+}
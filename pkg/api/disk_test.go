@@ -0,0 +1,154 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/threefoldtech/rivine/persist"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+func TestDiskUsageTracker(t *testing.T) {
+	dir, err := ioutil.TempDir("", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	write := func(name string, size int) {
+		filename := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filename), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filename, bytes.Repeat([]byte{1}, size), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("consensus/consensus.db", 1000)
+	write("consensus/"+persist.MigrationBackupFilename("consensus.db", "1.0.0"), 200)
+	write("consensus/consensus.log", 10)
+	write("gateway/nodes.json", 30)
+	tracker := NewDiskUsageTracker(dir,
+		DiskUsageModule{Name: "consensus", Dir: "consensus", Database: "consensus.db"},
+		DiskUsageModule{Name: "gateway", Dir: "gateway"},
+		// a module which did not persist anything yet
+		DiskUsageModule{Name: "explorer", Dir: "explorer", Database: "explorer.db"},
+	)
+
+	now := time.Now()
+	usages, err := tracker.measure(now.Add(-12*time.Hour), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(usages) != 3 || usages[0] != (ModuleDiskUsage{Name: "consensus", Size: 1210, DatabaseSize: 1000, BackupSize: 200}) ||
+		usages[1] != (ModuleDiskUsage{Name: "gateway", Size: 30}) || usages[2] != (ModuleDiskUsage{Name: "explorer"}) {
+		t.Fatalf("unexpected disk usage %+v", usages)
+	}
+	// the growth rate is extrapolated to a day
+	write("consensus/consensus.db", 1500)
+	usages, err = tracker.measure(now, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usages[0].GrowthRate != 1000 || usages[0].GrowthPeriod != 12*60*60 || usages[1].GrowthRate != 0 {
+		t.Fatalf("unexpected growth %+v", usages)
+	}
+	// samples older than a day are no longer taken into account
+	usages, err = tracker.measure(now.Add(13*time.Hour), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usages[0].GrowthRate != 0 || usages[0].GrowthPeriod != 0 {
+		t.Fatalf("unexpected growth %+v", usages)
+	}
+}
+
+func TestDaemonDiskCompact(t *testing.T) {
+	dir, err := ioutil.TempDir("", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for name, size := range map[string]int{
+		"consensus/consensus.db": 1000,
+		"consensus/" + persist.MigrationBackupFilename("consensus.db", "1.0.0"): 200,
+		"explorer/explorer.db": 100,
+	} {
+		filename := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filename), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filename, make([]byte, size), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tracker := NewDiskUsageTracker(dir,
+		DiskUsageModule{Name: "gateway", Dir: "gateway"},
+		DiskUsageModule{Name: "consensus", Dir: "consensus", Database: "consensus.db"},
+		DiskUsageModule{Name: "explorer", Dir: "explorer", Database: "explorer.db"},
+	)
+	post := func(body string) (int, DaemonDiskCompactPOSTResp) {
+		rec := httptest.NewRecorder()
+		NewDaemonDiskCompactPostHandler(tracker)(rec, httptest.NewRequest(http.MethodPost, "/daemon/disk/compact", strings.NewReader(body)), httprouter.Params{})
+		var resp DaemonDiskCompactPOSTResp
+		if rec.Code == http.StatusOK {
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return rec.Code, resp
+	}
+
+	// only the databases exceeding the thresholds are scheduled
+	code, resp := post(`{"mindatabasesize":500,"prunebackups":true}`)
+	if code != http.StatusOK || len(resp.Scheduled) != 1 || resp.Scheduled[0] != "consensus" || resp.PrunedSize != 200 {
+		t.Fatalf("unexpected response %d: %+v", code, resp)
+	}
+	if _, err = os.Stat(filepath.Join(dir, "consensus", persist.MigrationBackupFilename("consensus.db", "1.0.0"))); !os.IsNotExist(err) {
+		t.Error("expected the backup to be pruned:", err)
+	}
+	code, resp = post(`{"modules":["explorer"]}`)
+	if code != http.StatusOK || len(resp.Scheduled) != 1 || resp.Scheduled[0] != "explorer" {
+		t.Fatalf("unexpected response %d: %+v", code, resp)
+	}
+	schedule, err := LoadDatabaseCompactionSchedule(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schedule.Modules) != 2 || !schedule.Contains("consensus") || !schedule.Contains("explorer") {
+		t.Errorf("unexpected schedule %+v", schedule)
+	}
+
+	// the schedule is reported as part of the disk usage
+	rec := httptest.NewRecorder()
+	NewDaemonDiskGetHandler(tracker)(rec, httptest.NewRequest(http.MethodGet, "/daemon/disk", nil), httprouter.Params{})
+	var usage DaemonDiskGET
+	if err = json.Unmarshal(rec.Body.Bytes(), &usage); err != nil {
+		t.Fatal(err)
+	}
+	if usage.Size != 1100 || len(usage.Modules) != 3 || usage.Modules[0].CompactionScheduled || !usage.Modules[1].CompactionScheduled {
+		t.Errorf("unexpected disk usage %+v", usage)
+	}
+
+	for _, body := range []string{"", `{"modules":["gateway"]}`, `{"modules":["unknown"]}`} {
+		if code, _ := post(body); code != http.StatusBadRequest {
+			t.Errorf("%q: expected status 400, got %d", body, code)
+		}
+	}
+
+	// the schedule file is removed once nothing is scheduled
+	if err = SaveDatabaseCompactionSchedule(dir, DatabaseCompactionSchedule{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(filepath.Join(dir, DatabaseCompactionFile)); !os.IsNotExist(err) {
+		t.Error("expected the schedule to be removed:", err)
+	}
+}
@@ -0,0 +1,205 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/threefoldtech/rivine/modules"
+)
+
+// EventTopic identifies the module an event streamed by the /events endpoint originates from.
+type EventTopic string
+
+// The topics of the events streamed by the /events endpoint.
+const (
+	EventTopicConsensus       EventTopic = "consensus"
+	EventTopicTransactionPool EventTopic = "transactionpool"
+	EventTopicWallet          EventTopic = "wallet"
+	EventTopicGateway         EventTopic = "gateway"
+)
+
+type (
+	// EventsMessage is a single message streamed by the /events endpoint.
+	// The type of the event depends on the topic:
+	// a ConsensusEvent, modules.TransactionPoolEvent, modules.WalletEvent or modules.PeerEvent.
+	EventsMessage struct {
+		Topic EventTopic  `json:"topic"`
+		Event interface{} `json:"event"`
+	}
+)
+
+// eventsBufferSize is the amount of events buffered for a single
+// multiplexed event stream, before the client is considered too slow.
+const eventsBufferSize = 512
+
+// RegisterEventsHTTPHandlers registers the handler to stream the events
+// of all given modules. Modules which are not loaded (nil) are skipped.
+func RegisterEventsHTTPHandlers(router Router, cs modules.ConsensusSet, tpool modules.TransactionPool, wallet modules.Wallet, gateway modules.Gateway, requiredPassword string) {
+	if router == nil {
+		panic("no httprouter Router given")
+	}
+	router.GET("/events", NewEventsGetHandler(cs, tpool, wallet, gateway, requiredPassword))
+}
+
+// eventStream is a listener for the events of all modules,
+// buffering the events to be sent to a single client.
+type eventStream struct {
+	messages chan EventsMessage
+	overflow chan struct{}
+	once     sync.Once
+}
+
+// send buffers the given event, dropping the client if it fell behind.
+func (stream *eventStream) send(topic EventTopic, event interface{}) {
+	select {
+	case stream.messages <- EventsMessage{Topic: topic, Event: event}:
+	default:
+		// never block a module, drop the client instead
+		stream.once.Do(func() { close(stream.overflow) })
+	}
+}
+
+// ProcessConsensusChange implements modules.ConsensusSetSubscriber.ProcessConsensusChange
+func (stream *eventStream) ProcessConsensusChange(cc modules.ConsensusChange) {
+	var event ConsensusEvent
+	for _, block := range cc.RevertedBlocks {
+		event.RevertedBlocks = append(event.RevertedBlocks, block.ID())
+	}
+	for _, block := range cc.AppliedBlocks {
+		event.AppliedBlocks = append(event.AppliedBlocks, block.ID())
+	}
+	stream.send(EventTopicConsensus, event)
+}
+
+// ReceiveTransactionPoolEvent implements modules.TransactionPoolEventListener.ReceiveTransactionPoolEvent
+func (stream *eventStream) ReceiveTransactionPoolEvent(event modules.TransactionPoolEvent) {
+	stream.send(EventTopicTransactionPool, event)
+}
+
+// ReceiveWalletEvent implements modules.WalletEventListener.ReceiveWalletEvent
+func (stream *eventStream) ReceiveWalletEvent(event modules.WalletEvent) {
+	stream.send(EventTopicWallet, event)
+}
+
+// ReceivePeerEvent implements modules.PeerEventListener.ReceivePeerEvent
+func (stream *eventStream) ReceivePeerEvent(event modules.PeerEvent) {
+	stream.send(EventTopicGateway, event)
+}
+
+// parseEventTopics parses the comma-separated topics of the given query value,
+// returning all available topics if the value is empty.
+// An error is returned for unknown topics, or topics of which the module is not available.
+func parseEventTopics(query string, available map[EventTopic]bool) (map[EventTopic]bool, error) {
+	topics := make(map[EventTopic]bool)
+	if query == "" {
+		for topic, ok := range available {
+			if ok {
+				topics[topic] = true
+			}
+		}
+		return topics, nil
+	}
+	for _, s := range strings.Split(query, ",") {
+		topic := EventTopic(strings.TrimSpace(s))
+		ok, known := available[topic]
+		if !known {
+			return nil, fmt.Errorf("unknown event topic %q", topic)
+		}
+		if !ok {
+			return nil, fmt.Errorf("event topic %q is not available, as its module is not loaded", topic)
+		}
+		topics[topic] = true
+	}
+	return topics, nil
+}
+
+// NewEventsGetHandler creates a handler
+// to handle the API call to stream the events of the given modules over a websocket connection,
+// optionally filtered by the comma-separated topics of the topics query parameter.
+// Streaming wallet events requires the API password.
+// The connection is closed when the client falls behind by more than eventsBufferSize events.
+func NewEventsGetHandler(cs modules.ConsensusSet, tpool modules.TransactionPool, wallet modules.Wallet, gateway modules.Gateway, requiredPassword string) httprouter.Handle {
+	available := map[EventTopic]bool{
+		EventTopicConsensus:       cs != nil,
+		EventTopicTransactionPool: tpool != nil,
+		EventTopicWallet:          wallet != nil,
+		EventTopicGateway:         gateway != nil,
+	}
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		topics, err := parseEventTopics(req.FormValue("topics"), available)
+		if err != nil {
+			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+			return
+		}
+		handler := func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+			streamEvents(w, req, topics, cs, tpool, wallet, gateway)
+		}
+		if topics[EventTopicWallet] {
+			handler = RequirePasswordHandler(handler, requiredPassword)
+		}
+		handler(w, req, ps)
+	}
+}
+
+// streamEvents streams the events of the given topics over a websocket connection,
+// until the client closes the connection or falls behind.
+func streamEvents(w http.ResponseWriter, req *http.Request, topics map[EventTopic]bool, cs modules.ConsensusSet, tpool modules.TransactionPool, wallet modules.Wallet, gateway modules.Gateway) {
+	ws, err := upgradeWebSocket(w, req)
+	if err != nil {
+		WriteError(w, Error{"failed to open websocket connection: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	defer ws.Close()
+
+	stream := &eventStream{
+		messages: make(chan EventsMessage, eventsBufferSize),
+		overflow: make(chan struct{}),
+	}
+	closed := make(chan struct{})
+	if topics[EventTopicConsensus] {
+		err = cs.ConsensusSetSubscribe(stream, modules.ConsensusChangeRecent, closed)
+		if err != nil {
+			ws.writeFrame(websocketOpClose, nil)
+			return
+		}
+		defer cs.Unsubscribe(stream)
+	}
+	if topics[EventTopicTransactionPool] {
+		tpool.TransactionPoolEventSubscribe(stream)
+		defer tpool.TransactionPoolEventUnsubscribe(stream)
+	}
+	if topics[EventTopicWallet] {
+		wallet.WalletEventSubscribe(stream)
+		defer wallet.WalletEventUnsubscribe(stream)
+	}
+	if topics[EventTopicGateway] {
+		gateway.PeerEventSubscribe(stream)
+		defer gateway.PeerEventUnsubscribe(stream)
+	}
+
+	go func() {
+		ws.readLoop()
+		close(closed)
+	}()
+	for {
+		select {
+		case msg := <-stream.messages:
+			if event, ok := msg.Event.(ConsensusEvent); ok {
+				// the height is only known outside of the consensus set lock
+				event.Height = cs.Height()
+				msg.Event = event
+			}
+			if err := ws.WriteJSON(msg); err != nil {
+				return
+			}
+		case <-stream.overflow:
+			ws.writeFrame(websocketOpClose, nil)
+			return
+		case <-closed:
+			return
+		}
+	}
+}
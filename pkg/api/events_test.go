@@ -0,0 +1,74 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+func TestParseEventTopics(t *testing.T) {
+	available := map[EventTopic]bool{
+		EventTopicConsensus:       true,
+		EventTopicTransactionPool: true,
+		EventTopicWallet:          false,
+		EventTopicGateway:         true,
+	}
+	topics, err := parseEventTopics("", available)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(topics) != 3 || topics[EventTopicWallet] {
+		t.Errorf("unexpected default topics: %v", topics)
+	}
+	topics, err = parseEventTopics("consensus, gateway", available)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(topics) != 2 || !topics[EventTopicConsensus] || !topics[EventTopicGateway] {
+		t.Errorf("unexpected topics: %v", topics)
+	}
+	for _, query := range []string{"wallet", "consensus,blocks", ","} {
+		if _, err := parseEventTopics(query, available); err == nil {
+			t.Errorf("expected an error for topics %q", query)
+		}
+	}
+}
+
+func TestEventStream(t *testing.T) {
+	stream := &eventStream{
+		messages: make(chan EventsMessage, 2),
+		overflow: make(chan struct{}),
+	}
+	stream.ReceivePeerEvent(modules.PeerEvent{Type: modules.PeerEventConnected, Peer: modules.Peer{NetAddress: "127.0.0.1:23112"}})
+	stream.ReceiveWalletEvent(modules.WalletEvent{Type: modules.WalletEventConfirmed, Transactions: []types.TransactionID{{1}}, Height: 42})
+
+	b, err := json.Marshal(<-stream.messages)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msg struct {
+		Topic EventTopic
+		Event modules.PeerEvent
+	}
+	if err = json.Unmarshal(b, &msg); err != nil {
+		t.Fatal(err)
+	}
+	if msg.Topic != EventTopicGateway || msg.Event.Type != modules.PeerEventConnected || msg.Event.Peer.NetAddress != "127.0.0.1:23112" {
+		t.Errorf("unexpected message: %s", b)
+	}
+	if msg := <-stream.messages; msg.Topic != EventTopicWallet {
+		t.Errorf("unexpected message: %v", msg)
+	}
+
+	// a client falling behind is dropped, instead of blocking the modules
+	for i := 0; i < 3; i++ {
+		stream.ReceiveTransactionPoolEvent(modules.TransactionPoolEvent{})
+	}
+	select {
+	case <-stream.overflow:
+	default:
+		t.Error("expected the stream to overflow")
+	}
+}
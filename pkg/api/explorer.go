@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/threefoldtech/rivine/build"
 	"github.com/threefoldtech/rivine/crypto"
@@ -13,6 +14,22 @@ import (
 	"github.com/julienschmidt/httprouter"
 )
 
+const (
+	// defaultRichListLimit is the amount of addresses returned by default by /explorer/richlist.
+	defaultRichListLimit = 100
+	// maxRichListLimit is the maximum amount of addresses returned by /explorer/richlist.
+	maxRichListLimit = 1000
+	// defaultExplorerListLimit is the amount of blocks or transactions returned by default
+	// by /explorer/blocks and /explorer/transactions.
+	defaultExplorerListLimit = 100
+	// maxExplorerListLimit is the maximum amount of blocks or transactions returned
+	// by /explorer/blocks and /explorer/transactions.
+	maxExplorerListLimit = 1000
+	// maxExplorerReorgsLimit is the maximum amount of reorgs returned by /explorer/reorgs,
+	// being the amount of reorgs recorded by the explorer.
+	maxExplorerReorgsLimit = 100
+)
+
 // hash type string constants
 const (
 	HashTypeTransactionIDStr      = "transactionid"
@@ -195,6 +212,10 @@ type (
 	// /explorer.
 	ExplorerGET struct {
 		modules.BlockFacts
+
+		// LastReorg is the ID of the most recent reorg processed by the explorer, 0 if there was none,
+		// which changes whenever the history of the explorer changes, see /explorer/reorgs.
+		LastReorg uint64 `json:"lastreorg"`
 	}
 
 	// ExplorerBlockGET is the object returned by a GET request to
@@ -220,6 +241,63 @@ type (
 		MultiSigAddresses []types.UnlockHash    `json:"multisigaddresses"`
 		Unconfirmed       bool                  `json:"unconfirmed"`
 	}
+
+	// ExplorerAddressTransactionsGET is the object returned as a response to a GET request to
+	// /explorer/addresses/:unlockhash/transactions.
+	ExplorerAddressTransactionsGET struct {
+		Transactions []modules.AddressTransaction `json:"transactions"`
+		// NextCursor identifies the next page of transactions, omitted if there is no next page.
+		NextCursor string `json:"nextcursor,omitempty"`
+		// LastReorg is the ID of the most recent reorg processed by the explorer, 0 if there was none,
+		// which changes whenever the history of the explorer changes, see /explorer/reorgs.
+		LastReorg uint64 `json:"lastreorg"`
+	}
+
+	// ExplorerBlocksGET is the object returned by a GET request to /explorer/blocks.
+	ExplorerBlocksGET struct {
+		Blocks []modules.BlockSummary `json:"blocks"`
+		// NextCursor identifies the next page of blocks, omitted if there is no next page.
+		NextCursor string `json:"nextcursor,omitempty"`
+		// LastReorg is the ID of the most recent reorg processed by the explorer, 0 if there was none,
+		// which changes whenever the history of the explorer changes, see /explorer/reorgs.
+		LastReorg uint64 `json:"lastreorg"`
+	}
+
+	// ExplorerTransactionsGET is the object returned by a GET request to /explorer/transactions.
+	ExplorerTransactionsGET struct {
+		Transactions []modules.TransactionSummary `json:"transactions"`
+		// NextCursor identifies the next page of transactions, omitted if there is no next page.
+		NextCursor string `json:"nextcursor,omitempty"`
+		// LastReorg is the ID of the most recent reorg processed by the explorer, 0 if there was none,
+		// which changes whenever the history of the explorer changes, see /explorer/reorgs.
+		LastReorg uint64 `json:"lastreorg"`
+	}
+
+	// ExplorerAggregatesGET is the object returned by a GET request to /explorer/stats/aggregates/:period.
+	ExplorerAggregatesGET struct {
+		Aggregates []modules.ChainAggregate `json:"aggregates"`
+		// NextCursor identifies the next page of aggregates, omitted if there is no next page.
+		NextCursor string `json:"nextcursor,omitempty"`
+		// LastReorg is the ID of the most recent reorg processed by the explorer, 0 if there was none,
+		// which changes whenever the history of the explorer changes, see /explorer/reorgs.
+		LastReorg uint64 `json:"lastreorg"`
+	}
+
+	// ExplorerReorgsGET is the object returned by a GET request to /explorer/reorgs.
+	ExplorerReorgsGET struct {
+		Reorgs []modules.ExplorerReorg `json:"reorgs"`
+	}
+
+	// ExplorerRichListGET is the object returned by a GET request to /explorer/richlist.
+	ExplorerRichListGET struct {
+		Addresses []modules.AddressBalance `json:"addresses"`
+	}
+
+	// ExplorerBalanceDistributionGET is the object returned by a GET request
+	// to /explorer/stats/distribution.
+	ExplorerBalanceDistributionGET struct {
+		Ranges []modules.BalanceRange `json:"ranges"`
+	}
 )
 
 // RegisterExplorerHTTPHandlers registers the default Rivine handlers for all default Rivine Explprer HTTP endpoints.
@@ -235,10 +313,18 @@ func RegisterExplorerHTTPHandlers(router Router, cs modules.ConsensusSet, explor
 	}
 
 	router.GET("/explorer", NewExplorerRootHandler(explorer))
+	router.GET("/explorer/blocks", NewExplorerBlockListHandler(explorer))
 	router.GET("/explorer/blocks/:height", NewExplorerBlocksHandler(cs, explorer))
+	router.GET("/explorer/transactions", NewExplorerTransactionListHandler(explorer))
 	router.GET("/explorer/hashes/:hash", NewExplorerHashHandler(explorer, tpool))
+	router.GET("/explorer/addresses/:unlockhash/transactions", NewExplorerAddressTransactionsHandler(explorer))
 	router.GET("/explorer/stats/history", NewExplorerHistoryStatsHandler(explorer))
 	router.GET("/explorer/stats/range", NewExplorerRangeStatsHandler(explorer))
+	router.GET("/explorer/stats/aggregates/:period", NewExplorerAggregatesHandler(explorer))
+	router.GET("/explorer/reorgs", NewExplorerReorgsHandler(explorer))
+	router.GET("/explorer/richlist", NewExplorerRichListHandler(explorer))
+	router.GET("/explorer/stats/distribution", NewExplorerBalanceDistributionHandler(explorer))
+	router.GET("/explorer/stats/supply", NewExplorerSupplyHandler(explorer))
 	router.GET("/explorer/constants", NewExplorerConstantsHandler(explorer))
 }
 
@@ -404,12 +490,220 @@ func NewExplorerHashHandler(explorer modules.Explorer, tpool modules.Transaction
 	}
 }
 
+// NewExplorerAddressTransactionsHandler creates a handler to handle API calls to /explorer/addresses/:unlockhash/transactions,
+// returning a page of the transactions which created or spent outputs of an address.
+func NewExplorerAddressTransactionsHandler(explorer modules.Explorer) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		uh, err := ScanAddress(ps.ByName("unlockhash"))
+		if err != nil {
+			WriteError(w, Error{"invalid unlock hash: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		opts, err := parseListOptions(req)
+		if err != nil {
+			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+			return
+		}
+		query := modules.AddressTransactionsQuery{
+			Direction: modules.AddressTransactionDirection(req.FormValue("direction")),
+			Cursor:    opts.Cursor,
+			Reverse:   opts.Order == ListOrderDescending,
+			Limit:     opts.Limit,
+		}
+		for _, param := range []struct {
+			name   string
+			height *types.BlockHeight
+		}{{"minheight", &query.MinHeight}, {"maxheight", &query.MaxHeight}} {
+			if str := req.FormValue(param.name); str != "" {
+				n, err := strconv.ParseUint(str, 10, 64)
+				if err != nil {
+					WriteError(w, Error{fmt.Sprintf("invalid %s: %v", param.name, err)}, http.StatusBadRequest)
+					return
+				}
+				*param.height = types.BlockHeight(n)
+			}
+		}
+		switch query.Direction {
+		case "", modules.AddressTransactionIncoming, modules.AddressTransactionOutgoing, modules.AddressTransactionBoth:
+		default:
+			WriteError(w, Error{fmt.Sprintf("invalid direction %q: expected %s, %s or %s", query.Direction,
+				modules.AddressTransactionIncoming, modules.AddressTransactionOutgoing, modules.AddressTransactionBoth)}, http.StatusBadRequest)
+			return
+		}
+		txns, next, err := explorer.AddressTransactions(uh, query)
+		if err == modules.ErrInvalidAddressTransactionsCursor {
+			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			WriteError(w, Error{"error during call to /explorer/addresses: " + err.Error()}, http.StatusInternalServerError)
+			return
+		}
+		if txns == nil {
+			txns = []modules.AddressTransaction{}
+		}
+		lastReorg, err := lastExplorerReorg(explorer)
+		if err != nil {
+			WriteError(w, Error{"error during call to /explorer/addresses: " + err.Error()}, http.StatusInternalServerError)
+			return
+		}
+		WriteJSON(w, ExplorerAddressTransactionsGET{Transactions: txns, NextCursor: next, LastReorg: lastReorg})
+	}
+}
+
+// explorerListQuery is the query shared by /explorer/blocks and /explorer/transactions,
+// the ranges being parsed from the minheight, maxheight, mintimestamp and maxtimestamp
+// query parameters, all inclusive.
+type explorerListQuery struct {
+	MinHeight, MaxHeight       types.BlockHeight
+	MinTimestamp, MaxTimestamp types.Timestamp
+	Cursor                     string
+	Reverse                    bool
+	Limit                      int
+}
+
+// parseExplorerListQuery parses the list query of the given request.
+func parseExplorerListQuery(req *http.Request) (explorerListQuery, error) {
+	opts, err := parseListOptions(req)
+	if err != nil {
+		return explorerListQuery{}, err
+	}
+	if opts.Limit > maxExplorerListLimit {
+		return explorerListQuery{}, fmt.Errorf("invalid limit %d: cannot exceed %d", opts.Limit, maxExplorerListLimit)
+	}
+	query := explorerListQuery{
+		Cursor:  opts.Cursor,
+		Reverse: opts.Order == ListOrderDescending,
+		Limit:   defaultExplorerListLimit,
+	}
+	if opts.Limit > 0 {
+		query.Limit = opts.Limit
+	}
+	var values [4]uint64
+	for i, name := range []string{"minheight", "maxheight", "mintimestamp", "maxtimestamp"} {
+		if str := req.FormValue(name); str != "" {
+			values[i], err = strconv.ParseUint(str, 10, 64)
+			if err != nil {
+				return explorerListQuery{}, fmt.Errorf("invalid %s: %v", name, err)
+			}
+		}
+	}
+	query.MinHeight, query.MaxHeight = types.BlockHeight(values[0]), types.BlockHeight(values[1])
+	query.MinTimestamp, query.MaxTimestamp = types.Timestamp(values[2]), types.Timestamp(values[3])
+	return query, nil
+}
+
+// NewExplorerBlockListHandler creates a handler to handle API calls to /explorer/blocks,
+// returning a page of the blocks within a range of heights and timestamps.
+func NewExplorerBlockListHandler(explorer modules.Explorer) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		q, err := parseExplorerListQuery(req)
+		if err != nil {
+			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+			return
+		}
+		blocks, next, err := explorer.Blocks(modules.BlocksQuery{
+			MinHeight:    q.MinHeight,
+			MaxHeight:    q.MaxHeight,
+			MinTimestamp: q.MinTimestamp,
+			MaxTimestamp: q.MaxTimestamp,
+			Cursor:       q.Cursor,
+			Reverse:      q.Reverse,
+			Limit:        q.Limit,
+		})
+		if err == modules.ErrInvalidBlocksCursor {
+			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			WriteError(w, Error{"error during call to /explorer/blocks: " + err.Error()}, http.StatusInternalServerError)
+			return
+		}
+		if blocks == nil {
+			blocks = []modules.BlockSummary{}
+		}
+		lastReorg, err := lastExplorerReorg(explorer)
+		if err != nil {
+			WriteError(w, Error{"error during call to /explorer/blocks: " + err.Error()}, http.StatusInternalServerError)
+			return
+		}
+		WriteJSON(w, ExplorerBlocksGET{Blocks: blocks, NextCursor: next, LastReorg: lastReorg})
+	}
+}
+
+// NewExplorerTransactionListHandler creates a handler to handle API calls to /explorer/transactions,
+// returning a page of the transactions within a range of heights and timestamps,
+// optionally filtered by version and minimum value.
+func NewExplorerTransactionListHandler(explorer modules.Explorer) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		q, err := parseExplorerListQuery(req)
+		if err != nil {
+			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+			return
+		}
+		query := modules.TransactionsQuery{
+			MinHeight:    q.MinHeight,
+			MaxHeight:    q.MaxHeight,
+			MinTimestamp: q.MinTimestamp,
+			MaxTimestamp: q.MaxTimestamp,
+			MinValue:     types.ZeroCurrency,
+			Cursor:       q.Cursor,
+			Reverse:      q.Reverse,
+			Limit:        q.Limit,
+		}
+		if str := req.FormValue("version"); str != "" {
+			for _, s := range strings.Split(str, ",") {
+				version, err := strconv.ParseUint(s, 10, 8)
+				if err != nil {
+					WriteError(w, Error{fmt.Sprintf("invalid version %q: %v", s, err)}, http.StatusBadRequest)
+					return
+				}
+				query.Versions = append(query.Versions, types.TransactionVersion(version))
+			}
+		}
+		if str := req.FormValue("minvalue"); str != "" {
+			var ok bool
+			if !strings.HasPrefix(str, "-") {
+				query.MinValue, ok = ScanAmount(str)
+			}
+			if !ok {
+				WriteError(w, Error{fmt.Sprintf("invalid minvalue %q", str)}, http.StatusBadRequest)
+				return
+			}
+		}
+		txns, next, err := explorer.Transactions(query)
+		if err == modules.ErrInvalidTransactionsCursor {
+			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			WriteError(w, Error{"error during call to /explorer/transactions: " + err.Error()}, http.StatusInternalServerError)
+			return
+		}
+		if txns == nil {
+			txns = []modules.TransactionSummary{}
+		}
+		lastReorg, err := lastExplorerReorg(explorer)
+		if err != nil {
+			WriteError(w, Error{"error during call to /explorer/transactions: " + err.Error()}, http.StatusInternalServerError)
+			return
+		}
+		WriteJSON(w, ExplorerTransactionsGET{Transactions: txns, NextCursor: next, LastReorg: lastReorg})
+	}
+}
+
 // NewExplorerRootHandler creates a handler to handle API calls to /explorer
 func NewExplorerRootHandler(explorer modules.Explorer) httprouter.Handle {
 	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 		facts := explorer.LatestBlockFacts()
+		lastReorg, err := lastExplorerReorg(explorer)
+		if err != nil {
+			WriteError(w, Error{"error during call to /explorer: " + err.Error()}, http.StatusInternalServerError)
+			return
+		}
 		WriteJSON(w, ExplorerGET{
 			BlockFacts: facts,
+			LastReorg:  lastReorg,
 		})
 	}
 }
@@ -466,6 +760,145 @@ func NewExplorerRangeStatsHandler(explorer modules.Explorer) httprouter.Handle {
 	}
 }
 
+// NewExplorerAggregatesHandler creates a handler to handle API calls to /explorer/stats/aggregates/:period,
+// returning a page of the aggregates of the blocks per day or per range of heights, within the (inclusive)
+// range defined by the start and end query parameters, in seconds or blocks depending on the period.
+func NewExplorerAggregatesHandler(explorer modules.Explorer) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		opts, err := parseListOptions(req)
+		if err != nil {
+			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+			return
+		}
+		if opts.Limit > maxExplorerListLimit {
+			WriteError(w, Error{fmt.Sprintf("invalid limit %d: cannot exceed %d", opts.Limit, maxExplorerListLimit)}, http.StatusBadRequest)
+			return
+		}
+		query := modules.AggregatesQuery{
+			Period:  modules.AggregatePeriod(ps.ByName("period")),
+			Cursor:  opts.Cursor,
+			Reverse: opts.Order == ListOrderDescending,
+			Limit:   defaultExplorerListLimit,
+		}
+		if opts.Limit > 0 {
+			query.Limit = opts.Limit
+		}
+		for name, value := range map[string]*uint64{"start": &query.Start, "end": &query.End} {
+			if str := req.FormValue(name); str != "" {
+				*value, err = strconv.ParseUint(str, 10, 64)
+				if err != nil {
+					WriteError(w, Error{fmt.Sprintf("invalid %s: %v", name, err)}, http.StatusBadRequest)
+					return
+				}
+			}
+		}
+		aggregates, next, err := explorer.Aggregates(query)
+		if err == modules.ErrUnknownAggregatePeriod || err == modules.ErrInvalidAggregatesCursor {
+			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			WriteError(w, Error{"error during call to /explorer/stats/aggregates: " + err.Error()}, http.StatusInternalServerError)
+			return
+		}
+		if aggregates == nil {
+			aggregates = []modules.ChainAggregate{}
+		}
+		lastReorg, err := lastExplorerReorg(explorer)
+		if err != nil {
+			WriteError(w, Error{"error during call to /explorer/stats/aggregates: " + err.Error()}, http.StatusInternalServerError)
+			return
+		}
+		WriteJSON(w, ExplorerAggregatesGET{Aggregates: aggregates, NextCursor: next, LastReorg: lastReorg})
+	}
+}
+
+// lastExplorerReorg returns the ID of the most recent reorg processed by the explorer, 0 if there was none.
+func lastExplorerReorg(explorer modules.Explorer) (uint64, error) {
+	reorgs, err := explorer.Reorgs(1)
+	if err != nil || len(reorgs) == 0 {
+		return 0, err
+	}
+	return reorgs[0].ID, nil
+}
+
+// NewExplorerReorgsHandler creates a handler to handle API calls to /explorer/reorgs,
+// returning the most recent reorgs processed by the explorer.
+func NewExplorerReorgsHandler(explorer modules.Explorer) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		limit := maxExplorerReorgsLimit
+		if str := req.FormValue("limit"); str != "" {
+			n, err := strconv.Atoi(str)
+			if err != nil || n <= 0 || n > maxExplorerReorgsLimit {
+				WriteError(w, Error{fmt.Sprintf("invalid limit %q: expected a positive integer of at most %d", str, maxExplorerReorgsLimit)}, http.StatusBadRequest)
+				return
+			}
+			limit = n
+		}
+		reorgs, err := explorer.Reorgs(limit)
+		if err != nil {
+			WriteError(w, Error{"error during call to /explorer/reorgs: " + err.Error()}, http.StatusInternalServerError)
+			return
+		}
+		if reorgs == nil {
+			reorgs = []modules.ExplorerReorg{}
+		}
+		WriteJSON(w, ExplorerReorgsGET{Reorgs: reorgs})
+	}
+}
+
+// NewExplorerRichListHandler creates a handler to handle API calls to /explorer/richlist,
+// returning the addresses with the highest coin balance.
+func NewExplorerRichListHandler(explorer modules.Explorer) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		limit := defaultRichListLimit
+		if str := req.FormValue("limit"); str != "" {
+			n, err := strconv.Atoi(str)
+			if err != nil || n <= 0 || n > maxRichListLimit {
+				WriteError(w, Error{fmt.Sprintf("invalid limit %q: expected a positive integer of at most %d", str, maxRichListLimit)}, http.StatusBadRequest)
+				return
+			}
+			limit = n
+		}
+		balances, err := explorer.RichList(limit)
+		if err != nil {
+			WriteError(w, Error{"error during call to /explorer/richlist: " + err.Error()}, http.StatusInternalServerError)
+			return
+		}
+		if balances == nil {
+			balances = []modules.AddressBalance{}
+		}
+		WriteJSON(w, ExplorerRichListGET{Addresses: balances})
+	}
+}
+
+// NewExplorerBalanceDistributionHandler creates a handler to handle API calls to /explorer/stats/distribution
+func NewExplorerBalanceDistributionHandler(explorer modules.Explorer) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		ranges, err := explorer.BalanceDistribution()
+		if err != nil {
+			WriteError(w, Error{"error during call to /explorer/stats/distribution: " + err.Error()}, http.StatusInternalServerError)
+			return
+		}
+		if ranges == nil {
+			ranges = []modules.BalanceRange{}
+		}
+		WriteJSON(w, ExplorerBalanceDistributionGET{Ranges: ranges})
+	}
+}
+
+// NewExplorerSupplyHandler creates a handler to handle API calls to /explorer/stats/supply
+func NewExplorerSupplyHandler(explorer modules.Explorer) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		stats, err := explorer.Supply()
+		if err != nil {
+			WriteError(w, Error{"error during call to /explorer/stats/supply: " + err.Error()}, http.StatusInternalServerError)
+			return
+		}
+		WriteJSON(w, stats)
+	}
+}
+
 // getUnconfirmedTransactions returns a list of all transactions which are unconfirmed and related to the given unlock hash from the transactionpool
 func getUnconfirmedTransactions(explorer modules.Explorer, tpool modules.TransactionPool, addr types.UnlockHash) []ExplorerTransaction {
 	if tpool == nil {
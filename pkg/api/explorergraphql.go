@@ -0,0 +1,905 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/pkg/graphql"
+	"github.com/threefoldtech/rivine/types"
+)
+
+const (
+	// explorerGraphQLMaxDepth is the maximum depth of the selections of a query of /explorer/graphql.
+	explorerGraphQLMaxDepth = 12
+	// explorerGraphQLMaxFields is the maximum amount of fields resolved by a query of /explorer/graphql.
+	explorerGraphQLMaxFields = 50000
+)
+
+// The scalars of the explorer GraphQL schema, other than the built-in ones.
+var (
+	graphQLUint64 = &graphql.Scalar{
+		Name:        "Uint64",
+		Description: "an unsigned 64-bit integer, such as a block height or timestamp",
+	}
+	graphQLCurrency = &graphql.Scalar{
+		Name:        "Currency",
+		Description: "a value of coins or block stakes, in the smallest unit, encoded as a string",
+	}
+	graphQLJSON = &graphql.Scalar{
+		Name:        "JSON",
+		Description: "a JSON value, such as an unlock condition or fulfillment, encoded just like the REST API does",
+	}
+)
+
+type (
+	// explorerGraphQLContext is the context of a single query of /explorer/graphql,
+	// caching the blocks loaded by the query.
+	explorerGraphQLContext struct {
+		explorer modules.Explorer
+		blocks   map[types.BlockID]*graphQLBlock
+	}
+
+	// graphQLBlock is the source of the Block type.
+	graphQLBlock struct {
+		height types.BlockHeight
+		block  types.Block
+	}
+
+	// graphQLTransaction is the source of the Transaction type.
+	graphQLTransaction struct {
+		block *graphQLBlock
+		index int
+		txn   types.Transaction
+	}
+
+	// graphQLCoinOutput is the source of the CoinOutput type.
+	graphQLCoinOutput struct {
+		id     types.CoinOutputID
+		output types.CoinOutput
+	}
+
+	// graphQLBlockStakeOutput is the source of the BlockStakeOutput type.
+	graphQLBlockStakeOutput struct {
+		id     types.BlockStakeOutputID
+		output types.BlockStakeOutput
+	}
+)
+
+// block returns the block with the given ID, nil if it is not part of the blockchain.
+func (ctx *explorerGraphQLContext) block(id types.BlockID) *graphQLBlock {
+	if b, ok := ctx.blocks[id]; ok {
+		return b
+	}
+	block, height, ok := ctx.explorer.Block(id)
+	if !ok {
+		return nil
+	}
+	b := &graphQLBlock{height: height, block: block}
+	ctx.blocks[id] = b
+	return b
+}
+
+// blockAtHeight returns the block at the given height, nil if the explorer did not process it.
+func (ctx *explorerGraphQLContext) blockAtHeight(height types.BlockHeight) (*graphQLBlock, error) {
+	blocks, _, err := ctx.explorer.Blocks(modules.BlocksQuery{MinHeight: height, MaxHeight: height, Limit: 1})
+	if err != nil || len(blocks) == 0 || blocks[0].Height != height {
+		return nil, err
+	}
+	return ctx.block(blocks[0].BlockID), nil
+}
+
+// transaction returns the transaction with the given ID, nil if it is not part of the blockchain
+// or if the ID is the ID of a block, identifying its miner payouts.
+func (ctx *explorerGraphQLContext) transaction(id types.TransactionID) *graphQLTransaction {
+	block, _, ok := ctx.explorer.Transaction(id)
+	if !ok {
+		return nil
+	}
+	b := ctx.block(block.ID())
+	if b == nil {
+		return nil
+	}
+	return b.transaction(id)
+}
+
+// transaction returns the transaction of the block with the given ID, nil if the block does not contain it.
+func (b *graphQLBlock) transaction(id types.TransactionID) *graphQLTransaction {
+	for i, txn := range b.block.Transactions {
+		if txn.ID() == id {
+			return &graphQLTransaction{block: b, index: i, txn: txn}
+		}
+	}
+	return nil
+}
+
+// coinOutput returns the coin output with the given ID, nil if it is not part of the blockchain.
+func (ctx *explorerGraphQLContext) coinOutput(id types.CoinOutputID) *graphQLCoinOutput {
+	output, ok := ctx.explorer.CoinOutput(id)
+	if !ok {
+		return nil
+	}
+	return &graphQLCoinOutput{id: id, output: output}
+}
+
+// blockStakeOutput returns the block stake output with the given ID, nil if it is not part of the blockchain.
+func (ctx *explorerGraphQLContext) blockStakeOutput(id types.BlockStakeOutputID) *graphQLBlockStakeOutput {
+	output, ok := ctx.explorer.BlockStakeOutput(id)
+	if !ok {
+		return nil
+	}
+	return &graphQLBlockStakeOutput{id: id, output: output}
+}
+
+// outputTransaction returns the first of the given transactions referencing an output
+// which matches, e.g. by creating or spending the output,
+// nil if there is no such transaction, e.g. because the output is a miner payout or is unspent.
+func (ctx *explorerGraphQLContext) outputTransaction(ids []types.TransactionID, matches func(types.Transaction) bool) *graphQLTransaction {
+	for _, id := range ids {
+		if t := ctx.transaction(id); t != nil && matches(t.txn) {
+			return t
+		}
+	}
+	return nil
+}
+
+// graphQLContext returns the context of the query resolving the given field.
+func graphQLContext(p graphql.ResolveParams) *explorerGraphQLContext {
+	return p.Context.(*explorerGraphQLContext)
+}
+
+// graphQLUint64Arg returns the value of the given optional Uint64 argument, 0 if not given.
+func graphQLUint64Arg(p graphql.ResolveParams, name string) (uint64, error) {
+	value, ok := p.Args[name]
+	if !ok || value == nil {
+		return 0, nil
+	}
+	n, ok := value.(int64)
+	if !ok || n < 0 {
+		return 0, fmt.Errorf("invalid %s %v: expected an unsigned integer", name, value)
+	}
+	return uint64(n), nil
+}
+
+// graphQLListArgs returns the limit, cursor and order of a list field,
+// as defined by graphQLListArguments.
+func graphQLListArgs(p graphql.ResolveParams) (limit int, cursor string, reverse bool, err error) {
+	limit = int(p.Args["limit"].(int64))
+	if limit <= 0 || limit > maxExplorerListLimit {
+		return 0, "", false, fmt.Errorf("invalid limit %d: expected a positive integer of at most %d", limit, maxExplorerListLimit)
+	}
+	cursor, _ = p.Args["cursor"].(string)
+	switch order := ListOrder(p.Args["order"].(string)); order {
+	case ListOrderAscending:
+	case ListOrderDescending:
+		reverse = true
+	default:
+		return 0, "", false, fmt.Errorf("invalid order %q: expected %s or %s", order, ListOrderAscending, ListOrderDescending)
+	}
+	return limit, cursor, reverse, nil
+}
+
+// graphQLListArguments returns the arguments of a list field, preceded by the given arguments.
+func graphQLListArguments(args ...*graphql.Argument) []*graphql.Argument {
+	return append(args,
+		&graphql.Argument{Name: "limit", Type: graphql.Int, DefaultValue: int64(defaultExplorerListLimit)},
+		&graphql.Argument{Name: "cursor", Type: graphql.String},
+		&graphql.Argument{Name: "order", Type: graphql.String, DefaultValue: string(ListOrderAscending)},
+	)
+}
+
+// graphQLRangeArguments are the arguments defining the range of blocks of a list field.
+func graphQLRangeArguments() []*graphql.Argument {
+	return []*graphql.Argument{
+		{Name: "minheight", Type: graphQLUint64},
+		{Name: "maxheight", Type: graphQLUint64},
+		{Name: "mintimestamp", Type: graphQLUint64},
+		{Name: "maxtimestamp", Type: graphQLUint64},
+	}
+}
+
+// graphQLPage returns the page of a list field, with the given items and next cursor.
+func graphQLPage(itemsName string, items interface{}, next string) map[string]interface{} {
+	page := map[string]interface{}{itemsName: items, "nextcursor": nil}
+	if next != "" {
+		page["nextcursor"] = next
+	}
+	return page
+}
+
+// graphQLPageType returns the type of the page of a list field.
+func graphQLPageType(name, itemsName string, itemType graphql.Type) *graphql.Object {
+	return &graphql.Object{
+		Name: name,
+		Fields: map[string]*graphql.Field{
+			itemsName:    {Type: graphql.ListOf(itemType)},
+			"nextcursor": {Type: graphql.String, Description: "identifies the next page, null if there is no next page"},
+		},
+	}
+}
+
+// graphQLSourceField returns a field resolved from a source of the given type using the given function.
+func graphQLSourceField(t graphql.Type, description string, fn func(p graphql.ResolveParams) (interface{}, error)) *graphql.Field {
+	return &graphql.Field{Type: t, Description: description, Resolve: fn}
+}
+
+// newExplorerGraphQLSchema creates the schema of /explorer/graphql,
+// resolving the fields using the explorer of the context of each query.
+func newExplorerGraphQLSchema() *graphql.Schema {
+	block := &graphql.Object{Name: "Block"}
+	transaction := &graphql.Object{Name: "Transaction"}
+	coinOutput := &graphql.Object{Name: "CoinOutput"}
+	blockStakeOutput := &graphql.Object{Name: "BlockStakeOutput"}
+	address := &graphql.Object{Name: "Address"}
+
+	blockFacts := &graphql.Object{
+		Name:        "BlockFacts",
+		Description: "statistics of the blockchain, as they were at a block",
+		Fields: map[string]*graphql.Field{
+			"blockid":           {Type: graphql.String},
+			"height":            {Type: graphQLUint64},
+			"difficulty":        {Type: graphql.String},
+			"estimatedactivebs": {Type: graphql.String},
+			"maturitytimestamp": {Type: graphQLUint64},
+			"target": graphQLSourceField(graphql.String, "", func(p graphql.ResolveParams) (interface{}, error) {
+				return crypto.Hash(p.Source.(modules.BlockFacts).Target), nil
+			}),
+			"totalcoins":             {Type: graphQLCurrency},
+			"arbitrarydatatotalsize": {Type: graphQLUint64},
+			"minerpayoutcount":       {Type: graphQLUint64},
+			"transactioncount":       {Type: graphQLUint64},
+			"coininputcount":         {Type: graphQLUint64},
+			"coinoutputcount":        {Type: graphQLUint64},
+			"blockstakeinputcount":   {Type: graphQLUint64},
+			"blockstakeoutputcount":  {Type: graphQLUint64},
+			"minerfeecount":          {Type: graphQLUint64},
+			"arbitrarydatacount":     {Type: graphQLUint64},
+		},
+	}
+
+	blockOfID := func(id types.BlockID) graphql.ResolveFunc {
+		return func(p graphql.ResolveParams) (interface{}, error) {
+			return graphQLContext(p).block(id), nil
+		}
+	}
+
+	block.Fields = map[string]*graphql.Field{
+		"id": graphQLSourceField(graphql.String, "", func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*graphQLBlock).block.ID(), nil
+		}),
+		"parentid": graphQLSourceField(graphql.String, "", func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*graphQLBlock).block.ParentID, nil
+		}),
+		"height": graphQLSourceField(graphQLUint64, "", func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*graphQLBlock).height, nil
+		}),
+		"timestamp": graphQLSourceField(graphQLUint64, "", func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*graphQLBlock).block.Timestamp, nil
+		}),
+		"parent": graphQLSourceField(block, "null for the genesis block", func(p graphql.ResolveParams) (interface{}, error) {
+			b := p.Source.(*graphQLBlock)
+			if b.height == 0 {
+				return nil, nil
+			}
+			return blockOfID(b.block.ParentID)(p)
+		}),
+		"facts": graphQLSourceField(blockFacts, "", func(p graphql.ResolveParams) (interface{}, error) {
+			facts, ok := graphQLContext(p).explorer.BlockFacts(p.Source.(*graphQLBlock).height)
+			if !ok {
+				return nil, nil
+			}
+			return facts, nil
+		}),
+		"minerpayouts": graphQLSourceField(graphql.ListOf(coinOutput), "", func(p graphql.ResolveParams) (interface{}, error) {
+			b := p.Source.(*graphQLBlock)
+			outputs := make([]*graphQLCoinOutput, 0, len(b.block.MinerPayouts))
+			for i, payout := range b.block.MinerPayouts {
+				outputs = append(outputs, &graphQLCoinOutput{
+					id: b.block.MinerPayoutID(uint64(i)),
+					output: types.CoinOutput{
+						Value:     payout.Value,
+						Condition: types.NewCondition(types.NewUnlockHashCondition(payout.UnlockHash)),
+					},
+				})
+			}
+			return outputs, nil
+		}),
+		"transactioncount": graphQLSourceField(graphql.Int, "", func(p graphql.ResolveParams) (interface{}, error) {
+			return len(p.Source.(*graphQLBlock).block.Transactions), nil
+		}),
+		"transactions": graphQLSourceField(graphql.ListOf(transaction), "", func(p graphql.ResolveParams) (interface{}, error) {
+			b := p.Source.(*graphQLBlock)
+			txns := make([]*graphQLTransaction, 0, len(b.block.Transactions))
+			for i, txn := range b.block.Transactions {
+				txns = append(txns, &graphQLTransaction{block: b, index: i, txn: txn})
+			}
+			return txns, nil
+		}),
+	}
+
+	coinInput := &graphql.Object{
+		Name: "CoinInput",
+		Fields: map[string]*graphql.Field{
+			"parentid": graphQLSourceField(graphql.String, "", func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(types.CoinInput).ParentID, nil
+			}),
+			"fulfillment": graphQLSourceField(graphQLJSON, "", func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(types.CoinInput).Fulfillment, nil
+			}),
+			"output": graphQLSourceField(coinOutput, "the coin output spent by the input", func(p graphql.ResolveParams) (interface{}, error) {
+				return graphQLContext(p).coinOutput(p.Source.(types.CoinInput).ParentID), nil
+			}),
+		},
+	}
+	blockStakeInput := &graphql.Object{
+		Name: "BlockStakeInput",
+		Fields: map[string]*graphql.Field{
+			"parentid": graphQLSourceField(graphql.String, "", func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(types.BlockStakeInput).ParentID, nil
+			}),
+			"fulfillment": graphQLSourceField(graphQLJSON, "", func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(types.BlockStakeInput).Fulfillment, nil
+			}),
+			"output": graphQLSourceField(blockStakeOutput, "the block stake output spent by the input", func(p graphql.ResolveParams) (interface{}, error) {
+				return graphQLContext(p).blockStakeOutput(p.Source.(types.BlockStakeInput).ParentID), nil
+			}),
+		},
+	}
+
+	transaction.Fields = map[string]*graphql.Field{
+		"id": graphQLSourceField(graphql.String, "", func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*graphQLTransaction).txn.ID(), nil
+		}),
+		"version": graphQLSourceField(graphql.Int, "", func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*graphQLTransaction).txn.Version, nil
+		}),
+		"height": graphQLSourceField(graphQLUint64, "the height of the block of the transaction", func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*graphQLTransaction).block.height, nil
+		}),
+		"index": graphQLSourceField(graphql.Int, "the index of the transaction within its block", func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*graphQLTransaction).index, nil
+		}),
+		"blockid": graphQLSourceField(graphql.String, "", func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*graphQLTransaction).block.block.ID(), nil
+		}),
+		"block": graphQLSourceField(block, "", func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*graphQLTransaction).block, nil
+		}),
+		"coininputs": graphQLSourceField(graphql.ListOf(coinInput), "", func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*graphQLTransaction).txn.CoinInputs, nil
+		}),
+		"coinoutputs": graphQLSourceField(graphql.ListOf(coinOutput), "", func(p graphql.ResolveParams) (interface{}, error) {
+			txn := p.Source.(*graphQLTransaction).txn
+			outputs := make([]*graphQLCoinOutput, 0, len(txn.CoinOutputs))
+			for i, co := range txn.CoinOutputs {
+				outputs = append(outputs, &graphQLCoinOutput{id: txn.CoinOutputID(uint64(i)), output: co})
+			}
+			return outputs, nil
+		}),
+		"blockstakeinputs": graphQLSourceField(graphql.ListOf(blockStakeInput), "", func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*graphQLTransaction).txn.BlockStakeInputs, nil
+		}),
+		"blockstakeoutputs": graphQLSourceField(graphql.ListOf(blockStakeOutput), "", func(p graphql.ResolveParams) (interface{}, error) {
+			txn := p.Source.(*graphQLTransaction).txn
+			outputs := make([]*graphQLBlockStakeOutput, 0, len(txn.BlockStakeOutputs))
+			for i, bso := range txn.BlockStakeOutputs {
+				outputs = append(outputs, &graphQLBlockStakeOutput{id: txn.BlockStakeOutputID(uint64(i)), output: bso})
+			}
+			return outputs, nil
+		}),
+		"minerfees": graphQLSourceField(graphql.ListOf(graphQLCurrency), "", func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*graphQLTransaction).txn.MinerFees, nil
+		}),
+		"arbitrarydata": graphQLSourceField(graphql.String, "base64-encoded", func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*graphQLTransaction).txn.ArbitraryData, nil
+		}),
+	}
+
+	coinOutput.Fields = map[string]*graphql.Field{
+		"id": graphQLSourceField(graphql.String, "", func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*graphQLCoinOutput).id, nil
+		}),
+		"value": graphQLSourceField(graphQLCurrency, "", func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*graphQLCoinOutput).output.Value, nil
+		}),
+		"condition": graphQLSourceField(graphQLJSON, "", func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*graphQLCoinOutput).output.Condition, nil
+		}),
+		"unlockhash": graphQLSourceField(graphql.String, "", func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*graphQLCoinOutput).output.Condition.UnlockHash(), nil
+		}),
+		"address": graphQLSourceField(address, "", func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*graphQLCoinOutput).output.Condition.UnlockHash(), nil
+		}),
+		"transaction": graphQLSourceField(transaction, "the transaction creating the output, null for miner payouts", func(p graphql.ResolveParams) (interface{}, error) {
+			id := p.Source.(*graphQLCoinOutput).id
+			ctx := graphQLContext(p)
+			return ctx.outputTransaction(ctx.explorer.CoinOutputID(id), func(txn types.Transaction) bool {
+				for i := range txn.CoinOutputs {
+					if txn.CoinOutputID(uint64(i)) == id {
+						return true
+					}
+				}
+				return false
+			}), nil
+		}),
+		"spendingtransaction": graphQLSourceField(transaction, "the transaction spending the output, null if unspent", func(p graphql.ResolveParams) (interface{}, error) {
+			id := p.Source.(*graphQLCoinOutput).id
+			ctx := graphQLContext(p)
+			return ctx.outputTransaction(ctx.explorer.CoinOutputID(id), func(txn types.Transaction) bool {
+				for _, ci := range txn.CoinInputs {
+					if ci.ParentID == id {
+						return true
+					}
+				}
+				return false
+			}), nil
+		}),
+	}
+
+	blockStakeOutput.Fields = map[string]*graphql.Field{
+		"id": graphQLSourceField(graphql.String, "", func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*graphQLBlockStakeOutput).id, nil
+		}),
+		"value": graphQLSourceField(graphQLCurrency, "", func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*graphQLBlockStakeOutput).output.Value, nil
+		}),
+		"condition": graphQLSourceField(graphQLJSON, "", func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*graphQLBlockStakeOutput).output.Condition, nil
+		}),
+		"unlockhash": graphQLSourceField(graphql.String, "", func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*graphQLBlockStakeOutput).output.Condition.UnlockHash(), nil
+		}),
+		"address": graphQLSourceField(address, "", func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*graphQLBlockStakeOutput).output.Condition.UnlockHash(), nil
+		}),
+		"transaction": graphQLSourceField(transaction, "the transaction creating the output, null for the genesis allocation", func(p graphql.ResolveParams) (interface{}, error) {
+			id := p.Source.(*graphQLBlockStakeOutput).id
+			ctx := graphQLContext(p)
+			return ctx.outputTransaction(ctx.explorer.BlockStakeOutputID(id), func(txn types.Transaction) bool {
+				for i := range txn.BlockStakeOutputs {
+					if txn.BlockStakeOutputID(uint64(i)) == id {
+						return true
+					}
+				}
+				return false
+			}), nil
+		}),
+		"spendingtransaction": graphQLSourceField(transaction, "the transaction spending the output, null if unspent", func(p graphql.ResolveParams) (interface{}, error) {
+			id := p.Source.(*graphQLBlockStakeOutput).id
+			ctx := graphQLContext(p)
+			return ctx.outputTransaction(ctx.explorer.BlockStakeOutputID(id), func(txn types.Transaction) bool {
+				for _, bsi := range txn.BlockStakeInputs {
+					if bsi.ParentID == id {
+						return true
+					}
+				}
+				return false
+			}), nil
+		}),
+	}
+
+	addressBalance := &graphql.Object{
+		Name: "AddressBalance",
+		Fields: map[string]*graphql.Field{
+			"unlockhash":  {Type: graphql.String},
+			"coins":       {Type: graphQLCurrency},
+			"blockstakes": {Type: graphQLCurrency},
+			"address": graphQLSourceField(address, "", func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(modules.AddressBalance).UnlockHash, nil
+			}),
+		},
+	}
+	addressTransaction := &graphql.Object{
+		Name:        "AddressTransaction",
+		Description: "a transaction creating or spending outputs of an address, or the miner payouts of a block",
+		Fields: map[string]*graphql.Field{
+			"transactionid":       {Type: graphql.String, Description: "the ID of the block for miner payouts"},
+			"blockid":             {Type: graphql.String},
+			"height":              {Type: graphQLUint64},
+			"index":               {Type: graphql.Int},
+			"minerpayout":         {Type: graphql.Boolean},
+			"direction":           {Type: graphql.String, Description: "incoming, outgoing or both"},
+			"coinsreceived":       {Type: graphQLCurrency},
+			"coinssent":           {Type: graphQLCurrency},
+			"blockstakesreceived": {Type: graphQLCurrency},
+			"blockstakessent":     {Type: graphQLCurrency},
+			"transaction": graphQLSourceField(transaction, "null for miner payouts", func(p graphql.ResolveParams) (interface{}, error) {
+				at := p.Source.(modules.AddressTransaction)
+				if at.MinerPayout {
+					return nil, nil
+				}
+				b := graphQLContext(p).block(at.BlockID)
+				if b == nil {
+					return nil, nil
+				}
+				return b.transaction(at.TransactionID), nil
+			}),
+			"block": graphQLSourceField(block, "", func(p graphql.ResolveParams) (interface{}, error) {
+				return blockOfID(p.Source.(modules.AddressTransaction).BlockID)(p)
+			}),
+		},
+	}
+
+	address.Fields = map[string]*graphql.Field{
+		"unlockhash": graphQLSourceField(graphql.String, "", func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(types.UnlockHash), nil
+		}),
+		"balance": graphQLSourceField(addressBalance, "", func(p graphql.ResolveParams) (interface{}, error) {
+			return graphQLContext(p).explorer.AddressBalance(p.Source.(types.UnlockHash))
+		}),
+		"transactions": {
+			Type:        graphQLPageType("AddressTransactionPage", "transactions", addressTransaction),
+			Description: "the transactions creating or spending outputs of the address, ordered by height and index",
+			Args: graphQLListArguments(
+				&graphql.Argument{Name: "minheight", Type: graphQLUint64},
+				&graphql.Argument{Name: "maxheight", Type: graphQLUint64},
+				&graphql.Argument{Name: "direction", Type: graphql.String, Description: "incoming, outgoing or both"},
+			),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				query := modules.AddressTransactionsQuery{}
+				var err error
+				query.Limit, query.Cursor, query.Reverse, err = graphQLListArgs(p)
+				if err != nil {
+					return nil, err
+				}
+				minHeight, err := graphQLUint64Arg(p, "minheight")
+				if err != nil {
+					return nil, err
+				}
+				maxHeight, err := graphQLUint64Arg(p, "maxheight")
+				if err != nil {
+					return nil, err
+				}
+				query.MinHeight, query.MaxHeight = types.BlockHeight(minHeight), types.BlockHeight(maxHeight)
+				if direction, ok := p.Args["direction"].(string); ok {
+					query.Direction = modules.AddressTransactionDirection(direction)
+					switch query.Direction {
+					case modules.AddressTransactionIncoming, modules.AddressTransactionOutgoing, modules.AddressTransactionBoth:
+					default:
+						return nil, fmt.Errorf("invalid direction %q", direction)
+					}
+				}
+				txns, next, err := graphQLContext(p).explorer.AddressTransactions(p.Source.(types.UnlockHash), query)
+				if err != nil {
+					return nil, err
+				}
+				return graphQLPage("transactions", txns, next), nil
+			},
+		},
+		"multisigaddresses": graphQLSourceField(graphql.ListOf(address), "the multi-signature addresses the (wallet) address is part of", func(p graphql.ResolveParams) (interface{}, error) {
+			return graphQLContext(p).explorer.MultiSigAddresses(p.Source.(types.UnlockHash)), nil
+		}),
+	}
+
+	supply := &graphql.Object{
+		Name: "Supply",
+		Fields: map[string]*graphql.Field{
+			"height":      {Type: graphQLUint64},
+			"coins":       {Type: graphQLCurrency},
+			"activecoins": {Type: graphQLCurrency},
+			"lockedcoins": {Type: graphQLCurrency},
+			"blockstakes": {Type: graphQLCurrency},
+			"addresses":   {Type: graphQLUint64},
+		},
+	}
+	aggregate := &graphql.Object{
+		Name:        "Aggregate",
+		Description: "the aggregate of the blocks of a day or of a range of blocks",
+		Fields: map[string]*graphql.Field{
+			"start":            {Type: graphQLUint64, Description: "the timestamp of the start of the day, or the height of the first block of the range"},
+			"blocks":           {Type: graphQLUint64},
+			"transactions":     {Type: graphQLUint64},
+			"minerfees":        {Type: graphQLCurrency},
+			"coinoutputvalue":  {Type: graphQLCurrency},
+			"averageblocktime": {Type: graphql.Float},
+			"averageblocksize": {Type: graphql.Float},
+		},
+	}
+	reorg := &graphql.Object{
+		Name: "Reorg",
+		Fields: map[string]*graphql.Field{
+			"id":             {Type: graphQLUint64},
+			"forkheight":     {Type: graphQLUint64},
+			"revertedblocks": {Type: graphql.ListOf(graphql.String), Description: "the IDs of the reverted blocks"},
+			"appliedblocks": graphQLSourceField(graphql.ListOf(block), "the blocks applied in place of the reverted blocks, if still part of the blockchain", func(p graphql.ResolveParams) (interface{}, error) {
+				var blocks []*graphQLBlock
+				for _, id := range p.Source.(modules.ExplorerReorg).AppliedBlocks {
+					if b := graphQLContext(p).block(id); b != nil {
+						blocks = append(blocks, b)
+					}
+				}
+				return blocks, nil
+			}),
+			"timestamp": {Type: graphQLUint64},
+		},
+	}
+
+	query := &graphql.Object{
+		Name: "Query",
+		Fields: map[string]*graphql.Field{
+			"facts": graphQLSourceField(blockFacts, "the statistics of the blockchain, as they are at the last block", func(p graphql.ResolveParams) (interface{}, error) {
+				return graphQLContext(p).explorer.LatestBlockFacts(), nil
+			}),
+			"block": {
+				Type:        block,
+				Description: "the block with the given ID or at the given height",
+				Args: []*graphql.Argument{
+					{Name: "id", Type: graphql.String},
+					{Name: "height", Type: graphQLUint64},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					ctx := graphQLContext(p)
+					if str, ok := p.Args["id"].(string); ok {
+						hash, err := ScanHash(str)
+						if err != nil {
+							return nil, err
+						}
+						return ctx.block(types.BlockID(hash)), nil
+					}
+					if _, ok := p.Args["height"]; !ok {
+						return nil, errors.New("either the id or the height of the block is required")
+					}
+					height, err := graphQLUint64Arg(p, "height")
+					if err != nil {
+						return nil, err
+					}
+					return ctx.blockAtHeight(types.BlockHeight(height))
+				},
+			},
+			"blocks": {
+				Type:        graphQLPageType("BlockPage", "blocks", block),
+				Description: "the blocks within the given ranges, ordered by height",
+				Args:        graphQLListArguments(graphQLRangeArguments()...),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					query := modules.BlocksQuery{}
+					var err error
+					query.Limit, query.Cursor, query.Reverse, err = graphQLListArgs(p)
+					if err != nil {
+						return nil, err
+					}
+					var values [4]uint64
+					for i, name := range []string{"minheight", "maxheight", "mintimestamp", "maxtimestamp"} {
+						if values[i], err = graphQLUint64Arg(p, name); err != nil {
+							return nil, err
+						}
+					}
+					query.MinHeight, query.MaxHeight = types.BlockHeight(values[0]), types.BlockHeight(values[1])
+					query.MinTimestamp, query.MaxTimestamp = types.Timestamp(values[2]), types.Timestamp(values[3])
+					ctx := graphQLContext(p)
+					summaries, next, err := ctx.explorer.Blocks(query)
+					if err != nil {
+						return nil, err
+					}
+					blocks := make([]*graphQLBlock, 0, len(summaries))
+					for _, summary := range summaries {
+						if b := ctx.block(summary.BlockID); b != nil {
+							blocks = append(blocks, b)
+						}
+					}
+					return graphQLPage("blocks", blocks, next), nil
+				},
+			},
+			"transaction": {
+				Type: transaction,
+				Args: []*graphql.Argument{{Name: "id", Type: graphql.String, Required: true}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					hash, err := ScanHash(p.Args["id"].(string))
+					if err != nil {
+						return nil, err
+					}
+					return graphQLContext(p).transaction(types.TransactionID(hash)), nil
+				},
+			},
+			"transactions": {
+				Type:        graphQLPageType("TransactionPage", "transactions", transaction),
+				Description: "the transactions within the given ranges, optionally filtered by version and minimum value, ordered by height and index",
+				Args: graphQLListArguments(append(graphQLRangeArguments(),
+					&graphql.Argument{Name: "versions", Type: graphql.ListOf(graphql.Int)},
+					&graphql.Argument{Name: "minvalue", Type: graphQLCurrency, Description: "the minimum total value of the coin outputs"},
+				)...),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					query := modules.TransactionsQuery{MinValue: types.ZeroCurrency}
+					var err error
+					query.Limit, query.Cursor, query.Reverse, err = graphQLListArgs(p)
+					if err != nil {
+						return nil, err
+					}
+					var values [4]uint64
+					for i, name := range []string{"minheight", "maxheight", "mintimestamp", "maxtimestamp"} {
+						if values[i], err = graphQLUint64Arg(p, name); err != nil {
+							return nil, err
+						}
+					}
+					query.MinHeight, query.MaxHeight = types.BlockHeight(values[0]), types.BlockHeight(values[1])
+					query.MinTimestamp, query.MaxTimestamp = types.Timestamp(values[2]), types.Timestamp(values[3])
+					if versions, ok := p.Args["versions"].([]interface{}); ok {
+						for _, version := range versions {
+							v, ok := version.(int64)
+							if !ok || v < 0 || v > 255 {
+								return nil, fmt.Errorf("invalid version %v", version)
+							}
+							query.Versions = append(query.Versions, types.TransactionVersion(v))
+						}
+					}
+					if value, ok := p.Args["minvalue"]; ok && value != nil {
+						str, isString := value.(string)
+						if isString && !strings.HasPrefix(str, "-") {
+							query.MinValue, ok = ScanAmount(str)
+						}
+						if !isString || !ok {
+							return nil, fmt.Errorf("invalid minvalue %v", value)
+						}
+					}
+					ctx := graphQLContext(p)
+					summaries, next, err := ctx.explorer.Transactions(query)
+					if err != nil {
+						return nil, err
+					}
+					txns := make([]*graphQLTransaction, 0, len(summaries))
+					for _, summary := range summaries {
+						b := ctx.block(summary.BlockID)
+						if b != nil && summary.Index < len(b.block.Transactions) {
+							txns = append(txns, &graphQLTransaction{block: b, index: summary.Index, txn: b.block.Transactions[summary.Index]})
+						}
+					}
+					return graphQLPage("transactions", txns, next), nil
+				},
+			},
+			"address": {
+				Type: address,
+				Args: []*graphql.Argument{{Name: "unlockhash", Type: graphql.String, Required: true}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return ScanAddress(p.Args["unlockhash"].(string))
+				},
+			},
+			"coinoutput": {
+				Type: coinOutput,
+				Args: []*graphql.Argument{{Name: "id", Type: graphql.String, Required: true}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					hash, err := ScanHash(p.Args["id"].(string))
+					if err != nil {
+						return nil, err
+					}
+					return graphQLContext(p).coinOutput(types.CoinOutputID(hash)), nil
+				},
+			},
+			"blockstakeoutput": {
+				Type: blockStakeOutput,
+				Args: []*graphql.Argument{{Name: "id", Type: graphql.String, Required: true}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					hash, err := ScanHash(p.Args["id"].(string))
+					if err != nil {
+						return nil, err
+					}
+					return graphQLContext(p).blockStakeOutput(types.BlockStakeOutputID(hash)), nil
+				},
+			},
+			"richlist": {
+				Type:        graphql.ListOf(addressBalance),
+				Description: "the addresses with the highest coin balance, ordered from the highest to the lowest balance",
+				Args:        []*graphql.Argument{{Name: "limit", Type: graphql.Int, DefaultValue: int64(defaultRichListLimit)}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					limit := p.Args["limit"].(int64)
+					if limit <= 0 || limit > maxRichListLimit {
+						return nil, fmt.Errorf("invalid limit %d: expected a positive integer of at most %d", limit, maxRichListLimit)
+					}
+					return graphQLContext(p).explorer.RichList(int(limit))
+				},
+			},
+			"supply": graphQLSourceField(supply, "", func(p graphql.ResolveParams) (interface{}, error) {
+				return graphQLContext(p).explorer.Supply()
+			}),
+			"aggregates": {
+				Type:        graphQLPageType("AggregatePage", "aggregates", aggregate),
+				Description: "the aggregates of the blocks per day or per range of heights, ordered by period",
+				Args: graphQLListArguments(
+					&graphql.Argument{Name: "period", Type: graphql.String, Required: true, Description: "day or blocks"},
+					&graphql.Argument{Name: "start", Type: graphQLUint64, Description: "in seconds or blocks, depending on the period"},
+					&graphql.Argument{Name: "end", Type: graphQLUint64, Description: "in seconds or blocks, depending on the period"},
+				),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					query := modules.AggregatesQuery{Period: modules.AggregatePeriod(p.Args["period"].(string))}
+					var err error
+					query.Limit, query.Cursor, query.Reverse, err = graphQLListArgs(p)
+					if err != nil {
+						return nil, err
+					}
+					if query.Start, err = graphQLUint64Arg(p, "start"); err != nil {
+						return nil, err
+					}
+					if query.End, err = graphQLUint64Arg(p, "end"); err != nil {
+						return nil, err
+					}
+					aggregates, next, err := graphQLContext(p).explorer.Aggregates(query)
+					if err != nil {
+						return nil, err
+					}
+					return graphQLPage("aggregates", aggregates, next), nil
+				},
+			},
+			"reorgs": {
+				Type:        graphql.ListOf(reorg),
+				Description: "the most recent reorgs, ordered from the newest to the oldest",
+				Args:        []*graphql.Argument{{Name: "limit", Type: graphql.Int, DefaultValue: int64(maxExplorerReorgsLimit)}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					limit := p.Args["limit"].(int64)
+					if limit <= 0 || limit > maxExplorerReorgsLimit {
+						return nil, fmt.Errorf("invalid limit %d: expected a positive integer of at most %d", limit, maxExplorerReorgsLimit)
+					}
+					return graphQLContext(p).explorer.Reorgs(int(limit))
+				},
+			},
+		},
+	}
+	return &graphql.Schema{Query: query, MaxDepth: explorerGraphQLMaxDepth, MaxFields: explorerGraphQLMaxFields}
+}
+
+// RegisterExplorerGraphQLHTTPHandlers registers the handlers of the GraphQL API of the explorer,
+// served next to its REST API, see RegisterExplorerHTTPHandlers.
+func RegisterExplorerGraphQLHTTPHandlers(router Router, explorer modules.Explorer) {
+	if explorer == nil {
+		panic("no explorer module given")
+	}
+	if router == nil {
+		panic("no httprouter Router given")
+	}
+	schema := newExplorerGraphQLSchema()
+	router.GET("/explorer/graphql", NewExplorerGraphQLHandler(schema, explorer))
+	router.POST("/explorer/graphql", NewExplorerGraphQLHandler(schema, explorer))
+	router.GET("/explorer/graphql/schema", NewExplorerGraphQLSchemaHandler(schema))
+}
+
+// NewExplorerGraphQLHandler creates a handler to handle API calls to /explorer/graphql,
+// executing the query given as the query parameters of a GET call, or as the JSON body of a POST call.
+func NewExplorerGraphQLHandler(schema *graphql.Schema, explorer modules.Explorer) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		var gqlReq graphql.Request
+		if req.Method == http.MethodPost {
+			if err := json.NewDecoder(req.Body).Decode(&gqlReq); err != nil {
+				WriteError(w, Error{"error decoding the supplied GraphQL request: " + err.Error()}, http.StatusBadRequest)
+				return
+			}
+		} else {
+			gqlReq.Query = req.FormValue("query")
+			gqlReq.OperationName = req.FormValue("operationName")
+			if str := req.FormValue("variables"); str != "" {
+				if err := json.Unmarshal([]byte(str), &gqlReq.Variables); err != nil {
+					WriteError(w, Error{"invalid variables: " + err.Error()}, http.StatusBadRequest)
+					return
+				}
+			}
+		}
+		if gqlReq.Query == "" {
+			WriteError(w, Error{"no GraphQL query given"}, http.StatusBadRequest)
+			return
+		}
+		resp := schema.Execute(gqlReq, nil, &explorerGraphQLContext{
+			explorer: explorer,
+			blocks:   make(map[types.BlockID]*graphQLBlock),
+		})
+		if resp.Data == nil {
+			// the query could not be executed at all
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(resp)
+			return
+		}
+		WriteJSON(w, resp)
+	}
+}
+
+// NewExplorerGraphQLSchemaHandler creates a handler to handle API calls to /explorer/graphql/schema,
+// returning the schema of /explorer/graphql in the GraphQL schema definition language.
+func NewExplorerGraphQLSchemaHandler(schema *graphql.Schema) httprouter.Handle {
+	sdl := schema.String()
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte(sdl))
+	}
+}
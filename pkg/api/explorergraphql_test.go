@@ -0,0 +1,154 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// graphQLExplorer is an explorer of a chain of blocks,
+// only implementing the methods used by the tested GraphQL queries.
+type graphQLExplorer struct {
+	modules.Explorer
+	blocks []types.Block
+}
+
+func (e *graphQLExplorer) Block(id types.BlockID) (types.Block, types.BlockHeight, bool) {
+	for height, block := range e.blocks {
+		if block.ID() == id {
+			return block, types.BlockHeight(height), true
+		}
+	}
+	return types.Block{}, 0, false
+}
+
+func (e *graphQLExplorer) Blocks(query modules.BlocksQuery) ([]modules.BlockSummary, string, error) {
+	var summaries []modules.BlockSummary
+	for height, block := range e.blocks {
+		if types.BlockHeight(height) < query.MinHeight || (query.MaxHeight != 0 && types.BlockHeight(height) > query.MaxHeight) {
+			continue
+		}
+		summaries = append(summaries, modules.BlockSummary{BlockID: block.ID(), Height: types.BlockHeight(height)})
+	}
+	return summaries, "", nil
+}
+
+func (e *graphQLExplorer) Transaction(id types.TransactionID) (types.Block, types.BlockHeight, bool) {
+	for height, block := range e.blocks {
+		for _, txn := range block.Transactions {
+			if txn.ID() == id {
+				return block, types.BlockHeight(height), true
+			}
+		}
+	}
+	return types.Block{}, 0, false
+}
+
+func (e *graphQLExplorer) CoinOutputID(id types.CoinOutputID) []types.TransactionID {
+	var ids []types.TransactionID
+	for _, block := range e.blocks {
+		for _, txn := range block.Transactions {
+			for i := range txn.CoinOutputs {
+				if txn.CoinOutputID(uint64(i)) == id {
+					ids = append(ids, txn.ID())
+				}
+			}
+			for _, ci := range txn.CoinInputs {
+				if ci.ParentID == id {
+					ids = append(ids, txn.ID())
+				}
+			}
+		}
+	}
+	return ids
+}
+
+func (e *graphQLExplorer) AddressBalance(uh types.UnlockHash) (modules.AddressBalance, error) {
+	return modules.AddressBalance{UnlockHash: uh, Coins: types.NewCurrency64(uint64(uh.Hash[0]))}, nil
+}
+
+func TestExplorerGraphQLHandler(t *testing.T) {
+	alice := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: crypto.Hash{1}}
+	bob := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: crypto.Hash{2}}
+	funding := types.Transaction{Version: types.TransactionVersionOne, CoinOutputs: []types.CoinOutput{
+		{Value: types.NewCurrency64(10), Condition: types.NewCondition(types.NewUnlockHashCondition(alice))},
+	}}
+	spending := types.Transaction{Version: types.TransactionVersionOne, CoinInputs: []types.CoinInput{
+		{ParentID: funding.CoinOutputID(0)},
+	}, CoinOutputs: []types.CoinOutput{
+		{Value: types.NewCurrency64(9), Condition: types.NewCondition(types.NewUnlockHashCondition(bob))},
+	}, MinerFees: []types.Currency{types.NewCurrency64(1)}}
+	genesis := types.Block{Timestamp: 1000, Transactions: []types.Transaction{funding}}
+	explorer := &graphQLExplorer{blocks: []types.Block{
+		genesis,
+		{ParentID: genesis.ID(), Timestamp: 1600, Transactions: []types.Transaction{spending}},
+	}}
+
+	router := httprouter.New()
+	RegisterExplorerGraphQLHTTPHandlers(router, explorer)
+	call := func(req *http.Request) (int, []byte) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code, rec.Body.Bytes()
+	}
+
+	// the coin output created in the genesis block, its owner and the transaction spending it
+	query := `query Outputs($height: Uint64) {
+		block(height: $height) {
+			height
+			transactions {
+				coinoutputs {
+					value
+					address { unlockhash balance { coins } }
+					spendingtransaction { height minerfees block { parent { timestamp } } }
+				}
+			}
+		}
+	}`
+	body, err := json.Marshal(map[string]interface{}{"query": query, "variables": map[string]interface{}{"height": 0}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, resp := call(httptest.NewRequest(http.MethodPost, "/explorer/graphql", bytes.NewReader(body)))
+	expected := `{"data":{"block":{"height":0,"transactions":[{"coinoutputs":[{"value":"10",` +
+		`"address":{"unlockhash":"` + alice.String() + `","balance":{"coins":"1"}},` +
+		`"spendingtransaction":{"height":1,"minerfees":["1"],"block":{"parent":{"timestamp":1000}}}}]}]}}}`
+	if code != http.StatusOK || strings.TrimSpace(string(resp)) != expected {
+		t.Errorf("unexpected response: %d %s\nexpected: %s", code, resp, expected)
+	}
+
+	// the same query over GET, listing the blocks instead
+	code, resp = call(httptest.NewRequest(http.MethodGet, "/explorer/graphql?query="+url.QueryEscape(
+		`{ blocks(minheight: 1) { blocks { height transactioncount } nextcursor } }`), nil))
+	expected = `{"data":{"blocks":{"blocks":[{"height":1,"transactioncount":1}],"nextcursor":null}}}`
+	if code != http.StatusOK || strings.TrimSpace(string(resp)) != expected {
+		t.Errorf("unexpected response: %d %s\nexpected: %s", code, resp, expected)
+	}
+
+	// queries which cannot be executed are refused, field errors are not
+	if code, resp = call(httptest.NewRequest(http.MethodGet, "/explorer/graphql?query="+url.QueryEscape(`{ block { hash } }`), nil)); code != http.StatusBadRequest {
+		t.Errorf("expected an invalid query to be refused: %d %s", code, resp)
+	}
+	if code, _ = call(httptest.NewRequest(http.MethodGet, "/explorer/graphql", nil)); code != http.StatusBadRequest {
+		t.Errorf("expected a missing query to be refused: %d", code)
+	}
+	code, resp = call(httptest.NewRequest(http.MethodGet, "/explorer/graphql?query="+url.QueryEscape(`{ blocks(limit: 0) { nextcursor } }`), nil))
+	if code != http.StatusOK || !strings.Contains(string(resp), `"data":{"blocks":null}`) || !strings.Contains(string(resp), "invalid limit 0") {
+		t.Errorf("expected an invalid limit to result in a field error: %d %s", code, resp)
+	}
+
+	// the schema is served in the schema definition language
+	code, resp = call(httptest.NewRequest(http.MethodGet, "/explorer/graphql/schema", nil))
+	if code != http.StatusOK || !strings.Contains(string(resp), "  address(unlockhash: String!): Address\n") {
+		t.Errorf("unexpected schema: %d %s", code, resp)
+	}
+}
@@ -27,6 +27,31 @@ func RegisterGatewayHTTPHandlers(router Router, gateway modules.Gateway, require
 	router.POST("/gateway/disconnect/:netaddress", RequirePasswordHandler(NewGatewayDisconnectHandler(gateway), requiredPassword))
 }
 
+// RegisterGatewayAPIv2Handlers registers the Gateway calls of version 2 of the API.
+func RegisterGatewayAPIv2Handlers(v2 *APIv2, gateway modules.Gateway) {
+	if gateway == nil {
+		panic("no gateway module given")
+	}
+	v2.Handle(APIv2Route{
+		Method: http.MethodGet, Path: "/gateway", Tag: "gateway",
+		Summary:  "get the address and peers of the gateway",
+		Response: GatewayGET{},
+		Handler:  NewGatewayRootHandler(gateway),
+	})
+	v2.Handle(APIv2Route{
+		Method: http.MethodPost, Path: "/gateway/connect/:netaddress", Tag: "gateway",
+		Summary:       "connect to a peer",
+		Authenticated: true,
+		Handler:       NewGatewayConnectHandler(gateway),
+	})
+	v2.Handle(APIv2Route{
+		Method: http.MethodPost, Path: "/gateway/disconnect/:netaddress", Tag: "gateway",
+		Summary:       "disconnect from a peer",
+		Authenticated: true,
+		Handler:       NewGatewayDisconnectHandler(gateway),
+	})
+}
+
 // NewGatewayRootHandler creates a handler to handle the API call asking for the gatway status.
 func NewGatewayRootHandler(gateway modules.Gateway) httprouter.Handle {
 	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
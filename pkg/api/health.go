@@ -0,0 +1,215 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// DefaultHealthMaxBlocksBehind is the amount of blocks the consensus set can be
+// behind the (estimated) network height, while still being reported as synced
+// by the health endpoints, unless the maxblocks query parameter is given.
+const DefaultHealthMaxBlocksBehind = 10
+
+// The health statuses reported by the health endpoints.
+const (
+	HealthStatusOK          = "ok"
+	HealthStatusUnavailable = "unavailable"
+)
+
+type (
+	// HealthGET contains the fields returned by a GET call to "/health".
+	HealthGET struct {
+		Status  string         `json:"status"`
+		Live    bool           `json:"live"`
+		Ready   bool           `json:"ready"`
+		Synced  bool           `json:"synced"`
+		Modules []ModuleHealth `json:"modules"`
+		// Consensus is omitted if the consensus set is not loaded.
+		Consensus *ConsensusHealth `json:"consensus,omitempty"`
+	}
+
+	// HealthReadyGET contains the fields returned by a GET call to "/health/ready".
+	HealthReadyGET struct {
+		Status  string         `json:"status"`
+		Modules []ModuleHealth `json:"modules"`
+	}
+
+	// HealthSyncedGET contains the fields returned by a GET call to "/health/synced".
+	HealthSyncedGET struct {
+		Status string `json:"status"`
+		// Consensus is omitted if the consensus set is not loaded.
+		Consensus *ConsensusHealth `json:"consensus,omitempty"`
+	}
+
+	// ModuleHealth reports whether a module of the daemon is loaded.
+	ModuleHealth struct {
+		Name   string `json:"name"`
+		Loaded bool   `json:"loaded"`
+	}
+
+	// ConsensusHealth reports how far the consensus set is behind the network.
+	ConsensusHealth struct {
+		// Synced reports whether the consensus set considers itself synced.
+		Synced                 bool              `json:"synced"`
+		Height                 types.BlockHeight `json:"height"`
+		EstimatedNetworkHeight types.BlockHeight `json:"estimatednetworkheight"`
+		BlocksBehind           types.BlockHeight `json:"blocksbehind"`
+		MaxBlocksBehind        types.BlockHeight `json:"maxblocksbehind"`
+	}
+)
+
+// HealthTracker tracks the state of the daemon reported by the health endpoints,
+// updated by the daemon while it loads its modules.
+type HealthTracker struct {
+	mu      sync.RWMutex
+	modules []ModuleHealth
+	cs      modules.ConsensusSet
+}
+
+// NewHealthTracker creates a new health tracker for a daemon loading the given modules.
+func NewHealthTracker(moduleNames ...string) *HealthTracker {
+	ht := &HealthTracker{}
+	for _, name := range moduleNames {
+		ht.modules = append(ht.modules, ModuleHealth{Name: name})
+	}
+	return ht
+}
+
+// SetModuleLoaded marks the module with the given name as loaded.
+func (ht *HealthTracker) SetModuleLoaded(name string) {
+	ht.mu.Lock()
+	defer ht.mu.Unlock()
+	for i := range ht.modules {
+		if ht.modules[i].Name == name {
+			ht.modules[i].Loaded = true
+		}
+	}
+}
+
+// SetConsensusSet sets the (loaded) consensus set, used to report the sync state.
+func (ht *HealthTracker) SetConsensusSet(cs modules.ConsensusSet) {
+	ht.mu.Lock()
+	ht.cs = cs
+	ht.mu.Unlock()
+}
+
+// Ready returns whether all modules are loaded, and the state of each module.
+func (ht *HealthTracker) Ready() (bool, []ModuleHealth) {
+	ht.mu.RLock()
+	defer ht.mu.RUnlock()
+	ready := true
+	mods := make([]ModuleHealth, len(ht.modules))
+	for i, mod := range ht.modules {
+		mods[i] = mod
+		ready = ready && mod.Loaded
+	}
+	return ready, mods
+}
+
+// Synced returns whether the consensus set is synced within the given amount of blocks
+// of the network height, and its sync state, nil if the consensus set is not loaded.
+func (ht *HealthTracker) Synced(maxBlocksBehind types.BlockHeight) (bool, *ConsensusHealth) {
+	ht.mu.RLock()
+	cs := ht.cs
+	ht.mu.RUnlock()
+	if cs == nil {
+		return false, nil
+	}
+	sp := cs.SyncProgress()
+	health := &ConsensusHealth{
+		Synced:                 sp.Synced,
+		Height:                 sp.Height,
+		EstimatedNetworkHeight: sp.EstimatedNetworkHeight,
+		MaxBlocksBehind:        maxBlocksBehind,
+	}
+	if sp.EstimatedNetworkHeight > sp.Height {
+		health.BlocksBehind = sp.EstimatedNetworkHeight - sp.Height
+	}
+	return sp.Synced && health.BlocksBehind <= maxBlocksBehind, health
+}
+
+// NewHealthHandler creates a handler for the health endpoints,
+// which require neither authentication nor a user agent, such that they can be used
+// by (container orchestrator) probes and load balancers:
+//   - /health/live reports the daemon process is up;
+//   - /health/ready reports all modules are loaded;
+//   - /health/synced reports the consensus set is synced within maxblocks of the network height;
+//   - /health reports all of the above.
+//
+// The status code is 503 (Service Unavailable) if the checked condition is not met.
+func NewHealthHandler(ht *HealthTracker) http.Handler {
+	router := httprouter.New()
+	router.GET("/health", func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		maxBlocksBehind, ok := parseHealthMaxBlocks(w, req)
+		if !ok {
+			return
+		}
+		ready, mods := ht.Ready()
+		synced, consensus := ht.Synced(maxBlocksBehind)
+		writeHealth(w, ready && synced, func(status string) interface{} {
+			return HealthGET{
+				Status:    status,
+				Live:      true,
+				Ready:     ready,
+				Synced:    synced,
+				Modules:   mods,
+				Consensus: consensus,
+			}
+		})
+	})
+	router.GET("/health/live", func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		WriteJSON(w, struct {
+			Status string `json:"status"`
+		}{HealthStatusOK})
+	})
+	router.GET("/health/ready", func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		ready, mods := ht.Ready()
+		writeHealth(w, ready, func(status string) interface{} {
+			return HealthReadyGET{Status: status, Modules: mods}
+		})
+	})
+	router.GET("/health/synced", func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		maxBlocksBehind, ok := parseHealthMaxBlocks(w, req)
+		if !ok {
+			return
+		}
+		synced, consensus := ht.Synced(maxBlocksBehind)
+		writeHealth(w, synced, func(status string) interface{} {
+			return HealthSyncedGET{Status: status, Consensus: consensus}
+		})
+	})
+	return router
+}
+
+// parseHealthMaxBlocks parses the optional maxblocks query parameter,
+// writing an error response if it is invalid.
+func parseHealthMaxBlocks(w http.ResponseWriter, req *http.Request) (types.BlockHeight, bool) {
+	str := req.FormValue("maxblocks")
+	if str == "" {
+		return DefaultHealthMaxBlocksBehind, true
+	}
+	maxBlocks, err := strconv.ParseUint(str, 10, 64)
+	if err != nil {
+		WriteError(w, Error{"invalid maxblocks: " + err.Error()}, http.StatusBadRequest)
+		return 0, false
+	}
+	return types.BlockHeight(maxBlocks), true
+}
+
+// writeHealth writes the health response created for the status matching the given condition,
+// using status code 503 if the condition is not met.
+func writeHealth(w http.ResponseWriter, healthy bool, response func(status string) interface{}) {
+	if healthy {
+		WriteJSON(w, response(HealthStatusOK))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusServiceUnavailable)
+	json.NewEncoder(w).Encode(response(HealthStatusUnavailable)) // ignore error, just like WriteError
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/threefoldtech/rivine/modules"
+)
+
+// syncProgressConsensusSet is a consensus set only implementing SyncProgress.
+type syncProgressConsensusSet struct {
+	modules.ConsensusSet
+	progress modules.SyncProgress
+}
+
+func (cs *syncProgressConsensusSet) SyncProgress() modules.SyncProgress { return cs.progress }
+
+func TestHealthHandler(t *testing.T) {
+	ht := NewHealthTracker("gateway", "consensus")
+	handler := NewHealthHandler(ht)
+	get := func(path string) (int, HealthGET) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		var health HealthGET
+		if rec.Code != http.StatusBadRequest {
+			if err := json.Unmarshal(rec.Body.Bytes(), &health); err != nil {
+				t.Fatalf("%s: %v", path, err)
+			}
+		}
+		return rec.Code, health
+	}
+
+	// the daemon is live, but not ready while loading its modules
+	if code, health := get("/health/live"); code != http.StatusOK || health.Status != HealthStatusOK {
+		t.Errorf("unexpected liveness: %d %v", code, health)
+	}
+	ht.SetModuleLoaded("gateway")
+	if code, health := get("/health/ready"); code != http.StatusServiceUnavailable ||
+		len(health.Modules) != 2 || !health.Modules[0].Loaded || health.Modules[1].Loaded {
+		t.Errorf("unexpected readiness: %d %v", code, health)
+	}
+	if code, _ := get("/health/synced"); code != http.StatusServiceUnavailable {
+		t.Errorf("expected the daemon not to be synced without consensus set, got %d", code)
+	}
+
+	cs := &syncProgressConsensusSet{progress: modules.SyncProgress{Synced: true, Height: 95, EstimatedNetworkHeight: 100}}
+	ht.SetConsensusSet(cs)
+	ht.SetModuleLoaded("consensus")
+	if code, health := get("/health"); code != http.StatusOK || !health.Ready || !health.Synced ||
+		health.Consensus == nil || health.Consensus.BlocksBehind != 5 {
+		t.Errorf("unexpected health: %d %v", code, health)
+	}
+	if code, _ := get("/health/synced?maxblocks=4"); code != http.StatusServiceUnavailable {
+		t.Errorf("expected the daemon not to be synced within 4 blocks, got %d", code)
+	}
+	if code, _ := get("/health/synced?maxblocks=five"); code != http.StatusBadRequest {
+		t.Errorf("expected an invalid maxblocks to be refused, got %d", code)
+	}
+	cs.progress.Synced = false
+	if code, health := get("/health"); code != http.StatusServiceUnavailable || health.Synced || health.Status != HealthStatusUnavailable {
+		t.Errorf("unexpected health while syncing: %d %v", code, health)
+	}
+}
@@ -1,7 +1,9 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"io"
 	"net/http"
 	"strings"
 
@@ -13,11 +15,10 @@ import (
 // HTTPGet is a utility function for making http get requests to sia with a
 // whitelisted user-agent. A non-2xx response does not return an error.
 func HTTPGet(url, userAgent string) (resp *http.Response, err error) {
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := newRequest(http.MethodGet, url, "", userAgent, "")
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("User-Agent", userAgent)
 	return http.DefaultClient.Do(req)
 }
 
@@ -25,29 +26,20 @@ func HTTPGet(url, userAgent string) (resp *http.Response, err error) {
 // requests to sia with a whitelisted user-agent and the supplied password. A
 // non-2xx response does not return an error.
 func HTTPGETAuthenticated(url, userAgent, password string) (resp *http.Response, err error) {
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := newRequest(http.MethodGet, url, "", userAgent, password)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("User-Agent", userAgent)
-	req.SetBasicAuth("", password)
 	return http.DefaultClient.Do(req)
 }
 
 // HTTPPost is a utility function for making post requests to sia with a
 // whitelisted user-agent. A non-2xx response does not return an error.
 func HTTPPost(url, data, userAgent string) (resp *http.Response, err error) {
-	var req *http.Request
-	if data != "" {
-		req, err = http.NewRequest("POST", url, strings.NewReader(data))
-	} else {
-		req, err = http.NewRequest("POST", url, nil)
-	}
+	req, err := newRequest(http.MethodPost, url, data, userAgent, "")
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("User-Agent", userAgent)
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	return http.DefaultClient.Do(req)
 }
 
@@ -55,23 +47,43 @@ func HTTPPost(url, data, userAgent string) (resp *http.Response, err error) {
 // post requests to sia with a whitelisted user-agent and the supplied
 // password. A non-2xx response does not return an error.
 func HTTPPostAuthenticated(url, data, userAgent, password string) (resp *http.Response, err error) {
-	req, err := http.NewRequest("POST", url, strings.NewReader(data))
+	req, err := newRequest(http.MethodPost, url, data, userAgent, password)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("User-Agent", userAgent)
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	req.SetBasicAuth("", password)
 	return http.DefaultClient.Do(req)
 }
 
+// newRequest creates a request with a whitelisted user-agent,
+// posting the given (form-encoded) data if it is a POST request,
+// and authenticated using the given password if it is not empty.
+func newRequest(method, url, data, userAgent, password string) (*http.Request, error) {
+	var body io.Reader
+	if data != "" {
+		body = strings.NewReader(data)
+	}
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	if method == http.MethodPost {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	if password != "" {
+		req.SetBasicAuth("", password)
+	}
+	return req, nil
+}
+
 // server middleware: handler->handler
 
 // RequireUserAgentHandler is middleware that requires all requests to set a
-// UserAgent that contains the specified string.
+// UserAgent that contains the specified string. Cross-origin requests of an origin
+// explicitly allowed by the CORSHandler are accepted as well.
 func RequireUserAgentHandler(h http.Handler, userAgent string) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
-		if !strings.Contains(req.UserAgent(), userAgent) {
+		if !strings.Contains(req.UserAgent(), userAgent) && !isAllowedCORSOrigin(req) {
 			WriteError(w, Error{"Browser access disabled due to security vulnerability. Use an official client."}, http.StatusBadRequest)
 			return
 		}
@@ -79,15 +91,40 @@ func RequireUserAgentHandler(h http.Handler, userAgent string) http.Handler {
 	})
 }
 
+// unixSocketContextKey marks a request received over a unix domain socket, in its context.
+type unixSocketContextKey struct{}
+
+// UnixSocketHandler is middleware that marks all requests as received over a unix domain socket,
+// of which the file permissions authenticate the callers, see IsUnixSocketRequest.
+func UnixSocketHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		h.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), unixSocketContextKey{}, true)))
+	})
+}
+
+// IsUnixSocketRequest returns true if the given request is received over a unix domain socket,
+// as marked by the UnixSocketHandler.
+func IsUnixSocketRequest(req *http.Request) bool {
+	ok, _ := req.Context().Value(unixSocketContextKey{}).(bool)
+	return ok
+}
+
 // RequirePasswordHandler is middleware that requires a request to authenticate with a
 // password using HTTP basic auth. Usernames are ignored. Empty passwords
-// indicate no authentication is required.
+// indicate no authentication is required. Requests authenticated using an API token,
+// see RequireAPITokenScopeHandler, are accepted as well, as are requests received over
+// a unix domain socket, see UnixSocketHandler, as only the callers allowed by the
+// file permissions of the socket can connect to it.
 func RequirePasswordHandler(h httprouter.Handle, password string) httprouter.Handle {
 	// An empty password is equivalent to no password.
 	if password == "" {
 		return h
 	}
 	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		if _, ok := APITokenFromRequest(req); ok || IsUnixSocketRequest(req) {
+			h(w, req, ps)
+			return
+		}
 		_, pass, ok := req.BasicAuth()
 		if !ok || pass != password {
 			w.Header().Set("WWW-Authenticate", "Basic realm=\"SiaAPI\"")
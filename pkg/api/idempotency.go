@@ -0,0 +1,282 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/persist"
+	"github.com/threefoldtech/rivine/types"
+)
+
+const (
+	// APIIdempotencyFile is the name of the file, stored in the root persistent directory of the daemon,
+	// in which the responses of the API calls made using an idempotency key are persisted.
+	APIIdempotencyFile = "apiidempotency.json"
+
+	// IdempotencyKeyHeader is the header of a request defining its idempotency key,
+	// a unique value chosen by the client, e.g. a UUID or the ID of a withdrawal.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set to "true" in a response
+	// replaying the response of an earlier request with the same idempotency key.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+const (
+	// idempotencyKeyMaxLength is the maximum length of an idempotency key.
+	idempotencyKeyMaxLength = 255
+	// idempotencyMaxEntries is the maximum amount of responses remembered,
+	// the oldest ones are forgotten first.
+	idempotencyMaxEntries = 10000
+)
+
+var (
+	errIdempotencyKeyInProgress = errors.New("a request with the same idempotency key is in progress")
+	errIdempotencyKeyReused     = errors.New("the idempotency key is used already by a different request")
+)
+
+// IsIdempotentAPICall returns true if the API call of the given method and path
+// can be made using an idempotency key, such that it is executed only once,
+// being the calls sending funds using the wallet and publishing transactions.
+func IsIdempotentAPICall(method, path string) bool {
+	if method != http.MethodPost {
+		return false
+	}
+	path = strings.TrimSuffix(path, "/")
+	// the calls of version 2 of the API are the same calls
+	path = strings.TrimPrefix(path, APIv2Prefix)
+	switch path {
+	case "/wallet/coins", "/wallet/blockstakes", "/wallet/data", "/wallet/transaction",
+		"/transactionpool/transactions", "/transactionpool/transactionset":
+		return true
+	default:
+		return false
+	}
+}
+
+// apiIdempotencyEntry is the response of an API call made using an idempotency key.
+type apiIdempotencyEntry struct {
+	// Fingerprint identifies the request, such that a key cannot be reused for another request.
+	Fingerprint crypto.Hash     `json:"fingerprint"`
+	Status      int             `json:"status"`
+	ContentType string          `json:"contenttype,omitempty"`
+	Body        []byte          `json:"body,omitempty"`
+	Created     types.Timestamp `json:"created"`
+	// pending is true as long as the call is being executed
+	pending bool
+}
+
+// apiIdempotencyMetadata contains the header and version strings that identify the idempotency file.
+var apiIdempotencyMetadata = persist.Metadata{
+	Header:  "API Idempotency",
+	Version: "1.0.0",
+}
+
+// APIIdempotencyCache remembers the successful responses of the API calls made using
+// an idempotency key, for a limited duration, persisting them to disk, such that a call
+// retried by a client (e.g. after a timeout) is not executed twice, see APIIdempotencyHandler.
+type APIIdempotencyCache struct {
+	filename string
+	ttl      time.Duration
+	entries  map[string]*apiIdempotencyEntry
+	mu       sync.Mutex
+}
+
+// NewAPIIdempotencyCache creates an APIIdempotencyCache, remembering responses for the given duration,
+// persisted to the given file, loading the responses of that file if it exists.
+func NewAPIIdempotencyCache(filename string, ttl time.Duration) (*APIIdempotencyCache, error) {
+	if ttl <= 0 {
+		return nil, fmt.Errorf("invalid idempotency key duration %v: has to be positive", ttl)
+	}
+	cache := &APIIdempotencyCache{
+		filename: filename,
+		ttl:      ttl,
+		entries:  make(map[string]*apiIdempotencyEntry),
+	}
+	err := persist.LoadJSON(apiIdempotencyMetadata, &cache.entries, filename)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to load API idempotency keys: %v", err)
+	}
+	cache.prune()
+	return cache, nil
+}
+
+// begin returns the response remembered for the given ID, if any,
+// reserving the ID for the call of the given fingerprint otherwise.
+func (cache *APIIdempotencyCache) begin(id string, fingerprint crypto.Hash) (*apiIdempotencyEntry, error) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	cache.prune()
+	if entry, ok := cache.entries[id]; ok {
+		if entry.Fingerprint != fingerprint {
+			return nil, errIdempotencyKeyReused
+		}
+		if entry.pending {
+			return nil, errIdempotencyKeyInProgress
+		}
+		e := *entry
+		return &e, nil
+	}
+	cache.entries[id] = &apiIdempotencyEntry{
+		Fingerprint: fingerprint,
+		Created:     types.CurrentTimestamp(),
+		pending:     true,
+	}
+	return nil, nil
+}
+
+// finish remembers the response of the call reserving the given ID,
+// or releases the ID if the response is nil, such that the call can be retried.
+func (cache *APIIdempotencyCache) finish(id string, entry *apiIdempotencyEntry) error {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if entry == nil {
+		delete(cache.entries, id)
+		return nil
+	}
+	cache.entries[id] = entry
+	return cache.save()
+}
+
+// prune forgets the expired responses, as well as the oldest responses exceeding the maximum,
+// it should be called while holding the lock.
+func (cache *APIIdempotencyCache) prune() {
+	expiry := types.CurrentTimestamp() - types.Timestamp(cache.ttl.Seconds())
+	var oldestID string
+	var oldest types.Timestamp
+	for id, entry := range cache.entries {
+		if entry.pending {
+			continue
+		}
+		if entry.Created < expiry {
+			delete(cache.entries, id)
+			continue
+		}
+		if oldestID == "" || entry.Created < oldest {
+			oldestID, oldest = id, entry.Created
+		}
+	}
+	if len(cache.entries) >= idempotencyMaxEntries && oldestID != "" {
+		delete(cache.entries, oldestID)
+	}
+}
+
+// save persists the responses which are not pending, it should be called while holding the lock.
+func (cache *APIIdempotencyCache) save() error {
+	entries := make(map[string]*apiIdempotencyEntry, len(cache.entries))
+	for id, entry := range cache.entries {
+		if !entry.pending {
+			entries[id] = entry
+		}
+	}
+	err := os.MkdirAll(filepath.Dir(cache.filename), 0700)
+	if err != nil {
+		return err
+	}
+	return persist.SaveJSON(apiIdempotencyMetadata, entries, cache.filename)
+}
+
+// apiIdempotencyID returns the ID under which the response to a call using the given key is remembered,
+// scoped to the credentials of the request, such that only the same client can retrieve it.
+func apiIdempotencyID(req *http.Request, key string) string {
+	return crypto.HashAll(req.Header.Get("Authorization"), IsUnixSocketRequest(req), key).String()
+}
+
+// APIIdempotencyHandler is middleware that executes the API calls made using an idempotency key,
+// see IsIdempotentAPICall and IdempotencyKeyHeader, only once. A retried call gets the response
+// of the first call replayed, marked using the IdempotentReplayedHeader, rather than executing it again.
+// Only successful responses are remembered, as failed calls did not send or publish anything, and can be retried.
+// A call made while a call with the same key is in progress is refused with 409 (Conflict),
+// and a key reused for a different call is refused with 422 (Unprocessable Entity).
+func APIIdempotencyHandler(h http.Handler, cache *APIIdempotencyCache) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		key := req.Header.Get(IdempotencyKeyHeader)
+		if key == "" || !IsIdempotentAPICall(req.Method, req.URL.Path) {
+			h.ServeHTTP(w, req)
+			return
+		}
+		if len(key) > idempotencyKeyMaxLength {
+			WriteError(w, Error{fmt.Sprintf("invalid idempotency key: cannot be longer than %d characters", idempotencyKeyMaxLength)},
+				http.StatusBadRequest)
+			return
+		}
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			WriteError(w, Error{"failed to read request body: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		id := apiIdempotencyID(req, key)
+		fingerprint := crypto.HashAll(req.Method, req.URL.Path, req.URL.RawQuery, body)
+		entry, err := cache.begin(id, fingerprint)
+		switch {
+		case err == errIdempotencyKeyInProgress:
+			WriteError(w, Error{err.Error()}, http.StatusConflict)
+			return
+		case err != nil:
+			WriteError(w, Error{err.Error()}, http.StatusUnprocessableEntity)
+			return
+		case entry != nil:
+			if entry.ContentType != "" {
+				w.Header().Set("Content-Type", entry.ContentType)
+			}
+			w.Header().Set(IdempotentReplayedHeader, "true")
+			w.WriteHeader(entry.Status)
+			w.Write(entry.Body)
+			return
+		}
+
+		rw := &apiIdempotencyResponseWriter{ResponseWriter: w}
+		defer func() {
+			// release the key should the call fail or panic, such that it can be retried
+			if rw.status < 200 || rw.status > 299 {
+				cache.finish(id, nil)
+				return
+			}
+			err := cache.finish(id, &apiIdempotencyEntry{
+				Fingerprint: fingerprint,
+				Status:      rw.status,
+				ContentType: rw.Header().Get("Content-Type"),
+				Body:        rw.body.Bytes(),
+				Created:     types.CurrentTimestamp(),
+			})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "failed to persist the response of %s %s for its idempotency key: %v\n", req.Method, req.URL.Path, err)
+			}
+		}()
+		h.ServeHTTP(rw, req)
+	})
+}
+
+// apiIdempotencyResponseWriter is a response writer which remembers the status code and body of the response.
+type apiIdempotencyResponseWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+// WriteHeader implements http.ResponseWriter.WriteHeader
+func (rw *apiIdempotencyResponseWriter) WriteHeader(status int) {
+	if rw.status == 0 {
+		rw.status = status
+	}
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+// Write implements http.ResponseWriter.Write
+func (rw *apiIdempotencyResponseWriter) Write(b []byte) (int, error) {
+	if rw.status == 0 {
+		rw.status = http.StatusOK
+	}
+	rw.body.Write(b)
+	return rw.ResponseWriter.Write(b)
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAPIIdempotencyHandler(t *testing.T) {
+	dir, err := ioutil.TempDir("", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, APIIdempotencyFile)
+	cache, err := NewAPIIdempotencyCache(filename, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sends uint32
+	var fail bool
+	h := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if fail {
+			WriteError(w, Error{"insufficient funds"}, http.StatusPaymentRequired)
+			return
+		}
+		n := atomic.AddUint32(&sends, 1)
+		b, _ := ioutil.ReadAll(req.Body)
+		WriteJSON(w, map[string]interface{}{"send": n, "body": string(b)})
+	})
+	call := func(cache *APIIdempotencyCache, path, key, password, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+		if password != "" {
+			req.SetBasicAuth("", password)
+		}
+		rec := httptest.NewRecorder()
+		APIIdempotencyHandler(h, cache).ServeHTTP(rec, req)
+		return rec
+	}
+
+	// a retried call is executed only once, replaying its response
+	first := call(cache, "/wallet/coins", "withdrawal-1", "foo", `{"amount":"1"}`)
+	retry := call(cache, "/wallet/coins", "withdrawal-1", "foo", `{"amount":"1"}`)
+	if first.Code != http.StatusOK || retry.Code != http.StatusOK || sends != 1 {
+		t.Fatalf("expected a single send, got %d sends: %d, %d", sends, first.Code, retry.Code)
+	}
+	if retry.Body.String() != first.Body.String() || retry.Header().Get(IdempotentReplayedHeader) != "true" ||
+		retry.Header().Get("Content-Type") != first.Header().Get("Content-Type") {
+		t.Fatalf("expected the response to be replayed: %q != %q", retry.Body.String(), first.Body.String())
+	}
+	// the responses survive a restart
+	restarted, err := NewAPIIdempotencyCache(filename, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec := call(restarted, "/wallet/coins", "withdrawal-1", "foo", `{"amount":"1"}`); rec.Code != http.StatusOK || sends != 1 {
+		t.Fatalf("expected the persisted response to be replayed: %d sends", sends)
+	}
+
+	// a key is scoped to the credentials and call, and is not used for other calls
+	if rec := call(cache, "/wallet/coins", "withdrawal-1", "bar", `{"amount":"1"}`); rec.Code != http.StatusOK || sends != 2 {
+		t.Fatalf("expected the key of other credentials to be separate: %d sends", sends)
+	}
+	if rec := call(cache, "/wallet/coins", "withdrawal-1", "foo", `{"amount":"2"}`); rec.Code != http.StatusUnprocessableEntity || sends != 2 {
+		t.Fatalf("expected a reused key to be refused: %d", rec.Code)
+	}
+	call(cache, "/wallet/coins", "", "foo", `{"amount":"1"}`)
+	call(cache, "/wallet/unlock", "withdrawal-1", "foo", `{"amount":"1"}`)
+	call(cache, "/wallet/unlock", "withdrawal-1", "foo", `{"amount":"1"}`)
+	if sends != 5 {
+		t.Fatalf("expected the calls without key or of other paths to be executed: %d sends", sends)
+	}
+
+	// failed calls are not remembered, such that they can be retried
+	fail = true
+	if rec := call(cache, "/wallet/coins", "withdrawal-2", "foo", `{"amount":"3"}`); rec.Code != http.StatusPaymentRequired {
+		t.Fatalf("expected the call to fail: %d", rec.Code)
+	}
+	fail = false
+	if rec := call(cache, "/wallet/coins", "withdrawal-2", "foo", `{"amount":"3"}`); rec.Code != http.StatusOK || sends != 6 {
+		t.Fatalf("expected the failed call to be retried: %d sends", sends)
+	}
+
+	// calls in progress are not executed twice
+	if entry, err := cache.begin("pending", [32]byte{1}); entry != nil || err != nil {
+		t.Fatal("expected the ID to be reserved", err)
+	}
+	if _, err = cache.begin("pending", [32]byte{1}); err != errIdempotencyKeyInProgress {
+		t.Fatalf("expected a call in progress to be refused: %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/threefoldtech/rivine/persist"
+)
+
+type (
+	// DaemonLogGET contains the log settings returned by a GET call to /daemon/log.
+	DaemonLogGET struct {
+		Format persist.LogFormat `json:"format"`
+		// DefaultLevel is the level of all modules without a level of their own.
+		DefaultLevel persist.LogLevel `json:"defaultlevel"`
+		// Modules contains the level of every module with an open log.
+		Modules map[string]persist.LogLevel `json:"modules"`
+	}
+
+	// DaemonLogPOST contains the body of a POST call to /daemon/log,
+	// setting the log level of a single module, or the default level if the module is empty.
+	DaemonLogPOST struct {
+		Module string           `json:"module,omitempty"`
+		Level  persist.LogLevel `json:"level"`
+	}
+)
+
+// RegisterLogHTTPHandlers registers the handlers for the API calls to inspect and change
+// the log levels of the daemon at runtime.
+func RegisterLogHTTPHandlers(router Router, requiredPassword string) {
+	if router == nil {
+		panic("no httprouter Router given")
+	}
+	router.GET("/daemon/log", RequirePasswordHandler(NewDaemonLogGetHandler(), requiredPassword))
+	router.POST("/daemon/log", RequirePasswordHandler(NewDaemonLogPostHandler(), requiredPassword))
+}
+
+// RegisterLogAPIv2Handlers registers the calls of version 2 of the API
+// to inspect and change the log levels of the daemon at runtime.
+func RegisterLogAPIv2Handlers(v2 *APIv2) {
+	v2.Handle(APIv2Route{
+		Method: http.MethodGet, Path: "/daemon/log", Tag: "daemon",
+		Summary:       "get the log format and levels",
+		Response:      DaemonLogGET{},
+		Authenticated: true,
+		Handler:       NewDaemonLogGetHandler(),
+	})
+	v2.Handle(APIv2Route{
+		Method: http.MethodPost, Path: "/daemon/log", Tag: "daemon",
+		Summary:       "set the log level of a module, or the default log level",
+		Request:       DaemonLogPOST{},
+		Authenticated: true,
+		Handler:       NewDaemonLogPostHandler(),
+	})
+}
+
+// NewDaemonLogGetHandler creates a handler to handle the API call to get the log settings of the daemon.
+func NewDaemonLogGetHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		WriteJSON(w, DaemonLogGET{
+			Format:       persist.CurrentLogFormat(),
+			DefaultLevel: persist.DefaultLogLevel(),
+			Modules:      persist.LogLevels(),
+		})
+	}
+}
+
+// NewDaemonLogPostHandler creates a handler to handle the API call to set the log level of a module.
+// The level applies to the open logs of the module immediately.
+func NewDaemonLogPostHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		var body DaemonLogPOST
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			WriteError(w, Error{"error decoding the supplied log level: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		if err := persist.SetLogLevel(body.Module, body.Level); err != nil {
+			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+			return
+		}
+		WriteSuccess(w)
+	}
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/threefoldtech/rivine/persist"
+)
+
+func TestDaemonLogHandlers(t *testing.T) {
+	defer persist.SetLogLevel("", persist.DefaultLogLevel())
+	router := httprouter.New()
+	RegisterLogHTTPHandlers(router, "")
+
+	for _, tc := range []struct {
+		Body       string
+		StatusCode int
+	}{
+		{`{"level":"warn"}`, http.StatusNoContent},
+		{`{"module":"test-api","level":"debug"}`, http.StatusNoContent},
+		{`{"level":"verbose"}`, http.StatusBadRequest},
+		{`{}`, http.StatusBadRequest},
+	} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/daemon/log", strings.NewReader(tc.Body)))
+		if rec.Code != tc.StatusCode {
+			t.Errorf("%s: expected status %d, got %d: %s", tc.Body, tc.StatusCode, rec.Code, rec.Body.String())
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/daemon/log", nil))
+	var resp DaemonLogGET
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Format != persist.LogFormatText || resp.DefaultLevel != persist.LogLevelWarn ||
+		resp.Modules["test-api"] != persist.LogLevelDebug {
+		t.Fatalf("unexpected log settings: %+v", resp)
+	}
+}
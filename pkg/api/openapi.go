@@ -0,0 +1,283 @@
+package api
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"path"
+	"reflect"
+	"strings"
+)
+
+// openAPIVersion is the version of the OpenAPI specification the generated documents conform to.
+const openAPIVersion = "3.0.3"
+
+type (
+	// OpenAPIDocument is an OpenAPI document, describing (a version of) the API,
+	// such that clients for other languages can be generated from it.
+	OpenAPIDocument struct {
+		OpenAPI    string                                  `json:"openapi"`
+		Info       OpenAPIInfo                             `json:"info"`
+		Paths      map[string]map[string]*OpenAPIOperation `json:"paths"`
+		Components OpenAPIComponents                       `json:"components"`
+	}
+
+	// OpenAPIInfo describes the API documented by an OpenAPI document.
+	OpenAPIInfo struct {
+		Title       string `json:"title"`
+		Description string `json:"description,omitempty"`
+		Version     string `json:"version"`
+	}
+
+	// OpenAPIOperation describes a single API call.
+	OpenAPIOperation struct {
+		OperationID string                     `json:"operationId"`
+		Summary     string                     `json:"summary,omitempty"`
+		Tags        []string                   `json:"tags,omitempty"`
+		Parameters  []OpenAPIParameter         `json:"parameters,omitempty"`
+		RequestBody *OpenAPIRequestBody        `json:"requestBody,omitempty"`
+		Responses   map[string]OpenAPIResponse `json:"responses"`
+		Security    []map[string][]string      `json:"security,omitempty"`
+	}
+
+	// OpenAPIParameter describes a path or query parameter of an API call.
+	OpenAPIParameter struct {
+		Name        string         `json:"name"`
+		In          string         `json:"in"`
+		Description string         `json:"description,omitempty"`
+		Required    bool           `json:"required,omitempty"`
+		Schema      *OpenAPISchema `json:"schema"`
+	}
+
+	// OpenAPIRequestBody describes the body of an API call.
+	OpenAPIRequestBody struct {
+		Required bool                        `json:"required"`
+		Content  map[string]OpenAPIMediaType `json:"content"`
+	}
+
+	// OpenAPIResponse describes a response of an API call.
+	OpenAPIResponse struct {
+		Description string                      `json:"description"`
+		Content     map[string]OpenAPIMediaType `json:"content,omitempty"`
+	}
+
+	// OpenAPIMediaType describes the content of a body of a given media type.
+	OpenAPIMediaType struct {
+		Schema *OpenAPISchema `json:"schema"`
+	}
+
+	// OpenAPISchema describes a JSON value, either inline or as a reference to a component schema.
+	OpenAPISchema struct {
+		Ref                  string                    `json:"$ref,omitempty"`
+		Type                 string                    `json:"type,omitempty"`
+		Format               string                    `json:"format,omitempty"`
+		Description          string                    `json:"description,omitempty"`
+		Properties           map[string]*OpenAPISchema `json:"properties,omitempty"`
+		Items                *OpenAPISchema            `json:"items,omitempty"`
+		AdditionalProperties *OpenAPISchema            `json:"additionalProperties,omitempty"`
+		Enum                 []string                  `json:"enum,omitempty"`
+	}
+
+	// OpenAPIComponents contains the reusable schemas and security schemes of an OpenAPI document.
+	OpenAPIComponents struct {
+		Schemas         map[string]*OpenAPISchema        `json:"schemas"`
+		SecuritySchemes map[string]OpenAPISecurityScheme `json:"securitySchemes,omitempty"`
+	}
+
+	// OpenAPISecurityScheme describes how API calls are authenticated.
+	OpenAPISecurityScheme struct {
+		Type        string `json:"type"`
+		Scheme      string `json:"scheme,omitempty"`
+		Description string `json:"description,omitempty"`
+	}
+)
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// openAPISchemas generates the schemas of Go types, as they are encoded by encoding/json,
+// collecting the schemas of named struct types as components, such that they can be referenced.
+type openAPISchemas struct {
+	components map[string]*OpenAPISchema
+	names      map[reflect.Type]string
+}
+
+// newOpenAPISchemas creates an empty schema generator.
+func newOpenAPISchemas() *openAPISchemas {
+	return &openAPISchemas{
+		components: make(map[string]*OpenAPISchema),
+		names:      make(map[reflect.Type]string),
+	}
+}
+
+// schemaOf returns the schema of the given Go type.
+func (s *openAPISchemas) schemaOf(t reflect.Type) *OpenAPISchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	// types with a custom encoding are described using the encoding of their zero value
+	if t.Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(jsonMarshalerType) {
+		return marshaledSchemaOf(t)
+	}
+	if t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType) {
+		return &OpenAPISchema{Type: "string", Description: goTypeName(t)}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &OpenAPISchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &OpenAPISchema{Type: "integer", Format: integerFormat(t)}
+	case reflect.Float32, reflect.Float64:
+		return &OpenAPISchema{Type: "number"}
+	case reflect.String:
+		return &OpenAPISchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 && t.Kind() == reflect.Slice {
+			return &OpenAPISchema{Type: "string", Format: "byte"}
+		}
+		return &OpenAPISchema{Type: "array", Items: s.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &OpenAPISchema{Type: "object", AdditionalProperties: s.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.structSchema(t)
+		}
+		return &OpenAPISchema{Ref: "#/components/schemas/" + s.component(t)}
+	default:
+		// e.g. interface{}, any JSON value
+		return &OpenAPISchema{}
+	}
+}
+
+// component returns the name of the component schema of the given named struct type,
+// generating the schema if it is not generated yet.
+func (s *openAPISchemas) component(t reflect.Type) string {
+	if name, ok := s.names[t]; ok {
+		return name
+	}
+	name := t.Name()
+	if _, ok := s.components[name]; ok {
+		// a type with the same name from another package
+		pkg := path.Base(t.PkgPath())
+		name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+	}
+	s.names[t] = name
+	s.components[name] = &OpenAPISchema{} // placeholder for recursive types
+	*s.components[name] = *s.structSchema(t)
+	return name
+}
+
+// structSchema returns the schema of the given struct type, flattening embedded structs
+// just like encoding/json does.
+func (s *openAPISchemas) structSchema(t reflect.Type) *OpenAPISchema {
+	schema := &OpenAPISchema{Type: "object", Properties: make(map[string]*OpenAPISchema)}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct && !ft.Implements(jsonMarshalerType) && !reflect.PtrTo(ft).Implements(jsonMarshalerType) {
+				for name, property := range s.structSchema(ft).Properties {
+					if _, ok := schema.Properties[name]; !ok {
+						schema.Properties[name] = property
+					}
+				}
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			continue // unexported
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema.Properties[name] = s.schemaOf(field.Type)
+	}
+	return schema
+}
+
+// marshaledSchemaOf returns the schema of a type with a custom JSON encoding,
+// based on the JSON encoding of its zero value.
+func marshaledSchemaOf(t reflect.Type) (schema *OpenAPISchema) {
+	schema = &OpenAPISchema{Description: goTypeName(t)}
+	defer func() {
+		if recover() != nil {
+			schema.Type = ""
+		}
+	}()
+	b, err := json.Marshal(reflect.New(t).Interface())
+	if err != nil {
+		return schema
+	}
+	b = bytes.TrimSpace(b)
+	switch {
+	case len(b) == 0:
+	case b[0] == '"':
+		schema.Type = "string"
+	case b[0] == '{':
+		schema.Type = "object"
+	case b[0] == '[':
+		schema.Type = "array"
+		schema.Items = &OpenAPISchema{}
+	case b[0] == 't' || b[0] == 'f':
+		schema.Type = "boolean"
+	case b[0] == '-' || (b[0] >= '0' && b[0] <= '9'):
+		schema.Type = "number"
+		if t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64 {
+			schema.Type = "integer"
+		}
+	}
+	return schema
+}
+
+// goTypeName returns the qualified name of the given Go type, e.g. types.Currency.
+func goTypeName(t reflect.Type) string {
+	if t.Name() == "" {
+		return t.String()
+	}
+	return path.Base(t.PkgPath()) + "." + t.Name()
+}
+
+// integerFormat returns the OpenAPI format of the given integer type.
+func integerFormat(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return "int32"
+	default:
+		return "int64"
+	}
+}
+
+// openAPIPath converts the given httprouter path to an OpenAPI path,
+// returning the names of its parameters as well, e.g. /foo/{id} for /foo/:id.
+func openAPIPath(route string) (string, []string) {
+	var params []string
+	parts := strings.Split(route, "/")
+	for i, part := range parts {
+		if strings.HasPrefix(part, ":") || strings.HasPrefix(part, "*") {
+			params = append(params, part[1:])
+			parts[i] = "{" + part[1:] + "}"
+		}
+	}
+	return strings.Join(parts, "/"), params
+}
+
+// openAPIOperationID returns the operation ID of the API call of the given method and httprouter path,
+// e.g. getConsensusTransactionsId for GET /consensus/transactions/:id.
+func openAPIOperationID(method, route string) string {
+	id := strings.ToLower(method)
+	for _, part := range strings.FieldsFunc(route, func(r rune) bool { return r == '/' || r == ':' || r == '*' || r == '-' }) {
+		id += strings.ToUpper(part[:1]) + part[1:]
+	}
+	return id
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/threefoldtech/rivine/types"
+)
+
+// ListOrder defines the order in which the items of a list endpoint are returned.
+type ListOrder string
+
+// The orders in which the items of a list endpoint can be returned,
+// relative to the order documented for that endpoint.
+const (
+	ListOrderAscending  ListOrder = "asc"
+	ListOrderDescending ListOrder = "desc"
+)
+
+var (
+	// errInvalidCursor is returned for a cursor which does not identify an item of the list,
+	// e.g. because the item got removed since the previous page was returned.
+	errInvalidCursor = errors.New("invalid cursor: item not found")
+)
+
+// ListOptions are the pagination parameters accepted by list endpoints,
+// parsed from the limit, cursor and order query parameters.
+type ListOptions struct {
+	// Limit is the maximum amount of items returned, 0 if unlimited.
+	Limit int
+	// Cursor is the cursor returned as the next cursor of the previous page,
+	// the empty string for the first page.
+	Cursor string
+	// Order is the order in which the items are returned.
+	Order ListOrder
+}
+
+// parseListOptions parses the list options of the given request,
+// all of them are optional, such that all items are returned in ascending order by default.
+func parseListOptions(req *http.Request) (ListOptions, error) {
+	opts := ListOptions{
+		Cursor: req.FormValue("cursor"),
+		Order:  ListOrderAscending,
+	}
+	if str := req.FormValue("limit"); str != "" {
+		limit, err := strconv.Atoi(str)
+		if err != nil || limit < 0 {
+			return ListOptions{}, fmt.Errorf("invalid limit %q: expected a positive integer", str)
+		}
+		opts.Limit = limit
+	}
+	switch order := ListOrder(req.FormValue("order")); order {
+	case "":
+	case ListOrderAscending, ListOrderDescending:
+		opts.Order = order
+	default:
+		return ListOptions{}, fmt.Errorf("invalid order %q: expected %s or %s", order, ListOrderAscending, ListOrderDescending)
+	}
+	return opts, nil
+}
+
+// page returns the indices of the items of a list of n items, identified using the given
+// id function, which are part of the page defined by the options, in the order to be returned.
+// The returned cursor identifies the next page, and is empty if there is no next page.
+func (opts ListOptions) page(n int, id func(i int) string) ([]int, string, error) {
+	indices := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		if opts.Order == ListOrderDescending {
+			indices = append(indices, n-1-i)
+		} else {
+			indices = append(indices, i)
+		}
+	}
+	if opts.Cursor != "" {
+		found := false
+		for i, index := range indices {
+			if id(index) == opts.Cursor {
+				indices, found = indices[i+1:], true
+				break
+			}
+		}
+		if !found {
+			return nil, "", errInvalidCursor
+		}
+	}
+	if opts.Limit == 0 || len(indices) <= opts.Limit {
+		return indices, "", nil
+	}
+	indices = indices[:opts.Limit]
+	return indices, id(indices[len(indices)-1]), nil
+}
+
+// HeightRange is an optional block height range used to filter the items of a list endpoint,
+// parsed from the startheight and endheight query parameters, both inclusive.
+type HeightRange struct {
+	Start types.BlockHeight
+	// End is the last height of the range, nil if the range is unbounded.
+	End *types.BlockHeight
+}
+
+// parseHeightRange parses the optional height range of the given request.
+func parseHeightRange(req *http.Request) (HeightRange, error) {
+	var hr HeightRange
+	if str := req.FormValue("startheight"); str != "" {
+		start, err := strconv.ParseUint(str, 10, 64)
+		if err != nil {
+			return HeightRange{}, errors.New("parsing integer value for parameter `startheight` failed: " + err.Error())
+		}
+		hr.Start = types.BlockHeight(start)
+	}
+	if str := req.FormValue("endheight"); str != "" {
+		end, err := strconv.ParseUint(str, 10, 64)
+		if err != nil {
+			return HeightRange{}, errors.New("parsing integer value for parameter `endheight` failed: " + err.Error())
+		}
+		height := types.BlockHeight(end)
+		hr.End = &height
+	}
+	return hr, nil
+}
+
+// Contains returns true if the given height is part of the range.
+func (hr HeightRange) Contains(height types.BlockHeight) bool {
+	return height >= hr.Start && (hr.End == nil || height <= *hr.End)
+}
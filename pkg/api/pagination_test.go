@@ -0,0 +1,101 @@
+package api
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+func TestListOptionsPage(t *testing.T) {
+	id := func(i int) string { return strconv.Itoa(i * 10) }
+	testCases := []struct {
+		Options ListOptions
+		Indices []int
+		Cursor  string
+	}{
+		{ListOptions{}, []int{0, 1, 2, 3, 4}, ""},
+		{ListOptions{Limit: 2}, []int{0, 1}, "10"},
+		{ListOptions{Limit: 2, Cursor: "10"}, []int{2, 3}, "30"},
+		{ListOptions{Limit: 2, Cursor: "30"}, []int{4}, ""},
+		{ListOptions{Limit: 5}, []int{0, 1, 2, 3, 4}, ""},
+		{ListOptions{Order: ListOrderDescending, Limit: 3}, []int{4, 3, 2}, "20"},
+		{ListOptions{Order: ListOrderDescending, Cursor: "20"}, []int{1, 0}, ""},
+		{ListOptions{Cursor: "40"}, []int{}, ""},
+	}
+	for _, tc := range testCases {
+		indices, cursor, err := tc.Options.page(5, id)
+		if err != nil {
+			t.Errorf("%+v: %v", tc.Options, err)
+			continue
+		}
+		if !reflect.DeepEqual(indices, tc.Indices) || cursor != tc.Cursor {
+			t.Errorf("%+v: unexpected page %v (next %q), expected %v (next %q)", tc.Options, indices, cursor, tc.Indices, tc.Cursor)
+		}
+	}
+	if _, _, err := (ListOptions{Cursor: "15"}).page(5, id); err != errInvalidCursor {
+		t.Error("expected an unknown cursor to be refused, got:", err)
+	}
+}
+
+func TestParseListOptions(t *testing.T) {
+	req := httptest.NewRequest("GET", "/wallet/transactions?limit=10&cursor=abc&order=desc&startheight=5&endheight=8", nil)
+	opts, err := parseListOptions(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts != (ListOptions{Limit: 10, Cursor: "abc", Order: ListOrderDescending}) {
+		t.Errorf("unexpected options: %+v", opts)
+	}
+	hr, err := parseHeightRange(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hr.Contains(4) || !hr.Contains(5) || !hr.Contains(8) || hr.Contains(9) {
+		t.Errorf("unexpected height range: %+v", hr)
+	}
+	if hr, _ := parseHeightRange(httptest.NewRequest("GET", "/", nil)); !hr.Contains(0) || !hr.Contains(1<<40) {
+		t.Error("expected an absent height range to be unbounded")
+	}
+
+	for _, query := range []string{"limit=-1", "limit=ten", "order=newest"} {
+		if _, err := parseListOptions(httptest.NewRequest("GET", "/?"+query, nil)); err == nil {
+			t.Errorf("expected an error for query %q", query)
+		}
+	}
+}
+
+func TestListWalletTransactions(t *testing.T) {
+	incoming := modules.ProcessedTransaction{TransactionID: types.TransactionID{1}, ConfirmationHeight: 1,
+		Inputs: []modules.ProcessedInput{{WalletAddress: false}}}
+	outgoing := modules.ProcessedTransaction{TransactionID: types.TransactionID{2}, ConfirmationHeight: 2,
+		Inputs: []modules.ProcessedInput{{WalletAddress: false}, {WalletAddress: true}}}
+	confirmed := []modules.ProcessedTransaction{incoming, outgoing}
+	unconfirmed := []modules.ProcessedTransaction{outgoing}
+
+	req := httptest.NewRequest("GET", "/wallet/transactions?direction=incoming", nil)
+	c, u, cursor, err := listWalletTransactions(req, confirmed, unconfirmed, HeightRange{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c) != 1 || c[0].TransactionID != incoming.TransactionID || len(u) != 0 || cursor != "" {
+		t.Errorf("unexpected incoming transactions: %v %v %q", c, u, cursor)
+	}
+
+	req = httptest.NewRequest("GET", "/wallet/transactions?limit=1&order=desc", nil)
+	c, u, cursor, err = listWalletTransactions(req, confirmed, unconfirmed, HeightRange{Start: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c) != 1 || c[0].TransactionID != outgoing.TransactionID || len(u) != 1 || cursor != "" {
+		t.Errorf("unexpected transactions: %v %v %q", c, u, cursor)
+	}
+
+	req = httptest.NewRequest("GET", "/wallet/transactions?direction=sideways", nil)
+	if _, _, _, err = listWalletTransactions(req, confirmed, unconfirmed, HeightRange{}); err == nil {
+		t.Error("expected an invalid direction to be refused")
+	}
+}
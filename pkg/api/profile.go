@@ -0,0 +1,119 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	runtimepprof "runtime/pprof"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/threefoldtech/rivine/profile"
+)
+
+const (
+	// DefaultCPUProfileDuration is the duration of a cpu profile,
+	// captured using the /daemon/profile API call, if no duration is given.
+	DefaultCPUProfileDuration = 30 * time.Second
+	// MaxCPUProfileDuration is the maximum duration of a cpu profile,
+	// captured using the /daemon/profile API call.
+	MaxCPUProfileDuration = 5 * time.Minute
+)
+
+type (
+	// DaemonProfilePOST contains the body of a POST call to /daemon/profile.
+	DaemonProfilePOST struct {
+		// Profile is the kind of profile to capture: cpu, heap, goroutine
+		// or any other runtime profile (e.g. allocs, block, mutex or threadcreate).
+		Profile string `json:"profile"`
+		// Seconds is the duration of a cpu profile, in seconds.
+		Seconds uint64 `json:"seconds,omitempty"`
+	}
+
+	// DaemonProfilePOSTResp contains the response of a POST call to /daemon/profile.
+	DaemonProfilePOSTResp struct {
+		// File is the path of the captured profile, on the host of the daemon.
+		File string `json:"file"`
+	}
+)
+
+// RegisterProfileHTTPHandlers registers the handler for the API call to capture
+// profiles of the daemon to files in the given directory, and, only if enabled,
+// the net/http/pprof handlers under /debug/pprof/.
+func RegisterProfileHTTPHandlers(router Router, profileDir string, pprofEnabled bool, requiredPassword string) {
+	if router == nil {
+		panic("no httprouter Router given")
+	}
+	router.POST("/daemon/profile", RequirePasswordHandler(NewDaemonProfilePostHandler(profileDir), requiredPassword))
+	if pprofEnabled {
+		router.GET("/debug/pprof/*profile", RequirePasswordHandler(NewPprofHandler(), requiredPassword))
+		router.POST("/debug/pprof/*profile", RequirePasswordHandler(NewPprofHandler(), requiredPassword))
+	}
+}
+
+// NewDaemonProfilePostHandler creates a handler to handle the API call
+// capturing a profile of the daemon to a file in the given directory.
+func NewDaemonProfilePostHandler(profileDir string) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		var body DaemonProfilePOST
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			WriteError(w, Error{"error decoding the supplied profile request: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		var (
+			file string
+			err  error
+		)
+		switch body.Profile {
+		case "":
+			WriteError(w, Error{"no profile given"}, http.StatusBadRequest)
+			return
+		case "cpu":
+			duration := DefaultCPUProfileDuration
+			if body.Seconds != 0 {
+				duration = time.Duration(body.Seconds) * time.Second
+			}
+			if duration > MaxCPUProfileDuration {
+				WriteError(w, Error{fmt.Sprintf("cpu profile duration cannot exceed %v", MaxCPUProfileDuration)}, http.StatusBadRequest)
+				return
+			}
+			file, err = profile.SaveCPUProfile(profileDir, "api", duration)
+		default:
+			if runtimepprof.Lookup(body.Profile) == nil {
+				WriteError(w, Error{fmt.Sprintf("unknown profile %q", body.Profile)}, http.StatusBadRequest)
+				return
+			}
+			if body.Seconds != 0 {
+				WriteError(w, Error{"a duration can only be given for a cpu profile"}, http.StatusBadRequest)
+				return
+			}
+			file, err = profile.SaveProfile(profileDir, body.Profile, "api")
+		}
+		if err != nil {
+			WriteError(w, Error{"failed to capture the profile: " + err.Error()}, http.StatusInternalServerError)
+			return
+		}
+		WriteJSON(w, DaemonProfilePOSTResp{File: file})
+	}
+}
+
+// NewPprofHandler creates a handler serving the net/http/pprof handlers,
+// registered using the catch-all parameter profile, e.g. as /debug/pprof/*profile.
+func NewPprofHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		switch ps.ByName("profile") {
+		case "/cmdline":
+			pprof.Cmdline(w, req)
+		case "/profile":
+			pprof.Profile(w, req)
+		case "/symbol":
+			pprof.Symbol(w, req)
+		case "/trace":
+			pprof.Trace(w, req)
+		default:
+			// the index, as well as all named profiles, such as /debug/pprof/heap
+			pprof.Index(w, req)
+		}
+	}
+}
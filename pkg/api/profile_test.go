@@ -0,0 +1,78 @@
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+func TestDaemonProfile(t *testing.T) {
+	dir, err := ioutil.TempDir("", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	post := func(body string) (int, DaemonProfilePOSTResp) {
+		rec := httptest.NewRecorder()
+		NewDaemonProfilePostHandler(dir)(rec, httptest.NewRequest(http.MethodPost, "/daemon/profile", strings.NewReader(body)), httprouter.Params{})
+		var resp DaemonProfilePOSTResp
+		if rec.Code == http.StatusOK {
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return rec.Code, resp
+	}
+
+	for _, name := range []string{"heap", "goroutine", "cpu"} {
+		body := `{"profile":"` + name + `"}`
+		if name == "cpu" {
+			body = `{"profile":"cpu","seconds":1}`
+		}
+		code, resp := post(body)
+		if code != http.StatusOK {
+			t.Fatalf("%s: expected status 200, got %d", name, code)
+		}
+		if filepath.Dir(resp.File) != dir || !strings.HasPrefix(filepath.Base(resp.File), name+"-profile-") {
+			t.Errorf("%s: unexpected profile file %q", name, resp.File)
+		}
+		if stat, err := os.Stat(resp.File); err != nil || stat.Size() == 0 {
+			t.Errorf("%s: expected a non-empty profile file: %v", name, err)
+		}
+	}
+	for _, body := range []string{"", "{}", `{"profile":"unknown"}`, `{"profile":"heap","seconds":1}`, `{"profile":"cpu","seconds":3600}`} {
+		if code, _ := post(body); code != http.StatusBadRequest {
+			t.Errorf("%q: expected status 400, got %d", body, code)
+		}
+	}
+}
+
+func TestPprofHandlers(t *testing.T) {
+	get := func(pprofEnabled bool, path string) *httptest.ResponseRecorder {
+		router := httprouter.New()
+		RegisterProfileHTTPHandlers(router, "", pprofEnabled, "")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+	if rec := get(true, "/debug/pprof/"); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "goroutine") {
+		t.Errorf("unexpected index %d: %q", rec.Code, rec.Body.String())
+	}
+	if rec := get(true, "/debug/pprof/goroutine?debug=1"); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "goroutine profile") {
+		t.Errorf("unexpected goroutine profile %d: %q", rec.Code, rec.Body.String())
+	}
+	if rec := get(true, "/debug/pprof/cmdline"); rec.Code != http.StatusOK {
+		t.Errorf("unexpected cmdline %d", rec.Code)
+	}
+	// the pprof handlers are only served if enabled
+	if rec := get(false, "/debug/pprof/"); rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,185 @@
+package api
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimitConfig defines the rate limits of the API,
+// protecting the daemon from clients flooding it with (expensive) calls.
+// A limit of 0 disables it.
+type RateLimitConfig struct {
+	// IPRate is the amount of calls per second a single IP address can make,
+	// IPBurst the amount of calls it can make at once.
+	IPRate  int
+	IPBurst int
+	// TokenRate is the amount of calls per second a single API token can authenticate,
+	// TokenBurst the amount of calls it can authenticate at once.
+	TokenRate  int
+	TokenBurst int
+	// MaxConcurrentExpensive is the amount of expensive calls, see IsExpensiveAPICall,
+	// which can be executed concurrently.
+	MaxConcurrentExpensive int
+}
+
+// rateLimitPruneInterval is the interval at which the limits of idle clients are forgotten.
+const rateLimitPruneInterval = time.Minute
+
+// IsExpensiveAPICall returns true if the API call of the given method and path
+// can take a long time to execute, e.g. because it rescans the blockchain or exports
+// the full wallet history, such that only a limited amount of them can run concurrently.
+func IsExpensiveAPICall(method, path string) bool {
+	path = strings.TrimSuffix(path, "/")
+	// the calls of version 2 of the API are the same calls
+	path = strings.TrimPrefix(path, APIv2Prefix)
+	switch method {
+	case http.MethodPost:
+		// calls rescanning the blockchain, copying the databases or profiling the daemon
+		return path == "/wallet/init" || path == "/wallet/seed" || path == "/wallet/watch/add" ||
+			path == "/daemon/backup" || path == "/daemon/profile" ||
+			// GraphQL queries of the explorer, possibly resolving many nested relations
+			path == "/explorer/graphql"
+	case http.MethodGet:
+		// calls scanning or exporting (a large part of) the blockchain or the databases
+		return path == "/wallet/backup" || path == "/daemon/backup" || path == "/wallet/transactions" ||
+			strings.HasPrefix(path, "/wallet/transactions/") ||
+			path == "/consensus/unspent" || strings.HasPrefix(path, "/consensus/unspent/unlockhashes/") ||
+			path == "/consensus/statistics" ||
+			path == "/explorer/stats/history" || path == "/explorer/stats/range" || path == "/explorer/graphql" ||
+			// calls walking all buckets of a database
+			strings.HasPrefix(path, "/debug/database/") && !strings.HasSuffix(path, "/entries") ||
+			// calls profiling or tracing the daemon for a duration
+			path == "/debug/pprof/profile" || path == "/debug/pprof/trace"
+	default:
+		return false
+	}
+}
+
+// rateBucket is the token bucket of a single client.
+type rateBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter limits the rate of calls per client, using a token bucket per client.
+type rateLimiter struct {
+	rate  float64
+	burst float64
+	now   func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*rateBucket
+	lastPrune time.Time
+}
+
+// newRateLimiter creates a rate limiter allowing the given rate of calls per second,
+// and the given burst of calls at once, per client. It returns nil if the rate is 0.
+func newRateLimiter(rate, burst int) *rateLimiter {
+	if rate <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = rate
+	}
+	return &rateLimiter{
+		rate:    float64(rate),
+		burst:   float64(burst),
+		now:     time.Now,
+		buckets: make(map[string]*rateBucket),
+	}
+}
+
+// allow returns true if the client identified by the given key can make a call,
+// and otherwise the time after which it can.
+func (rl *rateLimiter) allow(key string) (bool, time.Duration) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	now := rl.now()
+	if now.Sub(rl.lastPrune) >= rateLimitPruneInterval {
+		rl.prune(now)
+	}
+	bucket, ok := rl.buckets[key]
+	if !ok {
+		bucket = &rateBucket{tokens: rl.burst, last: now}
+		rl.buckets[key] = bucket
+	}
+	bucket.tokens = math.Min(rl.burst, bucket.tokens+now.Sub(bucket.last).Seconds()*rl.rate)
+	bucket.last = now
+	if bucket.tokens < 1 {
+		return false, time.Duration((1 - bucket.tokens) / rl.rate * float64(time.Second))
+	}
+	bucket.tokens--
+	return true, 0
+}
+
+// prune forgets the buckets which are full again, as they are equal to new buckets,
+// it should be called while holding the lock.
+func (rl *rateLimiter) prune(now time.Time) {
+	for key, bucket := range rl.buckets {
+		if bucket.tokens+now.Sub(bucket.last).Seconds()*rl.rate >= rl.burst {
+			delete(rl.buckets, key)
+		}
+	}
+	rl.lastPrune = now
+}
+
+// RateLimitHandler is middleware that limits the rate of calls per IP address,
+// and per API token for requests authenticated using one, see RequireAPITokenScopeHandler,
+// as well as the amount of expensive calls executed concurrently.
+// Calls exceeding a limit are refused using status code 429 (Too Many Requests),
+// with the Retry-After header defining the amount of seconds after which they can be retried.
+func RateLimitHandler(h http.Handler, cfg RateLimitConfig) http.Handler {
+	ipLimiter := newRateLimiter(cfg.IPRate, cfg.IPBurst)
+	tokenLimiter := newRateLimiter(cfg.TokenRate, cfg.TokenBurst)
+	var expensive chan struct{}
+	if cfg.MaxConcurrentExpensive > 0 {
+		expensive = make(chan struct{}, cfg.MaxConcurrentExpensive)
+	}
+	if ipLimiter == nil && tokenLimiter == nil && expensive == nil {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if ipLimiter != nil {
+			host, _, err := net.SplitHostPort(req.RemoteAddr)
+			if err != nil {
+				host = req.RemoteAddr
+			}
+			if ok, wait := ipLimiter.allow(host); !ok {
+				writeTooManyRequests(w, "too many API calls from "+host, wait)
+				return
+			}
+		}
+		if token, ok := APITokenFromRequest(req); ok && tokenLimiter != nil {
+			if ok, wait := tokenLimiter.allow(token.ID); !ok {
+				writeTooManyRequests(w, "too many API calls authenticated using token "+token.ID, wait)
+				return
+			}
+		}
+		if expensive != nil && IsExpensiveAPICall(req.Method, req.URL.Path) {
+			select {
+			case expensive <- struct{}{}:
+				defer func() { <-expensive }()
+			default:
+				writeTooManyRequests(w, "too many expensive API calls in progress", time.Second)
+				return
+			}
+		}
+		h.ServeHTTP(w, req)
+	})
+}
+
+// writeTooManyRequests refuses a call exceeding a rate limit,
+// which can be retried after the given duration, rounded up to whole seconds.
+func writeTooManyRequests(w http.ResponseWriter, msg string, retryAfter time.Duration) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	WriteError(w, Error{msg}, http.StatusTooManyRequests)
+}
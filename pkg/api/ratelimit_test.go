@@ -0,0 +1,151 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Unix(1000, 0)
+	rl := newRateLimiter(2, 3)
+	rl.now = func() time.Time { return now }
+
+	// the burst is allowed at once
+	for i := 0; i < 3; i++ {
+		if ok, _ := rl.allow("a"); !ok {
+			t.Fatalf("call %d: expected to be allowed", i)
+		}
+	}
+	ok, wait := rl.allow("a")
+	if ok || wait != 500*time.Millisecond {
+		t.Fatalf("expected to be refused for 500ms, got %v, %v", ok, wait)
+	}
+	// other clients are not affected
+	if ok, _ := rl.allow("b"); !ok {
+		t.Fatal("expected another client to be allowed")
+	}
+	// the rate is allowed afterwards
+	now = now.Add(500 * time.Millisecond)
+	if ok, _ := rl.allow("a"); !ok {
+		t.Fatal("expected to be allowed after waiting")
+	}
+	if ok, _ := rl.allow("a"); ok {
+		t.Fatal("expected to be refused again")
+	}
+
+	// idle clients are forgotten
+	now = now.Add(rateLimitPruneInterval)
+	rl.allow("c")
+	if _, ok := rl.buckets["a"]; ok || len(rl.buckets) != 1 {
+		t.Fatalf("expected the idle clients to be pruned, got %d buckets", len(rl.buckets))
+	}
+
+	if newRateLimiter(0, 10) != nil {
+		t.Fatal("expected no rate limiter for a rate of 0")
+	}
+}
+
+func TestRateLimitHandler(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) { WriteSuccess(w) })
+	handler := RateLimitHandler(ok, RateLimitConfig{IPRate: 1, IPBurst: 2, TokenRate: 1})
+	call := func(remoteAddr string, token *APIToken) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/consensus", nil)
+		req.RemoteAddr = remoteAddr
+		if token != nil {
+			req = req.WithContext(context.WithValue(req.Context(), apiTokenContextKey{}, *token))
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for i := 0; i < 2; i++ {
+		if rec := call("10.0.0.1:1234", nil); rec.Code != http.StatusNoContent {
+			t.Fatalf("call %d: unexpected status %d", i, rec.Code)
+		}
+	}
+	// the limit is per IP address, not per connection
+	rec := call("10.0.0.1:5678", nil)
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "1" {
+		t.Fatalf("expected status 429 with Retry-After 1, got %d (%q)", rec.Code, rec.Header().Get("Retry-After"))
+	}
+
+	// calls authenticated using a token are limited per token as well
+	token := &APIToken{ID: "abc"}
+	if rec = call("10.0.0.2:1234", token); rec.Code != http.StatusNoContent {
+		t.Fatalf("unexpected status %d", rec.Code)
+	}
+	if rec = call("10.0.0.3:1234", token); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected the token to be limited, got status %d", rec.Code)
+	}
+}
+
+func TestRateLimitHandlerExpensive(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	blocking := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if IsExpensiveAPICall(req.Method, req.URL.Path) {
+			started <- struct{}{}
+			<-release
+		}
+		WriteSuccess(w)
+	})
+	handler := RateLimitHandler(blocking, RateLimitConfig{MaxConcurrentExpensive: 1})
+	call := func(method, path string) int {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec.Code
+	}
+
+	done := make(chan int)
+	go func() { done <- call(http.MethodPost, "/wallet/watch/add") }()
+	<-started
+	if code := call(http.MethodGet, "/wallet/backup"); code != http.StatusTooManyRequests {
+		t.Fatalf("expected a concurrent expensive call to be refused, got status %d", code)
+	}
+	// other calls are not limited
+	if code := call(http.MethodGet, "/wallet"); code != http.StatusNoContent {
+		t.Fatalf("unexpected status %d", code)
+	}
+	release <- struct{}{}
+	if code := <-done; code != http.StatusNoContent {
+		t.Fatalf("unexpected status %d", code)
+	}
+	// the slot is released
+	go func() { <-started; release <- struct{}{} }()
+	if code := call(http.MethodGet, "/wallet/backup"); code != http.StatusNoContent {
+		t.Fatalf("expected the expensive call to be allowed again, got status %d", code)
+	}
+}
+
+func TestIsExpensiveAPICall(t *testing.T) {
+	for _, call := range [][2]string{
+		{http.MethodPost, "/wallet/watch/add"},
+		{http.MethodPost, "/wallet/init"},
+		{http.MethodGet, "/wallet/backup"},
+		{http.MethodGet, "/wallet/transactions/"},
+		{http.MethodGet, "/consensus/unspent/unlockhashes/0123"},
+		{http.MethodGet, "/consensus/unspent"},
+		{http.MethodGet, "/daemon/backup"},
+		{http.MethodPost, "/daemon/backup"},
+		{http.MethodPost, "/daemon/profile"},
+		{http.MethodGet, "/debug/pprof/profile"},
+		{http.MethodGet, "/debug/database/consensus"},
+	} {
+		if !IsExpensiveAPICall(call[0], call[1]) {
+			t.Errorf("expected %s %s to be expensive", call[0], call[1])
+		}
+	}
+	for _, call := range [][2]string{
+		{http.MethodGet, "/wallet"},
+		{http.MethodGet, "/wallet/watch/add"},
+		{http.MethodPost, "/wallet/coins"},
+		{http.MethodGet, "/debug/database/consensus/entries"},
+	} {
+		if IsExpensiveAPICall(call[0], call[1]) {
+			t.Errorf("expected %s %s not to be expensive", call[0], call[1])
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package api
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+type (
+	// ConsensusChangeMessage is pushed over the websocket connection opened by a GET request
+	// to /consensus/changes, for every consensus change since the requested one.
+	// Once all changes since the requested one are pushed, a message marking the end of the catch-up
+	// is pushed, after which the new consensus changes are pushed as they happen.
+	ConsensusChangeMessage struct {
+		// Change is the consensus change, omitted for the messages
+		// marking the end of the catch-up or reporting an error.
+		Change *modules.ConsensusChange `json:"change,omitempty"`
+		// Height is the height of the last block applied by the change.
+		Height types.BlockHeight `json:"height,omitempty"`
+		// CaughtUp is true for the message marking the end of the catch-up.
+		CaughtUp bool `json:"caughtup,omitempty"`
+		// Error describes why the consensus changes cannot be streamed,
+		// e.g. as the requested consensus change is unknown, after which the connection is closed.
+		Error string `json:"error,omitempty"`
+	}
+
+	// TransactionPoolUpdate is pushed over the websocket connection opened by a GET request
+	// to /transactionpool/updates, containing all unconfirmed transactions, when connected
+	// and whenever the unconfirmed transactions change.
+	TransactionPoolUpdate struct {
+		Transactions []types.Transaction `json:"transactions"`
+	}
+)
+
+// RegisterRemoteNodeHTTPHandlers registers the handlers streaming the consensus changes
+// and the unconfirmed transactions, such that a wallet can use the consensus set and
+// transaction pool of this node from a separate process, and the node holds no secrets itself.
+// Both calls require the API password, or an API token, should the API be password protected.
+func RegisterRemoteNodeHTTPHandlers(router Router, cs modules.ConsensusSet, tpool modules.TransactionPool, requiredPassword string) {
+	if cs == nil {
+		panic("no consensus set module given")
+	}
+	if tpool == nil {
+		panic("no transaction pool module given")
+	}
+	if router == nil {
+		panic("no httprouter Router given")
+	}
+	router.GET("/consensus/changes", RequirePasswordHandler(NewConsensusGetChangesHandler(cs), requiredPassword))
+	router.GET("/transactionpool/updates", RequirePasswordHandler(NewTransactionPoolGetUpdatesHandler(tpool), requiredPassword))
+}
+
+const (
+	// consensusChangeBufferSize is the amount of consensus changes
+	// buffered for a single change stream.
+	consensusChangeBufferSize = 64
+	// consensusChangeSendTimeout is the maximum duration the consensus set waits
+	// for a client which is catching up, and of which the buffer is full, before the client is dropped.
+	// The consensus set is locked meanwhile, hence new consensus changes are never waited for.
+	consensusChangeSendTimeout = 10 * time.Second
+)
+
+// consensusChangeStream is a ConsensusSetSubscriber
+// buffering the consensus changes to be sent to a single client.
+type consensusChangeStream struct {
+	changes  chan modules.ConsensusChange
+	caughtUp chan struct{}
+	dropped  chan struct{}
+	once     sync.Once
+}
+
+// ProcessConsensusChange implements modules.ConsensusSetSubscriber.ProcessConsensusChange
+func (stream *consensusChangeStream) ProcessConsensusChange(cc modules.ConsensusChange) {
+	select {
+	case stream.changes <- cc:
+		return
+	case <-stream.dropped:
+		return
+	default:
+	}
+	select {
+	case <-stream.caughtUp:
+		// never block the consensus set for new changes, drop the client instead
+		stream.drop()
+		return
+	default:
+	}
+	// the client is catching up, wait for it to make room, within limits
+	timer := time.NewTimer(consensusChangeSendTimeout)
+	defer timer.Stop()
+	select {
+	case stream.changes <- cc:
+	case <-stream.dropped:
+	case <-timer.C:
+		stream.drop()
+	}
+}
+
+func (stream *consensusChangeStream) drop() {
+	stream.once.Do(func() { close(stream.dropped) })
+}
+
+// NewConsensusGetChangesHandler creates a handler
+// to handle the API call to stream the consensus changes, since the one identified by the optional id parameter,
+// over a websocket connection, as ConsensusChangeMessage values.
+// All consensus changes are streamed if no id is given.
+// The connection is closed when the client falls behind by more than consensusChangeBufferSize changes,
+// after which it can reconnect, requesting the changes since the last one it received.
+func NewConsensusGetChangesHandler(cs modules.ConsensusSet) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		start := modules.ConsensusChangeBeginning
+		if str := req.FormValue("id"); str != "" {
+			var id crypto.Hash
+			if err := id.LoadString(str); err != nil {
+				WriteError(w, Error{"invalid consensus change id: " + err.Error()}, http.StatusBadRequest)
+				return
+			}
+			start = modules.ConsensusChangeID(id)
+		}
+		ws, err := upgradeWebSocket(w, req)
+		if err != nil {
+			WriteError(w, Error{"failed to open websocket connection: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		defer ws.Close()
+
+		stream := &consensusChangeStream{
+			changes:  make(chan modules.ConsensusChange, consensusChangeBufferSize),
+			caughtUp: make(chan struct{}),
+			dropped:  make(chan struct{}),
+		}
+		go func() {
+			ws.readLoop()
+			stream.drop()
+		}()
+		// the consensus set pushes the changes since the requested one while subscribing,
+		// hence subscribe in the background, such that they can be sent meanwhile
+		subscribed := make(chan error, 1)
+		go func() {
+			subscribed <- cs.ConsensusSetSubscribe(stream, start, stream.dropped)
+		}()
+		defer func() {
+			stream.drop()
+			if subscribed != nil {
+				<-subscribed
+			}
+			cs.Unsubscribe(stream)
+		}()
+
+		writeChange := func(cc modules.ConsensusChange) error {
+			msg := ConsensusChangeMessage{Change: &cc}
+			if n := len(cc.AppliedBlocks); n > 0 {
+				msg.Height, _ = cs.BlockHeightOfBlock(cc.AppliedBlocks[n-1])
+			}
+			return ws.WriteJSON(msg)
+		}
+		for {
+			select {
+			case cc := <-stream.changes:
+				if err := writeChange(cc); err != nil {
+					return
+				}
+			case err := <-subscribed:
+				subscribed = nil
+				if err != nil {
+					ws.WriteJSON(ConsensusChangeMessage{Error: err.Error()})
+					ws.writeFrame(websocketOpClose, nil)
+					return
+				}
+				// all changes pushed while subscribing are buffered by now
+				for len(stream.changes) > 0 {
+					if err := writeChange(<-stream.changes); err != nil {
+						return
+					}
+				}
+				if err := ws.WriteJSON(ConsensusChangeMessage{CaughtUp: true}); err != nil {
+					return
+				}
+				close(stream.caughtUp)
+			case <-stream.dropped:
+				ws.writeFrame(websocketOpClose, nil)
+				return
+			}
+		}
+	}
+}
+
+// transactionPoolUpdateStream is a TransactionPoolSubscriber
+// keeping the latest unconfirmed transactions to be sent to a single client,
+// as only the latest update matters to the client.
+type transactionPoolUpdateStream struct {
+	mu      sync.Mutex
+	txns    []types.Transaction
+	updated chan struct{}
+}
+
+// ReceiveUpdatedUnconfirmedTransactions implements modules.TransactionPoolSubscriber.ReceiveUpdatedUnconfirmedTransactions
+func (stream *transactionPoolUpdateStream) ReceiveUpdatedUnconfirmedTransactions(txns []types.Transaction, _ modules.ConsensusChange) {
+	stream.mu.Lock()
+	stream.txns = txns
+	stream.mu.Unlock()
+	select {
+	case stream.updated <- struct{}{}:
+	default:
+		// an update is pending already
+	}
+}
+
+// NewTransactionPoolGetUpdatesHandler creates a handler
+// to handle the API call to stream the unconfirmed transactions over a websocket connection,
+// as TransactionPoolUpdate values, when connected and whenever they change.
+// Updates which the client did not receive yet are replaced by the latest one.
+func NewTransactionPoolGetUpdatesHandler(tpool modules.TransactionPool) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		ws, err := upgradeWebSocket(w, req)
+		if err != nil {
+			WriteError(w, Error{"failed to open websocket connection: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		defer ws.Close()
+
+		stream := &transactionPoolUpdateStream{
+			updated: make(chan struct{}, 1),
+		}
+		tpool.TransactionPoolSubscribe(stream)
+		defer tpool.Unsubscribe(stream)
+
+		closed := make(chan struct{})
+		go func() {
+			ws.readLoop()
+			close(closed)
+		}()
+		for {
+			select {
+			case <-stream.updated:
+				stream.mu.Lock()
+				update := TransactionPoolUpdate{Transactions: stream.txns}
+				stream.mu.Unlock()
+				if update.Transactions == nil {
+					update.Transactions = []types.Transaction{}
+				}
+				if err := ws.WriteJSON(update); err != nil {
+					return
+				}
+			case <-closed:
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package api
+
+import (
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// testChangesConsensusSet pushes its changes, following the requested one,
+// to a subscriber, of which the blocks have their height as timestamp.
+type testChangesConsensusSet struct {
+	modules.ConsensusSet
+	changes []modules.ConsensusChange
+
+	mu           sync.Mutex
+	subscriber   modules.ConsensusSetSubscriber
+	subscribed   chan struct{}
+	unsubscribed chan struct{}
+}
+
+func (cs *testChangesConsensusSet) ConsensusSetSubscribe(subscriber modules.ConsensusSetSubscriber, start modules.ConsensusChangeID, _ <-chan struct{}) error {
+	changes := cs.changes
+	if start != modules.ConsensusChangeBeginning {
+		for i := 0; ; i++ {
+			if i == len(changes) {
+				return modules.ErrInvalidConsensusChangeID
+			}
+			if changes[i].ID == start {
+				changes = changes[i+1:]
+				break
+			}
+		}
+	}
+	for _, cc := range changes {
+		subscriber.ProcessConsensusChange(cc)
+	}
+	cs.mu.Lock()
+	cs.subscriber = subscriber
+	cs.mu.Unlock()
+	close(cs.subscribed)
+	return nil
+}
+
+func (cs *testChangesConsensusSet) Unsubscribe(modules.ConsensusSetSubscriber) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if cs.subscriber != nil {
+		cs.subscriber = nil
+		close(cs.unsubscribed)
+	}
+}
+
+func (cs *testChangesConsensusSet) BlockHeightOfBlock(block types.Block) (types.BlockHeight, bool) {
+	return types.BlockHeight(block.Timestamp), true
+}
+
+type testUpdatesTransactionPool struct {
+	modules.TransactionPool
+	subscribed chan modules.TransactionPoolSubscriber
+}
+
+func (tpool *testUpdatesTransactionPool) TransactionPoolSubscribe(subscriber modules.TransactionPoolSubscriber) {
+	subscriber.ReceiveUpdatedUnconfirmedTransactions(nil, modules.ConsensusChange{})
+	tpool.subscribed <- subscriber
+}
+
+func (tpool *testUpdatesTransactionPool) Unsubscribe(modules.TransactionPoolSubscriber) {}
+
+func testConsensusChange(id byte, heights ...types.BlockHeight) modules.ConsensusChange {
+	cc := modules.ConsensusChange{ID: modules.ConsensusChangeID{id}}
+	for _, height := range heights {
+		cc.AppliedBlocks = append(cc.AppliedBlocks, types.Block{Timestamp: types.Timestamp(height)})
+	}
+	return cc
+}
+
+func TestConsensusGetChanges(t *testing.T) {
+	newConsensusSet := func() *testChangesConsensusSet {
+		return &testChangesConsensusSet{
+			changes:      []modules.ConsensusChange{testConsensusChange(1, 0), testConsensusChange(2, 1, 2)},
+			subscribed:   make(chan struct{}),
+			unsubscribed: make(chan struct{}),
+		}
+	}
+	serve := func(cs modules.ConsensusSet) (*HTTPClient, func()) {
+		router := httprouter.New()
+		RegisterRemoteNodeHTTPHandlers(router, cs, &testUpdatesTransactionPool{}, "foo")
+		server := httptest.NewServer(router)
+		return &HTTPClient{RootURL: server.URL, Password: "foo"}, server.Close
+	}
+
+	read := func(stream *WebSocketStream) ConsensusChangeMessage {
+		t.Helper()
+		var msg ConsensusChangeMessage
+		if err := stream.ReadJSON(&msg); err != nil {
+			t.Fatal(err)
+		}
+		return msg
+	}
+
+	// all changes are pushed, followed by the end of the catch-up and the new changes
+	cs := newConsensusSet()
+	client, closeServer := serve(cs)
+	defer closeServer()
+	stream, err := client.Subscribe("/consensus/changes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []struct {
+		id     byte
+		height types.BlockHeight
+	}{{1, 0}, {2, 2}} {
+		msg := read(stream)
+		if msg.Change == nil || msg.Change.ID != (modules.ConsensusChangeID{expected.id}) || msg.Height != expected.height {
+			t.Fatalf("unexpected message: %+v, expected change %d at height %d", msg, expected.id, expected.height)
+		}
+	}
+	if msg := read(stream); !msg.CaughtUp || msg.Change != nil {
+		t.Fatalf("expected the end of the catch-up, got: %+v", msg)
+	}
+	<-cs.subscribed
+	cs.mu.Lock()
+	cs.subscriber.ProcessConsensusChange(testConsensusChange(3, 3))
+	cs.mu.Unlock()
+	if msg := read(stream); msg.Change == nil || msg.Change.ID != (modules.ConsensusChangeID{3}) || msg.Height != 3 {
+		t.Fatalf("unexpected message: %+v", msg)
+	}
+	stream.Close()
+	select {
+	case <-cs.unsubscribed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream was not unsubscribed once closed")
+	}
+
+	// only the changes following the requested one are pushed
+	client, closeServer = serve(newConsensusSet())
+	defer closeServer()
+	stream, err = client.Subscribe("/consensus/changes?id=" + crypto.Hash(modules.ConsensusChangeID{1}).String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg := read(stream); msg.Change == nil || msg.Change.ID != (modules.ConsensusChangeID{2}) {
+		t.Fatalf("unexpected message: %+v", msg)
+	}
+	if msg := read(stream); !msg.CaughtUp {
+		t.Fatalf("expected the end of the catch-up, got: %+v", msg)
+	}
+	stream.Close()
+
+	// an unknown change is reported
+	client, closeServer = serve(newConsensusSet())
+	defer closeServer()
+	stream, err = client.Subscribe("/consensus/changes?id=" + crypto.Hash(modules.ConsensusChangeID{4}).String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg := read(stream); msg.Error != modules.ErrInvalidConsensusChangeID.Error() {
+		t.Fatalf("expected an invalid consensus change error, got: %+v", msg)
+	}
+	stream.Close()
+
+	// the stream requires the API password
+	client.Password = "bar"
+	if _, err = client.Subscribe("/consensus/changes"); err == nil {
+		t.Fatal("expected the subscription to be refused")
+	}
+}
+
+// TestConsensusChangeStreamOverflow ensures a client is dropped,
+// rather than blocking the consensus set, once it falls behind on the new changes.
+func TestConsensusChangeStreamOverflow(t *testing.T) {
+	stream := &consensusChangeStream{
+		changes:  make(chan modules.ConsensusChange, 1),
+		caughtUp: make(chan struct{}),
+		dropped:  make(chan struct{}),
+	}
+	close(stream.caughtUp)
+	stream.ProcessConsensusChange(testConsensusChange(1, 0))
+	select {
+	case <-stream.dropped:
+		t.Fatal("stream dropped while its buffer has room")
+	default:
+	}
+	stream.ProcessConsensusChange(testConsensusChange(2, 1))
+	select {
+	case <-stream.dropped:
+	default:
+		t.Fatal("stream not dropped once its buffer overflows")
+	}
+}
+
+func TestTransactionPoolGetUpdates(t *testing.T) {
+	tpool := &testUpdatesTransactionPool{subscribed: make(chan modules.TransactionPoolSubscriber, 1)}
+	router := httprouter.New()
+	RegisterRemoteNodeHTTPHandlers(router, &testChangesConsensusSet{}, tpool, "")
+	server := httptest.NewServer(router)
+	defer server.Close()
+	client := HTTPClient{RootURL: server.URL}
+
+	stream, err := client.Subscribe("/transactionpool/updates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+	var update TransactionPoolUpdate
+	if err = stream.ReadJSON(&update); err != nil {
+		t.Fatal(err)
+	}
+	if update.Transactions == nil || len(update.Transactions) != 0 {
+		t.Fatalf("expected an empty list of transactions, got: %v", update.Transactions)
+	}
+
+	// only the latest update matters, pending updates are replaced
+	subscriber := <-tpool.subscribed
+	for n := 1; n <= 3; n++ {
+		subscriber.ReceiveUpdatedUnconfirmedTransactions(make([]types.Transaction, n), modules.ConsensusChange{})
+	}
+	for len(update.Transactions) != 3 {
+		if err = stream.ReadJSON(&update); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// publicKeyPinPrefix is the optional prefix of a public key pin,
+// as used by the --pinnedpubkey option of curl.
+const publicKeyPinPrefix = "sha256//"
+
+// UnpinnedCertificateError is returned by the HTTPClient if the public key
+// of the certificate presented by the daemon does not match any of the pinned public keys.
+type UnpinnedCertificateError struct {
+	// Pin is the pin of the public key of the presented certificate.
+	Pin string
+}
+
+// Error implements error.Error
+func (e *UnpinnedCertificateError) Error() string {
+	return fmt.Sprintf("the public key of the certificate of the daemon (%s) is not pinned", e.Pin)
+}
+
+// PublicKeyPin returns the pin of the public key of the given certificate,
+// being the base64-encoded SHA-256 hash of its DER-encoded SubjectPublicKeyInfo,
+// prefixed with "sha256//".
+func PublicKeyPin(cert *x509.Certificate) string {
+	hash := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return publicKeyPinPrefix + base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// NewPinnedTLSConfig creates a TLS config, which only accepts a server certificate
+// of which the public key matches one of the given pins, as returned by PublicKeyPin,
+// optionally given without the "sha256//" prefix.
+// As the public key is pinned, self-signed certificates are accepted as well.
+func NewPinnedTLSConfig(pins []string) (*tls.Config, error) {
+	if len(pins) == 0 {
+		return nil, errors.New("no public key pins given")
+	}
+	pinned := make(map[string]struct{}, len(pins))
+	for _, pin := range pins {
+		pin = strings.TrimPrefix(strings.TrimSpace(pin), publicKeyPinPrefix)
+		b, err := base64.StdEncoding.DecodeString(pin)
+		if err != nil || len(b) != sha256.Size {
+			return nil, fmt.Errorf("invalid public key pin %q: has to be a base64-encoded SHA-256 hash", pin)
+		}
+		pinned[publicKeyPinPrefix+pin] = struct{}{}
+	}
+	return &tls.Config{
+		// the certificate chain is not verified, only the public key of the presented certificate,
+		// which is proven to be owned by the server during the handshake
+		InsecureSkipVerify: true,
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if len(rawCerts) == 0 {
+				return errors.New("no certificate presented by the daemon")
+			}
+			cert, err := x509.ParseCertificate(rawCerts[0])
+			if err != nil {
+				return err
+			}
+			pin := PublicKeyPin(cert)
+			if _, ok := pinned[pin]; !ok {
+				return &UnpinnedCertificateError{Pin: pin}
+			}
+			return nil
+		},
+	}, nil
+}
@@ -0,0 +1,358 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/persist"
+	"github.com/threefoldtech/rivine/types"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// APITokensFile is the name of the file, stored in the root persistent directory of the daemon,
+// in which the API tokens are persisted.
+const APITokensFile = "apitokens.json"
+
+// APITokenScope defines which API calls can be authenticated using an APIToken.
+type APITokenScope string
+
+const (
+	// APITokenScopeRead allows a token to authenticate API calls which only read information,
+	// such as the wallet balance, but not the secrets of the wallet (e.g. its seeds and keys).
+	APITokenScopeRead APITokenScope = "read"
+	// APITokenScopeWalletSpend allows a token to authenticate API calls which spend
+	// or sign using the wallet, as well as to publish transactions, including the read scope.
+	APITokenScopeWalletSpend APITokenScope = "wallet-spend"
+	// APITokenScopeAdmin allows a token to authenticate all API calls, just like the API password,
+	// including the calls to manage the API tokens.
+	APITokenScopeAdmin APITokenScope = "admin"
+)
+
+var (
+	// ErrAPITokenNotFound is returned when no API token exists for a given ID.
+	ErrAPITokenNotFound = errors.New("API token not found")
+	// ErrAPITokenNoScopes is returned when creating an API token without scopes.
+	ErrAPITokenNoScopes = errors.New("an API token requires at least one scope")
+)
+
+// level returns the level of the scope, a scope includes all scopes of a lower level.
+func (s APITokenScope) level() int {
+	switch s {
+	case APITokenScopeRead:
+		return 1
+	case APITokenScopeWalletSpend:
+		return 2
+	case APITokenScopeAdmin:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.UnmarshalText
+func (s *APITokenScope) UnmarshalText(b []byte) error {
+	scope := APITokenScope(b)
+	if scope.level() == 0 {
+		return fmt.Errorf("unknown API token scope %q", string(b))
+	}
+	*s = scope
+	return nil
+}
+
+// APIToken is a named credential which can be used instead of the API password,
+// authenticating only the API calls allowed by its scopes.
+// The secret of a token is only known at creation, the daemon stores only its hash.
+type APIToken struct {
+	ID      string          `json:"id"`
+	Name    string          `json:"name"`
+	Scopes  []APITokenScope `json:"scopes"`
+	Created types.Timestamp `json:"created"`
+}
+
+// HasScope returns true if the token can authenticate API calls requiring the given scope.
+func (t APIToken) HasScope(scope APITokenScope) bool {
+	for _, s := range t.Scopes {
+		if s.level() >= scope.level() {
+			return true
+		}
+	}
+	return false
+}
+
+// apiTokenEntry is an API token as persisted by the APITokenStore.
+type apiTokenEntry struct {
+	APIToken
+	SecretHash crypto.Hash `json:"secrethash"`
+}
+
+// apiTokensMetadata contains the header and version strings that identify the API tokens file.
+var apiTokensMetadata = persist.Metadata{
+	Header:  "API Tokens",
+	Version: "1.0.0",
+}
+
+// APITokenStore manages the API tokens of a daemon, persisting them to disk.
+type APITokenStore struct {
+	filename string
+	tokens   []apiTokenEntry
+	mu       sync.RWMutex
+}
+
+// NewAPITokenStore creates an APITokenStore, persisted to the given file,
+// loading the tokens of that file if it exists.
+func NewAPITokenStore(filename string) (*APITokenStore, error) {
+	store := &APITokenStore{filename: filename}
+	err := persist.LoadJSON(apiTokensMetadata, &store.tokens, filename)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to load API tokens: %v", err)
+	}
+	return store, nil
+}
+
+// Tokens returns all API tokens, in the order they were created.
+func (store *APITokenStore) Tokens() []APIToken {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	tokens := make([]APIToken, 0, len(store.tokens))
+	for _, entry := range store.tokens {
+		tokens = append(tokens, entry.APIToken)
+	}
+	return tokens
+}
+
+// CreateToken creates and persists a new API token with the given name and scopes,
+// returning the token and its secret, which is to be used as the API password.
+func (store *APITokenStore) CreateToken(name string, scopes []APITokenScope) (APIToken, string, error) {
+	if len(scopes) == 0 {
+		return APIToken{}, "", ErrAPITokenNoScopes
+	}
+	for _, scope := range scopes {
+		if scope.level() == 0 {
+			return APIToken{}, "", fmt.Errorf("unknown API token scope %q", string(scope))
+		}
+	}
+	var id [8]byte
+	var secret [32]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return APIToken{}, "", err
+	}
+	if _, err := rand.Read(secret[:]); err != nil {
+		return APIToken{}, "", err
+	}
+	entry := apiTokenEntry{
+		APIToken: APIToken{
+			ID:      hex.EncodeToString(id[:]),
+			Name:    name,
+			Scopes:  scopes,
+			Created: types.CurrentTimestamp(),
+		},
+		SecretHash: crypto.HashBytes(secret[:]),
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.tokens = append(store.tokens, entry)
+	err := store.save()
+	if err != nil {
+		store.tokens = store.tokens[:len(store.tokens)-1]
+		return APIToken{}, "", err
+	}
+	return entry.APIToken, hex.EncodeToString(secret[:]), nil
+}
+
+// RevokeToken removes the API token with the given ID,
+// such that it can no longer be used to authenticate API calls.
+func (store *APITokenStore) RevokeToken(id string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	for i, entry := range store.tokens {
+		if entry.ID == id {
+			tokens := store.tokens
+			store.tokens = append(append([]apiTokenEntry{}, tokens[:i]...), tokens[i+1:]...)
+			err := store.save()
+			if err != nil {
+				store.tokens = tokens
+			}
+			return err
+		}
+	}
+	return ErrAPITokenNotFound
+}
+
+// Authenticate returns the API token of the given secret, if it exists.
+func (store *APITokenStore) Authenticate(secret string) (APIToken, bool) {
+	b, err := hex.DecodeString(secret)
+	if err != nil {
+		return APIToken{}, false
+	}
+	hash := crypto.HashBytes(b)
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	for _, entry := range store.tokens {
+		if subtle.ConstantTimeCompare(entry.SecretHash[:], hash[:]) == 1 {
+			return entry.APIToken, true
+		}
+	}
+	return APIToken{}, false
+}
+
+// save persists the API tokens, it should be called while holding the lock.
+func (store *APITokenStore) save() error {
+	err := os.MkdirAll(filepath.Dir(store.filename), 0700)
+	if err != nil {
+		return err
+	}
+	return persist.SaveJSON(apiTokensMetadata, store.tokens, store.filename)
+}
+
+// APITokenScopeOfCall returns the scope an API token requires to authenticate the API call
+// of the given method and path. API calls not known to read or spend are considered admin calls.
+func APITokenScopeOfCall(method, path string) APITokenScope {
+	path = strings.TrimSuffix(path, "/")
+	// the calls of version 2 of the API are the same calls
+	path = strings.TrimPrefix(path, APIv2Prefix)
+	switch {
+	case path == "/wallet/seeds", path == "/wallet/backup", strings.HasPrefix(path, "/wallet/key/"),
+		path == "/daemon/tokens", strings.HasPrefix(path, "/daemon/tokens/"), path == "/daemon/backup", path == "/daemon/audit",
+		path == "/daemon/profile", path == "/debug/pprof", strings.HasPrefix(path, "/debug/pprof/"),
+		path == "/debug/database", strings.HasPrefix(path, "/debug/database/"):
+		// calls exposing the secrets of the wallet or the internals of the daemon,
+		// or managing the credentials of the API, or auditing their use
+		return APITokenScopeAdmin
+	case method == http.MethodGet,
+		// GraphQL queries of the explorer are posted, but only read the blockchain
+		method == http.MethodPost && path == "/explorer/graphql":
+		return APITokenScopeRead
+	}
+	switch path {
+	case "/wallet/coins", "/wallet/blockstakes", "/wallet/data", "/wallet/transaction",
+		"/wallet/create/transaction", "/wallet/sign", "/wallet/coldsign", "/wallet/signmessage",
+		"/transactionpool/transactions", "/transactionpool/transactionset":
+		return APITokenScopeWalletSpend
+	default:
+		return APITokenScopeAdmin
+	}
+}
+
+// apiTokenContextKey is the key of the API token authenticating a request, stored in its context.
+type apiTokenContextKey struct{}
+
+// APITokenFromRequest returns the API token authenticating the given request, if any.
+func APITokenFromRequest(req *http.Request) (APIToken, bool) {
+	token, ok := req.Context().Value(apiTokenContextKey{}).(APIToken)
+	return token, ok
+}
+
+// RequireAPITokenScopeHandler is middleware that authenticates requests using the API tokens of the given store,
+// given as the password using HTTP basic auth, such that RequirePasswordHandler accepts them.
+// Requests authenticated using a token without the scope required by the API call are refused.
+// Requests not authenticated using a token are passed on as is.
+func RequireAPITokenScopeHandler(h http.Handler, tokens *APITokenStore) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, pass, ok := req.BasicAuth()
+		if !ok || pass == "" {
+			h.ServeHTTP(w, req)
+			return
+		}
+		token, ok := tokens.Authenticate(pass)
+		if !ok {
+			h.ServeHTTP(w, req)
+			return
+		}
+		if scope := APITokenScopeOfCall(req.Method, req.URL.Path); !token.HasScope(scope) {
+			WriteError(w, Error{fmt.Sprintf("API authentication failed: token %s lacks the %s scope", token.ID, scope)},
+				http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), apiTokenContextKey{}, token)))
+	})
+}
+
+type (
+	// APITokensGET contains the tokens returned by a GET call to /daemon/tokens.
+	APITokensGET struct {
+		Tokens []APIToken `json:"tokens"`
+	}
+
+	// APITokensPOST contains the body of a POST call to /daemon/tokens.
+	APITokensPOST struct {
+		Name   string          `json:"name"`
+		Scopes []APITokenScope `json:"scopes"`
+	}
+
+	// APITokensPOSTResp contains the token returned by a POST call to /daemon/tokens,
+	// the secret is to be used as the API password.
+	APITokensPOSTResp struct {
+		Token  APIToken `json:"token"`
+		Secret string   `json:"secret"`
+	}
+)
+
+// RegisterAPITokenHTTPHandlers registers the handlers for the API calls to manage the API tokens.
+func RegisterAPITokenHTTPHandlers(router Router, tokens *APITokenStore, requiredPassword string) {
+	if tokens == nil {
+		panic("no API token store given")
+	}
+	if router == nil {
+		panic("no httprouter Router given")
+	}
+	router.GET("/daemon/tokens", RequirePasswordHandler(NewAPITokensGetHandler(tokens), requiredPassword))
+	router.POST("/daemon/tokens", RequirePasswordHandler(NewAPITokensPostHandler(tokens), requiredPassword))
+	router.POST("/daemon/tokens/:id/revoke", RequirePasswordHandler(NewAPITokenRevokeHandler(tokens), requiredPassword))
+}
+
+// NewAPITokensGetHandler creates a handler to handle the API call to list the API tokens.
+func NewAPITokensGetHandler(tokens *APITokenStore) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		WriteJSON(w, APITokensGET{Tokens: tokens.Tokens()})
+	}
+}
+
+// NewAPITokensPostHandler creates a handler to handle the API call to create an API token.
+func NewAPITokensPostHandler(tokens *APITokenStore) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		var body APITokensPOST
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			WriteError(w, Error{"error decoding the supplied token: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		if len(body.Scopes) == 0 {
+			WriteError(w, Error{ErrAPITokenNoScopes.Error()}, http.StatusBadRequest)
+			return
+		}
+		token, secret, err := tokens.CreateToken(body.Name, body.Scopes)
+		if err != nil {
+			WriteError(w, Error{"error after call to /daemon/tokens: " + err.Error()}, http.StatusInternalServerError)
+			return
+		}
+		WriteJSON(w, APITokensPOSTResp{Token: token, Secret: secret})
+	}
+}
+
+// NewAPITokenRevokeHandler creates a handler to handle the API call to revoke an API token.
+func NewAPITokenRevokeHandler(tokens *APITokenStore) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		err := tokens.RevokeToken(ps.ByName("id"))
+		if err == ErrAPITokenNotFound {
+			WriteError(w, Error{err.Error()}, http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			WriteError(w, Error{"error after call to /daemon/tokens/:id/revoke: " + err.Error()}, http.StatusInternalServerError)
+			return
+		}
+		WriteSuccess(w)
+	}
+}
@@ -0,0 +1,175 @@
+package api
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+func TestAPITokenStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "apitokens")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "sub", APITokensFile)
+
+	store, err := NewAPITokenStore(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := store.CreateToken("none", nil); err != ErrAPITokenNoScopes {
+		t.Fatal("expected a token without scopes to be refused, got:", err)
+	}
+	if _, _, err := store.CreateToken("unknown", []APITokenScope{"root"}); err == nil {
+		t.Fatal("expected a token with an unknown scope to be refused")
+	}
+	monitoring, monitoringSecret, err := store.CreateToken("monitoring", []APITokenScope{APITokenScopeRead})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exchange, exchangeSecret, err := store.CreateToken("exchange", []APITokenScope{APITokenScopeWalletSpend})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if monitoring.HasScope(APITokenScopeWalletSpend) || !exchange.HasScope(APITokenScopeRead) || exchange.HasScope(APITokenScopeAdmin) {
+		t.Fatal("unexpected token scopes")
+	}
+
+	// the tokens are persisted, without their secrets
+	store, err = NewAPITokenStore(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tokens := store.Tokens(); len(tokens) != 2 || tokens[0].ID != monitoring.ID || tokens[1].ID != exchange.ID {
+		t.Fatalf("unexpected persisted tokens: %v", tokens)
+	}
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{monitoringSecret, exchangeSecret} {
+		if token, ok := store.Authenticate(secret); !ok || (token.ID != monitoring.ID && token.ID != exchange.ID) {
+			t.Fatalf("failed to authenticate using secret %s: %v", secret, token)
+		}
+		if bytes.Contains(b, []byte(secret)) {
+			t.Fatal("the secret of a token is persisted")
+		}
+	}
+	for _, secret := range []string{"", "foo", monitoringSecret[2:], flipLastHexChar(monitoringSecret)} {
+		if _, ok := store.Authenticate(secret); ok {
+			t.Fatalf("authenticated using invalid secret %q", secret)
+		}
+	}
+
+	if err := store.RevokeToken(monitoring.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.RevokeToken(monitoring.ID); err != ErrAPITokenNotFound {
+		t.Fatal("expected a revoked token to be unknown, got:", err)
+	}
+	if _, ok := store.Authenticate(monitoringSecret); ok {
+		t.Fatal("authenticated using a revoked token")
+	}
+}
+
+// flipLastHexChar returns the given hex string, with its last character changed.
+func flipLastHexChar(s string) string {
+	if s[len(s)-1] == '0' {
+		return s[:len(s)-1] + "1"
+	}
+	return s[:len(s)-1] + "0"
+}
+
+func TestAPITokenScopeOfCall(t *testing.T) {
+	testCases := []struct {
+		Method, Path string
+		Scope        APITokenScope
+	}{
+		{http.MethodGet, "/wallet", APITokenScopeRead},
+		{http.MethodGet, "/consensus/transactions/abc", APITokenScopeRead},
+		{http.MethodGet, "/wallet/seeds", APITokenScopeAdmin},
+		{http.MethodGet, "/wallet/seeds/", APITokenScopeAdmin},
+		{http.MethodGet, "/wallet/key/0123", APITokenScopeAdmin},
+		{http.MethodGet, "/daemon/tokens", APITokenScopeAdmin},
+		{http.MethodGet, "/daemon/backup", APITokenScopeAdmin},
+		{http.MethodGet, "/debug/pprof/", APITokenScopeAdmin},
+		{http.MethodGet, "/debug/pprof/heap", APITokenScopeAdmin},
+		{http.MethodGet, "/debug/database/consensus/entries", APITokenScopeAdmin},
+		{http.MethodPost, "/daemon/profile", APITokenScopeAdmin},
+		{http.MethodGet, "/daemon/disk", APITokenScopeRead},
+		{http.MethodGet, "/explorer/graphql", APITokenScopeRead},
+		{http.MethodPost, "/explorer/graphql", APITokenScopeRead},
+		{http.MethodPost, APIv2Prefix + "/explorer/graphql", APITokenScopeRead},
+		{http.MethodPost, "/daemon/disk/compact", APITokenScopeAdmin},
+		{http.MethodPost, "/wallet/coins", APITokenScopeWalletSpend},
+		{http.MethodPost, "/transactionpool/transactions", APITokenScopeWalletSpend},
+		{http.MethodPost, "/transactionpool/transactionset", APITokenScopeWalletSpend},
+		{http.MethodPost, "/wallet/unlock", APITokenScopeAdmin},
+		{http.MethodPost, "/daemon/stop", APITokenScopeAdmin},
+		{http.MethodPost, "/gateway/connect/127.0.0.1:23112", APITokenScopeAdmin},
+	}
+	for _, tc := range testCases {
+		if scope := APITokenScopeOfCall(tc.Method, tc.Path); scope != tc.Scope {
+			t.Errorf("%s %s: scope %s != %s", tc.Method, tc.Path, scope, tc.Scope)
+		}
+	}
+}
+
+func TestRequireAPITokenScopeHandler(t *testing.T) {
+	dir, err := ioutil.TempDir("", "apitokens")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store, err := NewAPITokenStore(filepath.Join(dir, APITokensFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, readSecret, err := store.CreateToken("monitoring", []APITokenScope{APITokenScopeRead})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := httprouter.New()
+	ok := func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) { WriteSuccess(w) }
+	router.GET("/wallet", RequirePasswordHandler(ok, "password"))
+	router.POST("/wallet/coins", RequirePasswordHandler(ok, "password"))
+	RegisterAPITokenHTTPHandlers(router, store, "password")
+	server := httptest.NewServer(RequireAPITokenScopeHandler(router, store))
+	defer server.Close()
+
+	testCases := []struct {
+		Method, Path, Password string
+		StatusCode             int
+	}{
+		{http.MethodGet, "/wallet", "", http.StatusUnauthorized},
+		{http.MethodGet, "/wallet", "password", http.StatusNoContent},
+		{http.MethodGet, "/wallet", readSecret, http.StatusNoContent},
+		{http.MethodGet, "/wallet", flipLastHexChar(readSecret), http.StatusUnauthorized},
+		{http.MethodPost, "/wallet/coins", readSecret, http.StatusUnauthorized},
+		{http.MethodPost, "/wallet/coins", "password", http.StatusNoContent},
+		{http.MethodGet, "/daemon/tokens", readSecret, http.StatusUnauthorized},
+		{http.MethodGet, "/daemon/tokens", "password", http.StatusOK},
+	}
+	for _, tc := range testCases {
+		req, err := newRequest(tc.Method, server.URL+tc.Path, "", "", tc.Password)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tc.StatusCode {
+			t.Errorf("%s %s: status %d != %d", tc.Method, tc.Path, resp.StatusCode, tc.StatusCode)
+		}
+	}
+}
@@ -1,8 +1,13 @@
 package api
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"sort"
+	"strconv"
+	"sync"
 
 	"github.com/threefoldtech/rivine/modules"
 	"github.com/threefoldtech/rivine/types"
@@ -14,6 +19,46 @@ type (
 	// TransactionPoolGET contains the fields returned by a GET call to "/transactionpool/transactions".
 	TransactionPoolGET struct {
 		Transactions []types.Transaction `json:"transactions"`
+		// NextCursor identifies the next page of transactions, omitted if there is no next page.
+		NextCursor string `json:"nextcursor,omitempty"`
+	}
+
+	// TransactionPoolGetEntries contains the fields returned by a GET call to "/transactionpool/entries".
+	TransactionPoolGetEntries struct {
+		Transactions []modules.PooledTransaction `json:"transactions"`
+		// NextCursor identifies the next page of transactions, omitted if there is no next page.
+		NextCursor string `json:"nextcursor,omitempty"`
+	}
+
+	// TransactionPoolGetTransaction contains the fields returned by a GET call to "/transactionpool/transactions/:id".
+	TransactionPoolGetTransaction struct {
+		modules.PooledTransaction
+	}
+
+	// TransactionPoolGetStatistics contains the fields returned by a GET call to "/transactionpool/statistics".
+	TransactionPoolGetStatistics struct {
+		modules.TransactionPoolStatistics
+	}
+
+	// TransactionPoolGetFeeHistogram contains the fields returned by a GET call to "/transactionpool/feehistogram".
+	TransactionPoolGetFeeHistogram struct {
+		Histogram []modules.FeeHistogramEntry `json:"histogram"`
+	}
+
+	// TransactionPoolGetFeeEstimate contains the fields returned by a GET call to "/transactionpool/feeestimate".
+	TransactionPoolGetFeeEstimate struct {
+		modules.FeeEstimate
+	}
+
+	// TransactionPoolWaitGET contains the fields returned by a GET call to "/transactionpool/wait",
+	// once its condition is met or it timed out.
+	TransactionPoolWaitGET struct {
+		// Reached is true if the transaction has the awaited status, false if the call timed out.
+		Reached       bool                  `json:"reached"`
+		TransactionID types.TransactionID   `json:"transactionid"`
+		Status        TransactionWaitStatus `json:"status"`
+		// Height is the height of the block the transaction is confirmed in, omitted if it is not confirmed.
+		Height types.BlockHeight `json:"height,omitempty"`
 	}
 
 	// TransactionPoolPOST is the success response for a POST to "/transactionpool/transactions".
@@ -21,6 +66,42 @@ type (
 	TransactionPoolPOST struct {
 		TransactionID types.TransactionID `json:"transactionid"`
 	}
+
+	// TransactionPoolSetPOST contains the transactions posted using a POST call to "/transactionpool/transactionset",
+	// a transaction can spend the outputs of the transactions preceding it.
+	TransactionPoolSetPOST struct {
+		Transactions []types.Transaction `json:"transactions"`
+	}
+
+	// TransactionPoolSetPOSTResp is the response of a POST call to "/transactionpool/transactionset",
+	// containing the result of every posted transaction, in the order they were posted.
+	// The transactions are either all accepted, or all rejected.
+	TransactionPoolSetPOSTResp struct {
+		Accepted bool                            `json:"accepted"`
+		Results  []TransactionPoolSetTransaction `json:"results"`
+		// Message describes why the set is rejected, omitted if it is accepted.
+		Message string `json:"message,omitempty"`
+	}
+
+	// TransactionPoolSetTransaction is the result of a single transaction posted as part of a transaction set.
+	TransactionPoolSetTransaction struct {
+		TransactionID types.TransactionID `json:"transactionid"`
+		// Error describes why the transaction is invalid, omitted if it is not known to be invalid.
+		Error string `json:"error,omitempty"`
+	}
+)
+
+// TransactionWaitStatus is the status of a transaction, as waited for using /transactionpool/wait.
+type TransactionWaitStatus string
+
+const (
+	// TransactionWaitStatusUnknown is the status of a transaction
+	// which is neither in the transaction pool nor confirmed.
+	TransactionWaitStatusUnknown TransactionWaitStatus = "unknown"
+	// TransactionWaitStatusPooled is the status of a transaction in the transaction pool.
+	TransactionWaitStatusPooled TransactionWaitStatus = "pooled"
+	// TransactionWaitStatusConfirmed is the status of a transaction confirmed in a block of the current chain.
+	TransactionWaitStatusConfirmed TransactionWaitStatus = "confirmed"
 )
 
 // RegisterTransactionPoolHTTPHandlers registers the default Rivine handlers for all default Rivine TransactionPool HTTP endpoints.
@@ -36,12 +117,310 @@ func RegisterTransactionPoolHTTPHandlers(router Router, cs modules.ConsensusSet,
 	}
 	router.GET("/transactionpool/transactions", NewTransactionPoolGetTransactionsHandler(cs, tpool))
 	router.POST("/transactionpool/transactions", RequirePasswordHandler(NewTransactionPoolPostTransactionHandler(tpool), requiredPassword))
+	router.POST("/transactionpool/transactionset", RequirePasswordHandler(NewTransactionPoolPostTransactionSetHandler(cs, tpool), requiredPassword))
+	router.GET("/transactionpool/transactions/:id", NewTransactionPoolGetTransactionHandler(tpool))
+	router.GET("/transactionpool/entries", NewTransactionPoolGetEntriesHandler(tpool))
+	router.GET("/transactionpool/statistics", NewTransactionPoolGetStatisticsHandler(tpool))
+	router.GET("/transactionpool/feehistogram", NewTransactionPoolGetFeeHistogramHandler(tpool))
+	router.GET("/transactionpool/feeestimate", NewTransactionPoolGetFeeEstimateHandler(tpool))
+	router.GET("/transactionpool/events", NewTransactionPoolGetEventsHandler(tpool))
+	router.GET("/transactionpool/wait", NewTransactionPoolGetWaitHandler(cs, tpool))
+}
+
+// transactionPoolEventBufferSize is the amount of transaction pool events
+// buffered for a single event stream, before the client is considered too slow.
+const transactionPoolEventBufferSize = 256
+
+// transactionPoolEventStream is a TransactionPoolEventListener
+// buffering the events to be sent to a single client.
+type transactionPoolEventStream struct {
+	events   chan modules.TransactionPoolEvent
+	overflow chan struct{}
+	once     sync.Once
+}
+
+// ReceiveTransactionPoolEvent implements modules.TransactionPoolEventListener.ReceiveTransactionPoolEvent
+func (stream *transactionPoolEventStream) ReceiveTransactionPoolEvent(event modules.TransactionPoolEvent) {
+	select {
+	case stream.events <- event:
+	default:
+		// never block the transaction pool, drop the client instead
+		stream.once.Do(func() { close(stream.overflow) })
+	}
+}
+
+// RegisterTransactionPoolAPIv2Handlers registers the TransactionPool calls of version 2 of the API.
+func RegisterTransactionPoolAPIv2Handlers(v2 *APIv2, cs modules.ConsensusSet, tpool modules.TransactionPool) {
+	if cs == nil {
+		panic("no consensus module given")
+	}
+	if tpool == nil {
+		panic("no transaction pool module given")
+	}
+	v2.Handle(APIv2Route{
+		Method: http.MethodGet, Path: "/transactionpool/transactions", Tag: "transactionpool",
+		Summary:  "list the unconfirmed transactions",
+		Query:    apiv2Query(apiv2ListQuery, map[string]string{"unlockhash": "only list the transactions related to this unlock hash"}),
+		Response: TransactionPoolGET{},
+		Handler:  NewTransactionPoolGetTransactionsHandler(cs, tpool),
+	})
+	v2.Handle(APIv2Route{
+		Method: http.MethodPost, Path: "/transactionpool/transactions", Tag: "transactionpool",
+		Summary:       "submit a transaction",
+		Query:         map[string]string{"priority": "true to accept the transaction with priority"},
+		Request:       types.Transaction{},
+		Response:      TransactionPoolPOST{},
+		Authenticated: true,
+		Handler:       NewTransactionPoolPostTransactionHandler(tpool),
+	})
+	v2.Handle(APIv2Route{
+		Method: http.MethodPost, Path: "/transactionpool/transactionset", Tag: "transactionpool",
+		Summary:       "submit a set of dependent transactions atomically",
+		Query:         map[string]string{"priority": "true to accept the transactions with priority"},
+		Request:       TransactionPoolSetPOST{},
+		Response:      TransactionPoolSetPOSTResp{},
+		Authenticated: true,
+		Handler:       NewTransactionPoolPostTransactionSetHandler(cs, tpool),
+	})
+	v2.Handle(APIv2Route{
+		Method: http.MethodGet, Path: "/transactionpool/transactions/:id", Tag: "transactionpool",
+		Summary:  "get an unconfirmed transaction",
+		Response: TransactionPoolGetTransaction{},
+		Handler:  NewTransactionPoolGetTransactionHandler(tpool),
+	})
+	v2.Handle(APIv2Route{
+		Method: http.MethodGet, Path: "/transactionpool/entries", Tag: "transactionpool",
+		Summary:  "list the unconfirmed transactions with their fee, size, age and dependencies",
+		Query:    apiv2Query(apiv2ListQuery, apiv2HeightRangeQuery),
+		Response: TransactionPoolGetEntries{},
+		Handler:  NewTransactionPoolGetEntriesHandler(tpool),
+	})
+	v2.Handle(APIv2Route{
+		Method: http.MethodGet, Path: "/transactionpool/statistics", Tag: "transactionpool",
+		Summary:  "get aggregate information about the transaction pool",
+		Response: TransactionPoolGetStatistics{},
+		Handler:  NewTransactionPoolGetStatisticsHandler(tpool),
+	})
+	v2.Handle(APIv2Route{
+		Method: http.MethodGet, Path: "/transactionpool/feehistogram", Tag: "transactionpool",
+		Summary:  "get the histogram of the fees of the unconfirmed transactions",
+		Response: TransactionPoolGetFeeHistogram{},
+		Handler:  NewTransactionPoolGetFeeHistogramHandler(tpool),
+	})
+	v2.Handle(APIv2Route{
+		Method: http.MethodGet, Path: "/transactionpool/feeestimate", Tag: "transactionpool",
+		Summary:  "estimate the fee required to get a transaction confirmed",
+		Response: TransactionPoolGetFeeEstimate{},
+		Handler:  NewTransactionPoolGetFeeEstimateHandler(tpool),
+	})
+	v2.Handle(APIv2Route{
+		Method: http.MethodGet, Path: "/transactionpool/wait", Tag: "transactionpool",
+		Summary: "wait until a transaction is pooled or confirmed",
+		Query: map[string]string{
+			"txid":    "the ID of the transaction to wait for, required",
+			"status":  "the status to wait for, pooled or confirmed (default), a confirmed transaction is considered pooled as well",
+			"timeout": "the maximum duration to wait, e.g. 90s, 30s by default and 5m at most",
+		},
+		Response: TransactionPoolWaitGET{},
+		Handler:  NewTransactionPoolGetWaitHandler(cs, tpool),
+	})
+}
+
+// NewTransactionPoolGetEventsHandler creates a handler
+// to handle the API call to stream the transaction pool events over a websocket connection.
+// The connection is closed when the client falls behind by more than transactionPoolEventBufferSize events.
+func NewTransactionPoolGetEventsHandler(tpool modules.TransactionPool) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		ws, err := upgradeWebSocket(w, req)
+		if err != nil {
+			WriteError(w, Error{"failed to open websocket connection: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		defer ws.Close()
+
+		stream := &transactionPoolEventStream{
+			events:   make(chan modules.TransactionPoolEvent, transactionPoolEventBufferSize),
+			overflow: make(chan struct{}),
+		}
+		tpool.TransactionPoolEventSubscribe(stream)
+		defer tpool.TransactionPoolEventUnsubscribe(stream)
+
+		closed := make(chan struct{})
+		go func() {
+			ws.readLoop()
+			close(closed)
+		}()
+		for {
+			select {
+			case event := <-stream.events:
+				if err := ws.WriteJSON(event); err != nil {
+					return
+				}
+			case <-stream.overflow:
+				ws.writeFrame(websocketOpClose, nil)
+				return
+			case <-closed:
+				return
+			}
+		}
+	}
+}
+
+// NewTransactionPoolGetWaitHandler creates a handler
+// to handle the long-polling API call to wait until the transaction of the txid query parameter
+// is pooled or confirmed, as defined by the status query parameter, confirmed by default,
+// such that clients do not have to poll for the confirmation of their transactions.
+// The call returns the status of the transaction once it is reached, or once the timeout expires.
+func NewTransactionPoolGetWaitHandler(cs modules.ConsensusSet, tpool modules.TransactionPool) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		str := req.FormValue("txid")
+		if str == "" {
+			WriteError(w, Error{"no transaction ID given"}, http.StatusBadRequest)
+			return
+		}
+		var id types.TransactionID
+		err := id.LoadString(str)
+		if err != nil {
+			WriteError(w, Error{"invalid transaction ID: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		awaited := TransactionWaitStatus(req.FormValue("status"))
+		switch awaited {
+		case "":
+			awaited = TransactionWaitStatusConfirmed
+		case TransactionWaitStatusPooled, TransactionWaitStatusConfirmed:
+		default:
+			WriteError(w, Error{fmt.Sprintf("invalid status %q: pooled or confirmed is required", awaited)}, http.StatusBadRequest)
+			return
+		}
+		timeout, err := parseWaitTimeout(req)
+		if err != nil {
+			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+			return
+		}
+
+		resp := TransactionPoolWaitGET{TransactionID: id}
+		resp.Reached, err = waitUntil(req, cs, tpool, timeout, func() bool {
+			resp.Status, resp.Height = TransactionWaitStatusUnknown, 0
+			if _, shortID, found := cs.TransactionAtID(id); found {
+				resp.Status, resp.Height = TransactionWaitStatusConfirmed, shortID.BlockHeight()
+				return true
+			}
+			if _, err := tpool.PooledTransaction(id); err == nil {
+				resp.Status = TransactionWaitStatusPooled
+			}
+			return resp.Status == awaited
+		})
+		if err != nil {
+			if req.Context().Err() == nil {
+				WriteError(w, Error{err.Error()}, http.StatusInternalServerError)
+			}
+			return
+		}
+		WriteJSON(w, resp)
+	}
+}
+
+// NewTransactionPoolGetFeeHistogramHandler creates a handler
+// to handle the API call to get a histogram of the fee-per-byte paid by the pooled transactions.
+func NewTransactionPoolGetFeeHistogramHandler(tpool modules.TransactionPool) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		WriteJSON(w, TransactionPoolGetFeeHistogram{Histogram: tpool.FeeHistogram()})
+	}
+}
+
+// NewTransactionPoolGetFeeEstimateHandler creates a handler
+// to handle the API call to estimate the fee-per-byte required to get a transaction included within a few blocks.
+func NewTransactionPoolGetFeeEstimateHandler(tpool modules.TransactionPool) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		WriteJSON(w, TransactionPoolGetFeeEstimate{FeeEstimate: tpool.FeeEstimate()})
+	}
+}
+
+// NewTransactionPoolGetTransactionHandler creates a handler
+// to handle the API call to get a single transaction from the transaction pool.
+func NewTransactionPoolGetTransactionHandler(tpool modules.TransactionPool) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		var id types.TransactionID
+		err := id.LoadString(ps.ByName("id"))
+		if err != nil {
+			WriteError(w, Error{"invalid transaction ID: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		ptxn, err := tpool.PooledTransaction(id)
+		if err == modules.ErrTransactionNotFound {
+			WriteError(w, Error{err.Error()}, http.StatusNoContent)
+			return
+		}
+		if err != nil {
+			WriteError(w, Error{err.Error()}, http.StatusInternalServerError)
+			return
+		}
+		WriteJSON(w, TransactionPoolGetTransaction{PooledTransaction: ptxn})
+	}
+}
+
+// NewTransactionPoolGetEntriesHandler creates a handler
+// to handle the API call to get all transactions from the transaction pool,
+// together with their fee, size, age and dependency information.
+func NewTransactionPoolGetEntriesHandler(tpool modules.TransactionPool) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		opts, err := parseListOptions(req)
+		if err != nil {
+			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+			return
+		}
+		heightRange, err := parseHeightRange(req)
+		if err != nil {
+			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+			return
+		}
+		var entries []modules.PooledTransaction
+		for _, ptxn := range tpool.PooledTransactions() {
+			if heightRange.Contains(ptxn.Height) {
+				entries = append(entries, ptxn)
+			}
+		}
+		// order the entries by the height at which they were added, such that they can be paginated
+		sort.Slice(entries, func(i, j int) bool {
+			if entries[i].Height != entries[j].Height {
+				return entries[i].Height < entries[j].Height
+			}
+			return bytes.Compare(entries[i].ID[:], entries[j].ID[:]) < 0
+		})
+		indices, nextCursor, err := opts.page(len(entries), func(i int) string {
+			return entries[i].ID.String()
+		})
+		if err != nil {
+			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+			return
+		}
+		page := make([]modules.PooledTransaction, 0, len(indices))
+		for _, i := range indices {
+			page = append(page, entries[i])
+		}
+		WriteJSON(w, TransactionPoolGetEntries{Transactions: page, NextCursor: nextCursor})
+	}
+}
+
+// NewTransactionPoolGetStatisticsHandler creates a handler
+// to handle the API call to get aggregate information about the transaction pool.
+func NewTransactionPoolGetStatisticsHandler(tpool modules.TransactionPool) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		WriteJSON(w, TransactionPoolGetStatistics{TransactionPoolStatistics: tpool.Statistics()})
+	}
 }
 
 // NewTransactionPoolGetTransactionsHandler creates a handler
 // to handle the API call to get the transaction pool transactions, filtered or not.
 func NewTransactionPoolGetTransactionsHandler(cs modules.ConsensusSet, tpool modules.TransactionPool) httprouter.Handle {
 	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		opts, err := parseListOptions(req)
+		if err != nil {
+			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+			return
+		}
+
 		// get transactions
 		txns := tpool.TransactionList()
 
@@ -49,14 +428,14 @@ func NewTransactionPoolGetTransactionsHandler(cs modules.ConsensusSet, tpool mod
 		q := req.URL.Query()
 		str := q.Get("unlockhash")
 		if str == "" {
-			// if this parameter is not given, simply return all transactions
-			WriteJSON(w, TransactionPoolGET{Transactions: txns})
+			// if this parameter is not given, simply return (a page of) all transactions
+			writeTransactionPoolTransactionsPage(w, txns, opts)
 			return
 		}
 
 		// parse unlockhash param as an actual UnlockHash
 		var uh types.UnlockHash
-		err := uh.LoadString(str)
+		err = uh.LoadString(str)
 		if err != nil {
 			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 			return
@@ -108,10 +487,25 @@ func NewTransactionPoolGetTransactionsHandler(cs modules.ConsensusSet, tpool mod
 			txns = append(txns[:i], txns[i+1:]...)
 		}
 
-		// return filtered transactions
-		WriteJSON(w, TransactionPoolGET{Transactions: txns})
+		// return (a page of) the filtered transactions
+		writeTransactionPoolTransactionsPage(w, txns, opts)
+	}
+}
+
+// writeTransactionPoolTransactionsPage writes the page of the given transactions defined by the list options.
+func writeTransactionPoolTransactionsPage(w http.ResponseWriter, txns []types.Transaction, opts ListOptions) {
+	indices, nextCursor, err := opts.page(len(txns), func(i int) string {
+		return txns[i].ID().String()
+	})
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 		return
 	}
+	page := make([]types.Transaction, 0, len(indices))
+	for _, i := range indices {
+		page = append(page, txns[i])
+	}
+	WriteJSON(w, TransactionPoolGET{Transactions: page, NextCursor: nextCursor})
 }
 
 func isUnlockHashInCondition(uh types.UnlockHash, co types.UnlockConditionProxy) bool {
@@ -141,10 +535,78 @@ func NewTransactionPoolPostTransactionHandler(tpool modules.TransactionPool) htt
 			WriteError(w, Error{"error decoding the supplied transaction: " + err.Error()}, http.StatusBadRequest)
 			return
 		}
-		if err := tpool.AcceptTransactionSet([]types.Transaction{tx}); err != nil {
+		accept := tpool.AcceptLocalTransactionSet
+		if str := req.URL.Query().Get("priority"); str != "" {
+			priority, err := strconv.ParseBool(str)
+			if err != nil {
+				WriteError(w, Error{"invalid priority flag: " + err.Error()}, http.StatusBadRequest)
+				return
+			}
+			if priority {
+				accept = tpool.AcceptPriorityTransactionSet
+			}
+		}
+		if err := accept([]types.Transaction{tx}); err != nil {
 			WriteError(w, Error{"error after call to /wallet/transactions: " + err.Error()}, http.StatusBadRequest)
 			return
 		}
 		WriteJSON(w, TransactionPoolPOST{TransactionID: tx.ID()})
 	}
 }
+
+// NewTransactionPoolPostTransactionSetHandler creates a handler to handle
+// the API call to post a set of dependent transactions on /transactionpool/transactionset,
+// which are accepted atomically, such that a chain of transactions can be posted without racing the pool.
+// A rejected set is reported using status code 400, with the result of every transaction,
+// identifying the first transaction which is invalid given the transactions preceding it, if any.
+func NewTransactionPoolPostTransactionSetHandler(cs modules.ConsensusSet, tpool modules.TransactionPool) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		var body TransactionPoolSetPOST
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			WriteError(w, Error{"error decoding the supplied transaction set: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		if len(body.Transactions) == 0 {
+			WriteError(w, Error{"no transactions given"}, http.StatusBadRequest)
+			return
+		}
+		accept := tpool.AcceptLocalTransactionSet
+		if str := req.URL.Query().Get("priority"); str != "" {
+			priority, err := strconv.ParseBool(str)
+			if err != nil {
+				WriteError(w, Error{"invalid priority flag: " + err.Error()}, http.StatusBadRequest)
+				return
+			}
+			if priority {
+				accept = tpool.AcceptPriorityTransactionSet
+			}
+		}
+		resp := TransactionPoolSetPOSTResp{
+			Accepted: true,
+			Results:  make([]TransactionPoolSetTransaction, 0, len(body.Transactions)),
+		}
+		for _, txn := range body.Transactions {
+			resp.Results = append(resp.Results, TransactionPoolSetTransaction{TransactionID: txn.ID()})
+		}
+		err := accept(body.Transactions)
+		if err == nil {
+			WriteJSON(w, resp)
+			return
+		}
+
+		resp.Accepted = false
+		resp.Message = "transaction set rejected: " + err.Error()
+		// find the first transaction which is invalid given the transactions preceding it,
+		// the set can be rejected as a whole as well, e.g. for paying too little fees
+		for i := range body.Transactions {
+			if _, err := cs.TryTransactionSet(body.Transactions[:i+1]); err != nil {
+				resp.Results[i].Error = err.Error()
+				resp.Message += fmt.Sprintf(" (transaction %d, %s: %v)", i, resp.Results[i].TransactionID, err)
+				break
+			}
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(resp)
+	}
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// testTransactionPool is a transaction pool accepting transaction sets using a function.
+type testTransactionPool struct {
+	modules.TransactionPool
+	accepted [][]types.Transaction
+	accept   func([]types.Transaction) error
+}
+
+func (tpool *testTransactionPool) AcceptLocalTransactionSet(ts []types.Transaction) error {
+	if err := tpool.accept(ts); err != nil {
+		return err
+	}
+	tpool.accepted = append(tpool.accepted, ts)
+	return nil
+}
+
+// testConsensusSet is a consensus set considering the transactions with arbitrary data invalid.
+type testConsensusSet struct {
+	modules.ConsensusSet
+}
+
+func (cs testConsensusSet) TryTransactionSet(ts []types.Transaction) (modules.ConsensusChange, error) {
+	for _, txn := range ts {
+		if len(txn.ArbitraryData) > 0 {
+			return modules.ConsensusChange{}, errors.New("invalid transaction")
+		}
+	}
+	return modules.ConsensusChange{}, nil
+}
+
+func TestTransactionPoolPostTransactionSet(t *testing.T) {
+	tpool := &testTransactionPool{accept: func(ts []types.Transaction) error {
+		if _, err := (testConsensusSet{}).TryTransactionSet(ts); err != nil {
+			return modules.NewConsensusConflict(err.Error())
+		}
+		return nil
+	}}
+	handler := NewTransactionPoolPostTransactionSetHandler(testConsensusSet{}, tpool)
+	post := func(body string) (int, TransactionPoolSetPOSTResp) {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodPost, "/transactionpool/transactionset", strings.NewReader(body)), httprouter.Params{})
+		var resp TransactionPoolSetPOSTResp
+		if rec.Code == http.StatusOK || rec.Code == http.StatusBadRequest {
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("invalid response %q: %v", rec.Body.String(), err)
+			}
+		}
+		return rec.Code, resp
+	}
+	encode := func(txns ...types.Transaction) string {
+		b, err := json.Marshal(TransactionPoolSetPOST{Transactions: txns})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+	valid := types.Transaction{Version: types.TransactionVersionOne, MinerFees: []types.Currency{types.NewCurrency64(1)}}
+	other := types.Transaction{Version: types.TransactionVersionOne, MinerFees: []types.Currency{types.NewCurrency64(2)}}
+	invalid := types.Transaction{Version: types.TransactionVersionOne, ArbitraryData: []byte("invalid")}
+
+	// a valid set is accepted as a whole
+	code, resp := post(encode(valid, other))
+	if code != http.StatusOK || !resp.Accepted || len(resp.Results) != 2 ||
+		resp.Results[0].TransactionID != valid.ID() || resp.Results[1].TransactionID != other.ID() {
+		t.Fatalf("unexpected response %d: %+v", code, resp)
+	}
+	if len(tpool.accepted) != 1 || len(tpool.accepted[0]) != 2 {
+		t.Fatalf("expected the set to be accepted at once, got %v", tpool.accepted)
+	}
+
+	// an invalid set is rejected as a whole, identifying the invalid transaction
+	code, resp = post(encode(valid, invalid, other))
+	if code != http.StatusBadRequest || resp.Accepted || len(resp.Results) != 3 || resp.Message == "" {
+		t.Fatalf("unexpected response %d: %+v", code, resp)
+	}
+	if resp.Results[0].Error != "" || resp.Results[1].Error == "" || resp.Results[2].Error != "" {
+		t.Errorf("expected only the second transaction to be invalid, got %+v", resp.Results)
+	}
+	if len(tpool.accepted) != 1 {
+		t.Fatal("expected the invalid set not to be accepted")
+	}
+
+	for _, body := range []string{"", "{}", `{"transactions":[]}`} {
+		if code, _ := post(body); code != http.StatusBadRequest {
+			t.Errorf("%q: expected status 400, got %d", body, code)
+		}
+	}
+}
@@ -0,0 +1,317 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// APIv2Prefix is the prefix of the routes of version 2 of the API.
+const APIv2Prefix = "/api/v2"
+
+// The codes of the errors returned by version 2 of the API.
+const (
+	APIv2ErrorCodeBadRequest      = "bad_request"
+	APIv2ErrorCodeUnauthorized    = "unauthorized"
+	APIv2ErrorCodeForbidden       = "forbidden"
+	APIv2ErrorCodeNotFound        = "not_found"
+	APIv2ErrorCodeConflict        = "conflict"
+	APIv2ErrorCodeTooManyRequests = "too_many_requests"
+	APIv2ErrorCodeInternal        = "internal_error"
+	APIv2ErrorCodeUnavailable     = "unavailable"
+)
+
+type (
+	// APIv2Error is the envelope of every error returned by version 2 of the API.
+	APIv2Error struct {
+		Error APIv2ErrorDetails `json:"error"`
+	}
+
+	// APIv2ErrorDetails describes an error returned by version 2 of the API.
+	APIv2ErrorDetails struct {
+		// Code is a stable, machine-readable code of the error, e.g. not_found.
+		Code string `json:"code"`
+		// Status is the HTTP status code of the response.
+		Status int `json:"status"`
+		// Message describes the error in English.
+		Message string `json:"message"`
+	}
+
+	// APIv2Route is a single route of version 2 of the API,
+	// documented in the OpenAPI document of the API.
+	APIv2Route struct {
+		// Method and Path of the route, the path (in httprouter syntax) is relative to APIv2Prefix.
+		Method string
+		Path   string
+		// Summary describes the API call, Tag groups it with related calls.
+		Summary string
+		Tag     string
+		// Query are the optional query parameters of the call, by name, with their description.
+		Query map[string]string
+		// Request is the (zero value of the) type of the JSON body of the call, nil if it has no body.
+		Request interface{}
+		// Response is the (zero value of the) type of the JSON response of the call,
+		// nil if a successful call returns 204 No Content.
+		Response interface{}
+		// Authenticated calls require the API password, or an API token.
+		Authenticated bool
+		// Handler handles the call, errors written using WriteError
+		// are wrapped in an APIv2Error automatically.
+		Handler httprouter.Handle
+	}
+)
+
+// APIv2 collects the routes of version 2 of the API, which are registered to a router
+// together with a route serving the OpenAPI document describing them.
+type APIv2 struct {
+	requiredPassword string
+	routes           []APIv2Route
+}
+
+// NewAPIv2 creates a new, empty, version 2 of the API,
+// of which the authenticated routes require the given password.
+func NewAPIv2(requiredPassword string) *APIv2 {
+	return &APIv2{requiredPassword: requiredPassword}
+}
+
+// Handle adds the given route.
+func (v2 *APIv2) Handle(route APIv2Route) {
+	if route.Handler == nil {
+		panic("no handler given for route " + route.Method + " " + route.Path)
+	}
+	v2.routes = append(v2.routes, route)
+}
+
+// Routes returns all routes, in the order they were added.
+func (v2 *APIv2) Routes() []APIv2Route {
+	return append([]APIv2Route(nil), v2.routes...)
+}
+
+// Register registers all routes to the given router, under the APIv2Prefix,
+// as well as the route serving the OpenAPI document, at /api/v2/openapi.json.
+func (v2 *APIv2) Register(router Router, info OpenAPIInfo) {
+	if router == nil {
+		panic("no httprouter Router given")
+	}
+	for _, route := range v2.routes {
+		handler := route.Handler
+		if route.Authenticated {
+			handler = RequirePasswordHandler(handler, v2.requiredPassword)
+		}
+		handler = apiv2ErrorHandler(handler)
+		switch route.Method {
+		case http.MethodGet:
+			router.GET(APIv2Prefix+route.Path, handler)
+		case http.MethodPost:
+			router.POST(APIv2Prefix+route.Path, handler)
+		default:
+			panic("unsupported method " + route.Method)
+		}
+	}
+	document := v2.OpenAPIDocument(info)
+	router.GET(APIv2Prefix+"/openapi.json", func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		WriteJSON(w, document)
+	})
+}
+
+// OpenAPIDocument generates the OpenAPI document describing all routes,
+// with the schemas of their request and response types generated from the Go types.
+func (v2 *APIv2) OpenAPIDocument(info OpenAPIInfo) OpenAPIDocument {
+	schemas := newOpenAPISchemas()
+	errorSchema := schemas.schemaOf(reflect.TypeOf(APIv2Error{}))
+	errorResponse := OpenAPIResponse{
+		Description: "error",
+		Content:     map[string]OpenAPIMediaType{"application/json": {Schema: errorSchema}},
+	}
+	doc := OpenAPIDocument{
+		OpenAPI: openAPIVersion,
+		Info:    info,
+		Paths:   make(map[string]map[string]*OpenAPIOperation),
+		Components: OpenAPIComponents{
+			SecuritySchemes: map[string]OpenAPISecurityScheme{
+				"basicAuth": {
+					Type:        "http",
+					Scheme:      "basic",
+					Description: "the API password or the secret of an API token as password, the username is ignored",
+				},
+			},
+		},
+	}
+	for _, route := range v2.routes {
+		path, params := openAPIPath(APIv2Prefix + route.Path)
+		op := &OpenAPIOperation{
+			OperationID: openAPIOperationID(route.Method, route.Path),
+			Summary:     route.Summary,
+			Responses:   map[string]OpenAPIResponse{"default": errorResponse},
+		}
+		if route.Tag != "" {
+			op.Tags = []string{route.Tag}
+		}
+		for _, param := range params {
+			op.Parameters = append(op.Parameters, OpenAPIParameter{
+				Name: param, In: "path", Required: true, Schema: &OpenAPISchema{Type: "string"},
+			})
+		}
+		var query []string
+		for name := range route.Query {
+			query = append(query, name)
+		}
+		sort.Strings(query)
+		for _, name := range query {
+			op.Parameters = append(op.Parameters, OpenAPIParameter{
+				Name: name, In: "query", Description: route.Query[name], Schema: &OpenAPISchema{Type: "string"},
+			})
+		}
+		if route.Request != nil {
+			op.RequestBody = &OpenAPIRequestBody{
+				Required: true,
+				Content: map[string]OpenAPIMediaType{
+					"application/json": {Schema: schemas.schemaOf(reflect.TypeOf(route.Request))},
+				},
+			}
+		}
+		if route.Response != nil {
+			op.Responses[strconv.Itoa(http.StatusOK)] = OpenAPIResponse{
+				Description: http.StatusText(http.StatusOK),
+				Content: map[string]OpenAPIMediaType{
+					"application/json": {Schema: schemas.schemaOf(reflect.TypeOf(route.Response))},
+				},
+			}
+		} else {
+			op.Responses[strconv.Itoa(http.StatusNoContent)] = OpenAPIResponse{Description: http.StatusText(http.StatusNoContent)}
+		}
+		if route.Authenticated {
+			op.Security = []map[string][]string{{"basicAuth": {}}}
+		}
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = make(map[string]*OpenAPIOperation)
+		}
+		doc.Paths[path][strings.ToLower(route.Method)] = op
+	}
+	doc.Components.Schemas = schemas.components
+	return doc
+}
+
+// apiv2ErrorCode returns the error code of the given HTTP status code.
+func apiv2ErrorCode(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return APIv2ErrorCodeBadRequest
+	case http.StatusUnauthorized:
+		return APIv2ErrorCodeUnauthorized
+	case http.StatusForbidden:
+		return APIv2ErrorCodeForbidden
+	case http.StatusNotFound:
+		return APIv2ErrorCodeNotFound
+	case http.StatusConflict:
+		return APIv2ErrorCodeConflict
+	case http.StatusTooManyRequests:
+		return APIv2ErrorCodeTooManyRequests
+	case http.StatusServiceUnavailable:
+		return APIv2ErrorCodeUnavailable
+	}
+	if status < http.StatusInternalServerError {
+		return APIv2ErrorCodeBadRequest
+	}
+	return APIv2ErrorCodeInternal
+}
+
+// WriteAPIv2Error writes an error wrapped in the error envelope of version 2 of the API.
+func WriteAPIv2Error(w http.ResponseWriter, message string, status int) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(APIv2Error{Error: APIv2ErrorDetails{ // ignore error, just like WriteError
+		Code:    apiv2ErrorCode(status),
+		Status:  status,
+		Message: message,
+	}})
+}
+
+// apiv2ErrorHandler is middleware that wraps the errors written by the given handler
+// in the error envelope of version 2 of the API.
+func apiv2ErrorHandler(h httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		ew := &apiv2ErrorWriter{ResponseWriter: w}
+		h(ew, req, ps)
+		if ew.status == 0 {
+			if ew.noContent {
+				w.WriteHeader(http.StatusNoContent)
+			}
+			return
+		}
+		var body Error
+		if err := json.Unmarshal(ew.body.Bytes(), &body); err != nil || body.Message == "" {
+			body.Message = strings.TrimSpace(ew.body.String())
+		}
+		if body.Message == "" {
+			body.Message = http.StatusText(ew.status)
+		}
+		WriteAPIv2Error(w, body.Message, ew.status)
+	}
+}
+
+// apiv2ErrorWriter is a response writer which buffers error responses,
+// such that they can be wrapped in the error envelope.
+//
+// Version 1 of the API reports objects which are not found using WriteError
+// with status code 204 (No Content), of which the body is dropped,
+// version 2 reports these using status code 404 (Not Found) instead.
+type apiv2ErrorWriter struct {
+	http.ResponseWriter
+	status    int  // the status code of an error response, 0 otherwise
+	noContent bool // true if status code 204 is written, but not yet passed on
+	body      bytes.Buffer
+}
+
+// WriteHeader implements http.ResponseWriter.WriteHeader
+func (ew *apiv2ErrorWriter) WriteHeader(status int) {
+	switch {
+	case status >= http.StatusBadRequest:
+		ew.status = status
+	case status == http.StatusNoContent:
+		ew.noContent = true
+	default:
+		ew.ResponseWriter.WriteHeader(status)
+	}
+}
+
+// Write implements http.ResponseWriter.Write
+func (ew *apiv2ErrorWriter) Write(b []byte) (int, error) {
+	if ew.noContent && ew.status == 0 && len(b) > 0 {
+		ew.status = http.StatusNotFound
+	}
+	if ew.status != 0 {
+		return ew.body.Write(b)
+	}
+	return ew.ResponseWriter.Write(b)
+}
+
+// The descriptions of the query parameters shared by the calls of version 2 of the API.
+var (
+	apiv2ListQuery = map[string]string{
+		"limit":  "the maximum amount of items returned, all items if 0 or omitted",
+		"cursor": "the next cursor returned as part of the previous page",
+		"order":  "asc (default) or desc",
+	}
+	apiv2HeightRangeQuery = map[string]string{
+		"startheight": "the (inclusive) height of the first block",
+		"endheight":   "the (inclusive) height of the last block",
+	}
+)
+
+// apiv2Query merges the given descriptions of query parameters.
+func apiv2Query(queries ...map[string]string) map[string]string {
+	merged := make(map[string]string)
+	for _, query := range queries {
+		for name, description := range query {
+			merged[name] = description
+		}
+	}
+	return merged
+}
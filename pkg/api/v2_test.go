@@ -0,0 +1,191 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// newTestAPIv2 creates a router serving version 2 of the API with a few test routes.
+func newTestAPIv2() *httprouter.Router {
+	v2 := NewAPIv2("password")
+	v2.Handle(APIv2Route{
+		Method: http.MethodGet, Path: "/consensus", Tag: "consensus",
+		Summary:  "get the consensus",
+		Response: ConsensusGET{},
+		Handler: func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+			WriteJSON(w, ConsensusGET{Height: 42})
+		},
+	})
+	v2.Handle(APIv2Route{
+		Method: http.MethodGet, Path: "/consensus/transactions/:id", Tag: "consensus",
+		Query:    map[string]string{"verbose": "a query parameter"},
+		Response: ConsensusGetTransaction{},
+		Handler: func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+			switch ps.ByName("id") {
+			case "invalid":
+				WriteError(w, Error{"invalid ID"}, http.StatusBadRequest)
+			default:
+				// the way version 1 of the API reports objects which are not found
+				WriteError(w, Error{ErrNotFound.Error()}, http.StatusNoContent)
+			}
+		},
+	})
+	v2.Handle(APIv2Route{
+		Method: http.MethodPost, Path: "/wallet/coins", Tag: "wallet",
+		Request:       WalletCoinsPOST{},
+		Response:      WalletCoinsPOSTResp{},
+		Authenticated: true,
+		Handler: func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+			WriteJSON(w, WalletCoinsPOSTResp{})
+		},
+	})
+	v2.Handle(APIv2Route{
+		Method: http.MethodPost, Path: "/wallet/lock", Tag: "wallet",
+		Authenticated: true,
+		Handler: func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+			WriteSuccess(w)
+		},
+	})
+	router := httprouter.New()
+	v2.Register(router, OpenAPIInfo{Title: "test", Version: "1.0.0"})
+	return router
+}
+
+func TestAPIv2Errors(t *testing.T) {
+	router := newTestAPIv2()
+	for _, test := range []struct {
+		method, path string
+		password     string
+		status       int
+		code         string
+	}{
+		{http.MethodGet, "/api/v2/consensus", "", http.StatusOK, ""},
+		{http.MethodGet, "/api/v2/consensus/transactions/invalid", "", http.StatusBadRequest, APIv2ErrorCodeBadRequest},
+		{http.MethodGet, "/api/v2/consensus/transactions/unknown", "", http.StatusNotFound, APIv2ErrorCodeNotFound},
+		{http.MethodPost, "/api/v2/wallet/coins", "", http.StatusUnauthorized, APIv2ErrorCodeUnauthorized},
+		{http.MethodPost, "/api/v2/wallet/coins", "password", http.StatusOK, ""},
+		{http.MethodPost, "/api/v2/wallet/lock", "password", http.StatusNoContent, ""},
+	} {
+		req := httptest.NewRequest(test.method, test.path, nil)
+		if test.password != "" {
+			req.SetBasicAuth("", test.password)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != test.status {
+			t.Errorf("%s %s: expected status %d, got %d", test.method, test.path, test.status, rec.Code)
+			continue
+		}
+		if test.code == "" {
+			continue
+		}
+		var envelope APIv2Error
+		if err := json.Unmarshal(rec.Body.Bytes(), &envelope); err != nil {
+			t.Errorf("%s %s: invalid error envelope %q: %v", test.method, test.path, rec.Body.String(), err)
+			continue
+		}
+		if envelope.Error.Code != test.code || envelope.Error.Status != test.status || envelope.Error.Message == "" {
+			t.Errorf("%s %s: unexpected error %+v", test.method, test.path, envelope.Error)
+		}
+	}
+}
+
+func TestAPIv2OpenAPIDocument(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newTestAPIv2().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v2/openapi.json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d", rec.Code)
+	}
+	var doc OpenAPIDocument
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.OpenAPI != openAPIVersion || doc.Info.Title != "test" {
+		t.Errorf("unexpected header: %s %+v", doc.OpenAPI, doc.Info)
+	}
+
+	op := doc.Paths["/api/v2/consensus/transactions/{id}"]["get"]
+	if op == nil {
+		t.Fatalf("expected an operation for the transaction route, got paths %v", doc.Paths)
+	}
+	if op.OperationID != "getConsensusTransactionsId" || len(op.Parameters) != 2 ||
+		op.Parameters[0].Name != "id" || op.Parameters[0].In != "path" ||
+		op.Parameters[1].Name != "verbose" || op.Parameters[1].In != "query" {
+		t.Errorf("unexpected operation: %+v", op)
+	}
+	// the embedded transaction defines the JSON encoding of the response
+	if schema := op.Responses["200"].Content["application/json"].Schema; schema.Type != "object" || schema.Description != "api.ConsensusGetTransaction" {
+		t.Errorf("unexpected response schema: %+v", schema)
+	}
+	if op.Responses["default"].Content["application/json"].Schema.Ref != "#/components/schemas/APIv2Error" {
+		t.Errorf("unexpected error response: %+v", op.Responses["default"])
+	}
+
+	op = doc.Paths["/api/v2/wallet/lock"]["post"]
+	if op == nil || len(op.Security) != 1 || op.RequestBody != nil {
+		t.Fatalf("unexpected operation: %+v", op)
+	}
+	if _, ok := op.Responses["204"]; !ok {
+		t.Errorf("expected a 204 response, got %+v", op.Responses)
+	}
+
+	// the schemas are generated from the Go types
+	schema := doc.Components.Schemas["ConsensusGET"]
+	if schema == nil {
+		t.Fatal("expected a ConsensusGET schema")
+	}
+	for name, typ := range map[string]string{"synced": "boolean", "height": "integer", "blockspersecond": "number"} {
+		if property := schema.Properties[name]; property == nil || property.Type != typ {
+			t.Errorf("expected property %s of type %s, got %+v", name, typ, property)
+		}
+	}
+	if schema := doc.Components.Schemas["CoinOutput"]; schema == nil || schema.Properties["value"].Type != "string" {
+		t.Errorf("expected currencies to be described as strings, got %+v", schema)
+	}
+}
+
+func TestOpenAPISchemaOf(t *testing.T) {
+	type embedded struct {
+		Foo string `json:"foo"`
+		Bar int    `json:"bar"`
+	}
+	type object struct {
+		embedded
+		Bar        string `json:"bar"`
+		Ignored    bool   `json:"-"`
+		Untagged   []byte
+		Values     map[string]uint64 `json:"values,omitempty"`
+		unexported int
+	}
+	schemas := newOpenAPISchemas()
+	ref := schemas.schemaOf(reflect.TypeOf(&object{}))
+	if ref.Ref != "#/components/schemas/object" {
+		t.Fatalf("expected a reference to the object schema, got %+v", ref)
+	}
+	schema := schemas.components["object"]
+	if len(schema.Properties) != 4 {
+		t.Errorf("expected 4 properties, got %v", schema.Properties)
+	}
+	for name, typ := range map[string]string{"foo": "string", "bar": "string", "Untagged": "string", "values": "object"} {
+		if property := schema.Properties[name]; property == nil || property.Type != typ {
+			t.Errorf("expected property %s of type %s, got %+v", name, typ, property)
+		}
+	}
+	if property := schema.Properties["values"].AdditionalProperties; property.Type != "integer" || property.Format != "int64" {
+		t.Errorf("unexpected map value schema %+v", property)
+	}
+}
+
+func TestAPIv2TokenScopes(t *testing.T) {
+	if scope := APITokenScopeOfCall(http.MethodPost, APIv2Prefix+"/wallet/coins"); scope != APITokenScopeWalletSpend {
+		t.Errorf("unexpected scope %s", scope)
+	}
+	if !IsExpensiveAPICall(http.MethodGet, APIv2Prefix+"/wallet/transactions") {
+		t.Error("expected the version 2 call to be expensive as well")
+	}
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/threefoldtech/rivine/modules"
+)
+
+const (
+	// defaultWaitTimeout is the duration a long-polling call waits for its condition by default.
+	defaultWaitTimeout = 30 * time.Second
+	// maxWaitTimeout is the maximum duration a long-polling call can wait for its condition.
+	maxWaitTimeout = 5 * time.Minute
+)
+
+// parseWaitTimeout parses the optional timeout query parameter of a long-polling call,
+// a duration (e.g. 90s or 2m) of at most maxWaitTimeout, defaultWaitTimeout if not given.
+func parseWaitTimeout(req *http.Request) (time.Duration, error) {
+	str := req.FormValue("timeout")
+	if str == "" {
+		return defaultWaitTimeout, nil
+	}
+	timeout, err := time.ParseDuration(str)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timeout: %v", err)
+	}
+	if timeout < 0 || timeout > maxWaitTimeout {
+		return 0, fmt.Errorf("invalid timeout %v: has to be between 0 and %v", timeout, maxWaitTimeout)
+	}
+	return timeout, nil
+}
+
+// waitNotifier is a ConsensusSetSubscriber and TransactionPoolEventListener
+// which wakes up a single long-polling call on every change, such that it can check its condition.
+type waitNotifier struct {
+	changed chan struct{}
+}
+
+// waitNotifierSubscribeOptions define how the consensus set dispatches its changes to a waitNotifier.
+// A notifier only needs to know that the chain changed since its condition was last checked,
+// and never needs to hold up block acceptance: it is subscribed asynchronously,
+// with room for a single pending change, the oldest pending change being dropped in favour of a new one.
+var waitNotifierSubscribeOptions = modules.AsyncSubscribeOptions{
+	QueueSize:      1,
+	OverflowPolicy: modules.SubscriberOverflowDropOldest,
+}
+
+// notify wakes up the waiting call, without ever blocking the notifying module.
+func (n *waitNotifier) notify() {
+	select {
+	case n.changed <- struct{}{}:
+	default:
+	}
+}
+
+// ProcessConsensusChange implements modules.ConsensusSetSubscriber.ProcessConsensusChange
+func (n *waitNotifier) ProcessConsensusChange(modules.ConsensusChange) {
+	n.notify()
+}
+
+// ReceiveTransactionPoolEvent implements modules.TransactionPoolEventListener.ReceiveTransactionPoolEvent
+func (n *waitNotifier) ReceiveTransactionPoolEvent(modules.TransactionPoolEvent) {
+	n.notify()
+}
+
+// waitUntil blocks until the given condition is met, checking it on every change
+// of the given consensus set and transaction pool (either can be nil),
+// for at most the given duration, or until the client disconnects.
+// It returns whether or not the condition is met.
+func waitUntil(req *http.Request, cs modules.ConsensusSet, tpool modules.TransactionPool, timeout time.Duration, condition func() bool) (bool, error) {
+	n := &waitNotifier{changed: make(chan struct{}, 1)}
+	done := req.Context().Done()
+	// subscribe prior to checking the condition, such that no change is missed
+	if cs != nil {
+		err := cs.ConsensusSetSubscribeAsync(n, modules.ConsensusChangeRecent, done, waitNotifierSubscribeOptions)
+		if err != nil {
+			return false, err
+		}
+		defer cs.Unsubscribe(n)
+	}
+	if tpool != nil {
+		tpool.TransactionPoolEventSubscribe(n)
+		defer tpool.TransactionPoolEventUnsubscribe(n)
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		if condition() {
+			return true, nil
+		}
+		select {
+		case <-n.changed:
+		case <-timer.C:
+			return condition(), nil
+		case <-done:
+			return false, req.Context().Err()
+		}
+	}
+}
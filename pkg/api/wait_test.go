@@ -0,0 +1,181 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// testWaitChain is a consensus set of which the state, and the state of its transaction pool,
+// is changed by the test, notifying the subscribers of long-polling calls.
+type testWaitChain struct {
+	modules.ConsensusSet
+
+	mu          sync.Mutex
+	height      types.BlockHeight
+	pooled      map[types.TransactionID]bool
+	confirmed   map[types.TransactionID]types.BlockHeight
+	subscribers map[modules.ConsensusSetSubscriber]bool
+	subscribed  chan struct{}
+}
+
+func newTestWaitChain() *testWaitChain {
+	return &testWaitChain{
+		pooled:      make(map[types.TransactionID]bool),
+		confirmed:   make(map[types.TransactionID]types.BlockHeight),
+		subscribers: make(map[modules.ConsensusSetSubscriber]bool),
+		subscribed:  make(chan struct{}, 16),
+	}
+}
+
+func (c *testWaitChain) ConsensusSetSubscribeAsync(s modules.ConsensusSetSubscriber, _ modules.ConsensusChangeID, _ <-chan struct{}, opts modules.AsyncSubscribeOptions) error {
+	if opts != waitNotifierSubscribeOptions {
+		return errors.New("long-polling calls have to subscribe using waitNotifierSubscribeOptions")
+	}
+	c.mu.Lock()
+	c.subscribers[s] = true
+	c.mu.Unlock()
+	c.subscribed <- struct{}{}
+	return nil
+}
+
+func (c *testWaitChain) Unsubscribe(s modules.ConsensusSetSubscriber) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.subscribers, s)
+}
+
+func (c *testWaitChain) Height() types.BlockHeight {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.height
+}
+
+func (c *testWaitChain) CurrentBlock() types.Block {
+	return types.Block{Timestamp: types.Timestamp(c.Height())}
+}
+
+func (c *testWaitChain) TransactionAtID(id types.TransactionID) (types.Transaction, types.TransactionShortID, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	height, ok := c.confirmed[id]
+	return types.Transaction{}, types.NewTransactionShortID(height, 0), ok
+}
+
+// applyBlock adds a block, confirming the given transactions, and notifies the subscribers.
+func (c *testWaitChain) applyBlock(ids ...types.TransactionID) {
+	c.mu.Lock()
+	c.height++
+	for _, id := range ids {
+		delete(c.pooled, id)
+		c.confirmed[id] = c.height
+	}
+	subscribers := make([]modules.ConsensusSetSubscriber, 0, len(c.subscribers))
+	for s := range c.subscribers {
+		subscribers = append(subscribers, s)
+	}
+	c.mu.Unlock()
+	for _, s := range subscribers {
+		s.ProcessConsensusChange(modules.ConsensusChange{})
+	}
+}
+
+// testWaitTransactionPool is the transaction pool of a testWaitChain.
+type testWaitTransactionPool struct {
+	modules.TransactionPool
+	chain *testWaitChain
+}
+
+func (tpool testWaitTransactionPool) TransactionPoolEventSubscribe(modules.TransactionPoolEventListener) {
+}
+func (tpool testWaitTransactionPool) TransactionPoolEventUnsubscribe(modules.TransactionPoolEventListener) {
+}
+
+func (tpool testWaitTransactionPool) PooledTransaction(id types.TransactionID) (modules.PooledTransaction, error) {
+	tpool.chain.mu.Lock()
+	defer tpool.chain.mu.Unlock()
+	if !tpool.chain.pooled[id] {
+		return modules.PooledTransaction{}, modules.ErrTransactionNotFound
+	}
+	return modules.PooledTransaction{ID: id}, nil
+}
+
+func TestWaitHandlers(t *testing.T) {
+	chain := newTestWaitChain()
+	chain.height = 10
+	get := func(handler httprouter.Handle, url string, resp interface{}) int {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodGet, url, nil), httprouter.Params{})
+		if rec.Code == http.StatusOK {
+			if err := json.Unmarshal(rec.Body.Bytes(), resp); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return rec.Code
+	}
+	// getAsync makes the call, applying a block once a call subscribed
+	getAsync := func(handler httprouter.Handle, url string, resp interface{}, ids ...types.TransactionID) int {
+		go func() {
+			<-chain.subscribed
+			chain.applyBlock(ids...)
+		}()
+		return get(handler, url, resp)
+	}
+
+	consensusWait := NewConsensusGetWaitHandler(chain)
+	var cresp ConsensusWaitGET
+	if code := get(consensusWait, "/consensus/wait?height=10", &cresp); code != http.StatusOK || !cresp.Reached || cresp.Height != 10 {
+		t.Fatalf("expected a reached height to return immediately: %d, %+v", code, cresp)
+	}
+	tip := cresp.CurrentBlock
+	if code := getAsync(consensusWait, "/consensus/wait?currentblock="+tip.String(), &cresp); code != http.StatusOK ||
+		!cresp.Reached || cresp.Height != 11 || cresp.CurrentBlock == tip {
+		t.Fatalf("expected to wait for the tip to change: %d, %+v", code, cresp)
+	}
+	if code := getAsync(consensusWait, "/consensus/wait?height=12", &cresp); code != http.StatusOK || !cresp.Reached || cresp.Height != 12 {
+		t.Fatalf("expected to wait for the height: %d, %+v", code, cresp)
+	}
+	start := time.Now()
+	if code := get(consensusWait, "/consensus/wait?height=20&timeout=50ms", &cresp); code != http.StatusOK || cresp.Reached ||
+		cresp.Height != 12 || time.Since(start) < 50*time.Millisecond {
+		t.Fatalf("expected the call to time out: %d, %+v", code, cresp)
+	}
+	for _, url := range []string{"/consensus/wait", "/consensus/wait?height=foo", "/consensus/wait?height=20&timeout=1h"} {
+		if code := get(consensusWait, url, &cresp); code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", url, code)
+		}
+	}
+
+	tpoolWait := NewTransactionPoolGetWaitHandler(chain, testWaitTransactionPool{chain: chain})
+	id := types.TransactionID{1}
+	var tresp TransactionPoolWaitGET
+	if code := get(tpoolWait, "/transactionpool/wait?timeout=0s&txid="+id.String(), &tresp); code != http.StatusOK ||
+		tresp.Reached || tresp.Status != TransactionWaitStatusUnknown {
+		t.Fatalf("expected an unknown transaction: %d, %+v", code, tresp)
+	}
+	chain.mu.Lock()
+	chain.pooled[id] = true
+	chain.mu.Unlock()
+	if code := get(tpoolWait, "/transactionpool/wait?status=pooled&txid="+id.String(), &tresp); code != http.StatusOK ||
+		!tresp.Reached || tresp.Status != TransactionWaitStatusPooled {
+		t.Fatalf("expected a pooled transaction: %d, %+v", code, tresp)
+	}
+	if code := getAsync(tpoolWait, "/transactionpool/wait?txid="+id.String(), &tresp, id); code != http.StatusOK ||
+		!tresp.Reached || tresp.Status != TransactionWaitStatusConfirmed || tresp.Height != 13 {
+		t.Fatalf("expected to wait for the confirmation: %d, %+v", code, tresp)
+	}
+	for _, url := range []string{"/transactionpool/wait", "/transactionpool/wait?txid=foo", "/transactionpool/wait?status=foo&txid=" + id.String()} {
+		if code := get(tpoolWait, url, &tresp); code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", url, code)
+		}
+	}
+}
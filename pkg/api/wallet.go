@@ -3,6 +3,7 @@ package api
 import (
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"path/filepath"
 	"strconv"
@@ -85,6 +86,8 @@ type (
 	WalletCoinsPOST struct {
 		CoinOutputs []types.CoinOutput `json:"coinoutputs`
 		Data        []byte             `json:"data,omitempty"`
+		// CoinControl optionally defines the outputs to spend and the change address
+		CoinControl modules.CoinControl `json:"coincontrol"`
 	}
 	// WalletCoinsPOSTResp Resp contains the ID of the transaction
 	// that was created as a result of a POST call to /wallet/coins.
@@ -97,6 +100,8 @@ type (
 	WalletBlockStakesPOST struct {
 		BlockStakeOutputs []types.BlockStakeOutput `json:"blockstakeoutputs`
 		Data              []byte                   `json:"data,omitempty"`
+		// CoinControl optionally defines the outputs to spend and the change address
+		CoinControl modules.CoinControl `json:"coincontrol"`
 	}
 	// WalletBlockStakesPOSTResp Resp contains the ID of the transaction
 	// that was created as a result of a POST call to /wallet/blockstakes.
@@ -104,6 +109,17 @@ type (
 		TransactionID types.TransactionID `json:"transactionids"`
 	}
 
+	// WalletWatchGET contains the addresses watched by the wallet.
+	WalletWatchGET struct {
+		Addresses []modules.WatchedAddress `json:"addresses"`
+	}
+
+	// WalletWatchPOST is given by the user
+	// to indicate which addresses to add to or remove from the watched addresses.
+	WalletWatchPOST struct {
+		Addresses []types.UnlockHash `json:"addresses"`
+	}
+
 	// WalletSeedsGET contains the seeds used by the wallet.
 	WalletSeedsGET struct {
 		PrimarySeed        string   `json:"primaryseed"`
@@ -118,6 +134,17 @@ type (
 		SecretKey          types.ByteSlice `json:"secretkey"`
 	}
 
+	// WalletSignMessagePOST contains the body of a POST call to /wallet/signmessage.
+	WalletSignMessagePOST struct {
+		Address types.UnlockHash `json:"address"`
+		Message string           `json:"message"`
+	}
+
+	// WalletSignMessagePOSTResp contains the signature returned by a POST call to /wallet/signmessage.
+	WalletSignMessagePOSTResp struct {
+		Signature types.MessageSignature `json:"signature"`
+	}
+
 	// WalletTransactionGETid contains the transaction returned by a call to
 	// /wallet/transaction/$(id)
 	WalletTransactionGETid struct {
@@ -129,6 +156,9 @@ type (
 	WalletTransactionsGET struct {
 		ConfirmedTransactions   []modules.ProcessedTransaction `json:"confirmedtransactions"`
 		UnconfirmedTransactions []modules.ProcessedTransaction `json:"unconfirmedtransactions"`
+		// NextCursor identifies the next page of confirmed transactions,
+		// omitted if there is no next page.
+		NextCursor string `json:"nextcursor,omitempty"`
 	}
 
 	// WalletTransactionsGETaddr contains the set of wallet transactions
@@ -137,6 +167,9 @@ type (
 	WalletTransactionsGETaddr struct {
 		ConfirmedTransactions   []modules.ProcessedTransaction `json:"confirmedtransactions"`
 		UnconfirmedTransactions []modules.ProcessedTransaction `json:"unconfirmedtransactions"`
+		// NextCursor identifies the next page of confirmed transactions,
+		// omitted if there is no next page.
+		NextCursor string `json:"nextcursor,omitempty"`
 	}
 
 	// WalletListUnlockedGET contains the set of unspent, unlocked coin
@@ -213,6 +246,112 @@ func RegisterWalletHTTPHandlers(router Router, wallet modules.Wallet, requiredPa
 	router.GET("/wallet/locked", RequirePasswordHandler(NewWalletListLockedHandler(wallet), requiredPassword))
 	router.POST("/wallet/create/transaction", RequirePasswordHandler(NewWalletCreateTransactionHandler(wallet), requiredPassword))
 	router.POST("/wallet/sign", RequirePasswordHandler(NewWalletSignHandler(wallet), requiredPassword))
+	router.POST("/wallet/coldsign", RequirePasswordHandler(NewWalletColdSignHandler(wallet), requiredPassword))
+	router.POST("/wallet/signmessage", RequirePasswordHandler(NewWalletSignMessageHandler(wallet), requiredPassword))
+	router.GET("/wallet/watch", RequirePasswordHandler(NewWalletWatchHandler(wallet), requiredPassword))
+	router.POST("/wallet/watch/add", RequirePasswordHandler(NewWalletWatchAddHandler(wallet), requiredPassword))
+	router.POST("/wallet/watch/remove", RequirePasswordHandler(NewWalletWatchRemoveHandler(wallet), requiredPassword))
+}
+
+// RegisterWalletAPIv2Handlers registers the Wallet calls of version 2 of the API.
+// Calls taking form values, such as the calls initializing or unlocking the wallet,
+// are only available in version 1 of the API.
+func RegisterWalletAPIv2Handlers(v2 *APIv2, wallet modules.Wallet) {
+	if wallet == nil {
+		panic("no wallet module given")
+	}
+	v2.Handle(APIv2Route{
+		Method: http.MethodGet, Path: "/wallet", Tag: "wallet",
+		Summary:       "get the state and balances of the wallet",
+		Response:      WalletGET{},
+		Authenticated: true,
+		Handler:       NewWalletRootHandler(wallet),
+	})
+	v2.Handle(APIv2Route{
+		Method: http.MethodGet, Path: "/wallet/address", Tag: "wallet",
+		Summary:       "generate a new address",
+		Response:      WalletAddressGET{},
+		Authenticated: true,
+		Handler:       NewWalletAddressHandler(wallet),
+	})
+	v2.Handle(APIv2Route{
+		Method: http.MethodGet, Path: "/wallet/addresses", Tag: "wallet",
+		Summary:       "list the addresses of the wallet",
+		Response:      WalletAddressesGET{},
+		Authenticated: true,
+		Handler:       NewWalletAddressesHandler(wallet),
+	})
+	v2.Handle(APIv2Route{
+		Method: http.MethodPost, Path: "/wallet/lock", Tag: "wallet",
+		Summary:       "lock the wallet",
+		Authenticated: true,
+		Handler:       NewWalletLockHandler(wallet),
+	})
+	v2.Handle(APIv2Route{
+		Method: http.MethodPost, Path: "/wallet/transaction", Tag: "wallet",
+		Summary:       "create and submit a transaction sending coins to a condition",
+		Request:       WalletTransactionPOST{},
+		Response:      WalletTransactionPOSTResponse{},
+		Authenticated: true,
+		Handler:       NewWalletTransactionCreateHandler(wallet),
+	})
+	v2.Handle(APIv2Route{
+		Method: http.MethodPost, Path: "/wallet/coins", Tag: "wallet",
+		Summary:       "send coins",
+		Request:       WalletCoinsPOST{},
+		Response:      WalletCoinsPOSTResp{},
+		Authenticated: true,
+		Handler:       NewWalletCoinsHandler(wallet),
+	})
+	v2.Handle(APIv2Route{
+		Method: http.MethodPost, Path: "/wallet/blockstakes", Tag: "wallet",
+		Summary:       "send blockstakes",
+		Request:       WalletBlockStakesPOST{},
+		Response:      WalletBlockStakesPOSTResp{},
+		Authenticated: true,
+		Handler:       NewWalletBlockStakesHandler(wallet),
+	})
+	v2.Handle(APIv2Route{
+		Method: http.MethodGet, Path: "/wallet/transaction/:id", Tag: "wallet",
+		Summary:  "get a transaction of the wallet",
+		Response: WalletTransactionGETid{},
+		Handler:  NewWalletTransactionHandler(wallet),
+	})
+	v2.Handle(APIv2Route{
+		Method: http.MethodGet, Path: "/wallet/transactions", Tag: "wallet",
+		Summary:  "list the transactions of the wallet",
+		Query:    apiv2Query(apiv2ListQuery, apiv2HeightRangeQuery, map[string]string{"direction": "incoming or outgoing"}),
+		Response: WalletTransactionsGET{},
+		Handler:  NewWalletTransactionsHandler(wallet),
+	})
+	v2.Handle(APIv2Route{
+		Method: http.MethodGet, Path: "/wallet/transactions/:addr", Tag: "wallet",
+		Summary:  "list the transactions of the wallet related to an address",
+		Query:    apiv2Query(apiv2ListQuery, apiv2HeightRangeQuery, map[string]string{"direction": "incoming or outgoing"}),
+		Response: WalletTransactionsGETaddr{},
+		Handler:  NewWalletTransactionsAddrHandler(wallet),
+	})
+	v2.Handle(APIv2Route{
+		Method: http.MethodGet, Path: "/wallet/watch", Tag: "wallet",
+		Summary:       "list the watched addresses",
+		Response:      WalletWatchGET{},
+		Authenticated: true,
+		Handler:       NewWalletWatchHandler(wallet),
+	})
+	v2.Handle(APIv2Route{
+		Method: http.MethodPost, Path: "/wallet/watch/add", Tag: "wallet",
+		Summary:       "watch addresses",
+		Request:       WalletWatchPOST{},
+		Authenticated: true,
+		Handler:       NewWalletWatchAddHandler(wallet),
+	})
+	v2.Handle(APIv2Route{
+		Method: http.MethodPost, Path: "/wallet/watch/remove", Tag: "wallet",
+		Summary:       "stop watching addresses",
+		Request:       WalletWatchPOST{},
+		Authenticated: true,
+		Handler:       NewWalletWatchRemoveHandler(wallet),
+	})
 }
 
 // NewWalletRootHandler creates a handler to handle API calls to /wallet.
@@ -532,7 +671,7 @@ func NewWalletCoinsHandler(wallet modules.Wallet) httprouter.Handle {
 			WriteError(w, Error{"error decoding the supplied coin outputs: " + err.Error()}, http.StatusBadRequest)
 			return
 		}
-		tx, err := wallet.SendOutputs(body.CoinOutputs, nil, body.Data)
+		tx, err := wallet.SendOutputsWithCoinControl(body.CoinOutputs, nil, body.Data, body.CoinControl)
 		if err != nil {
 			WriteError(w, Error{"error after call to /wallet/coins: " + err.Error()}, walletErrorToHTTPStatus(err))
 			return
@@ -551,7 +690,7 @@ func NewWalletBlockStakesHandler(wallet modules.Wallet) httprouter.Handle {
 			WriteError(w, Error{"error decoding the supplied blockstake outputs: " + err.Error()}, http.StatusBadRequest)
 			return
 		}
-		tx, err := wallet.SendOutputs(nil, body.BlockStakeOutputs, body.Data)
+		tx, err := wallet.SendOutputsWithCoinControl(nil, body.BlockStakeOutputs, body.Data, body.CoinControl)
 		if err != nil {
 			WriteError(w, Error{"error after call to /wallet/blockstakes: " + err.Error()}, walletErrorToHTTPStatus(err))
 			return
@@ -645,10 +784,16 @@ func NewWalletTransactionsHandler(wallet modules.Wallet) httprouter.Handle {
 			WriteError(w, Error{"error after call to /wallet/transactions: " + err.Error()}, walletErrorToHTTPStatus(err))
 			return
 		}
+		confirmedTxns, unconfirmedTxns, nextCursor, err := listWalletTransactions(req, confirmedTxns, unconfirmedTxns, HeightRange{})
+		if err != nil {
+			WriteError(w, Error{"error after call to /wallet/transactions: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
 
 		WriteJSON(w, WalletTransactionsGET{
 			ConfirmedTransactions:   confirmedTxns,
 			UnconfirmedTransactions: unconfirmedTxns,
+			NextCursor:              nextCursor,
 		})
 	}
 }
@@ -675,13 +820,90 @@ func NewWalletTransactionsAddrHandler(wallet modules.Wallet) httprouter.Handle {
 			WriteError(w, Error{"error after call to /wallet/transactions: " + err.Error()}, walletErrorToHTTPStatus(err))
 			return
 		}
+		heightRange, err := parseHeightRange(req)
+		if err != nil {
+			WriteError(w, Error{"error after call to /wallet/transactions: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		confirmedATs, unconfirmedATs, nextCursor, err := listWalletTransactions(req, confirmedATs, unconfirmedATs, heightRange)
+		if err != nil {
+			WriteError(w, Error{"error after call to /wallet/transactions: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
 		WriteJSON(w, WalletTransactionsGETaddr{
 			ConfirmedTransactions:   confirmedATs,
 			UnconfirmedTransactions: unconfirmedATs,
+			NextCursor:              nextCursor,
 		})
 	}
 }
 
+// WalletTransactionDirection is used to filter wallet transactions,
+// relative to the wallet.
+type WalletTransactionDirection string
+
+// The directions of wallet transactions.
+const (
+	// WalletTransactionIncoming are transactions not funded by the wallet.
+	WalletTransactionIncoming WalletTransactionDirection = "incoming"
+	// WalletTransactionOutgoing are transactions funded (in part) by the wallet.
+	WalletTransactionOutgoing WalletTransactionDirection = "outgoing"
+)
+
+// Matches returns true if the given transaction has this direction.
+func (direction WalletTransactionDirection) Matches(pt modules.ProcessedTransaction) bool {
+	outgoing := false
+	for _, input := range pt.Inputs {
+		if input.WalletAddress {
+			outgoing = true
+			break
+		}
+	}
+	return outgoing == (direction == WalletTransactionOutgoing)
+}
+
+// listWalletTransactions filters the given wallet transactions using the optional direction
+// of the request and the given height range, returning the requested page of confirmed transactions.
+// The unconfirmed transactions are only filtered by direction, and are never paginated.
+func listWalletTransactions(req *http.Request, confirmed, unconfirmed []modules.ProcessedTransaction, heightRange HeightRange) ([]modules.ProcessedTransaction, []modules.ProcessedTransaction, string, error) {
+	opts, err := parseListOptions(req)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	direction := WalletTransactionDirection(req.FormValue("direction"))
+	switch direction {
+	case "", WalletTransactionIncoming, WalletTransactionOutgoing:
+	default:
+		return nil, nil, "", fmt.Errorf("invalid direction %q: expected %s or %s", direction, WalletTransactionIncoming, WalletTransactionOutgoing)
+	}
+	filter := func(txns []modules.ProcessedTransaction, checkHeight bool) []modules.ProcessedTransaction {
+		filtered := make([]modules.ProcessedTransaction, 0, len(txns))
+		for _, pt := range txns {
+			if direction != "" && !direction.Matches(pt) {
+				continue
+			}
+			if checkHeight && !heightRange.Contains(pt.ConfirmationHeight) {
+				continue
+			}
+			filtered = append(filtered, pt)
+		}
+		return filtered
+	}
+	confirmed, unconfirmed = filter(confirmed, true), filter(unconfirmed, false)
+
+	indices, nextCursor, err := opts.page(len(confirmed), func(i int) string {
+		return confirmed[i].TransactionID.String()
+	})
+	if err != nil {
+		return nil, nil, "", err
+	}
+	page := make([]modules.ProcessedTransaction, 0, len(indices))
+	for _, i := range indices {
+		page = append(page, confirmed[i])
+	}
+	return page, unconfirmed, nextCursor, nil
+}
+
 // NewWalletUnlockHandler creates a handler to handle API calls to /wallet/unlock.
 func NewWalletUnlockHandler(wallet modules.Wallet) httprouter.Handle {
 	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
@@ -794,9 +1016,102 @@ func NewWalletSignHandler(wallet modules.Wallet) httprouter.Handle {
 	}
 }
 
+// NewWalletColdSignHandler creates a handler to handle API calls to /wallet/coldsign,
+// signing an unsigned transaction without requiring the spent outputs to be known by the consensus set.
+func NewWalletColdSignHandler(wallet modules.Wallet) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		var body modules.UnsignedTransaction
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			WriteError(w, Error{"error decoding the supplied unsigned transaction: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		txn, err := wallet.ColdSign(body)
+		if err != nil {
+			WriteError(w, Error{"error after call to /wallet/coldsign: " + err.Error()}, walletErrorToHTTPStatus(err))
+			return
+		}
+		WriteJSON(w, txn)
+	}
+}
+
+// NewWalletSignMessageHandler creates a handler to handle API calls to /wallet/signmessage,
+// signing a message using the key pair of a (single signature) address of the wallet.
+func NewWalletSignMessageHandler(wallet modules.Wallet) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		var body WalletSignMessagePOST
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			WriteError(w, Error{"error decoding the supplied message: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		if body.Address.Type != types.UnlockTypePubKey {
+			WriteError(w, Error{"error after call to /wallet/signmessage: only single signature addresses can sign a message"},
+				http.StatusBadRequest)
+			return
+		}
+		signature, err := wallet.SignMessage(body.Address, []byte(body.Message))
+		if err != nil {
+			WriteError(w, Error{"error after call to /wallet/signmessage: " + err.Error()}, walletErrorToHTTPStatus(err))
+			return
+		}
+		WriteJSON(w, WalletSignMessagePOSTResp{Signature: signature})
+	}
+}
+
+// NewWalletWatchHandler creates a handler to handle API calls to /wallet/watch.
+func NewWalletWatchHandler(wallet modules.Wallet) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		addresses, err := wallet.WatchedAddresses()
+		if err != nil {
+			WriteError(w, Error{"error after call to /wallet/watch: " + err.Error()}, walletErrorToHTTPStatus(err))
+			return
+		}
+		WriteJSON(w, WalletWatchGET{Addresses: addresses})
+	}
+}
+
+// NewWalletWatchAddHandler creates a handler to handle API calls to /wallet/watch/add.
+func NewWalletWatchAddHandler(wallet modules.Wallet) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		var body WalletWatchPOST
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			WriteError(w, Error{"error decoding the supplied addresses: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		err := wallet.AddWatchedAddresses(body.Addresses)
+		if err != nil {
+			WriteError(w, Error{"error after call to /wallet/watch/add: " + err.Error()}, walletErrorToHTTPStatus(err))
+			return
+		}
+		WriteSuccess(w)
+	}
+}
+
+// NewWalletWatchRemoveHandler creates a handler to handle API calls to /wallet/watch/remove.
+func NewWalletWatchRemoveHandler(wallet modules.Wallet) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		var body WalletWatchPOST
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			WriteError(w, Error{"error decoding the supplied addresses: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		err := wallet.RemoveWatchedAddresses(body.Addresses)
+		if err != nil {
+			WriteError(w, Error{"error after call to /wallet/watch/remove: " + err.Error()}, walletErrorToHTTPStatus(err))
+			return
+		}
+		WriteSuccess(w)
+	}
+}
+
 func walletErrorToHTTPStatus(err error) int {
-	if err == modules.ErrLockedWallet {
+	switch err {
+	case modules.ErrLockedWallet:
 		return http.StatusForbidden
+	case modules.ErrLowBalance, modules.ErrIncompleteTransactions:
+		return http.StatusPaymentRequired
+	case types.ErrTooSmallMinerFee:
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
 	}
-	return http.StatusInternalServerError
 }
@@ -0,0 +1,306 @@
+package api
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// websocketGUID is the GUID used to compute the Sec-WebSocket-Accept header,
+// as defined in RFC 6455, section 1.3.
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// websocket opcodes, as defined in RFC 6455, section 5.2.
+const (
+	websocketOpText  = 0x1
+	websocketOpClose = 0x8
+	websocketOpPing  = 0x9
+	websocketOpPong  = 0xA
+)
+
+// websocketMaxControlPayload is the maximum payload size of a control frame,
+// and the largest client frame a websocketConn accepts.
+const websocketMaxControlPayload = 125
+
+// websocketMaxMessageSize is the largest message a WebSocketStream accepts,
+// large enough for a consensus change applying several full blocks.
+const websocketMaxMessageSize = 1 << 25
+
+var (
+	errWebSocketUpgrade       = errors.New("expected a websocket upgrade request")
+	errWebSocketHijack        = errors.New("websocket connection cannot be hijacked")
+	errWebSocketFrameTooLarge = errors.New("websocket frame too large")
+	errWebSocketFragmented    = errors.New("fragmented websocket messages are not supported")
+)
+
+// websocketConn is a minimal server-side websocket connection (RFC 6455),
+// used to push JSON-encoded messages to a client. Messages sent by the client
+// are not supported, only control frames (ping and close) are handled.
+type websocketConn struct {
+	conn net.Conn
+	rw   *bufio.ReadWriter
+	mu   sync.Mutex
+}
+
+// upgradeWebSocket performs the server side of the websocket opening handshake,
+// taking over the underlying connection of the given HTTP request.
+func upgradeWebSocket(w http.ResponseWriter, req *http.Request) (*websocketConn, error) {
+	if req.Method != http.MethodGet ||
+		!headerContainsToken(req.Header, "Connection", "upgrade") ||
+		!headerContainsToken(req.Header, "Upgrade", "websocket") ||
+		req.Header.Get("Sec-WebSocket-Version") != "13" {
+		return nil, errWebSocketUpgrade
+	}
+	key := req.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		return nil, errWebSocketUpgrade
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, errWebSocketHijack
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, err
+	}
+	_, err = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + websocketAcceptKey(key) + "\r\n\r\n")
+	if err == nil {
+		err = rw.Flush()
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &websocketConn{conn: conn, rw: rw}, nil
+}
+
+// websocketAcceptKey computes the Sec-WebSocket-Accept header value for the given client key.
+func websocketAcceptKey(key string) string {
+	h := sha1.Sum([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+// headerContainsToken returns true if the comma-separated header contains the given token,
+// compared case-insensitively.
+func headerContainsToken(header http.Header, name, token string) bool {
+	for _, value := range header[name] {
+		for _, t := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// WriteJSON sends the given value, JSON-encoded, as a text message.
+func (ws *websocketConn) WriteJSON(v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return ws.writeFrame(websocketOpText, b)
+}
+
+// writeFrame writes a single, unfragmented and unmasked, frame.
+func (ws *websocketConn) writeFrame(opcode byte, payload []byte) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	header := []byte{0x80 | opcode}
+	switch n := len(payload); {
+	case n <= websocketMaxControlPayload:
+		header = append(header, byte(n))
+	case n <= 0xFFFF:
+		header = append(header, 126, 0, 0)
+		binary.BigEndian.PutUint16(header[2:], uint16(n))
+	default:
+		header = append(header, 127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(header[2:], uint64(n))
+	}
+	if _, err := ws.rw.Write(header); err != nil {
+		return err
+	}
+	if _, err := ws.rw.Write(payload); err != nil {
+		return err
+	}
+	return ws.rw.Flush()
+}
+
+// readLoop reads the frames sent by the client, answering pings, until the
+// client closes the connection or an error occurs, in which case the error is returned.
+func (ws *websocketConn) readLoop() error {
+	var header [2]byte
+	for {
+		if _, err := io.ReadFull(ws.rw, header[:]); err != nil {
+			return err
+		}
+		opcode := header[0] & 0x0F
+		masked := header[1]&0x80 != 0
+		length := uint64(header[1] & 0x7F)
+		if length > websocketMaxControlPayload {
+			// messages sent by the client are not supported
+			ws.writeFrame(websocketOpClose, nil)
+			return errWebSocketFrameTooLarge
+		}
+		var mask [4]byte
+		if masked {
+			if _, err := io.ReadFull(ws.rw, mask[:]); err != nil {
+				return err
+			}
+		}
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(ws.rw, payload); err != nil {
+			return err
+		}
+		if masked {
+			for i := range payload {
+				payload[i] ^= mask[i%4]
+			}
+		}
+		switch opcode {
+		case websocketOpClose:
+			ws.writeFrame(websocketOpClose, payload)
+			return nil
+		case websocketOpPing:
+			if err := ws.writeFrame(websocketOpPong, payload); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Close closes the underlying connection.
+func (ws *websocketConn) Close() error {
+	return ws.conn.Close()
+}
+
+// WebSocketStream is a minimal client-side websocket connection (RFC 6455),
+// used to receive the JSON-encoded messages pushed by the daemon,
+// such as the events streamed by /transactionpool/events and /consensus/events.
+type WebSocketStream struct {
+	conn io.ReadWriteCloser
+	r    *bufio.Reader
+	mu   sync.Mutex
+}
+
+// newWebSocketStream performs the client side of the websocket opening handshake,
+// using the given HTTP client, and returns the stream, or the response in case the daemon refused the upgrade.
+// The returned response body is open only if no stream is returned.
+func newWebSocketStream(client *http.Client, req *http.Request) (*WebSocketStream, *http.Response, error) {
+	var nonce [16]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return nil, nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce[:])
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", key)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		return nil, resp, nil
+	}
+	conn, ok := resp.Body.(io.ReadWriteCloser)
+	if !ok || resp.Header.Get("Sec-WebSocket-Accept") != websocketAcceptKey(key) {
+		resp.Body.Close()
+		return nil, nil, errors.New("invalid websocket handshake response")
+	}
+	return &WebSocketStream{conn: conn, r: bufio.NewReader(conn)}, nil, nil
+}
+
+// ReadJSON blocks until the next text message is received, decoding it into the given value.
+// Pings are answered while waiting, io.EOF is returned once the daemon closes the connection.
+func (ws *WebSocketStream) ReadJSON(v interface{}) error {
+	for {
+		opcode, payload, err := ws.readFrame()
+		if err != nil {
+			return err
+		}
+		switch opcode {
+		case websocketOpText:
+			return json.Unmarshal(payload, v)
+		case websocketOpPing:
+			if err := ws.writeFrame(websocketOpPong, payload); err != nil {
+				return err
+			}
+		case websocketOpClose:
+			ws.writeFrame(websocketOpClose, nil)
+			return io.EOF
+		}
+	}
+}
+
+// readFrame reads a single, unmasked, frame sent by the daemon.
+func (ws *WebSocketStream) readFrame() (byte, []byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(ws.r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	opcode := header[0] & 0x0F
+	if header[0]&0x80 == 0 || opcode == 0 {
+		return 0, nil, errWebSocketFragmented
+	}
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(ws.r, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(ws.r, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if length > websocketMaxMessageSize {
+		return 0, nil, errWebSocketFrameTooLarge
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(ws.r, payload); err != nil {
+		return 0, nil, err
+	}
+	return opcode, payload, nil
+}
+
+// writeFrame writes a single control frame, masked as required for frames sent by a client.
+func (ws *WebSocketStream) writeFrame(opcode byte, payload []byte) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	var mask [4]byte
+	if _, err := rand.Read(mask[:]); err != nil {
+		return err
+	}
+	frame := append([]byte{0x80 | opcode, 0x80 | byte(len(payload))}, mask[:]...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	_, err := ws.conn.Write(frame)
+	return err
+}
+
+// Close sends a close frame and closes the underlying connection.
+func (ws *WebSocketStream) Close() error {
+	ws.writeFrame(websocketOpClose, nil)
+	return ws.conn.Close()
+}
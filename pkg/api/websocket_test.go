@@ -0,0 +1,156 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestWebSocketAcceptKey tests the accept key computation,
+// using the example of RFC 6455, section 1.3.
+func TestWebSocketAcceptKey(t *testing.T) {
+	if key := websocketAcceptKey("dGhlIHNhbXBsZSBub25jZQ=="); key != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatal("unexpected accept key:", key)
+	}
+}
+
+// TestWebSocketWriteJSON ensures a JSON message can be sent over an upgraded
+// connection, and that the connection is closed cleanly once the client closes it.
+func TestWebSocketWriteJSON(t *testing.T) {
+	done := make(chan error, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ws, err := upgradeWebSocket(w, req)
+		if err != nil {
+			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+			return
+		}
+		defer ws.Close()
+		if err := ws.WriteJSON(map[string]string{"type": "added"}); err != nil {
+			done <- err
+			return
+		}
+		done <- ws.readLoop()
+	}))
+	defer server.Close()
+
+	// a plain request is refused
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatal("expected a plain request to be refused, got status", resp.StatusCode)
+	}
+
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_, err = io.WriteString(conn, "GET / HTTP/1.1\r\nHost: localhost\r\n"+
+		"Upgrade: websocket\r\nConnection: Upgrade\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n\r\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := bufio.NewReader(conn)
+	resp, err = http.ReadResponse(r, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols || resp.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatal("unexpected handshake response:", resp.Status, resp.Header)
+	}
+
+	// read the (unmasked) text frame
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(r, header); err != nil {
+		t.Fatal(err)
+	}
+	if header[0] != 0x80|websocketOpText || header[1]&0x80 != 0 {
+		t.Fatalf("unexpected frame header: %x", header)
+	}
+	payload := make([]byte, header[1])
+	if _, err := io.ReadFull(r, payload); err != nil {
+		t.Fatal(err)
+	}
+	var msg map[string]string
+	if err := json.Unmarshal(payload, &msg); err != nil || msg["type"] != "added" {
+		t.Fatalf("unexpected message %q: %v", payload, err)
+	}
+
+	// send a masked close frame, which has to be echoed
+	if _, err := conn.Write([]byte{0x80 | websocketOpClose, 0x80, 1, 2, 3, 4}); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal("expected a clean close, got:", err)
+	}
+	if _, err := io.ReadFull(r, header); err != nil || header[0] != 0x80|websocketOpClose {
+		t.Fatalf("expected an echoed close frame, got %x: %v", header, err)
+	}
+}
+
+// TestWebSocketStream ensures the client side of a websocket connection
+// can receive messages of any size, answering pings, and can close the connection cleanly.
+func TestWebSocketStream(t *testing.T) {
+	message := strings.Repeat("a", 70000)
+	done := make(chan error, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if _, password, _ := req.BasicAuth(); password != "foo" {
+			WriteError(w, Error{"API authentication failed."}, http.StatusUnauthorized)
+			return
+		}
+		ws, err := upgradeWebSocket(w, req)
+		if err != nil {
+			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+			return
+		}
+		defer ws.Close()
+		for _, msg := range []string{"small", message} {
+			if err := ws.writeFrame(websocketOpPing, []byte("ping")); err != nil {
+				done <- err
+				return
+			}
+			if err := ws.WriteJSON(msg); err != nil {
+				done <- err
+				return
+			}
+		}
+		done <- ws.readLoop()
+	}))
+	defer server.Close()
+
+	client := HTTPClient{RootURL: server.URL, Password: "foo"}
+	stream, err := client.Subscribe("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"small", message} {
+		var msg string
+		if err := stream.ReadJSON(&msg); err != nil {
+			t.Fatal(err)
+		}
+		if msg != expected {
+			t.Fatalf("unexpected message of length %d, expected length %d", len(msg), len(expected))
+		}
+	}
+	if err := stream.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal("expected a clean close, got:", err)
+	}
+
+	// a refused upgrade returns the API error
+	client.Password = "bar"
+	if _, err := client.Subscribe("/"); err == nil {
+		t.Fatal("expected the subscription to be refused")
+	}
+}
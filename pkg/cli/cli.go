@@ -1,47 +1,163 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
+	"strings"
+
+	"github.com/threefoldtech/rivine/pkg/api"
 )
 
 // exit codes
 // inspired by sysexits.h
+//
+// The exit codes are stable, such that scripts can branch on the reason a command failed,
+// see ExitCodeKind for the names used for them in the JSON-encoded error output.
 const (
-	ExitCodeGeneral        = 1 // Not in sysexits.h, but is standard practice.
-	ExitCodeNotFound       = 2
-	ExitCodeCancelled      = 3
-	ExitCodeForbidden      = 4
-	ExitCodeTemporaryError = 5
-	ExitCodeUsage          = 64 // EX_USAGE in sysexits.h
+	ExitCodeGeneral           = 1 // Not in sysexits.h, but is standard practice.
+	ExitCodeNotFound          = 2
+	ExitCodeCancelled         = 3
+	ExitCodeForbidden         = 4
+	ExitCodeTemporaryError    = 5
+	ExitCodeUnreachable       = 6 // the daemon could not be reached
+	ExitCodeWalletLocked      = 7
+	ExitCodeInsufficientFunds = 8
+	ExitCodeInvalidAddress    = 9
+	ExitCodeUsage             = 64 // EX_USAGE in sysexits.h
 )
 
-// Die prints its arguments to stderr, then exits the program with the default
-// error code.
-func Die(args ...interface{}) {
-	DieWithExitCode(ExitCodeGeneral, args...)
+// ExitCodeKind returns the name of the given exit code,
+// as used as the kind of error in the JSON-encoded error output.
+func ExitCodeKind(code int) string {
+	switch code {
+	case ExitCodeNotFound:
+		return "notfound"
+	case ExitCodeCancelled:
+		return "cancelled"
+	case ExitCodeForbidden:
+		return "forbidden"
+	case ExitCodeTemporaryError:
+		return "temporary"
+	case ExitCodeUnreachable:
+		return "unreachable"
+	case ExitCodeWalletLocked:
+		return "walletlocked"
+	case ExitCodeInsufficientFunds:
+		return "insufficientfunds"
+	case ExitCodeInvalidAddress:
+		return "invalidaddress"
+	case ExitCodeUsage:
+		return "usage"
+	default:
+		return "general"
+	}
 }
 
-// DieWithError exits with an error.
-// if the error is of the type api.ErrorWithStatusCode, its status will be used,
-// otherwise the General exit code will be used
-func DieWithError(description string, err error) {
+// ErrorWithExitCode is an error which defines the exit code
+// to exit with when passed to Die or DieWithError.
+type ErrorWithExitCode struct {
+	Err  error
+	Code int
+}
+
+// Error implements error.Error
+func (e ErrorWithExitCode) Error() string {
+	return e.Err.Error()
+}
+
+// ExitCode returns the exit code defined for the error.
+func (e ErrorWithExitCode) ExitCode() int {
+	return e.Code
+}
+
+// ErrorExitCode returns the exit code to exit with for the given error.
+// Errors returned by the daemon are classified using the HTTP status code of their response:
+// 401 (Unauthorized) as forbidden, 402 (Payment Required) as insufficient funds
+// and 403 (Forbidden) as a locked wallet.
+func ErrorExitCode(err error) int {
+	if err == nil {
+		return ExitCodeGeneral
+	}
+	if err == api.ErrDaemonUnreachable {
+		return ExitCodeUnreachable
+	}
+	if exitCodeErr, ok := err.(interface{ ExitCode() int }); ok {
+		return exitCodeErr.ExitCode()
+	}
 	if httpStatusCodeErr, ok := err.(interface{ HTTPStatusCode() int }); ok {
 		switch httpStatusCodeErr.HTTPStatusCode() {
 		case http.StatusUnauthorized:
-			DieWithExitCode(ExitCodeForbidden, description, err)
-			return
-		default:
-			DieWithExitCode(ExitCodeGeneral, description, err)
+			return ExitCodeForbidden
+		case http.StatusPaymentRequired:
+			return ExitCodeInsufficientFunds
+		case http.StatusForbidden:
+			return ExitCodeWalletLocked
+		}
+	}
+	return ExitCodeGeneral
+}
+
+// ErrorOutput is the JSON-encoded form in which errors are printed to stderr,
+// when the JSON error output is enabled.
+type ErrorOutput struct {
+	ExitCode       int    `json:"exitcode"`
+	Kind           string `json:"kind"`
+	Message        string `json:"message"`
+	HTTPStatusCode int    `json:"httpstatuscode,omitempty"`
+}
+
+var (
+	// jsonErrorOutput defines whether errors are printed as JSON-encoded ErrorOutput
+	jsonErrorOutput bool
+)
+
+// SetJSONErrorOutput defines whether errors are printed to stderr
+// as a JSON-encoded ErrorOutput object, instead of as plain text.
+func SetJSONErrorOutput(enabled bool) {
+	jsonErrorOutput = enabled
+}
+
+// Die prints its arguments to stderr, then exits the program with the default
+// error code, or the exit code defined by the first error argument that defines one.
+func Die(args ...interface{}) {
+	code := ExitCodeGeneral
+	for _, arg := range args {
+		if err, ok := arg.(error); ok {
+			if code = ErrorExitCode(err); code != ExitCodeGeneral {
+				break
+			}
 		}
 	}
-	DieWithExitCode(ExitCodeGeneral, description, err)
+	DieWithExitCode(code, args...)
+}
+
+// DieWithError exits with an error,
+// using the exit code as classified by ErrorExitCode.
+func DieWithError(description string, err error) {
+	DieWithExitCode(ErrorExitCode(err), description, err)
 }
 
 // DieWithExitCode prints its arguments to stderr,
 // then exits the program with the given exit code.
 func DieWithExitCode(code int, args ...interface{}) {
-	fmt.Fprintln(os.Stderr, args...)
+	if !jsonErrorOutput {
+		fmt.Fprintln(os.Stderr, args...)
+		os.Exit(code)
+	}
+	output := ErrorOutput{
+		ExitCode: code,
+		Kind:     ExitCodeKind(code),
+		Message:  strings.TrimSuffix(fmt.Sprintln(args...), "\n"),
+	}
+	for _, arg := range args {
+		if httpStatusCodeErr, ok := arg.(interface{ HTTPStatusCode() int }); ok {
+			output.HTTPStatusCode = httpStatusCodeErr.HTTPStatusCode()
+		}
+	}
+	if err := json.NewEncoder(os.Stderr).Encode(output); err != nil {
+		fmt.Fprintln(os.Stderr, args...)
+	}
 	os.Exit(code)
 }
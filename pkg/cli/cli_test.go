@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/threefoldtech/rivine/pkg/api"
+)
+
+type httpStatusCodeError int
+
+func (e httpStatusCodeError) Error() string {
+	return fmt.Sprintf("HTTP %d error", int(e))
+}
+
+func (e httpStatusCodeError) HTTPStatusCode() int {
+	return int(e)
+}
+
+func TestErrorExitCode(t *testing.T) {
+	testCases := []struct {
+		Err      error
+		Expected int
+	}{
+		{nil, ExitCodeGeneral},
+		{errors.New("foo"), ExitCodeGeneral},
+		{api.ErrDaemonUnreachable, ExitCodeUnreachable},
+		{ErrorWithExitCode{Err: errors.New("foo"), Code: ExitCodeInvalidAddress}, ExitCodeInvalidAddress},
+		{httpStatusCodeError(http.StatusUnauthorized), ExitCodeForbidden},
+		{httpStatusCodeError(http.StatusPaymentRequired), ExitCodeInsufficientFunds},
+		{httpStatusCodeError(http.StatusForbidden), ExitCodeWalletLocked},
+		{httpStatusCodeError(http.StatusInternalServerError), ExitCodeGeneral},
+	}
+	for idx, testCase := range testCases {
+		if code := ErrorExitCode(testCase.Err); code != testCase.Expected {
+			t.Errorf("#%d: unexpected exit code for %v: %d != %d", idx, testCase.Err, code, testCase.Expected)
+		}
+	}
+}
+
+// TestExitCodeKind ensures the names of the exit codes remain stable,
+// as scripts rely on them.
+func TestExitCodeKind(t *testing.T) {
+	testCases := map[int]string{
+		ExitCodeGeneral:           "general",
+		ExitCodeNotFound:          "notfound",
+		ExitCodeCancelled:         "cancelled",
+		ExitCodeForbidden:         "forbidden",
+		ExitCodeTemporaryError:    "temporary",
+		ExitCodeUnreachable:       "unreachable",
+		ExitCodeWalletLocked:      "walletlocked",
+		ExitCodeInsufficientFunds: "insufficientfunds",
+		ExitCodeInvalidAddress:    "invalidaddress",
+		ExitCodeUsage:             "usage",
+	}
+	for code, expected := range testCases {
+		if kind := ExitCodeKind(code); kind != expected {
+			t.Errorf("unexpected kind for exit code %d: %q != %q", code, kind, expected)
+		}
+	}
+}
@@ -207,6 +207,93 @@ func createAtomicSwapCmd(client *CommandLineClient) *cobra.Command {
 			Run: atomicSwapCmd.extractSecretCmd,
 		}
 
+		auditBTCContractCmd = &cobra.Command{
+			Use:   "auditbtccontract contract",
+			Short: "Audit a Bitcoin-style counterparty atomic swap contract.",
+			Long: `Audit the atomic swap contract script of a Bitcoin-style counterparty chain
+		(e.g. Bitcoin, Litecoin or Bitcoin Cash), given as hex-encoded script,
+		as created by the atomic swap tooling of those chains.
+		
+		The contract is checked to be compatible with Rivine atomic swap contracts,
+		requiring a secret of 32 bytes which is hashed using OP_SHA256.
+		If the contract is compatible and its lock time is a timestamp,
+		the suggested duration of the matching Rivine participation contract is derived,
+		being half of the duration left, such that the initiator's contract on the
+		counterparty chain can only be refunded long after the participation contract.
+		
+		Optionally the secret hash and the minimum duration left are validated,
+		by giving them as flag arguments.
+		
+		Only the contract script is audited, the tooling of the counterparty chain
+		has to be used in order to verify that the contract is funded and confirmed.
+		
+		Returned status codes:
+		
+		  0: contract parsed and validated successfully
+		  1: generic error, automatically recovering is not possible or recommended
+		  64: misusage of the command, see --help on how to use the command
+		  128: contract invalid, incompatible or not matching the given criteria
+		
+		Example output when using the '--encoding json' flag:
+		
+		  {
+			"contract": {
+			  // opcode used to hash the secret, has to be OP_SHA256
+			  "hashop": "OP_SHA256",
+			  "secrethash": "c22267f0f118282b15098e0b5e3a8027af64f0cce7afa19b274abb21b5555626",
+			  // size in bytes the secret is required to have, has to be 32
+			  "secretsize": 32,
+			  // hash160 of the public key of the recipient, used to redeem
+			  "recipienthash160": "3e0dd8e1af7ef6e77d4ddcc5c4d09f8bd9ab5cb6",
+			  // hash160 of the public key of the creator, used to refund
+			  "refundhash160": "f2e5a0e57c5cca0ec8cb6b6e5ab5b0a8b4c4c4c1",
+			  // block height or unix timestamp from which the contract can be refunded
+			  "locktime": 1530169858,
+			  "locktimetype": "timestamp"
+			},
+			"compatible": true,
+			// suggested duration of the Rivine participation contract
+			"participateduration": "23h59m0s"
+		  }
+		
+		This output is always returned when the contract could be parsed,
+		even if it doesn't pass the audit.
+		`,
+			Run: Wrap(atomicSwapCmd.auditBTCContractCmd),
+		}
+
+		extractBTCSecretCmd = &cobra.Command{
+			Use:   "extractbtcsecret transaction secrethash",
+			Short: "Extract the secret from a Bitcoin-style redemption transaction.",
+			Long: `Extract the secret from the transaction of a Bitcoin-style counterparty chain
+		(e.g. Bitcoin, Litecoin or Bitcoin Cash), which redeemed an atomic swap contract,
+		given as hex-encoded raw transaction. Both legacy (P2SH) and segwit (P2WSH) redemptions are supported.
+		
+		The secret is found by looking for the data pushed by the inputs of the transaction
+		which hashes to the given secret hash, such that the extracted secret is always valid.
+		It can be used to redeem the matching Rivine atomic swap contract using the redeem command.
+		
+		Returned status codes:
+		
+		  0: secret extracted successfully
+		  1: generic error, automatically recovering is not possible or recommended
+		  2: the transaction does not contain the secret
+		  64: misusage of the command, see --help on how to use the command
+		
+		Example output when using the '--encoding json' flag:
+		
+		  {
+			// the secret that was used to redeem the funds,
+			// that were locked in the atomic swap contract
+			"secret": "6f0e3b2cdd82da7c4ddc20f03be25765fb885fb59af316cb3bbf8649c82d046d"
+		  }
+		
+		Note that this output is only returned in case the command
+		was successful, and thus exited with status code 0.
+		`,
+			Run: Wrap(atomicSwapCmd.extractBTCSecretCmd),
+		}
+
 		redeemCmd = &cobra.Command{
 			Use:   "redeem outputid secret",
 			Short: "Redeem the coins locked in an atomic swap contract.",
@@ -264,6 +351,8 @@ func createAtomicSwapCmd(client *CommandLineClient) *cobra.Command {
 		initiateCmd,
 		auditCmd,
 		extractSecretCmd,
+		auditBTCContractCmd,
+		extractBTCSecretCmd,
 		redeemCmd,
 		refundCmd,
 	)
@@ -304,6 +393,13 @@ func createAtomicSwapCmd(client *CommandLineClient) *cobra.Command {
 		cli.StringLoaderFlag{StringLoader: &atomicSwapCmd.extractSecretCfg.HashedSecret}, "secrethash",
 		"optionally validate the secret of the found atomic swap contract condition by comparing its hashed version with this secret hash")
 
+	auditBTCContractCmd.Flags().Var(
+		cli.StringLoaderFlag{StringLoader: &atomicSwapCmd.auditBTCCfg.HashedSecret}, "secrethash",
+		"optionally validate the secret hash of the contract by comparing it with this secret hash")
+	auditBTCContractCmd.Flags().DurationVar(
+		&atomicSwapCmd.auditBTCCfg.MinDurationLeft, "min-duration", 0,
+		"optionally validate the given contract has sufficient duration left, as defined by its (timestamp) lock time")
+
 	// return root command
 	return rootCmd
 }
@@ -332,6 +428,10 @@ type atomicSwapCmd struct {
 	extractSecretCfg struct {
 		HashedSecret types.AtomicSwapHashedSecret
 	}
+	auditBTCCfg struct {
+		HashedSecret    types.AtomicSwapHashedSecret
+		MinDurationLeft time.Duration
+	}
 }
 
 type (
@@ -373,7 +473,7 @@ func (atomicSwapCmd *atomicSwapCmd) participateCmd(participantAddress, amount, h
 	)
 	err := receiver.LoadString(participantAddress)
 	if err != nil {
-		cli.DieWithExitCode(cli.ExitCodeUsage, "failed to parse participant address (unlock hash):", err)
+		cli.DieWithExitCode(cli.ExitCodeInvalidAddress, "failed to parse participant address (unlock hash):", err)
 	}
 	if atomicSwapCmd.participateCfg.SourceUnlockHash.Type != 0 {
 		// use the hash given by the user explicitly
@@ -412,7 +512,7 @@ func (atomicSwapCmd *atomicSwapCmd) initiateCmd(participantAddress, amount strin
 	)
 	err := receiver.LoadString(participantAddress)
 	if err != nil {
-		cli.DieWithExitCode(cli.ExitCodeUsage, "failed to parse participant address (unlock hash):", err)
+		cli.DieWithExitCode(cli.ExitCodeInvalidAddress, "failed to parse participant address (unlock hash):", err)
 	}
 	if atomicSwapCmd.initiateCfg.SourceUnlockHash.Type != 0 {
 		// use the hash given by the user explicitly
@@ -494,7 +594,7 @@ func (atomicSwapCmd *atomicSwapCmd) createAtomicSwapContract(hastings types.Curr
 		cli.Die("didn't find atomic swap contract registered in any returned coin output")
 	}
 
-	if atomicSwapCmd.rootCfg.EncodingType == cli.EncodingTypeJSON {
+	if outputEncodingType(atomicSwapCmd.cli, atomicSwapCmd.rootCfg.EncodingType) == cli.EncodingTypeJSON {
 		// if encoding type is JSON, simply print all information as JSON
 		output := AtomicSwapOutputCreation{
 			Coins:         hastings,
@@ -646,7 +746,7 @@ func (atomicSwapCmd *atomicSwapCmd) auditAtomicSwapContract(co types.CoinOutput,
 	}
 	durationLeft := time.Unix(int64(condition.TimeLock), 0).Sub(computeTimeNow())
 
-	if atomicSwapCmd.rootCfg.EncodingType == cli.EncodingTypeJSON {
+	if outputEncodingType(atomicSwapCmd.cli, atomicSwapCmd.rootCfg.EncodingType) == cli.EncodingTypeJSON {
 		json.NewEncoder(os.Stdout).Encode(AtomicSwapOutputAudit{
 			Coins:    co.Value,
 			Contract: *condition,
@@ -841,7 +941,7 @@ secretCheck:
 		}
 	}
 
-	if atomicSwapCmd.rootCfg.EncodingType == cli.EncodingTypeJSON {
+	if outputEncodingType(atomicSwapCmd.cli, atomicSwapCmd.rootCfg.EncodingType) == cli.EncodingTypeJSON {
 		// if encoding type is JSON, simply print all information as JSON
 		json.NewEncoder(os.Stdout).Encode(AtomicSwapOutputExtractSecret{
 			Secret: secret,
@@ -995,7 +1095,7 @@ func (atomicSwapCmd *atomicSwapCmd) spendAtomicSwapContract(outputID types.CoinO
 		cli.Die("failed to "+keyWord+" atomic swaps locked tokens, as transaction couldn't commit:", err)
 	}
 
-	if atomicSwapCmd.rootCfg.EncodingType == cli.EncodingTypeJSON {
+	if outputEncodingType(atomicSwapCmd.cli, atomicSwapCmd.rootCfg.EncodingType) == cli.EncodingTypeJSON {
 		// if encoding type is JSON, simply print all information as JSON
 		json.NewEncoder(os.Stdout).Encode(AtomicSwapOutputSpendContract{
 			TransactionID: txnid,
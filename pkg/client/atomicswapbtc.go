@@ -0,0 +1,541 @@
+package client
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/threefoldtech/rivine/pkg/cli"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// Bitcoin script opcodes, as used by the atomic swap contracts of Bitcoin-style chains.
+const (
+	btcOpFalse               = 0x00
+	btcOpPushData1           = 0x4c
+	btcOpPushData2           = 0x4d
+	btcOpPushData4           = 0x4e
+	btcOp1Negate             = 0x4f
+	btcOp1                   = 0x51
+	btcOp16                  = 0x60
+	btcOpIf                  = 0x63
+	btcOpElse                = 0x67
+	btcOpEndIf               = 0x68
+	btcOpDrop                = 0x75
+	btcOpDup                 = 0x76
+	btcOpSize                = 0x82
+	btcOpEqualVerify         = 0x88
+	btcOpRipemd160           = 0xa6
+	btcOpSha256              = 0xa8
+	btcOpHash160             = 0xa9
+	btcOpHash256             = 0xaa
+	btcOpCheckSig            = 0xac
+	btcOpCheckLockTimeVerify = 0xb1
+)
+
+// btcLockTimeThreshold is the value below which a Bitcoin lock time is interpreted as a block height,
+// and at or above which it is interpreted as a unix timestamp.
+const btcLockTimeThreshold = 500000000
+
+// btcOpcode is a single opcode of a Bitcoin script, with the data it pushes, if any.
+type btcOpcode struct {
+	Op   byte
+	Data []byte
+}
+
+// isPush returns true if the opcode pushes data (including small integers) onto the stack.
+func (op btcOpcode) isPush() bool {
+	return op.Op <= btcOpPushData4 || op.Op == btcOp1Negate || (op.Op >= btcOp1 && op.Op <= btcOp16)
+}
+
+// scriptNum returns the integer pushed by the opcode, as encoded by Bitcoin scripts,
+// being little-endian with the sign in the most significant bit.
+func (op btcOpcode) scriptNum() (int64, bool) {
+	switch {
+	case op.Op == btcOp1Negate:
+		return -1, true
+	case op.Op >= btcOp1 && op.Op <= btcOp16:
+		return int64(op.Op-btcOp1) + 1, true
+	case op.Op > btcOpPushData4 || len(op.Data) > 5:
+		return 0, false
+	}
+	var n int64
+	for i, b := range op.Data {
+		n |= int64(b) << uint(8*i)
+	}
+	if l := len(op.Data); l > 0 && op.Data[l-1]&0x80 != 0 {
+		n &^= int64(0x80) << uint(8*(l-1))
+		n = -n
+	}
+	return n, true
+}
+
+// parseBTCScript parses a Bitcoin script into its opcodes.
+func parseBTCScript(script []byte) ([]btcOpcode, error) {
+	var ops []btcOpcode
+	for i := 0; i < len(script); {
+		op := btcOpcode{Op: script[i]}
+		i++
+		var size int
+		switch {
+		case op.Op > btcOpFalse && op.Op < btcOpPushData1:
+			size = int(op.Op)
+		case op.Op == btcOpPushData1:
+			if i+1 > len(script) {
+				return nil, errors.New("script ends within the size of a push")
+			}
+			size = int(script[i])
+			i++
+		case op.Op == btcOpPushData2:
+			if i+2 > len(script) {
+				return nil, errors.New("script ends within the size of a push")
+			}
+			size = int(binary.LittleEndian.Uint16(script[i:]))
+			i += 2
+		case op.Op == btcOpPushData4:
+			if i+4 > len(script) {
+				return nil, errors.New("script ends within the size of a push")
+			}
+			size = int(binary.LittleEndian.Uint32(script[i:]))
+			i += 4
+		}
+		if size < 0 || size > len(script)-i {
+			return nil, fmt.Errorf("script ends within a push of %d bytes", size)
+		}
+		if size > 0 {
+			op.Data = script[i : i+size]
+			i += size
+		}
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+// BTCAtomicSwapContract is an atomic swap contract of a Bitcoin-style chain,
+// as created by the atomic swap tooling of those chains:
+//
+//	OP_IF
+//	  [OP_SIZE <secret size> OP_EQUALVERIFY] <hash op> <secret hash> OP_EQUALVERIFY OP_DUP OP_HASH160 <recipient>
+//	OP_ELSE
+//	  <lock time> OP_CHECKLOCKTIMEVERIFY OP_DROP OP_DUP OP_HASH160 <refund address>
+//	OP_ENDIF
+//	OP_EQUALVERIFY OP_CHECKSIG
+type BTCAtomicSwapContract struct {
+	// HashOp is the name of the opcode used to hash the secret, e.g. "OP_SHA256".
+	HashOp     string          `json:"hashop"`
+	SecretHash types.ByteSlice `json:"secrethash"`
+	// SecretSize is the size in bytes the secret is required to have, 0 if not checked by the contract.
+	SecretSize       int64           `json:"secretsize"`
+	RecipientHash160 types.ByteSlice `json:"recipienthash160"`
+	RefundHash160    types.ByteSlice `json:"refundhash160"`
+	// LockTime is the block height or unix timestamp (as indicated by LockTimeType)
+	// after which the contract can be refunded.
+	LockTime     int64  `json:"locktime"`
+	LockTimeType string `json:"locktimetype"`
+}
+
+// btcHashOps are the names of the opcodes which can be used to hash the secret,
+// with the size of the hash they produce.
+var btcHashOps = map[byte]struct {
+	Name string
+	Size int
+}{
+	btcOpSha256:    {"OP_SHA256", sha256.Size},
+	btcOpHash256:   {"OP_HASH256", sha256.Size},
+	btcOpRipemd160: {"OP_RIPEMD160", 20},
+	btcOpHash160:   {"OP_HASH160", 20},
+}
+
+// parseBTCAtomicSwapContract parses the given Bitcoin script as an atomic swap contract.
+func parseBTCAtomicSwapContract(script []byte) (BTCAtomicSwapContract, error) {
+	ops, err := parseBTCScript(script)
+	if err != nil {
+		return BTCAtomicSwapContract{}, err
+	}
+	var (
+		contract BTCAtomicSwapContract
+		index    int
+	)
+	next := func() (btcOpcode, bool) {
+		if index >= len(ops) {
+			return btcOpcode{}, false
+		}
+		op := ops[index]
+		index++
+		return op, true
+	}
+	expect := func(opcodes ...byte) bool {
+		for _, opcode := range opcodes {
+			op, ok := next()
+			if !ok || op.Op != opcode {
+				return false
+			}
+		}
+		return true
+	}
+	pushed := func(size int) ([]byte, bool) {
+		op, ok := next()
+		if !ok || !op.isPush() || len(op.Data) != size {
+			return nil, false
+		}
+		return op.Data, true
+	}
+	errNotAContract := errors.New("script is not an atomic swap contract")
+
+	if !expect(btcOpIf) {
+		return contract, errNotAContract
+	}
+	// the secret size check is optional
+	if index < len(ops) && ops[index].Op == btcOpSize {
+		index++
+		op, ok := next()
+		if !ok {
+			return contract, errNotAContract
+		}
+		contract.SecretSize, ok = op.scriptNum()
+		if !ok || !expect(btcOpEqualVerify) {
+			return contract, errNotAContract
+		}
+	}
+	op, ok := next()
+	hashOp, known := btcHashOps[op.Op]
+	if !ok || !known {
+		return contract, errNotAContract
+	}
+	contract.HashOp = hashOp.Name
+	if contract.SecretHash, ok = pushed(hashOp.Size); !ok {
+		return contract, errNotAContract
+	}
+	if !expect(btcOpEqualVerify, btcOpDup, btcOpHash160) {
+		return contract, errNotAContract
+	}
+	if contract.RecipientHash160, ok = pushed(20); !ok || !expect(btcOpElse) {
+		return contract, errNotAContract
+	}
+	if op, ok = next(); !ok {
+		return contract, errNotAContract
+	}
+	if contract.LockTime, ok = op.scriptNum(); !ok || contract.LockTime <= 0 {
+		return contract, errNotAContract
+	}
+	if !expect(btcOpCheckLockTimeVerify, btcOpDrop, btcOpDup, btcOpHash160) {
+		return contract, errNotAContract
+	}
+	if contract.RefundHash160, ok = pushed(20); !ok {
+		return contract, errNotAContract
+	}
+	if !expect(btcOpEndIf, btcOpEqualVerify, btcOpCheckSig) || index != len(ops) {
+		return contract, errNotAContract
+	}
+	if contract.LockTime < btcLockTimeThreshold {
+		contract.LockTimeType = "blockheight"
+	} else {
+		contract.LockTimeType = "timestamp"
+	}
+	return contract, nil
+}
+
+// Incompatibilities returns the reasons why the contract cannot be used
+// as the counterpart of a Rivine atomic swap contract, none if it is compatible.
+// Rivine contracts require a secret of exactly 32 bytes, hashed using a single sha256 hash.
+func (contract BTCAtomicSwapContract) Incompatibilities() []string {
+	var reasons []string
+	if contract.HashOp != btcHashOps[btcOpSha256].Name {
+		reasons = append(reasons, fmt.Sprintf(
+			"secret is hashed using %s, while Rivine contracts use OP_SHA256", contract.HashOp))
+	}
+	switch contract.SecretSize {
+	case int64(len(types.AtomicSwapSecret{})):
+	case 0:
+		reasons = append(reasons, fmt.Sprintf(
+			"secret size is not checked, while Rivine contracts require a secret of %d bytes", len(types.AtomicSwapSecret{})))
+	default:
+		reasons = append(reasons, fmt.Sprintf(
+			"secret size is required to be %d bytes, while Rivine contracts require a secret of %d bytes",
+			contract.SecretSize, len(types.AtomicSwapSecret{})))
+	}
+	return reasons
+}
+
+// lockTimeDurationLeft returns the duration left until the contract can be refunded,
+// and false if the lock time is a block height, which cannot be converted to a time.
+func (contract BTCAtomicSwapContract) lockTimeDurationLeft(now time.Time) (time.Duration, bool) {
+	if contract.LockTime < btcLockTimeThreshold {
+		return 0, false
+	}
+	return time.Unix(contract.LockTime, 0).Sub(now), true
+}
+
+// btcTransactionPushes returns all data pushed by the signature scripts and witnesses
+// of the inputs of the given serialized Bitcoin transaction.
+func btcTransactionPushes(txn []byte) ([][]byte, error) {
+	r := bytes.NewReader(txn)
+	readBytes := func(n uint64) ([]byte, error) {
+		if n > uint64(r.Len()) {
+			return nil, errors.New("unexpected end of transaction")
+		}
+		b := make([]byte, n)
+		r.Read(b)
+		return b, nil
+	}
+	readVarInt := func() (uint64, error) {
+		prefix, err := r.ReadByte()
+		if err != nil {
+			return 0, errors.New("unexpected end of transaction")
+		}
+		var size uint64
+		switch prefix {
+		case 0xfd:
+			size = 2
+		case 0xfe:
+			size = 4
+		case 0xff:
+			size = 8
+		default:
+			return uint64(prefix), nil
+		}
+		b, err := readBytes(size)
+		if err != nil {
+			return 0, err
+		}
+		var n uint64
+		for i := range b {
+			n |= uint64(b[i]) << uint(8*i)
+		}
+		return n, nil
+	}
+
+	// version, optionally followed by the segwit marker and flag
+	if _, err := readBytes(4); err != nil {
+		return nil, err
+	}
+	var segwit bool
+	if r.Len() >= 2 && txn[4] == 0 && txn[5] == 1 {
+		segwit = true
+		readBytes(2)
+	}
+
+	var pushes [][]byte
+	inputCount, err := readVarInt()
+	if err != nil {
+		return nil, err
+	}
+	for i := uint64(0); i < inputCount; i++ {
+		// previous output (hash and index)
+		if _, err = readBytes(36); err != nil {
+			return nil, err
+		}
+		size, err := readVarInt()
+		if err != nil {
+			return nil, err
+		}
+		script, err := readBytes(size)
+		if err != nil {
+			return nil, err
+		}
+		// signature scripts are expected to only push data,
+		// those that cannot be parsed are ignored
+		if ops, err := parseBTCScript(script); err == nil {
+			for _, op := range ops {
+				if len(op.Data) > 0 {
+					pushes = append(pushes, op.Data)
+				}
+			}
+		}
+		// sequence
+		if _, err = readBytes(4); err != nil {
+			return nil, err
+		}
+	}
+	outputCount, err := readVarInt()
+	if err != nil {
+		return nil, err
+	}
+	for i := uint64(0); i < outputCount; i++ {
+		// value
+		if _, err = readBytes(8); err != nil {
+			return nil, err
+		}
+		size, err := readVarInt()
+		if err != nil {
+			return nil, err
+		}
+		if _, err = readBytes(size); err != nil {
+			return nil, err
+		}
+	}
+	if segwit {
+		for i := uint64(0); i < inputCount; i++ {
+			itemCount, err := readVarInt()
+			if err != nil {
+				return nil, err
+			}
+			for j := uint64(0); j < itemCount; j++ {
+				size, err := readVarInt()
+				if err != nil {
+					return nil, err
+				}
+				item, err := readBytes(size)
+				if err != nil {
+					return nil, err
+				}
+				pushes = append(pushes, item)
+			}
+		}
+	}
+	// lock time
+	if _, err = readBytes(4); err != nil {
+		return nil, err
+	}
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("%d unexpected trailing bytes after the transaction", r.Len())
+	}
+	return pushes, nil
+}
+
+// extractBTCAtomicSwapSecret extracts the secret matching the given secret hash
+// from the given serialized Bitcoin transaction, redeeming an atomic swap contract.
+func extractBTCAtomicSwapSecret(txn []byte, hashedSecret types.AtomicSwapHashedSecret) (types.AtomicSwapSecret, error) {
+	pushes, err := btcTransactionPushes(txn)
+	if err != nil {
+		return types.AtomicSwapSecret{}, fmt.Errorf("invalid transaction: %v", err)
+	}
+	var secret types.AtomicSwapSecret
+	for _, push := range pushes {
+		if len(push) != len(secret) {
+			continue
+		}
+		copy(secret[:], push)
+		if types.NewAtomicSwapHashedSecret(secret) == hashedSecret {
+			return secret, nil
+		}
+	}
+	return types.AtomicSwapSecret{}, errors.New("transaction does not contain a secret matching the secret hash")
+}
+
+// decodeHexArg decodes the given hex-encoded positional argument,
+// ignoring surrounding whitespace and an optional 0x prefix.
+func decodeHexArg(name, str string) []byte {
+	str = strings.TrimPrefix(strings.TrimSpace(str), "0x")
+	b, err := hex.DecodeString(str)
+	if err != nil {
+		cli.DieWithExitCode(cli.ExitCodeUsage, fmt.Sprintf("failed to decode %s as hex:", name), err)
+	}
+	return b
+}
+
+type (
+	// AtomicSwapOutputBTCAudit represents the formatted output
+	// of the atomic swap audit command for Bitcoin-style contracts.
+	AtomicSwapOutputBTCAudit struct {
+		Contract BTCAtomicSwapContract `json:"contract"`
+		// Compatible is true if the contract can be used as the counterpart of a Rivine contract,
+		// otherwise the reasons are given in Incompatibilities.
+		Compatible        bool     `json:"compatible"`
+		Incompatibilities []string `json:"incompatibilities,omitempty"`
+		// ParticipateDuration is the suggested duration of the Rivine participation contract,
+		// being half of the duration left of the contract, omitted if the lock time is a block height.
+		ParticipateDuration string `json:"participateduration,omitempty"`
+	}
+)
+
+// auditbtccontract contract
+func (atomicSwapCmd *atomicSwapCmd) auditBTCContractCmd(contractStr string) {
+	contract, err := parseBTCAtomicSwapContract(decodeHexArg("contract", contractStr))
+	if err != nil {
+		cli.DieWithExitCode(AuditContractExitCodeInvalidContract, "failed to parse contract:", err)
+	}
+	output := AtomicSwapOutputBTCAudit{
+		Contract:          contract,
+		Incompatibilities: contract.Incompatibilities(),
+	}
+	output.Compatible = len(output.Incompatibilities) == 0
+	durationLeft, durationKnown := contract.lockTimeDurationLeft(computeTimeNow())
+	if durationKnown && durationLeft > 0 {
+		output.ParticipateDuration = (durationLeft / 2).Truncate(time.Minute).String()
+	}
+
+	if outputEncodingType(atomicSwapCmd.cli, atomicSwapCmd.rootCfg.EncodingType) == cli.EncodingTypeJSON {
+		json.NewEncoder(os.Stdout).Encode(output)
+	} else {
+		fmt.Printf(`Bitcoin-style Atomic Swap Contract:
+
+Secret Hash: %s (%s)
+Secret Size: %d
+Recipient's address (hash160): %s
+Refund address (hash160): %s
+`, contract.SecretHash, contract.HashOp, contract.SecretSize, contract.RecipientHash160, contract.RefundHash160)
+		if durationKnown {
+			fmt.Printf("LockTime: %[1]d (%[1]s)\nLockTime reached in: %s\n",
+				types.Timestamp(contract.LockTime), durationLeft)
+		} else {
+			fmt.Printf("LockTime: block %d\n", contract.LockTime)
+		}
+		fmt.Println()
+		if output.Compatible && output.ParticipateDuration != "" {
+			fmt.Printf(`To participate in this swap, create the matching Rivine contract using:
+
+  atomicswap participate <initiator address> <amount> %s --duration %s
+
+`, contract.SecretHash, output.ParticipateDuration)
+		}
+	}
+
+	var invalidContract bool
+	for _, reason := range output.Incompatibilities {
+		invalidContract = true
+		fmt.Fprintln(os.Stderr, "contract is not compatible with Rivine contracts:", reason)
+	}
+	if hs := atomicSwapCmd.auditBTCCfg.HashedSecret; hs != (types.AtomicSwapHashedSecret{}) && !bytes.Equal(hs[:], contract.SecretHash) {
+		invalidContract = true
+		fmt.Fprintf(os.Stderr, "found contract's secret hash %s does not match the expected secret hash %s\n",
+			contract.SecretHash, hs.String())
+	}
+	if minDurationLeft := atomicSwapCmd.auditBTCCfg.MinDurationLeft; minDurationLeft != 0 {
+		if !durationKnown {
+			invalidContract = true
+			fmt.Fprintln(os.Stderr, "found contract's lock time is a block height, "+
+				"such that the duration left cannot be compared with the expected duration left of "+minDurationLeft.String())
+		} else if durationLeft < minDurationLeft {
+			invalidContract = true
+			fmt.Fprintln(os.Stderr, "found contract's duration left "+durationLeft.String()+
+				" is not sufficient, when compared the expected duration left of "+minDurationLeft.String())
+		}
+	}
+	if invalidContract {
+		cli.DieWithExitCode(AuditContractExitCodeInvalidContract,
+			"found Atomic Swap Contract does not meet the given expectations")
+	}
+	fmt.Fprintln(os.Stderr, `found Atomic Swap Contract is valid
+NOTE: this only audits the contract script, use the tooling of the counterparty chain
+to verify that the contract is funded (with the expected amount) and confirmed`)
+}
+
+// extractbtcsecret transaction secrethash
+func (atomicSwapCmd *atomicSwapCmd) extractBTCSecretCmd(txnStr, hashedSecretStr string) {
+	var hashedSecret types.AtomicSwapHashedSecret
+	err := hashedSecret.LoadString(hashedSecretStr)
+	if err != nil {
+		cli.DieWithExitCode(cli.ExitCodeUsage, "failed to parse secret hash:", err)
+	}
+	secret, err := extractBTCAtomicSwapSecret(decodeHexArg("transaction", txnStr), hashedSecret)
+	if err != nil {
+		cli.DieWithExitCode(cli.ExitCodeNotFound, "failed to extract secret:", err)
+	}
+
+	if outputEncodingType(atomicSwapCmd.cli, atomicSwapCmd.rootCfg.EncodingType) == cli.EncodingTypeJSON {
+		json.NewEncoder(os.Stdout).Encode(AtomicSwapOutputExtractSecret{
+			Secret: secret,
+		})
+		return
+	}
+	fmt.Println("atomic swap contract was redeemed")
+	fmt.Println("extracted secret:", secret.String())
+}
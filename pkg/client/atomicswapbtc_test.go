@@ -0,0 +1,194 @@
+package client
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"reflect"
+	"testing"
+
+	"github.com/threefoldtech/rivine/types"
+)
+
+// btcPush returns the script pushing the given data.
+func btcPush(data []byte) []byte {
+	if len(data) < btcOpPushData1 {
+		return append([]byte{byte(len(data))}, data...)
+	}
+	return append([]byte{btcOpPushData1, byte(len(data))}, data...)
+}
+
+// btcAtomicSwapScript returns an atomic swap contract script, as created by the atomic swap tooling of Bitcoin.
+func btcAtomicSwapScript(hashOp byte, secretHash, recipient, refund []byte, lockTime uint32, sizeCheck bool) []byte {
+	var script []byte
+	script = append(script, btcOpIf)
+	if sizeCheck {
+		script = append(script, btcOpSize)
+		script = append(script, btcPush([]byte{32})...)
+		script = append(script, btcOpEqualVerify)
+	}
+	script = append(script, hashOp)
+	script = append(script, btcPush(secretHash)...)
+	script = append(script, btcOpEqualVerify, btcOpDup, btcOpHash160)
+	script = append(script, btcPush(recipient)...)
+	script = append(script, btcOpElse)
+	lt := make([]byte, 4)
+	binary.LittleEndian.PutUint32(lt, lockTime)
+	for len(lt) > 1 && lt[len(lt)-1] == 0 && lt[len(lt)-2]&0x80 == 0 {
+		lt = lt[:len(lt)-1]
+	}
+	if lt[len(lt)-1]&0x80 != 0 {
+		lt = append(lt, 0)
+	}
+	script = append(script, btcPush(lt)...)
+	script = append(script, btcOpCheckLockTimeVerify, btcOpDrop, btcOpDup, btcOpHash160)
+	script = append(script, btcPush(refund)...)
+	script = append(script, btcOpEndIf, btcOpEqualVerify, btcOpCheckSig)
+	return script
+}
+
+func TestParseBTCAtomicSwapContract(t *testing.T) {
+	secretHash := bytes.Repeat([]byte{0xab}, 32)
+	recipient := bytes.Repeat([]byte{1}, 20)
+	refund := bytes.Repeat([]byte{2}, 20)
+
+	contract, err := parseBTCAtomicSwapContract(
+		btcAtomicSwapScript(btcOpSha256, secretHash, recipient, refund, 1530169858, true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := BTCAtomicSwapContract{
+		HashOp:           "OP_SHA256",
+		SecretHash:       secretHash,
+		SecretSize:       32,
+		RecipientHash160: recipient,
+		RefundHash160:    refund,
+		LockTime:         1530169858,
+		LockTimeType:     "timestamp",
+	}
+	if !reflect.DeepEqual(contract, expected) {
+		t.Errorf("unexpected contract: %+v != %+v", contract, expected)
+	}
+	if reasons := contract.Incompatibilities(); len(reasons) != 0 {
+		t.Errorf("unexpected incompatibilities: %v", reasons)
+	}
+
+	// a block height lock time, which requires a sign byte, without a size check
+	contract, err = parseBTCAtomicSwapContract(
+		btcAtomicSwapScript(btcOpSha256, secretHash, recipient, refund, 0x80, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contract.LockTime != 0x80 || contract.LockTimeType != "blockheight" || contract.SecretSize != 0 {
+		t.Errorf("unexpected contract: %+v", contract)
+	}
+	if reasons := contract.Incompatibilities(); len(reasons) != 1 {
+		t.Errorf("expected an incompatibility for the missing size check: %v", reasons)
+	}
+
+	// a 20-byte hash is incompatible
+	contract, err = parseBTCAtomicSwapContract(
+		btcAtomicSwapScript(btcOpHash160, recipient, recipient, refund, 1530169858, true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reasons := contract.Incompatibilities(); len(reasons) != 1 {
+		t.Errorf("expected an incompatibility for the hash op: %v", reasons)
+	}
+
+	// the contract of the atomic swap documentation, created using btcatomicswap
+	script, err := hex.DecodeString("6382012088a8202891f924fde4cc3c43af0d501a9fb52acb47b9a2e650c16ef0abb0a02c0ed9888876a9140db229d573c1ca5042f1f6f8d95b0e48dd30f54c670418daac5ab17576a914dbb79258a0200feeef593cc753e3c0c21757a1306888ac")
+	if err != nil {
+		t.Fatal(err)
+	}
+	contract, err = parseBTCAtomicSwapContract(script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contract.SecretHash.String() != "2891f924fde4cc3c43af0d501a9fb52acb47b9a2e650c16ef0abb0a02c0ed988" ||
+		contract.RecipientHash160.String() != "0db229d573c1ca5042f1f6f8d95b0e48dd30f54c" ||
+		contract.RefundHash160.String() != "dbb79258a0200feeef593cc753e3c0c21757a130" ||
+		contract.LockTime != 1521277464 || len(contract.Incompatibilities()) != 0 {
+		t.Errorf("unexpected contract: %+v", contract)
+	}
+
+	// invalid contracts
+	valid := btcAtomicSwapScript(btcOpSha256, secretHash, recipient, refund, 1530169858, true)
+	for _, script := range [][]byte{
+		nil,
+		valid[:len(valid)-1],
+		append(append([]byte{}, valid...), btcOpDrop),
+		btcAtomicSwapScript(btcOpSha256, secretHash[:20], recipient, refund, 1530169858, true),
+		btcAtomicSwapScript(btcOpSha256, secretHash, recipient[:19], refund, 1530169858, true),
+		{btcOpIf, btcOpPushData1},
+	} {
+		if _, err := parseBTCAtomicSwapContract(script); err == nil {
+			t.Errorf("expected an error for script %x", script)
+		}
+	}
+}
+
+// btcTransaction returns a serialized Bitcoin transaction with a single input and output,
+// using the given signature script and witness (omitted if nil).
+func btcTransaction(sigScript []byte, witness [][]byte) []byte {
+	var txn []byte
+	txn = append(txn, 2, 0, 0, 0) // version
+	if witness != nil {
+		txn = append(txn, 0, 1) // marker and flag
+	}
+	txn = append(txn, 1)                              // input count
+	txn = append(txn, bytes.Repeat([]byte{7}, 36)...) // previous output
+	txn = append(txn, byte(len(sigScript)))
+	txn = append(txn, sigScript...)
+	txn = append(txn, 0xff, 0xff, 0xff, 0xff) // sequence
+	txn = append(txn, 1)                      // output count
+	txn = append(txn, 0xe8, 3, 0, 0, 0, 0, 0, 0)
+	txn = append(txn, 3, btcOpDup, btcOpDrop, btcOpCheckSig)
+	if witness != nil {
+		txn = append(txn, byte(len(witness)))
+		for _, item := range witness {
+			txn = append(txn, byte(len(item)))
+			txn = append(txn, item...)
+		}
+	}
+	return append(txn, 0, 0, 0, 0) // lock time
+}
+
+func TestExtractBTCAtomicSwapSecret(t *testing.T) {
+	var secret types.AtomicSwapSecret
+	copy(secret[:], bytes.Repeat([]byte{42}, len(secret)))
+	hashedSecret := types.NewAtomicSwapHashedSecret(secret)
+	contract := btcAtomicSwapScript(btcOpSha256, hashedSecret[:], bytes.Repeat([]byte{1}, 20), bytes.Repeat([]byte{2}, 20), 1530169858, true)
+	sig, pubKey := bytes.Repeat([]byte{3}, 71), bytes.Repeat([]byte{4}, 33)
+
+	// P2SH redemption: <sig> <pubkey> <secret> OP_TRUE <contract>
+	var sigScript []byte
+	for _, push := range [][]byte{sig, pubKey, secret[:]} {
+		sigScript = append(sigScript, btcPush(push)...)
+	}
+	sigScript = append(sigScript, btcOp1)
+	sigScript = append(sigScript, btcPush(contract)...)
+	// P2WSH redemption: the same items as witness, with an empty signature script
+	witness := [][]byte{sig, pubKey, secret[:], {1}, contract}
+
+	for _, txn := range [][]byte{btcTransaction(sigScript, nil), btcTransaction(nil, witness)} {
+		extracted, err := extractBTCAtomicSwapSecret(txn, hashedSecret)
+		if err != nil {
+			t.Errorf("failed to extract secret from %x: %v", txn, err)
+		} else if extracted != secret {
+			t.Errorf("unexpected secret: %v != %v", extracted, secret)
+		}
+	}
+
+	// the secret of another hash is not found
+	if _, err := extractBTCAtomicSwapSecret(btcTransaction(sigScript, nil), types.AtomicSwapHashedSecret{1}); err == nil {
+		t.Error("expected an error for a secret hash not matching the secret")
+	}
+	// invalid transactions
+	txn := btcTransaction(sigScript, nil)
+	for _, invalid := range [][]byte{nil, txn[:len(txn)-1], append(append([]byte{}, txn...), 0)} {
+		if _, err := extractBTCAtomicSwapSecret(invalid, hashedSecret); err == nil {
+			t.Errorf("expected an error for invalid transaction %x", invalid)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package client
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/pkg/api"
+	"github.com/threefoldtech/rivine/pkg/cli"
+	"github.com/threefoldtech/rivine/types"
+)
+
+func createBlockCreatorCmd(client *CommandLineClient) *cobra.Command {
+	blockCreatorCmd := &blockCreatorCmd{cli: client}
+
+	// create root blockcreator command and all subs
+	var (
+		rootCmd = &cobra.Command{
+			Use:   "blockcreator",
+			Short: "Print the block creator status",
+			Long:  "Print the status of the block creator, creating blocks using the blockstake of the wallet.",
+			Run:   Wrap(blockCreatorCmd.statusCmd),
+		}
+		statusCmd = &cobra.Command{
+			Use:   "status",
+			Short: "Print the block creator status",
+			Long: `Print the status of the block creator:
+whether it is actively creating (minting) blocks, and if not why,
+the blockstake owned by the wallet and the estimated time to create the next block,
+and the blocks created recently.
+
+The estimated time is an average, based on the eligible (aged) blockstake of the wallet
+and the active blockstake of the network, such that it can take (a lot) longer than estimated.`,
+			Run: Wrap(blockCreatorCmd.statusCmd),
+		}
+	)
+	rootCmd.AddCommand(statusCmd)
+	for _, cmd := range []*cobra.Command{rootCmd, statusCmd} {
+		cmd.Flags().IntVar(&blockCreatorCmd.statusCfg.Blocks, "blocks", 5,
+			"amount of recently created blocks to list")
+	}
+
+	// return root command
+	return rootCmd
+}
+
+type blockCreatorCmd struct {
+	cli       *CommandLineClient
+	statusCfg struct {
+		Blocks int
+	}
+}
+
+// BlockCreatorStatusOutput is the output of the blockcreator status command.
+type BlockCreatorStatusOutput struct {
+	api.BlockCreatorGET
+	// BlocksLastDay is the amount of blocks created in the last 24 hours.
+	BlocksLastDay int `json:"blockslastday"`
+	// RecentBlocks are the most recently created blocks, sorted from newest to oldest.
+	RecentBlocks []modules.BlockCreatorBlock `json:"recentblocks"`
+}
+
+// statusCmd is the handler for the command `rivinec blockcreator status`.
+// Prints the status of the block creator and the blocks it created recently.
+func (blockCreatorCmd *blockCreatorCmd) statusCmd() {
+	var output BlockCreatorStatusOutput
+	err := blockCreatorCmd.cli.GetAPI("/blockcreator", &output.BlockCreatorGET)
+	if err != nil {
+		cli.DieWithError("Could not get the block creator status:", err)
+	}
+	var blocks api.BlockCreatorBlocksGET
+	err = blockCreatorCmd.cli.GetAPI("/blockcreator/blocks", &blocks)
+	if err != nil {
+		cli.DieWithError("Could not get the created blocks:", err)
+	}
+	now := types.CurrentTimestamp()
+	output.BlocksLastDay = blocksCreatedSince(blocks.Blocks, now-types.Timestamp(24*time.Hour/time.Second))
+	output.RecentBlocks = blocks.Blocks
+	if n := blockCreatorCmd.statusCfg.Blocks; n >= 0 && n < len(output.RecentBlocks) {
+		output.RecentBlocks = output.RecentBlocks[:n]
+	}
+
+	blockCreatorCmd.cli.PrintOutput(output, func() {
+		blockCreatorCmd.printStatus(output, now)
+	})
+}
+
+// printStatus prints the given block creator status in a human-friendly format.
+func (blockCreatorCmd *blockCreatorCmd) printStatus(output BlockCreatorStatusOutput, now types.Timestamp) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	status := output.BlockCreatorStatus
+	currencyConvertor := blockCreatorCmd.cli.CreateCurrencyConvertor()
+	if status.Active {
+		fmt.Fprintln(w, "Active:\tYes")
+	} else {
+		fmt.Fprintf(w, "Active:\tNo (%s)\n", status.InactiveReason)
+	}
+	fmt.Fprintf(w, "Consensus Synced:\t%s\n", YesNo(status.Synced))
+	fmt.Fprintf(w, "Wallet Unlocked:\t%s\n", YesNo(status.WalletUnlocked))
+	if status.WalletUnlocked {
+		fmt.Fprintf(w, "BlockStake:\t%v BS (%v BS eligible)\n", status.BlockStake, status.EligibleBlockStake)
+	}
+	fmt.Fprintf(w, "Network BlockStake (estimated):\t%v BS\n", status.NetworkBlockStake)
+	if status.Active {
+		fmt.Fprintf(w, "Expected Time To Block:\t%s\n", expectedTimeToBlockString(status.ExpectedTimeToBlock))
+	}
+	if status.LastAttempt != 0 {
+		fmt.Fprintf(w, "Last Attempt:\t%v ago\n", timeSince(status.LastAttempt, now))
+	}
+	fmt.Fprintf(w, "Blocks Created (last 24h):\t%d\n", output.BlocksLastDay)
+
+	if len(output.RecentBlocks) == 0 {
+		return
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Recent Blocks:")
+	fmt.Fprintln(w, "  Height\tID\tCreated\tReward")
+	for _, block := range output.RecentBlocks {
+		fmt.Fprintf(w, "  %d\t%s\t%v ago\t%s\n", block.Height, block.ID.String(),
+			timeSince(block.Timestamp, now), currencyConvertor.ToCoinStringWithUnit(block.Reward))
+	}
+}
+
+// blocksCreatedSince returns the amount of the given created blocks with a timestamp
+// at or after the given time. The blocks are expected to be sorted from newest to oldest.
+func blocksCreatedSince(blocks []modules.BlockCreatorBlock, since types.Timestamp) int {
+	for i, block := range blocks {
+		if block.Timestamp < since {
+			return i
+		}
+	}
+	return len(blocks)
+}
+
+// expectedTimeToBlockString returns the given expected time to block, in seconds,
+// in a human-friendly format.
+func expectedTimeToBlockString(seconds uint64) string {
+	if seconds == 0 {
+		return "unknown, no eligible blockstake"
+	}
+	return "~" + (time.Duration(seconds) * time.Second).String()
+}
+
+// timeSince returns the time elapsed between the given timestamps, rounded to seconds.
+func timeSince(timestamp, now types.Timestamp) time.Duration {
+	if timestamp > now {
+		return 0
+	}
+	return time.Duration(now-timestamp) * time.Second
+}
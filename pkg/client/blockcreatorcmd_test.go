@@ -0,0 +1,35 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+func TestBlocksCreatedSince(t *testing.T) {
+	blocks := []modules.BlockCreatorBlock{
+		{Height: 30, Timestamp: 3000},
+		{Height: 20, Timestamp: 2000},
+		{Height: 10, Timestamp: 1000},
+	}
+	testCases := []struct {
+		Since    uint64
+		Expected int
+	}{
+		{4000, 0},
+		{3000, 1},
+		{2500, 1},
+		{1000, 3},
+		{0, 3},
+	}
+	for _, testCase := range testCases {
+		n := blocksCreatedSince(blocks, types.Timestamp(testCase.Since))
+		if n != testCase.Expected {
+			t.Errorf("unexpected amount of blocks created since %d: %d != %d", testCase.Since, n, testCase.Expected)
+		}
+	}
+	if n := blocksCreatedSince(nil, 0); n != 0 {
+		t.Errorf("unexpected amount of blocks created for no blocks: %d", n)
+	}
+}
@@ -1,19 +1,26 @@
 package client
 
 import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"net"
+	"net/http"
 	"os"
 	"reflect"
 	"runtime"
 	"strings"
+	"time"
 
+	"github.com/spf13/cobra"
 	"github.com/threefoldtech/rivine/build"
 	"github.com/threefoldtech/rivine/modules"
 	"github.com/threefoldtech/rivine/pkg/api"
 	"github.com/threefoldtech/rivine/pkg/cli"
 	"github.com/threefoldtech/rivine/types"
-	"github.com/spf13/cobra"
 )
 
 // ConfigFromDaemonConstants returns CLI constants using
@@ -129,6 +136,9 @@ func NewCommandLineClient(address, name, userAgent string) (*CommandLineClient,
 		RootURL:   address,
 		UserAgent: userAgent,
 	}
+	client.Units = UnitsCoin
+	client.settingsPath = defaultSettingsPath(name)
+	client.settingsEnvPrefix = settingsEnvPrefix(name)
 
 	var consensusCmd *consensusCmd
 	consensusCmd, client.ConsensusCmd = createConsensusCmd(client)
@@ -137,7 +147,7 @@ func NewCommandLineClient(address, name, userAgent string) (*CommandLineClient,
 		Short:             fmt.Sprintf("%s Client", strings.Title(name)),
 		Long:              fmt.Sprintf("%s Client", strings.Title(name)),
 		Run:               Wrap(consensusCmd.rootCmd),
-		PersistentPreRunE: client.preRunE,
+		PersistentPreRunE: client.persistentPreRunE,
 	}
 
 	// create command tree
@@ -147,14 +157,22 @@ func NewCommandLineClient(address, name, userAgent string) (*CommandLineClient,
 		Short: "Print version information",
 		Long:  "Print version information.",
 		Run: Wrap(func() {
-			fmt.Printf("%s Client v%s\r\n",
-				strings.Title(client.Config.ChainName),
-				client.Config.ChainVersion.String())
-
-			fmt.Println()
-			fmt.Printf("Go Version   v%s\r\n", runtime.Version()[2:])
-			fmt.Printf("GOOS         %s\r\n", runtime.GOOS)
-			fmt.Printf("GOARCH       %s\r\n", runtime.GOARCH)
+			client.PrintOutput(VersionOutput{
+				ChainName:    client.Config.ChainName,
+				ChainVersion: client.Config.ChainVersion,
+				GoVersion:    runtime.Version()[2:],
+				GOOS:         runtime.GOOS,
+				GOARCH:       runtime.GOARCH,
+			}, func() {
+				fmt.Printf("%s Client v%s\r\n",
+					strings.Title(client.Config.ChainName),
+					client.Config.ChainVersion.String())
+
+				fmt.Println()
+				fmt.Printf("Go Version   v%s\r\n", runtime.Version()[2:])
+				fmt.Printf("GOOS         %s\r\n", runtime.GOOS)
+				fmt.Printf("GOARCH       %s\r\n", runtime.GOARCH)
+			})
 		}),
 	})
 	client.RootCmd.AddCommand(&cobra.Command{
@@ -166,7 +184,9 @@ func NewCommandLineClient(address, name, userAgent string) (*CommandLineClient,
 			if err != nil {
 				cli.Die("Could not stop daemon:", err)
 			}
-			fmt.Printf("%s daemon stopped.\n", client.Config.ChainName)
+			client.PrintOutput(EmptyOutput{}, func() {
+				fmt.Printf("%s daemon stopped.\n", client.Config.ChainName)
+			})
 		}),
 	})
 
@@ -185,11 +205,44 @@ func NewCommandLineClient(address, name, userAgent string) (*CommandLineClient,
 	client.MergeCmd = createMergeCmd(client)
 	client.RootCmd.AddCommand(client.MergeCmd)
 
+	client.TransactionCmd = createTransactionCmd(client)
+	client.RootCmd.AddCommand(client.TransactionCmd)
+
+	client.StatusCmd = createStatusCmd(client)
+	client.RootCmd.AddCommand(client.StatusCmd)
+
+	client.BlockCreatorCmd = createBlockCreatorCmd(client)
+	client.RootCmd.AddCommand(client.BlockCreatorCmd)
+
+	client.DebugCmd = createDebugCmd(client)
+	client.RootCmd.AddCommand(client.DebugCmd)
+
+	client.ConsoleCmd = createConsoleCmd(client)
+	client.RootCmd.AddCommand(client.ConsoleCmd)
+
 	// parse flags
 	client.RootCmd.PersistentFlags().StringVarP(&client.HTTPClient.RootURL, "addr", "a",
 		client.HTTPClient.RootURL, fmt.Sprintf(
-			"which host/port to communicate with (i.e. the host/port %sd is listening on)",
+			"which host/port to communicate with (i.e. the host/port %sd is listening on), or unix:///path/to/api.sock to call it over its unix socket",
 			name))
+	client.RootCmd.PersistentFlags().DurationVar(&client.Timeout, "timeout", 0,
+		"time limit of each API call to the daemon, e.g. 30s, no limit if 0")
+	client.RootCmd.PersistentFlags().IntVar(&client.Retries, "retries", 0,
+		"amount of times an API call is retried when the daemon cannot be reached or is temporarily unavailable")
+	client.RootCmd.PersistentFlags().StringArrayVar(&client.TLSPins, "tls-pin", nil,
+		"only accept a daemon certificate with the given public key pin (sha256//<base64>), "+
+			"allowing self-signed certificates, can be given multiple times (requires an https address)")
+	client.RootCmd.PersistentFlags().StringVar(&client.TLSClientCert, "tls-client-cert", "",
+		"PEM-encoded certificate presented to a daemon requiring TLS client certificates (requires --tls-client-key)")
+	client.RootCmd.PersistentFlags().StringVar(&client.TLSClientKey, "tls-client-key", "",
+		"PEM-encoded private key of the TLS client certificate")
+	client.RootCmd.PersistentFlags().BoolVar(&client.JSONOutput, "json", false,
+		"print the output of the command as JSON, errors are still printed to stderr")
+	client.RootCmd.PersistentFlags().StringVar(&client.Units, "units", client.Units, fmt.Sprintf(
+		"units in which currency amounts are given and printed, %q or %q", UnitsCoin, UnitsBase))
+	client.RootCmd.PersistentFlags().StringVar(&client.settingsPath, "config", client.settingsPath, fmt.Sprintf(
+		"config file defining the default settings of the client, overwritten by %s* environment variables",
+		client.settingsEnvPrefix))
 
 	// return client
 	return client, nil
@@ -207,24 +260,72 @@ type CommandLineClient struct {
 
 	PreRunE func(*Config) (*Config, error)
 
-	RootCmd       *cobra.Command
-	WalletCmd     *WalletCommand
-	ConsensusCmd  *cobra.Command
-	AtomicSwapCmd *cobra.Command
-	GatewayCmd    *cobra.Command
-	ExploreCmd    *cobra.Command
-	MergeCmd      *cobra.Command
+	// JSONOutput defines if the output of all commands is printed as JSON,
+	// rather than in a human-friendly format.
+	JSONOutput bool
+	// Units in which currency amounts are given and printed,
+	// either UnitsCoin or UnitsBase.
+	Units string
+	// Timeout is the time limit of each API call, no limit if 0.
+	Timeout time.Duration
+	// Retries is the amount of times an API call is retried,
+	// see the Retries of api.HTTPClient.
+	Retries int
+	// TLSPins are the pins of the public keys of which the daemon certificate has to be,
+	// as returned by api.PublicKeyPin, none if the certificate is verified as usual.
+	TLSPins []string
+	// TLSClientCert and TLSClientKey are the paths of the certificate and key
+	// presented to the daemon, if it requires a client certificate.
+	TLSClientCert string
+	TLSClientKey  string
+
+	settingsPath      string
+	settingsEnvPrefix string
+	// unixSocket is the path of the unix socket of the daemon, if its address refers to one
+	unixSocket string
+
+	RootCmd         *cobra.Command
+	WalletCmd       *WalletCommand
+	ConsensusCmd    *cobra.Command
+	AtomicSwapCmd   *cobra.Command
+	GatewayCmd      *cobra.Command
+	ExploreCmd      *cobra.Command
+	MergeCmd        *cobra.Command
+	TransactionCmd  *cobra.Command
+	StatusCmd       *cobra.Command
+	BlockCreatorCmd *cobra.Command
+	DebugCmd        *cobra.Command
+	ConsoleCmd      *cobra.Command
+}
+
+// persistentPreRunE runs the pre-run checks of all commands,
+// printing errors as JSON in the JSON output mode, also when the checks themselves failed.
+func (client *CommandLineClient) persistentPreRunE(cmd *cobra.Command, args []string) error {
+	err := client.preRunE(cmd, args)
+	cli.SetJSONErrorOutput(client.JSONOutput)
+	return err
 }
 
 // preRunE checks that all preConditions match
-func (cli *CommandLineClient) preRunE(*cobra.Command, []string) error {
+func (cli *CommandLineClient) preRunE(cmd *cobra.Command, _ []string) error {
+	err := cli.applySettings(cmd)
+	if err != nil {
+		return err
+	}
+
 	address, err := sanitizeURL(cli.HTTPClient.RootURL)
 	if err != nil {
 		return fmt.Errorf("invalid daemon RPC address %q: %v", cli.HTTPClient.RootURL, err)
 	}
 	cli.HTTPClient.RootURL = address
+	err = cli.configureHTTPClient()
+	if err != nil {
+		return err
+	}
 
-	if cli.Config == nil {
+	// offline commands do not connect to the daemon, and thus do not require a config
+	offline := cmd.Annotations[annotationOffline] != ""
+	if cli.Config == nil && !offline {
 		var err error
 		cli.Config, err = FetchConfigFromDaemon(cli.HTTPClient)
 		if err != nil {
@@ -237,19 +338,172 @@ func (cli *CommandLineClient) preRunE(*cobra.Command, []string) error {
 			return fmt.Errorf("user-defined pre-run callback failed: %v", err)
 		}
 	}
-	if cli.Config == nil {
+	if cli.Config == nil && !offline {
 		return errors.New("cannot run command line client: no config is defined")
 	}
 	return nil
 }
 
+// configureHTTPClient configures the timeout, retries and TLS config of the HTTP client,
+// as defined by the flags and settings of the client.
+func (cli *CommandLineClient) configureHTTPClient() error {
+	if cli.Timeout < 0 {
+		return fmt.Errorf("invalid timeout %v: cannot be negative", cli.Timeout)
+	}
+	if cli.Retries < 0 {
+		return fmt.Errorf("invalid retries %d: cannot be negative", cli.Retries)
+	}
+	cli.HTTPClient.Retries = cli.Retries
+	if strings.HasPrefix(cli.HTTPClient.RootURL, unixSocketURLPrefix) {
+		return cli.configureUnixSocketHTTPClient()
+	}
+	if cli.Timeout == 0 && len(cli.TLSPins) == 0 && cli.TLSClientCert == "" && cli.TLSClientKey == "" {
+		return nil
+	}
+	client := &http.Client{Timeout: cli.Timeout}
+	var config *tls.Config
+	if len(cli.TLSPins) > 0 {
+		if !strings.HasPrefix(cli.HTTPClient.RootURL, "https://") {
+			return fmt.Errorf("cannot pin the certificate of daemon %q: an https address is required", cli.HTTPClient.RootURL)
+		}
+		var err error
+		config, err = api.NewPinnedTLSConfig(cli.TLSPins)
+		if err != nil {
+			return err
+		}
+	}
+	if cli.TLSClientCert != "" || cli.TLSClientKey != "" {
+		if cli.TLSClientCert == "" || cli.TLSClientKey == "" {
+			return errors.New("both a TLS client certificate and key are required")
+		}
+		if !strings.HasPrefix(cli.HTTPClient.RootURL, "https://") {
+			return fmt.Errorf("cannot present a client certificate to daemon %q: an https address is required", cli.HTTPClient.RootURL)
+		}
+		cert, err := tls.LoadX509KeyPair(cli.TLSClientCert, cli.TLSClientKey)
+		if err != nil {
+			return fmt.Errorf("failed to load TLS client certificate: %v", err)
+		}
+		if config == nil {
+			config = &tls.Config{}
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	if config != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = config
+		client.Transport = transport
+	}
+	cli.HTTPClient.Client = client
+	return nil
+}
+
+// configureUnixSocketHTTPClient configures the HTTP client to call the daemon
+// over the unix socket its address refers to, which requires no API password.
+func (cli *CommandLineClient) configureUnixSocketHTTPClient() error {
+	if len(cli.TLSPins) > 0 || cli.TLSClientCert != "" || cli.TLSClientKey != "" {
+		return fmt.Errorf("cannot use TLS to call daemon %q: an https address is required", cli.HTTPClient.RootURL)
+	}
+	path := strings.TrimPrefix(cli.HTTPClient.RootURL, unixSocketURLPrefix)
+	cli.unixSocket = path
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+		var dialer net.Dialer
+		return dialer.DialContext(ctx, "unix", path)
+	}
+	cli.HTTPClient.Client = &http.Client{Timeout: cli.Timeout, Transport: transport}
+	// the host is ignored by the transport, as all calls are made over the socket
+	cli.HTTPClient.RootURL = "http://unix"
+	return nil
+}
+
+// daemonAddress returns the address of the daemon, as given by the user.
+func (cli *CommandLineClient) daemonAddress() string {
+	if cli.unixSocket != "" {
+		return unixSocketURLPrefix + cli.unixSocket
+	}
+	return cli.HTTPClient.RootURL
+}
+
+// annotationOffline is the annotation key of commands which do not connect to the daemon,
+// such that the config is not fetched from the daemon prior to running them.
+const annotationOffline = "offline"
+
+// PrintOutput prints the output of a command, JSON-encoded if the JSON output mode is enabled,
+// and otherwise using the given function, printing it in a human-friendly format.
+func (cli *CommandLineClient) PrintOutput(v interface{}, human func()) {
+	if cli.JSONOutput {
+		printJSON(v)
+		return
+	}
+	human()
+}
+
+// InfoWriter returns the writer to which informational messages and prompts
+// of a command are to be written, being stderr if the JSON output mode is enabled,
+// such that the standard output only contains the JSON-encoded output.
+func (cli *CommandLineClient) InfoWriter() io.Writer {
+	if cli.JSONOutput {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// outputEncodingType returns the JSON encoding type if the human encoding type is given
+// while the JSON output mode of the client is enabled, and the given encoding type otherwise.
+func outputEncodingType(client *CommandLineClient, encodingType cli.EncodingType) cli.EncodingType {
+	if client.JSONOutput && encodingType == cli.EncodingTypeHuman {
+		return cli.EncodingTypeJSON
+	}
+	return encodingType
+}
+
+// printJSON prints the given value, JSON-encoded, to the standard output.
+func printJSON(v interface{}) {
+	err := json.NewEncoder(os.Stdout).Encode(v)
+	if err != nil {
+		cli.Die("failed to encode output as JSON:", err)
+	}
+}
+
+type (
+	// EmptyOutput represents the formatted output
+	// of commands which have no output other than their success.
+	EmptyOutput struct{}
+	// FileOutput represents the formatted output
+	// of commands which write their result to a file.
+	FileOutput struct {
+		Path string `json:"path"`
+	}
+	// VersionOutput represents the formatted output
+	// of the version command.
+	VersionOutput struct {
+		ChainName    string                `json:"chainname"`
+		ChainVersion build.ProtocolVersion `json:"chainversion"`
+		GoVersion    string                `json:"goversion"`
+		GOOS         string                `json:"goos"`
+		GOARCH       string                `json:"goarch"`
+	}
+)
+
 // Run the CLI, logic dependend upon the command the user used.
 func (cli *CommandLineClient) Run() error {
 	return cli.RootCmd.Execute()
 }
 
-// CreateCurrencyConvertor creates a currency convertor using the internally stored Config.
+// CreateCurrencyConvertor creates a currency convertor using the internally stored Config,
+// expressing amounts in the base unit instead of the one coin unit if defined so by the user.
 func (cli *CommandLineClient) CreateCurrencyConvertor() CurrencyConvertor {
+	if cli.Units == UnitsBase {
+		cc := NewCurrencyConvertor(
+			types.CurrencyUnits{OneCoin: types.NewCurrency64(1)},
+			"base units of "+cli.Config.CurrencyCoinUnit,
+		)
+		// amounts with an explicit unit are still parsed using the one coin unit of the chain
+		oneCoin := NewCurrencyConvertor(cli.Config.CurrencyUnits, cli.Config.CurrencyCoinUnit)
+		cc.oneCoinPrecision, cc.oneCoinUnit = oneCoin.precision, oneCoin.coinUnit
+		return cc
+	}
 	return NewCurrencyConvertor(
 		cli.Config.CurrencyUnits,
 		cli.Config.CurrencyCoinUnit,
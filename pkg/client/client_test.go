@@ -0,0 +1,58 @@
+package client
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/threefoldtech/rivine/pkg/cli"
+)
+
+func TestPrintOutput(t *testing.T) {
+	capture := func(f func()) string {
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		stdout := os.Stdout
+		os.Stdout = w
+		defer func() { os.Stdout = stdout }()
+		f()
+		w.Close()
+		b, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+
+	client := &CommandLineClient{}
+	print := func() {
+		client.PrintOutput(FileOutput{Path: "txn.json"}, func() {
+			os.Stdout.WriteString("written to txn.json\n")
+		})
+	}
+	if out := capture(print); out != "written to txn.json\n" {
+		t.Errorf("unexpected human output: %q", out)
+	}
+	if client.InfoWriter() != os.Stdout {
+		t.Error("informational messages should be printed to stdout")
+	}
+	if et := outputEncodingType(client, cli.EncodingTypeHuman); et != cli.EncodingTypeHuman {
+		t.Error("unexpected encoding type:", et)
+	}
+
+	client.JSONOutput = true
+	if out := capture(print); out != `{"path":"txn.json"}`+"\n" {
+		t.Errorf("unexpected JSON output: %q", out)
+	}
+	if client.InfoWriter() != os.Stderr {
+		t.Error("informational messages should be printed to stderr")
+	}
+	if et := outputEncodingType(client, cli.EncodingTypeHuman); et != cli.EncodingTypeJSON {
+		t.Error("unexpected encoding type:", et)
+	}
+	if et := outputEncodingType(client, cli.EncodingTypeHex); et != cli.EncodingTypeHex {
+		t.Error("unexpected encoding type:", et)
+	}
+}
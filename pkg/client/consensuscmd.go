@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"text/tabwriter"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -31,8 +32,15 @@ func createConsensusCmd(client *CommandLineClient) (*consensusCmd, *cobra.Comman
 			Long:  "Get an existing transaction from the blockchain, using its given shortID or longID.",
 			Run:   Wrap(consensusCmd.transactionCmd),
 		}
+		subscribersCmd = &cobra.Command{
+			Use:   "subscribers",
+			Short: "List the consensus set subscribers",
+			Long: `List the subscribers of the consensus set, along with the dispatch metrics
+of the asynchronous subscribers, such as their pending changes and lag.`,
+			Run: Wrap(consensusCmd.subscribersCmd),
+		}
 	)
-	rootCmd.AddCommand(transactionCmd)
+	rootCmd.AddCommand(transactionCmd, subscribersCmd)
 
 	// create flags
 	transactionCmd.Flags().Var(
@@ -58,13 +66,29 @@ func (consensusCmd *consensusCmd) rootCmd() {
 	if err != nil {
 		cli.Die("Could not get current consensus state:", err)
 	}
+	if consensusCmd.cli.JSONOutput {
+		printJSON(cg)
+		return
+	}
 	if cg.Synced {
 		fmt.Printf(`Synced: %v
 Block:  %v
 Height: %v
 Target: %v
 `, YesNo(cg.Synced), cg.CurrentBlock, cg.Height, cg.Target)
+	} else if cg.EstimatedNetworkHeight > 0 {
+		// sync progress as reported by the daemon
+		fmt.Printf(`Synced: %v
+Height: %v
+Network Height (estimated): %v
+Progress (estimated): %.2f%%
+Speed: %.2f blocks/s
+`, YesNo(cg.Synced), cg.Height, cg.EstimatedNetworkHeight, cg.SyncProgress*100, cg.BlocksPerSecond)
+		if cg.SyncETA > 0 {
+			fmt.Printf("ETA: %v\n", time.Duration(cg.SyncETA)*time.Second)
+		}
 	} else {
+		// older daemons do not report their sync progress, estimate it locally
 		estimatedHeight := consensusCmd.estimatedHeightAt(time.Now())
 		estimatedProgress := float64(cg.Height) / float64(estimatedHeight) * 100
 		if estimatedProgress > 99 {
@@ -112,7 +136,7 @@ func (consensusCmd *consensusCmd) transactionCmd(id string) {
 	}
 
 	var encode func(interface{}) error
-	switch consensusCmd.transactionCfg.EncodingType {
+	switch outputEncodingType(consensusCmd.cli, consensusCmd.transactionCfg.EncodingType) {
 	case cli.EncodingTypeHuman:
 		e := json.NewEncoder(os.Stdout)
 		e.SetIndent("", "  ")
@@ -132,3 +156,29 @@ func (consensusCmd *consensusCmd) transactionCmd(id string) {
 		cli.Die("failed to encode transaction:", err, "; ID:", id)
 	}
 }
+
+// subscribersCmd is the handler for the command `rivinec consensus subscribers`.
+// Prints the subscribers of the consensus set and their dispatch metrics.
+func (consensusCmd *consensusCmd) subscribersCmd() {
+	var resp api.ConsensusGetSubscribers
+	err := consensusCmd.cli.GetAPI("/consensus/subscribers", &resp)
+	if err != nil {
+		cli.Die("Could not get the consensus set subscribers:", err)
+	}
+	if consensusCmd.cli.JSONOutput {
+		printJSON(resp)
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Name\tAsync\tPolicy\tQueue\tDelivered\tDropped\tLast Lag\tMax Lag\tDisconnected")
+	for _, s := range resp.Subscribers {
+		if !s.Async {
+			fmt.Fprintf(w, "%s\t%v\t-\t-\t-\t-\t-\t-\t-\n", s.Name, YesNo(s.Async))
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%v\t%v\t%d/%d\t%d\t%d\t%v\t%v\t%v\n",
+			s.Name, YesNo(s.Async), s.OverflowPolicy, s.QueueLength, s.QueueSize,
+			s.Delivered, s.Dropped, s.LastLag, s.MaxLag, YesNo(s.Disconnected))
+	}
+	w.Flush()
+}
@@ -0,0 +1,261 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/threefoldtech/rivine/pkg/api"
+	"github.com/threefoldtech/rivine/pkg/cli"
+	"github.com/threefoldtech/rivine/pkg/lineeditor"
+	"github.com/threefoldtech/rivine/types"
+)
+
+func createConsoleCmd(client *CommandLineClient) *cobra.Command {
+	consoleCmd := &consoleCmd{cli: client}
+	return &cobra.Command{
+		Use:   "console",
+		Short: "Start an interactive console",
+		Long: `Start an interactive console, in which commands can be given without the program name,
+e.g. "wallet balance", such that multi-step flows (e.g. multisig and atomic swaps)
+can be executed without re-typing the global flags or API password for each command.
+
+The API password is asked (at most) once when starting the console.
+The console supports the common line editing keys, a command history using the up and down keys,
+and tab completion of commands, flags and the addresses of the wallet.
+The history is kept in memory only, such that secrets given as arguments are not stored.
+Use "exit", "quit" or Ctrl+D to leave the console.`,
+		Args: cobra.NoArgs,
+		Run:  Wrap(consoleCmd.rootCmd),
+	}
+}
+
+type consoleCmd struct {
+	cli       *CommandLineClient
+	addresses []types.UnlockHash
+}
+
+// console commands which are handled by the console itself
+var consoleExitCommands = []string{"exit", "quit"}
+
+// rootCmd is the handler for the command `rivinec console`.
+// Reads and executes commands until the user exits the console.
+func (consoleCmd *consoleCmd) rootCmd() {
+	executable, err := os.Executable()
+	if err != nil {
+		cli.Die("failed to locate the client executable:", err)
+	}
+	// loading the addresses authenticates against the daemon,
+	// such that the API password is asked only once
+	err = consoleCmd.loadAddresses()
+	if err != nil {
+		if code := cli.ErrorExitCode(err); code == cli.ExitCodeUnreachable || code == cli.ExitCodeForbidden {
+			cli.DieWithError("failed to connect to the daemon:", err)
+		}
+	}
+
+	editor := lineeditor.New(os.Stdin, os.Stdout)
+	editor.Prompt = strings.ToLower(consoleCmd.cli.Config.ChainName) + "> "
+	editor.Complete = func(line string) []string {
+		return completeConsoleLine(consoleCmd.cli.RootCmd, line, consoleCmd.addresses)
+	}
+	if editor.IsTerminal() {
+		fmt.Printf("%s console, type \"help\" for a list of commands and \"exit\" to leave.\n",
+			strings.Title(consoleCmd.cli.Config.ChainName))
+	}
+	for {
+		line, err := editor.ReadLine()
+		if err == lineeditor.ErrInterrupted {
+			continue
+		}
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			cli.Die("failed to read command:", err)
+		}
+		editor.AddHistory(line)
+		args, err := splitConsoleLine(line)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			continue
+		}
+		if len(args) == 0 {
+			continue
+		}
+		switch args[0] {
+		case consoleExitCommands[0], consoleExitCommands[1]:
+			return
+		case "console":
+			fmt.Fprintln(os.Stderr, "already running the console")
+			continue
+		}
+		consoleCmd.execute(executable, args)
+		if args[0] == "wallet" {
+			// the command might have unlocked the wallet or generated a new address
+			consoleCmd.loadAddresses()
+		}
+	}
+}
+
+// loadAddresses loads the addresses of the wallet, used for tab completion.
+func (consoleCmd *consoleCmd) loadAddresses() error {
+	var addrs api.WalletAddressesGET
+	err := consoleCmd.cli.GetAPI("/wallet/addresses", &addrs)
+	if err != nil {
+		return err
+	}
+	consoleCmd.addresses = addrs.Addresses
+	return nil
+}
+
+// execute executes a single command, as a child process using the same settings as the console,
+// such that each command starts with the default flag values, and can exit without exiting the console.
+func (consoleCmd *consoleCmd) execute(executable string, args []string) {
+	prefix := consoleCmd.cli.settingsEnvPrefix
+	cmd := exec.Command(executable, args...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.Env = append(os.Environ(),
+		prefix+"ADDR="+consoleCmd.cli.daemonAddress(),
+		prefix+"UNITS="+consoleCmd.cli.Units,
+		prefix+"JSON="+strconv.FormatBool(consoleCmd.cli.JSONOutput),
+		prefix+"TIMEOUT="+consoleCmd.cli.Timeout.String(),
+		prefix+"RETRIES="+strconv.Itoa(consoleCmd.cli.Retries),
+		prefix+"TLS_PINS="+strings.Join(consoleCmd.cli.TLSPins, ","),
+		prefix+"TLS_CLIENT_CERT="+consoleCmd.cli.TLSClientCert,
+		prefix+"TLS_CLIENT_KEY="+consoleCmd.cli.TLSClientKey)
+	if password := consoleCmd.cli.HTTPClient.Password; password != "" {
+		cmd.Env = append(cmd.Env, prefix+"API_PASSWORD="+password)
+	}
+
+	// Ctrl+C interrupts the command, not the console
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+
+	err := cmd.Run()
+	if _, ok := err.(*exec.ExitError); err != nil && !ok {
+		// exit errors are already reported by the command itself
+		fmt.Fprintln(os.Stderr, "failed to execute command:", err)
+	}
+}
+
+// splitConsoleLine splits a console line into its arguments, separated by whitespace,
+// supporting single quotes, double quotes and backslash escapes in the same way as a POSIX shell,
+// such that arguments containing whitespace (e.g. JSON-encoded conditions) can be given.
+func splitConsoleLine(line string) ([]string, error) {
+	var (
+		args  []string
+		arg   []rune
+		inArg bool
+		quote rune
+	)
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				arg = append(arg, r)
+			}
+		case r == '\\' && (quote == 0 || (i+1 < len(runes) && strings.ContainsRune(`"\$`+"`", runes[i+1]))):
+			if i+1 == len(runes) {
+				return nil, errors.New("unexpected backslash at the end of the line")
+			}
+			i++
+			arg, inArg = append(arg, runes[i]), true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				arg = append(arg, r)
+			}
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case unicode.IsSpace(r):
+			if inArg {
+				args = append(args, string(arg))
+				arg, inArg = nil, false
+			}
+		default:
+			arg, inArg = append(arg, r), true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inArg {
+		args = append(args, string(arg))
+	}
+	return args, nil
+}
+
+// completeConsoleLine returns the completion candidates of the last word of the given line:
+// the flags of the command if the word starts with a dash, its subcommands if it has any,
+// and the given addresses otherwise.
+func completeConsoleLine(root *cobra.Command, line string, addresses []types.UnlockHash) []string {
+	words := strings.Fields(line)
+	word := ""
+	if len(words) > 0 && !unicode.IsSpace(rune(line[len(line)-1])) {
+		word, words = words[len(words)-1], words[:len(words)-1]
+	}
+
+	cmd := root
+	for _, w := range words {
+		if strings.HasPrefix(w, "-") {
+			continue
+		}
+		for _, sub := range cmd.Commands() {
+			if sub.Name() == w || sub.HasAlias(w) {
+				cmd = sub
+				break
+			}
+		}
+	}
+
+	var candidates []string
+	add := func(candidate string) {
+		if strings.HasPrefix(candidate, word) {
+			candidates = append(candidates, candidate)
+		}
+	}
+	switch {
+	case strings.HasPrefix(word, "-"):
+		addFlag := func(flag *pflag.Flag) {
+			if !flag.Hidden {
+				add("--" + flag.Name)
+			}
+		}
+		cmd.NonInheritedFlags().VisitAll(addFlag)
+		cmd.InheritedFlags().VisitAll(addFlag)
+	case cmd.HasAvailableSubCommands():
+		for _, sub := range cmd.Commands() {
+			if sub.IsAvailableCommand() {
+				add(sub.Name())
+			}
+		}
+		if cmd == root {
+			for _, name := range consoleExitCommands {
+				add(name)
+			}
+		}
+	default:
+		for _, address := range addresses {
+			add(address.String())
+		}
+	}
+	sort.Strings(candidates)
+	return candidates
+}
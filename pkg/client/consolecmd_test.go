@@ -0,0 +1,73 @@
+package client
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/types"
+)
+
+func TestSplitConsoleLine(t *testing.T) {
+	testCases := []struct {
+		Line     string
+		Expected []string
+	}{
+		{"", nil},
+		{"  wallet   balance ", []string{"wallet", "balance"}},
+		{`wallet send coins '{"type":1, "data":null}' 10`, []string{"wallet", "send", "coins", `{"type":1, "data":null}`, "10"}},
+		{`tx create --data "hello \"world\""`, []string{"tx", "create", "--data", `hello "world"`}},
+		{`a\ b "c\d" 'e\f' ""`, []string{"a b", `c\d`, `e\f`, ""}},
+		{`--data='it'\''s fine'`, []string{"--data=it's fine"}},
+	}
+	for _, testCase := range testCases {
+		args, err := splitConsoleLine(testCase.Line)
+		if err != nil {
+			t.Errorf("failed to split %q: %v", testCase.Line, err)
+		} else if !reflect.DeepEqual(args, testCase.Expected) {
+			t.Errorf("unexpected arguments for %q: %q != %q", testCase.Line, args, testCase.Expected)
+		}
+	}
+	for _, line := range []string{`'unterminated`, `"unterminated`, `trailing\`} {
+		if _, err := splitConsoleLine(line); err == nil {
+			t.Errorf("expected an error for %q", line)
+		}
+	}
+}
+
+func TestCompleteConsoleLine(t *testing.T) {
+	root := &cobra.Command{Use: "rivinec"}
+	root.PersistentFlags().Bool("json", false, "")
+	wallet := &cobra.Command{Use: "wallet"}
+	send := &cobra.Command{Use: "send", Run: func(*cobra.Command, []string) {}}
+	send.Flags().String("data", "", "")
+	wallet.AddCommand(send, &cobra.Command{Use: "seeds", Run: func(*cobra.Command, []string) {}})
+	root.AddCommand(wallet, &cobra.Command{Use: "status", Run: func(*cobra.Command, []string) {}})
+
+	addresses := []types.UnlockHash{
+		{Type: types.UnlockTypePubKey, Hash: crypto.Hash{1}},
+		{Type: types.UnlockTypeMultiSig, Hash: crypto.Hash{2}},
+	}
+	testCases := []struct {
+		Line     string
+		Expected []string
+	}{
+		{"", []string{"exit", "quit", "status", "wallet"}},
+		{"st", []string{"status"}},
+		{"wallet ", []string{"seeds", "send"}},
+		{"wallet sen", []string{"send"}},
+		{"wallet send --", []string{"--data", "--json"}},
+		{"wallet send --d", []string{"--data"}},
+		{"wallet send ", []string{addresses[0].String(), addresses[1].String()}},
+		{"wallet send --data x 03", []string{addresses[1].String()}},
+		{"unknown x", nil},
+	}
+	for _, testCase := range testCases {
+		candidates := completeConsoleLine(root, testCase.Line, addresses)
+		if !reflect.DeepEqual(candidates, testCase.Expected) {
+			t.Errorf("unexpected candidates for %q: %q != %q", testCase.Line, candidates, testCase.Expected)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/threefoldtech/rivine/pkg/api"
+	"github.com/threefoldtech/rivine/pkg/cli"
+)
+
+func createDebugCmd(client *CommandLineClient) *cobra.Command {
+	debugCmd := &debugCmd{cli: client}
+
+	// create root debug command and all subs
+	var (
+		rootCmd = &cobra.Command{
+			Use:   "debug",
+			Short: "Inspect the internals of the daemon",
+			Long:  "Inspect the internals of the daemon, requiring the API password or an admin API token.",
+		}
+		databaseCmd = &cobra.Command{
+			Use:   "database",
+			Short: "List the modules of which the database can be inspected",
+			Long: `List the modules of which the database can be inspected, while the daemon is running.
+The databases are only read, such that inspecting them never modifies the state of the daemon.`,
+			Run: Wrap(debugCmd.databaseCmd),
+		}
+		bucketsCmd = &cobra.Command{
+			Use:   "buckets <module>",
+			Short: "List the buckets of the database of a module",
+			Long: `List the top-level buckets of the database of a module, e.g. consensus,
+with the amount of keys and nested buckets they contain.
+The nested buckets are listed as entries of their parent bucket.`,
+			Run: Wrap(debugCmd.bucketsCmd),
+		}
+		entriesCmd = &cobra.Command{
+			Use:   "entries <module> <bucket>",
+			Short: "List the keys and values of a bucket of the database of a module",
+			Long: `List the keys and values of a bucket of the database of a module, hex-encoded,
+as well as the value decoded on a best-effort basis.
+
+The bucket is the slash-separated path of a (nested) bucket, e.g. BlockMap,
+of which each name is given as text or hex-encoded prefixed by 0x, as listed.
+Use the cursor printed at the end of the list to get the next entries.`,
+			Run: Wrap(debugCmd.entriesCmd),
+		}
+	)
+	rootCmd.AddCommand(databaseCmd)
+	databaseCmd.AddCommand(bucketsCmd, entriesCmd)
+	entriesCmd.Flags().StringVar(&debugCmd.entriesCfg.Prefix, "prefix", "",
+		"only list the keys with this prefix, as text or hex-encoded prefixed by 0x")
+	entriesCmd.Flags().IntVar(&debugCmd.entriesCfg.Limit, "limit", 0,
+		"maximum amount of entries listed, the default of the daemon if 0")
+	entriesCmd.Flags().StringVar(&debugCmd.entriesCfg.Cursor, "cursor", "",
+		"list the entries following this cursor, as printed by the previous call")
+	entriesCmd.Flags().BoolVar(&debugCmd.entriesCfg.Reverse, "reverse", false,
+		"list the keys in descending order")
+	entriesCmd.Flags().IntVar(&debugCmd.entriesCfg.MaxValueSize, "max-value-size", -1,
+		"size in bytes to which values are truncated, the default of the daemon if negative, unlimited if 0")
+
+	// return root command
+	return rootCmd
+}
+
+type debugCmd struct {
+	cli        *CommandLineClient
+	entriesCfg struct {
+		Prefix       string
+		Limit        int
+		Cursor       string
+		Reverse      bool
+		MaxValueSize int
+	}
+}
+
+// databaseCmd is the handler for the command `rivinec debug database`.
+// Prints the modules of which the database can be inspected.
+func (debugCmd *debugCmd) databaseCmd() {
+	var resp api.DebugDatabaseGET
+	err := debugCmd.cli.GetAPI("/debug/database", &resp)
+	if err != nil {
+		cli.DieWithError("Could not get the inspectable databases:", err)
+	}
+	debugCmd.cli.PrintOutput(resp, func() {
+		if len(resp.Modules) == 0 {
+			fmt.Println("No module databases can be inspected.")
+			return
+		}
+		for _, module := range resp.Modules {
+			fmt.Println(module)
+		}
+	})
+}
+
+// bucketsCmd is the handler for the command `rivinec debug database buckets <module>`.
+// Prints the top-level buckets of the database of a module.
+func (debugCmd *debugCmd) bucketsCmd(module string) {
+	var resp api.DebugDatabaseModuleGET
+	err := debugCmd.cli.GetAPI("/debug/database/"+url.PathEscape(module), &resp)
+	if err != nil {
+		cli.DieWithError("Could not get the database buckets:", err)
+	}
+	debugCmd.cli.PrintOutput(resp, func() {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		defer w.Flush()
+		fmt.Fprintln(w, "Bucket\tKeys\tNested Buckets")
+		for _, b := range resp.Buckets {
+			fmt.Fprintf(w, "%s\t%d\t%d\n", b.Name, b.Keys, b.Buckets)
+		}
+	})
+}
+
+// entriesCmd is the handler for the command `rivinec debug database entries <module> <bucket>`.
+// Prints the keys and values of a bucket of the database of a module.
+func (debugCmd *debugCmd) entriesCmd(module, bucket string) {
+	var resp api.DebugDatabaseEntriesGET
+	err := debugCmd.cli.GetAPI("/debug/database/"+url.PathEscape(module)+"/entries?"+debugCmd.entriesQuery(bucket).Encode(), &resp)
+	if err != nil {
+		cli.DieWithError("Could not get the database entries:", err)
+	}
+	debugCmd.cli.PrintOutput(resp, func() {
+		for _, entry := range resp.Entries {
+			key := entry.Key
+			if entry.KeyText != "" {
+				key = fmt.Sprintf("%s (%q)", entry.Key, entry.KeyText)
+			}
+			if entry.Bucket {
+				fmt.Printf("%s: nested bucket\n", key)
+				continue
+			}
+			fmt.Printf("%s: %d bytes\n", key, entry.ValueSize)
+			value := entry.Value
+			if entry.Truncated {
+				value += "..."
+			}
+			fmt.Printf("  value:   %s\n", value)
+			if entry.Decoded != nil {
+				b, err := json.Marshal(entry.Decoded)
+				if err == nil {
+					fmt.Printf("  decoded: %s\n", b)
+				}
+			}
+		}
+		if len(resp.Entries) == 0 {
+			fmt.Println("No entries found.")
+		}
+		if resp.NextCursor != "" {
+			fmt.Printf("\nMore entries can be listed using --cursor %s\n", resp.NextCursor)
+		}
+	})
+}
+
+// entriesQuery returns the query of the API call listing the entries of the given bucket,
+// as configured using the flags of the command.
+func (debugCmd *debugCmd) entriesQuery(bucket string) url.Values {
+	cfg := debugCmd.entriesCfg
+	query := url.Values{"bucket": {bucket}}
+	if cfg.Prefix != "" {
+		query.Set("prefix", cfg.Prefix)
+	}
+	if cfg.Limit > 0 {
+		query.Set("limit", strconv.Itoa(cfg.Limit))
+	}
+	if cfg.Cursor != "" {
+		query.Set("cursor", cfg.Cursor)
+	}
+	if cfg.Reverse {
+		query.Set("order", string(api.ListOrderDescending))
+	}
+	if cfg.MaxValueSize >= 0 {
+		query.Set("maxvaluesize", strconv.Itoa(cfg.MaxValueSize))
+	}
+	return query
+}
@@ -4,13 +4,18 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
+	"strconv"
+	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 
 	"github.com/threefoldtech/rivine/pkg/api"
 	"github.com/threefoldtech/rivine/pkg/cli"
 	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+	"github.com/threefoldtech/rivine/types"
 )
 
 func createExploreCmd(client *CommandLineClient) *cobra.Command {
@@ -21,14 +26,27 @@ func createExploreCmd(client *CommandLineClient) *cobra.Command {
 		rootCmd = &cobra.Command{
 			Use:   "explore",
 			Short: "Explore the blockchain",
-			Long:  "Explore the blockchain using the daemon's explorer module.",
+			Long: `Explore the blockchain using the daemon's explorer module,
+or the explorer module of a remote daemon, defined using the --explorer flag.`,
 		}
 		blockCmd = &cobra.Command{
-			Use:   "block <height>",
+			Use:   "block <height>|<blockID>",
 			Short: "Explore a block on the blockchain",
-			Long:  "Explore a block on the blockchain, using its ID.",
+			Long:  "Explore a block on the blockchain, using its height or ID.",
 			Run:   Wrap(exploreCmd.blockCmd),
 		}
+		txCmd = &cobra.Command{
+			Use:   "tx <transactionID>",
+			Short: "Explore a transaction on the blockchain",
+			Long:  "Explore a (confirmed or unconfirmed) transaction on the blockchain, using its ID.",
+			Run:   Wrap(exploreCmd.txCmd),
+		}
+		addressCmd = &cobra.Command{
+			Use:   "address <unlockhash>",
+			Short: "Explore an address on the blockchain",
+			Long:  "Explore the transactions and blocks linked to an address on the blockchain.",
+			Run:   Wrap(exploreCmd.addressCmd),
+		}
 		hashCmd = &cobra.Command{
 			Use:   "hash <unlockhash>|<transactionID>|<blockID>|<outputID>",
 			Short: "Explore an item on the blockchain",
@@ -36,15 +54,29 @@ func createExploreCmd(client *CommandLineClient) *cobra.Command {
 			Run:   Wrap(exploreCmd.hashCmd),
 		}
 	)
-	rootCmd.AddCommand(blockCmd, hashCmd)
+	rootCmd.AddCommand(blockCmd, txCmd, addressCmd, hashCmd)
 
 	// create flags
+	rootCmd.PersistentFlags().StringVar(
+		&exploreCmd.rootCfg.ExplorerAddress, "explorer", "",
+		"address of a remote daemon with the explorer module to query, instead of the local daemon")
 	blockCmd.Flags().Var(
 		cli.NewEncodingTypeFlag(0, &exploreCmd.blockCfg.EncodingType, cli.EncodingTypeHuman|cli.EncodingTypeJSON|cli.EncodingTypeHex), "encoding",
 		cli.EncodingTypeFlagDescription(cli.EncodingTypeHuman|cli.EncodingTypeJSON|cli.EncodingTypeHex))
 	blockCmd.Flags().BoolVar(
 		&exploreCmd.blockCfg.BlockOnly, "block-only", false, "print the raw block only")
 
+	txCmd.Flags().Var(
+		cli.NewEncodingTypeFlag(0, &exploreCmd.txCfg.EncodingType, cli.EncodingTypeHuman|cli.EncodingTypeJSON|cli.EncodingTypeHex), "encoding",
+		cli.EncodingTypeFlagDescription(cli.EncodingTypeHuman|cli.EncodingTypeJSON|cli.EncodingTypeHex))
+
+	addressCmd.Flags().Var(
+		cli.NewEncodingTypeFlag(0, &exploreCmd.addressCfg.EncodingType, cli.EncodingTypeHuman|cli.EncodingTypeJSON), "encoding",
+		cli.EncodingTypeFlagDescription(cli.EncodingTypeHuman|cli.EncodingTypeJSON))
+	addressCmd.Flags().Uint64Var(
+		&exploreCmd.addressCfg.MinHeight, "min-height", 0,
+		"only show the transactions and blocks since a given height")
+
 	hashCmd.Flags().Var(
 		cli.NewEncodingTypeFlag(0, &exploreCmd.hashCfg.EncodingType, cli.EncodingTypeHuman|cli.EncodingTypeJSON), "encoding",
 		cli.EncodingTypeFlagDescription(cli.EncodingTypeHuman|cli.EncodingTypeJSON))
@@ -57,11 +89,21 @@ func createExploreCmd(client *CommandLineClient) *cobra.Command {
 }
 
 type exploreCmd struct {
-	cli      *CommandLineClient
+	cli     *CommandLineClient
+	rootCfg struct {
+		ExplorerAddress string
+	}
 	blockCfg struct {
 		EncodingType cli.EncodingType
 		BlockOnly    bool
 	}
+	txCfg struct {
+		EncodingType cli.EncodingType
+	}
+	addressCfg struct {
+		EncodingType cli.EncodingType
+		MinHeight    uint64
+	}
 	hashCfg struct {
 		EncodingType cli.EncodingType
 		MinHeight    uint64
@@ -69,24 +111,32 @@ type exploreCmd struct {
 }
 
 // blockCmd is the handler for the command `rivinec explore block`,
-// explores a block on the blockchain, by looking it up by its height,
+// explores a block on the blockchain, by looking it up by its height or ID,
 // and printing either all info, or just the raw block itself.
-func (cmd *exploreCmd) blockCmd(blockHeightStr string) {
-	// get the block on the given height, using the daemon's explorer module
-	var resp api.ExplorerBlockGET
-	err := cmd.cli.GetAPI("/explorer/blocks/"+blockHeightStr, &resp)
-	if err != nil {
-		cli.Die(fmt.Sprintf("Could not get a block on height %q: %v", blockHeightStr, err))
+func (cmd *exploreCmd) blockCmd(blockStr string) {
+	var block api.ExplorerBlock
+	if _, err := strconv.ParseUint(blockStr, 10, 64); err == nil {
+		// get the block on the given height, using the explorer module
+		var resp api.ExplorerBlockGET
+		err = cmd.getAPI("/explorer/blocks/"+blockStr, &resp)
+		if err != nil {
+			cli.Die(fmt.Sprintf("Could not get a block on height %q: %v", blockStr, err))
+		}
+		block = resp.Block
+	} else {
+		// get the block with the given ID, using the explorer module
+		resp := cmd.getHash(blockStr, api.HashTypeBlockIDStr, 0)
+		block = resp.Block
 	}
 
 	// define the value to print
-	value := interface{}(resp.Block)
+	value := interface{}(block)
 	if cmd.blockCfg.BlockOnly {
-		value = resp.Block.RawBlock
+		value = block.RawBlock
 	}
 
 	// print depending on the encoding type
-	switch cmd.blockCfg.EncodingType {
+	switch outputEncodingType(cmd.cli, cmd.blockCfg.EncodingType) {
 	case cli.EncodingTypeHex:
 		enc := siabin.NewEncoder(hex.NewEncoder(os.Stdout))
 		enc.Encode(value)
@@ -94,12 +144,90 @@ func (cmd *exploreCmd) blockCmd(blockHeightStr string) {
 	case cli.EncodingTypeJSON:
 		json.NewEncoder(os.Stdout).Encode(value)
 	default:
-		e := json.NewEncoder(os.Stdout)
-		e.SetIndent("", "  ")
-		e.Encode(value)
+		if cmd.blockCfg.BlockOnly {
+			e := json.NewEncoder(os.Stdout)
+			e.SetIndent("", "  ")
+			e.Encode(value)
+			return
+		}
+		printExplorerBlock(os.Stdout, block, cmd.cli.CreateCurrencyConvertor())
 	}
 }
 
+// txCmd is the handler for the command `rivinec explore tx`,
+// explores a transaction on the blockchain, by looking it up by its ID.
+func (cmd *exploreCmd) txCmd(id string) {
+	resp := cmd.getHash(id, api.HashTypeTransactionIDStr, 0)
+
+	// print depending on the encoding type
+	switch outputEncodingType(cmd.cli, cmd.txCfg.EncodingType) {
+	case cli.EncodingTypeHex:
+		enc := siabin.NewEncoder(hex.NewEncoder(os.Stdout))
+		enc.Encode(resp.Transaction.RawTransaction)
+		fmt.Println()
+	case cli.EncodingTypeJSON:
+		json.NewEncoder(os.Stdout).Encode(resp.Transaction)
+	default:
+		printExplorerTransaction(os.Stdout, resp.Transaction, cmd.cli.CreateCurrencyConvertor())
+	}
+}
+
+// addressCmd is the handler for the command `rivinec explore address`,
+// explores an address on the blockchain, by looking up all transactions and blocks linked to it.
+func (cmd *exploreCmd) addressCmd(address string) {
+	var uh types.UnlockHash
+	err := uh.LoadString(address)
+	if err != nil {
+		cli.DieWithExitCode(cli.ExitCodeInvalidAddress, fmt.Sprintf("Invalid address %q: %v", address, err))
+	}
+	resp := cmd.getHash(address, api.HashTypeUnlockHashStr, cmd.addressCfg.MinHeight)
+
+	// print depending on the encoding type
+	switch outputEncodingType(cmd.cli, cmd.addressCfg.EncodingType) {
+	case cli.EncodingTypeJSON:
+		json.NewEncoder(os.Stdout).Encode(resp)
+	default:
+		printExplorerAddress(os.Stdout, uh, resp, cmd.cli.CreateCurrencyConvertor())
+	}
+}
+
+// getHash looks up an item on the blockchain by its hash, using the explorer module,
+// exiting with an error if the item is not found, or is not of the expected hash type.
+func (cmd *exploreCmd) getHash(hash, hashType string, minHeight uint64) api.ExplorerHashGET {
+	var resp api.ExplorerHashGET
+	url := "/explorer/hashes/" + hash
+	if minHeight > 0 {
+		url += fmt.Sprintf("?minheight=%d", minHeight)
+	}
+	err := cmd.getAPI(url, &resp)
+	if err != nil {
+		cli.Die(fmt.Sprintf("Could not get an item using the hash %q: %v", hash, err))
+	}
+	if resp.HashType != hashType {
+		cli.Die(fmt.Sprintf("Hash %q is a %s, while a %s was expected", hash, resp.HashType, hashType))
+	}
+	return resp
+}
+
+// getAPI makes a GET API call to the remote explorer if defined,
+// or the daemon of the client otherwise.
+func (cmd *exploreCmd) getAPI(call string, obj interface{}) error {
+	if cmd.rootCfg.ExplorerAddress == "" {
+		return cmd.cli.GetAPI(call, obj)
+	}
+	address, err := sanitizeURL(cmd.rootCfg.ExplorerAddress)
+	if err != nil {
+		return fmt.Errorf("invalid explorer address %q: %v", cmd.rootCfg.ExplorerAddress, err)
+	}
+	client := &api.HTTPClient{
+		RootURL:   address,
+		UserAgent: cmd.cli.HTTPClient.UserAgent,
+		Client:    &http.Client{Timeout: cmd.cli.Timeout},
+		Retries:   cmd.cli.Retries,
+	}
+	return client.GetAPI(call, obj)
+}
+
 // explorehashcmd is the handler for the command `rivinec explore hash`,
 // explores an item on the blockchain, by looking it up by its hash,
 // and printing all info it receives back for that hash
@@ -110,13 +238,13 @@ func (cmd *exploreCmd) hashCmd(hash string) {
 	if cmd.hashCfg.MinHeight > 0 {
 		url += fmt.Sprintf("?minheight=%d", cmd.hashCfg.MinHeight)
 	}
-	err := cmd.cli.GetAPI(url, &resp)
+	err := cmd.getAPI(url, &resp)
 	if err != nil {
 		cli.Die(fmt.Sprintf("Could not get an item using the hash %q: %v", hash, err))
 	}
 
 	// print depending on the encoding type
-	switch cmd.hashCfg.EncodingType {
+	switch outputEncodingType(cmd.cli, cmd.hashCfg.EncodingType) {
 	case cli.EncodingTypeJSON:
 		json.NewEncoder(os.Stdout).Encode(resp)
 	default:
@@ -125,3 +253,175 @@ func (cmd *exploreCmd) hashCmd(hash string) {
 		e.Encode(resp)
 	}
 }
+
+// printExplorerBlock prints a decoded explorer block in a human-friendly format.
+func printExplorerBlock(w io.Writer, block api.ExplorerBlock, cc CurrencyConvertor) {
+	fmt.Fprintf(w, "Block %v\n", block.BlockID)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Height:\t%d\n", block.Height)
+	fmt.Fprintf(tw, "Parent:\t%v\n", block.RawBlock.ParentID)
+	fmt.Fprintf(tw, "Timestamp:\t%v\n", block.RawBlock.Timestamp)
+	fmt.Fprintf(tw, "Difficulty:\t%v\n", block.Difficulty)
+	fmt.Fprintf(tw, "Total coins:\t%s\n", cc.ToCoinStringWithUnit(block.TotalCoins))
+	tw.Flush()
+
+	fmt.Fprintf(w, "\n%d miner payout(s):\n", len(block.RawBlock.MinerPayouts))
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for i, mp := range block.RawBlock.MinerPayouts {
+		var id types.CoinOutputID
+		if i < len(block.MinerPayoutIDs) {
+			id = block.MinerPayoutIDs[i]
+		}
+		fmt.Fprintf(tw, "  %v\t%s\t%v\n", id, cc.ToCoinStringWithUnit(mp.Value), mp.UnlockHash)
+	}
+	tw.Flush()
+
+	fmt.Fprintf(w, "\n%d transaction(s):\n", len(block.Transactions))
+	for _, txn := range block.Transactions {
+		fmt.Fprintf(w, "  %v\n", txn.ID)
+	}
+}
+
+// printExplorerTransaction prints a decoded explorer transaction in a human-friendly format.
+func printExplorerTransaction(w io.Writer, txn api.ExplorerTransaction, cc CurrencyConvertor) {
+	fmt.Fprintf(w, "Transaction %v\n", txn.ID)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Version:\t%d\n", txn.RawTransaction.Version)
+	if txn.Unconfirmed {
+		fmt.Fprintf(tw, "Confirmed:\t%s\n", YesNo(false))
+	} else {
+		fmt.Fprintf(tw, "Height:\t%d\n", txn.Height)
+		fmt.Fprintf(tw, "Block:\t%v\n", txn.Parent)
+	}
+	tw.Flush()
+
+	printSection := func(title string, rows [][]string) {
+		if len(rows) == 0 {
+			return
+		}
+		fmt.Fprintf(w, "\n%s:\n", title)
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, row := range rows {
+			fmt.Fprint(tw, " ")
+			for _, cell := range row {
+				fmt.Fprintf(tw, " %s\t", cell)
+			}
+			fmt.Fprintln(tw)
+		}
+		tw.Flush()
+	}
+
+	var rows [][]string
+	for i, ci := range txn.RawTransaction.CoinInputs {
+		row := []string{ci.ParentID.String()}
+		if i < len(txn.CoinInputOutputs) {
+			row = append(row, cc.ToCoinStringWithUnit(txn.CoinInputOutputs[i].Value), txn.CoinInputOutputs[i].UnlockHash.String())
+		}
+		rows = append(rows, row)
+	}
+	printSection("Coin inputs", rows)
+
+	rows = nil
+	for i, co := range txn.RawTransaction.CoinOutputs {
+		var id string
+		if i < len(txn.CoinOutputIDs) {
+			id = txn.CoinOutputIDs[i].String()
+		}
+		rows = append(rows, []string{id, cc.ToCoinStringWithUnit(co.Value), co.Condition.UnlockHash().String()})
+	}
+	printSection("Coin outputs", rows)
+
+	rows = nil
+	for i, bsi := range txn.RawTransaction.BlockStakeInputs {
+		row := []string{bsi.ParentID.String()}
+		if i < len(txn.BlockStakeInputOutputs) {
+			row = append(row, txn.BlockStakeInputOutputs[i].Value.String()+" BS", txn.BlockStakeInputOutputs[i].UnlockHash.String())
+		}
+		rows = append(rows, row)
+	}
+	printSection("Blockstake inputs", rows)
+
+	rows = nil
+	for i, bso := range txn.RawTransaction.BlockStakeOutputs {
+		var id string
+		if i < len(txn.BlockStakeOutputIDs) {
+			id = txn.BlockStakeOutputIDs[i].String()
+		}
+		rows = append(rows, []string{id, bso.Value.String() + " BS", bso.Condition.UnlockHash().String()})
+	}
+	printSection("Blockstake outputs", rows)
+
+	rows = nil
+	for _, fee := range txn.RawTransaction.MinerFees {
+		rows = append(rows, []string{cc.ToCoinStringWithUnit(fee)})
+	}
+	printSection("Miner fees", rows)
+
+	if len(txn.RawTransaction.ArbitraryData) > 0 {
+		fmt.Fprintf(w, "\nArbitrary data: %q\n", txn.RawTransaction.ArbitraryData)
+	}
+}
+
+// printExplorerAddress prints the decoded transactions and blocks linked to an address,
+// in a human-friendly format, with the coins received and sent by that address.
+func printExplorerAddress(w io.Writer, uh types.UnlockHash, resp api.ExplorerHashGET, cc CurrencyConvertor) {
+	fmt.Fprintf(w, "Address %v\n", uh)
+	if len(resp.MultiSigAddresses) > 0 {
+		fmt.Fprintf(w, "\nLinked to %d multisig address(es):\n", len(resp.MultiSigAddresses))
+		for _, addr := range resp.MultiSigAddresses {
+			fmt.Fprintf(w, "  %v\n", addr)
+		}
+	}
+
+	if len(resp.Blocks) > 0 {
+		fmt.Fprintf(w, "\n%d block(s) with miner payouts:\n", len(resp.Blocks))
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "  Height\tBlock\tPayout")
+		for _, block := range resp.Blocks {
+			var payout types.Currency
+			for _, mp := range block.RawBlock.MinerPayouts {
+				if mp.UnlockHash.Cmp(uh) == 0 {
+					payout = payout.Add(mp.Value)
+				}
+			}
+			fmt.Fprintf(tw, "  %d\t%v\t%s\n", block.Height, block.BlockID, cc.ToCoinStringWithUnit(payout))
+		}
+		tw.Flush()
+	}
+
+	if len(resp.Transactions) > 0 {
+		fmt.Fprintf(w, "\n%d transaction(s):\n", len(resp.Transactions))
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "  Height\tTransaction\tReceived\tSent")
+		for _, txn := range resp.Transactions {
+			received, sent := explorerTransactionCoinFlow(txn, uh)
+			height := strconv.FormatUint(uint64(txn.Height), 10)
+			if txn.Unconfirmed {
+				height = "unconfirmed"
+			}
+			fmt.Fprintf(tw, "  %s\t%v\t%s\t%s\n", height, txn.ID,
+				cc.ToCoinStringWithUnit(received), cc.ToCoinStringWithUnit(sent))
+		}
+		tw.Flush()
+	}
+
+	if len(resp.Blocks) == 0 && len(resp.Transactions) == 0 {
+		fmt.Fprintln(w, "\nNo transactions or blocks found for this address.")
+	}
+}
+
+// explorerTransactionCoinFlow returns the coins received and sent by the given address
+// within the given explorer transaction.
+func explorerTransactionCoinFlow(txn api.ExplorerTransaction, uh types.UnlockHash) (received, sent types.Currency) {
+	for i, co := range txn.RawTransaction.CoinOutputs {
+		if i < len(txn.CoinOutputUnlockHashes) && txn.CoinOutputUnlockHashes[i].Cmp(uh) == 0 {
+			received = received.Add(co.Value)
+		}
+	}
+	for _, co := range txn.CoinInputOutputs {
+		if co.UnlockHash.Cmp(uh) == 0 {
+			sent = sent.Add(co.Value)
+		}
+	}
+	return
+}
@@ -0,0 +1,64 @@
+package client
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/pkg/api"
+	"github.com/threefoldtech/rivine/types"
+)
+
+func TestExplorerTransactionCoinFlow(t *testing.T) {
+	uhA := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: crypto.Hash{1}}
+	uhB := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: crypto.Hash{2}}
+	txn := api.ExplorerTransaction{
+		ID: types.TransactionID{3},
+		RawTransaction: types.Transaction{
+			Version: types.TransactionVersionOne,
+			CoinInputs: []types.CoinInput{
+				{ParentID: types.CoinOutputID{4}},
+			},
+			CoinOutputs: []types.CoinOutput{
+				{Value: types.NewCurrency64(30), Condition: types.NewCondition(types.NewUnlockHashCondition(uhB))},
+				{Value: types.NewCurrency64(60), Condition: types.NewCondition(types.NewUnlockHashCondition(uhA))},
+			},
+			MinerFees: []types.Currency{types.NewCurrency64(10)},
+		},
+		CoinInputOutputs: []api.ExplorerCoinOutput{
+			{
+				CoinOutput: types.CoinOutput{Value: types.NewCurrency64(100), Condition: types.NewCondition(types.NewUnlockHashCondition(uhA))},
+				UnlockHash: uhA,
+			},
+		},
+		CoinOutputIDs:          []types.CoinOutputID{{5}, {6}},
+		CoinOutputUnlockHashes: []types.UnlockHash{uhB, uhA},
+	}
+
+	received, sent := explorerTransactionCoinFlow(txn, uhA)
+	if !received.Equals64(60) || !sent.Equals64(100) {
+		t.Errorf("unexpected coin flow for sender: received %v, sent %v", received, sent)
+	}
+	received, sent = explorerTransactionCoinFlow(txn, uhB)
+	if !received.Equals64(30) || !sent.Equals64(0) {
+		t.Errorf("unexpected coin flow for receiver: received %v, sent %v", received, sent)
+	}
+
+	// the decoded transaction contains all inputs and outputs
+	var buf bytes.Buffer
+	printExplorerTransaction(&buf, txn, createDefaultCurrencyConvertor())
+	output := buf.String()
+	for _, expected := range []string{
+		txn.ID.String(),
+		types.CoinOutputID{4}.String(),
+		types.CoinOutputID{6}.String(),
+		uhA.String(),
+		uhB.String(),
+		"Miner fees",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected %q in decoded transaction:\n%s", expected, output)
+		}
+	}
+}
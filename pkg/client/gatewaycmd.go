@@ -68,7 +68,9 @@ func (gatewayCmd *gatewayCmd) connectCmd(addr string) {
 	if err != nil {
 		cli.Die("Could not add peer:", err)
 	}
-	fmt.Println("Added", addr, "to peer list.")
+	gatewayCmd.cli.PrintOutput(EmptyOutput{}, func() {
+		fmt.Println("Added", addr, "to peer list.")
+	})
 }
 
 // disconnectCmd is the handler for the command `gateway remove [address]`.
@@ -78,7 +80,9 @@ func (gatewayCmd *gatewayCmd) disconnectCmd(addr string) {
 	if err != nil {
 		cli.Die("Could not remove peer:", err)
 	}
-	fmt.Println("Removed", addr, "from peer list.")
+	gatewayCmd.cli.PrintOutput(EmptyOutput{}, func() {
+		fmt.Println("Removed", addr, "from peer list.")
+	})
 }
 
 // addressCmd is the handler for the command `gateway address`.
@@ -89,7 +93,9 @@ func (gatewayCmd *gatewayCmd) addressCmd() {
 	if err != nil {
 		cli.Die("Could not get gateway address:", err)
 	}
-	fmt.Println("Address:", info.NetAddress)
+	gatewayCmd.cli.PrintOutput(info, func() {
+		fmt.Println("Address:", info.NetAddress)
+	})
 }
 
 // rootCmd is the handler for the command `gateway`.
@@ -100,8 +106,10 @@ func (gatewayCmd *gatewayCmd) rootCmd() {
 	if err != nil {
 		cli.Die("Could not get gateway address:", err)
 	}
-	fmt.Println("Address:", info.NetAddress)
-	fmt.Println("Active peers:", len(info.Peers))
+	gatewayCmd.cli.PrintOutput(info, func() {
+		fmt.Println("Address:", info.NetAddress)
+		fmt.Println("Active peers:", len(info.Peers))
+	})
 }
 
 // listPeersCmd is the handler for the command `gateway list`.
@@ -112,6 +120,10 @@ func (gatewayCmd *gatewayCmd) listPeersCmd() {
 	if err != nil {
 		cli.Die("Could not get peer list:", err)
 	}
+	if gatewayCmd.cli.JSONOutput {
+		printJSON(info)
+		return
+	}
 	if len(info.Peers) == 0 {
 		fmt.Println("No peers to show.")
 		return
@@ -6,6 +6,10 @@ import (
 	"strings"
 )
 
+// unixSocketURLPrefix is the prefix of an address referring to the unix socket of a daemon,
+// e.g. unix:///home/user/.rivine/standard/api.sock
+const unixSocketURLPrefix = "unix://"
+
 var (
 	urlSchemeSplitter   = regexp.MustCompile(`^(https?://)?(.+)$`)
 	urlLocalHostMatcher = regexp.MustCompile(`^(localhost|127\.0\.0\.1)?(\:[0-9]{1,5})?$`)
@@ -13,6 +17,12 @@ var (
 
 // look, a really bad validator! Hide it please :(
 func sanitizeURL(url string) (string, error) {
+	if strings.HasPrefix(url, unixSocketURLPrefix) {
+		if len(url) == len(unixSocketURLPrefix) {
+			return "", errors.New("no unix socket path defined")
+		}
+		return url, nil // the path of a unix socket of the daemon
+	}
 	parts := urlSchemeSplitter.FindStringSubmatch(url)
 	if len(parts) == 0 {
 		return "", errors.New("invalid url format") // or perhaps our regexp just sucks >.<
@@ -17,6 +17,7 @@ func TestSanitizeValidURL(t *testing.T) {
 		{"localhost", "http://localhost:23110"},
 		{":23110", "http://:23110"},
 		{"http://:23110", ""},
+		{"unix:///var/lib/rivine/api.sock", ""},
 	}
 	for idx, url := range urls {
 		out, err := sanitizeURL(url.Input)
@@ -38,6 +39,7 @@ func TestSanitizeInvalidURL(t *testing.T) {
 		Input string
 	}{
 		{""},
+		{"unix://"},
 	}
 	for idx, url := range urls {
 		_, err := sanitizeURL(url.Input)
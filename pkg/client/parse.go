@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"math/big"
 	"strings"
+	"unicode"
 
 	"github.com/threefoldtech/rivine/types"
 )
@@ -42,6 +43,11 @@ type CurrencyConvertor struct {
 	scalar    *big.Int
 	precision uint // amount of zeros after the comma
 	coinUnit  string
+
+	// precision and unit of the one coin unit of the chain,
+	// which can differ from the default unit, used to parse amounts with an explicit unit
+	oneCoinPrecision uint
+	oneCoinUnit      string
 }
 
 // NewCurrencyConvertor creates a new currency convertor
@@ -52,32 +58,113 @@ func NewCurrencyConvertor(units types.CurrencyUnits, coinUnit string) CurrencyCo
 	oneCoinStr := units.OneCoin.String()
 	precision := uint(len(oneCoinStr) - 1)
 	return CurrencyConvertor{
-		scalar:    units.OneCoin.Big(),
-		precision: precision,
-		coinUnit:  coinUnit,
+		scalar:           units.OneCoin.Big(),
+		precision:        precision,
+		coinUnit:         coinUnit,
+		oneCoinPrecision: precision,
+		oneCoinUnit:      coinUnit,
 	}
 }
 
-// ParseCoinString parses the given string assumed to be in the default unit,
+// metricPrefixes which can prefix the coin unit of an amount,
+// each dividing the coin unit by 10 to the power of its exponent.
+var metricPrefixes = []struct {
+	Prefix   string
+	Exponent uint
+}{
+	{"", 0},
+	{"m", 3},
+	{"u", 6},
+	{"µ", 6},
+	{"n", 9},
+}
+
+// genericCoinUnit can be used as the unit of an amount in place of the coin unit of the chain.
+const genericCoinUnit = "coin"
+
+// ParseCoinString parses the given string, an amount optionally followed by a unit,
 // and parses it into an in-memory currency unit of the smallest unit.
+// An amount without unit is assumed to be in the default unit.
+// The unit is the coin unit of the chain (or simply "coin"), case insensitive,
+// optionally prefixed by a metric prefix: m (milli), u or µ (micro) or n (nano),
+// e.g. "1.5", "1500 mCOIN" or "0.000001 COIN".
 // It will fail if the given string is invalid or too precise.
 func (cc CurrencyConvertor) ParseCoinString(str string) (types.Currency, error) {
+	amount, unit := splitCoinString(str)
+	if unit == "" {
+		return parseCoinAmount(amount, cc.precision, cc.coinUnit)
+	}
+	precision, err := cc.unitPrecision(unit)
+	if err != nil {
+		return types.Currency{}, err
+	}
+	return parseCoinAmount(amount, precision, unit)
+}
+
+// splitCoinString splits a coin string into its amount and optional unit,
+// the unit starting at the first letter of the string.
+func splitCoinString(str string) (amount, unit string) {
+	str = strings.TrimSpace(str)
+	idx := strings.IndexFunc(str, unicode.IsLetter)
+	if idx == -1 {
+		return str, ""
+	}
+	return strings.TrimSpace(str[:idx]), str[idx:]
+}
+
+// unitPrecision returns the amount of decimals an amount expressed in the given unit can have.
+func (cc CurrencyConvertor) unitPrecision(unit string) (uint, error) {
+	for _, mp := range metricPrefixes {
+		if !strings.HasPrefix(unit, mp.Prefix) {
+			continue
+		}
+		name := unit[len(mp.Prefix):]
+		if !strings.EqualFold(name, genericCoinUnit) && (cc.oneCoinUnit == "" || !strings.EqualFold(name, cc.oneCoinUnit)) {
+			continue
+		}
+		if mp.Exponent > cc.oneCoinPrecision {
+			return 0, fmt.Errorf(
+				"unit %s is smaller than the smallest unit: %s has a precision of %d decimals",
+				unit, cc.oneCoinDisplayUnit(), cc.oneCoinPrecision)
+		}
+		return cc.oneCoinPrecision - mp.Exponent, nil
+	}
+	return 0, fmt.Errorf(
+		"unknown unit %q: expected %[2]s, m%[2]s, u%[2]s or n%[2]s (case insensitive, or %[3]q in place of %[2]s)",
+		unit, cc.oneCoinDisplayUnit(), genericCoinUnit)
+}
+
+// oneCoinDisplayUnit returns the unit of the chain as displayed in error messages.
+func (cc CurrencyConvertor) oneCoinDisplayUnit() string {
+	if cc.oneCoinUnit == "" {
+		return genericCoinUnit
+	}
+	return cc.oneCoinUnit
+}
+
+// parseCoinAmount parses a decimal amount, which can have up to the given precision of digits after the comma,
+// into an in-memory currency unit of the smallest unit. The unit is only used for error messages.
+func parseCoinAmount(str string, precision uint, unit string) (types.Currency, error) {
+	if str == "" {
+		return types.Currency{}, errors.New("no currency coin amount given")
+	}
+	scalar := big.NewInt(0).Exp(big.NewInt(10), big.NewInt(int64(precision)), nil)
 	initialParts := strings.SplitN(str, ".", 2)
 	if len(initialParts) == 1 {
 		// a round number, simply multiply and go
 		i, ok := big.NewInt(0).SetString(initialParts[0], 10)
 		if !ok {
-			return types.Currency{}, errors.New("invalid round currency coin amount")
+			return types.Currency{}, fmt.Errorf("invalid round currency coin amount %q", str)
 		}
 		if i.Cmp(big.NewInt(0)) == -1 {
 			return types.Currency{}, errors.New("invalid round currency coin amount: cannot be negative")
 		}
-		return types.NewCurrency(i.Mul(i, cc.scalar)), nil
+		return types.NewCurrency(i.Mul(i, scalar)), nil
 	}
 
 	whole := initialParts[0]
 	dac := initialParts[1]
-	sn := uint(cc.precision)
+	sn := precision
 	if l := uint(len(dac)); l < sn {
 		sn = l
 	}
@@ -85,18 +172,19 @@ func (cc CurrencyConvertor) ParseCoinString(str string) (types.Currency, error)
 	dac = dac[sn:]
 	for i := range dac {
 		if dac[i] != '0' {
-			return types.Currency{}, errors.New("invalid or too precise currency coin amount")
+			return types.Currency{}, fmt.Errorf(
+				"currency coin amount %q is too precise: %s has a precision of %d decimals", str, unit, precision)
 		}
 	}
 	i, ok := big.NewInt(0).SetString(whole, 10)
 	if !ok {
-		return types.Currency{}, errors.New("invalid currency coin amount")
+		return types.Currency{}, fmt.Errorf("invalid currency coin amount %q", str)
 	}
 	if i.Cmp(big.NewInt(0)) == -1 {
 		return types.Currency{}, errors.New("invalid round currency coin amount: cannot be negative")
 	}
 	i.Mul(i, big.NewInt(0).Exp(
-		big.NewInt(10), big.NewInt(int64(cc.precision-sn)), nil))
+		big.NewInt(10), big.NewInt(int64(precision-sn)), nil))
 	c := types.NewCurrency(i)
 	if c.Cmp64(0) == -1 {
 		return types.Currency{}, errors.New("invalid round currency coin amount: cannot be negative")
@@ -143,12 +231,13 @@ func (cc CurrencyConvertor) ToCoinStringWithUnit(c types.Currency) string {
 // CoinArgDescription is used to print a helpful arg description message,
 // for this convertor.
 func (cc CurrencyConvertor) CoinArgDescription(argName string) string {
+	explicitUnit := fmt.Sprintf(", unless a unit is given explicitly (e.g. 1.5 %[1]s or 1500 m%[1]s)", cc.oneCoinDisplayUnit())
 	if cc.precision < 1 {
 		return fmt.Sprintf(
 			"argument %s (expressed in default unit %s) has to be a positive natural number (no digits after comma are allowed)",
-			argName, cc.coinUnit)
+			argName, cc.coinUnit) + explicitUnit
 	}
 	return fmt.Sprintf(
 		"argument %s (expressed in default unit %s) can (only) have up to %d digits after comma and has to be positive",
-		argName, cc.coinUnit, cc.precision)
+		argName, cc.coinUnit, cc.precision) + explicitUnit
 }
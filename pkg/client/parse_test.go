@@ -176,3 +176,84 @@ func (cc CurrencyConvertor) Cmp(other CurrencyConvertor) int {
 	}
 	return strings.Compare(cc.scalar.String(), other.scalar.String())
 }
+
+func TestParseCoinStringWithUnit(t *testing.T) {
+	cc := NewCurrencyConvertor(types.CurrencyUnits{
+		OneCoin: types.NewCurrency(new(big.Int).Exp(big.NewInt(10), big.NewInt(9), nil)),
+	}, "TFT")
+
+	validTestCases := map[string]uint64{
+		"1.5":            1500000000,
+		"1.5 TFT":        1500000000,
+		"1.5tft":         1500000000,
+		"1500 mTFT":      1500000000,
+		"1500mcoin":      1500000000,
+		"0.000001 COIN":  1000,
+		"2 uTFT":         2000,
+		"2 µTFT":         2000,
+		"3 nTFT":         3,
+		" 0.5 mTFT ":     500000,
+		"1.000000000TFT": 1000000000,
+	}
+	for str, expected := range validTestCases {
+		c, err := cc.ParseCoinString(str)
+		if err != nil {
+			t.Errorf("failed to parse %q: %v", str, err)
+			continue
+		}
+		if !c.Equals64(expected) {
+			t.Errorf("unexpected value for %q: %v != %v", str, c, expected)
+		}
+	}
+
+	invalidTestCases := map[string]string{
+		"1 BTC":           `unknown unit "BTC"`,
+		"1 MTFT":          `unknown unit "MTFT"`,
+		"TFT":             "no currency coin amount given",
+		"0.5 nTFT":        `currency coin amount "0.5" is too precise: nTFT has a precision of 0 decimals`,
+		"0.0000000001TFT": "too precise: TFT has a precision of 9 decimals",
+		"1e9":             `unknown unit "e9"`,
+		"-1 TFT":          "cannot be negative",
+	}
+	for str, expected := range invalidTestCases {
+		_, err := cc.ParseCoinString(str)
+		if err == nil {
+			t.Errorf("expected %q to not parse, but it did", str)
+		} else if !strings.Contains(err.Error(), expected) {
+			t.Errorf("unexpected error for %q: %q does not contain %q", str, err.Error(), expected)
+		}
+	}
+
+	// a coin unit without decimals cannot be divided using a metric prefix
+	cc = NewCurrencyConvertor(types.CurrencyUnits{OneCoin: types.NewCurrency64(1)}, "TFT")
+	_, err := cc.ParseCoinString("1 mTFT")
+	if err == nil || !strings.Contains(err.Error(), "smaller than the smallest unit") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestParseCoinStringBaseUnits(t *testing.T) {
+	client := &CommandLineClient{
+		Config: &Config{
+			CurrencyUnits: types.CurrencyUnits{
+				OneCoin: types.NewCurrency(new(big.Int).Exp(big.NewInt(10), big.NewInt(9), nil)),
+			},
+			CurrencyCoinUnit: "TFT",
+		},
+		Units: UnitsBase,
+	}
+	cc := client.CreateCurrencyConvertor()
+	// amounts without unit are expressed in the base unit,
+	// while an explicit unit still refers to the coin unit of the chain
+	for str, expected := range map[string]uint64{"1500": 1500, "1.5 TFT": 1500000000, "2 mTFT": 2000000} {
+		c, err := cc.ParseCoinString(str)
+		if err != nil {
+			t.Errorf("failed to parse %q: %v", str, err)
+		} else if !c.Equals64(expected) {
+			t.Errorf("unexpected value for %q: %v != %v", str, c, expected)
+		}
+	}
+	if _, err := cc.ParseCoinString("1.5"); err == nil {
+		t.Error("expected a decimal amount of base units to not parse")
+	}
+}
@@ -0,0 +1,101 @@
+package client
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/threefoldtech/rivine/pkg/cli"
+	"github.com/threefoldtech/rivine/pkg/qrcode"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// qrStyle defines whether and how a QR code is printed by a command,
+// implementing pflag.Value such that it can be used as a flag directly.
+type qrStyle string
+
+// all QR code styles
+const (
+	qrStyleNone  qrStyle = ""
+	qrStyleASCII qrStyle = "ascii"
+	qrStyleANSI  qrStyle = "ansi"
+)
+
+// String implements pflag.Value.String
+func (s *qrStyle) String() string {
+	return string(*s)
+}
+
+// Set implements pflag.Value.Set,
+// interpreting the given string in a case insensitive manner.
+func (s *qrStyle) Set(str string) error {
+	switch style := qrStyle(strings.ToLower(str)); style {
+	case qrStyleASCII, qrStyleANSI:
+		*s = style
+		return nil
+	default:
+		return fmt.Errorf("%q is not a valid QR code style, options: %s|%s", str, qrStyleANSI, qrStyleASCII)
+	}
+}
+
+// Type implements pflag.Value.Type
+func (s *qrStyle) Type() string {
+	return "QRStyle"
+}
+
+// addQRStyleFlag adds the --qr flag to the given command,
+// which prints an ANSI QR code if no style is given explicitly.
+func addQRStyleFlag(cmd *cobra.Command, style *qrStyle) {
+	cmd.Flags().Var(style, "qr", fmt.Sprintf(
+		"print a QR code as well, options: %[1]s|%[2]s, using %[1]s if no option is given (--qr=%[2]s requires a light terminal background)",
+		qrStyleANSI, qrStyleASCII))
+	cmd.Flags().Lookup("qr").NoOptDefVal = string(qrStyleANSI)
+}
+
+// printQRCode prints the given content as a QR code in the given style,
+// printing nothing if no style is given.
+func printQRCode(style qrStyle, content string) {
+	if style == qrStyleNone {
+		return
+	}
+	// no error correction is required to read a code from a screen,
+	// and the lowest level keeps a code with an address within 82 terminal columns
+	code, err := qrcode.Encode([]byte(content), qrcode.LevelL)
+	if err != nil {
+		cli.Die("failed to create QR code:", err)
+	}
+	if style == qrStyleASCII {
+		fmt.Print(code.ASCII())
+	} else {
+		fmt.Print(code.ANSI())
+	}
+}
+
+// paymentRequestURI returns a URI requesting a payment to the given address,
+// in the style of BIP-21: <chainname>:<address>?amount=<amount>&message=<message>.
+// The amount, expressed in the one coin unit, and the message are optional.
+func paymentRequestURI(chainName string, address types.UnlockHash, amount, message string) string {
+	// a URI scheme only allows letters, digits, '+', '-' and '.'
+	scheme := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '+' || r == '-' || r == '.' {
+			return r
+		}
+		return -1
+	}, strings.ToLower(chainName))
+
+	var params []string
+	if amount != "" {
+		params = append(params, "amount="+amount)
+	}
+	if message != "" {
+		// spaces are escaped as %20 rather than '+', as not all wallets decode the latter
+		params = append(params, "message="+strings.Replace(url.QueryEscape(message), "+", "%20", -1))
+	}
+	uri := scheme + ":" + address.String()
+	if len(params) > 0 {
+		uri += "?" + strings.Join(params, "&")
+	}
+	return uri
+}
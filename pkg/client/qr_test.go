@@ -0,0 +1,41 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/types"
+)
+
+func TestPaymentRequestURI(t *testing.T) {
+	address := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: crypto.Hash{1, 2, 3}}
+	testCases := []struct {
+		ChainName, Amount, Message string
+		Expected                   string
+	}{
+		{"rivine", "", "", "rivine:" + address.String()},
+		{"rivine", "12.5", "", "rivine:" + address.String() + "?amount=12.5"},
+		{"tfchain", "1", "lunch & drinks", "tfchain:" + address.String() + "?amount=1&message=lunch%20%26%20drinks"},
+		{"My Chain", "", "thanks!", "mychain:" + address.String() + "?message=thanks%21"},
+	}
+	for _, testCase := range testCases {
+		uri := paymentRequestURI(testCase.ChainName, address, testCase.Amount, testCase.Message)
+		if uri != testCase.Expected {
+			t.Errorf("unexpected URI: %q != %q", uri, testCase.Expected)
+		}
+	}
+}
+
+func TestQRStyleFlag(t *testing.T) {
+	var style qrStyle
+	for str, expected := range map[string]qrStyle{"ansi": qrStyleANSI, "ASCII": qrStyleASCII} {
+		if err := style.Set(str); err != nil {
+			t.Errorf("failed to set style %q: %v", str, err)
+		} else if style != expected {
+			t.Errorf("unexpected style for %q: %q != %q", str, style, expected)
+		}
+	}
+	if err := style.Set("png"); err == nil {
+		t.Error("expected an error for an invalid style")
+	}
+}
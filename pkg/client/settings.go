@@ -0,0 +1,198 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/spf13/cobra"
+)
+
+// units in which currency amounts are given and printed by the client
+const (
+	// UnitsCoin expresses amounts in the one coin unit, the default.
+	UnitsCoin = "coin"
+	// UnitsBase expresses amounts in the smallest (in-memory) unit.
+	UnitsBase = "base"
+)
+
+// Settings are the user settings of the client, loaded from a config file and
+// environment variables, such that they do not have to be given as flags for each command.
+// Command line flags always take precedence over the settings.
+type Settings struct {
+	// Address of the daemon's API, see the --addr flag
+	Address string `json:"address,omitempty"`
+	// APIPassword used to authenticate API calls, which is only supported as a setting,
+	// such that it does not end up in the shell history.
+	APIPassword string `json:"apipassword,omitempty"`
+	// Units in which currency amounts are given and printed, see the --units flag
+	Units string `json:"units,omitempty"`
+	// JSON output mode, see the --json flag
+	JSON *bool `json:"json,omitempty"`
+	// Timeout of each API call, e.g. "30s", see the --timeout flag
+	Timeout string `json:"timeout,omitempty"`
+	// Retries of each API call, see the --retries flag
+	Retries *int `json:"retries,omitempty"`
+	// TLSPins are the accepted public key pins of the daemon certificate, see the --tls-pin flag
+	TLSPins []string `json:"tlspins,omitempty"`
+	// TLSClientCert is the path of the TLS client certificate, see the --tls-client-cert flag
+	TLSClientCert string `json:"tlsclientcert,omitempty"`
+	// TLSClientKey is the path of the TLS client key, see the --tls-client-key flag
+	TLSClientKey string `json:"tlsclientkey,omitempty"`
+}
+
+// settingsEnvPrefix returns the prefix of the environment variables of the client
+// with the given name, e.g. RIVINEC_ for the rivine client.
+func settingsEnvPrefix(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return '_'
+		}
+		return unicode.ToUpper(r)
+	}, name) + "C_"
+}
+
+// defaultSettingsPath returns the default path of the config file of the client
+// with the given name, e.g. ~/.config/rivinec/config.json for the rivine client,
+// or an empty string if the user configuration directory is not known.
+func defaultSettingsPath(name string) string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, strings.ToLower(strings.TrimSuffix(settingsEnvPrefix(name), "_")), "config.json")
+}
+
+// loadSettings loads the settings from the config file at the given path if it exists,
+// overwritten by the environment variables with the given prefix, as looked up using the given function.
+// It is an error for the config file not to exist, if it is required.
+func loadSettings(path string, required bool, envPrefix string, lookupEnv func(string) (string, bool)) (Settings, error) {
+	var settings Settings
+	if path != "" {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			if required || !os.IsNotExist(err) {
+				return Settings{}, fmt.Errorf("failed to read config file: %v", err)
+			}
+		} else if err = json.Unmarshal(b, &settings); err != nil {
+			return Settings{}, fmt.Errorf("invalid config file %q: %v", path, err)
+		}
+	}
+
+	if str, ok := lookupEnv(envPrefix + "ADDR"); ok {
+		settings.Address = str
+	}
+	if str, ok := lookupEnv(envPrefix + "API_PASSWORD"); ok {
+		settings.APIPassword = str
+	}
+	if str, ok := lookupEnv(envPrefix + "UNITS"); ok {
+		settings.Units = str
+	}
+	if str, ok := lookupEnv(envPrefix + "JSON"); ok {
+		b, err := strconv.ParseBool(str)
+		if err != nil {
+			return Settings{}, fmt.Errorf("invalid %sJSON environment variable %q: %v", envPrefix, str, err)
+		}
+		settings.JSON = &b
+	}
+	if str, ok := lookupEnv(envPrefix + "TIMEOUT"); ok {
+		settings.Timeout = str
+	}
+	if str, ok := lookupEnv(envPrefix + "RETRIES"); ok {
+		n, err := strconv.Atoi(str)
+		if err != nil {
+			return Settings{}, fmt.Errorf("invalid %sRETRIES environment variable %q: %v", envPrefix, str, err)
+		}
+		settings.Retries = &n
+	}
+	if str, ok := lookupEnv(envPrefix + "TLS_PINS"); ok {
+		settings.TLSPins = nil
+		for _, pin := range strings.Split(str, ",") {
+			if pin = strings.TrimSpace(pin); pin != "" {
+				settings.TLSPins = append(settings.TLSPins, pin)
+			}
+		}
+	}
+
+	if str, ok := lookupEnv(envPrefix + "TLS_CLIENT_CERT"); ok {
+		settings.TLSClientCert = str
+	}
+	if str, ok := lookupEnv(envPrefix + "TLS_CLIENT_KEY"); ok {
+		settings.TLSClientKey = str
+	}
+
+	if settings.Timeout != "" {
+		if _, err := time.ParseDuration(settings.Timeout); err != nil {
+			return Settings{}, fmt.Errorf("invalid timeout %q: %v", settings.Timeout, err)
+		}
+	}
+
+	switch settings.Units {
+	case "", UnitsCoin, UnitsBase:
+	default:
+		return Settings{}, fmt.Errorf("invalid units %q: expected %q or %q", settings.Units, UnitsCoin, UnitsBase)
+	}
+	return settings, nil
+}
+
+// applySettings loads the user settings and applies them to the client,
+// for all settings that were not already defined by the flags of the given command.
+func (cli *CommandLineClient) applySettings(cmd *cobra.Command) error {
+	changed := func(name string) bool {
+		flag := cmd.Flag(name)
+		return flag != nil && flag.Changed
+	}
+
+	// an explicitly defined config file is required to exist
+	path, required := cli.settingsPath, changed("config")
+	if !required {
+		if str, ok := os.LookupEnv(cli.settingsEnvPrefix + "CONFIG"); ok {
+			path, required = str, true
+		}
+	}
+	settings, err := loadSettings(path, required, cli.settingsEnvPrefix, os.LookupEnv)
+	if err != nil {
+		return err
+	}
+
+	if settings.Address != "" && !changed("addr") {
+		cli.HTTPClient.RootURL = settings.Address
+	}
+	if settings.APIPassword != "" && cli.HTTPClient.Password == "" {
+		cli.HTTPClient.Password = settings.APIPassword
+	}
+	if settings.Units != "" && !changed("units") {
+		cli.Units = settings.Units
+	}
+	if settings.JSON != nil && !changed("json") {
+		cli.JSONOutput = *settings.JSON
+	}
+	if settings.Timeout != "" && !changed("timeout") {
+		// validated when loading the settings
+		cli.Timeout, _ = time.ParseDuration(settings.Timeout)
+	}
+	if settings.Retries != nil && !changed("retries") {
+		cli.Retries = *settings.Retries
+	}
+	if len(settings.TLSPins) > 0 && !changed("tls-pin") {
+		cli.TLSPins = settings.TLSPins
+	}
+	if settings.TLSClientCert != "" && !changed("tls-client-cert") {
+		cli.TLSClientCert = settings.TLSClientCert
+	}
+	if settings.TLSClientKey != "" && !changed("tls-client-key") {
+		cli.TLSClientKey = settings.TLSClientKey
+	}
+	switch cli.Units {
+	case UnitsCoin, UnitsBase:
+		return nil
+	default:
+		return fmt.Errorf("invalid units %q: expected %q or %q", cli.Units, UnitsCoin, UnitsBase)
+	}
+}
@@ -0,0 +1,119 @@
+package client
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSettingsEnvPrefix(t *testing.T) {
+	testCases := map[string]string{
+		"rivine":  "RIVINEC_",
+		"tfchain": "TFCHAINC_",
+		"R?v?ne":  "R_V_NEC_",
+	}
+	for name, expected := range testCases {
+		if prefix := settingsEnvPrefix(name); prefix != expected {
+			t.Errorf("unexpected prefix for %q: %q != %q", name, prefix, expected)
+		}
+	}
+}
+
+func TestLoadSettings(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rivinec-settings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	noEnv := func(string) (string, bool) { return "", false }
+
+	// a missing config file is only an error if it is required
+	path := filepath.Join(dir, "config.json")
+	settings, err := loadSettings(path, false, "RIVINEC_", noEnv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(settings, Settings{}) {
+		t.Error("expected empty settings:", settings)
+	}
+	if _, err = loadSettings(path, true, "RIVINEC_", noEnv); err == nil {
+		t.Error("expected an error for a missing required config file")
+	}
+
+	// settings are loaded from the config file
+	err = ioutil.WriteFile(path, []byte(`{"address":"localhost:9000","apipassword":"secret","units":"base","json":true}`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings, err = loadSettings(path, true, "RIVINEC_", noEnv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings.Address != "localhost:9000" || settings.APIPassword != "secret" ||
+		settings.Units != UnitsBase || settings.JSON == nil || !*settings.JSON {
+		t.Error("unexpected settings:", settings)
+	}
+
+	// environment variables take precedence over the config file
+	env := map[string]string{
+		"RIVINEC_ADDR":  "localhost:9001",
+		"RIVINEC_UNITS": "coin",
+		"RIVINEC_JSON":  "false",
+	}
+	lookupEnv := func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+	settings, err = loadSettings(path, true, "RIVINEC_", lookupEnv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings.Address != "localhost:9001" || settings.APIPassword != "secret" ||
+		settings.Units != UnitsCoin || settings.JSON == nil || *settings.JSON {
+		t.Error("unexpected settings:", settings)
+	}
+
+	// network settings
+	env["RIVINEC_TIMEOUT"] = "30s"
+	env["RIVINEC_RETRIES"] = "3"
+	env["RIVINEC_TLS_PINS"] = "sha256//a, sha256//b"
+	env["RIVINEC_TLS_CLIENT_CERT"] = "client.crt"
+	env["RIVINEC_TLS_CLIENT_KEY"] = "client.key"
+	settings, err = loadSettings(path, true, "RIVINEC_", lookupEnv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings.Timeout != "30s" || settings.Retries == nil || *settings.Retries != 3 ||
+		!reflect.DeepEqual(settings.TLSPins, []string{"sha256//a", "sha256//b"}) ||
+		settings.TLSClientCert != "client.crt" || settings.TLSClientKey != "client.key" {
+		t.Error("unexpected network settings:", settings)
+	}
+	for key, value := range map[string]string{"RIVINEC_TIMEOUT": "30", "RIVINEC_RETRIES": "many"} {
+		env[key] = value
+		if _, err = loadSettings(path, true, "RIVINEC_", lookupEnv); err == nil {
+			t.Errorf("expected an error for an invalid %s environment variable", key)
+		}
+		delete(env, key)
+	}
+
+	// invalid settings are refused
+	env["RIVINEC_JSON"] = "maybe"
+	if _, err = loadSettings(path, true, "RIVINEC_", lookupEnv); err == nil {
+		t.Error("expected an error for an invalid JSON environment variable")
+	}
+	env["RIVINEC_JSON"] = "true"
+	env["RIVINEC_UNITS"] = "satoshi"
+	if _, err = loadSettings(path, true, "RIVINEC_", lookupEnv); err == nil {
+		t.Error("expected an error for invalid units")
+	}
+	err = ioutil.WriteFile(path, []byte(`{"address":`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = loadSettings(path, true, "RIVINEC_", noEnv); err == nil {
+		t.Error("expected an error for an invalid config file")
+	}
+}
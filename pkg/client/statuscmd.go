@@ -0,0 +1,203 @@
+package client
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/threefoldtech/rivine/build"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/pkg/api"
+	"github.com/threefoldtech/rivine/pkg/cli"
+)
+
+func createStatusCmd(client *CommandLineClient) *cobra.Command {
+	statusCmd := &statusCmd{cli: client}
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Print the overall status of the daemon",
+		Long: `Print the overall status of the daemon in a single call:
+the consensus height and sync progress, the connected peers,
+the transaction pool size and the wallet lock state and balances.
+
+Modules that are not available (e.g. a daemon running without a wallet)
+are reported as such, without failing the command. The command only fails
+if the daemon itself cannot be reached.`,
+		Run: Wrap(statusCmd.rootCmd),
+	}
+}
+
+type statusCmd struct {
+	cli *CommandLineClient
+}
+
+// StatusOutput is the output of the status command,
+// with a nil section for each module whose status could not be fetched,
+// in which case the reason is given in Errors, keyed by the section name.
+type StatusOutput struct {
+	Consensus       *api.ConsensusGET                  `json:"consensus,omitempty"`
+	Gateway         *StatusOutputGateway               `json:"gateway,omitempty"`
+	TransactionPool *modules.TransactionPoolStatistics `json:"transactionpool,omitempty"`
+	Wallet          *api.WalletGET                     `json:"wallet,omitempty"`
+	Errors          map[string]string                  `json:"errors,omitempty"`
+}
+
+// StatusOutputGateway summarizes the connected peers of the gateway.
+type StatusOutputGateway struct {
+	NetAddress modules.NetAddress `json:"netaddress"`
+	Peers      int                `json:"peers"`
+	Inbound    int                `json:"inbound"`
+	Outbound   int                `json:"outbound"`
+	// Local is the amount of peers connected over a local network.
+	Local int `json:"local"`
+	// Outdated is the amount of peers running an older protocol version than this client.
+	Outdated int `json:"outdated"`
+}
+
+// status sections, as used as keys of StatusOutput.Errors
+const (
+	statusSectionConsensus       = "consensus"
+	statusSectionGateway         = "gateway"
+	statusSectionTransactionPool = "transactionpool"
+	statusSectionWallet          = "wallet"
+)
+
+// rootCmd is the handler for the command `rivinec status`.
+// Prints the status of all modules of the daemon.
+func (statusCmd *statusCmd) rootCmd() {
+	output := StatusOutput{Errors: map[string]string{}}
+	get := func(section, call string, v interface{}) bool {
+		err := statusCmd.cli.GetAPI(call, v)
+		if err != nil {
+			if err == api.ErrDaemonUnreachable {
+				cli.Die("Could not get daemon status:", err)
+			}
+			output.Errors[section] = err.Error()
+			return false
+		}
+		return true
+	}
+
+	var cg api.ConsensusGET
+	if get(statusSectionConsensus, "/consensus", &cg) {
+		output.Consensus = &cg
+	}
+	var gg api.GatewayGET
+	if get(statusSectionGateway, "/gateway", &gg) {
+		gateway := summarizePeers(gg.NetAddress, gg.Peers, statusCmd.cli.Config.ChainVersion)
+		output.Gateway = &gateway
+	}
+	var tpg api.TransactionPoolGetStatistics
+	if get(statusSectionTransactionPool, "/transactionpool/statistics", &tpg) {
+		output.TransactionPool = &tpg.TransactionPoolStatistics
+	}
+	var wg api.WalletGET
+	if get(statusSectionWallet, "/wallet", &wg) {
+		output.Wallet = &wg
+	}
+	if len(output.Errors) == 0 {
+		output.Errors = nil
+	}
+
+	statusCmd.cli.PrintOutput(output, func() {
+		statusCmd.printStatus(output)
+	})
+}
+
+// printStatus prints the given status in a human-friendly format.
+func (statusCmd *statusCmd) printStatus(output StatusOutput) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintln(w, "Consensus:")
+	if cg := output.Consensus; cg != nil {
+		fmt.Fprintf(w, "  Synced:\t%v\n", YesNo(cg.Synced))
+		fmt.Fprintf(w, "  Height:\t%v\n", cg.Height)
+		if !cg.Synced {
+			if cg.EstimatedNetworkHeight > 0 {
+				fmt.Fprintf(w, "  Progress (estimated):\t%.2f%% of %v blocks\n", cg.SyncProgress*100, cg.EstimatedNetworkHeight)
+				if cg.SyncETA > 0 {
+					fmt.Fprintf(w, "  ETA:\t%v\n", time.Duration(cg.SyncETA)*time.Second)
+				}
+			} else if statusCmd.cli.Config.GenesisBlockTimestamp != 0 {
+				// older daemons do not report their sync progress, estimate it locally
+				estimatedHeight := estimatedHeightBetween(
+					int64(statusCmd.cli.Config.GenesisBlockTimestamp), time.Now().Unix(),
+					statusCmd.cli.Config.BlockFrequencyInSeconds)
+				estimatedProgress := float64(cg.Height) / float64(estimatedHeight) * 100
+				if estimatedProgress > 99 {
+					estimatedProgress = 99
+				}
+				fmt.Fprintf(w, "  Progress (estimated):\t%.2f%%\n", estimatedProgress)
+			}
+		}
+	} else {
+		fmt.Fprintf(w, "  Unavailable:\t%s\n", output.Errors[statusSectionConsensus])
+	}
+
+	fmt.Fprintln(w, "Gateway:")
+	if gateway := output.Gateway; gateway != nil {
+		fmt.Fprintf(w, "  Address:\t%v\n", gateway.NetAddress)
+		fmt.Fprintf(w, "  Peers:\t%d (%d outbound, %d inbound)\n", gateway.Peers, gateway.Outbound, gateway.Inbound)
+		if gateway.Outdated > 0 {
+			fmt.Fprintf(w, "  Outdated Peers:\t%d\n", gateway.Outdated)
+		}
+	} else {
+		fmt.Fprintf(w, "  Unavailable:\t%s\n", output.Errors[statusSectionGateway])
+	}
+
+	fmt.Fprintln(w, "Transaction Pool:")
+	if stats := output.TransactionPool; stats != nil {
+		fmt.Fprintf(w, "  Transactions:\t%d\n", stats.TransactionCount)
+		fmt.Fprintf(w, "  Size:\t%d bytes (%.2f%% of the limit)\n", stats.Size, stats.SizeUtilization*100)
+	} else {
+		fmt.Fprintf(w, "  Unavailable:\t%s\n", output.Errors[statusSectionTransactionPool])
+	}
+
+	fmt.Fprintln(w, "Wallet:")
+	if wallet := output.Wallet; wallet != nil {
+		switch {
+		case !wallet.Encrypted:
+			fmt.Fprintln(w, "  Status:\tNot initialized")
+		case !wallet.Unlocked:
+			fmt.Fprintln(w, "  Status:\tLocked")
+		default:
+			currencyConvertor := statusCmd.cli.CreateCurrencyConvertor()
+			fmt.Fprintln(w, "  Status:\tUnlocked")
+			fmt.Fprintf(w, "  Confirmed Balance:\t%s\n", currencyConvertor.ToCoinStringWithUnit(wallet.ConfirmedCoinBalance))
+			fmt.Fprintf(w, "  Locked Balance:\t%s\n", currencyConvertor.ToCoinStringWithUnit(wallet.ConfirmedLockedCoinBalance))
+			fmt.Fprintf(w, "  Unconfirmed:\t+ %s / - %s\n",
+				currencyConvertor.ToCoinStringWithUnit(wallet.UnconfirmedIncomingCoins),
+				currencyConvertor.ToCoinStringWithUnit(wallet.UnconfirmedOutgoingCoins))
+			fmt.Fprintf(w, "  BlockStakes:\t%v BS\n", wallet.BlockStakeBalance)
+		}
+	} else {
+		fmt.Fprintf(w, "  Unavailable:\t%s\n", output.Errors[statusSectionWallet])
+	}
+}
+
+// summarizePeers summarizes the given peers of a gateway,
+// counting the peers running an older protocol version than the given one as outdated.
+func summarizePeers(netAddress modules.NetAddress, peers []modules.Peer, version build.ProtocolVersion) StatusOutputGateway {
+	gateway := StatusOutputGateway{
+		NetAddress: netAddress,
+		Peers:      len(peers),
+	}
+	for _, peer := range peers {
+		if peer.Inbound {
+			gateway.Inbound++
+		} else {
+			gateway.Outbound++
+		}
+		if peer.Local {
+			gateway.Local++
+		}
+		if peer.Version.Compare(version) < 0 {
+			gateway.Outdated++
+		}
+	}
+	return gateway
+}
@@ -0,0 +1,33 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/threefoldtech/rivine/build"
+	"github.com/threefoldtech/rivine/modules"
+)
+
+func TestSummarizePeers(t *testing.T) {
+	version := build.NewVersion(1, 2, 0, 0)
+	peers := []modules.Peer{
+		{Inbound: false, Version: build.NewVersion(1, 2, 0, 0)},
+		{Inbound: false, Local: true, Version: build.NewVersion(1, 3, 0, 0)},
+		{Inbound: true, Version: build.NewVersion(1, 1, 9, 0)},
+	}
+	gateway := summarizePeers("127.0.0.1:23112", peers, version)
+	expected := StatusOutputGateway{
+		NetAddress: "127.0.0.1:23112",
+		Peers:      3,
+		Inbound:    1,
+		Outbound:   2,
+		Local:      1,
+		Outdated:   1,
+	}
+	if gateway != expected {
+		t.Errorf("unexpected gateway summary: %+v != %+v", gateway, expected)
+	}
+
+	if gateway := summarizePeers("", nil, version); gateway != (StatusOutputGateway{}) {
+		t.Errorf("unexpected gateway summary without peers: %+v", gateway)
+	}
+}
@@ -0,0 +1,711 @@
+package client
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/pkg/api"
+	"github.com/threefoldtech/rivine/pkg/cli"
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+	"github.com/threefoldtech/rivine/types"
+)
+
+func createTransactionCmd(client *CommandLineClient) *cobra.Command {
+	txCmd := &transactionCmd{cli: client}
+
+	// create root tx command and all subs
+	var (
+		rootCmd = &cobra.Command{
+			Use:   "tx",
+			Short: "Create, sign and send raw transactions",
+			Long: `Create, sign and send raw transactions, encoded as JSON or hex,
+for scripting and debugging flows not covered by the wallet commands.
+
+Transactions can be given as an argument, or as the path to a file containing the transaction.
+The hex encoding can only be used for transactions of which all inputs are signed.`,
+			// Run field is not set, as the tx command itself is not a valid command.
+			// A subcommand must be provided.
+		}
+		createCmd = &cobra.Command{
+			Use:   "create",
+			Short: "Create an unsigned transaction",
+			Long: `Create an unsigned transaction from the given inputs and outputs, printed as JSON.
+
+Outputs are given as <dest>|<rawCondition>=<amount>, where the destination is an address (unlock hash),
+or a JSON-encoded unlock condition, giving full control over the condition of the output
+(e.g. a multisig, atomic swap or timelock condition).
+Coin amounts are expressed in the OneCoin unit, unless a unit is given explicitly:
+the coin unit of the chain (or simply coin), optionally prefixed by m, u or n, e.g. 1500mcoin.
+
+No wallet is required, and the inputs and outputs are not validated, such that
+also transactions spending outputs not owned by the local wallet can be created.
+`,
+			Args: cobra.NoArgs,
+			Run:  Wrap(txCmd.createCmd),
+		}
+		signCmd = &cobra.Command{
+			Use:   "sign <txn>|<txnfile>",
+			Short: "Sign the inputs of a transaction using the wallet",
+			Long: `Sign all inputs of the transaction which can be signed by the keys of the wallet.
+If the transaction is given as a file, the signed transaction is written back to that file.`,
+			Run: Wrap(txCmd.signCmd),
+		}
+		sendCmd = &cobra.Command{
+			Use:   "send <txn>|<txnfile>",
+			Short: "Publish a transaction to the transaction pool",
+			Long:  "Publish a signed transaction to the transaction pool of the daemon.",
+			Run:   Wrap(txCmd.sendCmd),
+		}
+		decodeCmd = &cobra.Command{
+			Use:   "decode <txn>|<txnfile>",
+			Short: "Decode a transaction, without publishing it",
+			Long: `Decode a JSON or hex encoded transaction of any registered version, printing its ID,
+the IDs of the outputs it creates, the unlock conditions of those outputs,
+the fulfillments of its inputs and its fee math, without publishing it.
+
+As the value of the inputs is not part of the transaction,
+the fee math shows the value the coin inputs have to sum up to instead.`,
+			Run: Wrap(txCmd.decodeCmd),
+		}
+		waitCmd = &cobra.Command{
+			Use:   "wait <id>",
+			Short: "Wait until a transaction is confirmed",
+			Long: `Wait until the transaction with the given ID is confirmed by the given amount of blocks,
+printing the amount of confirmations and the height of the block it is part of.
+
+Instead of polling, the events of the transaction pool and the consensus set are streamed from the daemon.
+The command fails if the transaction is neither pooled nor confirmed (exit code 2),
+if it is dropped from the transaction pool, e.g. because it got evicted or conflicts with another transaction (exit code 1),
+or if it is not confirmed within the timeout (exit code 5).
+For this command the --timeout flag defines the time to wait, instead of the time limit of a single API call.`,
+			Run: Wrap(txCmd.waitCmd),
+		}
+	)
+	rootCmd.AddCommand(createCmd, signCmd, sendCmd, decodeCmd, waitCmd)
+
+	// create flags
+	createCmd.Flags().StringArrayVar(
+		&txCmd.createCfg.CoinInputs, "coininput", nil,
+		"ID of a coin output to spend (can be given multiple times)")
+	createCmd.Flags().StringArrayVar(
+		&txCmd.createCfg.BlockStakeInputs, "blockstakeinput", nil,
+		"ID of a blockstake output to spend (can be given multiple times)")
+	createCmd.Flags().StringArrayVar(
+		&txCmd.createCfg.CoinOutputs, "coinoutput", nil,
+		"coin output, as <dest>|<rawCondition>=<amount> (can be given multiple times)")
+	createCmd.Flags().StringArrayVar(
+		&txCmd.createCfg.BlockStakeOutputs, "blockstakeoutput", nil,
+		"blockstake output, as <dest>|<rawCondition>=<amount> (can be given multiple times)")
+	createCmd.Flags().StringVar(
+		&txCmd.createCfg.MinerFee, "minerfee", "",
+		"miner fee, expressed in the OneCoin unit unless a unit is given (e.g. 100mcoin), defaults to the minimum transaction fee")
+	createCmd.Flags().StringVar(
+		&txCmd.createCfg.Data, "data", "",
+		"optional arbitrary data to attach to the transaction")
+
+	waitCmd.Flags().Uint64Var(
+		&txCmd.waitCfg.Confirmations, "confirmations", 1,
+		"amount of blocks confirming the transaction to wait for, including the block it is part of")
+	waitCmd.Flags().DurationVar(
+		&txCmd.waitCfg.Timeout, "timeout", 0,
+		"maximum time to wait, e.g. 1h, no limit if 0")
+
+	// return root command
+	return rootCmd
+}
+
+type transactionCmd struct {
+	cli       *CommandLineClient
+	createCfg struct {
+		CoinInputs        []string
+		BlockStakeInputs  []string
+		CoinOutputs       []string
+		BlockStakeOutputs []string
+		MinerFee          string
+		Data              string
+	}
+	waitCfg struct {
+		Confirmations uint64
+		Timeout       time.Duration
+	}
+}
+
+// createCmd is the handler for the command `rivinec tx create`.
+// Prints an unsigned transaction, created from the given inputs and outputs.
+func (txCmd *transactionCmd) createCmd() {
+	currencyConvertor := txCmd.cli.CreateCurrencyConvertor()
+	cfg := txCmd.createCfg
+
+	txn := types.Transaction{
+		Version:       txCmd.cli.Config.DefaultTransactionVersion,
+		ArbitraryData: []byte(cfg.Data),
+		MinerFees:     []types.Currency{txCmd.cli.Config.MinimumTransactionFee},
+	}
+	if cfg.MinerFee != "" {
+		fee, err := currencyConvertor.ParseCoinString(cfg.MinerFee)
+		if err != nil {
+			cli.Die("invalid miner fee:", err)
+		}
+		txn.MinerFees[0] = fee
+	}
+	for _, str := range cfg.CoinInputs {
+		var id types.CoinOutputID
+		if err := id.LoadString(str); err != nil {
+			cli.Die("invalid coin input ID:", err)
+		}
+		txn.CoinInputs = append(txn.CoinInputs, types.CoinInput{ParentID: id})
+	}
+	for _, str := range cfg.BlockStakeInputs {
+		var id types.BlockStakeOutputID
+		if err := id.LoadString(str); err != nil {
+			cli.Die("invalid blockstake input ID:", err)
+		}
+		txn.BlockStakeInputs = append(txn.BlockStakeInputs, types.BlockStakeInput{ParentID: id})
+	}
+	for _, str := range cfg.CoinOutputs {
+		pair, err := parseOutputFlag(str, currencyConvertor.ParseCoinString)
+		if err != nil {
+			cli.Die("invalid coin output:", err)
+		}
+		txn.CoinOutputs = append(txn.CoinOutputs, types.CoinOutput{Value: pair.Value, Condition: pair.Condition})
+	}
+	for _, str := range cfg.BlockStakeOutputs {
+		pair, err := parseOutputFlag(str, stringToBlockStakes)
+		if err != nil {
+			cli.Die("invalid blockstake output:", err)
+		}
+		txn.BlockStakeOutputs = append(txn.BlockStakeOutputs, types.BlockStakeOutput{Value: pair.Value, Condition: pair.Condition})
+	}
+
+	err := json.NewEncoder(os.Stdout).Encode(txn)
+	if err != nil {
+		cli.Die("failed to encode transaction:", err)
+	}
+}
+
+// signCmd is the handler for the command `rivinec tx sign`.
+// Signs the given transaction using the wallet, and prints it (or writes it back to the given file)
+// using the same encoding as it was given in.
+func (txCmd *transactionCmd) signCmd(txnarg string) {
+	txn, encodingType, path, err := decodeTransactionArg(txnarg)
+	if err != nil {
+		cli.Die("failed to decode transaction:", err)
+	}
+	b, err := json.Marshal(txn)
+	if err != nil {
+		cli.Die("failed to encode transaction:", err)
+	}
+	err = txCmd.cli.PostResp("/wallet/sign", string(b), &txn)
+	if err != nil {
+		cli.DieWithError("failed to sign transaction:", err)
+	}
+
+	if path == "" && txCmd.cli.JSONOutput {
+		printJSON(txn)
+		return
+	}
+	str, err := encodeTransaction(txn, encodingType)
+	if err != nil {
+		cli.Die("failed to encode signed transaction:", err)
+	}
+	if path == "" {
+		fmt.Println(str)
+		return
+	}
+	err = ioutil.WriteFile(path, []byte(str+"\n"), 0600)
+	if err != nil {
+		cli.Die("failed to write signed transaction:", err)
+	}
+	txCmd.cli.PrintOutput(FileOutput{Path: path}, func() {
+		fmt.Println("Signed transaction written to", path)
+	})
+}
+
+// sendCmd is the handler for the command `rivinec tx send`.
+// Publishes the given transaction to the transaction pool.
+func (txCmd *transactionCmd) sendCmd(txnarg string) {
+	txn, _, _, err := decodeTransactionArg(txnarg)
+	if err != nil {
+		cli.Die("failed to decode transaction:", err)
+	}
+	b, err := json.Marshal(txn)
+	if err != nil {
+		cli.Die("failed to encode transaction:", err)
+	}
+	var resp api.TransactionPoolPOST
+	err = txCmd.cli.PostResp("/transactionpool/transactions", string(b), &resp)
+	if err != nil {
+		cli.DieWithError("could not publish transaction:", err)
+	}
+	txCmd.cli.PrintOutput(resp, func() {
+		fmt.Println("Transaction published, transaction id:", resp.TransactionID)
+	})
+}
+
+// decodeCmd is the handler for the command `rivinec tx decode`.
+// Prints the given transaction fully decoded.
+func (txCmd *transactionCmd) decodeCmd(txnarg string) {
+	txn, _, _, err := decodeTransactionArg(txnarg)
+	if err != nil {
+		cli.Die("failed to decode transaction:", err)
+	}
+	output, err := decodeTransaction(txn, txCmd.cli.Config.MinimumTransactionFee)
+	if err != nil {
+		cli.Die("failed to decode transaction:", err)
+	}
+	txCmd.cli.PrintOutput(output, func() {
+		txCmd.printDecodedTransaction(output)
+	})
+}
+
+type (
+	// TransactionOutputDecoded represents the formatted output of the tx decode command.
+	TransactionOutputDecoded struct {
+		ID                 types.TransactionID       `json:"id"`
+		Version            types.TransactionVersion  `json:"version"`
+		Size               int                       `json:"size"`
+		CoinInputs         []TransactionOutputInput  `json:"coininputs"`
+		CoinOutputs        []TransactionOutputOutput `json:"coinoutputs"`
+		BlockStakeInputs   []TransactionOutputInput  `json:"blockstakeinputs"`
+		BlockStakeOutputs  []TransactionOutputOutput `json:"blockstakeoutputs"`
+		MinerFees          []types.Currency          `json:"minerfees"`
+		CustomMinerPayouts []types.MinerPayout       `json:"customminerpayouts,omitempty"`
+		ArbitraryData      []byte                    `json:"arbitrarydata,omitempty"`
+		Extension          interface{}               `json:"extension,omitempty"`
+		Fees               TransactionOutputFees     `json:"fees"`
+	}
+
+	// TransactionOutputInput is a decoded (coin or blockstake) input.
+	TransactionOutputInput struct {
+		ParentID    types.OutputID               `json:"parentid"`
+		Fulfillment types.UnlockFulfillmentProxy `json:"fulfillment"`
+	}
+
+	// TransactionOutputOutput is a decoded (coin or blockstake) output,
+	// identified by the ID it has once the transaction is confirmed.
+	TransactionOutputOutput struct {
+		ID         types.OutputID             `json:"id"`
+		Value      types.Currency             `json:"value"`
+		UnlockHash types.UnlockHash           `json:"unlockhash"`
+		Condition  types.UnlockConditionProxy `json:"condition"`
+	}
+
+	// TransactionOutputFees contains the fee math of a decoded transaction.
+	TransactionOutputFees struct {
+		CoinOutputTotal        types.Currency `json:"coinoutputtotal"`
+		MinerFeeTotal          types.Currency `json:"minerfeetotal"`
+		CustomMinerPayoutTotal types.Currency `json:"customminerpayouttotal"`
+		// CoinInputTotal is the value the coin inputs have to sum up to,
+		// being the sum of the coin outputs, miner fees and custom miner payouts.
+		CoinInputTotal types.Currency `json:"coininputtotal"`
+		// BlockStakeInputTotal is the value the blockstake inputs have to sum up to.
+		BlockStakeInputTotal types.Currency `json:"blockstakeinputtotal"`
+		// FeePerByte is the total miner fee divided by the (binary encoded) size of the transaction.
+		FeePerByte      types.Currency `json:"feeperbyte"`
+		MinimumMinerFee types.Currency `json:"minimumminerfee"`
+		// BelowMinimum is true if any of the miner fees is below the minimum miner fee.
+		BelowMinimum bool `json:"belowminimum"`
+	}
+)
+
+// decodeTransaction decodes the given transaction, computing its IDs and fee math.
+func decodeTransaction(txn types.Transaction, minimumMinerFee types.Currency) (TransactionOutputDecoded, error) {
+	output := TransactionOutputDecoded{
+		ID:                txn.ID(),
+		Version:           txn.Version,
+		Size:              len(siabin.Marshal(txn)),
+		CoinInputs:        []TransactionOutputInput{},
+		CoinOutputs:       []TransactionOutputOutput{},
+		BlockStakeInputs:  []TransactionOutputInput{},
+		BlockStakeOutputs: []TransactionOutputOutput{},
+		MinerFees:         txn.MinerFees,
+		ArbitraryData:     txn.ArbitraryData,
+		Extension:         txn.Extension,
+		Fees: TransactionOutputFees{
+			MinimumMinerFee: minimumMinerFee,
+		},
+	}
+	if output.MinerFees == nil {
+		output.MinerFees = []types.Currency{}
+	}
+	var err error
+	output.CustomMinerPayouts, err = txn.CustomMinerPayouts()
+	if err != nil {
+		return TransactionOutputDecoded{}, fmt.Errorf("failed to get custom miner payouts: %v", err)
+	}
+
+	for _, ci := range txn.CoinInputs {
+		output.CoinInputs = append(output.CoinInputs, TransactionOutputInput{
+			ParentID:    types.OutputID(ci.ParentID),
+			Fulfillment: ci.Fulfillment,
+		})
+	}
+	for i, co := range txn.CoinOutputs {
+		output.CoinOutputs = append(output.CoinOutputs, TransactionOutputOutput{
+			ID:         types.OutputID(txn.CoinOutputID(uint64(i))),
+			Value:      co.Value,
+			UnlockHash: co.Condition.UnlockHash(),
+			Condition:  co.Condition,
+		})
+		output.Fees.CoinOutputTotal = output.Fees.CoinOutputTotal.Add(co.Value)
+	}
+	for _, bsi := range txn.BlockStakeInputs {
+		output.BlockStakeInputs = append(output.BlockStakeInputs, TransactionOutputInput{
+			ParentID:    types.OutputID(bsi.ParentID),
+			Fulfillment: bsi.Fulfillment,
+		})
+	}
+	for i, bso := range txn.BlockStakeOutputs {
+		output.BlockStakeOutputs = append(output.BlockStakeOutputs, TransactionOutputOutput{
+			ID:         types.OutputID(txn.BlockStakeOutputID(uint64(i))),
+			Value:      bso.Value,
+			UnlockHash: bso.Condition.UnlockHash(),
+			Condition:  bso.Condition,
+		})
+		output.Fees.BlockStakeInputTotal = output.Fees.BlockStakeInputTotal.Add(bso.Value)
+	}
+
+	for _, fee := range txn.MinerFees {
+		output.Fees.MinerFeeTotal = output.Fees.MinerFeeTotal.Add(fee)
+		if fee.Cmp(minimumMinerFee) < 0 {
+			output.Fees.BelowMinimum = true
+		}
+	}
+	for _, mp := range output.CustomMinerPayouts {
+		output.Fees.CustomMinerPayoutTotal = output.Fees.CustomMinerPayoutTotal.Add(mp.Value)
+	}
+	output.Fees.CoinInputTotal = txn.CoinOutputSum()
+	output.Fees.FeePerByte = output.Fees.MinerFeeTotal.Div64(uint64(output.Size))
+	return output, nil
+}
+
+// printDecodedTransaction prints the given decoded transaction in a human-friendly format.
+func (txCmd *transactionCmd) printDecodedTransaction(output TransactionOutputDecoded) {
+	currencyConvertor := txCmd.cli.CreateCurrencyConvertor()
+	toJSON := func(v interface{}) string {
+		b, err := json.Marshal(v)
+		if err != nil {
+			cli.Die("failed to encode transaction:", err)
+		}
+		return string(b)
+	}
+
+	fmt.Println("Transaction ID:", output.ID)
+	fmt.Println("Version:       ", output.Version)
+	fmt.Println("Size:          ", output.Size, "bytes")
+
+	printInputs := func(title string, inputs []TransactionOutputInput) {
+		if len(inputs) == 0 {
+			return
+		}
+		fmt.Println()
+		fmt.Println(title + ":")
+		for i, input := range inputs {
+			fmt.Printf("  #%d parent ID: %v\n", i, input.ParentID)
+			fmt.Printf("     fulfillment: %s\n", toJSON(input.Fulfillment))
+		}
+	}
+	printOutputs := func(title string, outputs []TransactionOutputOutput, value func(types.Currency) string) {
+		if len(outputs) == 0 {
+			return
+		}
+		fmt.Println()
+		fmt.Println(title + ":")
+		for i, output := range outputs {
+			fmt.Printf("  #%d ID: %v\n", i, output.ID)
+			fmt.Printf("     value: %s\n", value(output.Value))
+			fmt.Printf("     unlock hash: %v\n", output.UnlockHash)
+			fmt.Printf("     condition: %s\n", toJSON(output.Condition))
+		}
+	}
+	blockStakes := func(c types.Currency) string { return c.String() + " BS" }
+	printInputs("Coin Inputs", output.CoinInputs)
+	printOutputs("Coin Outputs", output.CoinOutputs, currencyConvertor.ToCoinStringWithUnit)
+	printInputs("BlockStake Inputs", output.BlockStakeInputs)
+	printOutputs("BlockStake Outputs", output.BlockStakeOutputs, blockStakes)
+
+	if len(output.CustomMinerPayouts) > 0 {
+		fmt.Println()
+		fmt.Println("Custom Miner Payouts:")
+		for _, mp := range output.CustomMinerPayouts {
+			fmt.Printf("  %s to %v\n", currencyConvertor.ToCoinStringWithUnit(mp.Value), mp.UnlockHash)
+		}
+	}
+	if len(output.ArbitraryData) > 0 {
+		fmt.Println()
+		fmt.Printf("Arbitrary Data: %q\n", output.ArbitraryData)
+	}
+	if output.Extension != nil {
+		fmt.Println()
+		fmt.Println("Extension:", toJSON(output.Extension))
+	}
+
+	fees := output.Fees
+	fmt.Println()
+	fmt.Println("Fees:")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "  Coin Outputs:\t%s\n", currencyConvertor.ToCoinStringWithUnit(fees.CoinOutputTotal))
+	for _, fee := range output.MinerFees {
+		fmt.Fprintf(w, "  Miner Fee:\t%s\n", currencyConvertor.ToCoinStringWithUnit(fee))
+	}
+	if !fees.CustomMinerPayoutTotal.IsZero() {
+		fmt.Fprintf(w, "  Custom Miner Payouts:\t%s\n", currencyConvertor.ToCoinStringWithUnit(fees.CustomMinerPayoutTotal))
+	}
+	fmt.Fprintf(w, "  Required Coin Inputs:\t%s\n", currencyConvertor.ToCoinStringWithUnit(fees.CoinInputTotal))
+	if len(output.BlockStakeInputs) > 0 || len(output.BlockStakeOutputs) > 0 {
+		fmt.Fprintf(w, "  Required BlockStake Inputs:\t%s\n", blockStakes(fees.BlockStakeInputTotal))
+	}
+	fmt.Fprintf(w, "  Fee per Byte:\t%s\n", currencyConvertor.ToCoinStringWithUnit(fees.FeePerByte))
+	w.Flush()
+	if fees.BelowMinimum {
+		fmt.Printf("WARNING: a miner fee is below the minimum miner fee of %s, the transaction will be rejected\n",
+			currencyConvertor.ToCoinStringWithUnit(fees.MinimumMinerFee))
+	}
+}
+
+// parseOutputFlag parses an output given as <dest>|<rawCondition>=<amount>.
+func parseOutputFlag(str string, parseCurrency parseCurrencyString) (outputPair, error) {
+	// the amount never contains '=', while a raw condition can (e.g. base64 padding)
+	idx := strings.LastIndex(str, "=")
+	if idx == -1 {
+		return outputPair{}, fmt.Errorf("output %q has to be given as <dest>|<rawCondition>=<amount>", str)
+	}
+	pairs, err := parsePairedOutputs([]string{str[:idx], str[idx+1:]}, parseCurrency)
+	if err != nil {
+		return outputPair{}, err
+	}
+	return pairs[0], nil
+}
+
+// decodeTransactionArg decodes the transaction given as argument, either JSON or hex encoded,
+// or the path to a file containing such a transaction, in which case the path is returned as well.
+// The encoding type of the transaction is returned, such that it can be encoded the same way.
+func decodeTransactionArg(arg string) (txn types.Transaction, encodingType cli.EncodingType, path string, err error) {
+	str := strings.TrimSpace(arg)
+	if !strings.HasPrefix(str, "{") && !isHex(str) {
+		var b []byte
+		b, err = ioutil.ReadFile(arg)
+		if err != nil {
+			return
+		}
+		path, str = arg, strings.TrimSpace(string(b))
+	}
+	if strings.HasPrefix(str, "{") {
+		encodingType = cli.EncodingTypeJSON
+		err = json.Unmarshal([]byte(str), &txn)
+		return
+	}
+	encodingType = cli.EncodingTypeHex
+	b, err := hex.DecodeString(str)
+	if err != nil {
+		err = errors.New("transaction has to be JSON or hex encoded")
+		return
+	}
+	err = siabin.Unmarshal(b, &txn)
+	return
+}
+
+// encodeTransaction encodes a transaction using the given encoding type,
+// JSON by default.
+func encodeTransaction(txn types.Transaction, encodingType cli.EncodingType) (string, error) {
+	if encodingType == cli.EncodingTypeHex {
+		return hex.EncodeToString(siabin.Marshal(txn)), nil
+	}
+	b, err := json.Marshal(txn)
+	return string(b), err
+}
+
+// isHex returns true if the given string is a non-empty hex string.
+func isHex(str string) bool {
+	if str == "" || len(str)%2 != 0 {
+		return false
+	}
+	_, err := hex.DecodeString(str)
+	return err == nil
+}
+
+// TransactionOutputWait is the output of the tx wait command.
+type TransactionOutputWait struct {
+	TransactionID types.TransactionID `json:"transactionid"`
+	Confirmations types.BlockHeight   `json:"confirmations"`
+	BlockHeight   types.BlockHeight   `json:"blockheight"`
+}
+
+// waitCmd is the handler for the command `rivinec tx wait`.
+// Blocks until the transaction is confirmed by the configured amount of blocks.
+func (txCmd *transactionCmd) waitCmd(idStr string) {
+	var id types.TransactionID
+	if err := id.LoadString(idStr); err != nil {
+		cli.DieWithExitCode(cli.ExitCodeUsage, "invalid transaction ID:", err)
+	}
+	cfg := txCmd.waitCfg
+	if cfg.Confirmations == 0 {
+		cli.DieWithExitCode(cli.ExitCodeUsage, "at least 1 confirmation has to be awaited")
+	}
+	if cfg.Timeout < 0 {
+		cli.DieWithExitCode(cli.ExitCodeUsage, "invalid timeout: cannot be negative")
+	}
+	var timeout <-chan time.Time
+	if cfg.Timeout > 0 {
+		timeout = time.After(cfg.Timeout)
+	}
+
+	// subscribe prior to checking the transaction, such that no event is missed
+	poolEvents, poolErrs := txCmd.subscribe("/transactionpool/events", func() interface{} {
+		return new(modules.TransactionPoolEvent)
+	})
+	consensusEvents, consensusErrs := txCmd.subscribe("/consensus/events", func() interface{} {
+		return new(api.ConsensusEvent)
+	})
+
+	var output TransactionOutputWait
+	output.TransactionID = id
+	var dropReason string
+	for {
+		pooled, confirmed := txCmd.transactionState(&output)
+		if !pooled && !confirmed {
+			if dropReason != "" {
+				cli.DieWithExitCode(cli.ExitCodeGeneral, fmt.Sprintf(
+					"transaction %s was dropped from the transaction pool: %s", id.String(), dropReason))
+			}
+			cli.DieWithExitCode(cli.ExitCodeNotFound, fmt.Sprintf(
+				"transaction %s is neither pooled nor confirmed", id.String()))
+		}
+		if output.Confirmations >= types.BlockHeight(cfg.Confirmations) {
+			break
+		}
+		if confirmed {
+			fmt.Fprintf(os.Stderr, "transaction %s confirmed at height %d, %d/%d confirmations\n",
+				id.String(), output.BlockHeight, output.Confirmations, cfg.Confirmations)
+		} else {
+			fmt.Fprintf(os.Stderr, "transaction %s is pooled, waiting for it to be confirmed\n", id.String())
+		}
+
+	wait:
+		for {
+			select {
+			case event := <-poolEvents:
+				ev := event.(*modules.TransactionPoolEvent)
+				if transactionPoolEventDropsTransaction(*ev, id) {
+					// the transaction might be pooled or confirmed once again,
+					// e.g. as part of a reorg, which is ensured by checking it
+					dropReason = ev.Type.String()
+					break wait
+				}
+			case <-consensusEvents:
+				break wait
+			case err := <-poolErrs:
+				cli.DieWithExitCode(cli.ExitCodeTemporaryError, "transaction pool event stream closed:", err)
+			case err := <-consensusErrs:
+				cli.DieWithExitCode(cli.ExitCodeTemporaryError, "consensus event stream closed:", err)
+			case <-timeout:
+				cli.DieWithExitCode(cli.ExitCodeTemporaryError, fmt.Sprintf(
+					"transaction %s not confirmed by %d blocks within %v (%d confirmations)",
+					id.String(), cfg.Confirmations, cfg.Timeout, output.Confirmations))
+			}
+		}
+	}
+
+	txCmd.cli.PrintOutput(output, func() {
+		fmt.Printf("Transaction %s confirmed at height %d, with %d confirmations\n",
+			id.String(), output.BlockHeight, output.Confirmations)
+	})
+}
+
+// subscribe opens an event stream for the given API call, returning a channel
+// receiving the decoded events and a channel receiving the error closing the stream.
+func (txCmd *transactionCmd) subscribe(call string, newEvent func() interface{}) (<-chan interface{}, <-chan error) {
+	stream, err := txCmd.cli.Subscribe(call)
+	if err != nil {
+		cli.DieWithError("failed to subscribe to "+call+":", err)
+	}
+	events, errs := make(chan interface{}), make(chan error, 1)
+	go func() {
+		defer stream.Close()
+		for {
+			event := newEvent()
+			if err := stream.ReadJSON(event); err != nil {
+				errs <- err
+				return
+			}
+			events <- event
+		}
+	}()
+	return events, errs
+}
+
+// transactionState returns whether the transaction of the given output is pooled or confirmed,
+// updating the confirmations and block height of the output.
+func (txCmd *transactionCmd) transactionState(output *TransactionOutputWait) (pooled, confirmed bool) {
+	output.Confirmations, output.BlockHeight = 0, 0
+	// check the transaction pool first, such that a transaction confirmed in between both checks is not missed
+	if txCmd.transactionPooled(output.TransactionID) {
+		return true, false
+	}
+	var txn api.ConsensusGetTransaction
+	err := txCmd.cli.GetAPI("/consensus/transactions/"+output.TransactionID.String(), &txn)
+	if err == api.ErrStatusNotFound {
+		return false, false
+	}
+	if err != nil {
+		cli.DieWithError("failed to get the confirmed transaction:", err)
+	}
+	var consensus api.ConsensusGET
+	err = txCmd.cli.GetAPI("/consensus", &consensus)
+	if err != nil {
+		cli.DieWithError("failed to get the consensus height:", err)
+	}
+	output.BlockHeight = txn.TxShortID.BlockHeight()
+	output.Confirmations = transactionConfirmations(output.BlockHeight, consensus.Height)
+	return false, true
+}
+
+// transactionPooled returns true if the transaction with the given ID is in the transaction pool.
+func (txCmd *transactionCmd) transactionPooled(id types.TransactionID) bool {
+	var ptxn api.TransactionPoolGetTransaction
+	err := txCmd.cli.GetAPI("/transactionpool/transactions/"+id.String(), &ptxn)
+	if err == api.ErrStatusNotFound {
+		return false
+	}
+	if err != nil {
+		cli.DieWithError("failed to get the pooled transaction:", err)
+	}
+	return true
+}
+
+// transactionPoolEventDropsTransaction returns true if the given event
+// removes the transaction with the given ID from the pool, without confirming it.
+func transactionPoolEventDropsTransaction(event modules.TransactionPoolEvent, id types.TransactionID) bool {
+	switch event.Type {
+	case modules.TransactionPoolEventReplaced, modules.TransactionPoolEventEvicted,
+		modules.TransactionPoolEventExpired, modules.TransactionPoolEventConflict,
+		modules.TransactionPoolEventRemoved:
+	default:
+		return false
+	}
+	for _, txid := range event.Transactions {
+		if txid == id {
+			return true
+		}
+	}
+	return false
+}
+
+// transactionConfirmations returns the amount of blocks confirming a transaction
+// part of the block at the given height, including that block, given the current height.
+func transactionConfirmations(blockHeight, height types.BlockHeight) types.BlockHeight {
+	if height < blockHeight {
+		return 0
+	}
+	return height - blockHeight + 1
+}
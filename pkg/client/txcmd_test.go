@@ -0,0 +1,208 @@
+package client
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/pkg/cli"
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+	"github.com/threefoldtech/rivine/types"
+)
+
+func TestParseOutputFlag(t *testing.T) {
+	parse := createDefaultCurrencyConvertor().ParseCoinString
+	const addr = "01746677df456546d93729066dd88514e2009930f3eebac3c93d43c88a108f8f9aa9e7c6f58893"
+
+	pair, err := parseOutputFlag(addr+"=42", parse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pair.Value.Equals(types.NewCurrency64(42000000000)) {
+		t.Error("unexpected value:", pair.Value)
+	}
+	if uh := pair.Condition.UnlockHash().String(); uh != addr {
+		t.Error("unexpected unlock hash:", uh)
+	}
+
+	// a raw condition can contain '=' itself
+	pair, err = parseOutputFlag(`{"type":0,"data":null}=1`, parse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pair.Condition.ConditionType() != types.ConditionTypeNil {
+		t.Error("unexpected condition type:", pair.Condition.ConditionType())
+	}
+
+	for _, str := range []string{addr, addr + "=", addr + "=zz"} {
+		if _, err = parseOutputFlag(str, parse); err == nil {
+			t.Errorf("expected output %q to be invalid", str)
+		}
+	}
+}
+
+func TestDecodeTransactionArg(t *testing.T) {
+	txn := types.Transaction{
+		Version:       types.TransactionVersionOne,
+		ArbitraryData: []byte("data"),
+		MinerFees:     []types.Currency{types.NewCurrency64(1)},
+	}
+	jsonStr, err := encodeTransaction(txn, cli.EncodingTypeJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hexStr, err := encodeTransaction(txn, cli.EncodingTypeHex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hexStr != hex.EncodeToString(siabin.Marshal(txn)) {
+		t.Fatal("unexpected hex encoding:", hexStr)
+	}
+
+	dir, err := ioutil.TempDir("", "txcmd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "txn.hex")
+	err = ioutil.WriteFile(path, []byte(hexStr+"\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		Arg          string
+		EncodingType cli.EncodingType
+		Path         string
+	}{
+		{jsonStr, cli.EncodingTypeJSON, ""},
+		{hexStr, cli.EncodingTypeHex, ""},
+		{path, cli.EncodingTypeHex, path},
+	}
+	for idx, testCase := range testCases {
+		decoded, encodingType, decodedPath, err := decodeTransactionArg(testCase.Arg)
+		if err != nil {
+			t.Errorf("test case #%d failed: %v", idx, err)
+			continue
+		}
+		if decoded.ID() != txn.ID() {
+			t.Errorf("test case #%d: unexpected transaction: %v", idx, decoded)
+		}
+		if encodingType != testCase.EncodingType {
+			t.Errorf("test case #%d: unexpected encoding type: %v", idx, encodingType)
+		}
+		if decodedPath != testCase.Path {
+			t.Errorf("test case #%d: unexpected path: %q", idx, decodedPath)
+		}
+	}
+
+	if _, _, _, err = decodeTransactionArg(filepath.Join(dir, "missing")); err == nil {
+		t.Error("expected missing file to fail")
+	}
+}
+
+func TestDecodeTransaction(t *testing.T) {
+	uh := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: crypto.Hash{1}}
+	txn := types.Transaction{
+		Version: types.TransactionVersionOne,
+		CoinInputs: []types.CoinInput{
+			{ParentID: types.CoinOutputID{2}, Fulfillment: types.NewFulfillment(&types.SingleSignatureFulfillment{})},
+		},
+		CoinOutputs: []types.CoinOutput{
+			{Value: types.NewCurrency64(30), Condition: types.NewCondition(types.NewUnlockHashCondition(uh))},
+			{Value: types.NewCurrency64(12), Condition: types.NewCondition(types.NewUnlockHashCondition(uh))},
+		},
+		BlockStakeOutputs: []types.BlockStakeOutput{
+			{Value: types.NewCurrency64(5), Condition: types.NewCondition(types.NewUnlockHashCondition(uh))},
+		},
+		MinerFees: []types.Currency{types.NewCurrency64(1000), types.NewCurrency64(10)},
+	}
+	output, err := decodeTransaction(txn, types.NewCurrency64(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output.ID != txn.ID() || output.Size != len(siabin.Marshal(txn)) {
+		t.Errorf("unexpected ID or size: %v, %d", output.ID, output.Size)
+	}
+	for i, co := range output.CoinOutputs {
+		if co.ID != types.OutputID(txn.CoinOutputID(uint64(i))) || co.UnlockHash != uh {
+			t.Errorf("unexpected coin output #%d: %v", i, co)
+		}
+	}
+	if len(output.CoinInputs) != 1 || output.CoinInputs[0].ParentID != (types.OutputID{2}) {
+		t.Errorf("unexpected coin inputs: %v", output.CoinInputs)
+	}
+	if len(output.BlockStakeOutputs) != 1 || output.BlockStakeOutputs[0].ID != types.OutputID(txn.BlockStakeOutputID(0)) {
+		t.Errorf("unexpected blockstake outputs: %v", output.BlockStakeOutputs)
+	}
+
+	fees := output.Fees
+	for _, testCase := range []struct {
+		Name            string
+		Value, Expected types.Currency
+	}{
+		{"coin output total", fees.CoinOutputTotal, types.NewCurrency64(42)},
+		{"miner fee total", fees.MinerFeeTotal, types.NewCurrency64(1010)},
+		{"coin input total", fees.CoinInputTotal, types.NewCurrency64(1052)},
+		{"blockstake input total", fees.BlockStakeInputTotal, types.NewCurrency64(5)},
+		{"fee per byte", fees.FeePerByte, types.NewCurrency64(1010 / uint64(output.Size))},
+	} {
+		if !testCase.Value.Equals(testCase.Expected) {
+			t.Errorf("unexpected %s: %v != %v", testCase.Name, testCase.Value, testCase.Expected)
+		}
+	}
+	if !fees.BelowMinimum {
+		t.Error("expected a miner fee below the minimum")
+	}
+	if output, _ := decodeTransaction(txn, types.NewCurrency64(10)); output.Fees.BelowMinimum {
+		t.Error("expected all miner fees to be above the minimum")
+	}
+}
+
+func TestTransactionConfirmations(t *testing.T) {
+	testCases := []struct {
+		BlockHeight, Height, Confirmations types.BlockHeight
+	}{
+		{0, 0, 1},
+		{10, 10, 1},
+		{10, 15, 6},
+		// the consensus height can lag behind the transaction lookup
+		{10, 9, 0},
+	}
+	for _, tc := range testCases {
+		if c := transactionConfirmations(tc.BlockHeight, tc.Height); c != tc.Confirmations {
+			t.Errorf("confirmations of block %d at height %d: %d != %d", tc.BlockHeight, tc.Height, c, tc.Confirmations)
+		}
+	}
+}
+
+func TestTransactionPoolEventDropsTransaction(t *testing.T) {
+	id := types.TransactionID{1}
+	for _, et := range []modules.TransactionPoolEventType{
+		modules.TransactionPoolEventReplaced, modules.TransactionPoolEventEvicted,
+		modules.TransactionPoolEventExpired, modules.TransactionPoolEventConflict,
+		modules.TransactionPoolEventRemoved,
+	} {
+		event := modules.TransactionPoolEvent{Type: et, Transactions: []types.TransactionID{{2}, id}}
+		if !transactionPoolEventDropsTransaction(event, id) {
+			t.Errorf("expected %s event to drop the transaction", et)
+		}
+		// related transactions, e.g. the replacing transactions, are not dropped
+		event = modules.TransactionPoolEvent{Type: et, Transactions: []types.TransactionID{{2}}, RelatedTransactions: []types.TransactionID{id}}
+		if transactionPoolEventDropsTransaction(event, id) {
+			t.Errorf("expected %s event to not drop a related transaction", et)
+		}
+	}
+	for _, et := range []modules.TransactionPoolEventType{
+		modules.TransactionPoolEventAdded, modules.TransactionPoolEventConfirmed,
+	} {
+		event := modules.TransactionPoolEvent{Type: et, Transactions: []types.TransactionID{id}}
+		if transactionPoolEventDropsTransaction(event, id) {
+			t.Errorf("expected %s event to not drop the transaction", et)
+		}
+	}
+}
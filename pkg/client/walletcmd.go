@@ -2,21 +2,30 @@ package client
 
 import (
 	"bytes"
+	"crypto/rand"
 	"encoding/base64"
+	"encoding/csv"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"math/big"
 	"os"
+	"sort"
 	"strconv"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/bgentry/speakeasy"
 	"github.com/spf13/cobra"
 
+	"github.com/threefoldtech/rivine/crypto"
 	"github.com/threefoldtech/rivine/modules"
 	"github.com/threefoldtech/rivine/pkg/api"
 	"github.com/threefoldtech/rivine/pkg/cli"
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
 	"github.com/threefoldtech/rivine/types"
 )
 
@@ -44,12 +53,37 @@ func createWalletCmd(cli *CommandLineClient) *WalletCommand {
 			Long:  "Generate a new wallet address from the wallet's primary seed.",
 			Run:   Wrap(walletCmd.addressCmd),
 		}
+		requestCmd = &cobra.Command{
+			Use:   "request <amount> [message]",
+			Short: "Create a payment request",
+			Long: `Create a payment request URI for the given amount and optional message,
+	to be paid to a new wallet address, or to the address given using the --address flag.
+
+	The URI has the form <chainname>:<address>?amount=<amount>&message=<message>,
+	with the amount expressed in the one coin unit. Using the --qr flag it is printed
+	as a QR code as well, such that it can be scanned by a mobile wallet.`,
+			Args: cobra.RangeArgs(1, 2),
+			Run:  walletCmd.requestCmd,
+		}
 		addressesCmd = &cobra.Command{
 			Use:   "addresses",
 			Short: "List all addresses",
 			Long:  "List all addresses that have been generated by the wallet",
 			Run:   Wrap(walletCmd.addressesCmd),
 		}
+		validateCmd = &cobra.Command{
+			Use:   "validate <address>",
+			Short: "Validate and inspect an address",
+			Long: `Validate the given address, reporting whether it is syntactically valid,
+	its unlock type (single signature, multisig, atomic swap or unknown),
+	whether its checksum is valid, and whether it belongs to the wallet.
+
+	Whether the address belongs to the wallet can only be reported if the wallet is unlocked.
+	The command fails with the invalidaddress exit code (9) if the address is invalid.
+	`,
+			Args: cobra.ExactArgs(1),
+			Run:  Wrap(walletCmd.validateCmd),
+		}
 		initCmd = &cobra.Command{
 			Use:   "init",
 			Short: "Initialize and encrypt a new wallet",
@@ -69,12 +103,45 @@ func createWalletCmd(cli *CommandLineClient) *WalletCommand {
 			Run:   Wrap(walletCmd.lockCmd),
 		}
 		signTxCmd = &cobra.Command{
-			Use:   "sign <txnjson>",
+			Use:   "sign <txnjson>|<txnfile>",
 			Short: "Sign inputs from the transaction",
 			Long: `Signs as much of the inputs transaction. Iterate over every input, and check if they can be signed
-	by any of the keys in the wallet.`,
+	by any of the keys in the wallet.
+
+	The transaction can be given as JSON, or as the path to a file containing the JSON-encoded transaction,
+	in which case the signed transaction is written back to that file, such that a (multisig) transaction
+	can be passed along to all its signatories, each adding their signature(s) to the same file.`,
 			Run: Wrap(walletCmd.signTxCmd),
 		}
+		signMessageCmd = &cobra.Command{
+			Use:     "sign-message <address> [<message>]",
+			Aliases: []string{"signmessage"},
+			Short:   "Sign a message using the key of an address",
+			Long: `Sign a message using the key pair of a single signature address of the wallet,
+	such that anyone can verify that the message was signed by the owner of that address,
+	using the verify-message command and only the address.
+
+	The message is read from the standard input if it is not given as an argument.
+	The signature is printed as a base64 string, which contains the public key of the address.
+	`,
+			Args: cobra.RangeArgs(1, 2),
+			Run:  walletCmd.signMessageCmd,
+		}
+		verifyMessageCmd = &cobra.Command{
+			Use:     "verify-message <address> <signature> [<message>]",
+			Aliases: []string{"verifymessage"},
+			Short:   "Verify the signature of a message (offline)",
+			Long: `Verify that the given signature, as created by the sign-message command,
+	is a valid signature of the message, created by the owner of the given address.
+	The verification does not require a connection to the daemon, nor a wallet.
+
+	The message is read from the standard input if it is not given as an argument.
+	The command fails if the signature is invalid.
+	`,
+			Args:        cobra.RangeArgs(2, 3),
+			Annotations: map[string]string{annotationOffline: "true"},
+			Run:         walletCmd.verifyMessageCmd,
+		}
 		seedsCmd = &cobra.Command{
 			Use:   "seeds",
 			Short: "Retrieve information about your seeds",
@@ -135,13 +202,17 @@ func createWalletCmd(cli *CommandLineClient) *WalletCommand {
 	instead of an unlockHash, you can also give a JSON-encoded UnlockCondition directly,
 	giving you more control and options over how exactly the block stake is to be unlocked.
 	
-	Amounts have to be given expressed in the OneCoin unit, and without the unit of currency.
-	Decimals are possible and are to be expressed using English conventions.
-	
-	Amounts have to be given expressed in the OneCoin unit, and without the unit of currency.
+	Amounts are expressed in the OneCoin unit, unless a unit is given explicitly:
+	the coin unit of the chain (or simply coin), optionally prefixed by m, u or n, e.g. 1500mcoin.
 	Decimals are possible and have to be defined using the decimal point.
 	
 	The Minimum Miner Fee will be added on top of the total given amount automatically.
+	A higher miner fee can be paid using the --fee flag, or computed for the estimated size
+	of the transaction using the --fee-per-byte or --fee-priority (low, normal or high) flag,
+	in which case the fee has to be confirmed, unless the --yes flag is given.
+	
+	By default the wallet selects the outputs to spend and sends the change to a new wallet address,
+	which can be controlled using the --coininput, --exclude-locked and --change flags.
 	`,
 			Run: walletCmd.sendCoinsCmd,
 		}
@@ -157,19 +228,48 @@ func createWalletCmd(cli *CommandLineClient) *WalletCommand {
 	Decimals are possible and have to be defined using the decimal point.
 	
 	The Minimum Miner Fee will be added on top of the total given amount automatically.
+	A higher miner fee can be paid using the --fee flag, or computed for the estimated size
+	of the transaction using the --fee-per-byte or --fee-priority (low, normal or high) flag,
+	in which case the fee has to be confirmed, unless the --yes flag is given.
+	
+	By default the wallet selects the outputs to spend and sends the change to a new wallet address,
+	which can be controlled using the --coininput, --blockstakeinput, --exclude-locked and --change flags.
 	`,
 			Run: walletCmd.sendBlockStakesCmd,
 		}
+		sendManyCmd = &cobra.Command{
+			Use:   "sendmany --file <payments.csv>",
+			Short: "Send coins to many addresses, as listed in a CSV file",
+			Long: `Send coins to many addresses, as listed in a CSV file with one payment per row:
+	<address>,<amount>[,<label>]
+
+	Amounts are expressed in the OneCoin unit, unless a unit is given explicitly:
+	the coin unit of the chain (or simply coin), optionally prefixed by m, u or n, e.g. 1500mcoin.
+	The optional label is only used to identify the payment in the report.
+	A first row with "address" as its first column is skipped as header,
+	as are rows starting with '#'.
+
+	All rows are validated before any coins are sent. The payments are sent
+	in batches of up to --batch-size payments, one transaction per batch.
+	A report with the result of each row is printed when done.
+	If a batch fails, the payments of the remaining batches are not sent.
+
+	The Minimum Miner Fee will be added on top of the total amount of each transaction automatically.
+	`,
+			Args: cobra.NoArgs,
+			Run:  Wrap(walletCmd.sendManyCmd),
+		}
 		sendTxCmd = &cobra.Command{
-			Use:   "transaction <txnjson>",
+			Use:   "transaction <txnjson>|<txnfile>",
 			Short: "Publish a raw transaction",
-			Long:  "Publish a raw transasction. The transaction must be given in json format. The inputs don't need to be related to the current wallet",
-			Run:   Wrap(walletCmd.sendTxCmd),
+			Long: `Publish a raw transasction. The transaction must be given in json format,
+	or as the path to a file containing the JSON-encoded transaction. The inputs don't need to be related to the current wallet`,
+			Run: Wrap(walletCmd.sendTxCmd),
 		}
 
 		listCmd = &cobra.Command{
 			Use:   "list",
-			Short: "List either locked or unlocked unspent outputs",
+			Short: "List either locked, unlocked or multisig unspent outputs",
 			// Run field is not set, as the list command itself is not a valid command.
 			// A subcommand must be provided.
 		}
@@ -193,6 +293,18 @@ func createWalletCmd(cli *CommandLineClient) *WalletCommand {
 	`,
 			Run: walletCmd.listLockedCmd,
 		}
+		listMultisigCmd = &cobra.Command{
+			Use:   "multisig [address]",
+			Args:  cobra.RangeArgs(0, 1),
+			Short: "List the multisig wallets co-owned by this wallet",
+			Long: `List all multisig wallets of which this wallet is one of the possible signatories,
+	together with the IDs of their unspent coin and blockstake outputs.
+
+	If an address is given, only the multisig wallet with that address is shown.
+	Use the create cointransaction or blockstaketransaction command to start a spend of these outputs.
+	`,
+			Run: walletCmd.listMultisigCmd,
+		}
 
 		createCmd = &cobra.Command{
 			Use:   "create",
@@ -215,7 +327,8 @@ func createWalletCmd(cli *CommandLineClient) *WalletCommand {
 	The outputs can be given as a pair of value and a raw output condition (or
 	address, which resolved to a singlesignature condition).
 	
-	Amounts have to be given expressed in the OneCoin unit, and without the unit of currency.
+	Amounts are expressed in the OneCoin unit, unless a unit is given explicitly:
+	the coin unit of the chain (or simply coin), optionally prefixed by m, u or n, e.g. 1500mcoin.
 	Decimals are possible and have to be defined using the decimal point.
 	
 	The Minimum Miner Fee will be added on top of the total given amount automatically.
@@ -236,12 +349,107 @@ func createWalletCmd(cli *CommandLineClient) *WalletCommand {
 	`,
 			Run: walletCmd.createBlockStakeTxCmd,
 		}
+
+		coldCmd = &cobra.Command{
+			Use:   "cold",
+			Short: "Create, sign and send transactions using a cold (offline) wallet",
+			Long: `Create, sign and send transactions for an air-gapped wallet, of which the seed is only
+	loaded on an offline machine.
+
+	A funded unsigned transaction is created into a file on an online machine,
+	using the create command. That file is then signed on the offline machine, using the sign command.
+	Finally the signed file is published on the online machine, using the send command.
+	`,
+			// Run field is not set, as the cold command itself is not a valid command.
+			// A subcommand must be provided.
+		}
+		coldCreateCmd = &cobra.Command{
+			Use:   "create <txnfile> <address> <dest>|<rawCondition> <amount> [<dest>|<rawCondition> <amount>]...",
+			Short: "Create a funded unsigned transaction (online)",
+			Long: `Create a transaction sending coins from the given address to the given outputs,
+	funded by the unspent coin outputs of that address, and write it unsigned to the given file.
+
+	This command does not require a wallet, but does require the daemon to have
+	the unlock hash index of the consensus set enabled.
+	Any remainder is refunded to the given address.
+
+	Amounts are expressed in the OneCoin unit, unless a unit is given explicitly:
+	the coin unit of the chain (or simply coin), optionally prefixed by m, u or n, e.g. 1500mcoin.
+	Decimals are possible and have to be defined using the decimal point.
+
+	The Minimum Miner Fee will be added on top of the total given amount automatically.
+	`,
+			Args: cobra.MinimumNArgs(4),
+			Run:  walletCmd.coldCreateCmd,
+		}
+		coldSignCmd = &cobra.Command{
+			Use:   "sign <txnfile>",
+			Short: "Sign an unsigned transaction file (offline)",
+			Long: `Sign all inputs of the unsigned transaction file which can be signed by the wallet,
+	writing the signed transaction back into the file.
+
+	The wallet does not need to be synced, and thus can be kept offline.
+	`,
+			Run: Wrap(walletCmd.coldSignCmd),
+		}
+		coldSendCmd = &cobra.Command{
+			Use:   "send <txnfile>",
+			Short: "Publish a signed transaction file (online)",
+			Run:   Wrap(walletCmd.coldSendCmd),
+		}
+		coldGenerateCmd = &cobra.Command{
+			Use:   "generate [<n>]",
+			Short: "Generate a seed and its first addresses (offline)",
+			Long: `Generate a new seed and derive its first n addresses, 1 by default,
+	without connecting to the daemon, such that a cold wallet can be set up on an air-gapped machine.
+
+	The seed is printed as a mnemonic, which can be loaded into the wallet of the offline machine
+	using the recover command. The printed addresses are the first addresses that wallet will generate,
+	and can be used to receive coins on the cold wallet from any machine.
+	`,
+			Args:        cobra.RangeArgs(0, 1),
+			Annotations: map[string]string{annotationOffline: "true"},
+			Run:         walletCmd.coldGenerateCmd,
+		}
+
+		watchCmd = &cobra.Command{
+			Use:   "watch",
+			Short: "Watch addresses not owned by the wallet",
+			Long: `Add, remove and list addresses which are not owned by the wallet,
+	but of which the balance and transactions are tracked by the wallet.
+	`,
+			// Run field is not set, as the watch command itself is not a valid command.
+			// A subcommand must be provided.
+		}
+		watchAddCmd = &cobra.Command{
+			Use:   "add <address>...",
+			Short: "Watch one or multiple addresses",
+			Long: `Watch one or multiple addresses, not owned by the wallet.
+	The daemon rescans the blockchain, such that the full history of the addresses is tracked.
+	`,
+			Args: cobra.MinimumNArgs(1),
+			Run:  walletCmd.watchAddCmd,
+		}
+		watchRemoveCmd = &cobra.Command{
+			Use:   "remove <address>...",
+			Short: "Stop watching one or multiple addresses",
+			Args:  cobra.MinimumNArgs(1),
+			Run:   walletCmd.watchRemoveCmd,
+		}
+		watchListCmd = &cobra.Command{
+			Use:   "list",
+			Short: "List all watched addresses",
+			Long:  "List all addresses watched by the wallet, with their confirmed balances, and optionally their transactions.",
+			Run:   Wrap(walletCmd.watchListCmd),
+		}
 	)
 
 	// define wallet command tree
 	rootCmd.AddCommand(
 		addressCmd,
 		addressesCmd,
+		validateCmd,
+		requestCmd,
 		initCmd,
 		recoverCmd,
 		lockCmd,
@@ -249,13 +457,18 @@ func createWalletCmd(cli *CommandLineClient) *WalletCommand {
 		loadCmd,
 		seedsCmd,
 		sendCmd,
+		sendManyCmd,
 		balanceCmd,
 		listTransactionsCmd,
 		blockStakeStatCmd,
 		registerDataCmd,
 		listCmd,
 		createCmd,
-		signTxCmd)
+		coldCmd,
+		watchCmd,
+		signTxCmd,
+		signMessageCmd,
+		verifyMessageCmd)
 
 	sendCmd.AddCommand(
 		sendCoinsCmd,
@@ -266,20 +479,89 @@ func createWalletCmd(cli *CommandLineClient) *WalletCommand {
 
 	listCmd.AddCommand(
 		listUnlockedCmd,
-		listLockedCmd)
+		listLockedCmd,
+		listMultisigCmd)
 
 	createCmd.AddCommand(
 		createMultisigAddressesCmd,
 		createCoinTxCmd,
 		createBlockStakeTxCmd)
 
+	coldCmd.AddCommand(
+		coldCreateCmd,
+		coldSignCmd,
+		coldSendCmd,
+		coldGenerateCmd)
+
+	watchCmd.AddCommand(
+		watchAddCmd,
+		watchRemoveCmd,
+		watchListCmd)
+
 	// define config of commands that have a config
+	addQRStyleFlag(addressCmd, &walletCmd.addressCfg.QRStyle)
+	addQRStyleFlag(requestCmd, &walletCmd.requestCfg.QRStyle)
+	requestCmd.Flags().StringVar(
+		&walletCmd.requestCfg.Address, "address", "",
+		"address to request the payment to, instead of a new address of the wallet")
 	sendCoinsCmd.Flags().StringVar(
 		&walletCmd.sendCoinsCfg.Data,
 		"data", "", "optional arbitrary data (or description) to attach to transaction")
 	sendBlockStakesCmd.Flags().StringVar(
 		&walletCmd.sendBlockStakesCfg.Data,
 		"data", "", "optional arbitrary data (or description) to attach to transaction")
+	sendManyCmd.Flags().StringVar(
+		&walletCmd.sendManyCfg.File, "file", "",
+		"path of the CSV file listing the payments to send (required)")
+	sendManyCmd.Flags().IntVar(
+		&walletCmd.sendManyCfg.BatchSize, "batch-size", 100,
+		"max amount of payments to send within a single transaction")
+	sendManyCmd.Flags().StringVar(
+		&walletCmd.sendManyCfg.Data,
+		"data", "", "optional arbitrary data (or description) to attach to each transaction")
+	for _, cmd := range []struct {
+		Command *cobra.Command
+		Flags   *coinControlFlags
+	}{
+		{sendCoinsCmd, &walletCmd.sendCoinsCfg.CoinControl},
+		{sendBlockStakesCmd, &walletCmd.sendBlockStakesCfg.CoinControl},
+	} {
+		cmd.Command.Flags().StringArrayVar(
+			&cmd.Flags.CoinInputs, "coininput", nil,
+			"ID of a coin output to fund the transaction with, only the given outputs are spent (can be given multiple times)")
+		cmd.Command.Flags().BoolVar(
+			&cmd.Flags.ExcludeLocked, "exclude-locked", false,
+			"do not spend outputs with a time lock condition, even if the lock has been reached already")
+		cmd.Command.Flags().StringVar(
+			&cmd.Flags.ChangeAddress, "change", "",
+			"address to send the change to, instead of a new address of the wallet")
+	}
+	for _, cmd := range []struct {
+		Command *cobra.Command
+		Flags   *feeFlags
+	}{
+		{sendCoinsCmd, &walletCmd.sendCoinsCfg.Fee},
+		{sendBlockStakesCmd, &walletCmd.sendBlockStakesCfg.Fee},
+	} {
+		cmd.Command.Flags().StringVar(
+			&cmd.Flags.Fee, "fee", "",
+			"miner fee to pay, instead of the minimum transaction fee")
+		cmd.Command.Flags().StringVar(
+			&cmd.Flags.FeePerByte, "fee-per-byte", "",
+			"miner fee to pay per byte of the (estimated) transaction size")
+		cmd.Command.Flags().StringVar(
+			&cmd.Flags.FeePriority, "fee-priority", "",
+			"pay the fee-per-byte estimated by the transaction pool for the given priority: "+strings.Join(feePriorities, ", "))
+		cmd.Command.Flags().BoolVarP(
+			&cmd.Flags.Yes, "yes", "y", false,
+			"send the transaction without asking to confirm the miner fee")
+	}
+	sendBlockStakesCmd.Flags().StringArrayVar(
+		&walletCmd.sendBlockStakesCfg.CoinControl.BlockStakeInputs, "blockstakeinput", nil,
+		"ID of a blockstake output to fund the transaction with, only the given outputs are spent (can be given multiple times)")
+	watchListCmd.Flags().BoolVar(
+		&walletCmd.watchListCfg.Transactions, "transactions", false,
+		"list the transactions of each watched address as well")
 	initCmd.Flags().BoolVar(
 		&walletCmd.walletInitCfg.Plain,
 		"plain", false, "create a plain wallet, requiring no passphrase")
@@ -303,6 +585,8 @@ func createWalletCmd(cli *CommandLineClient) *WalletCommand {
 		RootCmdLoad:   loadCmd,
 		RootCmdList:   listCmd,
 		RootCmdCreate: createCmd,
+		RootCmdCold:   coldCmd,
+		RootCmdWatch:  watchCmd,
 	}
 }
 
@@ -315,15 +599,33 @@ type WalletCommand struct {
 	RootCmdLoad   *cobra.Command
 	RootCmdList   *cobra.Command
 	RootCmdCreate *cobra.Command
+	RootCmdCold   *cobra.Command
+	RootCmdWatch  *cobra.Command
 }
 
 type walletCmd struct {
-	cli          *CommandLineClient
+	cli        *CommandLineClient
+	addressCfg struct {
+		QRStyle qrStyle
+	}
+	requestCfg struct {
+		Address string
+		QRStyle qrStyle
+	}
 	sendCoinsCfg struct {
-		Data string
+		Data        string
+		CoinControl coinControlFlags
+		Fee         feeFlags
 	}
 	sendBlockStakesCfg struct {
-		Data string
+		Data        string
+		CoinControl coinControlFlags
+		Fee         feeFlags
+	}
+	sendManyCfg struct {
+		File      string
+		BatchSize int
+		Data      string
 	}
 	walletInitCfg struct {
 		Plain bool
@@ -336,6 +638,9 @@ type walletCmd struct {
 		Plain bool
 		Seed  string
 	}
+	watchListCfg struct {
+		Transactions bool
+	}
 }
 
 // addressCmd fetches a new address from the wallet that will be able to
@@ -346,7 +651,214 @@ func (walletCmd *walletCmd) addressCmd() {
 	if err != nil {
 		cli.DieWithError("Could not generate new address:", err)
 	}
-	fmt.Printf("Created new address: %s\n", addr.Address)
+	walletCmd.cli.PrintOutput(addr, func() {
+		fmt.Printf("Created new address: %s\n", addr.Address)
+		printQRCode(walletCmd.addressCfg.QRStyle, addr.Address.String())
+	})
+}
+
+// validateCmd is the handler for the command `rivinec wallet validate <address>`.
+// Validates and inspects the given address, checking whether it belongs to the wallet if it is valid.
+func (walletCmd *walletCmd) validateCmd(str string) {
+	output := validateAddress(str)
+	if output.Valid {
+		owned, err := walletCmd.ownsAddress(output.address)
+		if err != nil {
+			output.OwnedError = err.Error()
+		} else {
+			output.Owned = &owned
+		}
+	}
+
+	walletCmd.cli.PrintOutput(output, func() {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "Address:\t%s\n", output.Address)
+		fmt.Fprintf(w, "Valid:\t%s\n", YesNo(output.Valid))
+		if output.Error != "" {
+			fmt.Fprintf(w, "Error:\t%s\n", output.Error)
+		}
+		if output.Type != "" {
+			fmt.Fprintf(w, "Unlock Type:\t%s (%d)\n", output.Type, output.UnlockType)
+		}
+		if output.Checksum != "" {
+			fmt.Fprintf(w, "Checksum:\t%s\n", output.Checksum)
+		}
+		switch {
+		case output.Owned != nil:
+			fmt.Fprintf(w, "Owned by Wallet:\t%s\n", YesNo(*output.Owned))
+		case output.OwnedError != "":
+			fmt.Fprintf(w, "Owned by Wallet:\tunknown (%s)\n", output.OwnedError)
+		}
+		w.Flush()
+	})
+	if !output.Valid {
+		cli.DieWithExitCode(cli.ExitCodeInvalidAddress, "invalid address:", output.Error)
+	}
+}
+
+// ownsAddress returns true if the given address is a single signature address generated by the wallet,
+// or a multisig address of which the wallet is a signatory.
+func (walletCmd *walletCmd) ownsAddress(address types.UnlockHash) (bool, error) {
+	switch address.Type {
+	case types.UnlockTypePubKey:
+		var addrs api.WalletAddressesGET
+		err := walletCmd.cli.GetAPI("/wallet/addresses", &addrs)
+		if err != nil {
+			return false, err
+		}
+		for _, addr := range addrs.Addresses {
+			if addr.Cmp(address) == 0 {
+				return true, nil
+			}
+		}
+		return false, nil
+	case types.UnlockTypeMultiSig:
+		var status api.WalletGET
+		err := walletCmd.cli.GetAPI("/wallet", &status)
+		if err != nil {
+			return false, err
+		}
+		if !status.Unlocked {
+			return false, modules.ErrLockedWallet
+		}
+		for _, wallet := range status.MultiSigWallets {
+			if wallet.Address.Cmp(address) == 0 {
+				return true, nil
+			}
+		}
+		return false, nil
+	default:
+		// atomic swap contracts and unknown unlock types are never tracked by the wallet
+		return false, nil
+	}
+}
+
+// WalletOutputValidatedAddress represents the formatted output
+// of the wallet validate command.
+type WalletOutputValidatedAddress struct {
+	Address string `json:"address"`
+	Valid   bool   `json:"valid"`
+	// Error is the reason why the address is invalid.
+	Error string `json:"error,omitempty"`
+	// Type is the human-readable name of the unlock type,
+	// only defined if the address is syntactically valid.
+	Type       string           `json:"type,omitempty"`
+	UnlockType types.UnlockType `json:"unlocktype"`
+	// Checksum is one of "valid", "invalid" or "none" (nil addresses have no checksum),
+	// only defined if the address is syntactically valid.
+	Checksum string `json:"checksum,omitempty"`
+	// Owned defines whether the address belongs to the wallet,
+	// it is nil if this could not be checked, in which case the reason is given in OwnedError.
+	Owned      *bool  `json:"owned,omitempty"`
+	OwnedError string `json:"ownederror,omitempty"`
+
+	address types.UnlockHash
+}
+
+// address checksum states, as reported by the wallet validate command
+const (
+	addressChecksumValid   = "valid"
+	addressChecksumInvalid = "invalid"
+	addressChecksumNone    = "none"
+)
+
+// validateAddress validates and inspects the given address,
+// checking its syntax, unlock type and checksum separately.
+func validateAddress(str string) WalletOutputValidatedAddress {
+	output := WalletOutputValidatedAddress{Address: str}
+	if len(str) != (1+crypto.HashSize+types.UnlockHashChecksumSize)*2 {
+		output.Error = fmt.Sprintf("address has to be %d hex characters long, got %d characters",
+			(1+crypto.HashSize+types.UnlockHashChecksumSize)*2, len(str))
+		return output
+	}
+	b, err := hex.DecodeString(str)
+	if err != nil {
+		output.Error = "address is not hex-encoded: " + err.Error()
+		return output
+	}
+
+	output.address.Type = types.UnlockType(b[0])
+	copy(output.address.Hash[:], b[1:1+crypto.HashSize])
+	output.UnlockType = output.address.Type
+	switch output.address.Type {
+	case types.UnlockTypeNil:
+		output.Type = "nil"
+	case types.UnlockTypePubKey:
+		output.Type = "single signature"
+	case types.UnlockTypeMultiSig:
+		output.Type = "multisig"
+	case types.UnlockTypeAtomicSwap:
+		output.Type = "atomic swap"
+	default:
+		output.Type = "unknown"
+	}
+
+	if output.address.Type == types.UnlockTypeNil {
+		// the checksum of nil addresses is not verified
+		output.Checksum = addressChecksumNone
+	} else {
+		expected := crypto.HashAll(output.address.Type, output.address.Hash)
+		if bytes.Equal(expected[:types.UnlockHashChecksumSize], b[1+crypto.HashSize:]) {
+			output.Checksum = addressChecksumValid
+		} else {
+			output.Checksum = addressChecksumInvalid
+			output.Error = types.ErrInvalidUnlockHashChecksum.Error()
+			return output
+		}
+	}
+	output.Valid = true
+	return output
+}
+
+// requestCmd creates a payment request URI for the given amount and optional message,
+// to be paid to a new wallet address or the address defined by the --address flag.
+func (walletCmd *walletCmd) requestCmd(cmd *cobra.Command, args []string) {
+	amount := parseCoinArg(walletCmd.cli.CreateCurrencyConvertor(), args[0])
+	var message string
+	if len(args) == 2 {
+		message = args[1]
+	}
+
+	var address types.UnlockHash
+	if walletCmd.requestCfg.Address != "" {
+		err := address.LoadString(walletCmd.requestCfg.Address)
+		if err != nil {
+			cmd.UsageFunc()(cmd)
+			cli.DieWithExitCode(cli.ExitCodeInvalidAddress, "invalid address:", err)
+		}
+	} else {
+		addr := new(api.WalletAddressGET)
+		err := walletCmd.cli.GetAPI("/wallet/address", addr)
+		if err != nil {
+			cli.DieWithError("Could not generate new address:", err)
+		}
+		address = addr.Address
+	}
+
+	// the URI amount is always expressed in the one coin unit, regardless of the units of the client
+	var coinAmount string
+	if !amount.IsZero() {
+		coinAmount = NewCurrencyConvertor(walletCmd.cli.Config.CurrencyUnits, walletCmd.cli.Config.CurrencyCoinUnit).ToCoinString(amount)
+	}
+	output := WalletOutputPaymentRequest{
+		Address: address,
+		Amount:  amount,
+		Message: message,
+		URI:     paymentRequestURI(walletCmd.cli.Config.ChainName, address, coinAmount, message),
+	}
+	walletCmd.cli.PrintOutput(output, func() {
+		fmt.Println(output.URI)
+		printQRCode(walletCmd.requestCfg.QRStyle, output.URI)
+	})
+}
+
+// WalletOutputPaymentRequest represents the formatted output
+// of the wallet request command.
+type WalletOutputPaymentRequest struct {
+	Address types.UnlockHash `json:"address"`
+	Amount  types.Currency   `json:"amount"`
+	Message string           `json:"message,omitempty"`
+	URI     string           `json:"uri"`
 }
 
 // addressesCmd fetches the list of addresses that the wallet knows.
@@ -356,9 +868,11 @@ func (walletCmd *walletCmd) addressesCmd() {
 	if err != nil {
 		cli.DieWithError("Failed to fetch addresses:", err)
 	}
-	for _, addr := range addrs.Addresses {
-		fmt.Println(addr)
-	}
+	walletCmd.cli.PrintOutput(addrs, func() {
+		for _, addr := range addrs.Addresses {
+			fmt.Println(addr)
+		}
+	})
 }
 
 // initCmd encrypts the wallet with the given password
@@ -367,10 +881,10 @@ func (walletCmd *walletCmd) initCmd() {
 
 	var data string
 	if !walletCmd.walletInitCfg.Plain {
-		fmt.Println("You have to provide a passphrase!")
-		fmt.Println("If you have an existing mnemonic you can use the recover wallet command instead.")
+		fmt.Fprintln(walletCmd.cli.InfoWriter(), "You have to provide a passphrase!")
+		fmt.Fprintln(walletCmd.cli.InfoWriter(), "If you have an existing mnemonic you can use the recover wallet command instead.")
 
-		passphrase, err := speakeasy.Ask("Wallet passphrase: ")
+		passphrase, err := speakeasy.FAsk(walletCmd.cli.InfoWriter(), "Wallet passphrase: ")
 		if err != nil {
 			cli.Die("Reading passphrase failed:", err)
 		}
@@ -378,7 +892,7 @@ func (walletCmd *walletCmd) initCmd() {
 			cli.Die("passphrase is required and cannot be empty")
 		}
 
-		repassphrase, err := speakeasy.Ask("Reenter passphrase: ")
+		repassphrase, err := speakeasy.FAsk(walletCmd.cli.InfoWriter(), "Reenter passphrase: ")
 		if err != nil {
 			cli.Die("Reading passphrase failed:", err)
 		}
@@ -399,10 +913,12 @@ func (walletCmd *walletCmd) initCmd() {
 		}
 	}
 
-	fmt.Printf("Mnemonic of primary seed:\n%s\n\n", er.PrimarySeed)
-	if !walletCmd.walletInitCfg.Plain {
-		fmt.Printf("Wallet encrypted with given passphrase\n")
-	}
+	walletCmd.cli.PrintOutput(er, func() {
+		fmt.Printf("Mnemonic of primary seed:\n%s\n\n", er.PrimarySeed)
+		if !walletCmd.walletInitCfg.Plain {
+			fmt.Printf("Wallet encrypted with given passphrase\n")
+		}
+	})
 }
 
 // recoverCmd encrypts the wallet with the given password,
@@ -412,10 +928,10 @@ func (walletCmd *walletCmd) recoverCmd() {
 
 	var data string
 	if !walletCmd.walletRecoverCfg.Plain {
-		fmt.Println("You have to provide a passphrase and existing mnemonic!")
-		fmt.Println("If you have no existing mnemonic use the init wallet command instead!")
+		fmt.Fprintln(walletCmd.cli.InfoWriter(), "You have to provide a passphrase and existing mnemonic!")
+		fmt.Fprintln(walletCmd.cli.InfoWriter(), "If you have no existing mnemonic use the init wallet command instead!")
 
-		passphrase, err := speakeasy.Ask("Wallet passphrase: ")
+		passphrase, err := speakeasy.FAsk(walletCmd.cli.InfoWriter(), "Wallet passphrase: ")
 		if err != nil {
 			cli.Die("Reading passphrase failed:", err)
 		}
@@ -423,7 +939,7 @@ func (walletCmd *walletCmd) recoverCmd() {
 			cli.Die("passphrase is required and cannot be empty")
 		}
 
-		repassphrase, err := speakeasy.Ask("Reenter passphrase: ")
+		repassphrase, err := speakeasy.FAsk(walletCmd.cli.InfoWriter(), "Reenter passphrase: ")
 		if err != nil {
 			cli.Die("Reading passphrase failed:", err)
 		}
@@ -438,7 +954,7 @@ func (walletCmd *walletCmd) recoverCmd() {
 	mnemonic := walletCmd.walletRecoverCfg.Seed
 	if mnemonic == "" {
 		var err error
-		mnemonic, err = speakeasy.Ask("Enter existing mnemonic to be used as primary seed: ")
+		mnemonic, err = speakeasy.FAsk(walletCmd.cli.InfoWriter(), "Enter existing mnemonic to be used as primary seed: ")
 		if err != nil {
 			cli.Die("Reading mnemonic failed:", err)
 		}
@@ -462,17 +978,19 @@ func (walletCmd *walletCmd) recoverCmd() {
 		cli.Die("Wallet was created, but returned primary seed mnemonic was unexpected:\n\n" + er.PrimarySeed)
 	}
 
-	fmt.Printf("Mnemonic of primary seed:\n%s\n\n", er.PrimarySeed)
-	if !walletCmd.walletRecoverCfg.Plain {
-		fmt.Printf("Wallet encrypted with given passphrase\n")
-	}
+	walletCmd.cli.PrintOutput(er, func() {
+		fmt.Printf("Mnemonic of primary seed:\n%s\n\n", er.PrimarySeed)
+		if !walletCmd.walletRecoverCfg.Plain {
+			fmt.Printf("Wallet encrypted with given passphrase\n")
+		}
+	})
 }
 
 // loadSeedCmd adds a seed to the wallet's list of seeds
 func (walletCmd *walletCmd) loadSeedCmd() {
 	var data string
 	if !walletCmd.walletLoadSeedCfg.Plain {
-		passphrase, err := speakeasy.Ask("Wallet passphrase: ")
+		passphrase, err := speakeasy.FAsk(walletCmd.cli.InfoWriter(), "Wallet passphrase: ")
 		if err != nil {
 			cli.Die("Reading passphrase failed:", err)
 		}
@@ -481,7 +999,7 @@ func (walletCmd *walletCmd) loadSeedCmd() {
 	seed := walletCmd.walletLoadSeedCfg.Seed
 	if seed == "" {
 		var err error
-		seed, err = speakeasy.Ask("Existing Mnemonic: ")
+		seed, err = speakeasy.FAsk(walletCmd.cli.InfoWriter(), "Existing Mnemonic: ")
 		if err != nil {
 			cli.Die("Reading seed failed:", err)
 		}
@@ -491,7 +1009,9 @@ func (walletCmd *walletCmd) loadSeedCmd() {
 	if err != nil {
 		cli.DieWithError("Could not add seed:", err)
 	}
-	fmt.Println("Added Key")
+	walletCmd.cli.PrintOutput(EmptyOutput{}, func() {
+		fmt.Println("Added Key")
+	})
 }
 
 // lockCmd locks the wallet
@@ -500,6 +1020,7 @@ func (walletCmd *walletCmd) lockCmd() {
 	if err != nil {
 		cli.DieWithError("Could not lock wallet:", err)
 	}
+	walletCmd.cli.PrintOutput(EmptyOutput{}, func() {})
 }
 
 // seedsCmd returns the current seed {
@@ -509,12 +1030,14 @@ func (walletCmd *walletCmd) seedsCmd() {
 	if err != nil {
 		cli.DieWithError("Error retrieving the current seed:", err)
 	}
-	fmt.Printf("Primary Seed: %s\n"+
-		"Addresses Remaining %d\n"+
-		"All Seeds:\n", seedInfo.PrimarySeed, seedInfo.AddressesRemaining)
-	for _, seed := range seedInfo.AllSeeds {
-		fmt.Println(seed)
-	}
+	walletCmd.cli.PrintOutput(seedInfo, func() {
+		fmt.Printf("Primary Seed: %s\n"+
+			"Addresses Remaining %d\n"+
+			"All Seeds:\n", seedInfo.PrimarySeed, seedInfo.AddressesRemaining)
+		for _, seed := range seedInfo.AllSeeds {
+			fmt.Println(seed)
+		}
+	})
 }
 
 // sendCoinsCmd sends siacoins to one or multiple destination addresses.
@@ -525,10 +1048,16 @@ func (walletCmd *walletCmd) sendCoinsCmd(cmd *cobra.Command, args []string) {
 		cmd.UsageFunc()(cmd)
 		cli.Die(err)
 	}
+	cc, err := walletCmd.sendCoinsCfg.CoinControl.CoinControl()
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.Die(err)
+	}
 
 	body := api.WalletCoinsPOST{
 		CoinOutputs: make([]types.CoinOutput, len(pairs)),
 		Data:        []byte(walletCmd.sendCoinsCfg.Data),
+		CoinControl: cc,
 	}
 	for i, pair := range pairs {
 		body.CoinOutputs[i] = types.CoinOutput{
@@ -536,6 +1065,8 @@ func (walletCmd *walletCmd) sendCoinsCmd(cmd *cobra.Command, args []string) {
 			Condition: pair.Condition,
 		}
 	}
+	body.CoinControl.MinerFee = walletCmd.minerFee(cmd, walletCmd.sendCoinsCfg.Fee,
+		estimateSentTransactionSize(*walletCmd.cli.Config, body.CoinOutputs, nil, body.Data, cc))
 
 	bytes, err := json.Marshal(&body)
 	if err != nil {
@@ -546,12 +1077,203 @@ func (walletCmd *walletCmd) sendCoinsCmd(cmd *cobra.Command, args []string) {
 	if err != nil {
 		cli.DieWithError("Could not send coins:", err)
 	}
-	fmt.Println("Succesfully sent coins as transaction " + resp.TransactionID.String())
-	for _, co := range body.CoinOutputs {
-		fmt.Printf("Sent %s to %s (using ConditionType %d)\n",
-			currencyConvertor.ToCoinStringWithUnit(co.Value), co.Condition.UnlockHash(),
-			co.Condition.ConditionType())
+	walletCmd.cli.PrintOutput(resp, func() {
+		fmt.Println("Succesfully sent coins as transaction " + resp.TransactionID.String())
+		for _, co := range body.CoinOutputs {
+			fmt.Printf("Sent %s to %s (using ConditionType %d)\n",
+				currencyConvertor.ToCoinStringWithUnit(co.Value), co.Condition.UnlockHash(),
+				co.Condition.ConditionType())
+		}
+	})
+}
+
+// coinControlFlags are the flags of the send commands,
+// defining the outputs to spend and the change address of the sent transaction.
+type coinControlFlags struct {
+	CoinInputs       []string
+	BlockStakeInputs []string
+	ExcludeLocked    bool
+	ChangeAddress    string
+}
+
+// CoinControl parses the flags into the coin control sent to the wallet.
+func (flags coinControlFlags) CoinControl() (modules.CoinControl, error) {
+	cc := modules.CoinControl{
+		ExcludeTimeLocked: flags.ExcludeLocked,
+	}
+	for _, str := range flags.CoinInputs {
+		var id types.CoinOutputID
+		err := id.LoadString(str)
+		if err != nil {
+			return modules.CoinControl{}, fmt.Errorf("invalid coin input %q: %v", str, err)
+		}
+		cc.CoinInputs = append(cc.CoinInputs, id)
+	}
+	for _, str := range flags.BlockStakeInputs {
+		var id types.BlockStakeOutputID
+		err := id.LoadString(str)
+		if err != nil {
+			return modules.CoinControl{}, fmt.Errorf("invalid blockstake input %q: %v", str, err)
+		}
+		cc.BlockStakeInputs = append(cc.BlockStakeInputs, id)
+	}
+	if flags.ChangeAddress != "" {
+		var uh types.UnlockHash
+		err := uh.LoadString(flags.ChangeAddress)
+		if err != nil {
+			return modules.CoinControl{}, cli.ErrorWithExitCode{
+				Err:  fmt.Errorf("invalid change address %q: %v", flags.ChangeAddress, err),
+				Code: cli.ExitCodeInvalidAddress,
+			}
+		}
+		cc.ChangeAddress = &uh
 	}
+	return cc, nil
+}
+
+// feeFlags are the flags of the send commands,
+// defining the miner fee paid by the sent transaction.
+type feeFlags struct {
+	Fee         string
+	FeePerByte  string
+	FeePriority string
+	Yes         bool
+}
+
+// the priorities supported by the --fee-priority flag
+var feePriorities = []string{"low", "normal", "high"}
+
+// minerFee returns the miner fee defined by the given fee flags, for a transaction of the given (estimated) size,
+// after asking the user to confirm it, unless the --yes flag is given.
+// It returns nil if no fee flag is given, in which case the wallet pays the minimum transaction fee.
+func (walletCmd *walletCmd) minerFee(cmd *cobra.Command, flags feeFlags, size int) *types.Currency {
+	given := 0
+	for _, flag := range []string{flags.Fee, flags.FeePerByte, flags.FeePriority} {
+		if flag != "" {
+			given++
+		}
+	}
+	switch given {
+	case 0:
+		return nil
+	case 1:
+	default:
+		cmd.UsageFunc()(cmd)
+		cli.DieWithExitCode(cli.ExitCodeUsage, "only one of the --fee, --fee-per-byte and --fee-priority flags can be given")
+	}
+
+	currencyConvertor := walletCmd.cli.CreateCurrencyConvertor()
+	minimum := walletCmd.cli.Config.MinimumTransactionFee
+	var (
+		fee        types.Currency
+		feePerByte types.Currency
+		err        error
+	)
+	switch {
+	case flags.Fee != "":
+		fee, err = currencyConvertor.ParseCoinString(flags.Fee)
+		if err != nil {
+			cmd.UsageFunc()(cmd)
+			cli.Die("invalid fee:", err)
+		}
+		if fee.Cmp(minimum) < 0 {
+			cli.DieWithExitCode(cli.ExitCodeUsage, "the fee cannot be lower than the minimum transaction fee of "+
+				currencyConvertor.ToCoinStringWithUnit(minimum))
+		}
+	case flags.FeePerByte != "":
+		feePerByte, err = currencyConvertor.ParseCoinString(flags.FeePerByte)
+		if err != nil {
+			cmd.UsageFunc()(cmd)
+			cli.Die("invalid fee per byte:", err)
+		}
+		fee = feeForSize(feePerByte, size, minimum)
+	default:
+		var estimate api.TransactionPoolGetFeeEstimate
+		err = walletCmd.cli.GetAPI("/transactionpool/feeestimate", &estimate)
+		if err != nil {
+			cli.DieWithError("Could not estimate the fee:", err)
+		}
+		feePerByte, err = feeEstimateForPriority(estimate.FeeEstimate, flags.FeePriority)
+		if err != nil {
+			cmd.UsageFunc()(cmd)
+			cli.DieWithExitCode(cli.ExitCodeUsage, err)
+		}
+		fee = feeForSize(feePerByte, size, minimum)
+	}
+
+	w := walletCmd.cli.InfoWriter()
+	if feePerByte.IsZero() {
+		fmt.Fprintf(w, "Miner fee: %s\n", currencyConvertor.ToCoinStringWithUnit(fee))
+	} else {
+		fmt.Fprintf(w, "Miner fee: %s (%s per byte, for an estimated transaction size of %d bytes)\n",
+			currencyConvertor.ToCoinStringWithUnit(fee), currencyConvertor.ToCoinStringWithUnit(feePerByte), size)
+	}
+	if !flags.Yes && !askYesNoQuestion("Send the transaction with this miner fee?") {
+		cli.DieWithExitCode(cli.ExitCodeCancelled, "cancelled the transaction")
+	}
+	return &fee
+}
+
+// feeEstimateForPriority returns the fee-per-byte of the given estimate for the given priority.
+func feeEstimateForPriority(estimate modules.FeeEstimate, priority string) (types.Currency, error) {
+	switch strings.ToLower(priority) {
+	case feePriorities[0]:
+		return estimate.Low, nil
+	case feePriorities[1]:
+		return estimate.Normal, nil
+	case feePriorities[2]:
+		return estimate.High, nil
+	default:
+		return types.Currency{}, fmt.Errorf("invalid fee priority %q, has to be one of: %s",
+			priority, strings.Join(feePriorities, ", "))
+	}
+}
+
+// feeForSize returns the fee of a transaction of the given size, paying the given fee-per-byte,
+// or the given minimum fee in case that is higher.
+func feeForSize(feePerByte types.Currency, size int, minimum types.Currency) types.Currency {
+	fee := feePerByte.Mul64(uint64(size))
+	if fee.Cmp(minimum) < 0 {
+		return minimum
+	}
+	return fee
+}
+
+// estimateSentTransactionSize estimates the binary-encoded size of the transaction the wallet creates
+// to send the given outputs, assuming it spends single signature outputs and refunds a change output.
+// It spends the given coin control inputs if defined, and a single output of each type that is sent otherwise.
+func estimateSentTransactionSize(config Config, coinOutputs []types.CoinOutput, blockStakeOutputs []types.BlockStakeOutput, data []byte, cc modules.CoinControl) int {
+	fulfillment := types.NewFulfillment(&types.SingleSignatureFulfillment{
+		PublicKey: types.Ed25519PublicKey(crypto.PublicKey{}),
+		Signature: make(types.ByteSlice, crypto.SignatureSize),
+	})
+	change := types.NewCondition(types.NewUnlockHashCondition(types.UnlockHash{Type: types.UnlockTypePubKey}))
+	txn := types.Transaction{
+		Version:       config.DefaultTransactionVersion,
+		CoinOutputs:   append(coinOutputs[:len(coinOutputs):len(coinOutputs)], types.CoinOutput{Condition: change}),
+		MinerFees:     []types.Currency{config.MinimumTransactionFee},
+		ArbitraryData: data,
+	}
+	// the miner fee is always paid using coins
+	inputs := len(cc.CoinInputs)
+	if inputs == 0 {
+		inputs = 1
+	}
+	for i := 0; i < inputs; i++ {
+		txn.CoinInputs = append(txn.CoinInputs, types.CoinInput{Fulfillment: fulfillment})
+	}
+	if len(blockStakeOutputs) > 0 {
+		txn.BlockStakeOutputs = append(blockStakeOutputs[:len(blockStakeOutputs):len(blockStakeOutputs)],
+			types.BlockStakeOutput{Condition: change})
+		inputs = len(cc.BlockStakeInputs)
+		if inputs == 0 {
+			inputs = 1
+		}
+		for i := 0; i < inputs; i++ {
+			txn.BlockStakeInputs = append(txn.BlockStakeInputs, types.BlockStakeInput{Fulfillment: fulfillment})
+		}
+	}
+	return len(siabin.Marshal(txn))
 }
 
 // sendBlockStakesCmd sends block stakes to one or multiple destination addresses.
@@ -561,10 +1283,16 @@ func (walletCmd *walletCmd) sendBlockStakesCmd(cmd *cobra.Command, args []string
 		cmd.UsageFunc()(cmd)
 		cli.Die(err)
 	}
+	cc, err := walletCmd.sendBlockStakesCfg.CoinControl.CoinControl()
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.Die(err)
+	}
 
 	body := api.WalletBlockStakesPOST{
 		BlockStakeOutputs: make([]types.BlockStakeOutput, len(pairs)),
 		Data:              []byte(walletCmd.sendBlockStakesCfg.Data),
+		CoinControl:       cc,
 	}
 	for i, pair := range pairs {
 		body.BlockStakeOutputs[i] = types.BlockStakeOutput{
@@ -572,6 +1300,8 @@ func (walletCmd *walletCmd) sendBlockStakesCmd(cmd *cobra.Command, args []string
 			Condition: pair.Condition,
 		}
 	}
+	body.CoinControl.MinerFee = walletCmd.minerFee(cmd, walletCmd.sendBlockStakesCfg.Fee,
+		estimateSentTransactionSize(*walletCmd.cli.Config, nil, body.BlockStakeOutputs, body.Data, cc))
 
 	bytes, err := json.Marshal(&body)
 	if err != nil {
@@ -582,11 +1312,13 @@ func (walletCmd *walletCmd) sendBlockStakesCmd(cmd *cobra.Command, args []string
 	if err != nil {
 		cli.DieWithError("Could not send block stakes:", err)
 	}
-	fmt.Println("Succesfully sent blockstakes as transaction " + resp.TransactionID.String())
-	for _, bo := range body.BlockStakeOutputs {
-		fmt.Printf("Sent %s BS to %s (using ConditionType %d)\n",
-			bo.Value, bo.Condition.UnlockHash(), bo.Condition.ConditionType())
-	}
+	walletCmd.cli.PrintOutput(resp, func() {
+		fmt.Println("Succesfully sent blockstakes as transaction " + resp.TransactionID.String())
+		for _, bo := range body.BlockStakeOutputs {
+			fmt.Printf("Sent %s BS to %s (using ConditionType %d)\n",
+				bo.Value, bo.Condition.UnlockHash(), bo.Condition.ConditionType())
+		}
+	})
 }
 
 type outputPair struct {
@@ -594,6 +1326,204 @@ type outputPair struct {
 	Value     types.Currency
 }
 
+// sendManyCmd sends coins to all addresses listed in a CSV file,
+// in batches of multiple payments per transaction, and prints a report of the result of each row.
+func (walletCmd *walletCmd) sendManyCmd() {
+	cfg := walletCmd.sendManyCfg
+	if cfg.File == "" {
+		cli.DieWithExitCode(cli.ExitCodeUsage, "no payments file given, define it using the --file flag")
+	}
+	if cfg.BatchSize < 1 {
+		cli.DieWithExitCode(cli.ExitCodeUsage, "the batch size has to be at least 1")
+	}
+	currencyConvertor := walletCmd.cli.CreateCurrencyConvertor()
+
+	file, err := os.Open(cfg.File)
+	if err != nil {
+		cli.Die("failed to open payments file:", err)
+	}
+	payments, err := parsePaymentsCSV(file, currencyConvertor.ParseCoinString)
+	file.Close()
+	if err != nil {
+		cli.Die("failed to read payments file:", err)
+	}
+	if len(payments) == 0 {
+		cli.DieWithExitCode(cli.ExitCodeUsage, "payments file does not list any payment")
+	}
+
+	output := WalletOutputSendMany{
+		Payments: make([]WalletOutputPayment, 0, len(payments)),
+	}
+	var invalidErr error
+	for _, payment := range payments {
+		op := WalletOutputPayment{
+			Row:     payment.Row,
+			Address: payment.Address,
+			Amount:  payment.Amount,
+			Label:   payment.Label,
+		}
+		if payment.Err != nil {
+			op.Error = payment.Err.Error()
+			if invalidErr == nil {
+				invalidErr = payment.Err
+			}
+		}
+		output.Payments = append(output.Payments, op)
+	}
+	if invalidErr != nil {
+		walletCmd.printSendManyReport(output)
+		cli.Die("invalid payments file, no coins were sent:", invalidErr)
+	}
+
+	// send the payments in batches, stopping as soon as one batch fails
+	var sendErr error
+	for start := 0; start < len(output.Payments); start += cfg.BatchSize {
+		end := start + cfg.BatchSize
+		if end > len(output.Payments) {
+			end = len(output.Payments)
+		}
+		batch := output.Payments[start:end]
+		if sendErr != nil {
+			for i := range batch {
+				batch[i].Error = "not sent, as a previous batch failed"
+			}
+			continue
+		}
+
+		body := api.WalletCoinsPOST{
+			CoinOutputs: make([]types.CoinOutput, 0, len(batch)),
+			Data:        []byte(cfg.Data),
+		}
+		for i := range batch {
+			body.CoinOutputs = append(body.CoinOutputs, types.CoinOutput{
+				Value:     batch[i].Amount,
+				Condition: types.NewCondition(types.NewUnlockHashCondition(payments[start+i].UnlockHash)),
+			})
+		}
+		b, err := json.Marshal(&body)
+		if err != nil {
+			cli.Die("Failed to JSON Marshal the input body:", err)
+		}
+		var resp api.WalletCoinsPOSTResp
+		err = walletCmd.cli.PostResp("/wallet/coins", string(b), &resp)
+		for i := range batch {
+			if err != nil {
+				batch[i].Error = err.Error()
+				continue
+			}
+			txnID := resp.TransactionID
+			batch[i].TransactionID = &txnID
+		}
+		if err != nil {
+			sendErr = err
+			continue
+		}
+		output.TransactionIDs = append(output.TransactionIDs, resp.TransactionID)
+	}
+
+	walletCmd.printSendManyReport(output)
+	if sendErr != nil {
+		cli.DieWithError("Could not send all payments:", sendErr)
+	}
+}
+
+// printSendManyReport prints the result of each payment of the wallet sendmany command.
+func (walletCmd *walletCmd) printSendManyReport(output WalletOutputSendMany) {
+	walletCmd.cli.PrintOutput(output, func() {
+		currencyConvertor := walletCmd.cli.CreateCurrencyConvertor()
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "Row\tAddress\tAmount\tLabel\tResult")
+		for _, payment := range output.Payments {
+			result := payment.Error
+			if payment.TransactionID != nil {
+				result = "sent in transaction " + payment.TransactionID.String()
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", payment.Row, payment.Address,
+				currencyConvertor.ToCoinStringWithUnit(payment.Amount), payment.Label, result)
+		}
+		w.Flush()
+		if len(output.TransactionIDs) > 0 {
+			fmt.Printf("Sent %d transaction(s)\n", len(output.TransactionIDs))
+		}
+	})
+}
+
+type (
+	// WalletOutputSendMany represents the formatted output
+	// of the wallet sendmany command.
+	WalletOutputSendMany struct {
+		Payments       []WalletOutputPayment `json:"payments"`
+		TransactionIDs []types.TransactionID `json:"transactionids"`
+	}
+	// WalletOutputPayment represents the result of a single payment (row)
+	// in the formatted output of the wallet sendmany command.
+	WalletOutputPayment struct {
+		Row           int                  `json:"row"`
+		Address       string               `json:"address"`
+		Amount        types.Currency       `json:"amount"`
+		Label         string               `json:"label,omitempty"`
+		TransactionID *types.TransactionID `json:"transactionid,omitempty"`
+		Error         string               `json:"error,omitempty"`
+	}
+)
+
+// payment is a single row of a payments CSV file,
+// as parsed by parsePaymentsCSV.
+type payment struct {
+	Row        int    // line number within the file
+	Address    string // address as given
+	UnlockHash types.UnlockHash
+	Amount     types.Currency
+	Label      string
+	Err        error // defined if the row is invalid
+}
+
+// parsePaymentsCSV parses all payments listed in a CSV file, one payment per row,
+// as <address>,<amount>[,<label>]. A first row with "address" as its first column is skipped as header.
+// Invalid rows are returned with their error defined, such that all rows can be validated at once.
+func parsePaymentsCSV(r io.Reader, parseCurrency parseCurrencyString) ([]payment, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var payments []payment
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return payments, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		if len(payments) == 0 && strings.EqualFold(strings.TrimSpace(record[0]), "address") {
+			continue // header
+		}
+
+		p := payment{Row: line, Address: strings.TrimSpace(record[0])}
+		if len(record) < 2 || len(record) > 3 {
+			p.Err = fmt.Errorf("row %d: expected 2 or 3 columns (<address>,<amount>[,<label>]), not %d", line, len(record))
+			payments = append(payments, p)
+			continue
+		}
+		if len(record) == 3 {
+			p.Label = strings.TrimSpace(record[2])
+		}
+		if err = p.UnlockHash.LoadString(p.Address); err != nil {
+			p.Err = cli.ErrorWithExitCode{
+				Err:  fmt.Errorf("row %d: invalid address %q: %v", line, p.Address, err),
+				Code: cli.ExitCodeInvalidAddress,
+			}
+		} else if p.Amount, err = parseCurrency(strings.TrimSpace(record[1])); err != nil {
+			p.Err = fmt.Errorf("row %d: invalid amount %q: %v", line, record[1], err)
+		} else if p.Amount.IsZero() {
+			p.Err = fmt.Errorf("row %d: amount has to be greater than zero", line)
+		}
+		payments = append(payments, p)
+	}
+}
+
 // parseCurrencyString takes the string representation of a currency value
 type parseCurrencyString func(string) (types.Currency, error)
 
@@ -635,7 +1565,10 @@ func parsePairedOutputs(args []string, parseCurrency parseCurrencyString) (pairs
 		// try to parse it as a JSON-encoded unlock condition
 		err = pair.Condition.UnmarshalJSON([]byte(args[i]))
 		if err != nil {
-			err = fmt.Errorf("condition has to be UnlockHash or JSON-encoded UnlockCondition, output #%d's was neither", i/2)
+			err = cli.ErrorWithExitCode{
+				Err:  fmt.Errorf("condition has to be UnlockHash or JSON-encoded UnlockCondition, output #%d's was neither", i/2),
+				Code: cli.ExitCodeInvalidAddress,
+			}
 			return
 		}
 		pairs = append(pairs, pair)
@@ -652,7 +1585,9 @@ func (walletCmd *walletCmd) registerDataCmd(namespace, dest, data string) {
 	if err != nil {
 		cli.DieWithError("Could not register data:", err)
 	}
-	fmt.Printf("Registered data to %s\n", dest)
+	walletCmd.cli.PrintOutput(EmptyOutput{}, func() {
+		fmt.Printf("Registered data to %s\n", dest)
+	})
 }
 
 // blockStakesStatsCmd gives all statistical info of blockstake
@@ -664,6 +1599,10 @@ func (walletCmd *walletCmd) blockStakesStatsCmd() {
 	if err != nil {
 		cli.DieWithError("Could not gen blockstake info:", err)
 	}
+	if walletCmd.cli.JSONOutput {
+		printJSON(bsstat)
+		return
+	}
 	fmt.Printf("BlockStake stats:\n")
 	fmt.Printf("Total active Blockstake is %v\n", bsstat.TotalActiveBlockStake)
 	fmt.Printf("This account has %v Blockstake\n", bsstat.TotalBlockStake)
@@ -694,6 +1633,10 @@ func (walletCmd *walletCmd) balanceCmd() {
 	if err != nil {
 		cli.DieWithError("Could not get wallet status:", err)
 	}
+	if walletCmd.cli.JSONOutput {
+		printJSON(status)
+		return
+	}
 	encStatus := "Unencrypted"
 	if status.Encrypted {
 		encStatus = "Encrypted"
@@ -789,6 +1732,10 @@ func (walletCmd *walletCmd) listTransactionsCmd() {
 	if err != nil {
 		cli.DieWithError("Could not fetch transaction history:", err)
 	}
+	if walletCmd.cli.JSONOutput {
+		printJSON(wtg)
+		return
+	}
 
 	multiSigWalletTxns := make(map[types.UnlockHash][]modules.ProcessedTransaction)
 	txns := append(wtg.ConfirmedTransactions, wtg.UnconfirmedTransactions...)
@@ -823,7 +1770,7 @@ func (walletCmd *walletCmd) listTransactionsCmd() {
 		var incomingSiacoins types.Currency
 		var incomingBlockStakes types.Currency
 		for _, output := range txn.Outputs {
-			if output.FundType == types.SpecifierMinerPayout {
+			if output.FundType == types.SpecifierMinerPayout && output.WalletAddress {
 				rootWalletOwned = true
 				incomingSiacoins = incomingSiacoins.Add(output.Value)
 			}
@@ -927,28 +1874,36 @@ func (walletCmd *walletCmd) listTransactionsCmd() {
 
 // unlockCmd unlocks a saved wallet
 func (walletCmd *walletCmd) unlockCmd() {
-	password, err := speakeasy.Ask("Wallet password: ")
+	password, err := speakeasy.FAsk(walletCmd.cli.InfoWriter(), "Wallet password: ")
 	if err != nil {
 		cli.Die("Reading password failed:", err)
 	}
-	fmt.Println("Unlocking the wallet. This may take several minutes...")
+	fmt.Fprintln(walletCmd.cli.InfoWriter(), "Unlocking the wallet. This may take several minutes...")
 	qs := fmt.Sprintf("passphrase=%s", password)
 	err = walletCmd.cli.Post("/wallet/unlock", qs)
 	if err != nil {
 		cli.DieWithError("Could not unlock wallet:", err)
 	}
-	fmt.Println("Wallet unlocked")
+	walletCmd.cli.PrintOutput(EmptyOutput{}, func() {
+		fmt.Println("Wallet unlocked")
+	})
 }
 
 // sendTxCmd sends commits a transaction in json format
 // to the transaction pool
-func (walletCmd *walletCmd) sendTxCmd(txnjson string) {
+func (walletCmd *walletCmd) sendTxCmd(txnarg string) {
+	txnjson, _, err := loadTransactionArg(txnarg)
+	if err != nil {
+		cli.Die("Could not load transaction:", err)
+	}
 	var resp api.TransactionPoolPOST
-	err := walletCmd.cli.PostResp("/transactionpool/transactions", txnjson, &resp)
+	err = walletCmd.cli.PostResp("/transactionpool/transactions", txnjson, &resp)
 	if err != nil {
 		cli.DieWithError("Could not publish transaction:", err)
 	}
-	fmt.Println("Transaction published, transaction id:", resp.TransactionID)
+	walletCmd.cli.PrintOutput(resp, func() {
+		fmt.Println("Transaction published, transaction id:", resp.TransactionID)
+	})
 }
 
 func (walletCmd *walletCmd) listUnlockedCmd(_ *cobra.Command, args []string) {
@@ -962,7 +1917,7 @@ func (walletCmd *walletCmd) listUnlockedCmd(_ *cobra.Command, args []string) {
 	if addressGiven {
 		err = address.LoadString(args[0])
 		if err != nil {
-			cli.Die("failed to parse given wallet address: ", err)
+			cli.DieWithExitCode(cli.ExitCodeInvalidAddress, "failed to parse given wallet address: ", err)
 		}
 	}
 
@@ -994,6 +1949,10 @@ func (walletCmd *walletCmd) listUnlockedCmd(_ *cobra.Command, args []string) {
 		}
 	}
 
+	if walletCmd.cli.JSONOutput {
+		printJSON(resp)
+		return
+	}
 	if len(resp.UnlockedBlockstakeOutputs) == 0 && len(resp.UnlockedCoinOutputs) == 0 {
 		if addressGiven {
 			fmt.Println("No unlocked outputs matched to address: " + address.String())
@@ -1039,7 +1998,7 @@ func (walletCmd *walletCmd) listLockedCmd(_ *cobra.Command, args []string) {
 	if addressGiven {
 		err = address.LoadString(args[0])
 		if err != nil {
-			cli.Die("failed to parse given wallet address: ", err)
+			cli.DieWithExitCode(cli.ExitCodeInvalidAddress, "failed to parse given wallet address: ", err)
 		}
 	}
 
@@ -1071,6 +2030,10 @@ func (walletCmd *walletCmd) listLockedCmd(_ *cobra.Command, args []string) {
 		}
 	}
 
+	if walletCmd.cli.JSONOutput {
+		printJSON(resp)
+		return
+	}
 	if len(resp.LockedBlockstakeOutputs) == 0 && len(resp.LockedCoinOutputs) == 0 {
 		if addressGiven {
 			fmt.Println("No locked outputs matched to address: " + address.String())
@@ -1120,13 +2083,15 @@ func (walletCmd *walletCmd) createMultisigAddressesCmd(cmd *cobra.Command, args
 	for _, addr := range args[1:] {
 		err = uh.LoadString(addr)
 		if err != nil {
-			cli.Die("Failed to load unlock hash:", err)
+			cli.DieWithExitCode(cli.ExitCodeInvalidAddress, "Failed to load unlock hash:", err)
 		}
 		uhs = append(uhs, uh)
 	}
 
 	multiSigCond := types.NewMultiSignatureCondition(uhs, msr)
-	fmt.Println("Multisig address:", multiSigCond.UnlockHash())
+	walletCmd.cli.PrintOutput(api.WalletAddressGET{Address: multiSigCond.UnlockHash()}, func() {
+		fmt.Println("Multisig address:", multiSigCond.UnlockHash())
+	})
 }
 
 func (walletCmd *walletCmd) createCoinTxCmd(cmd *cobra.Command, args []string) {
@@ -1219,12 +2184,557 @@ func (walletCmd *walletCmd) createBlockStakeTxCmd(cmd *cobra.Command, args []str
 	json.NewEncoder(os.Stdout).Encode(resp.Transaction)
 }
 
-func (walletCmd *walletCmd) signTxCmd(txnjson string) {
+func (walletCmd *walletCmd) signTxCmd(txnarg string) {
+	txnjson, path, err := loadTransactionArg(txnarg)
+	if err != nil {
+		cli.Die("Could not load transaction:", err)
+	}
 	var txn types.Transaction
-	err := walletCmd.cli.PostResp("/wallet/sign", txnjson, &txn)
+	err = walletCmd.cli.PostResp("/wallet/sign", txnjson, &txn)
 	if err != nil {
 		cli.DieWithError("Failed to sign transaction:", err)
 	}
 
-	json.NewEncoder(os.Stdout).Encode(txn)
+	if path == "" {
+		json.NewEncoder(os.Stdout).Encode(txn)
+		return
+	}
+	b, err := json.MarshalIndent(txn, "", "  ")
+	if err != nil {
+		cli.Die("Could not encode signed transaction:", err)
+	}
+	err = ioutil.WriteFile(path, append(b, '\n'), 0600)
+	if err != nil {
+		cli.Die("Could not write signed transaction:", err)
+	}
+	walletCmd.cli.PrintOutput(FileOutput{Path: path}, func() {
+		fmt.Println("Signed transaction written to", path)
+	})
+}
+
+// loadTransactionArg returns the JSON-encoded transaction given as argument, which is either
+// the JSON-encoded transaction itself, or the path to a file containing it,
+// in which case the path is returned as well.
+func loadTransactionArg(arg string) (txnjson, path string, err error) {
+	if strings.HasPrefix(strings.TrimSpace(arg), "{") {
+		return arg, "", nil
+	}
+	b, err := ioutil.ReadFile(arg)
+	if err != nil {
+		return "", "", err
+	}
+	return string(b), arg, nil
 }
+
+func (walletCmd *walletCmd) listMultisigCmd(_ *cobra.Command, args []string) {
+	var (
+		err          error
+		address      types.UnlockHash
+		addressGiven = len(args) == 1
+	)
+	if addressGiven {
+		err = address.LoadString(args[0])
+		if err != nil {
+			cli.DieWithExitCode(cli.ExitCodeInvalidAddress, "failed to parse given multisig address: ", err)
+		}
+	}
+
+	status := new(api.WalletGET)
+	err = walletCmd.cli.GetAPI("/wallet", status)
+	if err != nil {
+		cli.DieWithError("Could not get wallet status:", err)
+	}
+	if !status.Unlocked {
+		cli.DieWithExitCode(cli.ExitCodeUsage, "Unlock the wallet to list its multisig wallets")
+	}
+
+	wallets := make([]modules.MultiSigWallet, 0, len(status.MultiSigWallets))
+	for _, wallet := range status.MultiSigWallets {
+		if addressGiven && wallet.Address.Cmp(address) != 0 {
+			continue
+		}
+		wallets = append(wallets, wallet)
+	}
+	if addressGiven && len(wallets) == 0 {
+		cli.Die("This wallet is not a signatory of multisig wallet", address)
+	}
+	if walletCmd.cli.JSONOutput {
+		printJSON(WalletOutputMultisig{MultiSigWallets: wallets})
+		return
+	}
+
+	if len(wallets) == 0 {
+		fmt.Println("This wallet is not a signatory of any multisig wallet")
+		return
+	}
+	currencyConvertor := walletCmd.cli.CreateCurrencyConvertor()
+	for idx, wallet := range wallets {
+		if idx > 0 {
+			fmt.Println()
+		}
+
+		fmt.Println("Address:", wallet.Address)
+		fmt.Printf("Signatures required: %d of %d\n", wallet.MinSigs, len(wallet.Owners))
+		fmt.Println("Possible signatories:")
+		for _, uh := range wallet.Owners {
+			fmt.Println("  " + uh.String())
+		}
+		if len(wallet.CoinOutputIDs) > 0 {
+			fmt.Println("Coin outputs:", currencyConvertor.ToCoinStringWithUnit(wallet.ConfirmedCoinBalance))
+			for _, id := range wallet.CoinOutputIDs {
+				fmt.Println("  " + id.String())
+			}
+		}
+		if len(wallet.BlockStakeOutputIDs) > 0 {
+			fmt.Println("BlockStake outputs:", wallet.ConfirmedBlockStakeBalance, "BS")
+			for _, id := range wallet.BlockStakeOutputIDs {
+				fmt.Println("  " + id.String())
+			}
+		}
+	}
+}
+
+// signMessageCmd is the handler for the command `rivinec wallet sign-message <address> [<message>]`.
+// Signs the given message, or the message read from stdin, using the key pair of the given address.
+func (walletCmd *walletCmd) signMessageCmd(cmd *cobra.Command, args []string) {
+	var address types.UnlockHash
+	err := address.LoadString(args[0])
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.DieWithExitCode(cli.ExitCodeInvalidAddress, "invalid address:", err)
+	}
+	if address.Type != types.UnlockTypePubKey {
+		cli.DieWithExitCode(cli.ExitCodeInvalidAddress, "only single signature addresses can sign a message")
+	}
+	message := messageArg(args, 1)
+
+	body, err := json.Marshal(api.WalletSignMessagePOST{
+		Address: address,
+		Message: message,
+	})
+	if err != nil {
+		cli.Die("Failed to JSON Marshal the input body:", err)
+	}
+	var resp api.WalletSignMessagePOSTResp
+	err = walletCmd.cli.PostResp("/wallet/signmessage", string(body), &resp)
+	if err != nil {
+		cli.DieWithError("Could not sign message:", err)
+	}
+	output := WalletOutputMessageSignature{
+		Address:   address,
+		Signature: resp.Signature,
+	}
+	walletCmd.cli.PrintOutput(output, func() {
+		fmt.Println(output.Signature)
+	})
+}
+
+// verifyMessageCmd is the handler for the command `rivinec wallet verify-message <address> <signature> [<message>]`.
+// Verifies the given signature of the given message, or the message read from stdin, without connecting to the daemon.
+func (walletCmd *walletCmd) verifyMessageCmd(cmd *cobra.Command, args []string) {
+	var address types.UnlockHash
+	err := address.LoadString(args[0])
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.DieWithExitCode(cli.ExitCodeInvalidAddress, "invalid address:", err)
+	}
+	var signature types.MessageSignature
+	err = signature.LoadString(args[1])
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.DieWithExitCode(cli.ExitCodeUsage, err)
+	}
+	message := messageArg(args, 2)
+
+	err = signature.Verify(address, []byte(message))
+	if err != nil {
+		cli.Die("Message signature is not valid:", err)
+	}
+	output := WalletOutputMessageSignature{
+		Address:   address,
+		Signature: signature,
+	}
+	walletCmd.cli.PrintOutput(output, func() {
+		fmt.Println("Valid message signature of", address)
+	})
+}
+
+// messageArg returns the message given as the argument with the given index,
+// or reads it from stdin if that argument is not given.
+func messageArg(args []string, index int) string {
+	if len(args) > index {
+		return args[index]
+	}
+	b, err := ioutil.ReadAll(os.Stdin)
+	if err != nil {
+		cli.Die("failed to read message from stdin:", err)
+	}
+	return string(b)
+}
+
+// WalletOutputMessageSignature represents the formatted output
+// of the wallet sign-message and verify-message commands.
+type WalletOutputMessageSignature struct {
+	Address   types.UnlockHash       `json:"address"`
+	Signature types.MessageSignature `json:"signature"`
+}
+
+// WalletOutputMultisig represents the formatted output
+// of the wallet list multisig command.
+type WalletOutputMultisig struct {
+	MultiSigWallets []modules.MultiSigWallet `json:"multisigwallets"`
+}
+
+// coldCreateCmd is the handler for the command `rivinec wallet cold create`.
+// Creates a funded unsigned transaction, using the unspent coin outputs of the given address,
+// and writes it to the given file, such that it can be signed by an offline wallet.
+func (walletCmd *walletCmd) coldCreateCmd(cmd *cobra.Command, args []string) {
+	currencyConvertor := walletCmd.cli.CreateCurrencyConvertor()
+
+	path := args[0]
+	var address types.UnlockHash
+	err := address.LoadString(args[1])
+	if err != nil {
+		cli.DieWithExitCode(cli.ExitCodeInvalidAddress, "failed to parse given address:", err)
+	}
+	pairs, err := parsePairedOutputs(args[2:], currencyConvertor.ParseCoinString)
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.Die(err)
+	}
+
+	txn := types.Transaction{
+		Version:   walletCmd.cli.Config.DefaultTransactionVersion,
+		MinerFees: []types.Currency{walletCmd.cli.Config.MinimumTransactionFee},
+	}
+	amount := walletCmd.cli.Config.MinimumTransactionFee
+	for _, pair := range pairs {
+		txn.CoinOutputs = append(txn.CoinOutputs, types.CoinOutput{Value: pair.Value, Condition: pair.Condition})
+		amount = amount.Add(pair.Value)
+	}
+
+	var cs api.ConsensusGET
+	err = walletCmd.cli.GetAPI("/consensus", &cs)
+	if err != nil {
+		cli.DieWithError("failed to get the consensus state:", err)
+	}
+	var unspent api.ConsensusGetUnspentOutputs
+	err = walletCmd.cli.GetAPI("/consensus/unspent/unlockhashes/"+address.String(), &unspent)
+	if err != nil {
+		cli.DieWithError("failed to get the unspent outputs of the given address:", err)
+	}
+	inputs, total, err := selectColdCoinInputs(unspent.CoinOutputs, amount, types.FulfillableContext{
+		BlockHeight: cs.Height,
+		BlockTime:   types.CurrentTimestamp(),
+	})
+	if err != nil {
+		cli.Die(err)
+	}
+
+	utxn := modules.UnsignedTransaction{Transaction: txn}
+	for _, input := range inputs {
+		utxn.Transaction.CoinInputs = append(utxn.Transaction.CoinInputs, types.CoinInput{ParentID: input.ID})
+		utxn.CoinInputOutputs = append(utxn.CoinInputOutputs, input.Output)
+	}
+	if total.Cmp(amount) > 0 {
+		utxn.Transaction.CoinOutputs = append(utxn.Transaction.CoinOutputs, types.CoinOutput{
+			Value:     total.Sub(amount),
+			Condition: coldRefundCondition(inputs[0].Output.Condition),
+		})
+	}
+
+	b, err := json.MarshalIndent(utxn, "", "  ")
+	if err != nil {
+		cli.Die("failed to encode unsigned transaction:", err)
+	}
+	err = ioutil.WriteFile(path, b, 0600)
+	if err != nil {
+		cli.Die("failed to write unsigned transaction:", err)
+	}
+	walletCmd.cli.PrintOutput(FileOutput{Path: path}, func() {
+		fmt.Println("Unsigned transaction written to", path)
+	})
+}
+
+// coldSignCmd is the handler for the command `rivinec wallet cold sign`.
+// Signs an unsigned transaction file, and writes the signed transaction back into that file.
+func (walletCmd *walletCmd) coldSignCmd(path string) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		cli.Die("failed to read unsigned transaction:", err)
+	}
+	var txn types.Transaction
+	err = walletCmd.cli.PostResp("/wallet/coldsign", string(b), &txn)
+	if err != nil {
+		cli.DieWithError("failed to sign transaction:", err)
+	}
+	b, err = json.MarshalIndent(txn, "", "  ")
+	if err != nil {
+		cli.Die("failed to encode signed transaction:", err)
+	}
+	err = ioutil.WriteFile(path, b, 0600)
+	if err != nil {
+		cli.Die("failed to write signed transaction:", err)
+	}
+	walletCmd.cli.PrintOutput(FileOutput{Path: path}, func() {
+		fmt.Println("Signed transaction written to", path)
+	})
+}
+
+// coldSendCmd is the handler for the command `rivinec wallet cold send`.
+// Publishes a signed transaction file.
+func (walletCmd *walletCmd) coldSendCmd(path string) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		cli.Die("failed to read signed transaction:", err)
+	}
+	var utxn struct {
+		Transaction *json.RawMessage `json:"transaction"`
+	}
+	if json.Unmarshal(b, &utxn) == nil && utxn.Transaction != nil {
+		cli.Die("transaction file is not yet signed, sign it first using `wallet cold sign`")
+	}
+	walletCmd.sendTxCmd(path)
+}
+
+// coldGenerateCmd is the handler for the command `rivinec wallet cold generate`.
+// Generates a new seed and derives its first addresses, without connecting to the daemon.
+func (walletCmd *walletCmd) coldGenerateCmd(_ *cobra.Command, args []string) {
+	n := uint64(1)
+	if len(args) > 0 {
+		var err error
+		n, err = strconv.ParseUint(args[0], 10, 64)
+		if err != nil || n == 0 {
+			cli.DieWithExitCode(cli.ExitCodeUsage, "invalid amount of addresses:", args[0])
+		}
+	}
+
+	var seed modules.Seed
+	_, err := rand.Read(seed[:])
+	if err != nil {
+		cli.Die("failed to generate seed:", err)
+	}
+	mnemonic, err := modules.NewMnemonic(seed)
+	if err != nil {
+		cli.Die("failed to convert seed to mnemonic:", err)
+	}
+	output := WalletOutputGeneratedSeed{
+		Seed:      mnemonic,
+		Addresses: seedAddresses(seed, n),
+	}
+	walletCmd.cli.PrintOutput(output, func() {
+		fmt.Fprintln(walletCmd.cli.InfoWriter(),
+			"Write down the seed and keep it secret, anyone who knows it can spend the coins of its addresses.")
+		fmt.Println()
+		fmt.Println("Seed:")
+		fmt.Println(output.Seed)
+		fmt.Println()
+		fmt.Println("Addresses:")
+		for _, address := range output.Addresses {
+			fmt.Println(address)
+		}
+	})
+}
+
+// WalletOutputGeneratedSeed represents the formatted output
+// of the wallet cold generate command.
+type WalletOutputGeneratedSeed struct {
+	Seed      string             `json:"seed"`
+	Addresses []types.UnlockHash `json:"addresses"`
+}
+
+// seedAddresses derives the first n addresses of the given seed,
+// in the same way (and order) as the wallet module derives the addresses of its primary seed.
+func seedAddresses(seed modules.Seed, n uint64) []types.UnlockHash {
+	addresses := make([]types.UnlockHash, 0, n)
+	for index := uint64(0); index < n; index++ {
+		_, pk := crypto.GenerateKeyPairDeterministic(crypto.HashAll(seed, index))
+		addresses = append(addresses, types.NewEd25519PubKeyUnlockHash(pk))
+	}
+	return addresses
+}
+
+// selectColdCoinInputs selects the largest fulfillable coin outputs,
+// until the given amount is reached, returning the selected outputs and their total value.
+func selectColdCoinInputs(outputs []modules.UnspentCoinOutput, amount types.Currency, ctx types.FulfillableContext) ([]modules.UnspentCoinOutput, types.Currency, error) {
+	candidates := make([]modules.UnspentCoinOutput, 0, len(outputs))
+	for _, output := range outputs {
+		if output.Output.Condition.Fulfillable(ctx) {
+			candidates = append(candidates, output)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Output.Value.Cmp(candidates[j].Output.Value) > 0
+	})
+
+	var selected []modules.UnspentCoinOutput
+	total := types.ZeroCurrency
+	for _, output := range candidates {
+		if total.Cmp(amount) >= 0 {
+			break
+		}
+		selected = append(selected, output)
+		total = total.Add(output.Output.Value)
+	}
+	if total.Cmp(amount) < 0 {
+		return nil, total, cli.ErrorWithExitCode{
+			Err:  errors.New("insufficient unlocked coins available to fund the transaction"),
+			Code: cli.ExitCodeInsufficientFunds,
+		}
+	}
+	return selected, total, nil
+}
+
+// coldRefundCondition returns the condition used to refund the remainder
+// of the spent coin outputs, being the condition of a spent coin output,
+// without the time lock it might have.
+func coldRefundCondition(condition types.UnlockConditionProxy) types.UnlockConditionProxy {
+	if tl, ok := condition.Condition.(*types.TimeLockCondition); ok {
+		return types.NewCondition(tl.Condition)
+	}
+	return condition
+}
+
+// watchAddCmd is the handler for the command `rivinec wallet watch add`.
+// Adds addresses to be watched by the wallet.
+func (walletCmd *walletCmd) watchAddCmd(cmd *cobra.Command, args []string) {
+	walletCmd.postWatchedAddresses(cmd, "/wallet/watch/add", args)
+	walletCmd.cli.PrintOutput(EmptyOutput{}, func() {
+		fmt.Println("Watching", len(args), "address(es)")
+	})
+}
+
+// watchRemoveCmd is the handler for the command `rivinec wallet watch remove`.
+// Removes addresses from the addresses watched by the wallet.
+func (walletCmd *walletCmd) watchRemoveCmd(cmd *cobra.Command, args []string) {
+	walletCmd.postWatchedAddresses(cmd, "/wallet/watch/remove", args)
+	walletCmd.cli.PrintOutput(EmptyOutput{}, func() {
+		fmt.Println("Stopped watching", len(args), "address(es)")
+	})
+}
+
+func (walletCmd *walletCmd) postWatchedAddresses(cmd *cobra.Command, call string, args []string) {
+	var body api.WalletWatchPOST
+	for _, arg := range args {
+		var uh types.UnlockHash
+		err := uh.LoadString(arg)
+		if err != nil {
+			cmd.UsageFunc()(cmd)
+			cli.DieWithExitCode(cli.ExitCodeInvalidAddress, fmt.Sprintf("invalid address %q: %v", arg, err))
+		}
+		body.Addresses = append(body.Addresses, uh)
+	}
+	b, err := json.Marshal(body)
+	if err != nil {
+		cli.Die("Failed to JSON Marshal the input body:", err)
+	}
+	err = walletCmd.cli.Post(call, string(b))
+	if err != nil {
+		cli.DieWithError("Could not update the watched addresses:", err)
+	}
+}
+
+// watchListCmd is the handler for the command `rivinec wallet watch list`.
+// Lists all addresses watched by the wallet, with their balances and optionally their transactions.
+func (walletCmd *walletCmd) watchListCmd() {
+	var resp api.WalletWatchGET
+	err := walletCmd.cli.GetAPI("/wallet/watch", &resp)
+	if err != nil {
+		cli.DieWithError("Could not get the watched addresses:", err)
+	}
+	output := WalletOutputWatch{
+		Addresses: make([]WalletOutputWatchedAddress, 0, len(resp.Addresses)),
+	}
+	for _, addr := range resp.Addresses {
+		wa := WalletOutputWatchedAddress{WatchedAddress: addr}
+		if walletCmd.watchListCfg.Transactions {
+			var txns api.WalletTransactionsGETaddr
+			err = walletCmd.cli.GetAPI("/wallet/transactions/"+addr.Address.String(), &txns)
+			if err != nil {
+				cli.DieWithError("Could not get the transactions of watched address "+addr.Address.String()+":", err)
+			}
+			wa.Transactions = append(txns.ConfirmedTransactions, txns.UnconfirmedTransactions...)
+		}
+		output.Addresses = append(output.Addresses, wa)
+	}
+	walletCmd.cli.PrintOutput(output, func() {
+		if len(output.Addresses) == 0 {
+			fmt.Println("This wallet is not watching any address.")
+			return
+		}
+		currencyConvertor := walletCmd.cli.CreateCurrencyConvertor()
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "Address\tCoins\tBlock Stakes")
+		for _, addr := range output.Addresses {
+			fmt.Fprintf(w, "%v\t%s\t%v BS\n", addr.Address,
+				currencyConvertor.ToCoinStringWithUnit(addr.ConfirmedCoinBalance), addr.ConfirmedBlockStakeBalance)
+		}
+		w.Flush()
+		if !walletCmd.watchListCfg.Transactions {
+			return
+		}
+		for _, addr := range output.Addresses {
+			fmt.Println()
+			fmt.Printf("Transactions of %v:\n", addr.Address)
+			if len(addr.Transactions) == 0 {
+				fmt.Println("  none")
+				continue
+			}
+			w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "  Height\tTransaction\tCoins In\tCoins Out\tBlock Stakes In\tBlock Stakes Out")
+			for _, txn := range addr.Transactions {
+				coinsIn, coinsOut, blockStakesIn, blockStakesOut := addressFlow(txn, addr.Address)
+				height := "unconfirmed"
+				if txn.ConfirmationHeight < 1e9 {
+					height = strconv.FormatUint(uint64(txn.ConfirmationHeight-1), 10)
+				}
+				fmt.Fprintf(w, "  %s\t%v\t%s\t%s\t%v BS\t%v BS\n", height, txn.TransactionID,
+					currencyConvertor.ToCoinStringWithUnit(coinsIn), currencyConvertor.ToCoinStringWithUnit(coinsOut),
+					blockStakesIn, blockStakesOut)
+			}
+			w.Flush()
+		}
+	})
+}
+
+// addressFlow returns the coins and block stakes received and sent by the given address,
+// within the given processed transaction.
+func addressFlow(txn modules.ProcessedTransaction, uh types.UnlockHash) (coinsIn, coinsOut, blockStakesIn, blockStakesOut types.Currency) {
+	for _, input := range txn.Inputs {
+		if input.RelatedAddress.Cmp(uh) != 0 {
+			continue
+		}
+		switch input.FundType {
+		case types.SpecifierCoinInput:
+			coinsOut = coinsOut.Add(input.Value)
+		case types.SpecifierBlockStakeInput:
+			blockStakesOut = blockStakesOut.Add(input.Value)
+		}
+	}
+	for _, output := range txn.Outputs {
+		if output.RelatedAddress.Cmp(uh) != 0 {
+			continue
+		}
+		switch output.FundType {
+		case types.SpecifierCoinOutput, types.SpecifierMinerPayout:
+			coinsIn = coinsIn.Add(output.Value)
+		case types.SpecifierBlockStakeOutput:
+			blockStakesIn = blockStakesIn.Add(output.Value)
+		}
+	}
+	return
+}
+
+type (
+	// WalletOutputWatch represents the formatted output
+	// of the wallet watch list command.
+	WalletOutputWatch struct {
+		Addresses []WalletOutputWatchedAddress `json:"addresses"`
+	}
+	// WalletOutputWatchedAddress represents a single watched address
+	// in the formatted output of the wallet watch list command.
+	WalletOutputWatchedAddress struct {
+		modules.WatchedAddress
+		Transactions []modules.ProcessedTransaction `json:"transactions,omitempty"`
+	}
+)
@@ -3,9 +3,14 @@ package client
 import (
 	"bytes"
 	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"strings"
 	"testing"
 
 	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/pkg/cli"
 	"github.com/threefoldtech/rivine/types"
 )
 
@@ -188,3 +193,327 @@ func createDefaultCurrencyConvertor() CurrencyConvertor {
 	bchainInfo := types.DefaultBlockchainInfo()
 	return NewCurrencyConvertor(types.DefaultCurrencyUnits(), bchainInfo.CoinUnit)
 }
+
+func TestLoadTransactionArg(t *testing.T) {
+	const txnjson = `{"version":1,"data":{}}`
+
+	// JSON is returned as-is
+	str, path, err := loadTransactionArg(" " + txnjson)
+	if err != nil || str != " "+txnjson || path != "" {
+		t.Fatalf("unexpected result for JSON argument: %q, %q, %v", str, path, err)
+	}
+
+	// files are read
+	f, err := ioutil.TempFile("", "txn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(txnjson)
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	str, path, err = loadTransactionArg(f.Name())
+	if err != nil || str != txnjson || path != f.Name() {
+		t.Fatalf("unexpected result for file argument: %q, %q, %v", str, path, err)
+	}
+
+	if _, _, err = loadTransactionArg(f.Name() + ".missing"); err == nil {
+		t.Fatal("expected an error for a missing file")
+	}
+}
+
+func TestSelectColdCoinInputs(t *testing.T) {
+	uhCondition := types.NewCondition(types.NewUnlockHashCondition(types.UnlockHash{Type: types.UnlockTypePubKey}))
+	lockedCondition := types.NewCondition(types.NewTimeLockCondition(100, uhCondition.Condition))
+	outputs := []modules.UnspentCoinOutput{
+		{ID: types.CoinOutputID{1}, Output: types.CoinOutput{Value: types.NewCurrency64(10), Condition: uhCondition}},
+		{ID: types.CoinOutputID{2}, Output: types.CoinOutput{Value: types.NewCurrency64(50), Condition: lockedCondition}},
+		{ID: types.CoinOutputID{3}, Output: types.CoinOutput{Value: types.NewCurrency64(30), Condition: uhCondition}},
+		{ID: types.CoinOutputID{4}, Output: types.CoinOutput{Value: types.NewCurrency64(20), Condition: uhCondition}},
+	}
+
+	// the largest unlocked outputs are selected first
+	selected, total, err := selectColdCoinInputs(outputs, types.NewCurrency64(40), types.FulfillableContext{BlockHeight: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(selected) != 2 || selected[0].ID != (types.CoinOutputID{3}) || selected[1].ID != (types.CoinOutputID{4}) {
+		t.Fatal("unexpected selected outputs:", selected)
+	}
+	if !total.Equals(types.NewCurrency64(50)) {
+		t.Fatal("unexpected total:", total)
+	}
+
+	// time locked outputs can only be selected once unlocked
+	_, _, err = selectColdCoinInputs(outputs, types.NewCurrency64(70), types.FulfillableContext{BlockHeight: 1})
+	if err == nil {
+		t.Fatal("expected an error for insufficient unlocked coins")
+	}
+	selected, _, err = selectColdCoinInputs(outputs, types.NewCurrency64(70), types.FulfillableContext{BlockHeight: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(selected) != 2 || selected[0].ID != (types.CoinOutputID{2}) {
+		t.Fatal("unexpected selected outputs:", selected)
+	}
+
+	// the remainder is refunded without time lock
+	if refund := coldRefundCondition(lockedCondition); refund.ConditionType() != types.ConditionTypeUnlockHash {
+		t.Fatal("unexpected refund condition type:", refund.ConditionType())
+	}
+}
+
+func TestCoinControlFlags(t *testing.T) {
+	cc, err := coinControlFlags{}.CoinControl()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cc.CoinInputs) != 0 || len(cc.BlockStakeInputs) != 0 || cc.ExcludeTimeLocked || cc.ChangeAddress != nil {
+		t.Fatal("expected the zero coin control for undefined flags:", cc)
+	}
+
+	address := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: crypto.Hash{1, 2, 3}}.String()
+	id := "1234567890abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	cc, err = coinControlFlags{
+		CoinInputs:       []string{id},
+		BlockStakeInputs: []string{id, id},
+		ExcludeLocked:    true,
+		ChangeAddress:    address,
+	}.CoinControl()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cc.CoinInputs) != 1 || cc.CoinInputs[0].String() != id {
+		t.Fatal("unexpected coin inputs:", cc.CoinInputs)
+	}
+	if len(cc.BlockStakeInputs) != 2 || cc.BlockStakeInputs[1].String() != id {
+		t.Fatal("unexpected blockstake inputs:", cc.BlockStakeInputs)
+	}
+	if !cc.ExcludeTimeLocked {
+		t.Fatal("expected time locked outputs to be excluded")
+	}
+	if cc.ChangeAddress == nil || cc.ChangeAddress.String() != address {
+		t.Fatal("unexpected change address:", cc.ChangeAddress)
+	}
+
+	for _, flags := range []coinControlFlags{
+		{CoinInputs: []string{"foo"}},
+		{BlockStakeInputs: []string{id[2:]}},
+		{ChangeAddress: id},
+	} {
+		if _, err = flags.CoinControl(); err == nil {
+			t.Errorf("expected an error for invalid flags %v", flags)
+		}
+	}
+}
+
+func TestFeeFlags(t *testing.T) {
+	estimate := modules.FeeEstimate{
+		Low:    types.NewCurrency64(1),
+		Normal: types.NewCurrency64(2),
+		High:   types.NewCurrency64(3),
+	}
+	for priority, expected := range map[string]uint64{"low": 1, "normal": 2, "HIGH": 3} {
+		feePerByte, err := feeEstimateForPriority(estimate, priority)
+		if err != nil {
+			t.Errorf("priority %q: %v", priority, err)
+		} else if !feePerByte.Equals64(expected) {
+			t.Errorf("priority %q: expected %d, got %v", priority, expected, feePerByte)
+		}
+	}
+	if _, err := feeEstimateForPriority(estimate, "urgent"); err == nil {
+		t.Error("expected an error for an invalid priority")
+	}
+
+	minimum := types.NewCurrency64(1000)
+	if fee := feeForSize(types.NewCurrency64(2), 300, minimum); !fee.Equals64(1000) {
+		t.Error("expected the minimum fee, got:", fee)
+	}
+	if fee := feeForSize(types.NewCurrency64(5), 300, minimum); !fee.Equals64(1500) {
+		t.Error("expected a fee of 1500, got:", fee)
+	}
+
+	config := Config{
+		MinimumTransactionFee:     types.NewCurrency64(1000),
+		DefaultTransactionVersion: types.TransactionVersionOne,
+	}
+	condition := types.NewCondition(types.NewUnlockHashCondition(types.UnlockHash{Type: types.UnlockTypePubKey}))
+	coinOutputs := []types.CoinOutput{{Value: types.NewCurrency64(1), Condition: condition}}
+	size := estimateSentTransactionSize(config, coinOutputs, nil, nil, modules.CoinControl{})
+	if size <= 0 {
+		t.Fatal("unexpected size:", size)
+	}
+	if len(coinOutputs) != 1 {
+		t.Fatal("the given coin outputs should not be modified")
+	}
+	// spending more inputs or attaching data results in a bigger transaction
+	cc := modules.CoinControl{CoinInputs: make([]types.CoinOutputID, 2)}
+	if larger := estimateSentTransactionSize(config, coinOutputs, nil, nil, cc); larger <= size {
+		t.Errorf("expected two inputs to be larger than %d bytes, got %d bytes", size, larger)
+	}
+	if larger := estimateSentTransactionSize(config, coinOutputs, nil, []byte("data"), modules.CoinControl{}); larger != size+4 {
+		t.Errorf("expected 4 bytes of data to add 4 bytes to %d bytes, got %d bytes", size, larger)
+	}
+	blockStakeOutputs := []types.BlockStakeOutput{{Value: types.NewCurrency64(1), Condition: condition}}
+	if larger := estimateSentTransactionSize(config, nil, blockStakeOutputs, nil, modules.CoinControl{}); larger <= size {
+		t.Errorf("expected a blockstake transaction to be larger than %d bytes, got %d bytes", size, larger)
+	}
+}
+
+func TestAddressFlow(t *testing.T) {
+	uhA := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: crypto.Hash{1}}
+	uhB := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: crypto.Hash{2}}
+	txn := modules.ProcessedTransaction{
+		Inputs: []modules.ProcessedInput{
+			{FundType: types.SpecifierCoinInput, RelatedAddress: uhA, Value: types.NewCurrency64(100)},
+			{FundType: types.SpecifierBlockStakeInput, RelatedAddress: uhA, Value: types.NewCurrency64(5)},
+		},
+		Outputs: []modules.ProcessedOutput{
+			{FundType: types.SpecifierCoinOutput, RelatedAddress: uhB, Value: types.NewCurrency64(60)},
+			{FundType: types.SpecifierCoinOutput, RelatedAddress: uhA, Value: types.NewCurrency64(30)},
+			{FundType: types.SpecifierBlockStakeOutput, RelatedAddress: uhB, Value: types.NewCurrency64(5)},
+			{FundType: types.SpecifierMinerPayout, RelatedAddress: uhB, Value: types.NewCurrency64(10)},
+		},
+	}
+
+	coinsIn, coinsOut, blockStakesIn, blockStakesOut := addressFlow(txn, uhA)
+	if !coinsIn.Equals64(30) || !coinsOut.Equals64(100) || !blockStakesIn.Equals64(0) || !blockStakesOut.Equals64(5) {
+		t.Errorf("unexpected flow of sender: %v %v %v %v", coinsIn, coinsOut, blockStakesIn, blockStakesOut)
+	}
+	coinsIn, coinsOut, blockStakesIn, blockStakesOut = addressFlow(txn, uhB)
+	if !coinsIn.Equals64(70) || !coinsOut.Equals64(0) || !blockStakesIn.Equals64(5) || !blockStakesOut.Equals64(0) {
+		t.Errorf("unexpected flow of receiver: %v %v %v %v", coinsIn, coinsOut, blockStakesIn, blockStakesOut)
+	}
+}
+
+func TestParsePaymentsCSV(t *testing.T) {
+	uhA := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: crypto.Hash{1}}
+	uhB := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: crypto.Hash{2}}
+	csv := "Address,Amount,Label\n" +
+		"# monthly payments\n" +
+		uhA.String() + ",1.5,alice\n" +
+		uhB.String() + ", 2 mcoin\n" +
+		"foo,1,bar\n" +
+		uhA.String() + ",abc\n" +
+		uhA.String() + ",0\n" +
+		uhB.String() + "\n" +
+		uhB.String() + ",1,\"bob, the builder\"\n"
+
+	cc := createDefaultCurrencyConvertor()
+	payments, err := parsePaymentsCSV(strings.NewReader(csv), cc.ParseCoinString)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(payments) != 7 {
+		t.Fatalf("unexpected amount of payments: %d != 7", len(payments))
+	}
+
+	expectedValid := []struct {
+		Index      int
+		Row        int
+		UnlockHash types.UnlockHash
+		Amount     string
+		Label      string
+	}{
+		{0, 3, uhA, "1.5", "alice"},
+		{1, 4, uhB, "0.002", ""},
+		{6, 9, uhB, "1", "bob, the builder"},
+	}
+	for _, expected := range expectedValid {
+		p := payments[expected.Index]
+		if p.Err != nil {
+			t.Errorf("payment #%d: unexpected error: %v", expected.Index, p.Err)
+			continue
+		}
+		if p.Row != expected.Row || p.UnlockHash.Cmp(expected.UnlockHash) != 0 ||
+			cc.ToCoinString(p.Amount) != expected.Amount || p.Label != expected.Label {
+			t.Errorf("payment #%d: unexpected payment: %+v", expected.Index, p)
+		}
+	}
+
+	expectedInvalid := map[int]string{
+		2: "row 5: invalid address",
+		3: "row 6: invalid amount",
+		4: "row 7: amount has to be greater than zero",
+		5: "row 8: expected 2 or 3 columns",
+	}
+	for index, expected := range expectedInvalid {
+		if err := payments[index].Err; err == nil || !strings.HasPrefix(err.Error(), expected) {
+			t.Errorf("payment #%d: unexpected error: %v", index, err)
+		}
+	}
+	if code := cli.ErrorExitCode(payments[2].Err); code != cli.ExitCodeInvalidAddress {
+		t.Errorf("unexpected exit code for an invalid address: %d", code)
+	}
+}
+
+func TestSeedAddresses(t *testing.T) {
+	seed := modules.Seed{1, 2, 3}
+	addresses := seedAddresses(seed, 5)
+	if len(addresses) != 5 {
+		t.Fatalf("unexpected amount of addresses: %d != 5", len(addresses))
+	}
+	unique := map[types.UnlockHash]struct{}{}
+	for i, address := range addresses {
+		if address.Type != types.UnlockTypePubKey {
+			t.Errorf("unexpected type of address %d: %v", i, address.Type)
+		}
+		unique[address] = struct{}{}
+	}
+	if len(unique) != len(addresses) {
+		t.Errorf("addresses are not unique: %v", addresses)
+	}
+	// derivation is deterministic, and independent of the amount of addresses
+	for i, address := range seedAddresses(seed, 2) {
+		if address != addresses[i] {
+			t.Errorf("unexpected address %d: %v != %v", i, address, addresses[i])
+		}
+	}
+	if other := seedAddresses(modules.Seed{3, 2, 1}, 1); other[0] == addresses[0] {
+		t.Error("different seeds derive the same address")
+	}
+}
+
+func TestValidateAddress(t *testing.T) {
+	valid := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: crypto.Hash{1, 2, 3}}.String()
+	multisig := types.UnlockHash{Type: types.UnlockTypeMultiSig, Hash: crypto.Hash{4, 5, 6}}.String()
+	badChecksum := valid[:len(valid)-2] + "00"
+	if strings.HasSuffix(valid, "00") {
+		badChecksum = valid[:len(valid)-2] + "11"
+	}
+	unknown := types.UnlockHash{Type: 255, Hash: crypto.Hash{1, 2, 3}}.String()
+
+	testCases := []struct {
+		Address  string
+		Valid    bool
+		Type     string
+		Checksum string
+	}{
+		{valid, true, "single signature", addressChecksumValid},
+		{multisig, true, "multisig", addressChecksumValid},
+		{strings.Repeat("0", 78), true, "nil", addressChecksumNone},
+		{badChecksum, false, "single signature", addressChecksumInvalid},
+		{unknown, true, "unknown", addressChecksumValid},
+		{valid[:20], false, "", ""},
+		{"zz" + valid[2:], false, "", ""},
+	}
+	for _, testCase := range testCases {
+		output := validateAddress(testCase.Address)
+		if output.Valid != testCase.Valid || output.Type != testCase.Type || output.Checksum != testCase.Checksum {
+			t.Errorf("%s: unexpected output: %+v", testCase.Address, output)
+		}
+		if output.Valid != (output.Error == "") {
+			t.Errorf("%s: expected an error only for an invalid address, got: %q", testCase.Address, output.Error)
+		}
+		if output.Valid {
+			var expected types.UnlockHash
+			if err := expected.LoadString(testCase.Address); err != nil {
+				t.Errorf("%s: %v", testCase.Address, err)
+			} else if output.address.Cmp(expected) != 0 {
+				t.Errorf("%s: unexpected parsed address: %v", testCase.Address, output.address)
+			}
+		}
+	}
+}
@@ -3,11 +3,16 @@ package daemon
 import (
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/spf13/pflag"
 
 	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/persist"
 	"github.com/threefoldtech/rivine/types"
 )
 
@@ -29,6 +34,15 @@ type (
 		// the host:port for the HTTP API to listen on.
 		// If `AllowAPIBind` is false, only localhost hosts are allowed
 		APIaddr string
+		// additional host:port addresses for the HTTP API to listen on,
+		// such as an IPv6 loopback address, subject to the same restrictions as `APIaddr`
+		APIListenAddrs []string
+		// the path of a unix domain socket for the HTTP API to listen on, none if empty,
+		// relative to the persistent directory of the network unless absolute,
+		// and its (octal) file permissions, which authenticate its callers,
+		// as calls over the socket do not require the API password
+		APIUnixSocket     string
+		APIUnixSocketMode string
 		// the host:port to listen for RPC calls
 		RPCaddr string
 		// indicates that the http API can listen on a non localhost address.
@@ -43,16 +57,110 @@ type (
 		RequiredUserAgent string
 		// indicates if the http api is password protected
 		AuthenticateAPI bool
+		// the origins allowed to make cross-origin (CORS) requests to the http api,
+		// none by default, "*" allows all origins
+		APICORSAllowedOrigins []string
+		// the methods and headers cross-origin requests to the http api can use
+		APICORSAllowedMethods []string
+		APICORSAllowedHeaders []string
+		// the certificate and key used to serve the http api over TLS,
+		// served over plain HTTP if not defined
+		APITLSCertFile string
+		APITLSKeyFile  string
+		// the CA certificates of which clients have to present a certificate,
+		// if the http api is served over TLS, not required if not defined
+		APITLSClientCAFile string
+		// the rate limits of the http api, per IP address and per API token,
+		// in calls per second and calls at once, disabled if the rate is 0
+		APIRateLimit      int
+		APIRateBurst      int
+		APITokenRateLimit int
+		APITokenRateBurst int
+		// the amount of expensive api calls (e.g. rescans) which can be executed concurrently,
+		// unlimited if 0
+		APIMaxConcurrentExpensive int
+		// indicates if the api calls modifying the state of the daemon are recorded
+		// to an append-only audit log, in the root persistent directory
+		APIAuditLog bool
+		// the duration for which the responses of the api calls made using an idempotency key
+		// are remembered, such that retried calls are not executed twice, disabled if 0
+		APIIdempotencyKeyTTL time.Duration
+
+		// the format of the module logs, text or json
+		LogFormat string
+		// the log levels, either a level applying to all modules,
+		// or a level of a single module given as module=level
+		LogLevels []string
+		// the size in megabytes and the age after which the log file of a module is rotated,
+		// not rotated if both are 0
+		LogMaxSize          int64
+		LogRotationInterval time.Duration
+		// indicates if the rotated log files are compressed
+		LogCompress bool
+		// the amount and maximum age of the rotated log files retained per module,
+		// unlimited if 0
+		LogMaxBackups int
+		LogMaxAge     time.Duration
 
 		// indicates if profile info should be collected while
 		// the daemon is running
 		Profile bool
 		// name of the directory to store the profile info,
-		// should this be collected
+		// should this be collected, as well as the profiles captured using the API
 		ProfileDir string
+		// indicates if the net/http/pprof handlers should be served by the API,
+		// requiring the API password or an admin API token should the API be password protected
+		APIPprof bool
+		// indicates if a GraphQL endpoint should be served over the explorer indexes,
+		// next to the REST API of the explorer
+		ExplorerGraphQL bool
 		// the parent directory where the individual module
 		// directories will be created
 		RootPersistentDir string
+
+		// indicates if the consensus set should maintain an index
+		// of all unspent outputs by unlock hash
+		UnlockHashIndex bool
+
+		// the transaction pool limits, overwriting the limits defined
+		// by the network constants, if not 0
+		TransactionPoolSizeLimit   int
+		TransactionPoolCountLimit  int
+		TransactionPoolSourceLimit int
+
+		// indicates that the database migrations should only be validated,
+		// after which the daemon exits, without modifying any database
+		DatabaseMigrationDryRun bool
+		// indicates that databases should not be backed up prior to migrating them
+		NoDatabaseBackup bool
+
+		// the maximum duration of the shutdown of the daemon, closing all modules,
+		// after which the daemon exits regardless, unlimited if 0
+		ShutdownTimeout time.Duration
+		// the amount of blocks the consensus set can be behind the (estimated) network height,
+		// for the daemon to notify systemd that it is ready, when started as a notify service
+		SystemdReadyMaxBlocksBehind uint64
+
+		// the URL of the signed update manifest published by the maintainers of the chain,
+		// checked at the given interval for a more recent version of the daemon, not checked if empty,
+		// trusted only if signed using the key pair of the given address
+		UpdateManifestURL    string
+		UpdateManifestSigner string
+		UpdateCheckInterval  time.Duration
+
+		// the address of the API of a remote node (e.g. https://node.example.org:23110),
+		// of which the consensus set and transaction pool are used by the wallet, rather than loading them,
+		// such that the wallet runs separately from the node, which then holds no secrets
+		RemoteNodeAddr string
+		// the API password, or API token, used to authenticate to the API of the remote node
+		RemoteNodePassword string
+		// the public key pins of the TLS certificate of the remote node,
+		// verified using the system CAs if not defined
+		RemoteNodeTLSPins []string
+		// the certificate and key presented to the remote node,
+		// should it require API clients to present a certificate
+		RemoteNodeTLSCertFile string
+		RemoteNodeTLSKeyFile  string
 	}
 
 	// NetworkConfig are variables for a particular chain. Currently, these are genesis constants and bootstrap peers
@@ -61,6 +169,10 @@ type (
 		Constants types.ChainConstants
 		// BootstrapPeers for this network
 		BootstrapPeers []modules.NetAddress
+		// the default host:port of the HTTP API and of the RPC calls for this network,
+		// such that the daemons of different networks can run on the same machine
+		APIaddr string
+		RPCaddr string
 	}
 )
 
@@ -75,13 +187,66 @@ func DefaultConfig() Config {
 		RPCaddr:      ":23112",
 		AllowAPIBind: false,
 
+		APIListenAddrs:    nil,
+		APIUnixSocket:     "",
+		APIUnixSocketMode: "0600",
+
 		NoBootstrap:       false,
 		RequiredUserAgent: RivineUserAgent,
 		AuthenticateAPI:   false,
 
+		APICORSAllowedOrigins: nil,
+		APICORSAllowedMethods: []string{"GET", "POST"},
+		APICORSAllowedHeaders: []string{"Authorization", "Content-Type"},
+
+		APITLSCertFile:     "",
+		APITLSKeyFile:      "",
+		APITLSClientCAFile: "",
+
+		APIRateLimit:              0,
+		APIRateBurst:              0,
+		APITokenRateLimit:         0,
+		APITokenRateBurst:         0,
+		APIMaxConcurrentExpensive: 2,
+		APIAuditLog:               false,
+		APIIdempotencyKeyTTL:      24 * time.Hour,
+
+		LogFormat: string(persist.LogFormatText),
+		LogLevels: nil,
+
+		LogMaxSize:          100,
+		LogRotationInterval: 0,
+		LogCompress:         true,
+		LogMaxBackups:       10,
+		LogMaxAge:           0,
+
 		Profile:           false,
 		ProfileDir:        "profiles",
+		APIPprof:          false,
+		ExplorerGraphQL:   false,
 		RootPersistentDir: "",
+
+		UnlockHashIndex: false,
+
+		TransactionPoolSizeLimit:   0,
+		TransactionPoolCountLimit:  0,
+		TransactionPoolSourceLimit: 0,
+
+		DatabaseMigrationDryRun: false,
+		NoDatabaseBackup:        false,
+
+		ShutdownTimeout:             time.Minute,
+		SystemdReadyMaxBlocksBehind: 10,
+
+		UpdateManifestURL:    "",
+		UpdateManifestSigner: "",
+		UpdateCheckInterval:  24 * time.Hour,
+
+		RemoteNodeAddr:        "",
+		RemoteNodePassword:    "",
+		RemoteNodeTLSPins:     nil,
+		RemoteNodeTLSCertFile: "",
+		RemoteNodeTLSKeyFile:  "",
 	}
 }
 
@@ -89,22 +254,164 @@ func DefaultConfig() Config {
 func (cfg *Config) RegisterAsFlags(flagSet *pflag.FlagSet) {
 	flagSet.StringVarP(&cfg.RequiredUserAgent, "agent", "", cfg.RequiredUserAgent, "required substring for the user agent")
 	flagSet.StringVarP(&cfg.ProfileDir, "profile-directory", "", cfg.ProfileDir, "location of the profiling directory")
-	flagSet.StringVarP(&cfg.APIaddr, "api-addr", "", cfg.APIaddr, "which host:port the API server listens on")
+	flagSet.StringVarP(&cfg.APIaddr, "api-addr", "", cfg.APIaddr, "which host:port the API server listens on (the default depends on the network)")
+	flagSet.StringSliceVarP(&cfg.APIListenAddrs, "api-listen", "", cfg.APIListenAddrs, "additional host:port addresses the API server listens on, such as [::1]:23110")
+	flagSet.StringVarP(&cfg.APIUnixSocket, "api-unix-socket", "", cfg.APIUnixSocket, "path of a unix domain socket the API server listens on, relative to the persistent directory unless absolute, calls over it do not require the API password")
+	flagSet.StringVarP(&cfg.APIUnixSocketMode, "api-unix-socket-mode", "", cfg.APIUnixSocketMode, "octal file permissions of the unix domain socket of the API, defining who can call the API over it")
 	flagSet.StringVarP(&cfg.RootPersistentDir, "persistent-directory", "d", cfg.RootPersistentDir,
 		"location of the root diretory used to store persistent data of the daemon of"+
 			cfg.BlockchainInfo.Name)
 	flagSet.BoolVarP(&cfg.NoBootstrap, "no-bootstrap", "", cfg.NoBootstrap, "disable bootstrapping on this run")
 	flagSet.BoolVarP(&cfg.Profile, "profile", "", cfg.Profile, "enable profiling")
-	flagSet.StringVarP(&cfg.RPCaddr, "rpc-addr", "", cfg.RPCaddr, "which port the gateway listens on")
+	flagSet.BoolVarP(&cfg.APIPprof, "api-pprof", "", cfg.APIPprof, "serve the net/http/pprof handlers under /debug/pprof/ of the API, for admins only should the API be password protected")
+	flagSet.BoolVarP(&cfg.ExplorerGraphQL, "explorer-graphql", "", cfg.ExplorerGraphQL, "serve a GraphQL endpoint over the explorer indexes under /explorer/graphql, requires the explorer module")
+	flagSet.StringVarP(&cfg.RPCaddr, "rpc-addr", "", cfg.RPCaddr, "which port the gateway listens on (the default depends on the network)")
 	flagSet.BoolVarP(&cfg.AuthenticateAPI, "authenticate-api", "", cfg.AuthenticateAPI, "enable API password protection")
+	flagSet.StringSliceVarP(&cfg.APICORSAllowedOrigins, "api-cors-origins", "", cfg.APICORSAllowedOrigins, "origins allowed to make cross-origin requests to the API, such as browser-based wallets (* allows all origins, without exempting them from the user agent check)")
+	flagSet.StringSliceVarP(&cfg.APICORSAllowedMethods, "api-cors-methods", "", cfg.APICORSAllowedMethods, "HTTP methods allowed for cross-origin requests to the API")
+	flagSet.StringSliceVarP(&cfg.APICORSAllowedHeaders, "api-cors-headers", "", cfg.APICORSAllowedHeaders, "headers allowed to be set by cross-origin requests to the API")
+	flagSet.StringVarP(&cfg.APITLSCertFile, "api-tls-cert", "", cfg.APITLSCertFile, "PEM-encoded certificate (chain) used to serve the API over TLS, reloaded when modified")
+	flagSet.StringVarP(&cfg.APITLSKeyFile, "api-tls-key", "", cfg.APITLSKeyFile, "PEM-encoded private key of the TLS certificate of the API, reloaded when modified")
+	flagSet.StringVarP(&cfg.APITLSClientCAFile, "api-tls-client-ca", "", cfg.APITLSClientCAFile, "PEM-encoded CA certificates of which API clients have to present a certificate (requires --api-tls-cert)")
+	flagSet.IntVarP(&cfg.APIRateLimit, "api-rate-limit", "", cfg.APIRateLimit, "maximum amount of API calls per second of a single IP address (0 = unlimited)")
+	flagSet.IntVarP(&cfg.APIRateBurst, "api-rate-burst", "", cfg.APIRateBurst, "maximum amount of API calls of a single IP address at once (0 = the rate limit)")
+	flagSet.IntVarP(&cfg.APITokenRateLimit, "api-token-rate-limit", "", cfg.APITokenRateLimit, "maximum amount of API calls per second authenticated using a single API token (0 = unlimited)")
+	flagSet.IntVarP(&cfg.APITokenRateBurst, "api-token-rate-burst", "", cfg.APITokenRateBurst, "maximum amount of API calls at once authenticated using a single API token (0 = the token rate limit)")
+	flagSet.IntVarP(&cfg.APIMaxConcurrentExpensive, "api-max-expensive", "", cfg.APIMaxConcurrentExpensive, "maximum amount of expensive API calls, such as rescans and wallet exports, executed concurrently (0 = unlimited)")
+	flagSet.BoolVarP(&cfg.APIAuditLog, "api-audit-log", "", cfg.APIAuditLog, "record the API calls modifying the state of the daemon, such as sends and bans, to an append-only audit log, retrievable by admins using /daemon/audit")
+	flagSet.DurationVarP(&cfg.APIIdempotencyKeyTTL, "api-idempotency-ttl", "", cfg.APIIdempotencyKeyTTL, "duration for which the responses of sends and published transactions made using an Idempotency-Key header are remembered, such that retried calls are not executed twice (0 = disabled)")
+	flagSet.StringVarP(&cfg.LogFormat, "log-format", "", cfg.LogFormat, "format of the module logs, text or json")
+	flagSet.StringSliceVarP(&cfg.LogLevels, "log-level", "", cfg.LogLevels, "log level (debug, info, warn or error) of all modules, or of a single module given as module=level, can be changed at runtime using the API")
+	flagSet.Int64VarP(&cfg.LogMaxSize, "log-max-size", "", cfg.LogMaxSize, "size in megabytes after which the log file of a module is rotated (0 = unlimited)")
+	flagSet.DurationVarP(&cfg.LogRotationInterval, "log-rotation-interval", "", cfg.LogRotationInterval, "age after which the log file of a module is rotated, e.g. 24h (0 = unlimited)")
+	flagSet.BoolVarP(&cfg.LogCompress, "log-compress", "", cfg.LogCompress, "compress the rotated log files using gzip")
+	flagSet.IntVarP(&cfg.LogMaxBackups, "log-max-backups", "", cfg.LogMaxBackups, "amount of rotated log files retained per module (0 = all)")
+	flagSet.DurationVarP(&cfg.LogMaxAge, "log-max-age", "", cfg.LogMaxAge, "age after which rotated log files are removed, e.g. 720h (0 = never)")
 	flagSet.BoolVarP(&cfg.AllowAPIBind, "disable-api-security", "", cfg.AllowAPIBind, fmt.Sprintf("allow the daemon of %s to listen on a non-localhost address (DANGEROUS)", cfg.BlockchainInfo.Name))
-	flagSet.StringVarP(&cfg.BlockchainInfo.NetworkName, "network", "n", cfg.BlockchainInfo.NetworkName, "the name of the network to which the daemon connects")
+	flagSet.StringVarP(&cfg.BlockchainInfo.NetworkName, "network", "n", cfg.BlockchainInfo.NetworkName, "the network to which the daemon connects: standard, testnet, devnet or a JSON file defining a custom network")
+	flagSet.BoolVarP(&cfg.UnlockHashIndex, "unlockhash-index", "", cfg.UnlockHashIndex, "maintain an index of unspent outputs by unlock hash in the consensus set")
+	flagSet.IntVarP(&cfg.TransactionPoolSizeLimit, "tpool-size-limit", "", cfg.TransactionPoolSizeLimit, "maximum size in bytes of the transaction pool (0 = network default)")
+	flagSet.IntVarP(&cfg.TransactionPoolCountLimit, "tpool-count-limit", "", cfg.TransactionPoolCountLimit, "maximum amount of transactions in the transaction pool (0 = network default)")
+	flagSet.IntVarP(&cfg.TransactionPoolSourceLimit, "tpool-source-limit", "", cfg.TransactionPoolSourceLimit, "maximum amount of pooled transactions relayed by a single peer (0 = network default)")
+	flagSet.BoolVarP(&cfg.DatabaseMigrationDryRun, "db-migrate-dry-run", "", cfg.DatabaseMigrationDryRun, "validate the pending database migrations and exit, without modifying any database")
+	flagSet.BoolVarP(&cfg.NoDatabaseBackup, "no-db-backup", "", cfg.NoDatabaseBackup, "do not back up databases prior to migrating them")
+	flagSet.Uint64VarP(&cfg.SystemdReadyMaxBlocksBehind, "systemd-ready-max-blocks", "", cfg.SystemdReadyMaxBlocksBehind, "maximum amount of blocks the consensus set can be behind the network, for the daemon to notify systemd it is ready")
+	flagSet.StringVarP(&cfg.UpdateManifestURL, "update-manifest-url", "", cfg.UpdateManifestURL, "URL of the signed update manifest of the chain, checked periodically for a more recent version reported by /daemon/version (disabled if empty)")
+	flagSet.StringVarP(&cfg.UpdateManifestSigner, "update-manifest-signer", "", cfg.UpdateManifestSigner, "address of which the key pair signs the update manifest (required by --update-manifest-url)")
+	flagSet.DurationVarP(&cfg.UpdateCheckInterval, "update-check-interval", "", cfg.UpdateCheckInterval, "interval at which the update manifest is checked")
+	flagSet.StringVarP(&cfg.RemoteNodeAddr, "remote-node", "", cfg.RemoteNodeAddr, "address of the API of a remote node (e.g. https://node.example.org:23110), of which the consensus set and transaction pool are used by the wallet, such that only the wallet is loaded")
+	flagSet.StringVarP(&cfg.RemoteNodePassword, "remote-node-password", "", cfg.RemoteNodePassword, "API password, or API token, of the remote node (preferably given as environment variable)")
+	flagSet.StringSliceVarP(&cfg.RemoteNodeTLSPins, "remote-node-tls-pin", "", cfg.RemoteNodeTLSPins, "public key pins (sha256//<base64>) of the TLS certificate of the remote node, verified using the system CAs if not given")
+	flagSet.StringVarP(&cfg.RemoteNodeTLSCertFile, "remote-node-tls-cert", "", cfg.RemoteNodeTLSCertFile, "PEM-encoded certificate presented to the remote node, should it require a client certificate")
+	flagSet.StringVarP(&cfg.RemoteNodeTLSKeyFile, "remote-node-tls-key", "", cfg.RemoteNodeTLSKeyFile, "PEM-encoded private key of the certificate presented to the remote node")
+	flagSet.DurationVarP(&cfg.ShutdownTimeout, "shutdown-timeout", "", cfg.ShutdownTimeout, "maximum duration of the shutdown of the daemon, after which it exits regardless, reporting the modules that did not close (0 = unlimited)")
+}
+
+// TransactionPoolConstants returns the given transaction pool constants,
+// with the limits overwritten by the ones configured, if any.
+func (cfg *Config) TransactionPoolConstants(constants types.TransactionPoolConstants) types.TransactionPoolConstants {
+	if cfg.TransactionPoolSizeLimit > 0 {
+		constants.PoolSizeLimit = cfg.TransactionPoolSizeLimit
+	}
+	if cfg.TransactionPoolCountLimit > 0 {
+		constants.PoolTransactionCountLimit = cfg.TransactionPoolCountLimit
+	}
+	if cfg.TransactionPoolSourceLimit > 0 {
+		constants.PoolSourceTransactionLimit = cfg.TransactionPoolSourceLimit
+	}
+	return constants
+}
+
+// APITLSConfig returns the TLS configuration of the http api,
+// which is served over plain HTTP if it is not enabled.
+func (cfg *Config) APITLSConfig() TLSConfig {
+	return TLSConfig{
+		CertFile:     cfg.APITLSCertFile,
+		KeyFile:      cfg.APITLSKeyFile,
+		ClientCAFile: cfg.APITLSClientCAFile,
+	}
+}
+
+// ApplyLogConfig applies the log format, levels and rotation to the logs of all modules loaded afterwards.
+func (cfg *Config) ApplyLogConfig() error {
+	err := persist.SetLogFormat(persist.LogFormat(cfg.LogFormat))
+	if err != nil {
+		return err
+	}
+	err = persist.SetLogRotation(cfg.LogRotation())
+	if err != nil {
+		return err
+	}
+	for _, str := range cfg.LogLevels {
+		var module string
+		if parts := strings.SplitN(str, "=", 2); len(parts) == 2 {
+			module, str = strings.TrimSpace(parts[0]), parts[1]
+		}
+		var level persist.LogLevel
+		if err = level.UnmarshalText([]byte(strings.TrimSpace(str))); err != nil {
+			return err
+		}
+		if err = persist.SetLogLevel(module, level); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LogRotation returns the rotation of the log files of the modules.
+func (cfg *Config) LogRotation() persist.LogRotation {
+	return persist.LogRotation{
+		MaxSize:    cfg.LogMaxSize * 1e6,
+		Interval:   cfg.LogRotationInterval,
+		Compress:   cfg.LogCompress,
+		MaxBackups: cfg.LogMaxBackups,
+		MaxAge:     cfg.LogMaxAge,
+	}
+}
+
+// UpdateChecker returns the update checker of the daemon, or nil if update checks are not enabled.
+func (cfg *Config) UpdateChecker() (*UpdateChecker, error) {
+	if cfg.UpdateManifestURL == "" {
+		return nil, nil
+	}
+	if cfg.UpdateManifestSigner == "" {
+		return nil, errors.New("the update manifest signer is required to check the update manifest")
+	}
+	var signer types.UnlockHash
+	err := signer.LoadString(cfg.UpdateManifestSigner)
+	if err != nil {
+		return nil, fmt.Errorf("invalid update manifest signer: %v", err)
+	}
+	if cfg.UpdateCheckInterval <= 0 {
+		return nil, errors.New("the update check interval has to be positive")
+	}
+	return NewUpdateChecker(cfg.UpdateManifestURL, signer, cfg.BlockchainInfo, cfg.UpdateCheckInterval), nil
+}
+
+// APIUnixSocketPath returns the path of the unix domain socket of the http api, if any.
+func (cfg *Config) APIUnixSocketPath() string {
+	if cfg.APIUnixSocket == "" || filepath.IsAbs(cfg.APIUnixSocket) {
+		return cfg.APIUnixSocket
+	}
+	return filepath.Join(cfg.RootPersistentDir, cfg.APIUnixSocket)
+}
+
+// APIUnixSocketPerm returns the file permissions of the unix domain socket of the http api.
+func (cfg *Config) APIUnixSocketPerm() (os.FileMode, error) {
+	perm, err := strconv.ParseUint(cfg.APIUnixSocketMode, 8, 32)
+	if err != nil || perm > 0777 {
+		return 0, fmt.Errorf("invalid unix socket mode %q: an octal file permission is required", cfg.APIUnixSocketMode)
+	}
+	return os.FileMode(perm), nil
 }
 
 // ProcessConfig checks the configuration values and performs cleanup on
 // incorrect-but-allowed values.
 func ProcessConfig(config Config) Config {
 	config.APIaddr = processNetAddr(config.APIaddr)
+	for i, addr := range config.APIListenAddrs {
+		config.APIListenAddrs[i] = processNetAddr(addr)
+	}
 	config.RPCaddr = processNetAddr(config.RPCaddr)
 	return config
 }
@@ -112,15 +419,17 @@ func ProcessConfig(config Config) Config {
 // VerifyAPISecurity checks that the security values are consistent with a
 // sane, secure system.
 func VerifyAPISecurity(cfg Config) error {
-	// Make sure that only the loopback address is allowed unless the
+	// Make sure that only the loopback addresses are allowed unless the
 	// --disable-api-security flag has been used.
 	if !cfg.AllowAPIBind {
-		addr := modules.NetAddress(cfg.APIaddr)
-		if !addr.IsLoopback() {
-			if addr.Host() == "" {
-				return fmt.Errorf("a blank host will listen on all interfaces, did you mean localhost:%v?\nyou must pass --disable-api-security to bind daemon of %s to a non-localhost address", addr.Port(), cfg.BlockchainInfo.Name)
+		for _, str := range append([]string{cfg.APIaddr}, cfg.APIListenAddrs...) {
+			addr := modules.NetAddress(str)
+			if !addr.IsLoopback() {
+				if addr.Host() == "" {
+					return fmt.Errorf("a blank host will listen on all interfaces, did you mean localhost:%v?\nyou must pass --disable-api-security to bind daemon of %s to a non-localhost address", addr.Port(), cfg.BlockchainInfo.Name)
+				}
+				return fmt.Errorf("you must pass --disable-api-security to bind daemon of %s to a non-localhost address", cfg.BlockchainInfo.Name)
 			}
-			return fmt.Errorf("you must pass --disable-api-security to bind daemon of %s to a non-localhost address", cfg.BlockchainInfo.Name)
 		}
 		return nil
 	}
@@ -153,10 +462,13 @@ func DefaultNetworkConfig(networkName string) (NetworkConfig, error) {
 	switch networkName {
 	case "standard":
 		networkCfg.Constants = types.StandardnetChainConstants()
+		networkCfg.APIaddr, networkCfg.RPCaddr = "localhost:23110", ":23112"
 	case "testnet":
 		networkCfg.Constants = types.TestnetChainConstants()
+		networkCfg.APIaddr, networkCfg.RPCaddr = "localhost:23120", ":23122"
 	case "devnet":
 		networkCfg.Constants = types.DevnetChainConstants()
+		networkCfg.APIaddr, networkCfg.RPCaddr = "localhost:23130", ":23132"
 	default:
 		return NetworkConfig{}, fmt.Errorf("unknown network name %s", networkName)
 	}
@@ -0,0 +1,320 @@
+package daemon
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// The configuration file of the daemon uses (a subset of) the TOML format:
+// key/value pairs, optionally grouped in tables, where the values are
+// (quoted) strings, integers, booleans or arrays of those. Every key corresponds to a
+// command line flag, joining the table and key names using a hyphen, such that
+//
+//	[api]
+//	addr = "localhost:23110"
+//	cors-origins = ["https://wallet.example.com"]
+//
+// is equivalent to `--api-addr localhost:23110 --api-cors-origins https://wallet.example.com`.
+//
+// Each flag can be overwritten using an environment variable as well, named after the flag,
+// in upper case, with hyphens replaced by underscores, and prefixed using the environment prefix,
+// e.g. RIVINED_API_ADDR. Flags given on the command line take precedence over environment
+// variables, which take precedence over the configuration file.
+
+// ConfigFileFlags are the flags which cannot be defined in a configuration file,
+// as they control the loading of the configuration file itself,
+// or are applied prior to loading it, such as the plugins to open.
+var ConfigFileFlags = []string{"config-file", "print-config", "help", "plugin"}
+
+// ApplyConfigFile applies the configuration file with the given name (if not empty),
+// and the environment variables with the given prefix, to all flags of the given flag set
+// which were not given on the command line.
+func ApplyConfigFile(flagSet *pflag.FlagSet, filename, envPrefix string) error {
+	values := make(map[string]string)
+	if filename != "" {
+		file, err := os.Open(filename)
+		if err != nil {
+			return fmt.Errorf("failed to open config file: %v", err)
+		}
+		values, err = ParseConfigFile(file)
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("invalid config file %s: %v", filename, err)
+		}
+	}
+	for name := range values {
+		if flagSet.Lookup(name) == nil || isConfigFileFlag(name) {
+			return fmt.Errorf("invalid config file %s: unknown key %q", filename, name)
+		}
+	}
+
+	var err error
+	flagSet.VisitAll(func(flag *pflag.Flag) {
+		if err != nil || flag.Changed || isConfigFileFlag(flag.Name) {
+			return
+		}
+		value, ok := os.LookupEnv(ConfigEnvVar(envPrefix, flag.Name))
+		if !ok {
+			value, ok = values[flag.Name]
+		}
+		if !ok {
+			return
+		}
+		if setErr := flagSet.Set(flag.Name, value); setErr != nil {
+			err = fmt.Errorf("invalid value %q for %s: %v", value, flag.Name, setErr)
+		}
+	})
+	return err
+}
+
+// ConfigEnvVar returns the name of the environment variable overwriting the flag with the given name.
+func ConfigEnvVar(envPrefix, flagName string) string {
+	return envPrefix + strings.ToUpper(strings.Replace(flagName, "-", "_", -1))
+}
+
+// isConfigFileFlag returns true if the flag with the given name cannot be configured.
+func isConfigFileFlag(name string) bool {
+	for _, flag := range ConfigFileFlags {
+		if flag == name {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteConfigFile writes the current value of all flags of the given flag set
+// as a configuration file, which can be loaded using ApplyConfigFile.
+func WriteConfigFile(w io.Writer, flagSet *pflag.FlagSet) error {
+	var err error
+	flagSet.VisitAll(func(flag *pflag.Flag) {
+		if err != nil || isConfigFileFlag(flag.Name) {
+			return
+		}
+		var value, disabled string
+		switch flag.Value.Type() {
+		case "bool", "int", "int64", "uint", "uint64":
+			value = flag.Value.String()
+		case "stringSlice":
+			var values []string
+			values, err = flagSet.GetStringSlice(flag.Name)
+			quoted := make([]string, 0, len(values))
+			for _, v := range values {
+				quoted = append(quoted, strconv.Quote(v))
+			}
+			value = "[" + strings.Join(quoted, ", ") + "]"
+			if len(values) == 0 && flag.DefValue == "[]" {
+				// an empty array is only written to overwrite a default,
+				// as it would define an empty rather than an undefined (nil) slice
+				disabled = "# "
+			}
+		default:
+			value = strconv.Quote(flag.Value.String())
+		}
+		if err == nil {
+			_, err = fmt.Fprintf(w, "# %s\n%s%s = %s\n\n", flag.Usage, disabled, flag.Name, value)
+		}
+	})
+	return err
+}
+
+// ParseConfigFile parses a configuration file, returning the value of each flag it defines,
+// as it would be given on the command line. Array values are returned comma-separated.
+func ParseConfigFile(r io.Reader) (map[string]string, error) {
+	values := make(map[string]string)
+	var (
+		table   string
+		lineNum int
+		pending string // an array spanning multiple lines
+		key     string
+	)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(stripConfigComment(scanner.Text()))
+		if pending != "" {
+			pending += " " + line
+			if !strings.HasSuffix(line, "]") {
+				continue
+			}
+			line, pending = key+" = "+pending, ""
+		}
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: invalid table %s", lineNum, line)
+			}
+			name, err := parseConfigKey(line[1 : len(line)-1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", lineNum, err)
+			}
+			table = name
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("line %d: expected a key = value pair", lineNum)
+		}
+		rawValue := strings.TrimSpace(parts[1])
+		if strings.HasPrefix(rawValue, "[") && !strings.HasSuffix(rawValue, "]") {
+			key, pending = parts[0], rawValue
+			continue
+		}
+		name, err := parseConfigKey(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNum, err)
+		}
+		if table != "" {
+			name = table + "-" + name
+		}
+		if _, ok := values[name]; ok {
+			return nil, fmt.Errorf("line %d: duplicate key %s", lineNum, name)
+		}
+		value, err := parseConfigValue(rawValue)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s: %v", lineNum, name, err)
+		}
+		values[name] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if pending != "" {
+		return nil, fmt.Errorf("line %d: unterminated array", lineNum)
+	}
+	return values, nil
+}
+
+// stripConfigComment removes the comment from the given line, if any.
+func stripConfigComment(line string) string {
+	var quote rune
+	escaped := false
+	for i, c := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case quote == '"' && c == '\\':
+			escaped = true
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// parseConfigKey parses a (dotted) key, returning its parts joined using hyphens.
+func parseConfigKey(key string) (string, error) {
+	parts := strings.Split(strings.TrimSpace(key), ".")
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return "", fmt.Errorf("invalid key %q", key)
+		}
+		for _, c := range part {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+				return "", fmt.Errorf("invalid key %q", key)
+			}
+		}
+		parts[i] = part
+	}
+	return strings.Join(parts, "-"), nil
+}
+
+// parseConfigValue parses a single value, returning it as it would be given on the command line.
+func parseConfigValue(value string) (string, error) {
+	if value == "" {
+		return "", fmt.Errorf("missing value")
+	}
+	if value[0] != '[' {
+		return parseConfigScalar(value)
+	}
+	if value[len(value)-1] != ']' {
+		return "", fmt.Errorf("unterminated array")
+	}
+	var elements []string
+	rest := strings.TrimSpace(value[1 : len(value)-1])
+	for rest != "" {
+		end := configElementEnd(rest)
+		element, err := parseConfigScalar(strings.TrimSpace(rest[:end]))
+		if err != nil {
+			return "", err
+		}
+		elements = append(elements, element)
+		rest = strings.TrimSpace(rest[end:])
+		if rest != "" {
+			if rest[0] != ',' {
+				return "", fmt.Errorf("expected a comma between array elements")
+			}
+			rest = strings.TrimSpace(rest[1:])
+		}
+	}
+	// encode the elements just like a slice flag expects them
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(elements); err != nil {
+		return "", err
+	}
+	w.Flush()
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// configElementEnd returns the end of the first element of the given array elements.
+func configElementEnd(elements string) int {
+	var quote rune
+	escaped := false
+	for i, c := range elements {
+		switch {
+		case escaped:
+			escaped = false
+		case quote == '"' && c == '\\':
+			escaped = true
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ',':
+			return i
+		}
+	}
+	return len(elements)
+}
+
+// parseConfigScalar parses a string, integer or boolean value.
+func parseConfigScalar(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, `"`):
+		s, err := strconv.Unquote(value)
+		if err != nil {
+			return "", fmt.Errorf("invalid string %s", value)
+		}
+		return s, nil
+	case strings.HasPrefix(value, "'"):
+		if len(value) < 2 || !strings.HasSuffix(value, "'") || strings.Contains(value[1:len(value)-1], "'") {
+			return "", fmt.Errorf("invalid string %s", value)
+		}
+		return value[1 : len(value)-1], nil
+	case value == "true" || value == "false":
+		return value, nil
+	}
+	if _, err := strconv.ParseInt(strings.Replace(value, "_", "", -1), 10, 64); err != nil {
+		return "", fmt.Errorf("invalid value %s: expected a quoted string, integer, boolean or array", value)
+	}
+	return strings.Replace(value, "_", "", -1), nil
+}
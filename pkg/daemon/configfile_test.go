@@ -0,0 +1,127 @@
+package daemon
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestParseConfigFile(t *testing.T) {
+	values, err := ParseConfigFile(strings.NewReader(`# rivined configuration
+modules = "cgtw" # the modules to load
+no-bootstrap = true
+
+[api]
+addr = 'localhost:23110'
+cors-origins = [
+	"https://wallet.example.com", # comments are allowed within arrays
+	"https://a,b.example.com",
+]
+
+[tpool]
+size-limit = 20_000_000
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"modules":          "cgtw",
+		"no-bootstrap":     "true",
+		"api-addr":         "localhost:23110",
+		"api-cors-origins": `https://wallet.example.com,"https://a,b.example.com"`,
+		"tpool-size-limit": "20000000",
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("unexpected values: %v", values)
+	}
+
+	for _, invalid := range []string{
+		"modules",
+		"modules = ",
+		"modules = cgtw",
+		`modules = "cgtw`,
+		"[api\naddr = 1",
+		"a..b = 1",
+		"a = 1\na = 2",
+		"a = [1 2]",
+		"a = [\n1,",
+	} {
+		if _, err := ParseConfigFile(strings.NewReader(invalid)); err == nil {
+			t.Errorf("expected an error for config %q", invalid)
+		}
+	}
+}
+
+func TestApplyConfigFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "configfile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	newFlagSet := func() (*Config, *pflag.FlagSet) {
+		cfg := DefaultConfig()
+		flagSet := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		cfg.RegisterAsFlags(flagSet)
+		return &cfg, flagSet
+	}
+	filename := filepath.Join(dir, "rivined.toml")
+	err = ioutil.WriteFile(filename, []byte(`
+[api]
+addr = "localhost:1"
+cors-origins = ["https://wallet.example.com"]
+[rpc]
+addr = ":2"
+[tpool]
+size-limit = 3
+`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// flags take precedence over environment variables, which take precedence over the file
+	cfg, flagSet := newFlagSet()
+	if err = flagSet.Parse([]string{"--api-addr", "localhost:4"}); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("TESTD_RPC_ADDR", ":5")
+	defer os.Unsetenv("TESTD_RPC_ADDR")
+	if err = ApplyConfigFile(flagSet, filename, "TESTD_"); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.APIaddr != "localhost:4" || cfg.RPCaddr != ":5" || cfg.TransactionPoolSizeLimit != 3 ||
+		!reflect.DeepEqual(cfg.APICORSAllowedOrigins, []string{"https://wallet.example.com"}) {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+
+	// the written configuration results in the same configuration
+	var buf bytes.Buffer
+	if err = WriteConfigFile(&buf, flagSet); err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(filename, buf.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+	os.Unsetenv("TESTD_RPC_ADDR")
+	written, flagSet := newFlagSet()
+	if err = ApplyConfigFile(flagSet, filename, "TESTD_"); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(written, cfg) {
+		t.Errorf("unexpected config after writing it: %+v != %+v", written, cfg)
+	}
+
+	// unknown keys are refused
+	if err = ioutil.WriteFile(filename, []byte("api-address = \"localhost:1\"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err = ApplyConfigFile(flagSet, filename, "TESTD_"); err == nil {
+		t.Error("expected an unknown key to be refused")
+	}
+}
@@ -88,18 +88,15 @@ func DefaultModuleSetFlag() ModuleSetFlag {
 }
 
 // DefaultModuleSet returns the default module set,
-// containing all the modules that ship with Rivine.
+// containing all the modules that ship with Rivine,
+// as well as the modules of the registered plugins.
 func DefaultModuleSet() ModuleSet {
-	set, err := NewModuleSet(
-		GatewayModule,
-		ConsensusSetModule,
-		TransactionPoolModule,
-		WalletModule,
-		BlockCreatorModule,
-		ExplorerModule,
-	)
-	if err != nil {
-		panic(err)
+	set := rivineModuleSet()
+	for _, plugin := range ModulePlugins() {
+		err := set.Append(plugin.Module())
+		if err != nil {
+			panic(err)
+		}
 	}
 	return set
 }
@@ -0,0 +1,118 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// NetworkFile defines a custom network, which can be selected by giving
+// the path of the (JSON-encoded) file as the network of the daemon.
+//
+// The chain constants of the base network are used, overwritten by the constants
+// defined by the file, such that only the constants which differ have to be defined, e.g.:
+//
+//	{
+//		"name": "localnet",
+//		"base": "devnet",
+//		"constants": {"BlockFrequency": 5},
+//		"bootstrappeers": ["192.168.1.2:23412"],
+//		"apiaddr": "localhost:23410",
+//		"rpcaddr": ":23412"
+//	}
+type NetworkFile struct {
+	// Name of the network, which namespaces the persistent directory of the daemon,
+	// it cannot be the name of one of the networks that ship with Rivine.
+	Name string `json:"name"`
+	// Base is the network of which the chain constants are used as the default constants,
+	// one of the networks that ship with Rivine, devnet if not defined.
+	Base           string               `json:"base,omitempty"`
+	Constants      json.RawMessage      `json:"constants,omitempty"`
+	BootstrapPeers []modules.NetAddress `json:"bootstrappeers,omitempty"`
+	// APIaddr and RPCaddr are the default addresses of the daemon for this network,
+	// which should differ from the ones of the other networks ran on the same machine.
+	APIaddr string `json:"apiaddr"`
+	RPCaddr string `json:"rpcaddr"`
+}
+
+// networkNameRegexp defines the valid names of custom networks,
+// which are used as the name of a directory.
+var networkNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]*$`)
+
+// IsNetworkFile returns true if the given network refers to a network file,
+// rather than to one of the networks that ship with Rivine.
+func IsNetworkFile(network string) bool {
+	return strings.HasSuffix(network, ".json") || strings.ContainsRune(network, '/') ||
+		strings.ContainsRune(network, filepath.Separator)
+}
+
+// LoadNetworkConfig returns the name and config of the given network, either one of the
+// networks that ship with Rivine, or a custom network defined by the given network file, see NetworkFile.
+func LoadNetworkConfig(network string) (string, NetworkConfig, error) {
+	if !IsNetworkFile(network) {
+		if network == "" {
+			network = types.DefaultNetworkName()
+		}
+		networkCfg, err := DefaultNetworkConfig(network)
+		return network, networkCfg, err
+	}
+
+	file, err := os.Open(network)
+	if err != nil {
+		return "", NetworkConfig{}, fmt.Errorf("failed to open network file: %v", err)
+	}
+	defer file.Close()
+	var nf NetworkFile
+	err = json.NewDecoder(file).Decode(&nf)
+	if err != nil {
+		return "", NetworkConfig{}, fmt.Errorf("invalid network file %s: %v", network, err)
+	}
+	if !networkNameRegexp.MatchString(nf.Name) {
+		return "", NetworkConfig{}, fmt.Errorf("invalid network file %s: invalid network name %q", network, nf.Name)
+	}
+	if _, err = DefaultNetworkConfig(nf.Name); err == nil {
+		return "", NetworkConfig{}, fmt.Errorf("invalid network file %s: network %s ships with Rivine already", network, nf.Name)
+	}
+	if nf.APIaddr == "" || nf.RPCaddr == "" {
+		return "", NetworkConfig{}, fmt.Errorf("invalid network file %s: the API and RPC addresses of the network are required", network)
+	}
+	if nf.Base == "" {
+		nf.Base = "devnet"
+	}
+	networkCfg, err := DefaultNetworkConfig(nf.Base)
+	if err != nil {
+		return "", NetworkConfig{}, fmt.Errorf("invalid network file %s: invalid base network: %v", network, err)
+	}
+	if len(nf.Constants) > 0 {
+		err = json.Unmarshal(nf.Constants, &networkCfg.Constants)
+		if err != nil {
+			return "", NetworkConfig{}, fmt.Errorf("invalid network file %s: invalid constants: %v", network, err)
+		}
+	}
+	networkCfg.BootstrapPeers = nf.BootstrapPeers
+	networkCfg.APIaddr, networkCfg.RPCaddr = nf.APIaddr, nf.RPCaddr
+	return nf.Name, networkCfg, nil
+}
+
+// ApplyNetworkConfig applies the network of the given name and config to the configuration:
+// the persistent data of the daemon is stored in a subdirectory named after the network,
+// and the default API and RPC addresses of the network are used, unless given using the flags
+// (or the configuration file or environment variables applied to them) of the given flag set.
+func (cfg *Config) ApplyNetworkConfig(flagSet *pflag.FlagSet, networkName string, networkCfg NetworkConfig) {
+	cfg.BlockchainInfo.NetworkName = networkName
+	cfg.RootPersistentDir = filepath.Join(cfg.RootPersistentDir, networkName)
+	if flag := flagSet.Lookup("api-addr"); (flag == nil || !flag.Changed) && networkCfg.APIaddr != "" {
+		cfg.APIaddr = networkCfg.APIaddr
+	}
+	if flag := flagSet.Lookup("rpc-addr"); (flag == nil || !flag.Changed) && networkCfg.RPCaddr != "" {
+		cfg.RPCaddr = networkCfg.RPCaddr
+	}
+}
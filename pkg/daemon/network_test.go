@@ -0,0 +1,97 @@
+package daemon
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/pflag"
+
+	"github.com/threefoldtech/rivine/types"
+)
+
+func TestLoadNetworkConfig(t *testing.T) {
+	// the networks that ship with Rivine have their own addresses
+	name, networkCfg, err := LoadNetworkConfig("testnet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "testnet" || networkCfg.APIaddr != "localhost:23120" || networkCfg.RPCaddr != ":23122" {
+		t.Fatalf("unexpected testnet config: %s, %s, %s", name, networkCfg.APIaddr, networkCfg.RPCaddr)
+	}
+	if name, _, err = LoadNetworkConfig(""); err != nil || name != types.DefaultNetworkName() {
+		t.Fatalf("expected the default network, got %q: %v", name, err)
+	}
+	if _, _, err = LoadNetworkConfig("foonet"); err == nil {
+		t.Fatal("expected an unknown network to be refused")
+	}
+
+	dir, err := ioutil.TempDir("", "network")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeNetworkFile := func(content string) string {
+		filename := filepath.Join(dir, "network.json")
+		if err := ioutil.WriteFile(filename, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return filename
+	}
+
+	// the constants of a custom network default to the ones of its base network
+	filename := writeNetworkFile(`{
+		"name": "localnet",
+		"base": "testnet",
+		"constants": {"BlockFrequency": 5},
+		"bootstrappeers": ["192.168.1.2:23412"],
+		"apiaddr": "localhost:23410",
+		"rpcaddr": ":23412"
+	}`)
+	name, networkCfg, err = LoadNetworkConfig(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := types.TestnetChainConstants()
+	if name != "localnet" || networkCfg.Constants.BlockFrequency != 5 ||
+		networkCfg.Constants.MaturityDelay != expected.MaturityDelay ||
+		len(networkCfg.BootstrapPeers) != 1 || networkCfg.BootstrapPeers[0] != "192.168.1.2:23412" ||
+		networkCfg.APIaddr != "localhost:23410" || networkCfg.RPCaddr != ":23412" {
+		t.Fatalf("unexpected custom network config: %s, %+v", name, networkCfg)
+	}
+
+	for _, content := range []string{
+		`{"name": "devnet", "apiaddr": "localhost:23410", "rpcaddr": ":23412"}`,
+		`{"name": "../localnet", "apiaddr": "localhost:23410", "rpcaddr": ":23412"}`,
+		`{"name": "localnet"}`,
+		`{"name": "localnet", "base": "foonet", "apiaddr": "localhost:23410", "rpcaddr": ":23412"}`,
+	} {
+		if _, _, err = LoadNetworkConfig(writeNetworkFile(content)); err == nil {
+			t.Errorf("expected network file to be refused: %s", content)
+		}
+	}
+}
+
+func TestApplyNetworkConfig(t *testing.T) {
+	_, networkCfg, err := LoadNetworkConfig("devnet")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the default addresses of the network are used, unless given as a flag
+	cfg := DefaultConfig()
+	cfg.RootPersistentDir = "data"
+	flagSet := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	cfg.RegisterAsFlags(flagSet)
+	if err = flagSet.Parse([]string{"--rpc-addr", ":9000"}); err != nil {
+		t.Fatal(err)
+	}
+	cfg.ApplyNetworkConfig(flagSet, "devnet", networkCfg)
+	if cfg.RootPersistentDir != filepath.Join("data", "devnet") || cfg.BlockchainInfo.NetworkName != "devnet" {
+		t.Fatalf("unexpected persistent directory %s of network %s", cfg.RootPersistentDir, cfg.BlockchainInfo.NetworkName)
+	}
+	if cfg.APIaddr != "localhost:23130" || cfg.RPCaddr != ":9000" {
+		t.Fatalf("unexpected addresses %s and %s", cfg.APIaddr, cfg.RPCaddr)
+	}
+}
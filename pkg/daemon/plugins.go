@@ -0,0 +1,229 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"plugin"
+	"sync"
+
+	"github.com/threefoldtech/rivine/build"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/pkg/api"
+)
+
+type (
+	// ModulePlugin is a module which does not ship with Rivine, such as a registry,
+	// bridge or custom index of a downstream chain, which is added to the daemon
+	// by registering it using RegisterModulePlugin.
+	ModulePlugin interface {
+		// Module describes the module. Its identifier enables the module using the modules flag,
+		// and its dependencies, which can be modules that ship with Rivine as well as other plugins,
+		// are loaded prior to the module.
+		Module() *Module
+		// Load creates the module, using the loaded modules it depends on.
+		Load(deps ModuleDependencies) (PluginModule, error)
+	}
+
+	// PluginModule is a module created by a ModulePlugin.
+	//
+	// Should the module implement modules.Backupper,
+	// its persistent data is backed up using the backup API of the daemon.
+	PluginModule interface {
+		// RegisterHTTPHandlers registers the API routes of the module,
+		// protecting the calls that require it using the given API password, if not empty.
+		RegisterHTTPHandlers(router api.Router, requiredPassword string)
+		// Close closes the module when the daemon stops,
+		// prior to the modules it depends on.
+		Close() error
+	}
+
+	// ModuleDependencies are the configuration of the daemon and its loaded modules,
+	// given to a ModulePlugin when loading its module.
+	// The modules which are not enabled are nil.
+	ModuleDependencies struct {
+		Config        Config
+		NetworkConfig NetworkConfig
+
+		Gateway         modules.Gateway
+		ConsensusSet    modules.ConsensusSet
+		TransactionPool modules.TransactionPool
+		Wallet          modules.Wallet
+		BlockCreator    modules.BlockCreator
+		Explorer        modules.Explorer
+
+		// Plugins are the modules of the plugins loaded so far, by identifier,
+		// containing at least all plugins the module depends on.
+		Plugins map[ModuleIdentifier]PluginModule
+	}
+)
+
+var (
+	modulePluginsMu sync.Mutex
+	modulePlugins   []ModulePlugin
+)
+
+// RegisterModulePlugin registers a plugin, adding its module to the default module set,
+// such that it can be enabled using the modules flag. It is meant to be called
+// from the init function of the package of the plugin, prior to creating the modules flag.
+//
+// RegisterModulePlugin panics if the module of the plugin is invalid,
+// or if its identifier is already used by another module.
+func RegisterModulePlugin(plugin ModulePlugin) {
+	modulePluginsMu.Lock()
+	defer modulePluginsMu.Unlock()
+	err := validateModulePlugin(plugin, modulePlugins)
+	if err != nil {
+		panic(fmt.Sprintf("failed to register module plugin: %v", err))
+	}
+	modulePlugins = append(modulePlugins, plugin)
+}
+
+// ModulePlugins returns all registered plugins, in the order they were registered.
+func ModulePlugins() []ModulePlugin {
+	modulePluginsMu.Lock()
+	defer modulePluginsMu.Unlock()
+	return append([]ModulePlugin(nil), modulePlugins...)
+}
+
+// validateModulePlugin validates that the module of the given plugin is valid,
+// and that its identifier is not used by a module that ships with Rivine, nor by a registered plugin.
+func validateModulePlugin(plugin ModulePlugin, registered []ModulePlugin) error {
+	if plugin == nil {
+		return errors.New("nil plugin")
+	}
+	mod := plugin.Module()
+	if mod == nil {
+		return errors.New("plugin has no module")
+	}
+	set := rivineModuleSet()
+	for _, p := range registered {
+		// registered modules are valid and unique
+		set.Append(p.Module())
+	}
+	return set.Append(mod)
+}
+
+// rivineModuleSet returns the module set containing all the modules that ship with Rivine.
+func rivineModuleSet() ModuleSet {
+	set, err := NewModuleSet(
+		GatewayModule,
+		ConsensusSetModule,
+		TransactionPoolModule,
+		WalletModule,
+		BlockCreatorModule,
+		ExplorerModule,
+	)
+	if err != nil {
+		panic(err)
+	}
+	return set
+}
+
+// OpenModulePlugins opens the Go plugins (see https://golang.org/pkg/plugin) with the given filenames,
+// which are expected to register their module plugins using RegisterModulePlugin when initialized.
+// Such that a daemon can be extended without rebuilding it, the plugins have to be built
+// using the same version of Go and of the packages they share with the daemon.
+func OpenModulePlugins(filenames []string) error {
+	for _, filename := range filenames {
+		n := len(ModulePlugins())
+		_, err := plugin.Open(filename)
+		if err != nil {
+			return fmt.Errorf("failed to open plugin %s: %v", filename, err)
+		}
+		if len(ModulePlugins()) == n {
+			return fmt.Errorf("plugin %s did not register any module", filename)
+		}
+	}
+	return nil
+}
+
+// LoadedModulePlugins are the modules of the plugins loaded by the daemon.
+type LoadedModulePlugins struct {
+	names   []string
+	modules []PluginModule
+}
+
+// LoadModulePlugins loads the modules of all registered plugins which are enabled by the given identifiers,
+// in dependency order, using the given dependencies, of which the Plugins are ignored.
+// Should a module fail to load, the modules already loaded are closed.
+func LoadModulePlugins(identifiers ModuleIdentifierSet, deps ModuleDependencies) (*LoadedModulePlugins, error) {
+	return loadModulePlugins(ModulePlugins(), identifiers, deps)
+}
+
+func loadModulePlugins(plugins []ModulePlugin, identifiers ModuleIdentifierSet, deps ModuleDependencies) (*LoadedModulePlugins, error) {
+	var pending []ModulePlugin
+	for _, p := range plugins {
+		if identifiers.Contains(p.Module().Identifier()) {
+			pending = append(pending, p)
+		}
+	}
+	deps.Plugins = make(map[ModuleIdentifier]PluginModule, len(pending))
+	loaded := new(LoadedModulePlugins)
+	for len(pending) > 0 {
+		// load the first plugin of which all plugin dependencies are loaded
+		idx := -1
+		for i, p := range pending {
+			if modulePluginDependenciesLoaded(p.Module(), pending) {
+				idx = i
+				break
+			}
+		}
+		if idx == -1 {
+			loaded.Close()
+			return nil, fmt.Errorf("module %s has a circular dependency", pending[0].Module().Name)
+		}
+		p := pending[idx]
+		pending = append(pending[:idx], pending[idx+1:]...)
+
+		mod := p.Module()
+		pm, err := p.Load(deps)
+		if err != nil {
+			loaded.Close()
+			return nil, fmt.Errorf("failed to load module %s: %v", mod.Name, err)
+		}
+		deps.Plugins[mod.Identifier()] = pm
+		loaded.names = append(loaded.names, mod.Name)
+		loaded.modules = append(loaded.modules, pm)
+	}
+	return loaded, nil
+}
+
+// modulePluginDependenciesLoaded returns true if none of the dependencies of the given module are pending.
+func modulePluginDependenciesLoaded(mod *Module, pending []ModulePlugin) bool {
+	for _, p := range pending {
+		if mod.Dependencies.Contains(p.Module().Identifier()) {
+			return false
+		}
+	}
+	return true
+}
+
+// Names returns the names of the loaded modules, in the order they were loaded.
+func (lmp *LoadedModulePlugins) Names() []string {
+	return append([]string(nil), lmp.names...)
+}
+
+// Modules returns the loaded modules, in the order they were loaded.
+func (lmp *LoadedModulePlugins) Modules() []PluginModule {
+	return append([]PluginModule(nil), lmp.modules...)
+}
+
+// RegisterHTTPHandlers registers the API routes of all loaded modules.
+func (lmp *LoadedModulePlugins) RegisterHTTPHandlers(router api.Router, requiredPassword string) {
+	for _, pm := range lmp.modules {
+		pm.RegisterHTTPHandlers(router, requiredPassword)
+	}
+}
+
+// Close closes all loaded modules in the reverse order they were loaded,
+// such that no module is closed prior to the modules depending on it,
+// returning the composed errors of the modules.
+func (lmp *LoadedModulePlugins) Close() error {
+	var errs []error
+	for i := len(lmp.modules) - 1; i >= 0; i-- {
+		if err := lmp.modules[i].Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close %s: %v", lmp.names[i], err))
+		}
+	}
+	return build.ComposeErrors(errs...)
+}
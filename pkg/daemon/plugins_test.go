@@ -0,0 +1,134 @@
+package daemon
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/threefoldtech/rivine/pkg/api"
+)
+
+type testModulePlugin struct {
+	module *Module
+	err    error
+	log    *[]string
+}
+
+func (p *testModulePlugin) Module() *Module { return p.module }
+
+func (p *testModulePlugin) Load(deps ModuleDependencies) (PluginModule, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	for _, id := range p.module.Dependencies.Identifiers() {
+		if _, ok := deps.Plugins[id]; !ok && rivineModuleSet().moduleForIdentifier(id) == nil {
+			return nil, errors.New("dependency not loaded: " + string(id))
+		}
+	}
+	*p.log = append(*p.log, "load "+p.module.Name)
+	return &testPluginModule{name: p.module.Name, log: p.log}, nil
+}
+
+type testPluginModule struct {
+	name string
+	log  *[]string
+}
+
+func (m *testPluginModule) RegisterHTTPHandlers(api.Router, string) {}
+
+func (m *testPluginModule) Close() error {
+	*m.log = append(*m.log, "close "+m.name)
+	return nil
+}
+
+func TestValidateModulePlugin(t *testing.T) {
+	registry := &testModulePlugin{module: &Module{Name: "Registry", Description: "registry of names"}}
+	if err := validateModulePlugin(registry, nil); err != nil {
+		t.Fatal("failed to validate plugin:", err)
+	}
+	for _, plugin := range []ModulePlugin{
+		nil,
+		&testModulePlugin{},
+		&testModulePlugin{module: &Module{Name: "Registry"}},
+		&testModulePlugin{module: &Module{Name: "Gallery", Description: "identifier of the gateway"}},
+		&testModulePlugin{module: &Module{Name: "Records", Description: "identifier of a registered plugin"}},
+		&testModulePlugin{module: &Module{Name: "1337", Description: "invalid identifier"}},
+	} {
+		if err := validateModulePlugin(plugin, []ModulePlugin{registry}); err == nil {
+			t.Errorf("expected plugin %v to be invalid", plugin)
+		}
+	}
+}
+
+func TestDefaultModuleSetPlugins(t *testing.T) {
+	defer func(plugins []ModulePlugin) { modulePlugins = plugins }(modulePlugins)
+	RegisterModulePlugin(&testModulePlugin{module: &Module{
+		Name:         "Registry",
+		Description:  "registry of names",
+		Dependencies: ForceNewIdentifierSet(ConsensusSetModule.Identifier()),
+	}})
+
+	flag := DefaultModuleSetFlag()
+	if flag.String() != "cgtwb" {
+		t.Fatal("expected the plugin not to be enabled by default, got:", flag.String())
+	}
+	if err := flag.Set("gcr"); err != nil {
+		t.Fatal("failed to enable the module of the plugin:", err)
+	}
+	if err := flag.Set("gr"); err == nil {
+		t.Fatal("expected the missing dependency of the plugin to be refused")
+	}
+}
+
+func TestLoadModulePlugins(t *testing.T) {
+	var log []string
+	plugins := []ModulePlugin{
+		&testModulePlugin{log: &log, module: &Module{
+			Name:         "Oracle",
+			Description:  "oracle depending on the registry",
+			Dependencies: ForceNewIdentifierSet(ConsensusSetModule.Identifier(), 'r'),
+		}},
+		&testModulePlugin{log: &log, module: &Module{
+			Name:         "Registry",
+			Description:  "registry of names",
+			Dependencies: ForceNewIdentifierSet(ConsensusSetModule.Identifier()),
+		}},
+		&testModulePlugin{log: &log, module: &Module{
+			Name:        "Index",
+			Description: "index, not enabled",
+		}},
+	}
+
+	loaded, err := loadModulePlugins(plugins, ForceNewIdentifierSet('g', 'c', 'o', 'r'), ModuleDependencies{})
+	if err != nil {
+		t.Fatal("failed to load plugins:", err)
+	}
+	if names := strings.Join(loaded.Names(), ","); names != "Registry,Oracle" {
+		t.Fatal("unexpected loaded plugins:", names)
+	}
+	if err = loaded.Close(); err != nil {
+		t.Fatal("failed to close plugins:", err)
+	}
+	expected := "load Registry,load Oracle,close Oracle,close Registry"
+	if strings.Join(log, ",") != expected {
+		t.Fatal("unexpected order:", strings.Join(log, ","), "!=", expected)
+	}
+
+	// the plugins already loaded are closed if a plugin fails to load
+	log = nil
+	plugins[0].(*testModulePlugin).err = errors.New("failed")
+	_, err = loadModulePlugins(plugins, ForceNewIdentifierSet('g', 'c', 'o', 'r'), ModuleDependencies{})
+	if err == nil || !strings.Contains(err.Error(), "failed to load module Oracle: failed") {
+		t.Fatal("expected the error of the oracle, got:", err)
+	}
+	if strings.Join(log, ",") != "load Registry,close Registry" {
+		t.Fatal("unexpected order:", strings.Join(log, ","))
+	}
+
+	// circular dependencies cannot be loaded
+	plugins[1].(*testModulePlugin).module.Dependencies = ForceNewIdentifierSet('o')
+	_, err = loadModulePlugins(plugins, ForceNewIdentifierSet('o', 'r'), ModuleDependencies{})
+	if err == nil || !strings.Contains(err.Error(), "circular dependency") {
+		t.Fatal("expected a circular dependency error, got:", err)
+	}
+}
@@ -1,32 +1,115 @@
 package daemon
 
 import (
+	"context"
+	"crypto/tls"
+	"fmt"
 	"net"
 	"net/http"
+	"os"
 	"strings"
+	"time"
+
+	"github.com/threefoldtech/rivine/pkg/api"
 )
 
 // HTTPServer creates and serves a HTTP server that offers communication using a REST API.
+// It can listen on multiple addresses, such as a loopback address of both IPv4 and IPv6,
+// as well as on a unix domain socket.
 type HTTPServer struct {
-	httpServer *http.Server
-	mux        *http.ServeMux
+	mux *http.ServeMux
+	// tlsConfig is the TLS config of the TCP listeners, nil if served over plain HTTP
+	tlsConfig *tls.Config
+	// listener is the listener of the address the server is created for
+	listener  net.Listener
+	listeners []httpListener
+}
+
+// httpListener is a single listener of the HTTP server, served by an http.Server of its own.
+type httpListener struct {
 	listener   net.Listener
+	httpServer *http.Server
 }
 
 // NewHTTPServer creates a new net.http server listening on bindAddr.
 func NewHTTPServer(bindAddr string) (*HTTPServer, error) {
-	l, err := net.Listen("tcp", bindAddr)
+	srv := &HTTPServer{mux: http.NewServeMux()}
+	err := srv.ListenTCP(bindAddr)
+	if err != nil {
+		return nil, err
+	}
+	return srv, nil
+}
+
+// NewHTTPSServer creates a new net.http server listening on bindAddr,
+// serving over TLS as defined by the given TLS configuration.
+// The certificate is reloaded when its files are modified.
+func NewHTTPSServer(bindAddr string, cfg TLSConfig) (*HTTPServer, error) {
+	loader, err := newTLSConfigLoader(cfg)
 	if err != nil {
 		return nil, err
 	}
-	mux := http.NewServeMux()
-	return &HTTPServer{
-		mux:      mux,
+	srv := &HTTPServer{mux: http.NewServeMux(), tlsConfig: loader.TLSConfig()}
+	err = srv.ListenTCP(bindAddr)
+	if err != nil {
+		return nil, err
+	}
+	return srv, nil
+}
+
+// ListenTCP makes the server listen on the given (IPv4 or IPv6) host:port as well,
+// e.g. "[::1]:23110", served over TLS if the server is an HTTPS server.
+// It should be called prior to serving the server.
+func (srv *HTTPServer) ListenTCP(bindAddr string) error {
+	l, err := net.Listen("tcp", bindAddr)
+	if err != nil {
+		return err
+	}
+	if srv.tlsConfig != nil {
+		l = tls.NewListener(l, srv.tlsConfig)
+	}
+	if srv.listener == nil {
+		srv.listener = l
+	}
+	srv.addListener(l, srv.mux)
+	return nil
+}
+
+// ListenUnix makes the server listen on a unix domain socket at the given path as well,
+// created with the given file permissions, which define who can call the API over the socket.
+// A stale socket at the given path, left by a daemon which did not stop cleanly, is replaced.
+// The requests received over the socket are marked as such, see api.UnixSocketHandler,
+// and are served over plain HTTP. It should be called prior to serving the server.
+func (srv *HTTPServer) ListenUnix(path string, perm os.FileMode) error {
+	if stat, err := os.Lstat(path); err == nil {
+		if stat.Mode()&os.ModeSocket == 0 {
+			return fmt.Errorf("cannot listen on unix socket %s: file exists", path)
+		}
+		if err = os.Remove(path); err != nil {
+			return err
+		}
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return err
+	}
+	err = os.Chmod(path, perm)
+	if err != nil {
+		l.Close()
+		return err
+	}
+	srv.addListener(l, api.UnixSocketHandler(srv.mux))
+	return nil
+}
+
+// addListener adds a listener, serving it using the given handler.
+func (srv *HTTPServer) addListener(l net.Listener, handler http.Handler) {
+	srv.listeners = append(srv.listeners, httpListener{
 		listener: l,
 		httpServer: &http.Server{
-			Handler: mux,
+			Handler: handler,
 		},
-	}, nil
+	})
 }
 
 // Handle the given pattern using the given handler.
@@ -34,19 +117,59 @@ func (srv *HTTPServer) Handle(pattern string, handler http.Handler) {
 	srv.mux.Handle(pattern, handler)
 }
 
-// Serve all registered endpoins as a REST API over HTTP endpoints.
+// Serve all registered endpoins as a REST API over HTTP endpoints, on all listeners.
+// Should serving any listener fail, all listeners are closed.
 func (srv *HTTPServer) Serve() error {
-	// The server will run until an error is encountered or the listener is
-	// closed, via the Close method. Closing the listener will result in the benign error handled below.
-	err := srv.httpServer.Serve(srv.listener)
-	if err != nil && !strings.HasSuffix(err.Error(), "use of closed network connection") {
-		return err
+	errs := make(chan error, len(srv.listeners))
+	for _, hl := range srv.listeners {
+		go func(hl httpListener) {
+			// The server will run until an error is encountered or the listener is
+			// closed, via the Close method. Closing the listener will result in the benign error handled below.
+			err := hl.httpServer.Serve(hl.listener)
+			if err != nil && err != http.ErrServerClosed && !strings.HasSuffix(err.Error(), "use of closed network connection") {
+				errs <- err
+				return
+			}
+			errs <- nil
+		}(hl)
 	}
-	return nil
+	var err error
+	for range srv.listeners {
+		if serveErr := <-errs; serveErr != nil && err == nil {
+			err = serveErr
+			srv.Close()
+		}
+	}
+	return err
 }
 
-// Close closes the Server's listener, causing the HTTP server to shut down.
+// Close closes the Server's listeners, causing the HTTP server to shut down.
 func (srv *HTTPServer) Close() error {
-	// Close the listener, which will cause Server.Serve() to return.
-	return srv.listener.Close()
+	// Close the listeners, which will cause Server.Serve() to return.
+	var err error
+	for _, hl := range srv.listeners {
+		if closeErr := hl.listener.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// Shutdown stops the server gracefully, closing the listeners if they aren't closed yet,
+// and waiting for the active connections to become idle, for at most the given duration,
+// after which all remaining connections are closed.
+func (srv *HTTPServer) Shutdown(timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	var err error
+	for _, hl := range srv.listeners {
+		shutdownErr := hl.httpServer.Shutdown(ctx)
+		if shutdownErr == context.DeadlineExceeded {
+			shutdownErr = hl.httpServer.Close()
+		}
+		if shutdownErr != nil && err == nil {
+			err = shutdownErr
+		}
+	}
+	return err
 }
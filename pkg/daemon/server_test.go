@@ -0,0 +1,91 @@
+package daemon
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/threefoldtech/rivine/pkg/api"
+)
+
+func TestHTTPServerListeners(t *testing.T) {
+	dir, err := ioutil.TempDir("", "server")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	srv, err := NewHTTPServer("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+	// not all test environments support IPv6
+	ipv6 := srv.ListenTCP("[::1]:0") == nil
+	socket := filepath.Join(dir, "api.sock")
+	// a stale socket is replaced
+	if stale, err := net.Listen("unix", socket); err != nil {
+		t.Fatal(err)
+	} else {
+		stale.(*net.UnixListener).SetUnlinkOnClose(false)
+		stale.Close()
+	}
+	err = srv.ListenUnix(socket, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stat, err := os.Stat(socket); err != nil || stat.Mode().Perm() != 0600 {
+		t.Fatalf("unexpected unix socket permissions: %v, %v", stat, err)
+	}
+	srv.Handle("/", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if api.IsUnixSocketRequest(req) {
+			w.Write([]byte("unix"))
+			return
+		}
+		w.Write([]byte("tcp"))
+	}))
+	go srv.Serve()
+
+	get := func(client *http.Client, url string) string {
+		resp, err := client.Get(url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		b, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+	if body := get(http.DefaultClient, "http://"+srv.listener.Addr().String()); body != "tcp" {
+		t.Errorf("unexpected response over IPv4: %s", body)
+	}
+	if ipv6 {
+		if body := get(http.DefaultClient, "http://"+srv.listeners[1].listener.Addr().String()); body != "tcp" {
+			t.Errorf("unexpected response over IPv6: %s", body)
+		}
+	}
+	unixClient := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", socket)
+		},
+	}}
+	if body := get(unixClient, "http://unix/"); body != "unix" {
+		t.Errorf("unexpected response over the unix socket: %s", body)
+	}
+
+	// regular files are not replaced
+	file := filepath.Join(dir, "file")
+	if err = ioutil.WriteFile(file, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err = srv.ListenUnix(file, 0600); err == nil {
+		t.Error("expected listening on a regular file to fail")
+	}
+}
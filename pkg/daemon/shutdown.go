@@ -0,0 +1,198 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/threefoldtech/rivine/build"
+)
+
+// ShutdownStage defines when a component of the daemon is closed during shutdown.
+// The components of a stage are only closed once all components of the earlier stages are closed,
+// such that no component is closed while a component depending on it is still running.
+type ShutdownStage int
+
+const (
+	// ShutdownStageAPI is the stage of the API server,
+	// closed first, such that no more calls are made into the modules.
+	ShutdownStageAPI ShutdownStage = iota
+	// ShutdownStagePlugins is the stage of the modules of the plugins,
+	// which can depend on all modules that ship with Rivine.
+	ShutdownStagePlugins
+	// ShutdownStageBlockCreator is the stage of the block creator,
+	// which submits blocks and transactions to the other modules.
+	ShutdownStageBlockCreator
+	// ShutdownStageTransactionPool is the stage of the transaction pool.
+	ShutdownStageTransactionPool
+	// ShutdownStageConsensusSubscribers is the stage of the modules subscribed
+	// to the consensus set and the transaction pool, such as the wallet and the explorer,
+	// closed concurrently.
+	ShutdownStageConsensusSubscribers
+	// ShutdownStageConsensus is the stage of the consensus set.
+	ShutdownStageConsensus
+	// ShutdownStageGateway is the stage of the gateway,
+	// closed last, as all other modules depend on it.
+	ShutdownStageGateway
+)
+
+// ErrShutdownDeadline is returned by Shutdown.Close if the components
+// of the daemon could not be closed in time.
+var ErrShutdownDeadline = errors.New("shutdown deadline exceeded")
+
+type shutdownComponent struct {
+	stage ShutdownStage
+	name  string
+	close func() error
+}
+
+// Shutdown closes the components of the daemon in order of their shutdown stage,
+// reporting how long it took to close each component. The modules flush their pending
+// writes to their databases when closed, hence all components have to be closed
+// before the daemon exits. Should a component not close in time, the components which
+// are still closing, and the stack traces of all goroutines, are reported, such that the hang can be diagnosed.
+type Shutdown struct {
+	mu         sync.Mutex
+	components []shutdownComponent
+
+	deadline time.Duration
+	w        io.Writer
+
+	once sync.Once
+	err  error
+}
+
+// NewShutdown creates a new Shutdown, reporting to the given writer,
+// giving up on closing the components once the given deadline is exceeded,
+// or never if the deadline is 0.
+func NewShutdown(deadline time.Duration, w io.Writer) *Shutdown {
+	return &Shutdown{
+		deadline: deadline,
+		w:        w,
+	}
+}
+
+// Add a component to be closed during the given stage of the shutdown.
+func (s *Shutdown) Add(stage ShutdownStage, name string, close func() error) {
+	s.mu.Lock()
+	s.components = append(s.components, shutdownComponent{
+		stage: stage,
+		name:  name,
+		close: close,
+	})
+	s.mu.Unlock()
+}
+
+// Close all added components, stage by stage, returning the composed errors of the components.
+// Close only shuts down once, consecutive calls return the same error.
+func (s *Shutdown) Close() error {
+	s.once.Do(func() {
+		s.err = s.shutdown()
+	})
+	return s.err
+}
+
+func (s *Shutdown) shutdown() error {
+	s.mu.Lock()
+	components := make([]shutdownComponent, len(s.components))
+	copy(components, s.components)
+	s.mu.Unlock()
+	// components of the same stage are closed in the order they were added
+	sort.SliceStable(components, func(i, j int) bool {
+		return components[i].stage < components[j].stage
+	})
+
+	var deadline <-chan time.Time
+	if s.deadline > 0 {
+		timer := time.NewTimer(s.deadline)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	start := time.Now()
+
+	var (
+		mu       sync.Mutex
+		errs     []error
+		closing  = make(map[string]struct{})
+		timedOut bool
+	)
+	for len(components) > 0 {
+		// all components of the next stage are closed concurrently
+		n := 1
+		for n < len(components) && components[n].stage == components[0].stage {
+			n++
+		}
+		var wg sync.WaitGroup
+		for _, c := range components[:n] {
+			fmt.Fprintf(s.w, "Closing %s...\n", c.name)
+			mu.Lock()
+			closing[c.name] = struct{}{}
+			mu.Unlock()
+			wg.Add(1)
+			go func(c shutdownComponent) {
+				defer wg.Done()
+				componentStart := time.Now()
+				err := c.close()
+				mu.Lock()
+				defer mu.Unlock()
+				delete(closing, c.name)
+				if timedOut {
+					// the shutdown is already reported as failed
+					return
+				}
+				if err != nil {
+					fmt.Fprintf(s.w, "Error during %s shutdown: %v\n", c.name, err)
+					errs = append(errs, fmt.Errorf("failed to close %s: %v", c.name, err))
+					return
+				}
+				fmt.Fprintf(s.w, "Closed %s in %v\n", c.name, time.Since(componentStart))
+			}(c)
+		}
+		components = components[n:]
+
+		done := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-deadline:
+			mu.Lock()
+			defer mu.Unlock()
+			timedOut = true
+			names := make([]string, 0, len(closing))
+			for name := range closing {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			fmt.Fprintf(s.w, "Shutdown did not complete within %v, still closing: %s\n", s.deadline, strings.Join(names, ", "))
+			if len(components) > 0 {
+				names = names[:0]
+				for _, c := range components {
+					names = append(names, c.name)
+				}
+				fmt.Fprintf(s.w, "Not closed: %s\n", strings.Join(names, ", "))
+			}
+			s.writeGoroutines()
+			return ErrShutdownDeadline
+		}
+	}
+	fmt.Fprintf(s.w, "Shutdown completed in %v\n", time.Since(start))
+	return build.ComposeErrors(errs...)
+}
+
+// writeGoroutines writes the stack traces of all goroutines,
+// in the format of an unrecovered panic, such that a hang can be diagnosed.
+func (s *Shutdown) writeGoroutines() {
+	fmt.Fprintln(s.w, "Goroutines:")
+	err := pprof.Lookup("goroutine").WriteTo(s.w, 2)
+	if err != nil {
+		fmt.Fprintln(s.w, "Failed to write the goroutines:", err)
+	}
+}
@@ -0,0 +1,81 @@
+package daemon
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestShutdownOrder(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		closed []string
+	)
+	closer := func(name string, err error) func() error {
+		return func() error {
+			mu.Lock()
+			closed = append(closed, name)
+			mu.Unlock()
+			return err
+		}
+	}
+	var buf bytes.Buffer
+	s := NewShutdown(0, &buf)
+	// added in the order the daemon loads the modules
+	s.Add(ShutdownStageAPI, "API server", closer("API server", nil))
+	s.Add(ShutdownStageGateway, "gateway", closer("gateway", nil))
+	s.Add(ShutdownStageConsensus, "consensus set", closer("consensus set", nil))
+	s.Add(ShutdownStageTransactionPool, "transaction pool", closer("transaction pool", nil))
+	s.Add(ShutdownStageConsensusSubscribers, "wallet", closer("wallet", errors.New("failed")))
+	s.Add(ShutdownStageBlockCreator, "block creator", closer("block creator", nil))
+
+	err := s.Close()
+	if err == nil || !strings.Contains(err.Error(), "failed to close wallet: failed") {
+		t.Fatal("expected the error of the wallet, got:", err)
+	}
+	expected := []string{"API server", "block creator", "transaction pool", "wallet", "consensus set", "gateway"}
+	if strings.Join(closed, ",") != strings.Join(expected, ",") {
+		t.Errorf("unexpected shutdown order %v", closed)
+	}
+	if output := buf.String(); !strings.Contains(output, "Closed gateway in ") || !strings.Contains(output, "Shutdown completed in ") {
+		t.Errorf("unexpected output %q", output)
+	}
+
+	// the components are only closed once
+	if err2 := s.Close(); err2 != err || len(closed) != len(expected) {
+		t.Errorf("expected the shutdown to happen only once, got %v, %v", err2, closed)
+	}
+}
+
+func TestShutdownDeadline(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	var gatewayClosed bool
+	var buf bytes.Buffer
+	s := NewShutdown(50*time.Millisecond, &buf)
+	s.Add(ShutdownStageConsensusSubscribers, "wallet", func() error {
+		<-release
+		return nil
+	})
+	s.Add(ShutdownStageConsensusSubscribers, "explorer", func() error { return nil })
+	s.Add(ShutdownStageGateway, "gateway", func() error {
+		gatewayClosed = true
+		return nil
+	})
+
+	if err := s.Close(); err != ErrShutdownDeadline {
+		t.Fatal("expected the deadline to be exceeded, got:", err)
+	}
+	if gatewayClosed {
+		t.Error("expected the gateway not to be closed while the wallet is closing")
+	}
+	output := buf.String()
+	for _, expected := range []string{"still closing: wallet\n", "Not closed: gateway\n", "Goroutines:", "TestShutdownDeadline"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected the output to contain %q, got %q", expected, output)
+		}
+	}
+}
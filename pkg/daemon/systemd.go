@@ -0,0 +1,150 @@
+package daemon
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The states a daemon can notify systemd of, see sd_notify(3).
+const (
+	SystemdReady    = "READY=1"
+	SystemdStopping = "STOPPING=1"
+	SystemdWatchdog = "WATCHDOG=1"
+)
+
+// systemdStatusInterval is the interval at which the state of the daemon
+// is checked and reported to systemd, unless the watchdog requires a shorter interval.
+const systemdStatusInterval = 5 * time.Second
+
+// SystemdNotifier notifies systemd of the state of the daemon, using the sd_notify protocol,
+// such that a unit of Type=notify is only considered started once the daemon is ready,
+// and the daemon is restarted by systemd if it stops servicing the watchdog (WatchdogSec).
+type SystemdNotifier struct {
+	socket           string
+	watchdogInterval time.Duration
+	interval         time.Duration
+
+	running  bool
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewSystemdNotifier creates a notifier for the systemd service manager which started the daemon,
+// as defined by the NOTIFY_SOCKET, WATCHDOG_USEC and WATCHDOG_PID environment variables.
+// Nil is returned, without error, if the daemon was not started by systemd as a notify service.
+func NewSystemdNotifier() (*SystemdNotifier, error) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return nil, nil
+	}
+	var watchdogInterval time.Duration
+	if usec := os.Getenv("WATCHDOG_USEC"); usec != "" {
+		n, err := strconv.ParseUint(usec, 10, 64)
+		if err != nil || n == 0 {
+			return nil, fmt.Errorf("invalid WATCHDOG_USEC %q", usec)
+		}
+		watchdogInterval = time.Duration(n) * time.Microsecond
+		// the watchdog can be meant for another process, such as a wrapper script
+		if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+			watchdogInterval = 0
+		}
+	}
+	return newSystemdNotifier(socket, watchdogInterval), nil
+}
+
+func newSystemdNotifier(socket string, watchdogInterval time.Duration) *SystemdNotifier {
+	interval := systemdStatusInterval
+	// service the watchdog twice within its timeout, as recommended by sd_watchdog_enabled(3)
+	if watchdogInterval > 0 && watchdogInterval/2 < interval {
+		interval = watchdogInterval / 2
+	}
+	return &SystemdNotifier{
+		socket:           socket,
+		watchdogInterval: watchdogInterval,
+		interval:         interval,
+		stop:             make(chan struct{}),
+		done:             make(chan struct{}),
+	}
+}
+
+// WatchdogInterval returns the timeout of the systemd watchdog, 0 if it is disabled.
+func (n *SystemdNotifier) WatchdogInterval() time.Duration {
+	return n.watchdogInterval
+}
+
+// Notify systemd of the given states, e.g. SystemdReady or STATUS=<text>.
+func (n *SystemdNotifier) Notify(states ...string) error {
+	addr := &net.UnixAddr{Name: n.socket, Net: "unixgram"}
+	if strings.HasPrefix(addr.Name, "@") {
+		// abstract socket
+		addr.Name = "\x00" + addr.Name[1:]
+	}
+	conn, err := net.DialUnix("unixgram", nil, addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = conn.Write([]byte(strings.Join(states, "\n")))
+	return err
+}
+
+// Run checks the state of the daemon periodically using the given function, until the notifier is closed,
+// notifying systemd that the daemon is ready once it is, as well as of its status,
+// and servicing the watchdog after every check. A check which does not return thus
+// stops the watchdog from being serviced, such that a hanging daemon is restarted.
+func (n *SystemdNotifier) Run(check func() (ready bool, status string)) {
+	n.running = true
+	go func() {
+		defer close(n.done)
+		ticker := time.NewTicker(n.interval)
+		defer ticker.Stop()
+		var (
+			notifiedReady bool
+			lastStatus    string
+		)
+		for {
+			ready, status := check()
+			var states []string
+			if ready && !notifiedReady {
+				states = append(states, SystemdReady)
+			}
+			if status != lastStatus {
+				states = append(states, "STATUS="+status)
+			}
+			if n.watchdogInterval > 0 {
+				states = append(states, SystemdWatchdog)
+			}
+			if len(states) > 0 {
+				if err := n.Notify(states...); err != nil {
+					fmt.Println("Failed to notify systemd:", err)
+				} else {
+					notifiedReady = notifiedReady || ready
+					lastStatus = status
+				}
+			}
+			select {
+			case <-n.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Close stops checking the state of the daemon, if running,
+// and notifies systemd that the daemon is stopping.
+func (n *SystemdNotifier) Close() error {
+	n.stopOnce.Do(func() {
+		close(n.stop)
+	})
+	if n.running {
+		<-n.done
+	}
+	return n.Notify(SystemdStopping)
+}
@@ -0,0 +1,92 @@
+package daemon
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSystemdNotifier(t *testing.T) {
+	dir, err := ioutil.TempDir("", "systemd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	receive := func() string {
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		buf := make([]byte, 1024)
+		n, err := conn.Read(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(buf[:n])
+	}
+
+	// the watchdog is serviced twice within its timeout
+	n := newSystemdNotifier(socket, 100*time.Millisecond)
+	if n.interval != 50*time.Millisecond {
+		t.Fatal("unexpected interval", n.interval)
+	}
+	var ready int32
+	n.Run(func() (bool, string) {
+		if atomic.LoadInt32(&ready) == 0 {
+			return false, "Syncing"
+		}
+		return true, "Synced"
+	})
+	if msg := receive(); msg != "STATUS=Syncing\n"+SystemdWatchdog {
+		t.Fatalf("unexpected notification %q", msg)
+	}
+	if msg := receive(); msg != SystemdWatchdog {
+		t.Fatalf("unexpected notification %q", msg)
+	}
+	// ready is notified only once
+	atomic.StoreInt32(&ready, 1)
+	for {
+		msg := receive()
+		if msg == SystemdWatchdog {
+			continue
+		}
+		if msg != SystemdReady+"\nSTATUS=Synced\n"+SystemdWatchdog {
+			t.Fatalf("unexpected notification %q", msg)
+		}
+		break
+	}
+	if msg := receive(); msg != SystemdWatchdog {
+		t.Fatalf("unexpected notification %q", msg)
+	}
+
+	if err := n.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// drain the pending watchdog notifications
+	for {
+		msg := receive()
+		if msg == SystemdStopping {
+			break
+		}
+		if msg != SystemdWatchdog {
+			t.Fatalf("unexpected notification %q", msg)
+		}
+	}
+}
+
+func TestNewSystemdNotifierDisabled(t *testing.T) {
+	if os.Getenv("NOTIFY_SOCKET") != "" {
+		t.Skip("started by systemd")
+	}
+	n, err := NewSystemdNotifier()
+	if err != nil || n != nil {
+		t.Fatalf("expected no notifier, got %v, %v", n, err)
+	}
+}
@@ -0,0 +1,168 @@
+package daemon
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+// TLSConfig defines the TLS termination of the HTTP server.
+type TLSConfig struct {
+	// CertFile and KeyFile are the paths of the PEM-encoded certificate (chain)
+	// and private key presented by the server.
+	CertFile string
+	KeyFile  string
+	// ClientCAFile is the optional path of the PEM-encoded certificates of the CAs
+	// of which clients have to present a certificate. Client certificates are not
+	// requested if empty.
+	ClientCAFile string
+}
+
+// Enabled returns true if TLS is configured.
+func (cfg TLSConfig) Enabled() bool {
+	return cfg.CertFile != "" || cfg.KeyFile != "" || cfg.ClientCAFile != ""
+}
+
+// Validate checks that the TLS configuration is complete.
+func (cfg TLSConfig) Validate() error {
+	if !cfg.Enabled() {
+		return nil
+	}
+	if cfg.CertFile == "" || cfg.KeyFile == "" {
+		return errors.New("both a TLS certificate and key are required to serve the API over TLS")
+	}
+	return nil
+}
+
+// tlsFileState is the state of a file, used to detect when it is modified.
+type tlsFileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// tlsConfigLoader loads the TLS config of the server from its files,
+// reloading it when any of the files is modified, such that certificates
+// can be rotated without restarting the daemon.
+type tlsConfigLoader struct {
+	cfg TLSConfig
+
+	mu     sync.Mutex
+	config *tls.Config
+	states []tlsFileState
+}
+
+// newTLSConfigLoader creates a loader for the given TLS configuration,
+// returning an error if it cannot be loaded.
+func newTLSConfigLoader(cfg TLSConfig) (*tlsConfigLoader, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	loader := &tlsConfigLoader{cfg: cfg}
+	states, err := loader.stat()
+	if err != nil {
+		return nil, err
+	}
+	loader.config, err = loader.load()
+	if err != nil {
+		return nil, err
+	}
+	loader.states = states
+	return loader, nil
+}
+
+// TLSConfig returns the config of the TLS listener, which returns the
+// config loaded by the loader for each connection.
+func (loader *tlsConfigLoader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return loader.current(), nil
+		},
+	}
+}
+
+// current returns the current TLS config, reloading it first if any of its files
+// got modified. The previous config remains in use if the modified files cannot be loaded,
+// e.g. because only the certificate has been replaced so far, until they are modified again.
+func (loader *tlsConfigLoader) current() *tls.Config {
+	loader.mu.Lock()
+	defer loader.mu.Unlock()
+	states, err := loader.stat()
+	if err != nil || tlsFileStatesEqual(states, loader.states) {
+		return loader.config
+	}
+	loader.states = states
+	config, err := loader.load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to reload the TLS certificate of the API, keeping the previous one:", err)
+		return loader.config
+	}
+	loader.config = config
+	fmt.Println("Reloaded the TLS certificate of the API")
+	return config
+}
+
+// files returns the paths of all files of the TLS configuration.
+func (loader *tlsConfigLoader) files() []string {
+	files := []string{loader.cfg.CertFile, loader.cfg.KeyFile}
+	if loader.cfg.ClientCAFile != "" {
+		files = append(files, loader.cfg.ClientCAFile)
+	}
+	return files
+}
+
+// stat returns the current state of all files of the TLS configuration.
+func (loader *tlsConfigLoader) stat() ([]tlsFileState, error) {
+	var states []tlsFileState
+	for _, file := range loader.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		states = append(states, tlsFileState{modTime: info.ModTime(), size: info.Size()})
+	}
+	return states, nil
+}
+
+// load loads the TLS config from its files.
+func (loader *tlsConfigLoader) load() (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(loader.cfg.CertFile, loader.cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificate: %v", err)
+	}
+	config := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+	}
+	if loader.cfg.ClientCAFile != "" {
+		b, err := ioutil.ReadFile(loader.cfg.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read TLS client CA file: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(b) {
+			return nil, fmt.Errorf("invalid TLS client CA file %s: no PEM-encoded certificates found", loader.cfg.ClientCAFile)
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return config, nil
+}
+
+// tlsFileStatesEqual returns true if the given file states are equal.
+func tlsFileStatesEqual(a, b []tlsFileState) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].modTime.Equal(b[i].modTime) || a[i].size != b[i].size {
+			return false
+		}
+	}
+	return true
+}
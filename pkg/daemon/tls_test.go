@@ -0,0 +1,212 @@
+package daemon
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testCertificate is a certificate generated for testing purposes.
+type testCertificate struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+// newTestCertificate creates a certificate for localhost with the given common name,
+// signed by the given CA, or self-signed if the CA is nil.
+func newTestCertificate(t *testing.T, commonName string, ca *testCertificate) *testCertificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: commonName},
+		DNSNames:              []string{"localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  ca == nil,
+	}
+	parent, parentKey := template, key
+	if ca != nil {
+		parent, parentKey = ca.cert, ca.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCertificate{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+// write writes the certificate and key to the given files,
+// modified at the given time, such that reloads are detected reliably.
+func (c *testCertificate) write(t *testing.T, certFile, keyFile string, modTime time.Time) {
+	for file, b := range map[string][]byte{certFile: c.certPEM, keyFile: c.keyPEM} {
+		if err := ioutil.WriteFile(file, b, 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(file, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// serveTestHTTPS serves an HTTPS server using the given TLS configuration, returning its address.
+func serveTestHTTPS(t *testing.T, cfg TLSConfig) (*HTTPServer, string) {
+	srv, err := NewHTTPSServer("localhost:0", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.Handle("/", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	go srv.Serve()
+	return srv, srv.listener.Addr().String()
+}
+
+// dialTestHTTPS returns the common name of the certificate presented by the server,
+// optionally presenting the given client certificate.
+func dialTestHTTPS(addr string, client *testCertificate) (string, error) {
+	config := &tls.Config{InsecureSkipVerify: true}
+	if client != nil {
+		config.Certificates = []tls.Certificate{{
+			Certificate: [][]byte{client.cert.Raw},
+			PrivateKey:  client.key,
+		}}
+	}
+	conn, err := tls.Dial("tcp", addr, config)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	// the handshake of a TLS 1.3 client completes before the server verified its certificate,
+	// such that a refused client certificate is only reported when reading
+	_, err = conn.Write([]byte("GET / HTTP/1.0\r\n\r\n"))
+	if err == nil {
+		_, err = conn.Read(make([]byte, 1))
+	}
+	if err != nil {
+		return "", err
+	}
+	return conn.ConnectionState().PeerCertificates[0].Subject.CommonName, nil
+}
+
+func TestTLSConfigValidate(t *testing.T) {
+	for _, cfg := range []TLSConfig{{}, {CertFile: "a.crt", KeyFile: "a.key"}, {CertFile: "a.crt", KeyFile: "a.key", ClientCAFile: "ca.crt"}} {
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("%+v: %v", cfg, err)
+		}
+	}
+	for _, cfg := range []TLSConfig{{CertFile: "a.crt"}, {KeyFile: "a.key"}, {ClientCAFile: "ca.crt"}} {
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("%+v: expected an error", cfg)
+		}
+	}
+	if (TLSConfig{}).Enabled() || !(TLSConfig{KeyFile: "a.key"}).Enabled() {
+		t.Error("unexpected enabled state")
+	}
+}
+
+// TestHTTPSServerReload ensures a rotated certificate is served to new connections,
+// while an invalid certificate is ignored.
+func TestHTTPSServerReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	certFile, keyFile := filepath.Join(dir, "api.crt"), filepath.Join(dir, "api.key")
+	modTime := time.Now().Add(-time.Minute)
+	newTestCertificate(t, "first", nil).write(t, certFile, keyFile, modTime)
+
+	srv, addr := serveTestHTTPS(t, TLSConfig{CertFile: certFile, KeyFile: keyFile})
+	defer srv.Close()
+	if name, err := dialTestHTTPS(addr, nil); err != nil || name != "first" {
+		t.Fatalf("expected the first certificate, got %q: %v", name, err)
+	}
+
+	// rotate the certificate
+	newTestCertificate(t, "second", nil).write(t, certFile, keyFile, modTime.Add(time.Second))
+	if name, err := dialTestHTTPS(addr, nil); err != nil || name != "second" {
+		t.Fatalf("expected the rotated certificate, got %q: %v", name, err)
+	}
+
+	// an invalid certificate is not loaded
+	err = ioutil.WriteFile(certFile, []byte("invalid"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chtimes(certFile, modTime.Add(2*time.Second), modTime.Add(2*time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if name, err := dialTestHTTPS(addr, nil); err != nil || name != "second" {
+		t.Fatalf("expected the previous certificate to remain in use, got %q: %v", name, err)
+	}
+
+	// the server does not start with an invalid certificate
+	if _, err = NewHTTPSServer("localhost:0", TLSConfig{CertFile: certFile, KeyFile: keyFile}); err == nil {
+		t.Fatal("expected an error for an invalid certificate")
+	}
+}
+
+// TestHTTPSServerClientCA ensures only clients presenting a certificate
+// signed by the configured CA are accepted.
+func TestHTTPSServerClientCA(t *testing.T) {
+	dir, err := ioutil.TempDir("", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	certFile, keyFile := filepath.Join(dir, "api.crt"), filepath.Join(dir, "api.key")
+	caFile := filepath.Join(dir, "ca.crt")
+	newTestCertificate(t, "server", nil).write(t, certFile, keyFile, time.Now())
+	ca := newTestCertificate(t, "ca", nil)
+	if err = ioutil.WriteFile(caFile, ca.certPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	srv, addr := serveTestHTTPS(t, TLSConfig{CertFile: certFile, KeyFile: keyFile, ClientCAFile: caFile})
+	defer srv.Close()
+	if _, err = dialTestHTTPS(addr, newTestCertificate(t, "client", ca)); err != nil {
+		t.Fatal("expected a client certificate signed by the CA to be accepted:", err)
+	}
+	if _, err = dialTestHTTPS(addr, nil); err == nil {
+		t.Fatal("expected a client without certificate to be refused")
+	}
+	if _, err = dialTestHTTPS(addr, newTestCertificate(t, "other", nil)); err == nil {
+		t.Fatal("expected a client certificate not signed by the CA to be refused")
+	}
+}
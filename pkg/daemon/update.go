@@ -0,0 +1,186 @@
+package daemon
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/threefoldtech/rivine/build"
+	"github.com/threefoldtech/rivine/types"
+)
+
+const (
+	// updateManifestMaxSize is the maximum size of an update manifest.
+	updateManifestMaxSize = 64 << 10
+	// updateCheckTimeout is the maximum duration of a single update check.
+	updateCheckTimeout = 30 * time.Second
+)
+
+// UpdateManifest announces the latest release of a chain,
+// published by its maintainers as part of a SignedUpdateManifest.
+type UpdateManifest struct {
+	ChainName string                `json:"chainname"`
+	Version   build.ProtocolVersion `json:"version"`
+	Released  types.Timestamp       `json:"released"`
+	URL       string                `json:"url,omitempty"`
+	Notes     string                `json:"notes,omitempty"`
+}
+
+// SignedUpdateManifest is an update manifest, as published by the maintainers of a chain,
+// signed using the key pair of the address trusted by the daemons to publish updates.
+// The signature is a message signature, see types.SignMessage, of the compact JSON manifest
+// (without insignificant whitespace), such that it can be created using any wallet (e.g. using /wallet/signmessage).
+type SignedUpdateManifest struct {
+	Manifest  json.RawMessage        `json:"manifest"`
+	Signature types.MessageSignature `json:"signature"`
+}
+
+// Verify verifies that the manifest is signed using the key pair of the given address,
+// returning the decoded manifest if so.
+func (sm SignedUpdateManifest) Verify(signer types.UnlockHash) (UpdateManifest, error) {
+	if len(sm.Manifest) == 0 {
+		return UpdateManifest{}, errors.New("no update manifest defined")
+	}
+	var compact bytes.Buffer
+	err := json.Compact(&compact, sm.Manifest)
+	if err != nil {
+		return UpdateManifest{}, fmt.Errorf("invalid update manifest: %v", err)
+	}
+	err = sm.Signature.Verify(signer, compact.Bytes())
+	if err != nil {
+		return UpdateManifest{}, fmt.Errorf("invalid update manifest signature: %v", err)
+	}
+	var manifest UpdateManifest
+	err = json.Unmarshal(sm.Manifest, &manifest)
+	if err != nil {
+		return UpdateManifest{}, fmt.Errorf("invalid update manifest: %v", err)
+	}
+	return manifest, nil
+}
+
+// UpdateStatus is the result of the last update check of a daemon.
+type UpdateStatus struct {
+	Checked types.Timestamp `json:"checked"`
+	// Manifest is the last manifest which was verified, if any.
+	Manifest *UpdateManifest `json:"manifest,omitempty"`
+	// Available is true if the manifest announces a more recent version than the running one.
+	Available bool `json:"available"`
+	// Error is the reason the last check failed, if it did.
+	Error string `json:"error,omitempty"`
+}
+
+// UpdateChecker periodically checks a signed update manifest,
+// published by the maintainers of the chain, for a more recent version of the daemon.
+type UpdateChecker struct {
+	url       string
+	signer    types.UnlockHash
+	chainName string
+	current   build.ProtocolVersion
+	interval  time.Duration
+	client    *http.Client
+
+	mu     sync.Mutex
+	status UpdateStatus
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewUpdateChecker creates an update checker, checking the manifest at the given URL
+// at the given interval, trusting only manifests signed using the key pair of the given address.
+func NewUpdateChecker(url string, signer types.UnlockHash, info types.BlockchainInfo, interval time.Duration) *UpdateChecker {
+	return &UpdateChecker{
+		url:       url,
+		signer:    signer,
+		chainName: info.Name,
+		current:   info.ChainVersion,
+		interval:  interval,
+		client:    &http.Client{Timeout: updateCheckTimeout},
+		stop:      make(chan struct{}),
+	}
+}
+
+// Run checks the update manifest periodically, until the checker is closed.
+func (uc *UpdateChecker) Run() {
+	go func() {
+		ticker := time.NewTicker(uc.interval)
+		defer ticker.Stop()
+		for {
+			if status := uc.Check(); status.Error != "" {
+				fmt.Println("Failed to check for updates:", status.Error)
+			}
+			select {
+			case <-uc.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Close stops checking the update manifest.
+func (uc *UpdateChecker) Close() error {
+	uc.stopOnce.Do(func() {
+		close(uc.stop)
+	})
+	return nil
+}
+
+// Status returns the result of the last update check.
+func (uc *UpdateChecker) Status() UpdateStatus {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	return uc.status
+}
+
+// Check fetches and verifies the update manifest, returning the resulting status.
+// The last verified manifest is retained should the check fail.
+func (uc *UpdateChecker) Check() UpdateStatus {
+	manifest, err := uc.fetch()
+
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	uc.status.Checked = types.CurrentTimestamp()
+	uc.status.Error = ""
+	if err != nil {
+		uc.status.Error = err.Error()
+		return uc.status
+	}
+	uc.status.Manifest = &manifest
+	uc.status.Available = manifest.Version.Compare(uc.current) > 0
+	return uc.status
+}
+
+// fetch fetches the update manifest, verifying its signature and that it announces a release of our chain.
+func (uc *UpdateChecker) fetch() (UpdateManifest, error) {
+	resp, err := uc.client.Get(uc.url)
+	if err != nil {
+		return UpdateManifest{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return UpdateManifest{}, fmt.Errorf("failed to fetch update manifest: %s", resp.Status)
+	}
+	b, err := ioutil.ReadAll(http.MaxBytesReader(nil, resp.Body, updateManifestMaxSize))
+	if err != nil {
+		return UpdateManifest{}, fmt.Errorf("failed to read update manifest: %v", err)
+	}
+	var sm SignedUpdateManifest
+	err = json.Unmarshal(b, &sm)
+	if err != nil {
+		return UpdateManifest{}, fmt.Errorf("invalid signed update manifest: %v", err)
+	}
+	manifest, err := sm.Verify(uc.signer)
+	if err != nil {
+		return UpdateManifest{}, err
+	}
+	if manifest.ChainName != uc.chainName {
+		return UpdateManifest{}, fmt.Errorf("update manifest announces a release of %q rather than %q", manifest.ChainName, uc.chainName)
+	}
+	return manifest, nil
+}
@@ -0,0 +1,71 @@
+package daemon
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/threefoldtech/rivine/build"
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/types"
+)
+
+func TestUpdateChecker(t *testing.T) {
+	sk, pk := crypto.GenerateKeyPair()
+	signer := types.NewPubKeyUnlockHash(types.Ed25519PublicKey(pk))
+	otherSK, _ := crypto.GenerateKeyPair()
+
+	var published []byte
+	publish := func(manifest string, sk crypto.SecretKey) {
+		// the compact manifest is signed, regardless of its formatting
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, []byte(manifest)); err != nil {
+			t.Fatal(err)
+		}
+		b, err := json.Marshal(SignedUpdateManifest{
+			Manifest:  json.RawMessage(manifest),
+			Signature: types.SignMessage(compact.Bytes(), sk),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		published = b
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write(published)
+	}))
+	defer srv.Close()
+
+	info := types.BlockchainInfo{Name: "rivine", ChainVersion: build.MustParse("1.0.7")}
+	uc := NewUpdateChecker(srv.URL, signer, info, time.Hour)
+
+	// the same version is not an update
+	publish(`{"chainname":"rivine","version":"1.0.7"}`, sk)
+	status := uc.Check()
+	if status.Error != "" || status.Available || status.Manifest == nil || status.Checked == 0 {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+	// a more recent version is
+	publish(`{"chainname":"rivine", "version":"1.0.8", "url":"https://example.org"}`, sk)
+	status = uc.Check()
+	if status.Error != "" || !status.Available || status.Manifest.URL != "https://example.org" {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+
+	// manifests of another signer or chain are refused, retaining the last verified manifest
+	publish(`{"chainname":"rivine","version":"9.0.0"}`, otherSK)
+	status = uc.Check()
+	if status.Error == "" || status.Manifest == nil || status.Manifest.Version.String() != "1.0.8" {
+		t.Fatalf("expected a manifest of another signer to be refused: %+v", status)
+	}
+	publish(`{"chainname":"tfchain","version":"9.0.0"}`, sk)
+	if status = uc.Check(); status.Error == "" {
+		t.Fatalf("expected a manifest of another chain to be refused: %+v", status)
+	}
+	if uc.Status() != status {
+		t.Fatal("expected the status of the last check to be returned")
+	}
+}
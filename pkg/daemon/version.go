@@ -1,9 +1,59 @@
 package daemon
 
-import "github.com/threefoldtech/rivine/build"
+import (
+	"runtime"
+
+	"github.com/threefoldtech/rivine/build"
+	"github.com/threefoldtech/rivine/types"
+)
 
 // Version defines the version of a daemon.
 type Version struct {
 	ChainVersion    build.ProtocolVersion `json:"version"`
 	ProtocolVersion build.ProtocolVersion `json:"protocolVersion"`
+
+	// the chain and network the daemon is part of,
+	// identified by the ID of the genesis block
+	ChainName   string        `json:"chainname"`
+	NetworkName string        `json:"networkname"`
+	GenesisID   types.BlockID `json:"genesisid"`
+	// the names of the modules loaded by the daemon
+	Modules []string `json:"modules"`
+	// metadata of the binary of the daemon
+	Build BuildInfo `json:"build"`
+	// the result of the last update check, if enabled
+	Update *UpdateStatus `json:"update,omitempty"`
+}
+
+// BuildInfo contains the metadata of the binary of a daemon.
+type BuildInfo struct {
+	// Release is the release the daemon is built for: standard, testing or dev.
+	Release string `json:"release"`
+	// GitRevision and BuildTime are only known if defined while building the daemon.
+	GitRevision string `json:"gitrevision,omitempty"`
+	BuildTime   string `json:"buildtime,omitempty"`
+	GoVersion   string `json:"goversion"`
+	OS          string `json:"os"`
+	Arch        string `json:"arch"`
+}
+
+// NewVersion returns the version of a daemon, using the given configuration,
+// which has loaded the modules of the given names.
+func NewVersion(cfg Config, networkCfg NetworkConfig, modules []string) Version {
+	return Version{
+		ChainVersion:    cfg.BlockchainInfo.ChainVersion,
+		ProtocolVersion: cfg.BlockchainInfo.ProtocolVersion,
+		ChainName:       cfg.BlockchainInfo.Name,
+		NetworkName:     cfg.BlockchainInfo.NetworkName,
+		GenesisID:       networkCfg.Constants.GenesisBlockID(),
+		Modules:         append([]string{}, modules...),
+		Build: BuildInfo{
+			Release:     build.Release,
+			GitRevision: build.GitRevision,
+			BuildTime:   build.BuildTime,
+			GoVersion:   runtime.Version(),
+			OS:          runtime.GOOS,
+			Arch:        runtime.GOARCH,
+		},
+	}
 }
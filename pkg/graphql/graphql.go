@@ -0,0 +1,796 @@
+// Package graphql implements a minimal GraphQL executor, executing the queries of clients
+// against a schema of objects resolved by Go functions, such that read-only APIs can be offered
+// as GraphQL without depending on a full GraphQL implementation.
+//
+// Query operations are supported, including variables, aliases, fragments and
+// the @include and @skip directives. Mutations, subscriptions, interfaces, unions and
+// introspection (other than __typename) are not, the schema is described by Schema.String instead.
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+type (
+	// Type is the type of a field or argument: a *Scalar, an *Object or a *List.
+	Type interface {
+		// String returns the name of the type, as used in a schema.
+		String() string
+	}
+
+	// Scalar is a leaf type, of which the values are encoded using encoding/json.
+	Scalar struct {
+		Name        string
+		Description string
+	}
+
+	// Object is a type with fields, of which the clients select the ones they need.
+	Object struct {
+		Name        string
+		Description string
+		Fields      map[string]*Field
+	}
+
+	// List is a list of values of the same type.
+	List struct {
+		OfType Type
+	}
+
+	// Field is a field of an Object.
+	Field struct {
+		Type        Type
+		Description string
+		Args        []*Argument
+		// Resolve resolves the value of the field, for the object given as the source.
+		// If nil, the value is the item of the same name of a map source,
+		// or the field of a struct source of which the JSON name is the same.
+		Resolve ResolveFunc
+	}
+
+	// Argument is an argument of a Field. The value of an argument of a built-in scalar type
+	// is given to the resolver as a string, int64, float64 or bool, or as a []interface{}
+	// for a list, while the values of other scalars are given as they are decoded.
+	// Enum values are given as strings.
+	Argument struct {
+		Name         string
+		Type         Type
+		Description  string
+		Required     bool
+		DefaultValue interface{}
+	}
+
+	// ResolveFunc resolves the value of a field.
+	ResolveFunc func(p ResolveParams) (interface{}, error)
+
+	// ResolveParams are the parameters of a ResolveFunc.
+	ResolveParams struct {
+		// Source is the object of which the field is resolved, the root value for the fields of the query.
+		Source interface{}
+		// Args are the values of the arguments of the field, by name,
+		// omitting the optional arguments without a default value which are not given.
+		Args map[string]interface{}
+		// Context is the context given to Schema.Execute.
+		Context interface{}
+	}
+
+	// Schema defines the queries clients can execute.
+	Schema struct {
+		Query *Object
+		// MaxDepth is the maximum depth of the selections of a query, unlimited if 0.
+		MaxDepth int
+		// MaxFields is the maximum amount of fields resolved by a query, unlimited if 0.
+		MaxFields int
+	}
+
+	// Request is the request to execute a query, as sent by clients.
+	Request struct {
+		Query string `json:"query"`
+		// OperationName is the operation to execute, required if the query contains multiple operations.
+		OperationName string                 `json:"operationName,omitempty"`
+		Variables     map[string]interface{} `json:"variables,omitempty"`
+	}
+
+	// Response is the response of an executed query. The data is omitted
+	// if the query could not be executed, e.g. because it is invalid.
+	Response struct {
+		Data   interface{} `json:"data,omitempty"`
+		Errors []*Error    `json:"errors,omitempty"`
+	}
+
+	// Error is an error of a query, located at the given path of the response
+	// if it is an error resolving a field.
+	Error struct {
+		Message string        `json:"message"`
+		Path    []interface{} `json:"path,omitempty"`
+	}
+)
+
+// The built-in scalars.
+var (
+	String  = &Scalar{Name: "String"}
+	Int     = &Scalar{Name: "Int"}
+	Float   = &Scalar{Name: "Float"}
+	Boolean = &Scalar{Name: "Boolean"}
+	ID      = &Scalar{Name: "ID"}
+)
+
+// String implements Type.String
+func (s *Scalar) String() string { return s.Name }
+
+// String implements Type.String
+func (o *Object) String() string { return o.Name }
+
+// String implements Type.String
+func (l *List) String() string { return "[" + l.OfType.String() + "]" }
+
+// ListOf returns a list of the given type.
+func ListOf(t Type) *List {
+	return &List{OfType: t}
+}
+
+// Error implements error.Error
+func (err *Error) Error() string {
+	return err.Message
+}
+
+// Execute executes the given request, passing the given context to the resolvers
+// and the given root value as the source of the fields of the query.
+func (s *Schema) Execute(req Request, root, context interface{}) *Response {
+	doc, err := parse(req.Query)
+	if err != nil {
+		return &Response{Errors: []*Error{{Message: err.Error()}}}
+	}
+	op, err := doc.operation(req.OperationName)
+	if err != nil {
+		return &Response{Errors: []*Error{{Message: err.Error()}}}
+	}
+	e := &executor{schema: s, doc: doc, op: op, context: context}
+	e.variables, err = op.coerceVariables(req.Variables)
+	if err != nil {
+		return &Response{Errors: []*Error{{Message: err.Error()}}}
+	}
+	err = e.validate(s.Query, op.selections, 1, nil)
+	if err != nil {
+		return &Response{Errors: []*Error{{Message: err.Error()}}}
+	}
+	data := e.executeSelections(s.Query, root, op.selections, nil)
+	return &Response{Data: data, Errors: e.errors}
+}
+
+// operation returns the operation of the document to execute.
+func (doc *document) operation(name string) (*operation, error) {
+	var op *operation
+	if name == "" {
+		if len(doc.operations) > 1 {
+			return nil, fmt.Errorf("an operation name is required, as the document contains multiple operations")
+		}
+		op = doc.operations[0]
+	} else {
+		for _, candidate := range doc.operations {
+			if candidate.name == name {
+				op = candidate
+				break
+			}
+		}
+		if op == nil {
+			return nil, fmt.Errorf("unknown operation %s", name)
+		}
+	}
+	if op.kind != "query" {
+		return nil, fmt.Errorf("%s operations are not supported, only query operations are", op.kind)
+	}
+	return op, nil
+}
+
+// coerceVariables returns the values of the variables of the operation,
+// using the given values or the default values.
+func (op *operation) coerceVariables(values map[string]interface{}) (map[string]interface{}, error) {
+	variables := make(map[string]interface{}, len(op.variables))
+	for _, def := range op.variables {
+		value, ok := values[def.name]
+		if !ok {
+			value, ok = def.defaultValue, def.hasDefault
+		}
+		if def.nonNull && (!ok || value == nil) {
+			return nil, fmt.Errorf("variable $%s of required type %s! is not given", def.name, def.typ)
+		}
+		if ok {
+			variables[def.name] = normalizeJSONValue(value)
+		}
+	}
+	return variables, nil
+}
+
+// normalizeJSONValue converts the integral numbers of the given JSON-decoded value to int64,
+// such that variables are given to resolvers just like literals.
+func normalizeJSONValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return int64(v)
+		}
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			list[i] = normalizeJSONValue(item)
+		}
+		return list
+	case map[string]interface{}:
+		object := make(map[string]interface{}, len(v))
+		for name, item := range v {
+			object[name] = normalizeJSONValue(item)
+		}
+		return object
+	}
+	return value
+}
+
+// executor executes a single operation.
+type executor struct {
+	schema    *Schema
+	doc       *document
+	op        *operation
+	variables map[string]interface{}
+	context   interface{}
+	errors    []*Error
+	fields    int
+}
+
+// validate validates the given selections of the given object, at the given depth, such that
+// all fields exist, their arguments are valid and they select subfields if and only if they should.
+// The fragments spread by the selections are given to detect cycles.
+func (e *executor) validate(obj *Object, selections []selection, depth int, spread []string) error {
+	if e.schema.MaxDepth > 0 && depth > e.schema.MaxDepth {
+		return fmt.Errorf("the query exceeds the maximum depth of %d", e.schema.MaxDepth)
+	}
+	for _, s := range selections {
+		switch s := s.(type) {
+		case *field:
+			err := e.validateDirectives(s.directives)
+			if err != nil {
+				return err
+			}
+			if s.name == "__typename" {
+				if len(s.arguments) != 0 || len(s.selections) != 0 {
+					return fmt.Errorf("%d:%d: field __typename has no arguments and no subfields", s.line, s.col)
+				}
+				continue
+			}
+			f, ok := obj.Fields[s.name]
+			if !ok {
+				return fmt.Errorf("%d:%d: type %s has no field %s", s.line, s.col, obj.Name, s.name)
+			}
+			if _, err = e.coerceArguments(f.Args, s.arguments); err != nil {
+				return fmt.Errorf("%d:%d: field %s: %v", s.line, s.col, s.name, err)
+			}
+			fieldObj := namedObject(f.Type)
+			if fieldObj == nil && len(s.selections) != 0 {
+				return fmt.Errorf("%d:%d: field %s of type %s cannot have subfields", s.line, s.col, s.name, f.Type)
+			}
+			if fieldObj != nil && len(s.selections) == 0 {
+				return fmt.Errorf("%d:%d: field %s of type %s has to select subfields", s.line, s.col, s.name, f.Type)
+			}
+			if fieldObj != nil {
+				if err = e.validate(fieldObj, s.selections, depth+1, spread); err != nil {
+					return err
+				}
+			}
+		case *fragmentSpread:
+			err := e.validateDirectives(s.directives)
+			if err != nil {
+				return err
+			}
+			f, ok := e.doc.fragments[s.name]
+			if !ok {
+				return fmt.Errorf("%d:%d: unknown fragment %s", s.line, s.col, s.name)
+			}
+			for _, name := range spread {
+				if name == s.name {
+					return fmt.Errorf("%d:%d: fragment %s spreads itself", s.line, s.col, s.name)
+				}
+			}
+			if f.typeCondition != obj.Name {
+				return fmt.Errorf("%d:%d: fragment %s on %s cannot be spread on type %s", s.line, s.col, s.name, f.typeCondition, obj.Name)
+			}
+			if err = e.validate(obj, f.selections, depth, append(spread[:len(spread):len(spread)], s.name)); err != nil {
+				return err
+			}
+		case *inlineFragment:
+			err := e.validateDirectives(s.directives)
+			if err != nil {
+				return err
+			}
+			if s.typeCondition != "" && s.typeCondition != obj.Name {
+				return fmt.Errorf("%d:%d: fragment on %s cannot be spread on type %s", s.line, s.col, s.typeCondition, obj.Name)
+			}
+			if err = e.validate(obj, s.selections, depth, spread); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateDirectives validates the given directives, only @include and @skip being supported.
+func (e *executor) validateDirectives(directives []*directive) error {
+	for _, d := range directives {
+		if d.name != "include" && d.name != "skip" {
+			return fmt.Errorf("unknown directive @%s", d.name)
+		}
+		if _, err := e.coerceArguments(directiveArgs, d.arguments); err != nil {
+			return fmt.Errorf("directive @%s: %v", d.name, err)
+		}
+	}
+	return nil
+}
+
+// directiveArgs are the arguments of the @include and @skip directives.
+var directiveArgs = []*Argument{{Name: "if", Type: Boolean, Required: true}}
+
+// included returns false if the given directives exclude the selection.
+func (e *executor) included(directives []*directive) bool {
+	for _, d := range directives {
+		args, _ := e.coerceArguments(directiveArgs, d.arguments)
+		if args["if"] == (d.name == "skip") {
+			return false
+		}
+	}
+	return true
+}
+
+// namedObject returns the object of the given type, or of the items of a list, nil if a scalar.
+func namedObject(t Type) *Object {
+	switch t := t.(type) {
+	case *Object:
+		return t
+	case *List:
+		return namedObject(t.OfType)
+	default:
+		return nil
+	}
+}
+
+// coerceArguments returns the values of the given arguments, as defined by the given definitions.
+func (e *executor) coerceArguments(defs []*Argument, args []*argument) (map[string]interface{}, error) {
+	values := make(map[string]interface{}, len(defs))
+	for _, arg := range args {
+		var def *Argument
+		for _, candidate := range defs {
+			if candidate.Name == arg.name {
+				def = candidate
+				break
+			}
+		}
+		if def == nil {
+			return nil, fmt.Errorf("unknown argument %s", arg.name)
+		}
+		if _, ok := values[arg.name]; ok {
+			return nil, fmt.Errorf("argument %s is given more than once", arg.name)
+		}
+		value, err := e.resolveVariables(arg.value)
+		if err != nil {
+			return nil, err
+		}
+		if value == (undefinedVariable{}) {
+			continue // as if the argument is not given
+		}
+		if values[arg.name], err = coerceValue(def.Type, value); err != nil {
+			return nil, fmt.Errorf("argument %s: %v", arg.name, err)
+		}
+	}
+	for _, def := range defs {
+		if _, ok := values[def.Name]; ok {
+			continue
+		}
+		if def.DefaultValue != nil {
+			values[def.Name] = def.DefaultValue
+		} else if def.Required {
+			return nil, fmt.Errorf("required argument %s is not given", def.Name)
+		}
+	}
+	for _, def := range defs {
+		if def.Required && values[def.Name] == nil {
+			return nil, fmt.Errorf("required argument %s cannot be null", def.Name)
+		}
+	}
+	return values, nil
+}
+
+// undefinedVariable is the value of a variable which is defined by the operation, but not given.
+type undefinedVariable struct{}
+
+// resolveVariables replaces the variables referenced by the given value with their value.
+func (e *executor) resolveVariables(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case variable:
+		if value, ok := e.variables[string(v)]; ok {
+			return value, nil
+		}
+		for _, def := range e.op.variables {
+			if def.name == string(v) {
+				return undefinedVariable{}, nil
+			}
+		}
+		return nil, fmt.Errorf("variable $%s is not defined", v)
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			var err error
+			if list[i], err = e.resolveVariables(item); err != nil {
+				return nil, err
+			}
+			if list[i] == (undefinedVariable{}) {
+				list[i] = nil
+			}
+		}
+		return list, nil
+	case map[string]interface{}:
+		object := make(map[string]interface{}, len(v))
+		for name, item := range v {
+			resolved, err := e.resolveVariables(item)
+			if err != nil {
+				return nil, err
+			}
+			if resolved != (undefinedVariable{}) {
+				object[name] = resolved
+			}
+		}
+		return object, nil
+	}
+	return value, nil
+}
+
+// coerceValue coerces the given value to the given type.
+func coerceValue(t Type, value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	switch t {
+	case String, ID:
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case enumValue:
+			return string(v), nil
+		case int64:
+			if t == ID {
+				return fmt.Sprint(v), nil
+			}
+		}
+	case Int:
+		if v, ok := value.(int64); ok && v >= math.MinInt32 && v <= math.MaxInt32 {
+			return v, nil
+		}
+	case Float:
+		switch v := value.(type) {
+		case float64:
+			return v, nil
+		case int64:
+			return float64(v), nil
+		}
+	case Boolean:
+		if v, ok := value.(bool); ok {
+			return v, nil
+		}
+	default:
+		if l, ok := t.(*List); ok {
+			items, ok := value.([]interface{})
+			if !ok {
+				items = []interface{}{value}
+			}
+			list := make([]interface{}, len(items))
+			for i, item := range items {
+				var err error
+				if list[i], err = coerceValue(l.OfType, item); err != nil {
+					return nil, err
+				}
+			}
+			return list, nil
+		}
+		if _, ok := t.(*Scalar); ok {
+			if v, ok := value.(enumValue); ok {
+				return string(v), nil
+			}
+			return value, nil
+		}
+		return nil, fmt.Errorf("type %s cannot be used as an input", t)
+	}
+	return nil, fmt.Errorf("invalid value %v for type %s", formatValue(value), t)
+}
+
+// formatValue formats the given value as it is written in a query.
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		b, _ := json.Marshal(v)
+		return string(b)
+	case enumValue:
+		return string(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// responseField is a field of the response, being the selection of one or more fields of the same name.
+type responseField struct {
+	key    string
+	fields []*field
+}
+
+// collectFields returns the fields selected by the given selections,
+// merging the fields of the same response key, in the order they are selected.
+func (e *executor) collectFields(selections []selection, collected []*responseField) []*responseField {
+	for _, s := range selections {
+		switch s := s.(type) {
+		case *field:
+			if !e.included(s.directives) {
+				continue
+			}
+			key := s.alias
+			if key == "" {
+				key = s.name
+			}
+			var rf *responseField
+			for _, candidate := range collected {
+				if candidate.key == key {
+					rf = candidate
+					break
+				}
+			}
+			if rf == nil {
+				rf = &responseField{key: key}
+				collected = append(collected, rf)
+			}
+			rf.fields = append(rf.fields, s)
+		case *fragmentSpread:
+			if e.included(s.directives) {
+				collected = e.collectFields(e.doc.fragments[s.name].selections, collected)
+			}
+		case *inlineFragment:
+			if e.included(s.directives) {
+				collected = e.collectFields(s.selections, collected)
+			}
+		}
+	}
+	return collected
+}
+
+// executeSelections executes the given selections of the given object, at the given path of the response.
+func (e *executor) executeSelections(obj *Object, source interface{}, selections []selection, path []interface{}) *orderedObject {
+	result := new(orderedObject)
+	for _, rf := range e.collectFields(selections, nil) {
+		fieldPath := append(path[:len(path):len(path)], rf.key)
+		f := rf.fields[0]
+		if f.name == "__typename" {
+			result.set(rf.key, obj.Name)
+			continue
+		}
+		value, err := e.executeField(obj.Fields[f.name], source, rf.fields, fieldPath)
+		if err != nil {
+			e.errors = append(e.errors, &Error{Message: err.Error(), Path: fieldPath})
+			value = nil
+		}
+		result.set(rf.key, value)
+	}
+	return result
+}
+
+// executeField resolves the value of the given fields, of the same response key, and completes it.
+func (e *executor) executeField(def *Field, source interface{}, fields []*field, path []interface{}) (interface{}, error) {
+	e.fields++
+	if e.schema.MaxFields > 0 && e.fields > e.schema.MaxFields {
+		return nil, fmt.Errorf("the query exceeds the maximum of %d resolved fields", e.schema.MaxFields)
+	}
+	args, err := e.coerceArguments(def.Args, fields[0].arguments)
+	if err != nil {
+		return nil, err
+	}
+	resolve := def.Resolve
+	if resolve == nil {
+		resolve = defaultResolver(fields[0].name)
+	}
+	value, err := resolve(ResolveParams{Source: source, Args: args, Context: e.context})
+	if err != nil {
+		return nil, err
+	}
+	var selections []selection
+	for _, f := range fields {
+		selections = append(selections, f.selections...)
+	}
+	return e.completeValue(def.Type, value, selections, path)
+}
+
+// completeValue completes the given resolved value of the given type,
+// executing the given selections of objects.
+func (e *executor) completeValue(t Type, value interface{}, selections []selection, path []interface{}) (interface{}, error) {
+	if isNil(value) {
+		return nil, nil
+	}
+	switch t := t.(type) {
+	case *Object:
+		return e.executeSelections(t, value, selections, path), nil
+	case *List:
+		v := reflect.ValueOf(value)
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			return nil, fmt.Errorf("resolved a %T as a list", value)
+		}
+		list := make([]interface{}, v.Len())
+		for i := range list {
+			var err error
+			list[i], err = e.completeValue(t.OfType, v.Index(i).Interface(), selections, append(path[:len(path):len(path)], i))
+			if err != nil {
+				return nil, err
+			}
+		}
+		return list, nil
+	default:
+		return value, nil
+	}
+}
+
+// isNil returns true if the given value is nil, or a nil pointer, map or interface.
+func isNil(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	switch v := reflect.ValueOf(value); v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
+
+// defaultResolver returns the resolver of fields without a ResolveFunc.
+func defaultResolver(name string) ResolveFunc {
+	return func(p ResolveParams) (interface{}, error) {
+		if m, ok := p.Source.(map[string]interface{}); ok {
+			return m[name], nil
+		}
+		v := reflect.ValueOf(p.Source)
+		for v.Kind() == reflect.Ptr {
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("cannot resolve field %s of a %T", name, p.Source)
+		}
+		for i := 0; i < v.NumField(); i++ {
+			sf := v.Type().Field(i)
+			if jsonName := strings.Split(sf.Tag.Get("json"), ",")[0]; jsonName == name || jsonName == "" && strings.EqualFold(sf.Name, name) {
+				return v.Field(i).Interface(), nil
+			}
+		}
+		return nil, fmt.Errorf("cannot resolve field %s of a %T", name, p.Source)
+	}
+}
+
+// orderedObject is an object of the response, of which the fields are encoded in the order they are selected.
+type orderedObject struct {
+	keys   []string
+	values []interface{}
+}
+
+func (o *orderedObject) set(key string, value interface{}) {
+	o.keys = append(o.keys, key)
+	o.values = append(o.values, value)
+}
+
+// MarshalJSON implements json.Marshaler.MarshalJSON
+func (o *orderedObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		b, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(b)
+		buf.WriteByte(':')
+		if b, err = json.Marshal(o.values[i]); err != nil {
+			return nil, err
+		}
+		buf.Write(b)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// String returns the schema in the GraphQL schema definition language,
+// describing all types reachable from the query.
+func (s *Schema) String() string {
+	objects := make(map[string]*Object)
+	scalars := make(map[string]*Scalar)
+	var walk func(t Type)
+	walk = func(t Type) {
+		switch t := t.(type) {
+		case *List:
+			walk(t.OfType)
+		case *Scalar:
+			scalars[t.Name] = t
+		case *Object:
+			if _, ok := objects[t.Name]; ok {
+				return
+			}
+			objects[t.Name] = t
+			for _, f := range t.Fields {
+				walk(f.Type)
+				for _, arg := range f.Args {
+					walk(arg.Type)
+				}
+			}
+		}
+	}
+	walk(s.Query)
+
+	var sb strings.Builder
+	writeDescription := func(indent, description string) {
+		if description != "" {
+			for _, line := range strings.Split(description, "\n") {
+				fmt.Fprintf(&sb, "%s# %s\n", indent, line)
+			}
+		}
+	}
+	fmt.Fprintf(&sb, "schema {\n  query: %s\n}\n", s.Query.Name)
+	for _, name := range sortedKeys(scalars) {
+		switch scalars[name] {
+		case String, Int, Float, Boolean, ID:
+			continue // built-in
+		}
+		sb.WriteByte('\n')
+		writeDescription("", scalars[name].Description)
+		fmt.Fprintf(&sb, "scalar %s\n", name)
+	}
+	for _, name := range sortedKeys(objects) {
+		obj := objects[name]
+		sb.WriteByte('\n')
+		writeDescription("", obj.Description)
+		fmt.Fprintf(&sb, "type %s {\n", name)
+		for _, fieldName := range sortedKeys(obj.Fields) {
+			f := obj.Fields[fieldName]
+			writeDescription("  ", f.Description)
+			sb.WriteString("  " + fieldName)
+			if len(f.Args) > 0 {
+				args := make([]string, len(f.Args))
+				for i, arg := range f.Args {
+					args[i] = arg.Name + ": " + arg.Type.String()
+					if arg.Required {
+						args[i] += "!"
+					}
+					if arg.DefaultValue != nil {
+						args[i] += " = " + formatValue(arg.DefaultValue)
+					}
+				}
+				sb.WriteString("(" + strings.Join(args, ", ") + ")")
+			}
+			sb.WriteString(": " + f.Type.String() + "\n")
+		}
+		sb.WriteString("}\n")
+	}
+	return sb.String()
+}
+
+// sortedKeys returns the keys of the given map, sorted.
+func sortedKeys(m interface{}) []string {
+	keys := reflect.ValueOf(m).MapKeys()
+	names := make([]string, len(keys))
+	for i, key := range keys {
+		names[i] = key.String()
+	}
+	sort.Strings(names)
+	return names
+}
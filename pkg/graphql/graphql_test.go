@@ -0,0 +1,236 @@
+package graphql
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type testBook struct {
+	Title  string `json:"title"`
+	Year   int    `json:"year"`
+	Author string `json:"-"`
+}
+
+// testSchema returns a schema of books and their authors.
+func testSchema() *Schema {
+	books := []testBook{
+		{Title: "Dune", Year: 1965, Author: "herbert"},
+		{Title: "Children of Dune", Year: 1976, Author: "herbert"},
+		{Title: "Neuromancer", Year: 1984, Author: "gibson"},
+	}
+	author := &Object{Name: "Author", Fields: map[string]*Field{
+		"name": {Type: String},
+	}}
+	book := &Object{Name: "Book", Fields: map[string]*Field{
+		"title": {Type: String},
+		"year":  {Type: Int},
+		"author": {Type: author, Resolve: func(p ResolveParams) (interface{}, error) {
+			return map[string]interface{}{"name": p.Source.(testBook).Author}, nil
+		}},
+		"isbn": {Type: String, Resolve: func(p ResolveParams) (interface{}, error) {
+			return nil, errors.New("unknown isbn")
+		}},
+	}}
+	author.Fields["books"] = &Field{Type: ListOf(book), Resolve: func(p ResolveParams) (interface{}, error) {
+		var written []testBook
+		for _, b := range books {
+			if b.Author == p.Source.(map[string]interface{})["name"] {
+				written = append(written, b)
+			}
+		}
+		return written, nil
+	}}
+	query := &Object{Name: "Query", Fields: map[string]*Field{
+		"books": {
+			Type: ListOf(book),
+			Args: []*Argument{
+				{Name: "since", Type: Int, DefaultValue: int64(0)},
+				{Name: "titles", Type: ListOf(String)},
+			},
+			Resolve: func(p ResolveParams) (interface{}, error) {
+				var selected []testBook
+				for _, b := range books {
+					if int64(b.Year) < p.Args["since"].(int64) {
+						continue
+					}
+					if titles, ok := p.Args["titles"].([]interface{}); ok {
+						found := false
+						for _, title := range titles {
+							found = found || title == b.Title
+						}
+						if !found {
+							continue
+						}
+					}
+					selected = append(selected, b)
+				}
+				return selected, nil
+			},
+		},
+		"book": {
+			Type: book,
+			Args: []*Argument{{Name: "title", Type: String, Required: true}},
+			Resolve: func(p ResolveParams) (interface{}, error) {
+				for _, b := range books {
+					if b.Title == p.Args["title"] {
+						return b, nil
+					}
+				}
+				return nil, nil
+			},
+		},
+	}}
+	return &Schema{Query: query, MaxDepth: 4}
+}
+
+func TestExecute(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Request  Request
+		Expected string
+		Error    string // expected (start of the) message of the first error, if any
+	}{
+		{
+			Name:     "fields, aliases and arguments",
+			Request:  Request{Query: `{ books(since: 1970) { title first: year } dune: book(title: "Dune") { __typename year } }`},
+			Expected: `{"books":[{"title":"Children of Dune","first":1976},{"title":"Neuromancer","first":1984}],"dune":{"__typename":"Book","year":1965}}`,
+		},
+		{
+			Name: "nested relations, fragments and directives",
+			Request: Request{Query: `
+				query Books($withYear: Boolean!) {
+					book(title: "Dune") {
+						...title
+						author { name books { ...title year @include(if: $withYear) } }
+						... on Book { year @skip(if: true) }
+					}
+				}
+				fragment title on Book { title }`,
+				Variables: map[string]interface{}{"withYear": false}},
+			Expected: `{"book":{"title":"Dune","author":{"name":"herbert","books":[{"title":"Dune"},{"title":"Children of Dune"}]}}}`,
+		},
+		{
+			Name: "variables, lists and operation names",
+			Request: Request{
+				Query:         `query A { book(title: "Dune") { year } } query B($titles: [String], $since: Int = 1980) { books(titles: $titles, since: $since) { title } }`,
+				OperationName: "B",
+				Variables:     map[string]interface{}{"titles": []interface{}{"Dune", "Neuromancer"}},
+			},
+			Expected: `{"books":[{"title":"Neuromancer"}]}`,
+		},
+		{
+			Name:     "null results and field errors",
+			Request:  Request{Query: `{ book(title: "Snow Crash") { title } dune: book(title: "Dune") { title isbn } }`},
+			Expected: `{"book":null,"dune":{"title":"Dune","isbn":null}}`,
+			Error:    "unknown isbn",
+		},
+		{
+			Name:    "syntax error",
+			Request: Request{Query: `{ books { title }`},
+			Error:   "syntax error at 1:18",
+		},
+		{
+			Name:    "unknown field",
+			Request: Request{Query: `{ books { publisher } }`},
+			Error:   "1:11: type Book has no field publisher",
+		},
+		{
+			Name:    "missing required argument",
+			Request: Request{Query: `{ book { title } }`},
+			Error:   "1:3: field book: required argument title is not given",
+		},
+		{
+			Name:    "invalid argument",
+			Request: Request{Query: `{ books(since: "1970") { title } }`},
+			Error:   "1:3: field books: argument since: invalid value",
+		},
+		{
+			Name:    "missing subfields",
+			Request: Request{Query: `{ books }`},
+			Error:   "1:3: field books of type [Book] has to select subfields",
+		},
+		{
+			Name:    "maximum depth",
+			Request: Request{Query: `{ books { author { books { author { name } } } } }`},
+			Error:   "the query exceeds the maximum depth of 4",
+		},
+		{
+			Name:    "fragment cycle",
+			Request: Request{Query: `{ books { ...a } } fragment a on Book { ...b } fragment b on Book { ...a }`},
+			Error:   "1:69: fragment a spreads itself",
+		},
+		{
+			Name:    "mutation",
+			Request: Request{Query: `mutation { books { title } }`},
+			Error:   "mutation operations are not supported",
+		},
+	}
+	schema := testSchema()
+	for _, testCase := range testCases {
+		resp := schema.Execute(testCase.Request, nil, nil)
+		if testCase.Error == "" && len(resp.Errors) > 0 {
+			t.Errorf("%s: unexpected errors: %v", testCase.Name, resp.Errors[0])
+			continue
+		}
+		if testCase.Error != "" && (len(resp.Errors) == 0 || !strings.HasPrefix(resp.Errors[0].Message, testCase.Error)) {
+			t.Errorf("%s: expected error %q, got %v", testCase.Name, testCase.Error, resp.Errors)
+			continue
+		}
+		if testCase.Expected == "" {
+			if resp.Data != nil {
+				t.Errorf("%s: expected no data to be returned", testCase.Name)
+			}
+			continue
+		}
+		b, err := json.Marshal(resp.Data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != testCase.Expected {
+			t.Errorf("%s: unexpected data:\n%s\nexpected:\n%s", testCase.Name, b, testCase.Expected)
+		}
+	}
+}
+
+func TestErrorPath(t *testing.T) {
+	resp := testSchema().Execute(Request{Query: `{ books { isbn } }`}, nil, nil)
+	if len(resp.Errors) != 3 {
+		t.Fatalf("expected an error per book, got %v", resp.Errors)
+	}
+	b, err := json.Marshal(resp.Errors[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"message":"unknown isbn","path":["books",1,"isbn"]}`; string(b) != expected {
+		t.Errorf("unexpected error: %s != %s", b, expected)
+	}
+}
+
+func TestMaxFields(t *testing.T) {
+	schema := testSchema()
+	schema.MaxFields = 4
+	resp := schema.Execute(Request{Query: `{ books { title } }`}, nil, nil)
+	if len(resp.Errors) != 0 {
+		t.Fatalf("expected 4 fields to be resolved without errors: %v", resp.Errors)
+	}
+	resp = schema.Execute(Request{Query: `{ books { title year } }`}, nil, nil)
+	if len(resp.Errors) == 0 || !strings.HasPrefix(resp.Errors[0].Message, "the query exceeds the maximum of 4 resolved fields") {
+		t.Fatalf("expected the maximum amount of fields to be exceeded: %v", resp.Errors)
+	}
+}
+
+func TestSchemaString(t *testing.T) {
+	sdl := testSchema().String()
+	for _, expected := range []string{
+		"schema {\n  query: Query\n}\n",
+		"type Author {\n  books: [Book]\n  name: String\n}\n",
+		"  books(since: Int = 0, titles: [String]): [Book]\n",
+		"  book(title: String!): Book\n",
+	} {
+		if !strings.Contains(sdl, expected) {
+			t.Errorf("expected the schema to contain %q:\n%s", expected, sdl)
+		}
+	}
+}
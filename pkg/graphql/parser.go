@@ -0,0 +1,644 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// The syntax tree of a parsed query document,
+// limited to the parts of the GraphQL language supported by this package.
+type (
+	document struct {
+		operations []*operation
+		fragments  map[string]*fragment
+	}
+
+	operation struct {
+		kind       string // query, mutation or subscription
+		name       string
+		variables  []*variableDefinition
+		selections []selection
+	}
+
+	variableDefinition struct {
+		name         string
+		typ          string
+		nonNull      bool
+		defaultValue interface{}
+		hasDefault   bool
+	}
+
+	// selection is a *field, *fragmentSpread or *inlineFragment.
+	selection interface{}
+
+	field struct {
+		alias      string
+		name       string
+		arguments  []*argument
+		directives []*directive
+		selections []selection
+		line, col  int
+	}
+
+	argument struct {
+		name  string
+		value interface{}
+	}
+
+	directive struct {
+		name      string
+		arguments []*argument
+	}
+
+	fragmentSpread struct {
+		name       string
+		directives []*directive
+		line, col  int
+	}
+
+	inlineFragment struct {
+		typeCondition string
+		directives    []*directive
+		selections    []selection
+		line, col     int
+	}
+
+	fragment struct {
+		name          string
+		typeCondition string
+		selections    []selection
+	}
+
+	// variable is a reference to a variable, as the value of an argument.
+	variable string
+	// enumValue is an enum literal, as the value of an argument.
+	enumValue string
+)
+
+// The kinds of tokens of the GraphQL language.
+const (
+	tokenEOF = iota
+	tokenPunctuator
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+type token struct {
+	kind      int
+	value     string
+	line, col int
+}
+
+// lexer splits a query document into tokens,
+// skipping the whitespace, commas and comments in between.
+type lexer struct {
+	src       string
+	pos       int
+	line, col int
+}
+
+// syntaxError returns an error for the given location of the document.
+func syntaxError(line, col int, format string, args ...interface{}) error {
+	return fmt.Errorf("syntax error at %d:%d: %s", line, col, fmt.Sprintf(format, args...))
+}
+
+func (l *lexer) advance(n int) {
+	for i := 0; i < n; i++ {
+		if l.src[l.pos] == '\n' {
+			l.line++
+			l.col = 1
+		} else {
+			l.col++
+		}
+		l.pos++
+	}
+}
+
+func (l *lexer) next() (token, error) {
+	// skip the ignored tokens
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',' {
+			l.advance(1)
+		} else if c == '#' {
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+				l.advance(1)
+			}
+		} else {
+			break
+		}
+	}
+	tok := token{line: l.line, col: l.col}
+	if l.pos == len(l.src) {
+		return tok, nil
+	}
+	rest := l.src[l.pos:]
+	c := rest[0]
+	switch {
+	case strings.HasPrefix(rest, "..."):
+		tok.kind, tok.value = tokenPunctuator, "..."
+	case strings.IndexByte("!$()[]{}:=@|", c) >= 0:
+		tok.kind, tok.value = tokenPunctuator, rest[:1]
+	case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+		n := 1
+		for n < len(rest) && (rest[n] == '_' || rest[n] >= 'a' && rest[n] <= 'z' || rest[n] >= 'A' && rest[n] <= 'Z' || rest[n] >= '0' && rest[n] <= '9') {
+			n++
+		}
+		tok.kind, tok.value = tokenName, rest[:n]
+	case c == '-' || c >= '0' && c <= '9':
+		n := 1
+		tok.kind = tokenInt
+		for n < len(rest) {
+			d := rest[n]
+			if d >= '0' && d <= '9' {
+				n++
+			} else if d == '.' || d == 'e' || d == 'E' || (d == '+' || d == '-') && (rest[n-1] == 'e' || rest[n-1] == 'E') {
+				tok.kind = tokenFloat
+				n++
+			} else {
+				break
+			}
+		}
+		tok.value = rest[:n]
+	case c == '"':
+		value, n, err := l.scanString(rest)
+		if err != nil {
+			return tok, err
+		}
+		tok.kind, tok.value = tokenString, value
+		l.advance(n)
+		return tok, nil
+	default:
+		r, _ := utf8.DecodeRuneInString(rest)
+		return tok, syntaxError(tok.line, tok.col, "unexpected character %q", r)
+	}
+	l.advance(len(tok.value))
+	return tok, nil
+}
+
+// scanString scans the (single line) string at the start of the given source,
+// returning its value and its length in the source.
+func (l *lexer) scanString(src string) (string, int, error) {
+	var sb strings.Builder
+	for n := 1; n < len(src); n++ {
+		switch c := src[n]; c {
+		case '"':
+			return sb.String(), n + 1, nil
+		case '\n', '\r':
+			return "", 0, syntaxError(l.line, l.col, "unterminated string")
+		case '\\':
+			if n+1 == len(src) {
+				return "", 0, syntaxError(l.line, l.col, "unterminated string")
+			}
+			n++
+			switch e := src[n]; e {
+			case '"', '\\', '/':
+				sb.WriteByte(e)
+			case 'b':
+				sb.WriteByte('\b')
+			case 'f':
+				sb.WriteByte('\f')
+			case 'n':
+				sb.WriteByte('\n')
+			case 'r':
+				sb.WriteByte('\r')
+			case 't':
+				sb.WriteByte('\t')
+			case 'u':
+				if n+5 > len(src) {
+					return "", 0, syntaxError(l.line, l.col, "invalid unicode escape sequence")
+				}
+				r, err := strconv.ParseUint(src[n+1:n+5], 16, 16)
+				if err != nil {
+					return "", 0, syntaxError(l.line, l.col, "invalid unicode escape sequence")
+				}
+				sb.WriteRune(rune(r))
+				n += 4
+			default:
+				return "", 0, syntaxError(l.line, l.col, "invalid escape sequence \\%c", e)
+			}
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return "", 0, syntaxError(l.line, l.col, "unterminated string")
+}
+
+// parser parses a query document, using a single token of lookahead.
+type parser struct {
+	lexer *lexer
+	tok   token
+}
+
+// parse parses the given query document.
+func parse(src string) (*document, error) {
+	p := &parser{lexer: &lexer{src: src, line: 1, col: 1}}
+	err := p.read()
+	if err != nil {
+		return nil, err
+	}
+	doc := &document{fragments: make(map[string]*fragment)}
+	for p.tok.kind != tokenEOF {
+		if p.peek(tokenName, "fragment") {
+			f, err := p.parseFragment()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := doc.fragments[f.name]; ok {
+				return nil, fmt.Errorf("fragment %s is defined more than once", f.name)
+			}
+			doc.fragments[f.name] = f
+			continue
+		}
+		op, err := p.parseOperation()
+		if err != nil {
+			return nil, err
+		}
+		doc.operations = append(doc.operations, op)
+	}
+	if len(doc.operations) == 0 {
+		return nil, fmt.Errorf("the document does not contain an operation")
+	}
+	return doc, nil
+}
+
+func (p *parser) read() (err error) {
+	p.tok, err = p.lexer.next()
+	return err
+}
+
+// peek returns true if the current token is of the given kind and value.
+func (p *parser) peek(kind int, value string) bool {
+	return p.tok.kind == kind && p.tok.value == value
+}
+
+// skip reads the next token if the current token is the given punctuator.
+func (p *parser) skip(punctuator string) (bool, error) {
+	if !p.peek(tokenPunctuator, punctuator) {
+		return false, nil
+	}
+	return true, p.read()
+}
+
+// expect reads the next token if the current token is the given punctuator, failing otherwise.
+func (p *parser) expect(punctuator string) error {
+	if !p.peek(tokenPunctuator, punctuator) {
+		return p.unexpected("%q", punctuator)
+	}
+	return p.read()
+}
+
+// name returns the current name token, reading the next token.
+func (p *parser) name() (string, error) {
+	if p.tok.kind != tokenName {
+		return "", p.unexpected("a name")
+	}
+	name := p.tok.value
+	return name, p.read()
+}
+
+// unexpected returns an error for the current token, which is not the expected one.
+func (p *parser) unexpected(format string, args ...interface{}) error {
+	found := strconv.Quote(p.tok.value)
+	if p.tok.kind == tokenEOF {
+		found = "the end of the document"
+	}
+	return syntaxError(p.tok.line, p.tok.col, "expected %s, found %s", fmt.Sprintf(format, args...), found)
+}
+
+func (p *parser) parseOperation() (*operation, error) {
+	op := &operation{kind: "query"}
+	if p.tok.kind == tokenName {
+		switch p.tok.value {
+		case "query", "mutation", "subscription":
+			op.kind = p.tok.value
+		default:
+			return nil, p.unexpected("an operation")
+		}
+		err := p.read()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok.kind == tokenName {
+			op.name = p.tok.value
+			if err = p.read(); err != nil {
+				return nil, err
+			}
+		}
+		if ok, err := p.skip("("); err != nil {
+			return nil, err
+		} else if ok {
+			for {
+				ok, err := p.skip(")")
+				if err != nil {
+					return nil, err
+				}
+				if ok {
+					break
+				}
+				def, err := p.parseVariableDefinition()
+				if err != nil {
+					return nil, err
+				}
+				op.variables = append(op.variables, def)
+			}
+		}
+		// directives of operations are parsed, but not supported
+		if _, err = p.parseDirectives(); err != nil {
+			return nil, err
+		}
+	}
+	var err error
+	op.selections, err = p.parseSelectionSet()
+	return op, err
+}
+
+func (p *parser) parseVariableDefinition() (*variableDefinition, error) {
+	err := p.expect("$")
+	if err != nil {
+		return nil, err
+	}
+	def := new(variableDefinition)
+	if def.name, err = p.name(); err != nil {
+		return nil, err
+	}
+	if err = p.expect(":"); err != nil {
+		return nil, err
+	}
+	// the type is only used to check that required variables are given
+	if def.typ, def.nonNull, err = p.parseType(); err != nil {
+		return nil, err
+	}
+	if ok, err := p.skip("="); err != nil {
+		return nil, err
+	} else if ok {
+		def.hasDefault = true
+		if def.defaultValue, err = p.parseValue(true); err != nil {
+			return nil, err
+		}
+	}
+	return def, nil
+}
+
+// parseType parses a (list or named) type, returning its textual representation.
+func (p *parser) parseType() (typ string, nonNull bool, err error) {
+	if ok, err := p.skip("["); err != nil {
+		return "", false, err
+	} else if ok {
+		inner, innerNonNull, err := p.parseType()
+		if err != nil {
+			return "", false, err
+		}
+		if innerNonNull {
+			inner += "!"
+		}
+		if err = p.expect("]"); err != nil {
+			return "", false, err
+		}
+		typ = "[" + inner + "]"
+	} else if typ, err = p.name(); err != nil {
+		return "", false, err
+	}
+	nonNull, err = p.skip("!")
+	return typ, nonNull, err
+}
+
+func (p *parser) parseSelectionSet() ([]selection, error) {
+	err := p.expect("{")
+	if err != nil {
+		return nil, err
+	}
+	var selections []selection
+	for {
+		if ok, err := p.skip("}"); err != nil {
+			return nil, err
+		} else if ok {
+			if len(selections) == 0 {
+				return nil, syntaxError(p.tok.line, p.tok.col, "empty selection set")
+			}
+			return selections, nil
+		}
+		s, err := p.parseSelection()
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, s)
+	}
+}
+
+func (p *parser) parseSelection() (selection, error) {
+	line, col := p.tok.line, p.tok.col
+	if ok, err := p.skip("..."); err != nil {
+		return nil, err
+	} else if ok {
+		if p.tok.kind == tokenName && p.tok.value != "on" {
+			spread := &fragmentSpread{name: p.tok.value, line: line, col: col}
+			if err = p.read(); err != nil {
+				return nil, err
+			}
+			spread.directives, err = p.parseDirectives()
+			return spread, err
+		}
+		inline := &inlineFragment{line: line, col: col}
+		if p.peek(tokenName, "on") {
+			if err = p.read(); err != nil {
+				return nil, err
+			}
+			if inline.typeCondition, err = p.name(); err != nil {
+				return nil, err
+			}
+		}
+		if inline.directives, err = p.parseDirectives(); err != nil {
+			return nil, err
+		}
+		inline.selections, err = p.parseSelectionSet()
+		return inline, err
+	}
+
+	f := &field{line: line, col: col}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if ok, err := p.skip(":"); err != nil {
+		return nil, err
+	} else if ok {
+		f.alias = name
+		if name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	f.name = name
+	if f.arguments, err = p.parseArguments(); err != nil {
+		return nil, err
+	}
+	if f.directives, err = p.parseDirectives(); err != nil {
+		return nil, err
+	}
+	if p.peek(tokenPunctuator, "{") {
+		f.selections, err = p.parseSelectionSet()
+	}
+	return f, err
+}
+
+func (p *parser) parseArguments() ([]*argument, error) {
+	if ok, err := p.skip("("); err != nil || !ok {
+		return nil, err
+	}
+	var args []*argument
+	for {
+		if ok, err := p.skip(")"); err != nil {
+			return nil, err
+		} else if ok {
+			return args, nil
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if err = p.expect(":"); err != nil {
+			return nil, err
+		}
+		value, err := p.parseValue(false)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, &argument{name: name, value: value})
+	}
+}
+
+func (p *parser) parseDirectives() ([]*directive, error) {
+	var directives []*directive
+	for {
+		if ok, err := p.skip("@"); err != nil || !ok {
+			return directives, err
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		args, err := p.parseArguments()
+		if err != nil {
+			return nil, err
+		}
+		directives = append(directives, &directive{name: name, arguments: args})
+	}
+}
+
+// parseValue parses a value, which can only reference variables if not constant.
+func (p *parser) parseValue(constant bool) (interface{}, error) {
+	tok := p.tok
+	switch tok.kind {
+	case tokenPunctuator:
+		switch tok.value {
+		case "$":
+			if constant {
+				return nil, p.unexpected("a constant value")
+			}
+			err := p.read()
+			if err != nil {
+				return nil, err
+			}
+			name, err := p.name()
+			return variable(name), err
+		case "[":
+			err := p.read()
+			if err != nil {
+				return nil, err
+			}
+			list := []interface{}{}
+			for {
+				if ok, err := p.skip("]"); err != nil {
+					return nil, err
+				} else if ok {
+					return list, nil
+				}
+				value, err := p.parseValue(constant)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, value)
+			}
+		case "{":
+			err := p.read()
+			if err != nil {
+				return nil, err
+			}
+			object := map[string]interface{}{}
+			for {
+				if ok, err := p.skip("}"); err != nil {
+					return nil, err
+				} else if ok {
+					return object, nil
+				}
+				name, err := p.name()
+				if err != nil {
+					return nil, err
+				}
+				if err = p.expect(":"); err != nil {
+					return nil, err
+				}
+				if object[name], err = p.parseValue(constant); err != nil {
+					return nil, err
+				}
+			}
+		}
+	case tokenInt:
+		n, err := strconv.ParseInt(tok.value, 10, 64)
+		if err != nil {
+			return nil, syntaxError(tok.line, tok.col, "invalid integer %s", tok.value)
+		}
+		return n, p.read()
+	case tokenFloat:
+		f, err := strconv.ParseFloat(tok.value, 64)
+		if err != nil {
+			return nil, syntaxError(tok.line, tok.col, "invalid float %s", tok.value)
+		}
+		return f, p.read()
+	case tokenString:
+		return tok.value, p.read()
+	case tokenName:
+		var value interface{}
+		switch tok.value {
+		case "true":
+			value = true
+		case "false":
+			value = false
+		case "null":
+			value = nil
+		default:
+			value = enumValue(tok.value)
+		}
+		return value, p.read()
+	}
+	return nil, p.unexpected("a value")
+}
+
+func (p *parser) parseFragment() (*fragment, error) {
+	err := p.read() // fragment
+	if err != nil {
+		return nil, err
+	}
+	f := new(fragment)
+	if f.name, err = p.name(); err != nil {
+		return nil, err
+	}
+	if f.name == "on" {
+		return nil, syntaxError(p.tok.line, p.tok.col, "a fragment cannot be named on")
+	}
+	if !p.peek(tokenName, "on") {
+		return nil, p.unexpected("%q", "on")
+	}
+	if err = p.read(); err != nil {
+		return nil, err
+	}
+	if f.typeCondition, err = p.name(); err != nil {
+		return nil, err
+	}
+	f.selections, err = p.parseSelectionSet()
+	return f, err
+}
@@ -0,0 +1,308 @@
+// Package lineeditor implements a minimal interactive line editor for terminals,
+// supporting the common (emacs style) editing keys, an in-memory history
+// and tab completion. When the input is not a terminal, lines are read as-is.
+package lineeditor
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// ErrInterrupted is returned by ReadLine when the user presses Ctrl+C.
+var ErrInterrupted = errors.New("interrupted")
+
+// MaxHistory is the maximum amount of lines kept in the history.
+const MaxHistory = 1000
+
+// Editor reads lines from a terminal, allowing the user to edit them,
+// recall earlier lines using the arrow keys and complete words using the tab key.
+type Editor struct {
+	// Prompt is printed in front of each line read from a terminal.
+	Prompt string
+	// Complete returns the completion candidates of the (possibly empty) last word
+	// of the given line, being the part of the line in front of the cursor.
+	// Each candidate replaces that last word as a whole.
+	Complete func(line string) []string
+
+	fd       int
+	terminal bool
+	reader   *bufio.Reader
+	out      io.Writer
+	history  []string
+
+	// state of the line being edited
+	buf          []rune
+	pos          int
+	lastTab      bool
+	historyIndex int
+	historyLine  []rune
+}
+
+// New creates an editor reading lines from the given input,
+// echoing them to the given output if the input is a terminal.
+func New(in *os.File, out io.Writer) *Editor {
+	fd := int(in.Fd())
+	return &Editor{
+		fd:       fd,
+		terminal: isTerminal(fd),
+		reader:   bufio.NewReader(in),
+		out:      out,
+	}
+}
+
+// IsTerminal returns true if the input of the editor is a terminal,
+// in which case lines are read with editing, history and completion support.
+func (e *Editor) IsTerminal() bool {
+	return e.terminal
+}
+
+// AddHistory adds the given line to the history,
+// unless it is empty or equal to the last line in the history.
+func (e *Editor) AddHistory(line string) {
+	if strings.TrimSpace(line) == "" || (len(e.history) > 0 && e.history[len(e.history)-1] == line) {
+		return
+	}
+	e.history = append(e.history, line)
+	if len(e.history) > MaxHistory {
+		e.history = e.history[len(e.history)-MaxHistory:]
+	}
+}
+
+// ReadLine reads a single line, without its line ending.
+// It returns io.EOF when the input is closed, or the user presses Ctrl+D on an empty line,
+// and ErrInterrupted when the user presses Ctrl+C.
+func (e *Editor) ReadLine() (string, error) {
+	if !e.terminal {
+		line, err := e.reader.ReadString('\n')
+		if err == io.EOF && line != "" {
+			err = nil
+		}
+		return strings.TrimRight(line, "\r\n"), err
+	}
+	state, err := makeRaw(e.fd)
+	if err != nil {
+		return "", fmt.Errorf("failed to put terminal into raw mode: %v", err)
+	}
+	defer restore(e.fd, state)
+	return e.edit()
+}
+
+// edit reads a single line from the terminal, handling all editing keys.
+func (e *Editor) edit() (string, error) {
+	e.buf, e.pos, e.lastTab = nil, 0, false
+	e.historyIndex, e.historyLine = len(e.history), nil
+	e.refresh()
+	for {
+		r, _, err := e.reader.ReadRune()
+		if err != nil {
+			return "", err
+		}
+		tab := false
+		switch r {
+		case '\r', '\n':
+			e.write("\r\n")
+			return string(e.buf), nil
+		case 3: // Ctrl+C
+			e.write("^C\r\n")
+			return "", ErrInterrupted
+		case 4: // Ctrl+D
+			if len(e.buf) == 0 {
+				e.write("\r\n")
+				return "", io.EOF
+			}
+			e.delete()
+		case 127, 8: // Backspace, Ctrl+H
+			if e.pos > 0 {
+				e.pos--
+				e.delete()
+			}
+		case '\t':
+			tab = true
+			e.complete()
+		case 1: // Ctrl+A
+			e.pos = 0
+		case 5: // Ctrl+E
+			e.pos = len(e.buf)
+		case 2: // Ctrl+B
+			e.moveLeft()
+		case 6: // Ctrl+F
+			e.moveRight()
+		case 16: // Ctrl+P
+			e.recall(-1)
+		case 14: // Ctrl+N
+			e.recall(1)
+		case 11: // Ctrl+K
+			e.buf = e.buf[:e.pos]
+		case 21: // Ctrl+U
+			e.buf = append([]rune{}, e.buf[e.pos:]...)
+			e.pos = 0
+		case 23: // Ctrl+W
+			start := e.pos
+			for start > 0 && unicode.IsSpace(e.buf[start-1]) {
+				start--
+			}
+			for start > 0 && !unicode.IsSpace(e.buf[start-1]) {
+				start--
+			}
+			e.buf = append(e.buf[:start], e.buf[e.pos:]...)
+			e.pos = start
+		case 27: // escape sequence
+			err = e.escape()
+			if err != nil {
+				return "", err
+			}
+		default:
+			if unicode.IsPrint(r) {
+				e.buf = append(e.buf, 0)
+				copy(e.buf[e.pos+1:], e.buf[e.pos:])
+				e.buf[e.pos] = r
+				e.pos++
+			}
+		}
+		e.lastTab = tab
+		e.refresh()
+	}
+}
+
+// escape handles the escape sequences of the arrow, home, end and delete keys,
+// ignoring all other sequences.
+func (e *Editor) escape() error {
+	r, _, err := e.reader.ReadRune()
+	if err != nil || (r != '[' && r != 'O') {
+		return err
+	}
+	var param []rune
+	for {
+		r, _, err = e.reader.ReadRune()
+		if err != nil {
+			return err
+		}
+		if r < '0' || r > '9' {
+			break
+		}
+		param = append(param, r)
+	}
+	switch r {
+	case 'A':
+		e.recall(-1)
+	case 'B':
+		e.recall(1)
+	case 'C':
+		e.moveRight()
+	case 'D':
+		e.moveLeft()
+	case 'H':
+		e.pos = 0
+	case 'F':
+		e.pos = len(e.buf)
+	case '~':
+		switch string(param) {
+		case "1", "7":
+			e.pos = 0
+		case "4", "8":
+			e.pos = len(e.buf)
+		case "3":
+			e.delete()
+		}
+	}
+	return nil
+}
+
+// delete deletes the rune under the cursor.
+func (e *Editor) delete() {
+	if e.pos < len(e.buf) {
+		e.buf = append(e.buf[:e.pos], e.buf[e.pos+1:]...)
+	}
+}
+
+func (e *Editor) moveLeft() {
+	if e.pos > 0 {
+		e.pos--
+	}
+}
+
+func (e *Editor) moveRight() {
+	if e.pos < len(e.buf) {
+		e.pos++
+	}
+}
+
+// recall replaces the line being edited with an earlier (-1) or later (1) line of the history,
+// restoring the line that was being edited when moving past the last line of the history.
+func (e *Editor) recall(direction int) {
+	index := e.historyIndex + direction
+	if index < 0 || index > len(e.history) {
+		return
+	}
+	if e.historyIndex == len(e.history) {
+		e.historyLine = e.buf
+	}
+	e.historyIndex = index
+	if index == len(e.history) {
+		e.buf = e.historyLine
+	} else {
+		e.buf = []rune(e.history[index])
+	}
+	e.pos = len(e.buf)
+}
+
+// complete completes the last word in front of the cursor to the longest common prefix
+// of its completion candidates, listing all candidates when tab is pressed twice
+// without any progress being made.
+func (e *Editor) complete() {
+	if e.Complete == nil {
+		return
+	}
+	line := string(e.buf[:e.pos])
+	candidates := e.Complete(line)
+	if len(candidates) == 0 {
+		return
+	}
+	start := e.pos
+	for start > 0 && !unicode.IsSpace(e.buf[start-1]) {
+		start--
+	}
+	word := string(e.buf[start:e.pos])
+
+	completion := longestCommonPrefix(candidates)
+	if len(candidates) == 1 {
+		completion += " "
+	}
+	if completion != word && strings.HasPrefix(completion, word) {
+		e.buf = append(append(e.buf[:start:start], []rune(completion)...), e.buf[e.pos:]...)
+		e.pos = start + len([]rune(completion))
+		return
+	}
+	if len(candidates) > 1 && e.lastTab {
+		e.write("\r\n" + strings.Join(candidates, "  ") + "\r\n")
+	}
+}
+
+// refresh redraws the prompt and line being edited, positioning the cursor.
+func (e *Editor) refresh() {
+	s := "\r" + e.Prompt + string(e.buf) + "\x1b[K"
+	if n := len(e.buf) - e.pos; n > 0 {
+		s += fmt.Sprintf("\x1b[%dD", n)
+	}
+	e.write(s)
+}
+
+func (e *Editor) write(s string) {
+	io.WriteString(e.out, s)
+}
+
+// longestCommonPrefix returns the longest prefix shared by all given strings.
+func longestCommonPrefix(strs []string) string {
+	prefix := strs[0]
+	for _, str := range strs[1:] {
+		for !strings.HasPrefix(str, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
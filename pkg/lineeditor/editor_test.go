@@ -0,0 +1,141 @@
+package lineeditor
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+// newTestEditor creates an editor reading the given (terminal) input.
+func newTestEditor(input string) *Editor {
+	return &Editor{
+		Prompt:   "> ",
+		terminal: true,
+		reader:   bufio.NewReader(strings.NewReader(input)),
+		out:      ioutil.Discard,
+	}
+}
+
+func TestEdit(t *testing.T) {
+	testCases := []struct {
+		Input    string
+		Expected string
+	}{
+		{"hello\r", "hello"},
+		{"hello\n", "hello"},
+		{"helo\x1b[Dl\r", "hello"},                                       // left arrow
+		{"ello\x01h\r", "hello"},                                         // Ctrl+A
+		{"hxello\x01\x1b[C\x1b[3~\r", "hello"},                           // right arrow, delete
+		{"hello world\x17\x7f\r", "hello"},                               // Ctrl+W, backspace
+		{"bye\x15hello\r", "hello"},                                      // Ctrl+U
+		{"hello world\x01\x1b[C\x1b[C\x1b[C\x1b[C\x1b[C\x0b\r", "hello"}, // Ctrl+K
+		{"héllo\x02\x02\x02\x08e\r", "hello"},                            // multi-byte runes, Ctrl+B, Ctrl+H
+	}
+	for _, testCase := range testCases {
+		line, err := newTestEditor(testCase.Input).edit()
+		if err != nil {
+			t.Errorf("failed to edit %q: %v", testCase.Input, err)
+		} else if line != testCase.Expected {
+			t.Errorf("unexpected line for %q: %q != %q", testCase.Input, line, testCase.Expected)
+		}
+	}
+
+	if _, err := newTestEditor("abc\x03").edit(); err != ErrInterrupted {
+		t.Errorf("expected %v, not: %v", ErrInterrupted, err)
+	}
+	if _, err := newTestEditor("\x04").edit(); err != io.EOF {
+		t.Errorf("expected %v, not: %v", io.EOF, err)
+	}
+	if line, err := newTestEditor("ab\x02\x04\r").edit(); err != nil || line != "a" {
+		t.Errorf("unexpected result of Ctrl+D on a non-empty line: %q, %v", line, err)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	e := newTestEditor("\x1b[A\r\x1b[A\x1b[A\x1b[A\r\x1b[A\x1b[A\x1b[B\r\x10\x0e\x0enew\r")
+	for _, line := range []string{"first", "", "second", "second"} {
+		e.AddHistory(line)
+	}
+	if len(e.history) != 2 {
+		t.Fatalf("unexpected history: %q", e.history)
+	}
+	for _, expected := range []string{"second", "first", "second", "new"} {
+		line, err := e.edit()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if line != expected {
+			t.Errorf("unexpected line: %q != %q", line, expected)
+		}
+	}
+
+	for i := 0; i < MaxHistory+10; i++ {
+		e.AddHistory(strings.Repeat("x", i+1))
+	}
+	if len(e.history) != MaxHistory {
+		t.Errorf("unexpected history length: %d != %d", len(e.history), MaxHistory)
+	}
+}
+
+func TestComplete(t *testing.T) {
+	complete := func(line string) []string {
+		words := strings.Fields(line)
+		if len(words) == 0 || strings.HasSuffix(line, " ") {
+			return nil
+		}
+		var candidates []string
+		for _, candidate := range []string{"wallet", "walk", "gateway"} {
+			if strings.HasPrefix(candidate, words[len(words)-1]) {
+				candidates = append(candidates, candidate)
+			}
+		}
+		return candidates
+	}
+	testCases := []struct {
+		Input    string
+		Expected string
+	}{
+		{"ga\t\r", "gateway "},
+		{"w\t\r", "wal"},
+		{"walle\t\r", "wallet "},
+		{"x\t\r", "x"},
+		{"wa balance\x01\x06\x06\t\r", "wal balance"}, // completion in front of the cursor
+	}
+	for _, testCase := range testCases {
+		e := newTestEditor(testCase.Input)
+		e.Complete = complete
+		line, err := e.edit()
+		if err != nil {
+			t.Errorf("failed to edit %q: %v", testCase.Input, err)
+		} else if line != testCase.Expected {
+			t.Errorf("unexpected line for %q: %q != %q", testCase.Input, line, testCase.Expected)
+		}
+	}
+
+	// pressing tab twice lists all candidates
+	var out bytes.Buffer
+	e := newTestEditor("wal\t\t\r")
+	e.Complete, e.out = complete, &out
+	if _, err := e.edit(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "wallet  walk") {
+		t.Errorf("candidates were not listed: %q", out.String())
+	}
+}
+
+func TestReadLineNoTerminal(t *testing.T) {
+	e := &Editor{reader: bufio.NewReader(strings.NewReader("first\r\nsecond"))}
+	for _, expected := range []string{"first", "second"} {
+		line, err := e.ReadLine()
+		if err != nil || line != expected {
+			t.Errorf("unexpected line: %q, %v", line, err)
+		}
+	}
+	if _, err := e.ReadLine(); err != io.EOF {
+		t.Errorf("expected %v, not: %v", io.EOF, err)
+	}
+}
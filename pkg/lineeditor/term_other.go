@@ -0,0 +1,22 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+// +build !linux,!darwin,!dragonfly,!freebsd,!netbsd,!openbsd
+
+package lineeditor
+
+import "errors"
+
+type termState struct{}
+
+// isTerminal always returns false, as raw terminal mode is not supported on this platform,
+// such that lines are read without editing support.
+func isTerminal(fd int) bool {
+	return false
+}
+
+func makeRaw(fd int) (*termState, error) {
+	return nil, errors.New("raw terminal mode is not supported on this platform")
+}
+
+func restore(fd int, state *termState) error {
+	return nil
+}
@@ -0,0 +1,39 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+// +build linux darwin dragonfly freebsd netbsd openbsd
+
+package lineeditor
+
+import "golang.org/x/sys/unix"
+
+type termState struct {
+	termios unix.Termios
+}
+
+// isTerminal returns true if the given file descriptor is a terminal.
+func isTerminal(fd int) bool {
+	_, err := unix.IoctlGetTermios(fd, ioctlGetTermios)
+	return err == nil
+}
+
+// makeRaw puts the terminal into raw mode, such that the input is read
+// byte per byte without being echoed, returning the previous state of the terminal.
+// Output processing is kept, such that the output of other goroutines is printed as usual.
+func makeRaw(fd int) (*termState, error) {
+	termios, err := unix.IoctlGetTermios(fd, ioctlGetTermios)
+	if err != nil {
+		return nil, err
+	}
+	state := &termState{termios: *termios}
+	termios.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON
+	termios.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+	termios.Cflag &^= unix.CSIZE | unix.PARENB
+	termios.Cflag |= unix.CS8
+	termios.Cc[unix.VMIN] = 1
+	termios.Cc[unix.VTIME] = 0
+	return state, unix.IoctlSetTermios(fd, ioctlSetTermios, termios)
+}
+
+// restore restores the terminal to the given state.
+func restore(fd int, state *termState) error {
+	return unix.IoctlSetTermios(fd, ioctlSetTermios, &state.termios)
+}
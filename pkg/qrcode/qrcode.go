@@ -0,0 +1,619 @@
+// Package qrcode implements a minimal QR code encoder,
+// encoding binary data in byte mode and rendering it as text,
+// such that addresses and payment requests can be scanned straight from a terminal.
+package qrcode
+
+import (
+	"errors"
+	"strings"
+)
+
+// Level is the error correction level of a QR code,
+// defining how much of a damaged code can still be restored by a reader.
+type Level int
+
+const (
+	// LevelL allows about 7% of the codewords to be restored.
+	LevelL Level = iota
+	// LevelM allows about 15% of the codewords to be restored.
+	LevelM
+)
+
+// MaxVersion is the largest version (size) of QR code supported by this package,
+// a version 15 code being 77x77 modules.
+const MaxVersion = 15
+
+var (
+	// ErrDataTooLong is returned when the data does not fit in a QR code of the max supported version.
+	ErrDataTooLong = errors.New("data is too long to be encoded as a QR code")
+	// ErrInvalidLevel is returned when an unsupported error correction level is given.
+	ErrInvalidLevel = errors.New("unsupported QR code error correction level")
+)
+
+// formatBits returns the error correction level indicator, as used in the format information.
+func (l Level) formatBits() int {
+	if l == LevelL {
+		return 1
+	}
+	return 0
+}
+
+// blockLayout describes how the codewords of a QR code version,
+// for a given error correction level, are split into blocks.
+type blockLayout struct {
+	ecCodewords    int // error correction codewords per block
+	blocks1        int
+	dataCodewords1 int // data codewords per block of the first group
+	blocks2        int
+	dataCodewords2 int // data codewords per block of the second group
+}
+
+// dataCodewords returns the total amount of data codewords.
+func (b blockLayout) dataCodewords() int {
+	return b.blocks1*b.dataCodewords1 + b.blocks2*b.dataCodewords2
+}
+
+// blockLayouts of all supported versions, indexed by version-1 and level
+var blockLayouts = [MaxVersion][2]blockLayout{
+	{{7, 1, 19, 0, 0}, {10, 1, 16, 0, 0}},
+	{{10, 1, 34, 0, 0}, {16, 1, 28, 0, 0}},
+	{{15, 1, 55, 0, 0}, {26, 1, 44, 0, 0}},
+	{{20, 1, 80, 0, 0}, {18, 2, 32, 0, 0}},
+	{{26, 1, 108, 0, 0}, {24, 2, 43, 0, 0}},
+	{{18, 2, 68, 0, 0}, {16, 4, 27, 0, 0}},
+	{{20, 2, 78, 0, 0}, {18, 4, 31, 0, 0}},
+	{{24, 2, 97, 0, 0}, {22, 2, 38, 2, 39}},
+	{{30, 2, 116, 0, 0}, {22, 3, 36, 2, 37}},
+	{{18, 2, 68, 2, 69}, {26, 4, 43, 1, 44}},
+	{{20, 4, 81, 0, 0}, {30, 1, 50, 4, 51}},
+	{{24, 2, 92, 2, 93}, {22, 6, 36, 2, 37}},
+	{{26, 4, 107, 0, 0}, {22, 8, 37, 1, 38}},
+	{{30, 3, 115, 1, 116}, {24, 4, 40, 5, 41}},
+	{{22, 5, 87, 1, 88}, {24, 5, 41, 5, 42}},
+}
+
+// alignmentPatternPositions of all supported versions, indexed by version-1
+var alignmentPatternPositions = [MaxVersion][]int{
+	nil,
+	{6, 18},
+	{6, 22},
+	{6, 26},
+	{6, 30},
+	{6, 34},
+	{6, 22, 38},
+	{6, 24, 42},
+	{6, 26, 46},
+	{6, 28, 50},
+	{6, 30, 54},
+	{6, 32, 58},
+	{6, 34, 62},
+	{6, 26, 46, 66},
+	{6, 26, 48, 70},
+}
+
+// Code is an encoded QR code, a square grid of dark and light modules.
+type Code struct {
+	// Version of the code, defining its size
+	Version int
+	// Level of error correction
+	Level Level
+	// Mask applied to the data modules of the code
+	Mask int
+
+	size     int
+	modules  [][]bool // dark modules, indexed as [y][x]
+	function [][]bool // modules part of a function pattern, indexed as [y][x]
+}
+
+// Encode encodes the given data in byte mode as a QR code,
+// using the smallest version that fits the data for the given error correction level.
+func Encode(data []byte, level Level) (*Code, error) {
+	if level != LevelL && level != LevelM {
+		return nil, ErrInvalidLevel
+	}
+	for version := 1; version <= MaxVersion; version++ {
+		layout := blockLayouts[version-1][level]
+		countBits := characterCountBits(version)
+		if len(data) >= 1<<uint(countBits) || 4+countBits+len(data)*8 > layout.dataCodewords()*8 {
+			continue
+		}
+		c := newCode(version, level)
+		c.draw(encodeCodewords(data, countBits, layout))
+		return c, nil
+	}
+	return nil, ErrDataTooLong
+}
+
+// Size returns the width (and height) of the code in modules, excluding the quiet zone.
+func (c *Code) Size() int {
+	return c.size
+}
+
+// Dark returns true if the module at the given coordinates is dark,
+// coordinates outside of the code being part of the (light) quiet zone.
+func (c *Code) Dark(x, y int) bool {
+	if x < 0 || y < 0 || x >= c.size || y >= c.size {
+		return false
+	}
+	return c.modules[y][x]
+}
+
+// quietZone is the width of the light border, in modules, surrounding a rendered code.
+const quietZone = 4
+
+// ASCII renders the code as plain text, using two characters per module,
+// such that it is roughly square when printed in a terminal.
+// Dark modules are rendered as '#' and light modules as spaces,
+// meaning a light terminal background is required to scan it.
+func (c *Code) ASCII() string {
+	return c.render("##", "  ", "")
+}
+
+// ANSI renders the code using ANSI escape codes, coloring the background
+// of two spaces per module black or white, such that it can be scanned
+// from a terminal regardless of its color scheme.
+func (c *Code) ANSI() string {
+	return c.render("\x1b[40m  ", "\x1b[47m  ", "\x1b[0m")
+}
+
+// render renders the code line per line, including its quiet zone,
+// using the given strings for dark and light modules and to end each line.
+func (c *Code) render(dark, light, eol string) string {
+	var sb strings.Builder
+	for y := -quietZone; y < c.size+quietZone; y++ {
+		for x := -quietZone; x < c.size+quietZone; x++ {
+			if c.Dark(x, y) {
+				sb.WriteString(dark)
+			} else {
+				sb.WriteString(light)
+			}
+		}
+		sb.WriteString(eol)
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// characterCountBits returns the length of the character count indicator of byte mode.
+func characterCountBits(version int) int {
+	if version < 10 {
+		return 8
+	}
+	return 16
+}
+
+// encodeCodewords encodes the data in byte mode as data codewords,
+// and returns them interleaved with their error correction codewords.
+func encodeCodewords(data []byte, countBits int, layout blockLayout) []byte {
+	capacity := layout.dataCodewords() * 8
+	var bb bitBuffer
+	bb.append(0x4, 4) // byte mode indicator
+	bb.append(len(data), countBits)
+	for _, b := range data {
+		bb.append(int(b), 8)
+	}
+	terminator := capacity - bb.len()
+	if terminator > 4 {
+		terminator = 4
+	}
+	bb.append(0, terminator)
+	if rem := bb.len() % 8; rem != 0 {
+		bb.append(0, 8-rem)
+	}
+	codewords := bb.bytes
+	for pad := byte(0xEC); len(codewords) < layout.dataCodewords(); pad ^= 0xEC ^ 0x11 {
+		codewords = append(codewords, pad)
+	}
+
+	// split the data codewords in blocks, computing error correction codewords for each block
+	divisor := reedSolomonDivisor(layout.ecCodewords)
+	var dataBlocks, ecBlocks [][]byte
+	for i, offset := 0, 0; i < layout.blocks1+layout.blocks2; i++ {
+		n := layout.dataCodewords1
+		if i >= layout.blocks1 {
+			n = layout.dataCodewords2
+		}
+		block := codewords[offset : offset+n]
+		offset += n
+		dataBlocks = append(dataBlocks, block)
+		ecBlocks = append(ecBlocks, reedSolomonRemainder(block, divisor))
+	}
+
+	// interleave the codewords of all blocks
+	maxDataCodewords := layout.dataCodewords1
+	if layout.dataCodewords2 > maxDataCodewords {
+		maxDataCodewords = layout.dataCodewords2
+	}
+	result := make([]byte, 0, len(codewords)+len(ecBlocks)*layout.ecCodewords)
+	for i := 0; i < maxDataCodewords; i++ {
+		for _, block := range dataBlocks {
+			if i < len(block) {
+				result = append(result, block[i])
+			}
+		}
+	}
+	for i := 0; i < layout.ecCodewords; i++ {
+		for _, block := range ecBlocks {
+			result = append(result, block[i])
+		}
+	}
+	return result
+}
+
+// bitBuffer is a sequence of bits, packed most significant bit first.
+type bitBuffer struct {
+	bytes []byte
+	n     int
+}
+
+// len returns the amount of bits in the buffer.
+func (bb *bitBuffer) len() int {
+	return bb.n
+}
+
+// append appends the given amount of least significant bits of the value,
+// most significant bit first.
+func (bb *bitBuffer) append(value, length int) {
+	for i := length - 1; i >= 0; i-- {
+		if bb.n%8 == 0 {
+			bb.bytes = append(bb.bytes, 0)
+		}
+		if (value>>uint(i))&1 != 0 {
+			bb.bytes[bb.n/8] |= 0x80 >> uint(bb.n%8)
+		}
+		bb.n++
+	}
+}
+
+// reedSolomonDivisor returns the coefficients of the generator polynomial of the given degree,
+// from highest to lowest power, excluding the leading coefficient which is always 1.
+func reedSolomonDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range result {
+			result[j] = gfMultiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = gfMultiply(root, 0x02)
+	}
+	return result
+}
+
+// reedSolomonRemainder returns the error correction codewords of the given data,
+// being the remainder of the data polynomial divided by the given divisor.
+func reedSolomonRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i := range result {
+			result[i] ^= gfMultiply(divisor[i], factor)
+		}
+	}
+	return result
+}
+
+// gfMultiply multiplies two elements of GF(2^8), modulo x^8 + x^4 + x^3 + x^2 + 1.
+func gfMultiply(x, y byte) byte {
+	var z int
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11D)
+		z ^= int((y>>uint(i))&1) * int(x)
+	}
+	return byte(z)
+}
+
+// newCode creates an empty code of the given version and error correction level.
+func newCode(version int, level Level) *Code {
+	size := version*4 + 17
+	c := &Code{
+		Version:  version,
+		Level:    level,
+		size:     size,
+		modules:  make([][]bool, size),
+		function: make([][]bool, size),
+	}
+	for y := 0; y < size; y++ {
+		c.modules[y] = make([]bool, size)
+		c.function[y] = make([]bool, size)
+	}
+	return c
+}
+
+// draw draws all patterns and the given codewords,
+// applying the mask which results in the lowest penalty.
+func (c *Code) draw(codewords []byte) {
+	c.drawFunctionPatterns()
+	c.drawCodewords(codewords)
+
+	bestMask, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		c.applyMask(mask)
+		c.drawFormatBits(mask)
+		if penalty := c.penalty(); bestPenalty < 0 || penalty < bestPenalty {
+			bestMask, bestPenalty = mask, penalty
+		}
+		c.applyMask(mask) // masks are their own inverse
+	}
+	c.Mask = bestMask
+	c.applyMask(bestMask)
+	c.drawFormatBits(bestMask)
+}
+
+// setFunctionModule sets a module which is part of a function pattern.
+func (c *Code) setFunctionModule(x, y int, dark bool) {
+	c.modules[y][x] = dark
+	c.function[y][x] = true
+}
+
+// drawFunctionPatterns draws the timing, finder and alignment patterns,
+// and reserves the modules of the format and version information.
+func (c *Code) drawFunctionPatterns() {
+	for i := 0; i < c.size; i++ {
+		c.setFunctionModule(6, i, i%2 == 0)
+		c.setFunctionModule(i, 6, i%2 == 0)
+	}
+
+	c.drawFinderPattern(3, 3)
+	c.drawFinderPattern(c.size-4, 3)
+	c.drawFinderPattern(3, c.size-4)
+
+	positions := alignmentPatternPositions[c.Version-1]
+	last := len(positions) - 1
+	for i, x := range positions {
+		for j, y := range positions {
+			// skip the corners occupied by finder patterns
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			c.drawAlignmentPattern(x, y)
+		}
+	}
+
+	c.drawFormatBits(0)
+	c.drawVersionBits()
+}
+
+// drawFinderPattern draws a finder pattern, including its separator, centered at the given module.
+func (c *Code) drawFinderPattern(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || yy < 0 || xx >= c.size || yy >= c.size {
+				continue
+			}
+			dist := maxAbs(dx, dy)
+			c.setFunctionModule(xx, yy, dist != 2 && dist != 4)
+		}
+	}
+}
+
+// drawAlignmentPattern draws an alignment pattern centered at the given module.
+func (c *Code) drawAlignmentPattern(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			c.setFunctionModule(x+dx, y+dy, maxAbs(dx, dy) != 1)
+		}
+	}
+}
+
+// formatBits returns the 15-bit format information for the given level and mask,
+// protected using a BCH code.
+func formatBits(level Level, mask int) int {
+	data := level.formatBits()<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	return (data<<10 | rem) ^ 0x5412
+}
+
+// drawFormatBits draws both copies of the format information, as well as the dark module.
+func (c *Code) drawFormatBits(mask int) {
+	bits := formatBits(c.Level, mask)
+	bit := func(i int) bool { return (bits>>uint(i))&1 != 0 }
+
+	// first copy, around the top left finder pattern
+	for i := 0; i <= 5; i++ {
+		c.setFunctionModule(8, i, bit(i))
+	}
+	c.setFunctionModule(8, 7, bit(6))
+	c.setFunctionModule(8, 8, bit(7))
+	c.setFunctionModule(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		c.setFunctionModule(14-i, 8, bit(i))
+	}
+
+	// second copy, split over the top right and bottom left finder patterns
+	for i := 0; i < 8; i++ {
+		c.setFunctionModule(c.size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		c.setFunctionModule(8, c.size-15+i, bit(i))
+	}
+	c.setFunctionModule(8, c.size-8, true)
+}
+
+// versionBits returns the 18-bit version information, protected using a BCH code.
+func versionBits(version int) int {
+	rem := version
+	for i := 0; i < 12; i++ {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+	}
+	return version<<12 | rem
+}
+
+// drawVersionBits draws both copies of the version information, only present from version 7 onwards.
+func (c *Code) drawVersionBits() {
+	if c.Version < 7 {
+		return
+	}
+	bits := versionBits(c.Version)
+	for i := 0; i < 18; i++ {
+		dark := (bits>>uint(i))&1 != 0
+		a, b := c.size-11+i%3, i/3
+		c.setFunctionModule(a, b, dark)
+		c.setFunctionModule(b, a, dark)
+	}
+}
+
+// drawCodewords draws the codewords in the data area,
+// in a zigzag pattern of two modules wide columns, starting from the bottom right.
+func (c *Code) drawCodewords(codewords []byte) {
+	i := 0
+	for right := c.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5 // skip the vertical timing pattern
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < c.size; vert++ {
+			y := vert
+			if upward {
+				y = c.size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if c.function[y][x] || i >= len(codewords)*8 {
+					continue
+				}
+				c.modules[y][x] = (codewords[i/8]>>uint(7-i%8))&1 != 0
+				i++
+			}
+		}
+	}
+}
+
+// applyMask inverts the data modules selected by the given mask pattern.
+func (c *Code) applyMask(mask int) {
+	for y := 0; y < c.size; y++ {
+		for x := 0; x < c.size; x++ {
+			if c.function[y][x] {
+				continue
+			}
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if invert {
+				c.modules[y][x] = !c.modules[y][x]
+			}
+		}
+	}
+}
+
+// penalty scores the readability of the code, lower being better.
+func (c *Code) penalty() int {
+	penalty := 0
+
+	// runs of same-colored modules and finder-like patterns in rows and columns
+	line := make([]bool, c.size)
+	for i := 0; i < c.size; i++ {
+		penalty += linePenalty(c.modules[i])
+		for j := 0; j < c.size; j++ {
+			line[j] = c.modules[j][i]
+		}
+		penalty += linePenalty(line)
+	}
+
+	// 2x2 blocks of same-colored modules
+	for y := 0; y < c.size-1; y++ {
+		for x := 0; x < c.size-1; x++ {
+			dark := c.modules[y][x]
+			if c.modules[y][x+1] == dark && c.modules[y+1][x] == dark && c.modules[y+1][x+1] == dark {
+				penalty += 3
+			}
+		}
+	}
+
+	// imbalance between dark and light modules
+	dark := 0
+	for y := 0; y < c.size; y++ {
+		for x := 0; x < c.size; x++ {
+			if c.modules[y][x] {
+				dark++
+			}
+		}
+	}
+	deviation := dark*100/(c.size*c.size) - 50
+	if deviation < 0 {
+		deviation = -deviation
+	}
+	return penalty + deviation/5*10
+}
+
+// finderLikePattern is the 1:1:3:1:1 dark-light pattern of a finder pattern.
+var finderLikePattern = []bool{true, false, true, true, true, false, true}
+
+// linePenalty scores a single row or column of modules.
+func linePenalty(line []bool) int {
+	penalty := 0
+	run := 1
+	for i := 1; i <= len(line); i++ {
+		if i < len(line) && line[i] == line[i-1] {
+			run++
+			continue
+		}
+		if run >= 5 {
+			penalty += run - 2
+		}
+		run = 1
+	}
+
+	for i := 0; i+len(finderLikePattern) <= len(line); i++ {
+		matches := true
+		for j, dark := range finderLikePattern {
+			if line[i+j] != dark {
+				matches = false
+				break
+			}
+		}
+		if matches && (isLight(line, i-4, i) || isLight(line, i+7, i+11)) {
+			penalty += 40
+		}
+	}
+	return penalty
+}
+
+// isLight returns true if all modules within the given range are light,
+// modules outside of the line being part of the (light) quiet zone.
+func isLight(line []bool, from, to int) bool {
+	for i := from; i < to; i++ {
+		if i >= 0 && i < len(line) && line[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func maxAbs(a, b int) int {
+	if a < 0 {
+		a = -a
+	}
+	if b < 0 {
+		b = -b
+	}
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,310 @@
+package qrcode
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestReedSolomonRemainder(t *testing.T) {
+	// the data and error correction codewords of "HELLO WORLD" as a 1-M code,
+	// as documented by the QR code specification
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	expected := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	ec := reedSolomonRemainder(data, reedSolomonDivisor(len(expected)))
+	if !bytes.Equal(ec, expected) {
+		t.Errorf("unexpected error correction codewords: %v != %v", ec, expected)
+	}
+}
+
+func TestFormatAndVersionBits(t *testing.T) {
+	formatTestCases := []struct {
+		Level    Level
+		Mask     int
+		Expected int
+	}{
+		{LevelL, 0, 0x77C4},
+		{LevelL, 4, 0x662F},
+		{LevelM, 0, 0x5412},
+		{LevelM, 7, 0x4AA0},
+	}
+	for _, testCase := range formatTestCases {
+		if bits := formatBits(testCase.Level, testCase.Mask); bits != testCase.Expected {
+			t.Errorf("unexpected format bits for level %d and mask %d: %015b != %015b",
+				testCase.Level, testCase.Mask, bits, testCase.Expected)
+		}
+	}
+	versionTestCases := map[int]int{7: 0x07C94, 8: 0x085BC, 15: 0x0F928}
+	for version, expected := range versionTestCases {
+		if bits := versionBits(version); bits != expected {
+			t.Errorf("unexpected version bits for version %d: %018b != %018b", version, bits, expected)
+		}
+	}
+}
+
+func TestEncodeVersion(t *testing.T) {
+	testCases := []struct {
+		Level   Level
+		Length  int
+		Version int
+	}{
+		{LevelL, 17, 1},
+		{LevelL, 18, 2},
+		{LevelM, 14, 1},
+		{LevelM, 15, 2},
+		{LevelM, 78, 5},
+		{LevelM, 180, 9},
+		{LevelM, 181, 10},
+		{LevelM, 412, 15},
+		{LevelL, 520, 15},
+	}
+	for _, testCase := range testCases {
+		code, err := Encode(bytes.Repeat([]byte{'a'}, testCase.Length), testCase.Level)
+		if err != nil {
+			t.Errorf("failed to encode %d bytes at level %d: %v", testCase.Length, testCase.Level, err)
+			continue
+		}
+		if code.Version != testCase.Version {
+			t.Errorf("unexpected version for %d bytes at level %d: %d != %d",
+				testCase.Length, testCase.Level, code.Version, testCase.Version)
+		}
+		if expected := testCase.Version*4 + 17; code.Size() != expected {
+			t.Errorf("unexpected size for version %d: %d != %d", code.Version, code.Size(), expected)
+		}
+	}
+
+	if _, err := Encode(make([]byte, 413), LevelM); err != ErrDataTooLong {
+		t.Errorf("expected %v, not: %v", ErrDataTooLong, err)
+	}
+	if _, err := Encode([]byte("a"), Level(3)); err != ErrInvalidLevel {
+		t.Errorf("expected %v, not: %v", ErrInvalidLevel, err)
+	}
+}
+
+func TestBlockLayouts(t *testing.T) {
+	for version := 1; version <= MaxVersion; version++ {
+		// the codewords have to fill the data area, except for 0, 3 or 7 remainder bits
+		c := newCode(version, LevelM)
+		c.drawFunctionPatterns()
+		dataModules := 0
+		for y := 0; y < c.size; y++ {
+			for x := 0; x < c.size; x++ {
+				if !c.function[y][x] {
+					dataModules++
+				}
+			}
+		}
+		for level, layout := range blockLayouts[version-1] {
+			if layout.blocks2 != 0 && layout.dataCodewords2 != layout.dataCodewords1+1 {
+				t.Errorf("invalid second block group of version %d and level %d", version, level)
+			}
+			codewords := layout.dataCodewords() + (layout.blocks1+layout.blocks2)*layout.ecCodewords
+			switch remainder := dataModules - codewords*8; remainder {
+			case 0, 3, 7:
+			default:
+				t.Errorf("codewords of version %d and level %d do not fit the data area: %d remainder bits",
+					version, level, remainder)
+			}
+		}
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	inputs := []string{
+		"",
+		"hello",
+		"rivine:015a080a9259b9d4aaa550e2156f49b1a79a64c7ea463d810d4493e8242e6791584fbdac553e6f",
+		"rivine:015a080a9259b9d4aaa550e2156f49b1a79a64c7ea463d810d4493e8242e6791584fbdac553e6f?amount=12.5&message=" +
+			strings.Repeat("lunch%20money%20", 8),
+		strings.Repeat("0123456789", 40),
+	}
+	for _, level := range []Level{LevelL, LevelM} {
+		for _, input := range inputs {
+			code, err := Encode([]byte(input), level)
+			if err != nil {
+				t.Errorf("failed to encode %q: %v", input, err)
+				continue
+			}
+			output, err := decode(code)
+			if err != nil {
+				t.Errorf("failed to decode %q (version %d, level %d, mask %d): %v",
+					input, code.Version, level, code.Mask, err)
+				continue
+			}
+			if output != input {
+				t.Errorf("unexpected decoded data: %q != %q", output, input)
+			}
+		}
+	}
+}
+
+func TestFunctionPatterns(t *testing.T) {
+	code, err := Encode([]byte(strings.Repeat("x", 200)), LevelM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// finder patterns, including their (light) separators
+	for _, corner := range [][2]int{{0, 0}, {code.Size() - 7, 0}, {0, code.Size() - 7}} {
+		for dy := -1; dy <= 7; dy++ {
+			for dx := -1; dx <= 7; dx++ {
+				dist := maxAbs(dx-3, dy-3)
+				expected := dist != 2 && dist != 4
+				x, y := corner[0]+dx, corner[1]+dy
+				if x < 0 || y < 0 || x >= code.Size() || y >= code.Size() {
+					continue
+				}
+				if code.Dark(x, y) != expected {
+					t.Errorf("unexpected module (%d, %d) of finder pattern at %v", x, y, corner)
+				}
+			}
+		}
+	}
+	// timing patterns
+	for i := 8; i < code.Size()-8; i++ {
+		if code.Dark(i, 6) != (i%2 == 0) || code.Dark(6, i) != (i%2 == 0) {
+			t.Errorf("unexpected module %d of timing patterns", i)
+		}
+	}
+	// dark module
+	if !code.Dark(8, code.Size()-8) {
+		t.Error("dark module is light")
+	}
+	// quiet zone
+	if code.Dark(-1, 0) || code.Dark(0, code.Size()) {
+		t.Error("quiet zone is not light")
+	}
+}
+
+func TestRender(t *testing.T) {
+	code, err := Encode([]byte("hello"), LevelM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	width := code.Size() + 2*quietZone
+
+	lines := strings.Split(strings.TrimSuffix(code.ASCII(), "\n"), "\n")
+	if len(lines) != width {
+		t.Fatalf("unexpected amount of ASCII lines: %d != %d", len(lines), width)
+	}
+	for i, line := range lines {
+		if len(line) != width*2 {
+			t.Errorf("unexpected length of ASCII line %d: %d != %d", i, len(line), width*2)
+		}
+	}
+	if expected := strings.Repeat(" ", 2*quietZone) + strings.Repeat("#", 14) + "  "; !strings.HasPrefix(lines[quietZone], expected) {
+		t.Errorf("unexpected first line of finder pattern: %q", lines[quietZone])
+	}
+
+	lines = strings.Split(strings.TrimSuffix(code.ANSI(), "\n"), "\n")
+	if len(lines) != width {
+		t.Fatalf("unexpected amount of ANSI lines: %d != %d", len(lines), width)
+	}
+	for i, line := range lines {
+		if !strings.HasSuffix(line, "\x1b[0m") {
+			t.Errorf("ANSI line %d does not reset its colors: %q", i, line)
+		}
+	}
+}
+
+// decode reads the data of a code back, validating its format information
+// and the error correction codewords of each block.
+func decode(code *Code) (string, error) {
+	// read the first copy of the format information
+	var bits int
+	bit := func(i, x, y int) {
+		if code.Dark(x, y) {
+			bits |= 1 << uint(i)
+		}
+	}
+	for i := 0; i <= 5; i++ {
+		bit(i, 8, i)
+	}
+	bit(6, 8, 7)
+	bit(7, 8, 8)
+	bit(8, 7, 8)
+	for i := 9; i < 15; i++ {
+		bit(i, 14-i, 8)
+	}
+	if expected := formatBits(code.Level, code.Mask); bits != expected {
+		return "", fmt.Errorf("invalid format bits %015b, expected %015b", bits, expected)
+	}
+
+	// read the codewords, undoing the mask
+	code.applyMask(code.Mask)
+	defer code.applyMask(code.Mask)
+	var bb bitBuffer
+	for right := code.Size() - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < code.Size(); vert++ {
+			y := vert
+			if upward {
+				y = code.Size() - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				if x := right - j; !code.function[y][x] {
+					dark := 0
+					if code.Dark(x, y) {
+						dark = 1
+					}
+					bb.append(dark, 1)
+				}
+			}
+		}
+	}
+
+	// deinterleave the blocks, validating their error correction codewords
+	layout := blockLayouts[code.Version-1][code.Level]
+	blocks := layout.blocks1 + layout.blocks2
+	dataBlocks := make([][]byte, blocks)
+	ecBlocks := make([][]byte, blocks)
+	codewords := bb.bytes
+	for i := 0; i < layout.dataCodewords1+1; i++ {
+		for b := range dataBlocks {
+			n := layout.dataCodewords1
+			if b >= layout.blocks1 {
+				n = layout.dataCodewords2
+			}
+			if i < n {
+				dataBlocks[b] = append(dataBlocks[b], codewords[0])
+				codewords = codewords[1:]
+			}
+		}
+	}
+	for i := 0; i < layout.ecCodewords; i++ {
+		for b := range ecBlocks {
+			ecBlocks[b] = append(ecBlocks[b], codewords[0])
+			codewords = codewords[1:]
+		}
+	}
+	divisor := reedSolomonDivisor(layout.ecCodewords)
+	var data []byte
+	for b := range dataBlocks {
+		if !bytes.Equal(reedSolomonRemainder(dataBlocks[b], divisor), ecBlocks[b]) {
+			return "", fmt.Errorf("invalid error correction codewords for block %d", b)
+		}
+		data = append(data, dataBlocks[b]...)
+	}
+
+	// parse the byte mode segment
+	readBits := func(offset, length int) int {
+		value := 0
+		for i := offset; i < offset+length; i++ {
+			value = value<<1 | int(data[i/8]>>uint(7-i%8))&1
+		}
+		return value
+	}
+	if mode := readBits(0, 4); mode != 0x4 {
+		return "", fmt.Errorf("unexpected mode %x", mode)
+	}
+	countBits := characterCountBits(code.Version)
+	length := readBits(4, countBits)
+	output := make([]byte, length)
+	for i := range output {
+		output[i] = byte(readBits(4+countBits+i*8, 8))
+	}
+	return string(output), nil
+}
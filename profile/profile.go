@@ -82,6 +82,81 @@ func SaveMemProfile(profileDir, identifier string) error {
 	return nil
 }
 
+// profileFilePath returns the path of a new profile file of the given kind in the given directory,
+// creating the directory if it doesn't exist yet.
+func profileFilePath(profileDir, kind, identifier string) (string, error) {
+	err := os.MkdirAll(profileDir, 0700)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(profileDir, kind+"-profile-"+identifier+"-"+time.Now().Format(time.RFC3339Nano)+".prof"), nil
+}
+
+// SaveCPUProfile profiles the cpu for the given duration, saving the profile to a file
+// in the given directory, of which the path is returned. An error will be returned
+// if a cpu profiler is already running.
+func SaveCPUProfile(profileDir, identifier string, duration time.Duration) (string, error) {
+	cpuLock.Lock()
+	if cpuActive {
+		cpuLock.Unlock()
+		return "", errors.New("cannot start cpu profiler, a profiler is already running")
+	}
+	cpuActive = true
+	cpuLock.Unlock()
+	defer func() {
+		cpuLock.Lock()
+		cpuActive = false
+		cpuLock.Unlock()
+	}()
+
+	filename, err := profileFilePath(profileDir, "cpu", identifier)
+	if err != nil {
+		return "", err
+	}
+	file, err := os.Create(filename)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	// the cpu profiler can still be started outside of this package, e.g. by net/http/pprof
+	err = pprof.StartCPUProfile(file)
+	if err != nil {
+		os.Remove(filename)
+		return "", err
+	}
+	time.Sleep(duration)
+	pprof.StopCPUProfile()
+	return filename, file.Sync()
+}
+
+// SaveProfile saves the named runtime profile, such as heap or goroutine (see pprof.Lookup),
+// to a file in the given directory, of which the path is returned.
+func SaveProfile(profileDir, name, identifier string) (string, error) {
+	p := pprof.Lookup(name)
+	if p == nil {
+		return "", fmt.Errorf("unknown profile %q", name)
+	}
+	filename, err := profileFilePath(profileDir, name, identifier)
+	if err != nil {
+		return "", err
+	}
+	file, err := os.Create(filename)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	if name == "heap" {
+		// the heap profile is as of the last garbage collection, make it up-to-date
+		runtime.GC()
+	}
+	err = p.WriteTo(file, 0)
+	if err != nil {
+		os.Remove(filename)
+		return "", err
+	}
+	return filename, file.Sync()
+}
+
 // StartContinuousProfiling will continuously print statistics about the cpu
 // usage, memory usage, and runtime stats of the program.
 func StartContinuousProfile(profileDir string, bcInfo types.BlockchainInfo) {
@@ -17,6 +17,7 @@ then
 else
 	full_version="${version}-${commit}"
 fi
+build_time="$(date -u +%Y-%m-%dT%H:%M:%SZ)"
 
 for os in darwin linux windows; do
 	echo Packaging ${os}...
@@ -31,7 +32,7 @@ for os in darwin linux windows; do
 			bin=${pkg}.exe
 		fi
 		GOOS=${os} go build -a \
-			-ldflags="-X ${package}/build.rawVersion=${full_version} -s -w" \
+			-ldflags="-X ${package}/build.rawVersion=${full_version} -X ${package}/build.GitRevision=${commit} -X ${package}/build.BuildTime=${build_time} -s -w" \
 			-o "${folder}/${bin}" "./${pkg}"
 
 	done
@@ -61,6 +61,9 @@ type ChainConstants struct {
 	// which is not on index 0 in the first transaction of a block can be used to
 	// participate in the proof of blockstake protocol
 	BlockStakeAging uint64
+	// ProofOfBlockStake optionally defines custom proof of blockstake rules,
+	// by default (nil) the standard rules are used, using BlockStakeAging.
+	ProofOfBlockStake ProofOfBlockStakeRules
 	// BlockCreatorFee is the amount of hastings you get for creating a block on top of
 	// all the other rewards such as collected transaction fees.
 	BlockCreatorFee Currency
@@ -91,6 +94,11 @@ type ChainConstants struct {
 	CurrencyUnits CurrencyUnits
 
 	TransactionPool TransactionPoolConstants
+
+	// HardForks define the planned protocol upgrades of the chain,
+	// each activating a new block rule set at a given height.
+	// They have to be sorted by height, and are optional.
+	HardForks []HardFork
 }
 
 // CurrencyUnits defines the units used for the different kind of currencies.
@@ -115,6 +123,37 @@ type TransactionPoolConstants struct {
 	// ordering, so the size limit is such that the transaction pool will never
 	// exceed the size of a block.
 	PoolSizeLimit int
+
+	// PoolTransactionCountLimit is the maximum amount of transactions in the transaction pool.
+	// There is no limit if this is 0.
+	PoolTransactionCountLimit int
+	// PoolSourceTransactionLimit is the maximum amount of pooled transactions relayed by
+	// a single peer, preventing a single peer from filling up the transaction pool.
+	// There is no limit if this is 0.
+	PoolSourceTransactionLimit int
+
+	// OrphanPoolSizeLimit is the maximum size, in bytes, of all orphan transaction sets combined,
+	// held by the transaction pool while waiting for their parents. The oldest orphans
+	// are dropped once this limit is reached.
+	OrphanPoolSizeLimit int
+
+	// MinimumFeePerByte is the minimum fee, in hastings per byte of a binary-encoded
+	// transaction set, a transaction set has to pay in order to be accepted by the transaction pool.
+	// The fee-per-byte is also used to decide which transaction sets to evict, once the pool is full,
+	// in favour of transaction sets paying a higher fee-per-byte.
+	MinimumFeePerByte Currency
+
+	// MaxTransactionAge is the amount of blocks a transaction set can remain unconfirmed,
+	// after which it is dropped from the transaction pool, freeing its inputs.
+	// Transaction sets never expire if this is 0.
+	MaxTransactionAge BlockHeight
+
+	// ReplaceByFee allows a transaction set to replace the pooled transaction set(s)
+	// it conflicts with (spending the same outputs), if it pays sufficiently higher fees.
+	ReplaceByFee bool
+	// ReplaceByFeeMinimumIncrease is the minimum increase, in percentage, of both the total fee
+	// and the fee-per-byte of a replacing transaction set, compared to the set(s) it replaces.
+	ReplaceByFeeMinimumIncrease uint64
 }
 
 // DefaultCurrencyUnits provides sane defaults for currency units
@@ -130,6 +169,11 @@ func DefaultTransactionPoolConstants() TransactionPoolConstants {
 		TransactionSizeLimit:    16e3,
 		TransactionSetSizeLimit: 250e3,
 		PoolSizeLimit:           2e6 - 5e3 - 250e3,
+		OrphanPoolSizeLimit:     250e3,
+		MaxTransactionAge:       720,
+
+		ReplaceByFee:                true,
+		ReplaceByFeeMinimumIncrease: 10,
 	}
 }
 
@@ -322,12 +366,11 @@ func (c *ChainConstants) Validate() error {
 	if len(c.GenesisBlockStakeAllocation) == 0 {
 		return errors.New("Invalid genesis blockstake allocation")
 	}
-	// Genesis timestamp should not be too far in the past. The reference timestamp is the timestamp of the bitcoin genesis block,
-	// as it's pretty safe to assume no blockchain was created before this (Saturday, January 3, 2009 6:15:05 PM GMT)
-	if c.GenesisTimestamp < Timestamp(1231006505) {
+	// Genesis timestamp should not be too far in the past.
+	if c.GenesisTimestamp < minimumGenesisTimestamp {
 		return errors.New("Invalid genesis timestamp")
 	}
-	return nil
+	return c.validateHardForks()
 }
 
 // GenesisBlock returns the genesis block based on the blockchain config
@@ -0,0 +1,110 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+)
+
+// minimumGenesisTimestamp is the timestamp of the bitcoin genesis block,
+// as it's pretty safe to assume no blockchain was created before this
+// (Saturday, January 3, 2009 6:15:05 PM GMT)
+const minimumGenesisTimestamp = Timestamp(1231006505)
+
+// GenesisDefinition defines the complete state created by the genesis block,
+// allowing a chain (or test) to define its genesis programmatically,
+// rather than having to edit the chain constants.
+type GenesisDefinition struct {
+	// Timestamp is the timestamp of the genesis block.
+	Timestamp Timestamp
+	// TransactionVersion is the version of the (only) transaction of the genesis block.
+	TransactionVersion TransactionVersion
+	// CoinOutputs are the coin outputs created by the genesis block,
+	// locked by any (standard) unlock condition.
+	CoinOutputs []CoinOutput
+	// BlockStakeOutputs are the block stake outputs created by the genesis block,
+	// locked by any (standard) unlock condition.
+	BlockStakeOutputs []BlockStakeOutput
+}
+
+// Validate checks that the genesis definition is internally consistent,
+// such that it can be used to create a valid genesis block.
+func (gd GenesisDefinition) Validate() error {
+	if gd.Timestamp < minimumGenesisTimestamp {
+		return errors.New("Invalid genesis timestamp")
+	}
+	err := gd.TransactionVersion.IsValidTransactionVersion()
+	if err != nil {
+		return fmt.Errorf("invalid genesis transaction version: %v", err)
+	}
+	if len(gd.CoinOutputs) == 0 {
+		return errors.New("Invalid genesis coin distribution")
+	}
+	if len(gd.BlockStakeOutputs) == 0 {
+		return errors.New("Invalid genesis blockstake allocation")
+	}
+	ctx := ValidationContext{
+		Confirmed:   true,
+		BlockHeight: 0,
+		BlockTime:   gd.Timestamp,
+	}
+	for i, co := range gd.CoinOutputs {
+		if co.Value.IsZero() {
+			return fmt.Errorf("invalid genesis coin output #%d: %v", i, ErrZeroOutput)
+		}
+		err = co.Condition.IsStandardCondition(ctx)
+		if err != nil {
+			return fmt.Errorf("invalid genesis coin output #%d: %v", i, err)
+		}
+	}
+	for i, bso := range gd.BlockStakeOutputs {
+		if bso.Value.IsZero() {
+			return fmt.Errorf("invalid genesis blockstake output #%d: %v", i, ErrZeroOutput)
+		}
+		err = bso.Condition.IsStandardCondition(ctx)
+		if err != nil {
+			return fmt.Errorf("invalid genesis blockstake output #%d: %v", i, err)
+		}
+	}
+	return nil
+}
+
+// Genesis returns the genesis definition as defined by the chain constants.
+func (c *ChainConstants) Genesis() GenesisDefinition {
+	return GenesisDefinition{
+		Timestamp:          c.GenesisTimestamp,
+		TransactionVersion: c.GenesisTransactionVersion,
+		CoinOutputs:        c.GenesisCoinDistribution,
+		BlockStakeOutputs:  c.GenesisBlockStakeAllocation,
+	}
+}
+
+// SetGenesis validates the given genesis definition,
+// and overwrites the genesis constants with it, if valid.
+//
+// The same (modified) chain constants have to be passed to all modules,
+// as they all derive the genesis block from it.
+func (c *ChainConstants) SetGenesis(gd GenesisDefinition) error {
+	err := gd.Validate()
+	if err != nil {
+		return err
+	}
+	genesisBlock := Block{
+		Timestamp: gd.Timestamp,
+		Transactions: []Transaction{
+			{
+				Version:           gd.TransactionVersion,
+				BlockStakeOutputs: gd.BlockStakeOutputs,
+				CoinOutputs:       gd.CoinOutputs,
+			},
+		},
+	}
+	err = TransactionFitsInABlock(genesisBlock.Transactions[0], c.BlockSizeLimit)
+	if err != nil {
+		return fmt.Errorf("invalid genesis transaction: %v", err)
+	}
+	c.GenesisTimestamp = gd.Timestamp
+	c.GenesisTransactionVersion = gd.TransactionVersion
+	c.GenesisCoinDistribution = append([]CoinOutput(nil), gd.CoinOutputs...)
+	c.GenesisBlockStakeAllocation = append([]BlockStakeOutput(nil), gd.BlockStakeOutputs...)
+	return nil
+}
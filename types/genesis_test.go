@@ -0,0 +1,79 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/threefoldtech/rivine/crypto"
+)
+
+func TestGenesisDefinitionValidate(t *testing.T) {
+	uh := UnlockHash{Type: UnlockTypePubKey, Hash: crypto.Hash{1, 2, 3}}
+	validDefinition := func() GenesisDefinition {
+		return GenesisDefinition{
+			Timestamp:          Timestamp(1500000000),
+			TransactionVersion: TransactionVersionOne,
+			CoinOutputs: []CoinOutput{
+				{Value: NewCurrency64(100), Condition: NewCondition(NewUnlockHashCondition(uh))},
+				{Value: NewCurrency64(50), Condition: NewCondition(NewTimeLockCondition(42, NewUnlockHashCondition(uh)))},
+			},
+			BlockStakeOutputs: []BlockStakeOutput{
+				{Value: NewCurrency64(1000), Condition: NewCondition(NewMultiSignatureCondition(UnlockHashSlice{uh, uh}, 1))},
+			},
+		}
+	}
+	if err := validDefinition().Validate(); err != nil {
+		t.Fatal("valid definition is invalid:", err)
+	}
+
+	testCases := []func(gd *GenesisDefinition){
+		func(gd *GenesisDefinition) { gd.Timestamp = 42 },
+		func(gd *GenesisDefinition) { gd.TransactionVersion = 42 },
+		func(gd *GenesisDefinition) { gd.CoinOutputs = nil },
+		func(gd *GenesisDefinition) { gd.BlockStakeOutputs = nil },
+		func(gd *GenesisDefinition) { gd.CoinOutputs[1].Value = ZeroCurrency },
+		func(gd *GenesisDefinition) { gd.BlockStakeOutputs[0].Value = ZeroCurrency },
+		func(gd *GenesisDefinition) {
+			gd.BlockStakeOutputs[0].Condition = NewCondition(&MultiSignatureCondition{UnlockHashes: UnlockHashSlice{uh}, MinimumSignatureCount: 2})
+		},
+	}
+	for idx, modify := range testCases {
+		gd := validDefinition()
+		modify(&gd)
+		if err := gd.Validate(); err == nil {
+			t.Errorf("test case #%d: expected invalid definition to be invalid", idx)
+		}
+	}
+}
+
+func TestChainConstantsSetGenesis(t *testing.T) {
+	cts := TestnetChainConstants()
+	gd := cts.Genesis()
+	gd.Timestamp = Timestamp(1500000000)
+	gd.CoinOutputs = append(gd.CoinOutputs, CoinOutput{
+		Value:     NewCurrency64(1),
+		Condition: NewCondition(&NilCondition{}),
+	})
+	err := cts.SetGenesis(gd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	genesisBlock := cts.GenesisBlock()
+	if genesisBlock.Timestamp != gd.Timestamp {
+		t.Error("unexpected genesis timestamp:", genesisBlock.Timestamp)
+	}
+	if len(genesisBlock.Transactions[0].CoinOutputs) != len(gd.CoinOutputs) {
+		t.Error("unexpected genesis coin outputs:", genesisBlock.Transactions[0].CoinOutputs)
+	}
+	if err = cts.Validate(); err != nil {
+		t.Error("chain constants are invalid:", err)
+	}
+
+	// an invalid definition should leave the constants untouched
+	gd.BlockStakeOutputs = nil
+	if err = cts.SetGenesis(gd); err == nil {
+		t.Fatal("expected invalid genesis definition to be rejected")
+	}
+	if len(cts.GenesisBlockStakeAllocation) == 0 {
+		t.Fatal("invalid genesis definition modified the chain constants")
+	}
+}
@@ -0,0 +1,122 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+)
+
+// hardfork.go defines the machinery used to upgrade the protocol at planned block heights.
+// Each hard fork activates a block rule set, which defines how blocks are encoded
+// and validated, starting at the height of that fork, up until the height of the next fork.
+// The encoding of a rule set is the one a block is checked against the block size limit with,
+// and can be used to exchange blocks of a known height, see MarshalBlockAt and UnmarshalBlockAt.
+// Block IDs are derived from the block header, and do not depend on that encoding.
+
+type (
+	// BlockRuleSet defines the fork-specific rules which apply to a block.
+	BlockRuleSet interface {
+		// MarshalBlock binary-encodes a block,
+		// as it is encoded to check it against the block size limit.
+		MarshalBlock(b Block) ([]byte, error)
+		// UnmarshalBlock decodes a block binary-encoded using MarshalBlock.
+		UnmarshalBlock(data []byte, b *Block) error
+		// ValidateBlock validates any fork-specific rules of a block,
+		// on top of the standard block validation rules.
+		ValidateBlock(b Block, ctx BlockValidationContext) error
+	}
+
+	// BlockValidationContext is given to a BlockRuleSet,
+	// in order to validate a block within its context.
+	BlockValidationContext struct {
+		// BlockHeight is the height of the block that is validated.
+		BlockHeight BlockHeight
+		// BlockSizeLimit is the maximum size of the encoded block, in bytes.
+		BlockSizeLimit uint64
+	}
+
+	// HardFork activates a block rule set at a given height.
+	HardFork struct {
+		// Name is the (unique) name of the hard fork, used for logging and errors only.
+		Name string
+		// Height is the first block height at which the block rule set applies.
+		Height BlockHeight
+		// Rules are the block rules which apply from the height of this fork onwards.
+		Rules BlockRuleSet
+	}
+
+	// DefaultBlockRuleSet is the block rule set which applies to all blocks
+	// prior to the first hard fork. It can be embedded by a custom rule set,
+	// in order to only overwrite part of the rules.
+	DefaultBlockRuleSet struct{}
+)
+
+// MarshalBlock implements BlockRuleSet.MarshalBlock,
+// binary-encoding the block using the siabin encoding.
+func (DefaultBlockRuleSet) MarshalBlock(b Block) ([]byte, error) {
+	return siabin.Marshal(b), nil
+}
+
+// UnmarshalBlock implements BlockRuleSet.UnmarshalBlock,
+// decoding a siabin-encoded block.
+func (DefaultBlockRuleSet) UnmarshalBlock(data []byte, b *Block) error {
+	return siabin.Unmarshal(data, b)
+}
+
+// ValidateBlock implements BlockRuleSet.ValidateBlock,
+// and defines no additional rules.
+func (DefaultBlockRuleSet) ValidateBlock(Block, BlockValidationContext) error {
+	return nil
+}
+
+// BlockRuleSetAt returns the block rule set which applies to a block at the given height,
+// which is the rule set of the last hard fork activated at or prior to that height.
+// The DefaultBlockRuleSet is returned if no hard fork is active yet at the given height.
+func (c *ChainConstants) BlockRuleSetAt(height BlockHeight) BlockRuleSet {
+	// hard forks are sorted by height, as guaranteed by the Validate method
+	for i := len(c.HardForks) - 1; i >= 0; i-- {
+		if c.HardForks[i].Height <= height {
+			return c.HardForks[i].Rules
+		}
+	}
+	return DefaultBlockRuleSet{}
+}
+
+// MarshalBlockAt binary-encodes a block at the given height,
+// using the encoding of the block rule set which applies at that height.
+func (c *ChainConstants) MarshalBlockAt(b Block, height BlockHeight) ([]byte, error) {
+	return c.BlockRuleSetAt(height).MarshalBlock(b)
+}
+
+// UnmarshalBlockAt decodes a block at the given height,
+// using the encoding of the block rule set which applies at that height.
+func (c *ChainConstants) UnmarshalBlockAt(data []byte, height BlockHeight) (b Block, err error) {
+	err = c.BlockRuleSetAt(height).UnmarshalBlock(data, &b)
+	return
+}
+
+// validateHardForks ensures all hard forks are well defined,
+// and sorted by strictly increasing height.
+func (c *ChainConstants) validateHardForks() error {
+	names := make(map[string]struct{}, len(c.HardForks))
+	for i, fork := range c.HardForks {
+		if fork.Name == "" {
+			return fmt.Errorf("hard fork #%d has no name", i)
+		}
+		if _, ok := names[fork.Name]; ok {
+			return fmt.Errorf("hard fork %q is defined multiple times", fork.Name)
+		}
+		names[fork.Name] = struct{}{}
+		if fork.Rules == nil {
+			return fmt.Errorf("hard fork %q has no block rules", fork.Name)
+		}
+		if fork.Height == 0 {
+			return fmt.Errorf("hard fork %q cannot be activated at the genesis block", fork.Name)
+		}
+		if i > 0 && fork.Height <= c.HardForks[i-1].Height {
+			return errors.New("hard forks have to be sorted by strictly increasing height")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package types
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+)
+
+// maxTransactionsRuleSet is a test rule set,
+// limiting the amount of transactions a block can have.
+type maxTransactionsRuleSet struct {
+	DefaultBlockRuleSet
+	max int
+}
+
+func (rs maxTransactionsRuleSet) ValidateBlock(b Block, ctx BlockValidationContext) error {
+	if len(b.Transactions) > rs.max {
+		return errors.New("too many transactions")
+	}
+	return nil
+}
+
+func TestBlockRuleSetAt(t *testing.T) {
+	cts := TestnetChainConstants()
+	if _, ok := cts.BlockRuleSetAt(42).(DefaultBlockRuleSet); !ok {
+		t.Fatal("expected default rule set when no hard forks are defined")
+	}
+
+	cts.HardForks = []HardFork{
+		{Name: "first", Height: 10, Rules: maxTransactionsRuleSet{max: 1}},
+		{Name: "second", Height: 20, Rules: maxTransactionsRuleSet{max: 2}},
+	}
+	if err := cts.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		height BlockHeight
+		max    int // -1 = default rule set
+	}{
+		{0, -1}, {9, -1}, {10, 1}, {11, 1}, {19, 1}, {20, 2}, {1000, 2},
+	}
+	for _, tc := range testCases {
+		rules := cts.BlockRuleSetAt(tc.height)
+		if tc.max == -1 {
+			if _, ok := rules.(DefaultBlockRuleSet); !ok {
+				t.Errorf("height %d: expected default rule set, got %T", tc.height, rules)
+			}
+			continue
+		}
+		if rs, ok := rules.(maxTransactionsRuleSet); !ok || rs.max != tc.max {
+			t.Errorf("height %d: unexpected rule set %#v", tc.height, rules)
+		}
+	}
+}
+
+func TestValidateHardForks(t *testing.T) {
+	rules := DefaultBlockRuleSet{}
+	testCases := [][]HardFork{
+		{{Name: "", Height: 1, Rules: rules}},
+		{{Name: "a", Height: 1, Rules: nil}},
+		{{Name: "a", Height: 0, Rules: rules}},
+		{{Name: "a", Height: 1, Rules: rules}, {Name: "a", Height: 2, Rules: rules}},
+		{{Name: "a", Height: 2, Rules: rules}, {Name: "b", Height: 2, Rules: rules}},
+		{{Name: "a", Height: 3, Rules: rules}, {Name: "b", Height: 2, Rules: rules}},
+	}
+	for idx, forks := range testCases {
+		cts := TestnetChainConstants()
+		cts.HardForks = forks
+		if err := cts.Validate(); err == nil {
+			t.Errorf("test case #%d: expected invalid hard forks to be invalid", idx)
+		}
+	}
+}
+
+// versionedBlockRuleSet is a test rule set, encoding blocks
+// prefixed with a format version, which has to match when decoding.
+type versionedBlockRuleSet struct {
+	DefaultBlockRuleSet
+	version byte
+}
+
+func (rs versionedBlockRuleSet) MarshalBlock(b Block) ([]byte, error) {
+	return append([]byte{rs.version}, siabin.Marshal(b)...), nil
+}
+
+func (rs versionedBlockRuleSet) UnmarshalBlock(data []byte, b *Block) error {
+	if len(data) == 0 || data[0] != rs.version {
+		return errors.New("unexpected block format version")
+	}
+	return siabin.Unmarshal(data[1:], b)
+}
+
+func TestMarshalBlockAcrossForks(t *testing.T) {
+	cts := TestnetChainConstants()
+	cts.HardForks = []HardFork{
+		{Name: "v1", Height: 10, Rules: versionedBlockRuleSet{version: 1}},
+		{Name: "v2", Height: 20, Rules: versionedBlockRuleSet{version: 2}},
+	}
+	if err := cts.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	block := Block{
+		Timestamp:    42,
+		MinerPayouts: []MinerPayout{{Value: NewCurrency64(1)}},
+		Transactions: []Transaction{{Version: TransactionVersionOne, ArbitraryData: []byte("data")}},
+	}
+
+	testCases := []struct {
+		height BlockHeight
+		prefix []byte
+	}{
+		{9, nil}, {10, []byte{1}}, {19, []byte{1}}, {20, []byte{2}}, {1000, []byte{2}},
+	}
+	for _, tc := range testCases {
+		data, err := cts.MarshalBlockAt(block, tc.height)
+		if err != nil {
+			t.Fatalf("height %d: %v", tc.height, err)
+		}
+		if expected := append(tc.prefix, siabin.Marshal(block)...); !bytes.Equal(data, expected) {
+			t.Errorf("height %d: unexpected encoding %x", tc.height, data)
+		}
+		decoded, err := cts.UnmarshalBlockAt(data, tc.height)
+		if err != nil {
+			t.Fatalf("height %d: %v", tc.height, err)
+		}
+		if decoded.ID() != block.ID() || decoded.MerkleRoot() != block.MerkleRoot() {
+			t.Errorf("height %d: decoded block differs from the encoded block", tc.height)
+		}
+	}
+
+	// a block encoded using the format of one fork cannot be decoded using the format of another fork
+	data, err := cts.MarshalBlockAt(block, 15)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = cts.UnmarshalBlockAt(data, 25); err == nil {
+		t.Error("expected a block encoded prior to the v2 fork not to decode after it")
+	}
+}
@@ -0,0 +1,118 @@
+package types
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+)
+
+// SignedMessagePrefix is prepended to a message prior to hashing and signing it,
+// such that a message signature can never be a valid transaction signature.
+const SignedMessagePrefix = "Rivine Signed Message:\n"
+
+var (
+	// ErrMessageSignatureAddressMismatch is returned when verifying a message signature
+	// which was not created using the key pair of the given address.
+	ErrMessageSignatureAddressMismatch = errors.New("message signature was not created by the given address")
+	// ErrInvalidMessageSignature is returned when verifying a message signature
+	// which is not a valid signature of the given message.
+	ErrInvalidMessageSignature = errors.New("invalid message signature")
+)
+
+// MessageSignature is the signature of an arbitrary message,
+// created using the key pair of a single signature address.
+// It contains the public key of that key pair, such that it can be verified
+// by anyone knowing only the address.
+//
+// It is encoded as a base64 string of its binary encoding, such that it can be easily shared.
+type MessageSignature struct {
+	PublicKey PublicKey
+	Signature ByteSlice
+}
+
+// MessageHash returns the hash of a message which is signed as a MessageSignature.
+func MessageHash(message []byte) crypto.Hash {
+	return crypto.HashAll(SignedMessagePrefix, message)
+}
+
+// SignMessage signs the given message using the given Ed25519 secret key.
+func SignMessage(message []byte, sk crypto.SecretKey) MessageSignature {
+	sig := crypto.SignHash(MessageHash(message), sk)
+	return MessageSignature{
+		PublicKey: Ed25519PublicKey(sk.PublicKey()),
+		Signature: sig[:],
+	}
+}
+
+// UnlockHash returns the (single signature) address of the key pair which created the signature.
+func (ms MessageSignature) UnlockHash() UnlockHash {
+	return NewPubKeyUnlockHash(ms.PublicKey)
+}
+
+// Verify verifies that the signature is a valid signature of the given message,
+// created using the key pair of the given address.
+func (ms MessageSignature) Verify(address UnlockHash, message []byte) error {
+	if ms.UnlockHash().Cmp(address) != 0 {
+		return ErrMessageSignatureAddressMismatch
+	}
+	err := strictSignatureCheck(ms.PublicKey, ms.Signature)
+	if err != nil {
+		return err
+	}
+	var (
+		pk  crypto.PublicKey
+		sig crypto.Signature
+	)
+	copy(pk[:], ms.PublicKey.Key)
+	copy(sig[:], ms.Signature)
+	if crypto.VerifyHash(MessageHash(message), pk, sig) != nil {
+		return ErrInvalidMessageSignature
+	}
+	return nil
+}
+
+// String returns the message signature as a base64 string.
+func (ms MessageSignature) String() string {
+	return base64.StdEncoding.EncodeToString(siabin.MarshalAll(ms.PublicKey, ms.Signature))
+}
+
+// LoadString loads a message signature from a base64 string, as returned by String.
+func (ms *MessageSignature) LoadString(str string) error {
+	b, err := base64.StdEncoding.DecodeString(str)
+	if err != nil {
+		return fmt.Errorf("message signature is not base64-encoded: %v", err)
+	}
+	var decoded MessageSignature
+	r := bytes.NewReader(b)
+	err = siabin.NewDecoder(r).DecodeAll(&decoded.PublicKey, &decoded.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid message signature: %v", err)
+	}
+	if r.Len() != 0 {
+		return errors.New("invalid message signature: unexpected trailing bytes")
+	}
+	*ms = decoded
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.MarshalJSON,
+// encoding the message signature as a base64 string.
+func (ms MessageSignature) MarshalJSON() ([]byte, error) {
+	return json.Marshal(ms.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.UnmarshalJSON,
+// decoding the message signature from a base64 string.
+func (ms *MessageSignature) UnmarshalJSON(b []byte) error {
+	var str string
+	err := json.Unmarshal(b, &str)
+	if err != nil {
+		return err
+	}
+	return ms.LoadString(str)
+}
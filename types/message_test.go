@@ -0,0 +1,64 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/threefoldtech/rivine/crypto"
+)
+
+// TestMessageSignature probes the signing, verification and encoding of a message signature.
+func TestMessageSignature(t *testing.T) {
+	sk, pk := crypto.GenerateKeyPair()
+	address := NewEd25519PubKeyUnlockHash(pk)
+	message := []byte("hello, world")
+
+	ms := SignMessage(message, sk)
+	if ms.UnlockHash().Cmp(address) != 0 {
+		t.Fatal("unexpected address of the signature:", ms.UnlockHash())
+	}
+	if err := ms.Verify(address, message); err != nil {
+		t.Fatal("failed to verify signature:", err)
+	}
+	if err := ms.Verify(address, []byte("hello, world!")); err != ErrInvalidMessageSignature {
+		t.Fatal("expected an invalid signature for another message, got:", err)
+	}
+	_, otherPK := crypto.GenerateKeyPair()
+	if err := ms.Verify(NewEd25519PubKeyUnlockHash(otherPK), message); err != ErrMessageSignatureAddressMismatch {
+		t.Fatal("expected an address mismatch for another address, got:", err)
+	}
+
+	// a message signature can never be a transaction signature of the same hash
+	if MessageHash(message) == crypto.HashObject(message) {
+		t.Fatal("message hash is not prefixed")
+	}
+
+	// the signature is encoded as a base64 string
+	var decoded MessageSignature
+	if err := decoded.LoadString(ms.String()); err != nil {
+		t.Fatal(err)
+	}
+	if err := decoded.Verify(address, message); err != nil {
+		t.Fatal("failed to verify decoded signature:", err)
+	}
+	b, err := json.Marshal(ms)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `"`+ms.String()+`"` {
+		t.Fatal("unexpected JSON encoding:", string(b))
+	}
+	decoded = MessageSignature{}
+	if err = json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if err := decoded.Verify(address, message); err != nil {
+		t.Fatal("failed to verify JSON-decoded signature:", err)
+	}
+
+	for _, str := range []string{"", "not base64!", ms.String()[:20], ms.String() + "AAAA"} {
+		if err := decoded.LoadString(str); err == nil {
+			t.Errorf("expected an error for invalid signature %q", str)
+		}
+	}
+}
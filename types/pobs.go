@@ -0,0 +1,66 @@
+package types
+
+import (
+	"math/big"
+
+	"github.com/threefoldtech/rivine/crypto"
+)
+
+// ProofOfBlockStakeRules defines the computations of the proof of blockstake protocol,
+// allowing a chain to experiment with alternative staking rules (e.g. different aging or weighting),
+// while reusing the rest of the consensus logic.
+//
+// The same rules are used by the consensus set, to validate blocks,
+// and by the block creator, to create them.
+type ProofOfBlockStakeRules interface {
+	// MinimumBlockTimestamp returns the earliest timestamp a block can have,
+	// in order to use the blockstake output (with the given indexes) for the proof of blockstake protocol.
+	// The timestamp of the block that created the blockstake output is given as well.
+	MinimumBlockTimestamp(indexes BlockStakeOutputIndexes, outputBlockTimestamp Timestamp) Timestamp
+	// MeetsTarget returns true if a block with the given timestamp,
+	// using the given blockstake output (indexes and value), meets the given target,
+	// for the given stake modifier.
+	MeetsTarget(stakeModifier *big.Int, indexes BlockStakeOutputIndexes, value Currency, timestamp Timestamp, target Target) bool
+}
+
+// StandardProofOfBlockStakeRules are the proof of blockstake rules used by default.
+//
+// A blockstake output can only be used immediately if it is the first output
+// of the first transaction of a block (which is where a block creator respends its blockstake),
+// any other blockstake output has to be aged for BlockStakeAging seconds first.
+// The chance a blockstake output creates a block is linear to its value.
+type StandardProofOfBlockStakeRules struct {
+	// BlockStakeAging is the amount of seconds to wait before a blockstake output
+	// which is not on index 0 in the first transaction of a block can be used.
+	BlockStakeAging uint64
+}
+
+// MinimumBlockTimestamp implements ProofOfBlockStakeRules.MinimumBlockTimestamp
+func (rules StandardProofOfBlockStakeRules) MinimumBlockTimestamp(indexes BlockStakeOutputIndexes, outputBlockTimestamp Timestamp) Timestamp {
+	if indexes.TransactionIndex == 0 && indexes.OutputIndex == 0 {
+		return 0
+	}
+	return outputBlockTimestamp + Timestamp(rules.BlockStakeAging)
+}
+
+// MeetsTarget implements ProofOfBlockStakeRules.MeetsTarget
+func (rules StandardProofOfBlockStakeRules) MeetsTarget(stakeModifier *big.Int, indexes BlockStakeOutputIndexes, value Currency, timestamp Timestamp, target Target) bool {
+	// Calculate the hash for the given unspent output and timestamp
+	pobshash := crypto.HashAll(stakeModifier.Bytes(), indexes.BlockHeight, indexes.TransactionIndex, indexes.OutputIndex, timestamp)
+	// Check if it meets the difficulty
+	pobshashvalue := big.NewInt(0).SetBytes(pobshash[:])
+	pobshashvalue.Div(pobshashvalue, value.Big()) //TODO rivine : this div can be mul on the other side of the compare
+	return pobshashvalue.Cmp(target.Int()) == -1
+}
+
+// POBSRules returns the proof of blockstake rules of the chain,
+// defaulting to the standard rules, using the BlockStakeAging constant,
+// in case no custom rules are defined.
+func (c *ChainConstants) POBSRules() ProofOfBlockStakeRules {
+	if c.ProofOfBlockStake != nil {
+		return c.ProofOfBlockStake
+	}
+	return StandardProofOfBlockStakeRules{
+		BlockStakeAging: c.BlockStakeAging,
+	}
+}
@@ -0,0 +1,52 @@
+package types
+
+import (
+	"math/big"
+	"testing"
+)
+
+// noAgingProofOfBlockStakeRules are custom rules, allowing any blockstake to be used immediately.
+type noAgingProofOfBlockStakeRules struct {
+	StandardProofOfBlockStakeRules
+}
+
+func (noAgingProofOfBlockStakeRules) MinimumBlockTimestamp(BlockStakeOutputIndexes, Timestamp) Timestamp {
+	return 0
+}
+
+func TestStandardProofOfBlockStakeRules(t *testing.T) {
+	rules := StandardProofOfBlockStakeRules{BlockStakeAging: 100}
+	if ts := rules.MinimumBlockTimestamp(BlockStakeOutputIndexes{BlockHeight: 5}, 1000); ts != 0 {
+		t.Error("respent blockstake should not require aging, minimum timestamp:", ts)
+	}
+	if ts := rules.MinimumBlockTimestamp(BlockStakeOutputIndexes{BlockHeight: 5, OutputIndex: 1}, 1000); ts != 1100 {
+		t.Error("unexpected minimum timestamp:", ts)
+	}
+	if ts := rules.MinimumBlockTimestamp(BlockStakeOutputIndexes{BlockHeight: 5, TransactionIndex: 1}, 1000); ts != 1100 {
+		t.Error("unexpected minimum timestamp:", ts)
+	}
+
+	indexes := BlockStakeOutputIndexes{BlockHeight: 1}
+	if rules.MeetsTarget(big.NewInt(42), indexes, NewCurrency64(1), 1000, Target{}) {
+		t.Error("no stake should meet the zero target")
+	}
+	var maxTarget Target
+	for i := range maxTarget {
+		maxTarget[i] = 0xff
+	}
+	if !rules.MeetsTarget(big.NewInt(42), indexes, NewCurrency64(2), 1000, maxTarget) {
+		t.Error("any stake should meet the max target")
+	}
+}
+
+func TestChainConstantsPOBSRules(t *testing.T) {
+	cts := TestnetChainConstants()
+	rules, ok := cts.POBSRules().(StandardProofOfBlockStakeRules)
+	if !ok || rules.BlockStakeAging != cts.BlockStakeAging {
+		t.Fatalf("unexpected default rules: %#v", cts.POBSRules())
+	}
+	cts.ProofOfBlockStake = noAgingProofOfBlockStakeRules{}
+	if ts := cts.POBSRules().MinimumBlockTimestamp(BlockStakeOutputIndexes{OutputIndex: 1}, 1000); ts != 0 {
+		t.Error("custom rules are not used, minimum timestamp:", ts)
+	}
+}